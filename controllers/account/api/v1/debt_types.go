@@ -67,6 +67,9 @@ const (
 	ResumeCompletedDebtNamespaceAnnoStatus           = "ResumeCompleted"
 	TerminateSuspendDebtNamespaceAnnoStatus          = "TerminateSuspend"
 	TerminateSuspendCompletedDebtNamespaceAnnoStatus = "TerminateSuspendCompleted"
+	// ResumeNetworkOnly restores routing only; compute stays suspended (KB clusters stopped, limit-0 quota kept)
+	ResumeNetworkOnlyDebtNamespaceAnnoStatus          = "ResumeNetworkOnly"
+	ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus = "ResumeNetworkOnlyCompleted"
 )
 
 // DebtSpec defines the desired state of Debt
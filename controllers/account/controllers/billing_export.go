@@ -0,0 +1,250 @@
+/*
+Copyright 2025 sealos.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	objectstoragev1 "github/labring/sealos/controllers/objectstorage/api/v1"
+
+	"github.com/labring/sealos/controllers/pkg/resources"
+	"github.com/labring/sealos/controllers/pkg/utils/env"
+)
+
+const (
+	// BillingExportBucketEnv is the bucket billing records are archived to, empty disables the export
+	BillingExportBucketEnv = "BILLING_EXPORT_BUCKET"
+	// BillingExportIntervalEnv is the length of each exported time window, defaults to DefaultBillingExportInterval
+	BillingExportIntervalEnv = "BILLING_EXPORT_INTERVAL"
+	// BillingExportFormatEnv is the object format, BillingExportFormatJSON (default) or BillingExportFormatCSV
+	BillingExportFormatEnv = "BILLING_EXPORT_FORMAT"
+
+	BillingExportFormatJSON = "json"
+	BillingExportFormatCSV  = "csv"
+
+	DefaultBillingExportInterval = 24 * time.Hour
+	// billingExportDelay waits for the billing task of the last hour before exporting a window
+	billingExportDelay  = 10 * time.Minute
+	billingExportPrefix = "billing"
+)
+
+// billingExportCSVHeader is the column order of csv exports
+var billingExportCSVHeader = []string{"time", "order_id", "type", "namespace", "owner", "app_type", "app_name", "amount", "status", "detail"}
+
+// BillingRecordSource reads the billing records of a time window
+type BillingRecordSource interface {
+	GetBillingRecords(startTime, endTime time.Time) ([]resources.Billing, error)
+}
+
+// BillingObjectUploader uploads an export object (subset of minio.Client)
+type BillingObjectUploader interface {
+	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+}
+
+// BillingExportRunner periodically archives the billing records of the last complete window
+// to object storage as gzip compressed json lines or csv.
+type BillingExportRunner struct {
+	Client   client.Client
+	Logger   logr.Logger
+	DBClient BillingRecordSource
+	Uploader BillingObjectUploader
+	Bucket   string
+	Interval time.Duration
+	Format   string
+
+	OSNamespace      string
+	OSAdminSecret    string
+	InternalEndpoint string
+}
+
+// NewBillingExportRunnerFromEnv builds the export runner from the environment, returns nil when no bucket is configured
+func NewBillingExportRunnerFromEnv(c client.Client, logger logr.Logger, dbClient BillingRecordSource) (*BillingExportRunner, error) {
+	bucket := os.Getenv(BillingExportBucketEnv)
+	if bucket == "" {
+		return nil, nil
+	}
+	runner := &BillingExportRunner{
+		Client:           c,
+		Logger:           logger.WithName("billing-export"),
+		DBClient:         dbClient,
+		Bucket:           bucket,
+		Interval:         env.GetDurationEnvWithDefault(BillingExportIntervalEnv, DefaultBillingExportInterval),
+		Format:           env.GetEnvWithDefault(BillingExportFormatEnv, BillingExportFormatJSON),
+		OSNamespace:      os.Getenv(OSNamespace),
+		OSAdminSecret:    os.Getenv(OSAdminSecret),
+		InternalEndpoint: os.Getenv(OSInternalEndpointEnv),
+	}
+	if err := runner.validate(); err != nil {
+		return nil, err
+	}
+	return runner, nil
+}
+
+func (r *BillingExportRunner) validate() error {
+	if r.Interval < time.Hour {
+		return fmt.Errorf("%s must be at least 1h, got %s", BillingExportIntervalEnv, r.Interval)
+	}
+	if r.Format != BillingExportFormatJSON && r.Format != BillingExportFormatCSV {
+		return fmt.Errorf("%s must be %s or %s, got %q", BillingExportFormatEnv, BillingExportFormatJSON, BillingExportFormatCSV, r.Format)
+	}
+	if r.Uploader == nil && (r.OSNamespace == "" || r.OSAdminSecret == "" || r.InternalEndpoint == "") {
+		return fmt.Errorf("billing export requires the %s, %s and %s env of object storage", OSNamespace, OSAdminSecret, OSInternalEndpointEnv)
+	}
+	return nil
+}
+
+func (r *BillingExportRunner) Start(ctx context.Context) error {
+	for {
+		// Export the last complete window once the billing task of its last hour has run
+		windowEnd := time.Now().UTC().Truncate(r.Interval)
+		next := windowEnd.Add(r.Interval).Add(billingExportDelay)
+		if time.Since(windowEnd) >= billingExportDelay {
+			if _, err := r.ExportWindow(ctx, windowEnd.Add(-r.Interval), windowEnd); err != nil {
+				r.Logger.Error(err, "failed to export billing records", "end", windowEnd.Format(time.RFC3339))
+			}
+		} else {
+			next = windowEnd.Add(billingExportDelay)
+		}
+
+		r.Logger.Info("next billing export time", "time", next.Format(time.RFC3339))
+		select {
+		case <-time.After(time.Until(next)):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection only the leader exports, so each window is written once
+func (r *BillingExportRunner) NeedLeaderElection() bool {
+	return true
+}
+
+// ExportWindow uploads the billing records of [start, end) and returns the object name, an empty window is skipped
+func (r *BillingExportRunner) ExportWindow(ctx context.Context, start, end time.Time) (string, error) {
+	billings, err := r.DBClient.GetBillingRecords(start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to get billing records: %w", err)
+	}
+	if len(billings) == 0 {
+		r.Logger.V(1).Info("no billing records to export", "start", start.Format(time.RFC3339), "end", end.Format(time.RFC3339))
+		return "", nil
+	}
+
+	data, err := encodeBillingExport(billings, r.Format)
+	if err != nil {
+		return "", err
+	}
+	uploader, err := r.getUploader(ctx)
+	if err != nil {
+		return "", err
+	}
+	objectName := billingExportObjectName(start, end, r.Format)
+	contentType := "application/x-ndjson"
+	if r.Format == BillingExportFormatCSV {
+		contentType = "text/csv"
+	}
+	if _, err := uploader.PutObject(ctx, r.Bucket, objectName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType:     contentType,
+		ContentEncoding: "gzip",
+	}); err != nil {
+		return "", fmt.Errorf("failed to upload billing export %s/%s: %w", r.Bucket, objectName, err)
+	}
+	r.Logger.Info("exported billing records", "object", objectName, "count", len(billings))
+	return objectName, nil
+}
+
+// getUploader creates the object storage client from the admin secret on first use
+func (r *BillingExportRunner) getUploader(ctx context.Context) (BillingObjectUploader, error) {
+	if r.Uploader != nil {
+		return r.Uploader, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: r.OSAdminSecret, Namespace: r.OSNamespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get object storage admin secret %s/%s: %w", r.OSNamespace, r.OSAdminSecret, err)
+	}
+	osClient, err := objectstoragev1.NewOSClient(r.InternalEndpoint, string(secret.Data[OSAccessKey]), string(secret.Data[OSSecretKey]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to new object storage client: %w", err)
+	}
+	r.Uploader = osClient
+	return r.Uploader, nil
+}
+
+// billingExportObjectName names the object after its window, e.g. billing/2025-01-01T00-00-00Z_2025-01-02T00-00-00Z.json.gz
+func billingExportObjectName(start, end time.Time, format string) string {
+	const layout = "2006-01-02T15-04-05Z"
+	return fmt.Sprintf("%s/%s_%s.%s.gz", billingExportPrefix, start.UTC().Format(layout), end.UTC().Format(layout), format)
+}
+
+// encodeBillingExport encodes the billings as gzip compressed json lines or csv
+func encodeBillingExport(billings []resources.Billing, format string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	switch format {
+	case BillingExportFormatCSV:
+		w := csv.NewWriter(gz)
+		if err := w.Write(billingExportCSVHeader); err != nil {
+			return nil, fmt.Errorf("failed to write billing export header: %w", err)
+		}
+		for _, billing := range billings {
+			if err := w.Write([]string{
+				billing.Time.UTC().Format(time.RFC3339),
+				billing.OrderID,
+				strconv.Itoa(int(billing.Type)),
+				billing.Namespace,
+				billing.Owner,
+				resources.AppTypeReverse[billing.AppType],
+				billing.AppName,
+				strconv.FormatInt(billing.Amount, 10),
+				strconv.Itoa(int(billing.Status)),
+				billing.Detail,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to write billing %s: %w", billing.OrderID, err)
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, fmt.Errorf("failed to write billing export: %w", err)
+		}
+	default:
+		enc := json.NewEncoder(gz)
+		for i := range billings {
+			if err := enc.Encode(&billings[i]); err != nil {
+				return nil, fmt.Errorf("failed to encode billing %s: %w", billings[i].OrderID, err)
+			}
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress billing export: %w", err)
+	}
+	return buf.Bytes(), nil
+}
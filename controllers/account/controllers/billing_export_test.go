@@ -0,0 +1,223 @@
+/*
+Copyright 2025 sealos.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+
+	"github.com/labring/sealos/controllers/pkg/common"
+	"github.com/labring/sealos/controllers/pkg/resources"
+)
+
+type fakeBillingRecordSource struct {
+	billings []resources.Billing
+}
+
+func (f *fakeBillingRecordSource) GetBillingRecords(startTime, endTime time.Time) ([]resources.Billing, error) {
+	var result []resources.Billing
+	for _, billing := range f.billings {
+		if !billing.Time.Before(startTime) && billing.Time.Before(endTime) {
+			result = append(result, billing)
+		}
+	}
+	return result, nil
+}
+
+type uploadedObject struct {
+	bucket string
+	opts   minio.PutObjectOptions
+	data   []byte
+}
+
+type fakeBillingObjectUploader struct {
+	objects map[string]uploadedObject
+}
+
+func (f *fakeBillingObjectUploader) PutObject(_ context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	if f.objects == nil {
+		f.objects = make(map[string]uploadedObject)
+	}
+	f.objects[objectName] = uploadedObject{bucket: bucketName, opts: opts, data: data}
+	return minio.UploadInfo{Bucket: bucketName, Key: objectName, Size: objectSize}, nil
+}
+
+func gunzipBillingExport(t *testing.T, data []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("export is not gzip compressed: %v", err)
+	}
+	plain, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("failed to decompress export: %v", err)
+	}
+	return plain
+}
+
+func TestBillingExportRunner_ExportWindow(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	source := &fakeBillingRecordSource{billings: []resources.Billing{
+		{Time: start.Add(time.Hour), OrderID: "order-1", Type: common.Consumption, Namespace: "ns-user1", Owner: "user1", AppType: resources.AppType[resources.DB], AppName: "mysql", Amount: 1200},
+		{Time: start.Add(2 * time.Hour), OrderID: "order-2", Type: common.Consumption, Namespace: "ns-user2", Owner: "user2", AppType: resources.AppType[resources.TERMINAL], Amount: 300, Detail: "terminal, with comma"},
+		// outside the window
+		{Time: end, OrderID: "order-3", Type: common.Consumption, Namespace: "ns-user1", Owner: "user1", Amount: 100},
+	}}
+
+	tests := []struct {
+		format string
+		verify func(t *testing.T, plain []byte)
+	}{
+		{
+			format: BillingExportFormatJSON,
+			verify: func(t *testing.T, plain []byte) {
+				var orderIDs []string
+				scanner := bufio.NewScanner(bytes.NewReader(plain))
+				for scanner.Scan() {
+					var billing resources.Billing
+					if err := json.Unmarshal(scanner.Bytes(), &billing); err != nil {
+						t.Fatalf("line %q is not a billing record: %v", scanner.Text(), err)
+					}
+					orderIDs = append(orderIDs, billing.OrderID)
+				}
+				if len(orderIDs) != 2 || orderIDs[0] != "order-1" || orderIDs[1] != "order-2" {
+					t.Errorf("exported orders = %v, want [order-1 order-2]", orderIDs)
+				}
+			},
+		},
+		{
+			format: BillingExportFormatCSV,
+			verify: func(t *testing.T, plain []byte) {
+				records, err := csv.NewReader(bytes.NewReader(plain)).ReadAll()
+				if err != nil {
+					t.Fatalf("export is not valid csv: %v", err)
+				}
+				if len(records) != 3 {
+					t.Fatalf("csv rows = %d, want header and 2 records", len(records))
+				}
+				if records[0][1] != "order_id" {
+					t.Errorf("csv header = %v", records[0])
+				}
+				if got := records[1]; got[1] != "order-1" || got[5] != resources.DB || got[7] != "1200" {
+					t.Errorf("csv record = %v, want order-1 of %s costing 1200", got, resources.DB)
+				}
+				if got := records[2][9]; got != "terminal, with comma" {
+					t.Errorf("csv detail = %q, want the quoted detail", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			uploader := &fakeBillingObjectUploader{}
+			runner := &BillingExportRunner{
+				Logger:   logr.Discard(),
+				DBClient: source,
+				Uploader: uploader,
+				Bucket:   "billing-archive",
+				Interval: 24 * time.Hour,
+				Format:   tt.format,
+			}
+
+			objectName, err := runner.ExportWindow(context.Background(), start, end)
+			if err != nil {
+				t.Fatalf("ExportWindow() error = %v", err)
+			}
+			wantName := "billing/2025-01-01T00-00-00Z_2025-01-02T00-00-00Z." + tt.format + ".gz"
+			if objectName != wantName {
+				t.Errorf("object name = %s, want %s", objectName, wantName)
+			}
+			object, ok := uploader.objects[wantName]
+			if !ok {
+				t.Fatalf("object %s was not uploaded", wantName)
+			}
+			if object.bucket != "billing-archive" || object.opts.ContentEncoding != "gzip" {
+				t.Errorf("uploaded to bucket %s with encoding %q, want billing-archive gzip", object.bucket, object.opts.ContentEncoding)
+			}
+			tt.verify(t, gunzipBillingExport(t, object.data))
+		})
+	}
+}
+
+func TestBillingExportRunner_SkipsEmptyWindow(t *testing.T) {
+	uploader := &fakeBillingObjectUploader{}
+	runner := &BillingExportRunner{
+		Logger:   logr.Discard(),
+		DBClient: &fakeBillingRecordSource{},
+		Uploader: uploader,
+		Bucket:   "billing-archive",
+		Interval: 24 * time.Hour,
+		Format:   BillingExportFormatJSON,
+	}
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	objectName, err := runner.ExportWindow(context.Background(), start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("ExportWindow() error = %v", err)
+	}
+	if objectName != "" || len(uploader.objects) != 0 {
+		t.Errorf("empty window uploaded %v, want nothing", uploader.objects)
+	}
+}
+
+func TestNewBillingExportRunnerFromEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantRunner bool
+		wantErr    bool
+	}{
+		{name: "disabled without bucket"},
+		{
+			name:       "configured",
+			env:        map[string]string{BillingExportBucketEnv: "billing-archive", BillingExportFormatEnv: "csv", BillingExportIntervalEnv: "6h", OSNamespace: "objectstorage-system", OSAdminSecret: "object-storage-sealos-user-0", OSInternalEndpointEnv: "minio.objectstorage-system.svc:80"},
+			wantRunner: true,
+		},
+		{name: "missing object storage env", env: map[string]string{BillingExportBucketEnv: "billing-archive"}, wantErr: true},
+		{name: "unknown format", env: map[string]string{BillingExportBucketEnv: "billing-archive", BillingExportFormatEnv: "xml", OSNamespace: "os", OSAdminSecret: "secret", OSInternalEndpointEnv: "minio:80"}, wantErr: true},
+		{name: "interval too short", env: map[string]string{BillingExportBucketEnv: "billing-archive", BillingExportIntervalEnv: "10m", OSNamespace: "os", OSAdminSecret: "secret", OSInternalEndpointEnv: "minio:80"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{BillingExportBucketEnv, BillingExportFormatEnv, BillingExportIntervalEnv, OSNamespace, OSAdminSecret, OSInternalEndpointEnv} {
+				t.Setenv(key, tt.env[key])
+			}
+			runner, err := NewBillingExportRunnerFromEnv(nil, logr.Discard(), &fakeBillingRecordSource{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewBillingExportRunnerFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (runner != nil) != tt.wantRunner {
+				t.Errorf("NewBillingExportRunnerFromEnv() runner = %v, want runner %v", runner, tt.wantRunner)
+			}
+		})
+	}
+}
@@ -18,9 +18,14 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,7 +34,6 @@ import (
 	"gopkg.in/yaml.v2"
 	
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 	
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
@@ -39,9 +43,14 @@ import (
 
 	//kbv1alpha1 "github.com/apecloud/kubeblocks/apis/apps/v1alpha1"
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 	v1 "github.com/labring/sealos/controllers/account/api/v1"
+	"github.com/labring/sealos/controllers/pkg/resources"
+	"github.com/labring/sealos/controllers/pkg/utils/env"
 	"github.com/minio/madmin-go/v3"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -49,14 +58,19 @@ import (
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	clientretry "k8s.io/client-go/util/retry"
+	"k8s.io/utils/clock"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -66,10 +80,16 @@ type NamespaceReconciler struct {
 	dynamicClient    dynamic.Interface
 	Log              logr.Logger
 	Scheme           *runtime.Scheme
-	OSAdminClient    *madmin.AdminClient
+	OSAdminClient    ObjectStorageUserAdmin
 	OSNamespace      string
 	OSAdminSecret    string
 	InternalEndpoint string
+	// DryRun 演练模式，来自环境变量 SUSPEND_DRY_RUN：暂停时只列出各策略将要修改的资源，不做任何修改
+	DryRun bool
+	// FinalDeletionSnapshot 来自环境变量 FINAL_DELETION_SNAPSHOT：最终删除 PVC 前先为其创建 VolumeSnapshot
+	FinalDeletionSnapshot bool
+	// Clock 时间来源，为空时使用系统时间；用于暂停宽限期、事务时间戳和分布式锁的过期判断
+	Clock clock.PassiveClock
 	
 	// 优化相关字段
 	resourceCache    *ResourceCache
@@ -78,6 +98,31 @@ type NamespaceReconciler struct {
 	metrics          *SuspensionMetrics
 }
 
+// now 返回 Clock 的当前时间，未注入 Clock 时为系统时间
+func (r *NamespaceReconciler) now() time.Time {
+	if r.Clock == nil {
+		return time.Now()
+	}
+	return r.Clock.Now()
+}
+
+// strategyClock 暂停策略的时间来源，由 initializeStrategies 注入 NamespaceReconciler.now
+type strategyClock func() time.Time
+
+// Now 返回注入的当前时间，未注入时为系统时间
+func (c strategyClock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c()
+}
+
+// ObjectStorageUserAdmin 对象存储用户管理接口（madmin.AdminClient 的子集）
+type ObjectStorageUserAdmin interface {
+	ListUsers(ctx context.Context) (map[string]madmin.UserInfo, error)
+	SetUserStatus(ctx context.Context, accessKey string, status madmin.AccountStatus) error
+}
+
 // SuspensionStrategy 暂停策略接口
 type SuspensionStrategy interface {
 	Suspend(ctx context.Context, namespace string) error
@@ -91,6 +136,9 @@ type CertManagerStrategy struct {
 	client        client.Client
 	dynamicClient dynamic.Interface
 	cache         *ResourceCache
+	clock         strategyClock
+	// keepCertificatesActive 为 true 时暂停不处理证书和 Challenge，证书在暂停期间继续续期
+	keepCertificatesActive bool
 }
 
 // NetworkStrategy 网络资源暂停策略
@@ -98,6 +146,9 @@ type NetworkStrategy struct {
 	client        client.Client
 	dynamicClient dynamic.Interface
 	cache         *ResourceCache
+	clock         strategyClock
+	// exemptBudget 按资源类型（如 ingresses）保留的免暂停资源数量
+	exemptBudget map[string]int
 }
 
 // RBACStrategy RBAC权限暂停策略
@@ -106,6 +157,29 @@ type RBACStrategy struct {
 	cache  *ResourceCache
 }
 
+// ScalableStrategy 通用可伸缩CRD暂停策略（如Knative Service），通过合并补丁缩容到零
+type ScalableStrategy struct {
+	dynamicClient dynamic.Interface
+	cache         *ResourceCache
+	clock         strategyClock
+	resources     []ScalableResourceConfig
+}
+
+// WorkloadStrategy Deployment/StatefulSet 暂停策略，记录原副本数后缩容到零，避免控制器重建被删除的 Pod
+type WorkloadStrategy struct {
+	dynamicClient dynamic.Interface
+	cache         *ResourceCache
+	clock         strategyClock
+}
+
+// PVCStrategy PVC暂停策略，把支持的存储类的PVC切换到只读 VolumeAttributesClass，阻止新的写挂载
+type PVCStrategy struct {
+	client          client.Client
+	cache           *ResourceCache
+	clock           strategyClock
+	readOnlyClasses map[string]string // storageClassName -> 只读 VolumeAttributesClass
+}
+
 // ResourceCache 资源状态缓存
 type ResourceCache struct {
 	suspended map[string]map[string]bool // namespace -> resourceType -> suspended
@@ -114,7 +188,8 @@ type ResourceCache struct {
 	lastClean time.Time
 }
 
-// SuspensionTransaction 暂停事务记录
+// SuspensionTransaction 暂停事务记录，暂停过程中持久化到 sealos-system 下的 ConfigMap，
+// 控制器重启后据此回滚未完成的步骤
 type SuspensionTransaction struct {
 	Namespace string    `json:"namespace"`
 	Status    string    `json:"status"`
@@ -122,11 +197,41 @@ type SuspensionTransaction struct {
 	Error     string    `json:"error,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	
+	// mu 保护并行策略对 Steps 的追加及持久化
+	mu sync.Mutex
 }
 
 // SuspensionConfig 暂停配置
 type SuspensionConfig struct {
-	Resources map[string]ResourceConfig `yaml:"resources"`
+	Resources         map[string]ResourceConfig `yaml:"resources"`
+	ScalableResources []ScalableResourceConfig  `yaml:"scalable_resources"`
+	// PVCReadOnlyClasses 存储类到只读 VolumeAttributesClass 的映射，配置后启用PVC暂停策略，
+	// 未列出的存储类不支持只读重挂载，暂停时保持不变
+	PVCReadOnlyClasses map[string]string `yaml:"pvc_read_only_classes"`
+	// DeleteConcurrency 最终删除用户资源时并发执行 DeleteCollection 的上限，
+	// 未配置或小于等于 0 时使用 DefaultDeleteConcurrency
+	DeleteConcurrency int `yaml:"delete_concurrency"`
+	// BlackoutWindows 暂停禁止窗口（如业务高峰时段），窗口内的暂停请求推迟到窗口结束后执行，恢复不受影响
+	BlackoutWindows []BlackoutWindow `yaml:"blackout_windows"`
+	// KeepCertificatesActive 暂停时保留 cert-manager 证书的续期（不标记证书、不删除 Challenge），
+	// 避免长时间暂停导致证书过期，网络路由仍按原逻辑清空
+	KeepCertificatesActive bool `yaml:"keep_certificates_active"`
+	// SuspendExemptBudget 每个租户暂停时保留的免暂停网络资源数量，按资源类型配置，
+	// 例如 {ingresses: 1, services: 1}，使落地页/充值页在暂停期间仍可访问。
+	// 按创建时间（相同时按名称）选出最早的 N 个资源保持不变，未配置的类型全部暂停
+	SuspendExemptBudget map[string]int `yaml:"suspend_exempt_budget"`
+}
+
+// BlackoutWindow 暂停禁止时间窗口
+type BlackoutWindow struct {
+	// Start/End 格式为 HH:MM，End 不晚于 Start 时表示窗口跨越午夜
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Weekdays 窗口生效的星期（按窗口开始时间计算），例如 Mon、Tue，为空表示每天生效
+	Weekdays []string `yaml:"weekdays"`
+	// Timezone IANA 时区，例如 Asia/Shanghai，为空时使用 UTC
+	Timezone string `yaml:"timezone"`
 }
 
 // ResourceConfig 资源配置
@@ -137,6 +242,16 @@ type ResourceConfig struct {
 	BackupSizeLimit  string `yaml:"backup_size_limit"`
 }
 
+// ScalableResourceConfig 可伸缩CRD配置
+type ScalableResourceConfig struct {
+	// GVR 格式为 group/version/resource，例如 serving.knative.dev/v1/services
+	GVR string `yaml:"gvr"`
+	// SuspendPatch 暂停时应用的 JSON 合并补丁，例如 {"spec":{"replicas":0}}
+	SuspendPatch string `yaml:"suspend_patch"`
+	// ResumePatch 恢复时应用的 JSON 合并补丁，为空时恢复暂停前备份的原始值
+	ResumePatch string `yaml:"resume_patch"`
+}
+
 // SuspensionMetrics 暂停操作指标
 type SuspensionMetrics struct {
 	suspensionDuration *prometheus.HistogramVec
@@ -156,24 +271,70 @@ const (
 	OSAdminSecret         = "OSAdminSecret"
 	
 	// 新增的优化相关常量
-	SuspensionConfigMapName = "suspension-config"
-	SuspensionConfigMapKey  = "config.yaml"
-	TransactionInProgress   = "IN_PROGRESS"
-	TransactionCompleted    = "COMPLETED"
-	TransactionFailed       = "FAILED"
-	CacheCleanupInterval    = 10 * time.Minute
-	DefaultCacheTTL         = 5 * time.Minute
-	LockTimeout             = 30 * time.Second
+	SuspensionConfigMapName     = "suspension-config"
+	SuspensionConfigMapKey      = "config.yaml"
+	TransactionInProgress       = "IN_PROGRESS"
+	TransactionCompleted        = "COMPLETED"
+	TransactionFailed           = "FAILED"
+	// TransactionRecordNamespace 暂停事务记录 ConfigMap 所在的命名空间
+	TransactionRecordNamespace  = "sealos-system"
+	TransactionRecordLabel      = "debt.sealos.io/suspension-transaction"
+	TransactionRecordKey        = "transaction.json"
+	// SuspendDryRunEnv 开启暂停演练模式的环境变量
+	SuspendDryRunEnv            = "SUSPEND_DRY_RUN"
+	// FinalDeletionSnapshotEnv 开启最终删除前 PVC 快照的环境变量
+	FinalDeletionSnapshotEnv    = "FINAL_DELETION_SNAPSHOT"
+	CacheCleanupInterval        = 10 * time.Minute
+	DefaultCacheTTL             = 5 * time.Minute
+	LockTimeout                 = 30 * time.Second
+	// LockLeaseDuration 暂停锁 Lease 的有效期：操作最长执行 LockTimeout，另外留出释放锁的时间，
+	// 持有者崩溃后超过有效期的锁可被其他实例强制获取
+	LockLeaseDuration           = LockTimeout + 15*time.Second
+	DefaultDeleteConcurrency    = 4
+	// RestrictedRoleSweepInterval 清理孤立受限角色的默认周期，可通过 RESTRICTED_ROLE_SWEEP_INTERVAL 覆盖
+	RestrictedRoleSweepInterval = 30 * time.Minute
 	
 	// 策略名称
 	StrategyCertManager = "cert-manager"
 	StrategyNetwork     = "network"
 	StrategyRBAC        = "rbac"
+	StrategyScalable    = "scalable"
+	StrategyPVC         = "pvc"
+	StrategyWorkload    = "workload"
+	// StrategyCompute 删除受控 Pod 停止计算资源，只用于应用级策略覆盖
+	StrategyCompute     = "compute"
+	
+	// 策略暂停注解
+	DebtAnnotationPrefix      = "debt.sealos.io/"
+	DebtSuspendedAnnotation   = DebtAnnotationPrefix + "suspended"
+	// DebtSuspendedAtAnnotation 资源被暂停的时间（RFC3339）
+	DebtSuspendedAtAnnotation = DebtAnnotationPrefix + "suspended-at"
+	DebtScaleBackupAnnotation = DebtAnnotationPrefix + "scale-backup"
+	// DebtScaleSuspendedAnnotation/DebtScaleSuspendedAtAnnotation ScalableStrategy 专用的暂停标记和暂停时间，
+	// 与其他策略分开，资源（如 apps/v1 工作负载）同时被 WorkloadStrategy 暂停时互不干扰
+	DebtScaleSuspendedAnnotation   = DebtAnnotationPrefix + "scale-suspended"
+	DebtScaleSuspendedAtAnnotation = DebtAnnotationPrefix + "scale-suspended-at"
+	// DebtOriginalReplicasAnnotation 暂停前 Deployment/StatefulSet 的 spec.replicas
+	DebtOriginalReplicasAnnotation = DebtAnnotationPrefix + "original-replicas"
+	// DebtVolumeAttributesClassBackupAnnotation 暂停前PVC的 VolumeAttributesClass，为空表示未设置
+	DebtVolumeAttributesClassBackupAnnotation = DebtAnnotationPrefix + "original-volume-attributes-class"
+	// DebtBackupChecksumAnnotation 备份数据的 SHA-256 校验和，恢复时校验，防止应用被截断或损坏的备份
+	DebtBackupChecksumAnnotation = DebtAnnotationPrefix + "backup-checksum"
+	// DebtSuspendAtAnnotation RFC3339 时间，设置且在未来时推迟到该时间再执行暂停
+	DebtSuspendAtAnnotation = DebtAnnotationPrefix + "suspend-at"
+	// DebtStrategiesAnnotation 设置在 Deployment/StatefulSet 上，逗号分隔的策略名称（如 network），
+	// 覆盖命名空间级策略集合：该应用的资源只执行列出的 compute/network/pvc 策略，
+	// 其余策略和 debt-limit0 配额仍按命名空间生效
+	DebtStrategiesAnnotation = DebtAnnotationPrefix + "strategies"
+	// FinalDeletionSnapshotLabel 最终删除前创建的 VolumeSnapshot 的标签，值为 true，用于之后查找快照
+	FinalDeletionSnapshotLabel = DebtAnnotationPrefix + "final-deletion-snapshot"
+	// FinalDeletionSourcePVCAnnotation 快照对应的 PVC 名称
+	FinalDeletionSourcePVCAnnotation = DebtAnnotationPrefix + "source-pvc"
 )
 
-// 全局Prometheus指标
+// 全局Prometheus指标，由 RegisterSuspensionMetrics 显式注册
 var (
-	suspensionDuration = promauto.NewHistogramVec(
+	suspensionDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name: "debt_suspension_duration_seconds",
 			Help: "暂停操作耗时",
@@ -182,7 +343,7 @@ var (
 		[]string{"namespace", "operation", "result", "strategy"},
 	)
 	
-	resourceCount = promauto.NewGaugeVec(
+	resourceCount = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "debt_suspended_resources_total",
 			Help: "暂停的资源数量",
@@ -190,7 +351,7 @@ var (
 		[]string{"namespace", "resource_type", "strategy"},
 	)
 	
-	operationTotal = promauto.NewCounterVec(
+	operationTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "debt_operations_total",
 			Help: "暂停/恢复操作总数",
@@ -198,7 +359,7 @@ var (
 		[]string{"operation", "result", "strategy"},
 	)
 	
-	errorTotal = promauto.NewCounterVec(
+	errorTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "debt_errors_total",
 			Help: "暂停/恢复操作错误总数",
@@ -206,6 +367,14 @@ var (
 		[]string{"operation", "error_type", "strategy"},
 	)
 	
+	dryRunResourcesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "debt_dryrun_resources_total",
+			Help: "演练模式下暂停将要修改的资源数量",
+		},
+		[]string{"namespace", "strategy"},
+	)
+	
 	// 默认暂停配置
 	defaultSuspensionConfig = &SuspensionConfig{
 		Resources: map[string]ResourceConfig{
@@ -256,6 +425,7 @@ var (
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=gateways,verbs=get;list;watch;create;update;patch;delete
@@ -271,13 +441,22 @@ var (
 //+kubebuilder:rbac:groups=apps.kubeblocks.io,resources=opsrequests/status,verbs=get;update;watch
 //+kubebuilder:rbac:groups=app.sealos.io,resources=apps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=app.sealos.io,resources=instances,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshotclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
 
 func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := r.Log.WithValues("Namespace", req.Namespace, "Name", req.NamespacedName)
 
 	ns := corev1.Namespace{}
 	if err := r.Client.Get(ctx, req.NamespacedName, &ns); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		if errors.IsNotFound(err) {
+			// 命名空间被强制删除但资源残留时仍需清理外部状态
+			return ctrl.Result{}, r.cleanupMissingNamespace(ctx, req.NamespacedName.Name)
+		}
+		return ctrl.Result{}, err
 	}
 	if ns.Status.Phase == corev1.NamespaceTerminating {
 		logger.V(1).Info("namespace is terminating")
@@ -294,6 +473,7 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if debtStatus == v1.SuspendCompletedDebtNamespaceAnnoStatus ||
 		debtStatus == v1.FinalDeletionCompletedDebtNamespaceAnnoStatus ||
 		debtStatus == v1.ResumeCompletedDebtNamespaceAnnoStatus ||
+		debtStatus == v1.ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus ||
 		debtStatus == v1.TerminateSuspendCompletedDebtNamespaceAnnoStatus {
 		logger.V(1).Info("Skipping completed namespace")
 		return ctrl.Result{}, nil
@@ -301,10 +481,36 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	switch debtStatus {
 	case v1.SuspendDebtNamespaceAnnoStatus, v1.TerminateSuspendDebtNamespaceAnnoStatus:
+		delay, err := suspendDelay(ns.Annotations, r.now())
+		if err != nil {
+			// 注解格式错误时不推迟，按原逻辑立即暂停
+			logger.Error(err, "invalid suspend-at annotation, suspend immediately")
+		}
+		if delay > 0 {
+			logger.V(1).Info("suspension scheduled, requeue", "suspendAt", ns.Annotations[DebtSuspendAtAnnotation], "after", delay)
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+		if r.suspensionConfig == nil {
+			r.suspensionConfig = r.loadSuspensionConfig()
+		}
+		blackout, err := blackoutDelay(r.suspensionConfig.BlackoutWindows, r.now())
+		if err != nil {
+			// 无效的窗口配置被忽略，不影响其他窗口
+			logger.Error(err, "invalid blackout window config")
+		}
+		if blackout > 0 {
+			logger.V(1).Info("suspension deferred by blackout window, requeue", "after", blackout)
+			return ctrl.Result{RequeueAfter: blackout}, nil
+		}
 		if err := r.SuspendUserResource(ctx, req.NamespacedName.Name); err != nil {
 			logger.Error(err, "suspend namespace resources failed")
 			return ctrl.Result{}, err
 		}
+		if r.DryRun {
+			// 演练模式不修改命名空间状态，关闭演练后重启控制器即按原逻辑暂停
+			logger.Info("dry-run suspension finished, namespace status is left unchanged")
+			return ctrl.Result{}, nil
+		}
 		// Update to corresponding completed state
 		newStatus := v1.SuspendCompletedDebtNamespaceAnnoStatus
 		if debtStatus == v1.TerminateSuspendDebtNamespaceAnnoStatus {
@@ -338,6 +544,16 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			logger.Error(err, "update namespace status to ResumeCompleted failed")
 			return ctrl.Result{}, err
 		}
+	case v1.ResumeNetworkOnlyDebtNamespaceAnnoStatus:
+		if err := r.ResumeNetworkOnly(ctx, req.NamespacedName.Name); err != nil {
+			logger.Error(err, "resume namespace network resources failed")
+			return ctrl.Result{}, err
+		}
+		ns.Annotations[v1.DebtNamespaceAnnoStatusKey] = v1.ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus
+		if err := r.Client.Update(ctx, &ns); err != nil {
+			logger.Error(err, "update namespace status to ResumeNetworkOnlyCompleted failed")
+			return ctrl.Result{}, err
+		}
 	case v1.NormalDebtNamespaceAnnoStatus:
 		// No action needed for Normal state
 	default:
@@ -351,6 +567,127 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	return ctrl.Result{}, nil
 }
 
+// suspendDelay 根据 suspend-at 注解计算距离计划暂停时间的剩余时长，未设置或已到期时返回 0
+func suspendDelay(annotations map[string]string, now time.Time) (time.Duration, error) {
+	raw, ok := annotations[DebtSuspendAtAnnotation]
+	if !ok || raw == "" {
+		return 0, nil
+	}
+	suspendAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s %q: %w", DebtSuspendAtAnnotation, raw, err)
+	}
+	if delay := suspendAt.Sub(now); delay > 0 {
+		return delay, nil
+	}
+	return 0, nil
+}
+
+// blackoutDelay 计算 now 所处暂停禁止窗口的剩余时长，不在任何窗口内时返回 0；
+// 多个窗口重叠时取最晚结束的窗口，无效窗口跳过并返回第一个解析错误
+func blackoutDelay(windows []BlackoutWindow, now time.Time) (time.Duration, error) {
+	var delay time.Duration
+	var firstErr error
+	for _, w := range windows {
+		remaining, err := w.remaining(now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if remaining > delay {
+			delay = remaining
+		}
+	}
+	return delay, firstErr
+}
+
+// remaining 返回 now 所在窗口距离结束的时长，不在窗口内时返回 0
+func (w BlackoutWindow) remaining(now time.Time) (time.Duration, error) {
+	loc := time.UTC
+	if w.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return 0, fmt.Errorf("blackout window timezone %q: %w", w.Timezone, err)
+		}
+	}
+	start, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return 0, fmt.Errorf("blackout window start %q: %w", w.Start, err)
+	}
+	end, err := time.Parse("15:04", w.End)
+	if err != nil {
+		return 0, fmt.Errorf("blackout window end %q: %w", w.End, err)
+	}
+	weekdays := make(map[time.Weekday]bool, len(w.Weekdays))
+	for _, day := range w.Weekdays {
+		weekday, ok := weekdayNames[strings.ToLower(day)]
+		if !ok {
+			return 0, fmt.Errorf("blackout window weekday %q is invalid", day)
+		}
+		weekdays[weekday] = true
+	}
+
+	local := now.In(loc)
+	// 同时检查今天和昨天开始的窗口，覆盖跨越午夜的情况
+	for _, offset := range []int{0, -1} {
+		day := local.AddDate(0, 0, offset)
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, loc)
+		windowEnd := time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc)
+		if !windowEnd.After(windowStart) {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+		if len(weekdays) > 0 && !weekdays[windowStart.Weekday()] {
+			continue
+		}
+		if !local.Before(windowStart) && local.Before(windowEnd) {
+			return windowEnd.Sub(local), nil
+		}
+	}
+	return 0, nil
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// cleanupMissingNamespace 命名空间对象已不存在（强制删除、finalizer 卡住）但残留的 debt-limit0 配额
+// 表明其处于暂停或最终删除状态时，按命名空间名称禁用对象存储用户并清理遗留的暂停锁和暂停事务记录
+func (r *NamespaceReconciler) cleanupMissingNamespace(ctx context.Context, namespace string) error {
+	if !strings.HasPrefix(namespace, "ns-") {
+		return nil
+	}
+	quota := &corev1.ResourceQuota{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: DebtLimit0Name, Namespace: namespace}, quota); err != nil {
+		// 没有暂停痕迹，属于正常删除
+		return client.IgnoreNotFound(err)
+	}
+	
+	r.Log.Info("namespace is missing but debt resources remain, cleaning up external state", "namespace", namespace)
+	if err := r.suspendObjectStorage(ctx, namespace); err != nil {
+		return err
+	}
+	for _, operation := range []string{"suspend", "resume"} {
+		lock := &coordinationv1.Lease{}
+		lock.Name = fmt.Sprintf("debt-%s-%s", operation, namespace)
+		lock.Namespace = "sealos-system"
+		if err := r.Client.Delete(ctx, lock); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete lock %s: %w", lock.Name, err)
+		}
+	}
+	if err := r.deleteTransactionRecord(ctx, namespace); err != nil {
+		return fmt.Errorf("failed to delete suspension transaction record: %w", err)
+	}
+	return nil
+}
+
 func (r *NamespaceReconciler) SuspendUserResource(ctx context.Context, namespace string) error {
 	return r.suspendWithLockAndMetrics(ctx, namespace, "suspend")
 }
@@ -360,15 +697,15 @@ func (r *NamespaceReconciler) suspendWithLockAndMetrics(ctx context.Context, nam
 	logger := r.Log.WithValues(
 		"operation", operation,
 		"namespace", namespace,
-		"timestamp", time.Now(),
+		"timestamp", r.now(),
 	)
 	
 	logger.Info("开始资源暂停操作")
 	
 	// 记录开始时间用于指标
-	startTime := time.Now()
+	startTime := r.now()
 	defer func() {
-		duration := time.Since(startTime)
+		duration := r.now().Sub(startTime)
 		logger.Info("资源暂停操作完成", "duration", duration)
 	}()
 	
@@ -388,72 +725,183 @@ func (r *NamespaceReconciler) suspendWithLockAndMetrics(ctx context.Context, nam
 	})
 }
 
-// suspendWithLock 使用简化的锁机制执行暂停操作
+// errLockHeld 锁被其他实例持有且尚未过期
+var errLockHeld = fmt.Errorf("操作正在被其他实例执行")
+
+// suspendWithLock 使用 coordination.k8s.io Lease 作为分布式锁执行暂停操作，
+// 持有者崩溃未释放的锁在 LockLeaseDuration 后过期，可被其他实例强制获取
 func (r *NamespaceReconciler) suspendWithLock(ctx context.Context, namespace string, operation string, fn func(context.Context) error) error {
 	lockName := fmt.Sprintf("debt-%s-%s", operation, namespace)
+	// 同一实例内的多次获取也需要区分持有者，避免释放时误删后来者的锁
+	holder := fmt.Sprintf("namespace-controller-%s-%s", os.Getenv("HOSTNAME"), uuid.NewString())
+	
+	// 尝试获取锁
+	if err := r.acquireLockLease(ctx, lockName, operation, holder); err != nil {
+		if err == errLockHeld {
+			r.Log.Info("操作正在被其他实例执行", "namespace", namespace, "operation", operation)
+		}
+		return err
+	}
+	
+	// 确保释放锁
+	defer r.releaseLockLease(lockName, holder)
 	
-	// 使用ConfigMap作为分布式锁
-	lockConfigMap := &corev1.ConfigMap{
+	// 设置超时
+	ctx, cancel := context.WithTimeout(ctx, LockTimeout)
+	defer cancel()
+	
+	// 执行操作
+	return fn(ctx)
+}
+
+// acquireLockLease 创建锁 Lease；Lease 已存在且持有者超过有效期未续约时强制获取
+func (r *NamespaceReconciler) acquireLockLease(ctx context.Context, lockName, operation, holder string) error {
+	now := v12.NewMicroTime(r.now())
+	duration := int32(LockLeaseDuration / time.Second)
+	lease := &coordinationv1.Lease{
 		ObjectMeta: v12.ObjectMeta{
 			Name:      lockName,
 			Namespace: "sealos-system",
 			Labels: map[string]string{
-				"debt.sealos.io/lock": "true",
+				"debt.sealos.io/lock":      "true",
 				"debt.sealos.io/operation": operation,
 			},
 		},
-		Data: map[string]string{
-			"holder":    fmt.Sprintf("namespace-controller-%s", os.Getenv("HOSTNAME")),
-			"timestamp": time.Now().Format(time.RFC3339),
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			AcquireTime:          &now,
+			RenewTime:            &now,
 		},
 	}
 	
-	// 尝试获取锁
-	err := r.Client.Create(ctx, lockConfigMap)
-	if err != nil {
-		if errors.IsAlreadyExists(err) {
-			// 锁已被其他实例持有
-			r.Log.Info("操作正在被其他实例执行", "namespace", namespace, "operation", operation)
-			return fmt.Errorf("操作正在被其他实例执行")
-		}
+	err := r.Client.Create(ctx, lease)
+	if err == nil {
+		return nil
+	}
+	if !errors.IsAlreadyExists(err) {
 		return fmt.Errorf("创建分布式锁失败: %w", err)
 	}
 	
-	// 确保释放锁
-	defer func() {
-		if deleteErr := r.Client.Delete(context.Background(), lockConfigMap); deleteErr != nil {
-			r.Log.Error(deleteErr, "释放分布式锁失败", "lockName", lockName)
-		}
-	}()
-	
-	// 设置超时
-	ctx, cancel := context.WithTimeout(ctx, LockTimeout)
-	defer cancel()
+	existing := &coordinationv1.Lease{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(lease), existing); err != nil {
+		return fmt.Errorf("获取分布式锁失败: %w", err)
+	}
+	if !leaseExpired(existing, now.Time) {
+		return errLockHeld
+	}
 	
-	// 执行操作
-	return fn(ctx)
+	// 持有者超过有效期未释放（如实例崩溃），强制获取锁；按 resourceVersion 更新，并发获取时只有一个实例成功
+	previousHolder := ptr.Deref(existing.Spec.HolderIdentity, "")
+	transitions := ptr.Deref(existing.Spec.LeaseTransitions, 0) + 1
+	existing.Labels = lease.Labels
+	existing.Spec = lease.Spec
+	existing.Spec.LeaseTransitions = &transitions
+	if err := r.Client.Update(ctx, existing); err != nil {
+		if errors.IsConflict(err) {
+			return errLockHeld
+		}
+		return fmt.Errorf("强制获取分布式锁失败: %w", err)
+	}
+	r.Log.Info("强制获取已过期的分布式锁", "lockName", lockName, "previousHolder", previousHolder)
+	return nil
+}
+
+// leaseExpired 锁没有持有者，或持有者超过有效期未续约
+func leaseExpired(lease *coordinationv1.Lease, now time.Time) bool {
+	if ptr.Deref(lease.Spec.HolderIdentity, "") == "" || lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	return now.After(lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// releaseLockLease 释放锁，只删除自己持有的 Lease，避免误删过期后被其他实例获取的锁
+func (r *NamespaceReconciler) releaseLockLease(lockName, holder string) {
+	ctx := context.Background()
+	lease := &coordinationv1.Lease{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: lockName, Namespace: "sealos-system"}, lease); err != nil {
+		if !errors.IsNotFound(err) {
+			r.Log.Error(err, "释放分布式锁失败", "lockName", lockName)
+		}
+		return
+	}
+	if ptr.Deref(lease.Spec.HolderIdentity, "") != holder {
+		r.Log.Info("分布式锁已过期并被其他实例获取，跳过释放", "lockName", lockName)
+		return
+	}
+	if err := r.Client.Delete(ctx, lease, client.Preconditions{ResourceVersion: &lease.ResourceVersion}); client.IgnoreNotFound(err) != nil {
+		r.Log.Error(err, "释放分布式锁失败", "lockName", lockName)
+	}
 }
 
 // suspendResourcesWithTransaction 事务性暂停资源
 func (r *NamespaceReconciler) suspendResourcesWithTransaction(ctx context.Context, namespace string) error {
+	if r.DryRun {
+		_, err := r.dryRunSuspension(ctx, namespace)
+		return err
+	}
+	
 	txn := &SuspensionTransaction{
 		Namespace: namespace,
 		Status:    TransactionInProgress,
 		Steps:     []string{},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: r.now(),
+		UpdatedAt: r.now(),
+	}
+	if err := r.saveTransaction(ctx, txn); err != nil {
+		return err
 	}
 	
 	defer func() {
 		if txn.Status != TransactionCompleted {
 			r.rollbackTransaction(ctx, txn)
 		}
+		// 已完成或已回滚，不再需要恢复；删除失败时重启后会再次回滚，Resume 是幂等的
+		if err := r.deleteTransactionRecord(ctx, namespace); err != nil {
+			r.Log.Error(err, "删除暂停事务记录失败", "namespace", namespace)
+		}
 	}()
 	
 	// 使用策略模式并行执行
 	return r.executeSuspensionStrategies(ctx, namespace, txn)
 }
 
+// dryRunSuspension 演练暂停：各策略只列出将要修改的资源并记录到事务步骤，不修改资源、不持久化事务
+func (r *NamespaceReconciler) dryRunSuspension(ctx context.Context, namespace string) (*SuspensionTransaction, error) {
+	txn := &SuspensionTransaction{
+		Namespace: namespace,
+		Status:    TransactionInProgress,
+		Steps:     []string{},
+		CreatedAt: r.now(),
+		UpdatedAt: r.now(),
+	}
+	report := newDryRunReport()
+	if err := r.executeSuspensionStrategies(withDryRunReport(ctx, report), namespace, txn); err != nil {
+		return txn, err
+	}
+	
+	for _, strategy := range r.strategies {
+		resources := report.list(strategy.GetName())
+		dryRunResourcesTotal.WithLabelValues(namespace, strategy.GetName()).Add(float64(len(resources)))
+		r.Log.Info("演练暂停：策略将要修改的资源", "namespace", namespace, "strategy", strategy.GetName(),
+			"count", len(resources), "resources", resources)
+	}
+	return txn, nil
+}
+
+// recordStrategyStep 记录策略已完成；演练模式下改为记录该策略将要修改的资源，这些步骤不会被回滚
+func (r *NamespaceReconciler) recordStrategyStep(ctx context.Context, txn *SuspensionTransaction, strategy string) error {
+	if report := dryRunReportFrom(ctx); report != nil {
+		txn.mu.Lock()
+		defer txn.mu.Unlock()
+		for _, resource := range report.list(strategy) {
+			txn.Steps = append(txn.Steps, fmt.Sprintf("%s_dryrun:%s", strategy, resource))
+		}
+		return nil
+	}
+	return r.recordTransactionStep(ctx, txn, fmt.Sprintf("%s_suspended", strategy))
+}
+
 // executeSuspensionStrategies 执行暂停策略
 func (r *NamespaceReconciler) executeSuspensionStrategies(ctx context.Context, namespace string, txn *SuspensionTransaction) error {
 	// 初始化策略
@@ -461,12 +909,21 @@ func (r *NamespaceReconciler) executeSuspensionStrategies(ctx context.Context, n
 		r.initializeStrategies()
 	}
 	
+	// 加载应用级策略覆盖，通过 context 传递给各策略和原有暂停函数
+	overrides, err := r.loadAppStrategyOverrides(ctx, namespace)
+	if err != nil {
+		txn.Status = TransactionFailed
+		txn.Error = err.Error()
+		return err
+	}
+	ctx = withAppStrategyOverrides(ctx, overrides)
+	
 	// 并行执行策略 - 第一阶段：cert-manager和网络资源（可并行）
 	g1, ctx1 := errgroup.WithContext(ctx)
 	
 	for _, strategy := range r.strategies {
 		strategy := strategy // 避免闭包变量问题
-		if strategy.GetName() == StrategyCertManager || strategy.GetName() == StrategyNetwork {
+		if strategy.GetName() == StrategyCertManager || strategy.GetName() == StrategyNetwork || strategy.GetName() == StrategyScalable || strategy.GetName() == StrategyPVC || strategy.GetName() == StrategyWorkload {
 			g1.Go(func() error {
 				timer := prometheus.NewTimer(suspensionDuration.WithLabelValues(namespace, "suspend", "", strategy.GetName()))
 				defer timer.ObserveDuration()
@@ -481,7 +938,7 @@ func (r *NamespaceReconciler) executeSuspensionStrategies(ctx context.Context, n
 				operationTotal.WithLabelValues("suspend", result, strategy.GetName()).Inc()
 				
 				if err == nil {
-					txn.Steps = append(txn.Steps, fmt.Sprintf("%s_suspended", strategy.GetName()))
+					err = r.recordStrategyStep(ctx1, txn, strategy.GetName())
 				}
 				
 				return err
@@ -509,13 +966,23 @@ func (r *NamespaceReconciler) executeSuspensionStrategies(ctx context.Context, n
 				return err
 			}
 			
-			txn.Steps = append(txn.Steps, fmt.Sprintf("%s_suspended", strategy.GetName()))
+			if err := r.recordStrategyStep(ctx, txn, strategy.GetName()); err != nil {
+				txn.Status = TransactionFailed
+				txn.Error = err.Error()
+				return err
+			}
 			operationTotal.WithLabelValues("suspend", "success", strategy.GetName()).Inc()
 			break
 		}
 	}
 	
-	// 第三阶段：其他原有功能（保持向后兼容）
+	// 第三阶段：其他原有功能（保持向后兼容），未接入策略，演练模式下不执行
+	if isDryRun(ctx) {
+		r.Log.Info("演练模式跳过数据库、CronJob、对象存储等原有暂停步骤", "namespace", namespace)
+		txn.Status = TransactionCompleted
+		txn.UpdatedAt = r.now()
+		return nil
+	}
 	g2, ctx2 := errgroup.WithContext(ctx)
 	
 	legacyFunctions := []func(context.Context, string) error{
@@ -541,31 +1008,133 @@ func (r *NamespaceReconciler) executeSuspensionStrategies(ctx context.Context, n
 	}
 	
 	txn.Status = TransactionCompleted
-	txn.UpdatedAt = time.Now()
+	txn.UpdatedAt = r.now()
 	return nil
 }
 
-func (r *NamespaceReconciler) DeleteUserResource(_ context.Context, namespace string) error {
+func (r *NamespaceReconciler) DeleteUserResource(ctx context.Context, namespace string) error {
+	// PVC 删除不可逆，开启后先创建快照再删除
+	if r.FinalDeletionSnapshot {
+		if err := r.snapshotPVCsBeforeDeletion(ctx, namespace); err != nil {
+			return err
+		}
+	}
+	
 	deleteResources := []string{
 		"backup", "cluster.apps.kubeblocks.io", "backupschedules", "devboxes", "devboxreleases", "cronjob",
 		"objectstorageuser", "deploy", "sts", "pvc", "Service", "Ingress",
 		"Issuer", "Certificate", "HorizontalPodAutoscaler", "instance",
 		"job", "app",
 	}
-	errChan := make(chan error, len(deleteResources))
+	// 限制并发，避免同时对大量 GVR 执行 DeleteCollection 导致 API Server 负载突增
+	var g errgroup.Group
+	g.SetLimit(r.deleteConcurrency())
 	for _, rs := range deleteResources {
-		go func(resource string) {
-			errChan <- deleteResource(r.dynamicClient, resource, namespace)
-		}(rs)
+		resource := rs
+		g.Go(func() error {
+			return deleteResource(r.dynamicClient, resource, namespace)
+		})
 	}
-	for range deleteResources {
-		if err := <-errChan; err != nil {
-			return err
+	return g.Wait()
+}
+
+var (
+	pvcGVR                 = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}
+	storageClassGVR        = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	volumeSnapshotGVR      = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+	volumeSnapshotClassGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshotclasses"}
+)
+
+// snapshotPVCsBeforeDeletion 为存储类支持快照的 PVC 创建 VolumeSnapshot，不支持的 PVC 记录日志后跳过。
+// 快照由 snapshot-controller 异步完成，完成前源 PVC 受其 finalizer 保护，随后的删除不会丢失数据
+func (r *NamespaceReconciler) snapshotPVCsBeforeDeletion(ctx context.Context, namespace string) error {
+	logger := r.Log.WithValues("operation", "final-deletion-snapshot", "namespace", namespace)
+	
+	pvcs, err := r.dynamicClient.Resource(pvcGVR).Namespace(namespace).List(ctx, v12.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pvcs: %w", err)
+	}
+	if len(pvcs.Items) == 0 {
+		return nil
+	}
+	
+	snapshotClasses, err := r.snapshotClassesByStorageClass(ctx)
+	if errors.IsNotFound(err) {
+		logger.Info("集群未安装 VolumeSnapshot CRD，跳过删除前快照")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	
+	for _, pvc := range pvcs.Items {
+		storageClass, _, _ := unstructured.NestedString(pvc.Object, "spec", "storageClassName")
+		snapshotClass, ok := snapshotClasses[storageClass]
+		if !ok {
+			logger.Info("PVC 的存储类不支持快照，跳过", "pvc", pvc.GetName(), "storageClass", storageClass)
+			continue
 		}
+		
+		// 名称固定，删除失败重试时不会重复创建快照
+		snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"volumeSnapshotClassName": snapshotClass,
+				"source":                  map[string]interface{}{"persistentVolumeClaimName": pvc.GetName()},
+			},
+		}}
+		snapshot.SetAPIVersion("snapshot.storage.k8s.io/v1")
+		snapshot.SetKind("VolumeSnapshot")
+		snapshot.SetName(fmt.Sprintf("%s-final-deletion", pvc.GetName()))
+		snapshot.SetNamespace(namespace)
+		snapshot.SetLabels(map[string]string{FinalDeletionSnapshotLabel: "true"})
+		snapshot.SetAnnotations(map[string]string{FinalDeletionSourcePVCAnnotation: pvc.GetName()})
+		if _, err := r.dynamicClient.Resource(volumeSnapshotGVR).Namespace(namespace).Create(ctx, snapshot, v12.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create volumesnapshot for pvc %s: %w", pvc.GetName(), err)
+		}
+		logger.Info("已为 PVC 创建删除前快照", "pvc", pvc.GetName(), "snapshot", snapshot.GetName(), "snapshotClass", snapshotClass)
 	}
 	return nil
 }
 
+// snapshotClassesByStorageClass 返回存储类名称 -> VolumeSnapshotClass 名称，快照类的驱动与存储类 provisioner 一致时支持快照，
+// 同一驱动有多个快照类时优先使用默认快照类
+func (r *NamespaceReconciler) snapshotClassesByStorageClass(ctx context.Context) (map[string]string, error) {
+	classes, err := r.dynamicClient.Resource(volumeSnapshotClassGVR).List(ctx, v12.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	byDriver := make(map[string]string)
+	for _, class := range classes.Items {
+		driver, _, _ := unstructured.NestedString(class.Object, "driver")
+		if _, exists := byDriver[driver]; !exists || class.GetAnnotations()["snapshot.storage.kubernetes.io/is-default-class"] == "true" {
+			byDriver[driver] = class.GetName()
+		}
+	}
+	
+	storageClasses, err := r.dynamicClient.Resource(storageClassGVR).List(ctx, v12.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storageclasses: %w", err)
+	}
+	result := make(map[string]string)
+	for _, sc := range storageClasses.Items {
+		provisioner, _, _ := unstructured.NestedString(sc.Object, "provisioner")
+		if class, ok := byDriver[provisioner]; ok {
+			result[sc.GetName()] = class
+		}
+	}
+	return result, nil
+}
+
+// deleteConcurrency 获取删除用户资源的并发上限
+func (r *NamespaceReconciler) deleteConcurrency() int {
+	if r.suspensionConfig == nil {
+		r.suspensionConfig = r.loadSuspensionConfig()
+	}
+	if r.suspensionConfig.DeleteConcurrency > 0 {
+		return r.suspensionConfig.DeleteConcurrency
+	}
+	return DefaultDeleteConcurrency
+}
+
 func (r *NamespaceReconciler) ResumeUserResource(ctx context.Context, namespace string) error {
 	return r.resumeWithLockAndMetrics(ctx, namespace, "resume")
 }
@@ -575,15 +1144,15 @@ func (r *NamespaceReconciler) resumeWithLockAndMetrics(ctx context.Context, name
 	logger := r.Log.WithValues(
 		"operation", operation,
 		"namespace", namespace,
-		"timestamp", time.Now(),
+		"timestamp", r.now(),
 	)
 	
 	logger.Info("开始资源恢复操作")
 	
 	// 记录开始时间用于指标
-	startTime := time.Now()
+	startTime := r.now()
 	defer func() {
-		duration := time.Since(startTime)
+		duration := r.now().Sub(startTime)
 		logger.Info("资源恢复操作完成", "duration", duration)
 	}()
 	
@@ -609,8 +1178,8 @@ func (r *NamespaceReconciler) resumeResourcesWithTransaction(ctx context.Context
 		Namespace: namespace,
 		Status:    TransactionInProgress,
 		Steps:     []string{},
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: r.now(),
+		UpdatedAt: r.now(),
 	}
 	
 	defer func() {
@@ -645,7 +1214,7 @@ func (r *NamespaceReconciler) executeResumeStrategies(ctx context.Context, names
 				return err
 			}
 			
-			txn.Steps = append(txn.Steps, fmt.Sprintf("%s_resumed", strategy.GetName()))
+			txn.addStep(fmt.Sprintf("%s_resumed", strategy.GetName()))
 			operationTotal.WithLabelValues("resume", "success", strategy.GetName()).Inc()
 			break
 		}
@@ -656,7 +1225,7 @@ func (r *NamespaceReconciler) executeResumeStrategies(ctx context.Context, names
 	
 	for _, strategy := range r.strategies {
 		strategy := strategy // 避免闭包变量问题
-		if strategy.GetName() == StrategyCertManager || strategy.GetName() == StrategyNetwork {
+		if strategy.GetName() == StrategyCertManager || strategy.GetName() == StrategyNetwork || strategy.GetName() == StrategyScalable || strategy.GetName() == StrategyPVC || strategy.GetName() == StrategyWorkload {
 			g.Go(func() error {
 				timer := prometheus.NewTimer(suspensionDuration.WithLabelValues(namespace, "resume", "", strategy.GetName()))
 				defer timer.ObserveDuration()
@@ -671,7 +1240,8 @@ func (r *NamespaceReconciler) executeResumeStrategies(ctx context.Context, names
 				operationTotal.WithLabelValues("resume", result, strategy.GetName()).Inc()
 				
 				if err == nil {
-					txn.Steps = append(txn.Steps, fmt.Sprintf("%s_resumed", strategy.GetName()))
+					// 多个策略并行恢复，通过事务锁追加步骤
+					txn.addStep(fmt.Sprintf("%s_resumed", strategy.GetName()))
 				}
 				
 				return err
@@ -708,36 +1278,122 @@ func (r *NamespaceReconciler) executeResumeStrategies(ctx context.Context, names
 	}
 	
 	txn.Status = TransactionCompleted
-	txn.UpdatedAt = time.Now()
+	txn.UpdatedAt = r.now()
 	return nil
 }
 
-func (r *NamespaceReconciler) limitResourceQuotaCreate(ctx context.Context, namespace string) error {
-	limitQuota := GetLimit0ResourceQuota(namespace)
-	_, err := ctrl.CreateOrUpdate(ctx, r.Client, limitQuota, func() error {
-		return nil
+// ResumeNetworkOnly 只恢复网络路由（Service/Gateway/Ingress/VirtualService 及证书），
+// KB 集群保持停止、limit-0 配额和受限角色保留，之后仍需通过 Resume 完整恢复
+func (r *NamespaceReconciler) ResumeNetworkOnly(ctx context.Context, namespace string) error {
+	const operation = "resume-network"
+	logger := r.Log.WithValues("operation", operation, "namespace", namespace)
+	logger.Info("开始恢复网络资源")
+	
+	// 网络资源按暂停注解逐个恢复，本身幂等，无需检查命名空间暂停状态
+	return r.suspendWithLock(ctx, namespace, operation, func(ctx context.Context) error {
+		return r.executeNetworkResumeStrategies(ctx, namespace, operation)
 	})
-	return err
 }
 
-func (r *NamespaceReconciler) limitResourceQuotaDelete(ctx context.Context, namespace string) error {
-	limitQuota := GetLimit0ResourceQuota(namespace)
-	err := r.Client.Delete(ctx, limitQuota)
-	return client.IgnoreNotFound(err)
-}
-
-func GetLimit0ResourceQuota(namespace string) *corev1.ResourceQuota {
-	quota := corev1.ResourceQuota{}
-	quota.Name = "debt-limit0"
-	quota.Namespace = namespace
-	quota.Spec.Hard = corev1.ResourceList{
-		corev1.ResourceLimitsCPU:        resource.MustParse("0"),
-		corev1.ResourceLimitsMemory:     resource.MustParse("0"),
-		corev1.ResourceRequestsStorage:  resource.MustParse("0"),
-		corev1.ResourceEphemeralStorage: resource.MustParse("0"),
-		"services.loadbalancers":        resource.MustParse("0"), // 强制禁止 LoadBalancer 服务
+// ResumeUserResourceByStrategy 只执行指定策略的恢复（如只恢复 network 让用户拉取备份），其他策略保持暂停。
+// 命名空间整体仍处于暂停状态，之后仍需通过 Resume 完整恢复
+func (r *NamespaceReconciler) ResumeUserResourceByStrategy(ctx context.Context, namespace, strategyName string) error {
+	const operation = "resume-strategy"
+	logger := r.Log.WithValues("operation", operation, "namespace", namespace, "strategy", strategyName)
+	
+	// 初始化策略
+	if len(r.strategies) == 0 {
+		r.initializeStrategies()
 	}
-	return &quota
+	var strategy SuspensionStrategy
+	names := make([]string, 0, len(r.strategies))
+	for _, s := range r.strategies {
+		if s.GetName() == strategyName {
+			strategy = s
+		}
+		names = append(names, s.GetName())
+	}
+	if strategy == nil {
+		return fmt.Errorf("unknown suspension strategy %q, registered strategies: %s", strategyName, strings.Join(names, ", "))
+	}
+	logger.Info("开始按策略恢复资源")
+	
+	// 不检查命名空间级的暂停状态：部分恢复后命名空间仍是暂停状态，且缓存中的 all 状态不能反映单个策略，
+	// 各策略按资源上的暂停标记恢复，本身幂等
+	return r.suspendWithLock(ctx, namespace, operation, func(ctx context.Context) error {
+		timer := prometheus.NewTimer(suspensionDuration.WithLabelValues(namespace, operation, "", strategy.GetName()))
+		defer timer.ObserveDuration()
+		
+		// 策略的 Resume 只更新自身在缓存中的状态，其他策略的缓存保持不变
+		err := strategy.Resume(ctx, namespace)
+		
+		result := "success"
+		if err != nil {
+			result = "error"
+			errorTotal.WithLabelValues(operation, "strategy_execution", strategy.GetName()).Inc()
+		}
+		operationTotal.WithLabelValues(operation, result, strategy.GetName()).Inc()
+		return err
+	})
+}
+
+// executeNetworkResumeStrategies 并行执行cert-manager和网络资源的恢复策略，不恢复计算资源
+func (r *NamespaceReconciler) executeNetworkResumeStrategies(ctx context.Context, namespace string, operation string) error {
+	// 初始化策略
+	if len(r.strategies) == 0 {
+		r.initializeStrategies()
+	}
+	
+	g, ctx := errgroup.WithContext(ctx)
+	for _, strategy := range r.strategies {
+		strategy := strategy // 避免闭包变量问题
+		if strategy.GetName() != StrategyCertManager && strategy.GetName() != StrategyNetwork {
+			continue
+		}
+		g.Go(func() error {
+			timer := prometheus.NewTimer(suspensionDuration.WithLabelValues(namespace, operation, "", strategy.GetName()))
+			defer timer.ObserveDuration()
+			
+			err := strategy.Resume(ctx, namespace)
+			
+			result := "success"
+			if err != nil {
+				result = "error"
+				errorTotal.WithLabelValues(operation, "strategy_execution", strategy.GetName()).Inc()
+			}
+			operationTotal.WithLabelValues(operation, result, strategy.GetName()).Inc()
+			return err
+		})
+	}
+	return g.Wait()
+}
+
+func (r *NamespaceReconciler) limitResourceQuotaCreate(ctx context.Context, namespace string) error {
+	limitQuota := GetLimit0ResourceQuota(namespace)
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, limitQuota, func() error {
+		return nil
+	})
+	return err
+}
+
+func (r *NamespaceReconciler) limitResourceQuotaDelete(ctx context.Context, namespace string) error {
+	limitQuota := GetLimit0ResourceQuota(namespace)
+	err := r.Client.Delete(ctx, limitQuota)
+	return client.IgnoreNotFound(err)
+}
+
+func GetLimit0ResourceQuota(namespace string) *corev1.ResourceQuota {
+	quota := corev1.ResourceQuota{}
+	quota.Name = "debt-limit0"
+	quota.Namespace = namespace
+	quota.Spec.Hard = corev1.ResourceList{
+		corev1.ResourceLimitsCPU:        resource.MustParse("0"),
+		corev1.ResourceLimitsMemory:     resource.MustParse("0"),
+		corev1.ResourceRequestsStorage:  resource.MustParse("0"),
+		corev1.ResourceEphemeralStorage: resource.MustParse("0"),
+		"services.loadbalancers":        resource.MustParse("0"), // 强制禁止 LoadBalancer 服务
+	}
+	return &quota
 }
 
 func (r *NamespaceReconciler) suspendKBCluster(ctx context.Context, namespace string) error {
@@ -782,7 +1438,7 @@ func (r *NamespaceReconciler) suspendKBCluster(ctx context.Context, namespace st
 		}
 
 		// Create OpsRequest resource
-		opsName := fmt.Sprintf("stop-%s-%s", clusterName, time.Now().Format("2006-01-02-15"))
+		opsName := fmt.Sprintf("stop-%s-%s", clusterName, r.now().Format("2006-01-02-15"))
 		opsRequest := &unstructured.Unstructured{}
 		opsRequest.SetGroupVersionKind(schema.GroupVersionKind{
 			Group:   "apps.kubeblocks.io",
@@ -872,11 +1528,16 @@ func (r *NamespaceReconciler) deleteControlledPod(ctx context.Context, namespace
 	if err := r.Client.List(ctx, &podList, client.InNamespace(namespace)); err != nil {
 		return err
 	}
+	overrides := appStrategyOverridesFrom(ctx)
 	for _, pod := range podList.Items {
 		if pod.Spec.SchedulerName == v1.DebtSchedulerName || len(pod.ObjectMeta.OwnerReferences) == 0 {
 			r.Log.Info("skip pod", "pod", pod.Name)
 			continue
 		}
+		if !overrides.Applies(pod.Labels, StrategyCompute) {
+			r.Log.Info("skip pod of app without compute strategy", "pod", pod.Name)
+			continue
+		}
 		r.Log.Info("delete pod", "pod", pod.Name)
 		err := r.Client.Delete(ctx, &pod)
 		if err != nil {
@@ -1006,11 +1667,32 @@ func (r *NamespaceReconciler) setOSUserStatus(ctx context.Context, user string,
 	return nil
 }
 
+// RegisterSuspensionMetrics 注册暂停/恢复指标，重复注册（多个控制器或测试共用同一进程）时忽略
+func RegisterSuspensionMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{suspensionDuration, resourceCount, operationTotal, errorTotal, dryRunResourcesTotal} {
+		if err := registerer.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+				continue
+			}
+			return fmt.Errorf("failed to register suspension metrics: %w", err)
+		}
+	}
+	return nil
+}
+
 func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager, limitOps controller.Options) error {
 	r.Log = ctrl.Log.WithName("controllers").WithName("Namespace")
+	if err := RegisterSuspensionMetrics(metrics.Registry); err != nil {
+		return err
+	}
 	r.OSAdminSecret = os.Getenv(OSAdminSecret)
 	r.InternalEndpoint = os.Getenv(OSInternalEndpointEnv)
 	r.OSNamespace = os.Getenv(OSNamespace)
+	r.DryRun = env.GetBoolWithDefault(SuspendDryRunEnv, false)
+	r.FinalDeletionSnapshot = env.GetBoolWithDefault(FinalDeletionSnapshotEnv, false)
+	if r.DryRun {
+		r.Log.Info("suspension dry-run is enabled, namespaces will not be suspended")
+	}
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load in-cluster config: %v", err)
@@ -1023,6 +1705,13 @@ func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager, limitOps contro
 	if r.OSAdminSecret == "" || r.InternalEndpoint == "" || r.OSNamespace == "" {
 		r.Log.V(1).Info("failed to get the endpoint or namespace or admin secret env of object storage")
 	}
+	if err := mgr.Add(manager.RunnableFunc(r.runRestrictedRoleSweep)); err != nil {
+		return fmt.Errorf("add restricted role sweep failed: %w", err)
+	}
+	// 缓存启动后才能读取事务记录，因此以 Runnable 的方式执行恢复
+	if err := mgr.Add(manager.RunnableFunc(r.runTransactionRecovery)); err != nil {
+		return fmt.Errorf("add suspension transaction recovery failed: %w", err)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Namespace{}, builder.WithPredicates(AnnotationChangedPredicate{})).
 		WithEventFilter(&AnnotationChangedPredicate{}).
@@ -1045,6 +1734,7 @@ func (AnnotationChangedPredicate) Update(e event.UpdateEvent) bool {
 	return oldStatus != newStatus && newStatus != v1.SuspendCompletedDebtNamespaceAnnoStatus &&
 		newStatus != v1.FinalDeletionCompletedDebtNamespaceAnnoStatus &&
 		newStatus != v1.ResumeCompletedDebtNamespaceAnnoStatus &&
+		newStatus != v1.ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus &&
 		newStatus != v1.TerminateSuspendCompletedDebtNamespaceAnnoStatus
 }
 
@@ -1054,6 +1744,7 @@ func (AnnotationChangedPredicate) Create(e event.CreateEvent) bool {
 		status != v1.SuspendCompletedDebtNamespaceAnnoStatus &&
 		status != v1.FinalDeletionCompletedDebtNamespaceAnnoStatus &&
 		status != v1.ResumeCompletedDebtNamespaceAnnoStatus &&
+		status != v1.ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus &&
 		status != v1.TerminateSuspendCompletedDebtNamespaceAnnoStatus
 }
 
@@ -1198,7 +1889,7 @@ func (r *NamespaceReconciler) backupRoleBinding(ctx context.Context, rb *rbacv1.
 	backupData := map[string]interface{}{
 		"originalRoleRef": rb.RoleRef,
 		"subjects":       rb.Subjects,
-		"backupTime":     time.Now().Format(time.RFC3339),
+		"backupTime":     r.now().Format(time.RFC3339),
 	}
 	
 	backupJSON, err := json.Marshal(backupData)
@@ -1232,7 +1923,7 @@ func (r *NamespaceReconciler) backupRoleBinding(ctx context.Context, rb *rbacv1.
 	}
 	rb.Annotations["sealos.io/debt-suspended"] = "true"
 	rb.Annotations["sealos.io/debt-backup-configmap"] = configMapName
-	rb.Annotations["sealos.io/debt-suspended-time"] = time.Now().Format(time.RFC3339)
+	rb.Annotations["sealos.io/debt-suspended-time"] = r.now().Format(time.RFC3339)
 	
 	return nil
 }
@@ -1252,7 +1943,7 @@ func (r *NamespaceReconciler) createRestrictedPermissions(ctx context.Context, r
 			},
 			Annotations: map[string]string{
 				"sealos.io/restriction-reason": "Account debt suspension",
-				"sealos.io/created-time":       time.Now().Format(time.RFC3339),
+				"sealos.io/created-time":       r.now().Format(time.RFC3339),
 			},
 		},
 	}
@@ -1378,6 +2069,71 @@ func (r *NamespaceReconciler) cleanupRestrictedRole(ctx context.Context, rb *rba
 	return nil
 }
 
+// runRestrictedRoleSweep 周期性清理恢复失败后遗留的受限角色
+func (r *NamespaceReconciler) runRestrictedRoleSweep(ctx context.Context) error {
+	ticker := time.NewTicker(env.GetDurationEnvWithDefault("RESTRICTED_ROLE_SWEEP_INTERVAL", RestrictedRoleSweepInterval))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.sweepOrphanedRestrictedRoles(ctx); err != nil {
+				r.Log.Error(err, "清理孤立受限角色失败")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// sweepOrphanedRestrictedRoles 删除已不处于暂停状态的命名空间中遗留的受限角色，
+// 包括 RBACStrategy 创建的 debt-restricted-role 和旧逻辑按 RoleBinding 创建的 debt-restricted-<role>
+func (r *NamespaceReconciler) sweepOrphanedRestrictedRoles(ctx context.Context) error {
+	var roles []rbacv1.Role
+	for _, label := range []string{"debt.sealos.io/restricted", "sealos.io/debt-restricted"} {
+		roleList := &rbacv1.RoleList{}
+		if err := r.Client.List(ctx, roleList, client.MatchingLabels{label: "true"}); err != nil {
+			return fmt.Errorf("列出受限角色失败: %w", err)
+		}
+		roles = append(roles, roleList.Items...)
+	}
+	
+	orphaned := make(map[string]bool)
+	for i := range roles {
+		role := &roles[i]
+		isOrphaned, checked := orphaned[role.Namespace]
+		if !checked {
+			ns := &corev1.Namespace{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Name: role.Namespace}, ns); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return fmt.Errorf("获取命名空间 %s 失败: %w", role.Namespace, err)
+			}
+			isOrphaned = ns.Status.Phase != corev1.NamespaceTerminating &&
+				!isRestrictedDebtStatus(ns.Annotations[v1.DebtNamespaceAnnoStatusKey])
+			orphaned[role.Namespace] = isOrphaned
+		}
+		if !isOrphaned {
+			continue
+		}
+		if err := r.Client.Delete(ctx, role); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("删除孤立受限角色 %s/%s 失败: %w", role.Namespace, role.Name, err)
+		}
+		r.Log.Info("已清理孤立受限角色", "namespace", role.Namespace, "role", role.Name)
+	}
+	return nil
+}
+
+// isRestrictedDebtStatus 判断欠费状态下命名空间是否仍应保留受限角色，
+// 只有正常或已完成恢复的命名空间中的受限角色才视为孤立
+func isRestrictedDebtStatus(status string) bool {
+	switch status {
+	case "", v1.NormalDebtNamespaceAnnoStatus, v1.ResumeCompletedDebtNamespaceAnnoStatus:
+		return false
+	}
+	return true
+}
+
 func (r *NamespaceReconciler) suspendNetworkResources(ctx context.Context, namespace string) error {
 	logger := r.Log.WithValues("Namespace", namespace, "Function", "suspendNetworkResources")
 	
@@ -1456,33 +2212,34 @@ func (r *NamespaceReconciler) suspendNetworkResourceByType(ctx context.Context,
 			continue
 		}
 		
-		// 添加暂停注解
-		if annotations == nil {
-			annotations = make(map[string]string)
-		}
-		annotations["sealos.io/debt-suspended"] = "true"
-		annotations["sealos.io/debt-suspended-time"] = time.Now().Format(time.RFC3339)
-		annotations["sealos.io/debt-resource-type"] = resourceType
-		
-		resource.SetAnnotations(annotations)
-		
-		// 根据资源类型进行特定的暂停处理
-		if err := r.processNetworkResourceSuspension(ctx, &resource, resourceType); err != nil {
-			logger.Error(err, "处理资源暂停失败", "Resource", resourceName, "Type", resourceType)
-			failedResources = append(failedResources, resourceName)
-			
-			// 暂停处理失败时，移除暂停标记以避免状态不一致
-			if annotations != nil {
-				delete(annotations, "sealos.io/debt-suspended")
-				delete(annotations, "sealos.io/debt-suspended-time")
-				delete(annotations, "sealos.io/debt-resource-type")
-				resource.SetAnnotations(annotations)
+		// 添加暂停注解并根据资源类型进行特定的暂停处理，更新冲突时基于最新版本重新处理
+		var processErr error
+		resourceClient := r.dynamicClient.Resource(gvr).Namespace(namespace)
+		err := retryUnstructuredUpdateOnConflict(ctx, resourceClient, &resource, func(obj *unstructured.Unstructured) (bool, error) {
+			annotations := obj.GetAnnotations()
+			if annotations != nil && annotations["sealos.io/debt-suspended"] == "true" {
+				return false, nil
+			}
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations["sealos.io/debt-suspended"] = "true"
+			annotations["sealos.io/debt-suspended-time"] = r.now().Format(time.RFC3339)
+			annotations["sealos.io/debt-resource-type"] = resourceType
+			obj.SetAnnotations(annotations)
+
+			if err := r.processNetworkResourceSuspension(ctx, obj, resourceType); err != nil {
+				processErr = err
+				return false, err
 			}
+			return true, nil
+		})
+		if processErr != nil {
+			logger.Error(processErr, "处理资源暂停失败", "Resource", resourceName, "Type", resourceType)
+			failedResources = append(failedResources, resourceName)
 			continue
 		}
-		
-		// 更新资源
-		if _, err := r.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, &resource, v12.UpdateOptions{}); err != nil {
+		if err != nil {
 			logger.Error(err, "更新资源暂停状态失败", "Resource", resourceName, "Type", resourceType)
 			failedResources = append(failedResources, resourceName)
 			continue
@@ -1655,6 +2412,7 @@ func (r *NamespaceReconciler) backupResourceConfig(ctx context.Context, resource
 		annotations = make(map[string]string)
 	}
 	annotations[annotationKey] = configStr
+	annotations[annotationKey+"-checksum"] = backupChecksum(configJSON)
 	resource.SetAnnotations(annotations)
 	
 	logger.V(1).Info("已将配置备份到annotation", "size", len(configStr))
@@ -1698,7 +2456,7 @@ func (r *NamespaceReconciler) backupLargeConfigToConfigMap(ctx context.Context,
 				"sealos.io/source-resource": resourceName,
 			},
 			Annotations: map[string]string{
-				"sealos.io/backup-time":   time.Now().Format(time.RFC3339),
+				"sealos.io/backup-time":   r.now().Format(time.RFC3339),
 				"sealos.io/backup-source": fmt.Sprintf("%s/%s", resource.GetKind(), resourceName),
 			},
 		},
@@ -1718,7 +2476,7 @@ func (r *NamespaceReconciler) backupLargeConfigToConfigMap(ctx context.Context,
 			}
 			
 			existingConfigMap.Data["config"] = configData
-			existingConfigMap.Annotations["sealos.io/backup-time"] = time.Now().Format(time.RFC3339)
+			existingConfigMap.Annotations["sealos.io/backup-time"] = r.now().Format(time.RFC3339)
 			
 			if err := r.Client.Update(ctx, existingConfigMap); err != nil {
 				logger.Error(err, "更新ConfigMap失败")
@@ -1736,6 +2494,7 @@ func (r *NamespaceReconciler) backupLargeConfigToConfigMap(ctx context.Context,
 		annotations = make(map[string]string)
 	}
 	annotations[annotationKey+"-configmap"] = configMapName
+	annotations[annotationKey+"-checksum"] = backupChecksum([]byte(configData))
 	resource.SetAnnotations(annotations)
 	
 	logger.V(1).Info("已将大配置备份到ConfigMap", "configMap", configMapName, "size", len(configData))
@@ -1824,43 +2583,69 @@ func (r *NamespaceReconciler) resumeNetworkResourceByType(ctx context.Context, n
 		
 		logger.V(1).Info("恢复网络资源", "Resource", resourceName, "Type", resourceType)
 		
-		// 根据资源类型恢复原始配置
-		if err := r.processNetworkResourceResumption(ctx, &resource, resourceType); err != nil {
-			logger.Error(err, "处理资源恢复失败", "Resource", resourceName, "Type", resourceType)
+		// 恢复原始配置并移除暂停注解，更新冲突时基于最新版本重新处理
+		var processErr error
+		var suspendedSnapshot *unstructured.Unstructured
+		resourceClient := r.dynamicClient.Resource(gvr).Namespace(namespace)
+		err := retryUnstructuredUpdateOnConflict(ctx, resourceClient, &resource, func(obj *unstructured.Unstructured) (bool, error) {
+			annotations := obj.GetAnnotations()
+			if annotations == nil || annotations["sealos.io/debt-suspended"] != "true" {
+				return false, nil
+			}
+
+			// 根据资源类型恢复原始配置
+			if err := r.processNetworkResourceResumption(ctx, obj, resourceType); err != nil {
+				processErr = err
+				return false, err
+			}
+
+			// 保留暂停状态快照，用于更新成功后清理备份资源（ConfigMap等）
+			suspendedSnapshot = obj.DeepCopy()
+
+			// 移除暂停相关的注解
+			annotations = obj.GetAnnotations()
+			delete(annotations, "sealos.io/debt-suspended")
+			delete(annotations, "sealos.io/debt-suspended-time")
+			delete(annotations, "sealos.io/debt-resource-type")
+			delete(annotations, "sealos.io/debt-original-hosts")
+			delete(annotations, "sealos.io/debt-original-ports")
+			delete(annotations, "sealos.io/debt-original-servers")
+			delete(annotations, "sealos.io/debt-original-http")
+
+			// 清理ConfigMap引用注解
+			delete(annotations, "sealos.io/debt-original-hosts-configmap")
+			delete(annotations, "sealos.io/debt-original-ports-configmap")
+			delete(annotations, "sealos.io/debt-original-servers-configmap")
+			delete(annotations, "sealos.io/debt-original-http-configmap")
+
+			// 清理备份校验和注解
+			delete(annotations, "sealos.io/debt-original-hosts-checksum")
+			delete(annotations, "sealos.io/debt-original-ports-checksum")
+			delete(annotations, "sealos.io/debt-original-servers-checksum")
+			delete(annotations, "sealos.io/debt-original-http-checksum")
+
+			obj.SetAnnotations(annotations)
+			return true, nil
+		})
+		if processErr != nil {
+			logger.Error(processErr, "处理资源恢复失败", "Resource", resourceName, "Type", resourceType)
 			failedResources = append(failedResources, resourceName)
 			continue
 		}
-		
-		// 清理备份资源（ConfigMap等）
-		if err := r.cleanupBackupResources(ctx, &resource, logger); err != nil {
-			logger.Error(err, "清理备份资源失败", "Resource", resourceName, "Type", resourceType)
-			// 清理失败不影响主要恢复流程
-		}
-		
-		// 移除暂停相关的注解
-		delete(annotations, "sealos.io/debt-suspended")
-		delete(annotations, "sealos.io/debt-suspended-time")
-		delete(annotations, "sealos.io/debt-resource-type")
-		delete(annotations, "sealos.io/debt-original-hosts")
-		delete(annotations, "sealos.io/debt-original-ports")
-		delete(annotations, "sealos.io/debt-original-servers")
-		delete(annotations, "sealos.io/debt-original-http")
-		
-		// 清理ConfigMap引用注解
-		delete(annotations, "sealos.io/debt-original-hosts-configmap")
-		delete(annotations, "sealos.io/debt-original-ports-configmap")
-		delete(annotations, "sealos.io/debt-original-servers-configmap")
-		delete(annotations, "sealos.io/debt-original-http-configmap")
-		
-		resource.SetAnnotations(annotations)
-		
-		// 更新资源
-		if _, err := r.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, &resource, v12.UpdateOptions{}); err != nil {
+		if err != nil {
 			logger.Error(err, "更新资源恢复状态失败", "Resource", resourceName, "Type", resourceType)
 			failedResources = append(failedResources, resourceName)
 			continue
 		}
 		
+		// 更新成功后再清理备份资源（ConfigMap等），避免更新失败时丢失备份
+		if suspendedSnapshot != nil {
+			if err := r.cleanupBackupResources(ctx, suspendedSnapshot, logger); err != nil {
+				logger.Error(err, "清理备份资源失败", "Resource", resourceName, "Type", resourceType)
+				// 清理失败不影响主要恢复流程
+			}
+		}
+		
 		resumedCount++
 		logger.V(1).Info("已恢复网络资源", "Resource", resourceName, "Type", resourceType)
 	}
@@ -2002,7 +2787,13 @@ func (r *NamespaceReconciler) restoreResourceConfig(ctx context.Context, resourc
 	}
 	
 	// 首先尝试从annotation恢复
+	checksum := annotations[annotationKey+"-checksum"]
 	if configStr, exists := annotations[annotationKey]; exists {
+		if err := verifyBackupChecksum([]byte(configStr), checksum); err != nil {
+			logger.Error(err, "annotation备份校验失败", "annotationKey", annotationKey)
+			return nil, fmt.Errorf("annotation备份校验失败: %w", err)
+		}
+		
 		var config []interface{}
 		if err := json.Unmarshal([]byte(configStr), &config); err != nil {
 			logger.Error(err, "解析annotation配置失败", "annotationKey", annotationKey)
@@ -2022,7 +2813,7 @@ func (r *NamespaceReconciler) restoreResourceConfig(ctx context.Context, resourc
 	// 尝试从ConfigMap恢复
 	configMapKey := annotationKey + "-configmap"
 	if configMapName, exists := annotations[configMapKey]; exists {
-		config, err := r.restoreConfigFromConfigMap(ctx, resource.GetNamespace(), configMapName, logger)
+		config, err := r.restoreConfigFromConfigMap(ctx, resource.GetNamespace(), configMapName, checksum, logger)
 		if err != nil {
 			logger.Error(err, "从ConfigMap恢复配置失败", "configMap", configMapName)
 			return nil, fmt.Errorf("从ConfigMap恢复失败: %w", err)
@@ -2042,8 +2833,8 @@ func (r *NamespaceReconciler) restoreResourceConfig(ctx context.Context, resourc
 	return nil, nil
 }
 
-// restoreConfigFromConfigMap 从ConfigMap恢复配置
-func (r *NamespaceReconciler) restoreConfigFromConfigMap(ctx context.Context, namespace, configMapName string, logger logr.Logger) ([]interface{}, error) {
+// restoreConfigFromConfigMap 从ConfigMap恢复配置，checksum 非空时校验备份完整性
+func (r *NamespaceReconciler) restoreConfigFromConfigMap(ctx context.Context, namespace, configMapName, checksum string, logger logr.Logger) ([]interface{}, error) {
 	configMap := &corev1.ConfigMap{}
 	if err := r.Client.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: namespace}, configMap); err != nil {
 		if errors.IsNotFound(err) {
@@ -2058,6 +2849,10 @@ func (r *NamespaceReconciler) restoreConfigFromConfigMap(ctx context.Context, na
 		return nil, fmt.Errorf("ConfigMap中没有config数据")
 	}
 	
+	if err := verifyBackupChecksum([]byte(configData), checksum); err != nil {
+		return nil, fmt.Errorf("ConfigMap %s 备份校验失败: %w", configMapName, err)
+	}
+	
 	var config []interface{}
 	if err := json.Unmarshal([]byte(configData), &config); err != nil {
 		return nil, fmt.Errorf("解析ConfigMap配置失败: %w", err)
@@ -2066,6 +2861,23 @@ func (r *NamespaceReconciler) restoreConfigFromConfigMap(ctx context.Context, na
 	return config, nil
 }
 
+// backupChecksum 计算备份数据的 SHA-256 校验和
+func backupChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyBackupChecksum 校验备份数据完整性，expected 为空（升级前创建的备份）时跳过校验
+func verifyBackupChecksum(data []byte, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	if actual := backupChecksum(data); actual != expected {
+		return fmt.Errorf("备份校验和不匹配，备份可能已被截断或损坏: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
 // validateRestoredConfig 验证恢复的配置
 func (r *NamespaceReconciler) validateRestoredConfig(config []interface{}) error {
 	if config == nil {
@@ -2156,23 +2968,25 @@ func (r *NamespaceReconciler) suspendCertManagerResources(ctx context.Context, n
 				continue
 			}
 			
-			// 添加暂停注解
-			if annotations == nil {
-				annotations = make(map[string]string)
-			}
-			annotations["sealos.io/debt-suspended"] = "true"
-			annotations["sealos.io/debt-suspended-time"] = time.Now().Format(time.RFC3339)
-			
-			// 记录原始状态以便恢复
-			secretName, found, err := unstructured.NestedString(cert.Object, "spec", "secretName")
-			if err == nil && found {
-				annotations["sealos.io/debt-original-secret"] = secretName
-			}
-			
-			cert.SetAnnotations(annotations)
-			
-			// 更新证书（仅添加注解，不删除Secret）
-			if _, err := r.dynamicClient.Resource(certificateGVR).Namespace(namespace).Update(ctx, &cert, v12.UpdateOptions{}); err != nil {
+			// 添加暂停注解并更新证书（仅添加注解，不删除Secret）
+			certClient := r.dynamicClient.Resource(certificateGVR).Namespace(namespace)
+			if err := retryUnstructuredUpdateOnConflict(ctx, certClient, &cert, func(obj *unstructured.Unstructured) (bool, error) {
+				annotations := obj.GetAnnotations()
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations["sealos.io/debt-suspended"] = "true"
+				annotations["sealos.io/debt-suspended-time"] = r.now().Format(time.RFC3339)
+				
+				// 记录原始状态以便恢复
+				secretName, found, err := unstructured.NestedString(obj.Object, "spec", "secretName")
+				if err == nil && found {
+					annotations["sealos.io/debt-original-secret"] = secretName
+				}
+				
+				obj.SetAnnotations(annotations)
+				return true, nil
+			}); err != nil {
 				logger.Error(err, "更新证书暂停注解失败", "Certificate", certName)
 				continue
 			}
@@ -2231,15 +3045,19 @@ func (r *NamespaceReconciler) resumeCertManagerResources(ctx context.Context, na
 				continue
 			}
 			
-			// 移除暂停相关的注解（保留TLS Secret，不强制续期）
-			delete(annotations, "sealos.io/debt-suspended")
-			delete(annotations, "sealos.io/debt-suspended-time")
-			delete(annotations, "sealos.io/debt-original-secret")
-			
-			cert.SetAnnotations(annotations)
-			
-			// 更新证书
-			if _, err := r.dynamicClient.Resource(certificateGVR).Namespace(namespace).Update(ctx, &cert, v12.UpdateOptions{}); err != nil {
+			// 移除暂停相关的注解并更新证书（保留TLS Secret，不强制续期）
+			certClient := r.dynamicClient.Resource(certificateGVR).Namespace(namespace)
+			if err := retryUnstructuredUpdateOnConflict(ctx, certClient, &cert, func(obj *unstructured.Unstructured) (bool, error) {
+				annotations := obj.GetAnnotations()
+				if annotations == nil || annotations["sealos.io/debt-suspended"] != "true" {
+					return false, nil
+				}
+				delete(annotations, "sealos.io/debt-suspended")
+				delete(annotations, "sealos.io/debt-suspended-time")
+				delete(annotations, "sealos.io/debt-original-secret")
+				obj.SetAnnotations(annotations)
+				return true, nil
+			}); err != nil {
 				logger.Error(err, "更新证书恢复注解失败", "Certificate", certName)
 				continue
 			}
@@ -2401,6 +3219,75 @@ func deleteResource(dynamicClient dynamic.Interface, resource, namespace string)
 	return nil
 }
 
+// retryUnstructuredUpdateOnConflict 通过动态客户端更新资源，遇到版本冲突时获取最新版本并重新执行 mutate 后重试。
+// mutate 返回 false 表示最新版本无需更新（例如已被其他协调处理）。
+func retryUnstructuredUpdateOnConflict(ctx context.Context, resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured, mutate func(*unstructured.Unstructured) (bool, error)) error {
+	refresh := false
+	return clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+		if refresh {
+			latest, err := resourceClient.Get(ctx, obj.GetName(), v12.GetOptions{})
+			if err != nil {
+				return err
+			}
+			latest.DeepCopyInto(obj)
+		}
+		refresh = true
+		
+		needUpdate, err := mutate(obj)
+		if err != nil || !needUpdate {
+			return err
+		}
+		
+		updated, err := resourceClient.Update(ctx, obj, v12.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		updated.DeepCopyInto(obj)
+		return nil
+	})
+}
+
+// networkSuspensionAnnotations NetworkStrategy 暂停时写入的记录注解
+var networkSuspensionAnnotations = []string{
+	DebtSuspendedAnnotation,
+	DebtSuspendedAtAnnotation,
+	DebtAnnotationPrefix + "backup-data",
+	DebtAnnotationPrefix + "backup-location",
+	DebtAnnotationPrefix + "backup-configmap",
+	DebtBackupChecksumAnnotation,
+}
+
+// stripDebtAnnotations 返回去除欠费暂停记录注解后的注解副本，
+// 其他 debt.sealos.io/ 注解（如 strategies、suspend-at）保持不变
+func stripDebtAnnotations(annotations map[string]string) map[string]string {
+	result := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		result[k] = v
+	}
+	for _, k := range networkSuspensionAnnotations {
+		delete(result, k)
+	}
+	return result
+}
+
+// toStringMap 将JSON反序列化得到的map转换为字符串map
+func toStringMap(value interface{}) map[string]string {
+	result := make(map[string]string)
+	switch m := value.(type) {
+	case map[string]string:
+		for k, v := range m {
+			result[k] = v
+		}
+	case map[string]interface{}:
+		for k, v := range m {
+			if str, ok := v.(string); ok {
+				result[k] = str
+			}
+		}
+	}
+	return result
+}
+
 // ====================== 优化功能实现 ======================
 
 // initializeStrategies 初始化暂停策略
@@ -2415,20 +3302,169 @@ func (r *NamespaceReconciler) initializeStrategies() {
 	
 	r.strategies = []SuspensionStrategy{
 		&CertManagerStrategy{
-			client:        r.Client,
-			dynamicClient: r.dynamicClient,
-			cache:         r.resourceCache,
+			client:                 r.Client,
+			dynamicClient:          r.dynamicClient,
+			cache:                  r.resourceCache,
+			clock:                  r.now,
+			keepCertificatesActive: r.suspensionConfig.KeepCertificatesActive,
 		},
 		&NetworkStrategy{
 			client:        r.Client,
 			dynamicClient: r.dynamicClient,
 			cache:         r.resourceCache,
+			clock:         r.now,
+			exemptBudget:  r.suspensionConfig.SuspendExemptBudget,
 		},
 		&RBACStrategy{
 			client: r.Client,
 			cache:  r.resourceCache,
 		},
+		&WorkloadStrategy{
+			dynamicClient: r.dynamicClient,
+			cache:         r.resourceCache,
+			clock:         r.now,
+		},
+	}
+	
+	if len(r.suspensionConfig.ScalableResources) > 0 {
+		r.strategies = append(r.strategies, &ScalableStrategy{
+			dynamicClient: r.dynamicClient,
+			cache:         r.resourceCache,
+			clock:         r.now,
+			resources:     r.suspensionConfig.ScalableResources,
+		})
 	}
+	
+	if len(r.suspensionConfig.PVCReadOnlyClasses) > 0 {
+		r.strategies = append(r.strategies, &PVCStrategy{
+			client:          r.Client,
+			cache:           r.resourceCache,
+			clock:           r.now,
+			readOnlyClasses: r.suspensionConfig.PVCReadOnlyClasses,
+		})
+	}
+}
+
+// AppStrategyOverrides 应用名 -> 对该应用资源生效的策略集合，来自工作负载上的 DebtStrategiesAnnotation
+type AppStrategyOverrides map[string]map[string]bool
+
+// appStrategyOverridesKey context 中保存应用级策略覆盖的键
+type appStrategyOverridesKey struct{}
+
+func withAppStrategyOverrides(ctx context.Context, overrides AppStrategyOverrides) context.Context {
+	return context.WithValue(ctx, appStrategyOverridesKey{}, overrides)
+}
+
+func appStrategyOverridesFrom(ctx context.Context) AppStrategyOverrides {
+	overrides, _ := ctx.Value(appStrategyOverridesKey{}).(AppStrategyOverrides)
+	return overrides
+}
+
+// dryRunReport 演练模式下各策略将要修改的资源，策略名 -> Kind/名称 列表
+type dryRunReport struct {
+	mu        sync.Mutex
+	resources map[string][]string
+}
+
+func newDryRunReport() *dryRunReport {
+	return &dryRunReport{resources: make(map[string][]string)}
+}
+
+func (d *dryRunReport) add(strategy, kind, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resources[strategy] = append(d.resources[strategy], kind+"/"+name)
+}
+
+func (d *dryRunReport) list(strategy string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.resources[strategy]...)
+}
+
+// dryRunReportKey context 中保存演练报告的键，存在时各策略只记录不修改
+type dryRunReportKey struct{}
+
+func withDryRunReport(ctx context.Context, report *dryRunReport) context.Context {
+	return context.WithValue(ctx, dryRunReportKey{}, report)
+}
+
+func dryRunReportFrom(ctx context.Context) *dryRunReport {
+	report, _ := ctx.Value(dryRunReportKey{}).(*dryRunReport)
+	return report
+}
+
+func isDryRun(ctx context.Context) bool {
+	return dryRunReportFrom(ctx) != nil
+}
+
+// updateUnstructuredOrReport 演练模式下只在副本上执行 mutate，记录需要更新的资源；否则按冲突重试更新
+func updateUnstructuredOrReport(ctx context.Context, resourceClient dynamic.ResourceInterface, obj *unstructured.Unstructured, strategy string, mutate func(*unstructured.Unstructured) (bool, error)) error {
+	report := dryRunReportFrom(ctx)
+	if report == nil {
+		return retryUnstructuredUpdateOnConflict(ctx, resourceClient, obj, mutate)
+	}
+	needUpdate, err := mutate(obj.DeepCopy())
+	if err != nil || !needUpdate {
+		return err
+	}
+	report.add(strategy, obj.GetKind(), obj.GetName())
+	return nil
+}
+
+// Applies 判断策略是否作用于带有指定标签的资源，资源按 app-deploy-manager 标签归属应用，
+// 不属于任何声明了覆盖的应用时使用命名空间级策略集合
+func (o AppStrategyOverrides) Applies(labels map[string]string, strategy string) bool {
+	app := labels[resources.AppDeployLabelKey]
+	if app == "" {
+		return true
+	}
+	strategies, ok := o[app]
+	if !ok {
+		return true
+	}
+	return strategies[strategy]
+}
+
+// loadAppStrategyOverrides 从命名空间内 Deployment 和 StatefulSet 的 DebtStrategiesAnnotation 加载应用级策略覆盖
+func (r *NamespaceReconciler) loadAppStrategyOverrides(ctx context.Context, namespace string) (AppStrategyOverrides, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.Client.List(ctx, deployments, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.Client.List(ctx, statefulSets, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	
+	workloads := make([]v12.ObjectMeta, 0, len(deployments.Items)+len(statefulSets.Items))
+	for _, deploy := range deployments.Items {
+		workloads = append(workloads, deploy.ObjectMeta)
+	}
+	for _, sts := range statefulSets.Items {
+		workloads = append(workloads, sts.ObjectMeta)
+	}
+	
+	overrides := AppStrategyOverrides{}
+	for _, meta := range workloads {
+		// 注解为空时不覆盖，避免误把应用排除在所有策略之外
+		value := meta.Annotations[DebtStrategiesAnnotation]
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		app := meta.Labels[resources.AppDeployLabelKey]
+		if app == "" {
+			app = meta.Name
+		}
+		strategies := make(map[string]bool)
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				strategies[name] = true
+			}
+		}
+		overrides[app] = strategies
+	}
+	return overrides, nil
 }
 
 // loadSuspensionConfig 加载暂停配置
@@ -2456,17 +3492,110 @@ func (r *NamespaceReconciler) loadSuspensionConfig() *SuspensionConfig {
 		r.Log.Error(err, "解析配置文件失败，使用默认配置")
 		return defaultSuspensionConfig
 	}
+	config.ScalableResources = validScalableResources(r.Log, config.ScalableResources)
 	
 	return config
 }
 
-// isSuspended 检查幂等性
-func (r *NamespaceReconciler) isSuspended(ctx context.Context, namespace string) (bool, error) {
-	if r.resourceCache == nil {
-		r.resourceCache = NewResourceCache(DefaultCacheTTL)
+// validScalableResources 校验可伸缩CRD配置的 GVR 和补丁，记录并丢弃无效配置，
+// 避免单个错误配置导致整个命名空间的暂停/恢复失败
+func validScalableResources(logger logr.Logger, resources []ScalableResourceConfig) []ScalableResourceConfig {
+	valid := make([]ScalableResourceConfig, 0, len(resources))
+	for _, res := range resources {
+		if _, err := parseScalableGVR(res.GVR); err != nil {
+			logger.Error(err, "忽略无效的可伸缩资源配置", "gvr", res.GVR)
+			continue
+		}
+		if _, err := decodeMergePatch(res.SuspendPatch); err != nil {
+			logger.Error(err, "忽略暂停补丁无效的可伸缩资源配置", "gvr", res.GVR)
+			continue
+		}
+		if res.ResumePatch != "" {
+			if _, err := decodeMergePatch(res.ResumePatch); err != nil {
+				logger.Error(err, "忽略恢复补丁无效的可伸缩资源配置", "gvr", res.GVR)
+				continue
+			}
+		}
+		valid = append(valid, res)
 	}
-	
-	// 先检查缓存
+	return valid
+}
+
+// SuspendedResource 命名空间中处于暂停状态的资源
+type SuspendedResource struct {
+	Kind        string
+	Name        string
+	SuspendedAt time.Time // 暂停时间，注解缺失或格式错误时为零值
+}
+
+// ListSuspendedResources 列出命名空间中带有暂停标记的资源及其暂停时间，按 Kind、Name 排序
+func (r *NamespaceReconciler) ListSuspendedResources(ctx context.Context, namespace string) ([]SuspendedResource, error) {
+	if r.suspensionConfig == nil {
+		r.suspensionConfig = r.loadSuspensionConfig()
+	}
+	gvrs := []schema.GroupVersionResource{
+		{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		{Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+	}
+	gvrs = append(gvrs, workloadGVRs...)
+	for _, stage := range networkResumeStages {
+		gvrs = append(gvrs, stage...)
+	}
+	for _, res := range r.suspensionConfig.ScalableResources {
+		gvr, err := parseScalableGVR(res.GVR)
+		if err != nil {
+			return nil, err
+		}
+		// 可伸缩资源可能与内置策略的资源类型相同（如 apps/v1 工作负载），只列出一次
+		if !slices.Contains(gvrs, gvr) {
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	
+	var suspended []SuspendedResource
+	for _, gvr := range gvrs {
+		list, err := r.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, v12.ListOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("列出 %s 失败: %w", gvr.Resource, err)
+		}
+		for _, item := range list.Items {
+			annotations := item.GetAnnotations()
+			suspendedAtAnnotation := DebtSuspendedAtAnnotation
+			if annotations[DebtSuspendedAnnotation] != "true" {
+				if annotations[DebtScaleSuspendedAnnotation] != "true" {
+					continue
+				}
+				suspendedAtAnnotation = DebtScaleSuspendedAtAnnotation
+			}
+			// 时间格式错误时仍然列出资源，只是不带暂停时间
+			suspendedAt, _ := time.Parse(time.RFC3339, annotations[suspendedAtAnnotation])
+			suspended = append(suspended, SuspendedResource{
+				Kind:        item.GetKind(),
+				Name:        item.GetName(),
+				SuspendedAt: suspendedAt,
+			})
+		}
+	}
+	
+	sort.Slice(suspended, func(i, j int) bool {
+		if suspended[i].Kind != suspended[j].Kind {
+			return suspended[i].Kind < suspended[j].Kind
+		}
+		return suspended[i].Name < suspended[j].Name
+	})
+	return suspended, nil
+}
+
+// isSuspended 检查幂等性
+func (r *NamespaceReconciler) isSuspended(ctx context.Context, namespace string) (bool, error) {
+	if r.resourceCache == nil {
+		r.resourceCache = NewResourceCache(DefaultCacheTTL)
+	}
+	
+	// 先检查缓存
 	if suspended, found := r.resourceCache.IsSuspended(namespace, "all"); found {
 		return suspended, nil
 	}
@@ -2507,6 +3636,147 @@ func (r *NamespaceReconciler) rollbackTransaction(ctx context.Context, txn *Susp
 	}
 }
 
+// transactionRecordName 暂停事务记录 ConfigMap 的名称，每个命名空间同时只有一个暂停事务
+func transactionRecordName(namespace string) string {
+	return fmt.Sprintf("debt-suspend-txn-%s", namespace)
+}
+
+// addStep 在事务锁内追加已完成的步骤（不持久化），供并行执行的策略使用
+func (txn *SuspensionTransaction) addStep(step string) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.Steps = append(txn.Steps, step)
+}
+
+// recordTransactionStep 追加已完成的步骤并立即持久化，持久化失败时返回错误使暂停失败回滚，
+// 避免重启后无法得知该步骤已执行
+func (r *NamespaceReconciler) recordTransactionStep(ctx context.Context, txn *SuspensionTransaction, step string) error {
+	txn.mu.Lock()
+	txn.Steps = append(txn.Steps, step)
+	txn.UpdatedAt = r.now()
+	txn.mu.Unlock()
+	return r.saveTransaction(ctx, txn)
+}
+
+// saveTransaction 创建或更新暂停事务记录
+func (r *NamespaceReconciler) saveTransaction(ctx context.Context, txn *SuspensionTransaction) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	
+	data, err := json.Marshal(txn)
+	if err != nil {
+		return fmt.Errorf("序列化暂停事务失败: %w", err)
+	}
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Name: transactionRecordName(txn.Namespace), Namespace: TransactionRecordNamespace}
+	if err := r.Client.Get(ctx, key, configMap); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("获取暂停事务记录失败: %w", err)
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: v12.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels: map[string]string{
+					TransactionRecordLabel: "true",
+				},
+			},
+			Data: map[string]string{TransactionRecordKey: string(data)},
+		}
+		if err := r.Client.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("创建暂停事务记录失败: %w", err)
+		}
+		return nil
+	}
+	
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string)
+	}
+	configMap.Data[TransactionRecordKey] = string(data)
+	if err := r.Client.Update(ctx, configMap); err != nil {
+		return fmt.Errorf("更新暂停事务记录失败: %w", err)
+	}
+	return nil
+}
+
+// deleteTransactionRecord 删除暂停事务记录
+func (r *NamespaceReconciler) deleteTransactionRecord(ctx context.Context, namespace string) error {
+	configMap := &corev1.ConfigMap{}
+	configMap.Name = transactionRecordName(namespace)
+	configMap.Namespace = TransactionRecordNamespace
+	return client.IgnoreNotFound(r.Client.Delete(ctx, configMap))
+}
+
+// recoverPendingTransactions 回滚上次运行时未完成的暂停事务（控制器在暂停过程中重启），
+// 在暂停锁内重新读取记录后按记录的步骤回滚；锁仍被持有的命名空间返回错误，由调用方稍后重试
+func (r *NamespaceReconciler) recoverPendingTransactions(ctx context.Context) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.Client.List(ctx, configMaps, client.InNamespace(TransactionRecordNamespace), client.MatchingLabels{TransactionRecordLabel: "true"}); err != nil {
+		return fmt.Errorf("列出暂停事务记录失败: %w", err)
+	}
+	if len(r.strategies) == 0 {
+		r.initializeStrategies()
+	}
+	
+	var held []string
+	for i := range configMaps.Items {
+		txn := &SuspensionTransaction{}
+		if err := json.Unmarshal([]byte(configMaps.Items[i].Data[TransactionRecordKey]), txn); err != nil || txn.Namespace == "" {
+			r.Log.Error(err, "暂停事务记录无法解析，跳过", "configmap", configMaps.Items[i].Name)
+			continue
+		}
+		err := r.suspendWithLock(ctx, txn.Namespace, "suspend", func(ctx context.Context) error {
+			return r.recoverTransaction(ctx, txn.Namespace)
+		})
+		if err == errLockHeld {
+			held = append(held, txn.Namespace)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(held) > 0 {
+		return fmt.Errorf("暂停锁仍被持有，稍后重试恢复暂停事务: %v", held)
+	}
+	return nil
+}
+
+// recoverTransaction 在持有暂停锁时回滚单个命名空间遗留的暂停事务
+func (r *NamespaceReconciler) recoverTransaction(ctx context.Context, namespace string) error {
+	// 获取锁前记录可能已被新的暂停操作完成并删除
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: transactionRecordName(namespace), Namespace: TransactionRecordNamespace}, configMap); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	txn := &SuspensionTransaction{}
+	if err := json.Unmarshal([]byte(configMap.Data[TransactionRecordKey]), txn); err != nil {
+		return fmt.Errorf("解析暂停事务记录失败: %w", err)
+	}
+	if txn.Status != TransactionCompleted {
+		r.Log.Info("回滚控制器重启前未完成的暂停事务", "namespace", namespace, "status", txn.Status, "steps", txn.Steps)
+		r.rollbackTransaction(ctx, txn)
+	}
+	return r.deleteTransactionRecord(ctx, namespace)
+}
+
+// runTransactionRecovery 启动后恢复未完成的暂停事务，失败时每隔 LockLeaseDuration 重试，
+// 等待崩溃实例遗留的暂停锁过期
+func (r *NamespaceReconciler) runTransactionRecovery(ctx context.Context) error {
+	for {
+		err := r.recoverPendingTransactions(ctx)
+		if err == nil {
+			return nil
+		}
+		r.Log.Error(err, "恢复未完成的暂停事务失败")
+		select {
+		case <-time.After(LockLeaseDuration):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 // rollbackStep 回滚单个步骤
 func (r *NamespaceReconciler) rollbackStep(ctx context.Context, namespace, step string) error {
 	parts := strings.Split(step, "_")
@@ -2604,6 +3874,11 @@ func (s *CertManagerStrategy) Suspend(ctx context.Context, namespace string) err
 		return nil
 	}
 	
+	// 保留证书续期时跳过暂停；恢复仍会清理之前暂停留下的标记
+	if s.keepCertificatesActive {
+		return nil
+	}
+	
 	g, ctx := errgroup.WithContext(ctx)
 	
 	// 暂停Certificate资源
@@ -2619,6 +3894,9 @@ func (s *CertManagerStrategy) Suspend(ctx context.Context, namespace string) err
 	if err := g.Wait(); err != nil {
 		return err
 	}
+	if isDryRun(ctx) {
+		return nil
+	}
 	
 	// 更新缓存
 	s.cache.SetSuspended(namespace, StrategyCertManager, true)
@@ -2654,18 +3932,19 @@ func (s *CertManagerStrategy) suspendCertificates(ctx context.Context, namespace
 		return err
 	}
 	
+	certClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
 	for _, cert := range resources.Items {
 		// 标记为暂停状态而不是删除
-		annotations := cert.GetAnnotations()
-		if annotations == nil {
-			annotations = make(map[string]string)
-		}
-		annotations["debt.sealos.io/suspended"] = "true"
-		annotations["debt.sealos.io/suspended-at"] = time.Now().Format(time.RFC3339)
-		
-		cert.SetAnnotations(annotations)
-		
-		if _, err := s.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, &cert, v12.UpdateOptions{}); err != nil {
+		if err := updateUnstructuredOrReport(ctx, certClient, &cert, StrategyCertManager, func(obj *unstructured.Unstructured) (bool, error) {
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations["debt.sealos.io/suspended"] = "true"
+			annotations[DebtSuspendedAtAnnotation] = s.clock.Now().Format(time.RFC3339)
+			obj.SetAnnotations(annotations)
+			return true, nil
+		}); err != nil {
 			return err
 		}
 	}
@@ -2681,6 +3960,17 @@ func (s *CertManagerStrategy) deleteChallenges(ctx context.Context, namespace st
 		Resource: "challenges",
 	}
 	
+	if report := dryRunReportFrom(ctx); report != nil {
+		challenges, err := s.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, v12.ListOptions{})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		for _, challenge := range challenges.Items {
+			report.add(StrategyCertManager, challenge.GetKind(), challenge.GetName())
+		}
+		return nil
+	}
+	
 	deletePolicy := v12.DeletePropagationForeground
 	return s.dynamicClient.Resource(gvr).Namespace(namespace).DeleteCollection(ctx, v12.DeleteOptions{
 		PropagationPolicy: &deletePolicy,
@@ -2700,17 +3990,20 @@ func (s *CertManagerStrategy) resumeCertificates(ctx context.Context, namespace
 		return err
 	}
 	
+	certClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
 	for _, cert := range resources.Items {
-		annotations := cert.GetAnnotations()
-		if annotations != nil && annotations["debt.sealos.io/suspended"] == "true" {
-			// 移除暂停标记
-			delete(annotations, "debt.sealos.io/suspended")
-			delete(annotations, "debt.sealos.io/suspended-at")
-			cert.SetAnnotations(annotations)
-			
-			if _, err := s.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, &cert, v12.UpdateOptions{}); err != nil {
-				return err
+		// 移除暂停标记
+		if err := retryUnstructuredUpdateOnConflict(ctx, certClient, &cert, func(obj *unstructured.Unstructured) (bool, error) {
+			annotations := obj.GetAnnotations()
+			if annotations == nil || annotations["debt.sealos.io/suspended"] != "true" {
+				return false, nil
 			}
+			delete(annotations, "debt.sealos.io/suspended")
+			delete(annotations, DebtSuspendedAtAnnotation)
+			obj.SetAnnotations(annotations)
+			return true, nil
+		}); err != nil {
+			return err
 		}
 	}
 	
@@ -2761,6 +4054,9 @@ func (s *NetworkStrategy) Suspend(ctx context.Context, namespace string) error {
 	if err := g.Wait(); err != nil {
 		return err
 	}
+	if isDryRun(ctx) {
+		return nil
+	}
 	
 	// 更新缓存
 	s.cache.SetSuspended(namespace, StrategyNetwork, true)
@@ -2769,26 +4065,35 @@ func (s *NetworkStrategy) Suspend(ctx context.Context, namespace string) error {
 	return nil
 }
 
-// Resume 恢复网络资源
-func (s *NetworkStrategy) Resume(ctx context.Context, namespace string) error {
-	networkGVRs := []schema.GroupVersionResource{
-		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+// networkResumeStages 网络资源按依赖逆序分阶段恢复：先恢复后端Service，再恢复Gateway，
+// 最后恢复引用它们的Ingress/VirtualService，避免前端路由先生效导致短暂的503
+var networkResumeStages = [][]schema.GroupVersionResource{
+	{
 		{Group: "", Version: "v1", Resource: "services"},
+	},
+	{
 		{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"},
+	},
+	{
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
 		{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"},
-	}
-	
-	g, ctx := errgroup.WithContext(ctx)
-	
-	for _, gvr := range networkGVRs {
-		gvr := gvr
-		g.Go(func() error {
-			return s.resumeResourcesByGVR(ctx, namespace, gvr)
-		})
-	}
-	
-	if err := g.Wait(); err != nil {
-		return err
+	},
+}
+
+// Resume 恢复网络资源
+func (s *NetworkStrategy) Resume(ctx context.Context, namespace string) error {
+	// 阶段之间顺序执行，同一阶段内并行恢复
+	for _, stage := range networkResumeStages {
+		g, stageCtx := errgroup.WithContext(ctx)
+		for _, gvr := range stage {
+			gvr := gvr
+			g.Go(func() error {
+				return s.resumeResourcesByGVR(stageCtx, namespace, gvr)
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return err
+		}
 	}
 	
 	// 更新缓存
@@ -2808,8 +4113,22 @@ func (s *NetworkStrategy) suspendResourcesByGVR(ctx context.Context, namespace s
 		return err
 	}
 	
+	overrides := appStrategyOverridesFrom(ctx)
+	candidates := make([]unstructured.Unstructured, 0, len(resources.Items))
 	for _, resource := range resources.Items {
-		if err := s.backupAndClearResource(ctx, namespace, &resource, gvr); err != nil {
+		if overrides.Applies(resource.GetLabels(), StrategyNetwork) {
+			candidates = append(candidates, resource)
+		}
+	}
+	
+	// 免暂停预算内的资源保持可用，其余资源暂停
+	exempt := s.exemptBudget[gvr.Resource]
+	sortByCreation(candidates)
+	for i := range candidates {
+		if i < exempt {
+			continue
+		}
+		if err := s.backupAndClearResource(ctx, namespace, &candidates[i], gvr); err != nil {
 			return err
 		}
 	}
@@ -2817,6 +4136,17 @@ func (s *NetworkStrategy) suspendResourcesByGVR(ctx context.Context, namespace s
 	return nil
 }
 
+// sortByCreation 按创建时间排序（相同时按名称），保证每次选出的免暂停资源一致
+func sortByCreation(items []unstructured.Unstructured) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := items[i].GetCreationTimestamp(), items[j].GetCreationTimestamp()
+		if !ti.Equal(&tj) {
+			return ti.Before(&tj)
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+}
+
 // resumeResourcesByGVR 按GVR恢复资源
 func (s *NetworkStrategy) resumeResourcesByGVR(ctx context.Context, namespace string, gvr schema.GroupVersionResource) error {
 	resources, err := s.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, v12.ListOptions{})
@@ -2838,10 +4168,23 @@ func (s *NetworkStrategy) resumeResourcesByGVR(ctx context.Context, namespace st
 
 // backupAndClearResource 备份并清空资源配置
 func (s *NetworkStrategy) backupAndClearResource(ctx context.Context, namespace string, resource *unstructured.Unstructured, gvr schema.GroupVersionResource) error {
+	resourceClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
+	return updateUnstructuredOrReport(ctx, resourceClient, resource, StrategyNetwork, func(obj *unstructured.Unstructured) (bool, error) {
+		return s.applySuspension(ctx, namespace, obj, gvr)
+	})
+}
+
+// applySuspension 在资源对象上记录备份并清空配置，返回是否需要更新
+func (s *NetworkStrategy) applySuspension(ctx context.Context, namespace string, resource *unstructured.Unstructured, gvr schema.GroupVersionResource) (bool, error) {
+	// 已暂停的资源保留首次备份，避免用清空后的配置覆盖原始备份
+	if resource.GetAnnotations()[DebtSuspendedAnnotation] == "true" {
+		return false, nil
+	}
+	
 	// 获取需要备份的字段
 	spec, found, err := unstructured.NestedMap(resource.Object, "spec")
 	if err != nil || !found {
-		return nil
+		return false, nil
 	}
 	
 	// 创建备份数据
@@ -2849,13 +4192,13 @@ func (s *NetworkStrategy) backupAndClearResource(ctx context.Context, namespace
 		"spec": spec,
 		"metadata": map[string]interface{}{
 			"labels":      resource.GetLabels(),
-			"annotations": resource.GetAnnotations(),
+			"annotations": stripDebtAnnotations(resource.GetAnnotations()),
 		},
 	}
 	
 	backupJSON, err := json.Marshal(backup)
 	if err != nil {
-		return err
+		return false, err
 	}
 	
 	// 检查备份大小
@@ -2867,9 +4210,12 @@ func (s *NetworkStrategy) backupAndClearResource(ctx context.Context, namespace
 	}
 	
 	if len(backupJSON) > maxAnnotationSize {
-		// 使用ConfigMap存储大的备份数据
+		// 使用ConfigMap存储大的备份数据，演练模式下不创建
+		if isDryRun(ctx) {
+			return true, nil
+		}
 		if err := s.storeBackupInConfigMap(ctx, namespace, resource.GetName(), gvr.Resource, backupJSON); err != nil {
-			return err
+			return false, err
 		}
 		annotations["debt.sealos.io/backup-location"] = "configmap"
 		annotations["debt.sealos.io/backup-configmap"] = fmt.Sprintf("%s-%s-backup", resource.GetName(), gvr.Resource)
@@ -2878,9 +4224,10 @@ func (s *NetworkStrategy) backupAndClearResource(ctx context.Context, namespace
 		annotations["debt.sealos.io/backup-data"] = string(backupJSON)
 		annotations["debt.sealos.io/backup-location"] = "annotation"
 	}
+	annotations[DebtBackupChecksumAnnotation] = backupChecksum(backupJSON)
 	
-	annotations["debt.sealos.io/suspended"] = "true"
-	annotations["debt.sealos.io/suspended-at"] = time.Now().Format(time.RFC3339)
+	annotations[DebtSuspendedAnnotation] = "true"
+	annotations[DebtSuspendedAtAnnotation] = s.clock.Now().Format(time.RFC3339)
 	
 	// 清空spec但保留备份信息
 	resource.SetAnnotations(annotations)
@@ -2903,15 +4250,39 @@ func (s *NetworkStrategy) backupAndClearResource(ctx context.Context, namespace
 		unstructured.SetNestedMap(resource.Object, map[string]interface{}{}, "spec")
 	}
 	
-	_, err = s.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, resource, v12.UpdateOptions{})
-	return err
+	return true, nil
 }
 
 // restoreResource 恢复资源配置
 func (s *NetworkStrategy) restoreResource(ctx context.Context, namespace string, resource *unstructured.Unstructured, gvr schema.GroupVersionResource) error {
+	var backupLocation string
+	resourceClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
+	updated := false
+	
+	err := retryUnstructuredUpdateOnConflict(ctx, resourceClient, resource, func(obj *unstructured.Unstructured) (bool, error) {
+		var err error
+		backupLocation, err = s.applyRestoration(ctx, namespace, obj)
+		updated = err == nil && backupLocation != ""
+		return updated, err
+	})
+	if err != nil || !updated {
+		return err
+	}
+	
+	// 清理ConfigMap备份
+	if backupLocation == "configmap" {
+		configMapName := fmt.Sprintf("%s-%s-backup", resource.GetName(), gvr.Resource)
+		s.deleteBackupConfigMap(ctx, namespace, configMapName)
+	}
+	
+	return nil
+}
+
+// applyRestoration 在资源对象上恢复备份配置，返回备份位置（资源未暂停时返回空字符串）
+func (s *NetworkStrategy) applyRestoration(ctx context.Context, namespace string, resource *unstructured.Unstructured) (string, error) {
 	annotations := resource.GetAnnotations()
-	if annotations == nil || annotations["debt.sealos.io/suspended"] != "true" {
-		return nil // 资源未被暂停
+	if annotations == nil || annotations[DebtSuspendedAnnotation] != "true" {
+		return "", nil // 资源未被暂停
 	}
 	
 	var backupData []byte
@@ -2922,16 +4293,23 @@ func (s *NetworkStrategy) restoreResource(ctx context.Context, namespace string,
 		configMapName := annotations["debt.sealos.io/backup-configmap"]
 		backupData, err = s.loadBackupFromConfigMap(ctx, namespace, configMapName)
 		if err != nil {
-			return err
+			return "", err
 		}
 	} else {
+		backupLocation = "annotation"
 		backupData = []byte(annotations["debt.sealos.io/backup-data"])
 	}
 	
+	// 校验备份完整性，校验失败时拒绝恢复，避免应用损坏的配置
+	if err := verifyBackupChecksum(backupData, annotations[DebtBackupChecksumAnnotation]); err != nil {
+		errorTotal.WithLabelValues("resume", "backup_checksum", StrategyNetwork).Inc()
+		return "", fmt.Errorf("资源 %s/%s 备份校验失败: %w", namespace, resource.GetName(), err)
+	}
+	
 	// 恢复备份数据
 	var backup map[string]interface{}
 	if err := json.Unmarshal(backupData, &backup); err != nil {
-		return err
+		return "", err
 	}
 	
 	// 恢复spec
@@ -2939,41 +4317,24 @@ func (s *NetworkStrategy) restoreResource(ctx context.Context, namespace string,
 		unstructured.SetNestedMap(resource.Object, spec.(map[string]interface{}), "spec")
 	}
 	
-	// 恢复metadata（除了暂停相关的注解）
-	if metadata, exists := backup["metadata"]; exists {
-		metadataMap := metadata.(map[string]interface{})
-		if labels, exists := metadataMap["labels"]; exists && labels != nil {
-			resource.SetLabels(labels.(map[string]string))
+	// 清理暂停相关的注解，保留暂停期间新增的其他注解
+	restored := stripDebtAnnotations(annotations)
+	
+	// 恢复metadata，备份中的注解（如云厂商LB配置）原样覆盖
+	if metadata, ok := backup["metadata"].(map[string]interface{}); ok {
+		if labels, exists := metadata["labels"]; exists && labels != nil {
+			resource.SetLabels(toStringMap(labels))
 		}
-		if backupAnnotations, exists := metadataMap["annotations"]; exists && backupAnnotations != nil {
-			for k, v := range backupAnnotations.(map[string]string) {
-				annotations[k] = v
+		if backupAnnotations, exists := metadata["annotations"]; exists && backupAnnotations != nil {
+			for k, v := range stripDebtAnnotations(toStringMap(backupAnnotations)) {
+				restored[k] = v
 			}
 		}
 	}
 	
-	// 清理暂停相关的注解
-	delete(annotations, "debt.sealos.io/suspended")
-	delete(annotations, "debt.sealos.io/suspended-at")
-	delete(annotations, "debt.sealos.io/backup-data")
-	delete(annotations, "debt.sealos.io/backup-location")
-	delete(annotations, "debt.sealos.io/backup-configmap")
-	
-	resource.SetAnnotations(annotations)
+	resource.SetAnnotations(restored)
 	
-	// 更新资源
-	_, err = s.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, resource, v12.UpdateOptions{})
-	if err != nil {
-		return err
-	}
-	
-	// 清理ConfigMap备份
-	if backupLocation == "configmap" {
-		configMapName := fmt.Sprintf("%s-%s-backup", resource.GetName(), gvr.Resource)
-		s.deleteBackupConfigMap(ctx, namespace, configMapName)
-	}
-	
-	return nil
+	return backupLocation, nil
 }
 
 // storeBackupInConfigMap 在ConfigMap中存储备份数据
@@ -2993,7 +4354,20 @@ func (s *NetworkStrategy) storeBackupInConfigMap(ctx context.Context, namespace,
 		},
 	}
 	
-	return s.client.Create(ctx, configMap)
+	if err := s.client.Create(ctx, configMap); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			return err
+		}
+		// 更新冲突重试时备份ConfigMap可能已存在，覆盖为最新备份
+		existing := &corev1.ConfigMap{}
+		if err := s.client.Get(ctx, client.ObjectKeyFromObject(configMap), existing); err != nil {
+			return err
+		}
+		existing.Data = configMap.Data
+		return s.client.Update(ctx, existing)
+	}
+	
+	return nil
 }
 
 // loadBackupFromConfigMap 从ConfigMap加载备份数据
@@ -3049,6 +4423,9 @@ func (s *RBACStrategy) Suspend(ctx context.Context, namespace string) error {
 	if err := s.backupAndModifyRoleBindings(ctx, namespace); err != nil {
 		return err
 	}
+	if isDryRun(ctx) {
+		return nil
+	}
 	
 	// 更新缓存
 	s.cache.SetSuspended(namespace, StrategyRBAC, true)
@@ -3103,6 +4480,10 @@ func (s *RBACStrategy) createRestrictedRole(ctx context.Context, namespace strin
 		},
 	}
 	
+	if report := dryRunReportFrom(ctx); report != nil {
+		report.add(StrategyRBAC, "Role", restrictedRole.Name)
+		return nil
+	}
 	return s.client.Create(ctx, restrictedRole)
 }
 
@@ -3151,6 +4532,10 @@ func (s *RBACStrategy) backupAndModifyRoleBindings(ctx context.Context, namespac
 		
 		rb.SetAnnotations(annotations)
 		
+		if report := dryRunReportFrom(ctx); report != nil {
+			report.add(StrategyRBAC, "RoleBinding", rb.Name)
+			continue
+		}
 		if err := s.client.Update(ctx, &rb); err != nil {
 			return err
 		}
@@ -3194,3 +4579,488 @@ func (s *RBACStrategy) restoreRoleBindings(ctx context.Context, namespace string
 	
 	return nil
 }
+
+// ====================== ScalableStrategy 实现 ======================
+
+// GetName 获取策略名称
+func (s *ScalableStrategy) GetName() string {
+	return StrategyScalable
+}
+
+// IsSupported 检查是否支持指定资源类型
+func (s *ScalableStrategy) IsSupported(resourceType string) bool {
+	for _, res := range s.resources {
+		if gvr, err := parseScalableGVR(res.GVR); err == nil && gvr.Resource == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Suspend 按配置补丁缩容可伸缩资源
+func (s *ScalableStrategy) Suspend(ctx context.Context, namespace string) error {
+	// 检查缓存
+	if suspended, found := s.cache.IsSuspended(namespace, StrategyScalable); found && suspended {
+		return nil
+	}
+	
+	for _, res := range s.resources {
+		if err := s.suspendResources(ctx, namespace, res); err != nil {
+			return err
+		}
+	}
+	if isDryRun(ctx) {
+		return nil
+	}
+	
+	// 更新缓存
+	s.cache.SetSuspended(namespace, StrategyScalable, true)
+	resourceCount.WithLabelValues(namespace, "Scalable", StrategyScalable).Inc()
+	
+	return nil
+}
+
+// Resume 恢复可伸缩资源
+func (s *ScalableStrategy) Resume(ctx context.Context, namespace string) error {
+	restored := 0
+	for _, res := range s.resources {
+		count, err := s.resumeResources(ctx, namespace, res)
+		if err != nil {
+			return err
+		}
+		restored += count
+	}
+	
+	// 更新缓存，仅在确有资源被恢复时扣减计数，与 Suspend 的计数保持对称
+	s.cache.SetSuspended(namespace, StrategyScalable, false)
+	if restored > 0 {
+		resourceCount.WithLabelValues(namespace, "Scalable", StrategyScalable).Dec()
+	}
+	
+	return nil
+}
+
+// suspendResources 对单个GVR的所有资源应用暂停补丁，并备份被覆盖的原始值
+func (s *ScalableStrategy) suspendResources(ctx context.Context, namespace string, res ScalableResourceConfig) error {
+	gvr, err := parseScalableGVR(res.GVR)
+	if err != nil {
+		return err
+	}
+	patch, err := decodeMergePatch(res.SuspendPatch)
+	if err != nil {
+		return fmt.Errorf("invalid suspend patch for %s: %w", res.GVR, err)
+	}
+	
+	resourceClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
+	resources, err := resourceClient.List(ctx, v12.ListOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // CRD未安装
+		}
+		return err
+	}
+	
+	for i := range resources.Items {
+		if err := updateUnstructuredOrReport(ctx, resourceClient, &resources.Items[i], StrategyScalable, func(obj *unstructured.Unstructured) (bool, error) {
+			// 已暂停的资源保留首次备份
+			if obj.GetAnnotations()[DebtScaleSuspendedAnnotation] == "true" {
+				return false, nil
+			}
+			
+			backup, err := json.Marshal(buildMergePatchBackup(obj.Object, patch))
+			if err != nil {
+				return false, err
+			}
+			
+			applyMergePatch(obj.Object, patch)
+			
+			annotations := obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[DebtScaleSuspendedAnnotation] = "true"
+			annotations[DebtScaleSuspendedAtAnnotation] = s.clock.Now().Format(time.RFC3339)
+			annotations[DebtScaleBackupAnnotation] = string(backup)
+			obj.SetAnnotations(annotations)
+			return true, nil
+		}); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// resumeResources 对单个GVR的所有已暂停资源应用恢复补丁或原始备份，返回恢复的资源数量
+func (s *ScalableStrategy) resumeResources(ctx context.Context, namespace string, res ScalableResourceConfig) (int, error) {
+	gvr, err := parseScalableGVR(res.GVR)
+	if err != nil {
+		return 0, err
+	}
+	var resumePatch map[string]interface{}
+	if res.ResumePatch != "" {
+		if resumePatch, err = decodeMergePatch(res.ResumePatch); err != nil {
+			return 0, fmt.Errorf("invalid resume patch for %s: %w", res.GVR, err)
+		}
+	}
+	
+	resourceClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
+	resources, err := resourceClient.List(ctx, v12.ListOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	
+	restored := 0
+	for i := range resources.Items {
+		// 冲突重试时回调会重复执行，只按最后一次判断计数
+		changed := false
+		if err := retryUnstructuredUpdateOnConflict(ctx, resourceClient, &resources.Items[i], func(obj *unstructured.Unstructured) (bool, error) {
+			changed = false
+			annotations := obj.GetAnnotations()
+			if annotations[DebtScaleSuspendedAnnotation] != "true" {
+				return false, nil
+			}
+			
+			patch := resumePatch
+			if patch == nil {
+				backup, err := decodeMergePatch(annotations[DebtScaleBackupAnnotation])
+				if err != nil {
+					return false, fmt.Errorf("invalid scale backup on %s: %w", obj.GetName(), err)
+				}
+				patch = backup
+			}
+			applyMergePatch(obj.Object, patch)
+			
+			// 补丁可能修改了注解，重新读取后清理暂停标记
+			annotations = obj.GetAnnotations()
+			delete(annotations, DebtScaleSuspendedAnnotation)
+			delete(annotations, DebtScaleSuspendedAtAnnotation)
+			delete(annotations, DebtScaleBackupAnnotation)
+			obj.SetAnnotations(annotations)
+			changed = true
+			return true, nil
+		}); err != nil {
+			return restored, err
+		}
+		if changed {
+			restored++
+		}
+	}
+	
+	return restored, nil
+}
+
+// ====================== WorkloadStrategy 实现 ======================
+
+// workloadGVRs WorkloadStrategy 缩容的工作负载类型
+var workloadGVRs = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+}
+
+// GetName 获取策略名称
+func (s *WorkloadStrategy) GetName() string {
+	return StrategyWorkload
+}
+
+// IsSupported 检查是否支持指定资源类型
+func (s *WorkloadStrategy) IsSupported(resourceType string) bool {
+	return resourceType == "Deployment" || resourceType == "StatefulSet"
+}
+
+// Suspend 记录原副本数并缩容到零
+func (s *WorkloadStrategy) Suspend(ctx context.Context, namespace string) error {
+	// 检查缓存
+	if suspended, found := s.cache.IsSuspended(namespace, StrategyWorkload); found && suspended {
+		return nil
+	}
+	
+	overrides := appStrategyOverridesFrom(ctx)
+	for _, gvr := range workloadGVRs {
+		resourceClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
+		workloads, err := resourceClient.List(ctx, v12.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		for i := range workloads.Items {
+			if !workloadAppliesCompute(overrides, &workloads.Items[i]) {
+				continue
+			}
+			if err := updateUnstructuredOrReport(ctx, resourceClient, &workloads.Items[i], StrategyWorkload, func(obj *unstructured.Unstructured) (bool, error) {
+				// 已暂停的工作负载保留首次记录的副本数
+				if obj.GetAnnotations()[DebtSuspendedAnnotation] == "true" {
+					return false, nil
+				}
+				replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+				if err != nil {
+					return false, err
+				}
+				if !found {
+					replicas = 1 // 未设置时 Kubernetes 默认 1 个副本
+				}
+				// 已经是零副本，无需缩容，恢复时也保持不变
+				if replicas == 0 {
+					return false, nil
+				}
+				
+				annotations := obj.GetAnnotations()
+				if annotations == nil {
+					annotations = make(map[string]string)
+				}
+				annotations[DebtSuspendedAnnotation] = "true"
+				annotations[DebtSuspendedAtAnnotation] = s.clock.Now().Format(time.RFC3339)
+				annotations[DebtOriginalReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+				obj.SetAnnotations(annotations)
+				return true, unstructured.SetNestedField(obj.Object, int64(0), "spec", "replicas")
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if isDryRun(ctx) {
+		return nil
+	}
+	
+	// 更新缓存
+	s.cache.SetSuspended(namespace, StrategyWorkload, true)
+	resourceCount.WithLabelValues(namespace, "Workload", StrategyWorkload).Inc()
+	
+	return nil
+}
+
+// Resume 恢复暂停前记录的副本数
+func (s *WorkloadStrategy) Resume(ctx context.Context, namespace string) error {
+	for _, gvr := range workloadGVRs {
+		resourceClient := s.dynamicClient.Resource(gvr).Namespace(namespace)
+		workloads, err := resourceClient.List(ctx, v12.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		for i := range workloads.Items {
+			if err := retryUnstructuredUpdateOnConflict(ctx, resourceClient, &workloads.Items[i], func(obj *unstructured.Unstructured) (bool, error) {
+				annotations := obj.GetAnnotations()
+				if annotations[DebtSuspendedAnnotation] != "true" {
+					return false, nil
+				}
+				if original, ok := annotations[DebtOriginalReplicasAnnotation]; ok {
+					replicas, err := strconv.ParseInt(original, 10, 32)
+					if err != nil {
+						return false, fmt.Errorf("invalid original replicas on %s %s: %w", obj.GetKind(), obj.GetName(), err)
+					}
+					if err := unstructured.SetNestedField(obj.Object, replicas, "spec", "replicas"); err != nil {
+						return false, err
+					}
+				}
+				delete(annotations, DebtSuspendedAnnotation)
+				delete(annotations, DebtSuspendedAtAnnotation)
+				delete(annotations, DebtOriginalReplicasAnnotation)
+				obj.SetAnnotations(annotations)
+				return true, nil
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	
+	// 更新缓存
+	s.cache.SetSuspended(namespace, StrategyWorkload, false)
+	resourceCount.WithLabelValues(namespace, "Workload", StrategyWorkload).Dec()
+	
+	return nil
+}
+
+// workloadAppliesCompute 判断应用级策略覆盖是否允许停止该工作负载的计算资源，
+// 与 loadAppStrategyOverrides 一致，未设置应用标签时按工作负载名称匹配
+func workloadAppliesCompute(overrides AppStrategyOverrides, workload *unstructured.Unstructured) bool {
+	labels := workload.GetLabels()
+	if labels[resources.AppDeployLabelKey] == "" {
+		labels = map[string]string{resources.AppDeployLabelKey: workload.GetName()}
+	}
+	return overrides.Applies(labels, StrategyCompute)
+}
+
+// ====================== PVCStrategy 实现 ======================
+
+// GetName 获取策略名称
+func (s *PVCStrategy) GetName() string {
+	return StrategyPVC
+}
+
+// IsSupported 检查是否支持指定资源类型
+func (s *PVCStrategy) IsSupported(resourceType string) bool {
+	return resourceType == "PersistentVolumeClaim"
+}
+
+// Suspend 将支持的存储类的PVC切换到只读 VolumeAttributesClass
+func (s *PVCStrategy) Suspend(ctx context.Context, namespace string) error {
+	// 检查缓存
+	if suspended, found := s.cache.IsSuspended(namespace, StrategyPVC); found && suspended {
+		return nil
+	}
+	
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := s.client.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	
+	overrides := appStrategyOverridesFrom(ctx)
+	for i := range pvcs.Items {
+		readOnlyClass, supported := s.readOnlyClass(&pvcs.Items[i])
+		if !supported || !overrides.Applies(pvcs.Items[i].Labels, StrategyPVC) {
+			continue
+		}
+		if err := s.updatePVC(ctx, client.ObjectKeyFromObject(&pvcs.Items[i]), func(pvc *corev1.PersistentVolumeClaim) bool {
+			// 已暂停的PVC保留首次备份
+			if pvc.Annotations[DebtSuspendedAnnotation] == "true" {
+				return false
+			}
+			if pvc.Annotations == nil {
+				pvc.Annotations = make(map[string]string)
+			}
+			pvc.Annotations[DebtSuspendedAnnotation] = "true"
+			pvc.Annotations[DebtSuspendedAtAnnotation] = s.clock.Now().Format(time.RFC3339)
+			pvc.Annotations[DebtVolumeAttributesClassBackupAnnotation] = ptr.Deref(pvc.Spec.VolumeAttributesClassName, "")
+			pvc.Spec.VolumeAttributesClassName = ptr.To(readOnlyClass)
+			return true
+		}); err != nil {
+			return err
+		}
+	}
+	if isDryRun(ctx) {
+		return nil
+	}
+	
+	// 更新缓存
+	s.cache.SetSuspended(namespace, StrategyPVC, true)
+	resourceCount.WithLabelValues(namespace, "PVC", StrategyPVC).Inc()
+	
+	return nil
+}
+
+// Resume 恢复PVC暂停前的 VolumeAttributesClass
+func (s *PVCStrategy) Resume(ctx context.Context, namespace string) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := s.client.List(ctx, pvcs, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	
+	for i := range pvcs.Items {
+		if pvcs.Items[i].Annotations[DebtSuspendedAnnotation] != "true" {
+			continue
+		}
+		if err := s.updatePVC(ctx, client.ObjectKeyFromObject(&pvcs.Items[i]), func(pvc *corev1.PersistentVolumeClaim) bool {
+			if pvc.Annotations[DebtSuspendedAnnotation] != "true" {
+				return false
+			}
+			if original := pvc.Annotations[DebtVolumeAttributesClassBackupAnnotation]; original != "" {
+				pvc.Spec.VolumeAttributesClassName = ptr.To(original)
+			} else {
+				pvc.Spec.VolumeAttributesClassName = nil
+			}
+			delete(pvc.Annotations, DebtSuspendedAnnotation)
+			delete(pvc.Annotations, DebtSuspendedAtAnnotation)
+			delete(pvc.Annotations, DebtVolumeAttributesClassBackupAnnotation)
+			return true
+		}); err != nil {
+			return err
+		}
+	}
+	
+	// 更新缓存
+	s.cache.SetSuspended(namespace, StrategyPVC, false)
+	resourceCount.WithLabelValues(namespace, "PVC", StrategyPVC).Dec()
+	
+	return nil
+}
+
+// readOnlyClass 返回PVC存储类对应的只读 VolumeAttributesClass，存储类未配置时不支持
+func (s *PVCStrategy) readOnlyClass(pvc *corev1.PersistentVolumeClaim) (string, bool) {
+	storageClass := ptr.Deref(pvc.Spec.StorageClassName, "")
+	if storageClass == "" {
+		return "", false
+	}
+	readOnlyClass, ok := s.readOnlyClasses[storageClass]
+	return readOnlyClass, ok && readOnlyClass != ""
+}
+
+// updatePVC 冲突时重新读取并重试，mutate 返回 false 时跳过更新
+func (s *PVCStrategy) updatePVC(ctx context.Context, key client.ObjectKey, mutate func(*corev1.PersistentVolumeClaim) bool) error {
+	return clientretry.RetryOnConflict(clientretry.DefaultRetry, func() error {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := s.client.Get(ctx, key, pvc); err != nil {
+			return err
+		}
+		if !mutate(pvc) {
+			return nil
+		}
+		if report := dryRunReportFrom(ctx); report != nil {
+			report.add(StrategyPVC, "PersistentVolumeClaim", pvc.Name)
+			return nil
+		}
+		return s.client.Update(ctx, pvc)
+	})
+}
+
+// parseScalableGVR 解析 group/version/resource 格式的GVR，核心组可省略为 version/resource
+func parseScalableGVR(raw string) (schema.GroupVersionResource, error) {
+	parts := strings.Split(strings.Trim(raw, "/"), "/")
+	switch len(parts) {
+	case 2:
+		return schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}, nil
+	case 3:
+		return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, nil
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("invalid gvr %q, expected group/version/resource", raw)
+}
+
+// decodeMergePatch 解析 JSON 合并补丁，整数保持为 int64
+func decodeMergePatch(raw string) (map[string]interface{}, error) {
+	patch := map[string]interface{}{}
+	if err := utiljson.Unmarshal([]byte(raw), &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// applyMergePatch 按 RFC 7386 将合并补丁应用到对象：null 删除字段，对象递归合并，其他值直接替换
+func applyMergePatch(obj, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(obj, key)
+			continue
+		}
+		if patchMap, ok := value.(map[string]interface{}); ok {
+			current, ok := obj[key].(map[string]interface{})
+			if !ok {
+				current = map[string]interface{}{}
+			}
+			applyMergePatch(current, patchMap)
+			obj[key] = current
+			continue
+		}
+		obj[key] = runtime.DeepCopyJSONValue(value)
+	}
+}
+
+// buildMergePatchBackup 生成能撤销 patch 的合并补丁：记录被覆盖字段的原始值，原本不存在的字段记为 null
+func buildMergePatchBackup(obj, patch map[string]interface{}) map[string]interface{} {
+	backup := map[string]interface{}{}
+	for key, value := range patch {
+		current, exists := obj[key]
+		if !exists {
+			backup[key] = nil
+			continue
+		}
+		patchMap, patchIsMap := value.(map[string]interface{})
+		currentMap, currentIsMap := current.(map[string]interface{})
+		if patchIsMap && currentIsMap {
+			backup[key] = buildMergePatchBackup(currentMap, patchMap)
+			continue
+		}
+		backup[key] = runtime.DeepCopyJSONValue(current)
+	}
+	return backup
+}
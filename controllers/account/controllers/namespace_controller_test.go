@@ -0,0 +1,2444 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/madmin-go/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1 "github.com/labring/sealos/controllers/account/api/v1"
+	"github.com/labring/sealos/controllers/pkg/resources"
+)
+
+var (
+	testCertificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+	testServiceGVR     = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}
+)
+
+func newTestCertificate(name, namespace string) *unstructured.Unstructured {
+	cert := &unstructured.Unstructured{}
+	cert.SetAPIVersion("cert-manager.io/v1")
+	cert.SetKind("Certificate")
+	cert.SetName(name)
+	cert.SetNamespace(namespace)
+	_ = unstructured.SetNestedField(cert.Object, name+"-tls", "spec", "secretName")
+	return cert
+}
+
+func newTestDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testCertificateGVR: "CertificateList",
+			testServiceGVR:     "ServiceList",
+		}, objects...)
+}
+
+// injectUpdateConflicts 让前 n 次 update 返回版本冲突
+func injectUpdateConflicts(client *dynamicfake.FakeDynamicClient, resource string, n int) *int {
+	attempts := 0
+	client.PrependReactor("update", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		attempts++
+		if attempts <= n {
+			obj := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+			return true, nil, errors.NewConflict(schema.GroupResource{Group: testCertificateGVR.Group, Resource: resource}, obj.GetName(), nil)
+		}
+		return false, nil, nil
+	})
+	return &attempts
+}
+
+func TestRetryUnstructuredUpdateOnConflict(t *testing.T) {
+	namespace := "ns-test"
+	client := newTestDynamicClient(newTestCertificate("cert", namespace))
+	attempts := injectUpdateConflicts(client, "certificates", 1)
+
+	ctx := context.Background()
+	resourceClient := client.Resource(testCertificateGVR).Namespace(namespace)
+	obj, err := resourceClient.Get(ctx, "cert", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get certificate: %v", err)
+	}
+
+	mutations := 0
+	err = retryUnstructuredUpdateOnConflict(ctx, resourceClient, obj, func(obj *unstructured.Unstructured) (bool, error) {
+		mutations++
+		obj.SetAnnotations(map[string]string{"debt.sealos.io/suspended": "true"})
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("retryUnstructuredUpdateOnConflict() error = %v", err)
+	}
+	if *attempts != 2 {
+		t.Errorf("update attempts = %d, want 2", *attempts)
+	}
+	if mutations != 2 {
+		t.Errorf("mutate calls = %d, want 2", mutations)
+	}
+
+	got, err := resourceClient.Get(ctx, "cert", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get certificate: %v", err)
+	}
+	if got.GetAnnotations()["debt.sealos.io/suspended"] != "true" {
+		t.Errorf("annotations = %v, want suspended annotation", got.GetAnnotations())
+	}
+}
+
+func TestRetryUnstructuredUpdateOnConflict_SkipUpdate(t *testing.T) {
+	namespace := "ns-test"
+	client := newTestDynamicClient(newTestCertificate("cert", namespace))
+	attempts := injectUpdateConflicts(client, "certificates", 0)
+
+	ctx := context.Background()
+	resourceClient := client.Resource(testCertificateGVR).Namespace(namespace)
+	obj, err := resourceClient.Get(ctx, "cert", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get certificate: %v", err)
+	}
+
+	err = retryUnstructuredUpdateOnConflict(ctx, resourceClient, obj, func(obj *unstructured.Unstructured) (bool, error) {
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("retryUnstructuredUpdateOnConflict() error = %v", err)
+	}
+	if *attempts != 0 {
+		t.Errorf("update attempts = %d, want 0", *attempts)
+	}
+}
+
+func TestCertManagerStrategy_SuspendResumeWithConflict(t *testing.T) {
+	namespace := "ns-test"
+	client := newTestDynamicClient(newTestCertificate("cert", namespace))
+	injectUpdateConflicts(client, "certificates", 1)
+
+	strategy := &CertManagerStrategy{dynamicClient: client, cache: NewResourceCache(DefaultCacheTTL)}
+	ctx := context.Background()
+
+	if err := strategy.suspendCertificates(ctx, namespace); err != nil {
+		t.Fatalf("suspendCertificates() error = %v", err)
+	}
+
+	resourceClient := client.Resource(testCertificateGVR).Namespace(namespace)
+	got, err := resourceClient.Get(ctx, "cert", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get certificate: %v", err)
+	}
+	if got.GetAnnotations()["debt.sealos.io/suspended"] != "true" {
+		t.Fatalf("annotations = %v, want suspended annotation", got.GetAnnotations())
+	}
+
+	injectUpdateConflicts(client, "certificates", 1)
+	if err := strategy.resumeCertificates(ctx, namespace); err != nil {
+		t.Fatalf("resumeCertificates() error = %v", err)
+	}
+
+	got, err = resourceClient.Get(ctx, "cert", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get certificate: %v", err)
+	}
+	if _, ok := got.GetAnnotations()["debt.sealos.io/suspended"]; ok {
+		t.Errorf("annotations = %v, want suspended annotation removed", got.GetAnnotations())
+	}
+}
+
+func TestNetworkStrategy_PreservesServiceAnnotations(t *testing.T) {
+	namespace := "ns-test"
+	cloudAnnotations := map[string]string{
+		"service.beta.kubernetes.io/aws-load-balancer-type":             "nlb",
+		"service.beta.kubernetes.io/aws-load-balancer-internal":         "false",
+		"service.beta.kubernetes.io/aws-load-balancer-backend-protocol": "tcp",
+	}
+
+	svc := &unstructured.Unstructured{}
+	svc.SetAPIVersion("v1")
+	svc.SetKind("Service")
+	svc.SetName("game")
+	svc.SetNamespace(namespace)
+	svc.SetLabels(map[string]string{"app": "game"})
+	svc.SetAnnotations(cloudAnnotations)
+	_ = unstructured.SetNestedField(svc.Object, "LoadBalancer", "spec", "type")
+	_ = unstructured.SetNestedSlice(svc.Object, []interface{}{
+		map[string]interface{}{"name": "game", "port": int64(7777), "protocol": "TCP"},
+	}, "spec", "ports")
+
+	client := newTestDynamicClient(svc)
+	strategy := &NetworkStrategy{dynamicClient: client, cache: NewResourceCache(DefaultCacheTTL)}
+	ctx := context.Background()
+
+	if err := strategy.suspendResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+		t.Fatalf("suspendResourcesByGVR() error = %v", err)
+	}
+	// 重复暂停不应覆盖原始备份
+	if err := strategy.suspendResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+		t.Fatalf("second suspendResourcesByGVR() error = %v", err)
+	}
+
+	resourceClient := client.Resource(testServiceGVR).Namespace(namespace)
+	suspended, err := resourceClient.Get(ctx, "game", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if suspended.GetAnnotations()[DebtSuspendedAnnotation] != "true" {
+		t.Fatalf("annotations = %v, want suspended annotation", suspended.GetAnnotations())
+	}
+
+	if err := strategy.resumeResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+		t.Fatalf("resumeResourcesByGVR() error = %v", err)
+	}
+
+	restored, err := resourceClient.Get(ctx, "game", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+
+	annotations := restored.GetAnnotations()
+	if len(annotations) != len(cloudAnnotations) {
+		t.Errorf("restored annotations = %v, want %v", annotations, cloudAnnotations)
+	}
+	for k, v := range cloudAnnotations {
+		if annotations[k] != v {
+			t.Errorf("annotation %s = %q, want %q", k, annotations[k], v)
+		}
+	}
+	for k := range annotations {
+		if strings.HasPrefix(k, DebtAnnotationPrefix) {
+			t.Errorf("debt annotation %s should be removed after restore", k)
+		}
+	}
+	if restored.GetLabels()["app"] != "game" {
+		t.Errorf("restored labels = %v, want app=game", restored.GetLabels())
+	}
+	ports, _, _ := unstructured.NestedSlice(restored.Object, "spec", "ports")
+	if len(ports) != 1 {
+		t.Errorf("restored ports = %v, want 1 port", ports)
+	}
+	if svcType, _, _ := unstructured.NestedString(restored.Object, "spec", "type"); svcType != "LoadBalancer" {
+		t.Errorf("restored type = %q, want LoadBalancer", svcType)
+	}
+}
+
+func TestNetworkStrategy_KeepsOtherDebtAnnotations(t *testing.T) {
+	namespace := "ns-test"
+	userAnnotations := map[string]string{
+		DebtStrategiesAnnotation: StrategyNetwork,
+		DebtSuspendAtAnnotation:  "2025-01-01T00:00:00Z",
+		"app.sealos.io/owner":    "team-a",
+	}
+
+	svc := &unstructured.Unstructured{}
+	svc.SetAPIVersion("v1")
+	svc.SetKind("Service")
+	svc.SetName("web")
+	svc.SetNamespace(namespace)
+	svc.SetAnnotations(userAnnotations)
+	_ = unstructured.SetNestedSlice(svc.Object, []interface{}{
+		map[string]interface{}{"name": "http", "port": int64(80), "protocol": "TCP"},
+	}, "spec", "ports")
+
+	client := newTestDynamicClient(svc)
+	strategy := &NetworkStrategy{dynamicClient: client, cache: NewResourceCache(DefaultCacheTTL)}
+	ctx := context.Background()
+
+	if err := strategy.suspendResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+		t.Fatalf("suspendResourcesByGVR() error = %v", err)
+	}
+	resourceClient := client.Resource(testServiceGVR).Namespace(namespace)
+	suspended, err := resourceClient.Get(ctx, "web", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	for k, v := range userAnnotations {
+		if suspended.GetAnnotations()[k] != v {
+			t.Errorf("suspended annotation %s = %q, want %q", k, suspended.GetAnnotations()[k], v)
+		}
+	}
+
+	if err := strategy.resumeResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+		t.Fatalf("resumeResourcesByGVR() error = %v", err)
+	}
+	restored, err := resourceClient.Get(ctx, "web", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if !reflect.DeepEqual(restored.GetAnnotations(), userAnnotations) {
+		t.Errorf("restored annotations = %v, want %v", restored.GetAnnotations(), userAnnotations)
+	}
+}
+
+var testScalerGVR = schema.GroupVersionResource{Group: "apps.example.com", Version: "v1", Resource: "scalers"}
+
+func newTestScaler(name, namespace string, replicas *int64) *unstructured.Unstructured {
+	scaler := &unstructured.Unstructured{}
+	scaler.SetAPIVersion("apps.example.com/v1")
+	scaler.SetKind("Scaler")
+	scaler.SetName(name)
+	scaler.SetNamespace(namespace)
+	scaler.SetAnnotations(map[string]string{"owner": "team-a"})
+	_ = unstructured.SetNestedField(scaler.Object, "nginx", "spec", "image")
+	if replicas != nil {
+		_ = unstructured.SetNestedField(scaler.Object, *replicas, "spec", "replicas")
+	}
+	return scaler
+}
+
+func newTestScalableStrategy(resumePatch string, objects ...runtime.Object) (*ScalableStrategy, *dynamicfake.FakeDynamicClient) {
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{testScalerGVR: "ScalerList"}, objects...)
+	return &ScalableStrategy{
+		dynamicClient: client,
+		cache:         NewResourceCache(DefaultCacheTTL),
+		resources: []ScalableResourceConfig{{
+			GVR:          "apps.example.com/v1/scalers",
+			SuspendPatch: `{"spec":{"replicas":0}}`,
+			ResumePatch:  resumePatch,
+		}},
+	}, client
+}
+
+func TestScalableStrategy_SuspendResume(t *testing.T) {
+	namespace := "ns-test"
+	replicas := int64(3)
+	strategy, client := newTestScalableStrategy("",
+		newTestScaler("with-replicas", namespace, &replicas),
+		newTestScaler("without-replicas", namespace, nil))
+	ctx := context.Background()
+	resourceClient := client.Resource(testScalerGVR).Namespace(namespace)
+
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	// 重复暂停不应覆盖原始备份
+	strategy.cache.ClearNamespace(namespace)
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("second Suspend() error = %v", err)
+	}
+
+	for _, name := range []string{"with-replicas", "without-replicas"} {
+		got, err := resourceClient.Get(ctx, name, v12.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", name, err)
+		}
+		if r, _, _ := unstructured.NestedInt64(got.Object, "spec", "replicas"); r != 0 {
+			t.Errorf("%s suspended replicas = %d, want 0", name, r)
+		}
+		if got.GetAnnotations()[DebtScaleSuspendedAnnotation] != "true" {
+			t.Errorf("%s annotations = %v, want suspended annotation", name, got.GetAnnotations())
+		}
+	}
+
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	got, err := resourceClient.Get(ctx, "with-replicas", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get with-replicas: %v", err)
+	}
+	if r, _, _ := unstructured.NestedInt64(got.Object, "spec", "replicas"); r != replicas {
+		t.Errorf("restored replicas = %d, want %d", r, replicas)
+	}
+	if image, _, _ := unstructured.NestedString(got.Object, "spec", "image"); image != "nginx" {
+		t.Errorf("restored image = %q, want nginx", image)
+	}
+	annotations := got.GetAnnotations()
+	if len(annotations) != 1 || annotations["owner"] != "team-a" {
+		t.Errorf("restored annotations = %v, want only owner", annotations)
+	}
+
+	got, err = resourceClient.Get(ctx, "without-replicas", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get without-replicas: %v", err)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(got.Object, "spec", "replicas"); found {
+		t.Errorf("replicas should be removed when it was absent before suspension: %v", got.Object["spec"])
+	}
+}
+
+func TestScalableStrategy_ResumePatch(t *testing.T) {
+	namespace := "ns-test"
+	replicas := int64(3)
+	strategy, client := newTestScalableStrategy(`{"spec":{"replicas":1}}`, newTestScaler("scaler", namespace, &replicas))
+	ctx := context.Background()
+
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	got, err := client.Resource(testScalerGVR).Namespace(namespace).Get(ctx, "scaler", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get scaler: %v", err)
+	}
+	if r, _, _ := unstructured.NestedInt64(got.Object, "spec", "replicas"); r != 1 {
+		t.Errorf("resumed replicas = %d, want 1", r)
+	}
+}
+
+func TestScalableStrategy_ResumeCountsRestoredResources(t *testing.T) {
+	namespace := "ns-resume-count"
+	replicas := int64(3)
+	strategy, _ := newTestScalableStrategy("", newTestScaler("scaler", namespace, &replicas))
+	ctx := context.Background()
+	gauge := resourceCount.WithLabelValues(namespace, "Scalable", StrategyScalable)
+	defer resourceCount.DeleteLabelValues(namespace, "Scalable", StrategyScalable)
+
+	// 没有暂停过的资源时恢复不应扣减计数
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("resource count after resuming nothing = %v, want 0", got)
+	}
+
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	// 重复恢复时资源已无暂停标记
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("second Resume() error = %v", err)
+	}
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("resource count after suspend and repeated resume = %v, want 0", got)
+	}
+}
+
+func TestParseScalableGVR(t *testing.T) {
+	tests := []struct {
+		raw         string
+		expected    schema.GroupVersionResource
+		expectError bool
+	}{
+		{raw: "serving.knative.dev/v1/services", expected: schema.GroupVersionResource{Group: "serving.knative.dev", Version: "v1", Resource: "services"}},
+		{raw: "v1/replicationcontrollers", expected: schema.GroupVersionResource{Version: "v1", Resource: "replicationcontrollers"}},
+		{raw: "services", expectError: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseScalableGVR(tt.raw)
+		if (err != nil) != tt.expectError {
+			t.Errorf("parseScalableGVR(%q) error = %v, expectError %v", tt.raw, err, tt.expectError)
+		}
+		if got != tt.expected {
+			t.Errorf("parseScalableGVR(%q) = %v, want %v", tt.raw, got, tt.expected)
+		}
+	}
+}
+
+func TestLoadSuspensionConfig_DropsInvalidScalableResources(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: v12.ObjectMeta{Name: SuspensionConfigMapName, Namespace: "sealos-system"},
+		Data: map[string]string{SuspensionConfigMapKey: `
+scalable_resources:
+  - gvr: serving.knative.dev/v1/services
+    suspend_patch: '{"spec":{"replicas":0}}'
+  - gvr: services
+    suspend_patch: '{"spec":{"replicas":0}}'
+  - gvr: apps.example.com/v1/scalers
+    suspend_patch: '{"spec":'
+  - gvr: apps.example.com/v1/scalers
+    suspend_patch: '{"spec":{"replicas":0}}'
+    resume_patch: 'replicas: 1'
+`},
+	}
+	r := &NamespaceReconciler{
+		Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(configMap).Build(),
+		Log:    logr.Discard(),
+	}
+
+	config := r.loadSuspensionConfig()
+	if len(config.ScalableResources) != 1 || config.ScalableResources[0].GVR != "serving.knative.dev/v1/services" {
+		t.Fatalf("ScalableResources = %+v, want only the valid knative entry", config.ScalableResources)
+	}
+}
+
+func TestScalableStrategy_SharesWorkloadWithWorkloadStrategy(t *testing.T) {
+	const namespace = "ns-test"
+	deployGVR := workloadGVRs[0]
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{deployGVR: "DeploymentList", workloadGVRs[1]: "StatefulSetList"},
+		newTestWorkload("Deployment", "web", namespace, ptr.To[int64](3)),
+	)
+	workload := &WorkloadStrategy{dynamicClient: dynamicClient, cache: NewResourceCache(DefaultCacheTTL)}
+	scalable := &ScalableStrategy{
+		dynamicClient: dynamicClient,
+		cache:         NewResourceCache(DefaultCacheTTL),
+		resources: []ScalableResourceConfig{{
+			GVR:          "apps/v1/deployments",
+			SuspendPatch: `{"spec":{"paused":true}}`,
+		}},
+	}
+	ctx := context.Background()
+
+	// 两个策略先后暂停同一个 Deployment，互不跳过
+	if err := workload.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("workload Suspend() error = %v", err)
+	}
+	if err := scalable.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("scalable Suspend() error = %v", err)
+	}
+	got, err := dynamicClient.Resource(deployGVR).Namespace(namespace).Get(ctx, "web", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	if paused, _, _ := unstructured.NestedBool(got.Object, "spec", "paused"); !paused {
+		t.Errorf("spec.paused = false, want the scalable suspend patch applied")
+	}
+
+	// 先恢复可伸缩策略，不能清除 WorkloadStrategy 的暂停标记
+	if err := scalable.Resume(ctx, namespace); err != nil {
+		t.Fatalf("scalable Resume() error = %v", err)
+	}
+	if err := workload.Resume(ctx, namespace); err != nil {
+		t.Fatalf("workload Resume() error = %v", err)
+	}
+	got, err = dynamicClient.Resource(deployGVR).Namespace(namespace).Get(ctx, "web", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(got.Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("replicas after resume = %d, want 3", replicas)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(got.Object, "spec", "paused"); found {
+		t.Errorf("spec.paused should be removed after resume: %v", got.Object["spec"])
+	}
+	if len(got.GetAnnotations()) != 0 {
+		t.Errorf("annotations after resume = %v, want none", got.GetAnnotations())
+	}
+}
+
+func newTestSuspendNamespace(name, suspendAt string) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: v12.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{v1.DebtNamespaceAnnoStatusKey: v1.SuspendDebtNamespaceAnnoStatus},
+		},
+	}
+	if suspendAt != "" {
+		ns.Annotations[DebtSuspendAtAnnotation] = suspendAt
+	}
+	return ns
+}
+
+func TestSuspendDelay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		suspendAt string
+		want      time.Duration
+		wantErr   bool
+	}{
+		{name: "not set", want: 0},
+		{name: "future", suspendAt: "2025-01-02T00:00:00Z", want: 12 * time.Hour},
+		{name: "past", suspendAt: "2025-01-01T00:00:00Z", want: 0},
+		{name: "now", suspendAt: "2025-01-01T12:00:00Z", want: 0},
+		{name: "offset", suspendAt: "2025-01-01T13:00:00+01:00", want: 0},
+		{name: "invalid", suspendAt: "tomorrow", want: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := newTestSuspendNamespace("ns-test", tt.suspendAt)
+			got, err := suspendDelay(ns.Annotations, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("suspendDelay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("suspendDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile_SuspendAt(t *testing.T) {
+	tests := []struct {
+		name        string
+		suspendAt   string
+		wantRequeue bool
+		wantStatus  string
+	}{
+		{
+			name:        "future time requeues without suspending",
+			suspendAt:   time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			wantRequeue: true,
+			wantStatus:  v1.SuspendDebtNamespaceAnnoStatus,
+		},
+		{
+			name:       "past time suspends now",
+			suspendAt:  time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			wantStatus: v1.SuspendCompletedDebtNamespaceAnnoStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := newTestSuspendNamespace("ns-test", tt.suspendAt)
+			r := &NamespaceReconciler{
+				Client:        fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns).Build(),
+				Log:           logr.Discard(),
+				resourceCache: NewResourceCache(DefaultCacheTTL),
+			}
+			// 标记资源已暂停，跳过实际的暂停策略，只验证调度逻辑
+			r.resourceCache.SetSuspended(ns.Name, "all", true)
+
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ns)})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if requeue := result.RequeueAfter > 0; requeue != tt.wantRequeue {
+				t.Errorf("RequeueAfter = %v, wantRequeue %v", result.RequeueAfter, tt.wantRequeue)
+			}
+			if tt.wantRequeue && result.RequeueAfter > time.Hour {
+				t.Errorf("RequeueAfter = %v, should not exceed the scheduled time", result.RequeueAfter)
+			}
+
+			got := &corev1.Namespace{}
+			if err := r.Client.Get(context.Background(), client.ObjectKeyFromObject(ns), got); err != nil {
+				t.Fatalf("get namespace: %v", err)
+			}
+			if status := got.Annotations[v1.DebtNamespaceAnnoStatusKey]; status != tt.wantStatus {
+				t.Errorf("debt status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReconcile_SuspendAtFakeClock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(start)
+	ns := newTestSuspendNamespace("ns-test", start.Add(time.Hour).Format(time.RFC3339))
+	r := &NamespaceReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns).Build(),
+		Log:           logr.Discard(),
+		resourceCache: NewResourceCache(DefaultCacheTTL),
+		Clock:         fakeClock,
+	}
+	r.resourceCache.SetSuspended(ns.Name, "all", true)
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ns)}
+
+	// 宽限期内按假时钟计算精确的剩余时间
+	result, err := r.Reconcile(ctx, req)
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if result.RequeueAfter != time.Hour {
+		t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, time.Hour)
+	}
+
+	// 宽限期结束后暂停
+	fakeClock.SetTime(start.Add(time.Hour + time.Second))
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	got := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(ns), got); err != nil {
+		t.Fatalf("get namespace: %v", err)
+	}
+	if status := got.Annotations[v1.DebtNamespaceAnnoStatusKey]; status != v1.SuspendCompletedDebtNamespaceAnnoStatus {
+		t.Errorf("debt status = %s, want %s", status, v1.SuspendCompletedDebtNamespaceAnnoStatus)
+	}
+}
+
+func TestInitializeStrategies_UsesReconcilerClock(t *testing.T) {
+	const namespace = "ns-test"
+	now := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	deployGVR, stsGVR := workloadGVRs[0], workloadGVRs[1]
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{deployGVR: "DeploymentList", stsGVR: "StatefulSetList"},
+		newTestWorkload("Deployment", "web", namespace, ptr.To[int64](3)))
+	r := &NamespaceReconciler{
+		dynamicClient:    dynamicClient,
+		Log:              logr.Discard(),
+		suspensionConfig: &SuspensionConfig{},
+		Clock:            clocktesting.NewFakePassiveClock(now),
+	}
+	r.initializeStrategies()
+
+	var workload *WorkloadStrategy
+	for _, strategy := range r.strategies {
+		if s, ok := strategy.(*WorkloadStrategy); ok {
+			workload = s
+		}
+	}
+	if workload == nil {
+		t.Fatal("initializeStrategies() did not register WorkloadStrategy")
+	}
+	ctx := context.Background()
+	if err := workload.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+
+	// 暂停时间注解使用注入的时钟
+	got, err := dynamicClient.Resource(deployGVR).Namespace(namespace).Get(ctx, "web", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	if at := got.GetAnnotations()[DebtSuspendedAtAnnotation]; at != now.Format(time.RFC3339) {
+		t.Errorf("%s = %q, want %q", DebtSuspendedAtAnnotation, at, now.Format(time.RFC3339))
+	}
+}
+
+func TestRegisterSuspensionMetrics_MultipleReconcilers(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	// 同一进程内的两个控制器注册到同一个 registry 不应 panic 或报错
+	reconcilers := []*NamespaceReconciler{{Log: logr.Discard()}, {Log: logr.Discard()}}
+	for i := range reconcilers {
+		if err := RegisterSuspensionMetrics(registry); err != nil {
+			t.Fatalf("RegisterSuspensionMetrics() for reconciler %d error = %v", i, err)
+		}
+	}
+	// 独立的 registry（如另一个 manager）也可以注册
+	if err := RegisterSuspensionMetrics(prometheus.NewRegistry()); err != nil {
+		t.Fatalf("RegisterSuspensionMetrics() on a second registry error = %v", err)
+	}
+
+	operationTotal.WithLabelValues("suspend", "success", StrategyNetwork).Inc()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	found := false
+	for _, family := range families {
+		if family.GetName() == "debt_operations_total" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("debt_operations_total should be exposed by the registry")
+	}
+}
+
+func newTestPVC(name, namespace, storageClass string, volumeAttributesClass *string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: v12.ObjectMeta{Name: name, Namespace: namespace, Annotations: map[string]string{"owner": "team-a"}},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName:          &storageClass,
+			VolumeAttributesClassName: volumeAttributesClass,
+		},
+	}
+}
+
+func TestPVCStrategy_SuspendResume(t *testing.T) {
+	namespace := "ns-test"
+	fast := "fast"
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		newTestPVC("data", namespace, "csi-ssd", nil),
+		newTestPVC("tuned", namespace, "csi-ssd", &fast),
+	).Build()
+	strategy := &PVCStrategy{
+		client:          c,
+		cache:           NewResourceCache(DefaultCacheTTL),
+		readOnlyClasses: map[string]string{"csi-ssd": "csi-ssd-read-only"},
+	}
+	ctx := context.Background()
+
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	// 重复暂停不应覆盖原始备份
+	strategy.cache.ClearNamespace(namespace)
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("second Suspend() error = %v", err)
+	}
+
+	for _, name := range []string{"data", "tuned"} {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, pvc); err != nil {
+			t.Fatalf("failed to get pvc %s: %v", name, err)
+		}
+		if pvc.Annotations[DebtSuspendedAnnotation] != "true" {
+			t.Errorf("pvc %s annotations = %v, want suspended annotation", name, pvc.Annotations)
+		}
+		if got := ptr.Deref(pvc.Spec.VolumeAttributesClassName, ""); got != "csi-ssd-read-only" {
+			t.Errorf("pvc %s volumeAttributesClassName = %q, want csi-ssd-read-only", name, got)
+		}
+	}
+
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	wantClasses := map[string]*string{"data": nil, "tuned": &fast}
+	for name, want := range wantClasses {
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, pvc); err != nil {
+			t.Fatalf("failed to get pvc %s: %v", name, err)
+		}
+		if !reflect.DeepEqual(pvc.Spec.VolumeAttributesClassName, want) {
+			t.Errorf("pvc %s volumeAttributesClassName = %v, want %v", name, pvc.Spec.VolumeAttributesClassName, want)
+		}
+		for k := range pvc.Annotations {
+			if strings.HasPrefix(k, DebtAnnotationPrefix) {
+				t.Errorf("pvc %s debt annotation %s should be removed after resume", name, k)
+			}
+		}
+		if pvc.Annotations["owner"] != "team-a" {
+			t.Errorf("pvc %s annotations = %v, want user annotations preserved", name, pvc.Annotations)
+		}
+	}
+}
+
+func TestPVCStrategy_UnsupportedStorageClass(t *testing.T) {
+	namespace := "ns-test"
+	original := newTestPVC("data", namespace, "local-path", nil)
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(original.DeepCopy()).Build()
+	strategy := &PVCStrategy{
+		client:          c,
+		cache:           NewResourceCache(DefaultCacheTTL),
+		readOnlyClasses: map[string]string{"csi-ssd": "csi-ssd-read-only"},
+	}
+	ctx := context.Background()
+
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(original), pvc); err != nil {
+		t.Fatalf("failed to get pvc: %v", err)
+	}
+	if pvc.Spec.VolumeAttributesClassName != nil {
+		t.Errorf("volumeAttributesClassName = %q, want unchanged for unsupported storage class", *pvc.Spec.VolumeAttributesClassName)
+	}
+	if len(pvc.Annotations) != 1 || pvc.Annotations["owner"] != "team-a" {
+		t.Errorf("annotations = %v, want unchanged for unsupported storage class", pvc.Annotations)
+	}
+}
+
+func TestDeleteUserResource_ConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *SuspensionConfig
+		wantLimit int
+	}{
+		{name: "configured limit", config: &SuspensionConfig{DeleteConcurrency: 2}, wantLimit: 2},
+		{name: "serial deletion", config: &SuspensionConfig{DeleteConcurrency: 1}, wantLimit: 1},
+		{name: "default limit", config: &SuspensionConfig{}, wantLimit: DefaultDeleteConcurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dynamicClient := newTestDynamicClient()
+			var inFlight, maxInFlight, calls int32
+			dynamicClient.PrependReactor("delete-collection", "*", func(k8stesting.Action) (bool, runtime.Object, error) {
+				current := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				atomic.AddInt32(&calls, 1)
+				for {
+					seen := atomic.LoadInt32(&maxInFlight)
+					if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				return true, nil, nil
+			})
+			r := &NamespaceReconciler{
+				dynamicClient:    dynamicClient,
+				Log:              logr.Discard(),
+				suspensionConfig: tt.config,
+			}
+
+			if err := r.DeleteUserResource(context.Background(), "ns-test"); err != nil {
+				t.Fatalf("DeleteUserResource() error = %v", err)
+			}
+			if calls == 0 {
+				t.Fatal("expected delete collection calls")
+			}
+			if maxInFlight > int32(tt.wantLimit) {
+				t.Errorf("max concurrent deletes = %d, want <= %d", maxInFlight, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestBlackoutDelay(t *testing.T) {
+	// 2025-01-01 是星期三
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		windows []BlackoutWindow
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "no windows", want: 0},
+		{name: "inside window", windows: []BlackoutWindow{{Start: "09:00", End: "18:00"}}, want: 8 * time.Hour},
+		{name: "outside window", windows: []BlackoutWindow{{Start: "12:00", End: "18:00"}}, want: 0},
+		{name: "window end is exclusive", windows: []BlackoutWindow{{Start: "08:00", End: "10:00"}}, want: 0},
+		{name: "across midnight", windows: []BlackoutWindow{{Start: "22:00", End: "11:00"}}, want: time.Hour},
+		{name: "weekday matches", windows: []BlackoutWindow{{Start: "09:00", End: "18:00", Weekdays: []string{"Wed"}}}, want: 8 * time.Hour},
+		{name: "weekday not matched", windows: []BlackoutWindow{{Start: "09:00", End: "18:00", Weekdays: []string{"Sat", "Sun"}}}, want: 0},
+		{name: "timezone", windows: []BlackoutWindow{{Start: "17:00", End: "19:00", Timezone: "Asia/Shanghai"}}, want: time.Hour},
+		{
+			name:    "overlapping windows use latest end",
+			windows: []BlackoutWindow{{Start: "09:00", End: "11:00"}, {Start: "09:30", End: "12:00"}},
+			want:    2 * time.Hour,
+		},
+		{
+			name:    "invalid window is skipped",
+			windows: []BlackoutWindow{{Start: "9am", End: "18:00"}, {Start: "09:00", End: "11:00"}},
+			want:    time.Hour,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := blackoutDelay(tt.windows, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("blackoutDelay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("blackoutDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcile_BlackoutWindow(t *testing.T) {
+	now := time.Now().UTC()
+	inside := []BlackoutWindow{{Start: now.Add(-time.Hour).Format("15:04"), End: now.Add(time.Hour).Format("15:04")}}
+	outside := []BlackoutWindow{{Start: now.Add(time.Hour).Format("15:04"), End: now.Add(2 * time.Hour).Format("15:04")}}
+	tests := []struct {
+		name        string
+		status      string
+		windows     []BlackoutWindow
+		wantRequeue bool
+		wantStatus  string
+	}{
+		{
+			name:        "suspend inside blackout is deferred",
+			status:      v1.SuspendDebtNamespaceAnnoStatus,
+			windows:     inside,
+			wantRequeue: true,
+			wantStatus:  v1.SuspendDebtNamespaceAnnoStatus,
+		},
+		{
+			name:       "suspend outside blackout proceeds",
+			status:     v1.SuspendDebtNamespaceAnnoStatus,
+			windows:    outside,
+			wantStatus: v1.SuspendCompletedDebtNamespaceAnnoStatus,
+		},
+		{
+			name:       "resume inside blackout proceeds",
+			status:     v1.ResumeDebtNamespaceAnnoStatus,
+			windows:    inside,
+			wantStatus: v1.ResumeCompletedDebtNamespaceAnnoStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns := newTestSuspendNamespace("ns-test", "")
+			ns.Annotations[v1.DebtNamespaceAnnoStatusKey] = tt.status
+			r := &NamespaceReconciler{
+				Client:           fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns).Build(),
+				Log:              logr.Discard(),
+				resourceCache:    NewResourceCache(DefaultCacheTTL),
+				suspensionConfig: &SuspensionConfig{BlackoutWindows: tt.windows},
+			}
+			// 通过缓存跳过实际的暂停/恢复策略，只验证窗口调度逻辑
+			r.resourceCache.SetSuspended(ns.Name, "all", tt.status == v1.SuspendDebtNamespaceAnnoStatus)
+
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(ns)})
+			if err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+			if requeue := result.RequeueAfter > 0; requeue != tt.wantRequeue {
+				t.Errorf("RequeueAfter = %v, wantRequeue %v", result.RequeueAfter, tt.wantRequeue)
+			}
+			if tt.wantRequeue && result.RequeueAfter > time.Hour {
+				t.Errorf("RequeueAfter = %v, should not exceed the window end", result.RequeueAfter)
+			}
+
+			got := &corev1.Namespace{}
+			if err := r.Client.Get(context.Background(), client.ObjectKeyFromObject(ns), got); err != nil {
+				t.Fatalf("get namespace: %v", err)
+			}
+			if status := got.Annotations[v1.DebtNamespaceAnnoStatusKey]; status != tt.wantStatus {
+				t.Errorf("debt status = %s, want %s", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSweepOrphanedRestrictedRoles(t *testing.T) {
+	newNamespace := func(name, status string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: v12.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{v1.DebtNamespaceAnnoStatusKey: status},
+		}}
+	}
+	newRole := func(name, namespace, label string) *rbacv1.Role {
+		return &rbacv1.Role{ObjectMeta: v12.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{label: "true"},
+		}}
+	}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		newNamespace("ns-resumed", v1.ResumeCompletedDebtNamespaceAnnoStatus),
+		newNamespace("ns-normal", v1.NormalDebtNamespaceAnnoStatus),
+		newNamespace("ns-suspended", v1.SuspendCompletedDebtNamespaceAnnoStatus),
+		newNamespace("ns-resuming", v1.ResumeDebtNamespaceAnnoStatus),
+		newRole("debt-restricted-role", "ns-resumed", "debt.sealos.io/restricted"),
+		newRole("debt-restricted-edit", "ns-normal", "sealos.io/debt-restricted"),
+		newRole("debt-restricted-role", "ns-suspended", "debt.sealos.io/restricted"),
+		newRole("debt-restricted-edit", "ns-suspended", "sealos.io/debt-restricted"),
+		newRole("debt-restricted-role", "ns-resuming", "debt.sealos.io/restricted"),
+		// 非受限角色不受影响
+		&rbacv1.Role{ObjectMeta: v12.ObjectMeta{Name: "edit", Namespace: "ns-resumed"}},
+	).Build()
+	r := &NamespaceReconciler{Client: c, Log: logr.Discard()}
+
+	if err := r.sweepOrphanedRestrictedRoles(context.Background()); err != nil {
+		t.Fatalf("sweepOrphanedRestrictedRoles() error = %v", err)
+	}
+
+	tests := []struct {
+		namespace string
+		name      string
+		wantExist bool
+	}{
+		{namespace: "ns-resumed", name: "debt-restricted-role", wantExist: false},
+		{namespace: "ns-normal", name: "debt-restricted-edit", wantExist: false},
+		{namespace: "ns-suspended", name: "debt-restricted-role", wantExist: true},
+		{namespace: "ns-suspended", name: "debt-restricted-edit", wantExist: true},
+		{namespace: "ns-resuming", name: "debt-restricted-role", wantExist: true},
+		{namespace: "ns-resumed", name: "edit", wantExist: true},
+	}
+	for _, tt := range tests {
+		err := c.Get(context.Background(), client.ObjectKey{Namespace: tt.namespace, Name: tt.name}, &rbacv1.Role{})
+		if exists := err == nil; exists != tt.wantExist {
+			t.Errorf("role %s/%s exists = %v, want %v (err: %v)", tt.namespace, tt.name, exists, tt.wantExist, err)
+		}
+	}
+}
+
+func TestNetworkStrategy_BackupChecksum(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(annotations map[string]string)
+		wantErr bool
+	}{
+		{name: "matching checksum restores", tamper: func(map[string]string) {}},
+		{
+			name: "backup without checksum restores",
+			tamper: func(annotations map[string]string) {
+				delete(annotations, DebtBackupChecksumAnnotation)
+			},
+		},
+		{
+			name: "tampered backup is refused",
+			tamper: func(annotations map[string]string) {
+				annotations["debt.sealos.io/backup-data"] = strings.Replace(annotations["debt.sealos.io/backup-data"], "7777", "8888", 1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "truncated backup is refused",
+			tamper: func(annotations map[string]string) {
+				data := annotations["debt.sealos.io/backup-data"]
+				annotations["debt.sealos.io/backup-data"] = data[:len(data)/2]
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := "ns-test"
+			svc := &unstructured.Unstructured{}
+			svc.SetAPIVersion("v1")
+			svc.SetKind("Service")
+			svc.SetName("game")
+			svc.SetNamespace(namespace)
+			_ = unstructured.SetNestedSlice(svc.Object, []interface{}{
+				map[string]interface{}{"name": "game", "port": int64(7777), "protocol": "TCP"},
+			}, "spec", "ports")
+
+			client := newTestDynamicClient(svc)
+			strategy := &NetworkStrategy{dynamicClient: client, cache: NewResourceCache(DefaultCacheTTL)}
+			ctx := context.Background()
+			if err := strategy.suspendResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+				t.Fatalf("suspendResourcesByGVR() error = %v", err)
+			}
+
+			resourceClient := client.Resource(testServiceGVR).Namespace(namespace)
+			suspended, err := resourceClient.Get(ctx, "game", v12.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get service: %v", err)
+			}
+			annotations := suspended.GetAnnotations()
+			if annotations[DebtBackupChecksumAnnotation] == "" {
+				t.Fatalf("annotations = %v, want backup checksum", annotations)
+			}
+			tt.tamper(annotations)
+			suspended.SetAnnotations(annotations)
+			if _, err := resourceClient.Update(ctx, suspended, v12.UpdateOptions{}); err != nil {
+				t.Fatalf("failed to update service: %v", err)
+			}
+
+			err = strategy.resumeResourcesByGVR(ctx, namespace, testServiceGVR)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resumeResourcesByGVR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got, err := resourceClient.Get(ctx, "game", v12.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get service: %v", err)
+			}
+			ports, _, _ := unstructured.NestedSlice(got.Object, "spec", "ports")
+			if tt.wantErr {
+				if len(ports) != 0 || got.GetAnnotations()[DebtSuspendedAnnotation] != "true" {
+					t.Errorf("service should stay suspended after refused restore, ports = %v, annotations = %v", ports, got.GetAnnotations())
+				}
+				return
+			}
+			if len(ports) != 1 {
+				t.Errorf("restored ports = %v, want 1 port", ports)
+			}
+		})
+	}
+}
+
+func TestRestoreResourceConfig_Checksum(t *testing.T) {
+	config := []interface{}{map[string]interface{}{"host": "app.example.com"}}
+	tests := []struct {
+		name    string
+		tamper  string
+		wantErr bool
+	}{
+		{name: "matching checksum"},
+		{name: "tampered backup", tamper: `[{"host":"evil.example.com"}]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &NamespaceReconciler{Log: logr.Discard()}
+			resource := &unstructured.Unstructured{}
+			resource.SetName("app")
+			resource.SetNamespace("ns-test")
+			const key = "sealos.io/debt-original-hosts"
+			if err := r.backupResourceConfig(context.Background(), resource, key, config, r.Log); err != nil {
+				t.Fatalf("backupResourceConfig() error = %v", err)
+			}
+			if tt.tamper != "" {
+				annotations := resource.GetAnnotations()
+				annotations[key] = tt.tamper
+				resource.SetAnnotations(annotations)
+			}
+
+			got, err := r.restoreResourceConfig(context.Background(), resource, key, r.Log)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("restoreResourceConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, config) {
+				t.Errorf("restoreResourceConfig() = %v, want %v", got, config)
+			}
+		})
+	}
+}
+
+func TestNetworkStrategy_ResumeOrder(t *testing.T) {
+	namespace := "ns-test"
+	newObject := func(apiVersion, kind, name string, spec map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace(namespace)
+		return obj
+	}
+	ingressGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	gatewayGVR := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}
+	vsGVR := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testServiceGVR: "ServiceList",
+			ingressGVR:     "IngressList",
+			gatewayGVR:     "GatewayList",
+			vsGVR:          "VirtualServiceList",
+		})
+	ctx := context.Background()
+	objects := map[schema.GroupVersionResource]*unstructured.Unstructured{
+		testServiceGVR: newObject("v1", "Service", "app", map[string]interface{}{
+			"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+		}),
+		ingressGVR: newObject("networking.k8s.io/v1", "Ingress", "app", map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"host": "app.example.com"}},
+		}),
+		gatewayGVR: newObject("networking.istio.io/v1beta1", "Gateway", "app-gateway", map[string]interface{}{
+			"servers": []interface{}{map[string]interface{}{"hosts": []interface{}{"app.example.com"}}},
+		}),
+		vsGVR: newObject("networking.istio.io/v1beta1", "VirtualService", "app-vs", map[string]interface{}{
+			"hosts": []interface{}{"app.example.com"},
+		}),
+	}
+	for gvr, obj := range objects {
+		if _, err := client.Resource(gvr).Namespace(namespace).Create(ctx, obj, v12.CreateOptions{}); err != nil {
+			t.Fatalf("create %s: %v", gvr.Resource, err)
+		}
+	}
+	strategy := &NetworkStrategy{dynamicClient: client, cache: NewResourceCache(DefaultCacheTTL)}
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []string
+	client.PrependReactor("update", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, action.GetResource().Resource)
+		return false, nil, nil
+	})
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	position := make(map[string]int)
+	for i, resource := range order {
+		position[resource] = i
+	}
+	if len(position) != 4 {
+		t.Fatalf("restored resources = %v, want services, gateways, ingresses and virtualservices", order)
+	}
+	for _, frontEnd := range []string{"gateways", "ingresses", "virtualservices"} {
+		if position["services"] > position[frontEnd] {
+			t.Errorf("services restored after %s, order = %v", frontEnd, order)
+		}
+	}
+	if position["gateways"] > position["virtualservices"] {
+		t.Errorf("gateways restored after virtualservices, order = %v", order)
+	}
+}
+
+func TestNetworkStrategy_ExemptBudget(t *testing.T) {
+	namespace := "ns-test"
+	ingressGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	created := time.Now().Add(-time.Hour)
+	var objects []runtime.Object
+	// landing 最早创建，应被保留；billing 与 app 同时创建，按名称排序
+	for _, item := range []struct {
+		name    string
+		created time.Time
+	}{{"landing", created}, {"billing", created.Add(time.Minute)}, {"app", created.Add(time.Minute)}} {
+		name := item.name
+		ingress := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"host": name + ".example.com"}},
+		}}}
+		ingress.SetAPIVersion("networking.k8s.io/v1")
+		ingress.SetKind("Ingress")
+		ingress.SetName(name)
+		ingress.SetNamespace(namespace)
+		ingress.SetCreationTimestamp(v12.NewTime(item.created))
+		objects = append(objects, ingress)
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ingressGVR: "IngressList"}, objects...)
+	strategy := &NetworkStrategy{
+		dynamicClient: client,
+		cache:         NewResourceCache(DefaultCacheTTL),
+		exemptBudget:  map[string]int{"ingresses": 1},
+	}
+	ctx := context.Background()
+	// 重复暂停选出的免暂停资源不变
+	for i := 0; i < 2; i++ {
+		if err := strategy.suspendResourcesByGVR(ctx, namespace, ingressGVR); err != nil {
+			t.Fatalf("suspendResourcesByGVR() error = %v", err)
+		}
+	}
+
+	active := 0
+	for _, name := range []string{"landing", "billing", "app"} {
+		ingress, err := client.Resource(ingressGVR).Namespace(namespace).Get(ctx, name, v12.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get ingress %s: %v", name, err)
+		}
+		suspended := ingress.GetAnnotations()[DebtSuspendedAnnotation] == "true"
+		if !suspended {
+			active++
+		}
+		if wantSuspended := name != "landing"; suspended != wantSuspended {
+			t.Errorf("ingress %s suspended = %v, want %v", name, suspended, wantSuspended)
+		}
+	}
+	if active != 1 {
+		t.Errorf("active ingresses = %d, want 1", active)
+	}
+}
+
+func TestCertManagerStrategy_KeepCertificatesActive(t *testing.T) {
+	challengeGVR := schema.GroupVersionResource{Group: "acme.cert-manager.io", Version: "v1", Resource: "challenges"}
+	tests := []struct {
+		name          string
+		keepActive    bool
+		wantSuspended bool
+	}{
+		{name: "flag on leaves certificates untouched", keepActive: true},
+		{name: "flag off suspends certificates", wantSuspended: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namespace := "ns-test"
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					testCertificateGVR: "CertificateList",
+					challengeGVR:       "ChallengeList",
+				}, newTestCertificate("cert", namespace))
+			challengesDeleted := false
+			client.PrependReactor("delete-collection", "challenges", func(k8stesting.Action) (bool, runtime.Object, error) {
+				challengesDeleted = true
+				return true, nil, nil
+			})
+			strategy := &CertManagerStrategy{
+				dynamicClient:          client,
+				cache:                  NewResourceCache(DefaultCacheTTL),
+				keepCertificatesActive: tt.keepActive,
+			}
+
+			ctx := context.Background()
+			if err := strategy.Suspend(ctx, namespace); err != nil {
+				t.Fatalf("Suspend() error = %v", err)
+			}
+
+			got, err := client.Resource(testCertificateGVR).Namespace(namespace).Get(ctx, "cert", v12.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get certificate: %v", err)
+			}
+			if suspended := got.GetAnnotations()[DebtSuspendedAnnotation] == "true"; suspended != tt.wantSuspended {
+				t.Errorf("certificate suspended = %v, want %v (annotations: %v)", suspended, tt.wantSuspended, got.GetAnnotations())
+			}
+			if challengesDeleted != tt.wantSuspended {
+				t.Errorf("challenges deleted = %v, want %v", challengesDeleted, tt.wantSuspended)
+			}
+		})
+	}
+}
+
+func TestListSuspendedResources(t *testing.T) {
+	namespace := "ns-test"
+	pvcGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"}
+	ingressGVR := schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	gatewayGVR := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}
+	vsGVR := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testCertificateGVR: "CertificateList",
+			testServiceGVR:     "ServiceList",
+			pvcGVR:             "PersistentVolumeClaimList",
+			ingressGVR:         "IngressList",
+			gatewayGVR:         "GatewayList",
+			vsGVR:              "VirtualServiceList",
+			workloadGVRs[0]:    "DeploymentList",
+			workloadGVRs[1]:    "StatefulSetList",
+		})
+
+	serviceSuspendedAt := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	vsSuspendedAt := time.Date(2025, 1, 2, 9, 30, 0, 0, time.UTC)
+	newObject := func(apiVersion, kind, name string, suspendedAt *time.Time) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		obj.SetNamespace(namespace)
+		if suspendedAt != nil {
+			obj.SetAnnotations(map[string]string{
+				DebtSuspendedAnnotation:   "true",
+				DebtSuspendedAtAnnotation: suspendedAt.Format(time.RFC3339),
+			})
+		}
+		return obj
+	}
+	objects := []struct {
+		gvr schema.GroupVersionResource
+		obj *unstructured.Unstructured
+	}{
+		{testServiceGVR, newObject("v1", "Service", "app", &serviceSuspendedAt)},
+		{testServiceGVR, newObject("v1", "Service", "active", nil)},
+		{ingressGVR, newObject("networking.k8s.io/v1", "Ingress", "app", nil)},
+		{vsGVR, newObject("networking.istio.io/v1beta1", "VirtualService", "app-vs", &vsSuspendedAt)},
+		{testCertificateGVR, newObject("cert-manager.io/v1", "Certificate", "active-cert", nil)},
+	}
+	ctx := context.Background()
+	for _, o := range objects {
+		if _, err := client.Resource(o.gvr).Namespace(namespace).Create(ctx, o.obj, v12.CreateOptions{}); err != nil {
+			t.Fatalf("create %s/%s: %v", o.gvr.Resource, o.obj.GetName(), err)
+		}
+	}
+	// 暂停时间格式错误的资源仍会被列出
+	cert := newObject("cert-manager.io/v1", "Certificate", "cert", nil)
+	cert.SetAnnotations(map[string]string{DebtSuspendedAnnotation: "true", DebtSuspendedAtAnnotation: "yesterday"})
+	if _, err := client.Resource(testCertificateGVR).Namespace(namespace).Create(ctx, cert, v12.CreateOptions{}); err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	r := &NamespaceReconciler{dynamicClient: client, Log: logr.Discard(), suspensionConfig: &SuspensionConfig{}}
+	got, err := r.ListSuspendedResources(ctx, namespace)
+	if err != nil {
+		t.Fatalf("ListSuspendedResources() error = %v", err)
+	}
+	want := []SuspendedResource{
+		{Kind: "Certificate", Name: "cert"},
+		{Kind: "Service", Name: "app", SuspendedAt: serviceSuspendedAt},
+		{Kind: "VirtualService", Name: "app-vs", SuspendedAt: vsSuspendedAt},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ListSuspendedResources() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].Name != want[i].Name || !got[i].SuspendedAt.Equal(want[i].SuspendedAt) {
+			t.Errorf("resource[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func newTestAppDeployment(app, namespace, strategies string) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      app,
+			Namespace: namespace,
+			Labels:    map[string]string{resources.AppDeployLabelKey: app},
+		},
+	}
+	if strategies != "" {
+		deploy.Annotations = map[string]string{DebtStrategiesAnnotation: strategies}
+	}
+	return deploy
+}
+
+func newTestAppPod(app, namespace string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: v12.ObjectMeta{
+			Name:            app + "-pod",
+			Namespace:       namespace,
+			Labels:          map[string]string{resources.AppDeployLabelKey: app},
+			OwnerReferences: []v12.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: app + "-rs", UID: "uid-" + types.UID(app)}},
+		},
+	}
+}
+
+func newTestAppService(app, namespace string) *unstructured.Unstructured {
+	svc := &unstructured.Unstructured{}
+	svc.SetAPIVersion("v1")
+	svc.SetKind("Service")
+	svc.SetName(app)
+	svc.SetNamespace(namespace)
+	svc.SetLabels(map[string]string{resources.AppDeployLabelKey: app})
+	_ = unstructured.SetNestedField(svc.Object, "ClusterIP", "spec", "type")
+	_ = unstructured.SetNestedSlice(svc.Object, []interface{}{
+		map[string]interface{}{"name": "http", "port": int64(80), "protocol": "TCP"},
+	}, "spec", "ports")
+	return svc
+}
+
+func TestAppStrategyOverride(t *testing.T) {
+	namespace := "ns-test"
+	// dashboard 只暂停网络，worker 只停止计算，web 使用命名空间级策略
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		newTestAppDeployment("dashboard", namespace, "network"),
+		newTestAppDeployment("worker", namespace, " compute "),
+		newTestAppDeployment("web", namespace, ""),
+		newTestAppPod("dashboard", namespace),
+		newTestAppPod("worker", namespace),
+		newTestAppPod("web", namespace),
+	).Build()
+	dynamicClient := newTestDynamicClient(
+		newTestAppService("dashboard", namespace),
+		newTestAppService("worker", namespace),
+		newTestAppService("web", namespace),
+	)
+	r := &NamespaceReconciler{Client: c, dynamicClient: dynamicClient, Log: logr.Discard()}
+	network := &NetworkStrategy{dynamicClient: dynamicClient, cache: NewResourceCache(DefaultCacheTTL)}
+
+	overrides, err := r.loadAppStrategyOverrides(context.Background(), namespace)
+	if err != nil {
+		t.Fatalf("loadAppStrategyOverrides() error = %v", err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("overrides = %v, want dashboard and worker", overrides)
+	}
+	ctx := withAppStrategyOverrides(context.Background(), overrides)
+
+	if err := r.deleteControlledPod(ctx, namespace); err != nil {
+		t.Fatalf("deleteControlledPod() error = %v", err)
+	}
+	if err := network.suspendResourcesByGVR(ctx, namespace, testServiceGVR); err != nil {
+		t.Fatalf("suspendResourcesByGVR() error = %v", err)
+	}
+
+	tests := []struct {
+		app           string
+		wantRunning   bool
+		wantSuspended bool
+	}{
+		{app: "dashboard", wantRunning: true, wantSuspended: true},
+		{app: "worker", wantRunning: false, wantSuspended: false},
+		{app: "web", wantRunning: false, wantSuspended: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.app, func(t *testing.T) {
+			err := c.Get(ctx, client.ObjectKey{Name: tt.app + "-pod", Namespace: namespace}, &corev1.Pod{})
+			if running := err == nil; running != tt.wantRunning {
+				t.Errorf("pod running = %v (err %v), want %v", running, err, tt.wantRunning)
+			}
+
+			svc, err := dynamicClient.Resource(testServiceGVR).Namespace(namespace).Get(ctx, tt.app, v12.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get service: %v", err)
+			}
+			if suspended := svc.GetAnnotations()[DebtSuspendedAnnotation] == "true"; suspended != tt.wantSuspended {
+				t.Errorf("service suspended = %v, want %v", suspended, tt.wantSuspended)
+			}
+		})
+	}
+}
+
+func TestAppStrategyOverrides_Applies(t *testing.T) {
+	overrides := AppStrategyOverrides{"dashboard": {StrategyNetwork: true}}
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		strategy string
+		want     bool
+	}{
+		{name: "listed strategy", labels: map[string]string{resources.AppDeployLabelKey: "dashboard"}, strategy: StrategyNetwork, want: true},
+		{name: "unlisted strategy", labels: map[string]string{resources.AppDeployLabelKey: "dashboard"}, strategy: StrategyCompute, want: false},
+		{name: "app without override", labels: map[string]string{resources.AppDeployLabelKey: "web"}, strategy: StrategyCompute, want: true},
+		{name: "resource without app label", labels: nil, strategy: StrategyPVC, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overrides.Applies(tt.labels, tt.strategy); got != tt.want {
+				t.Errorf("Applies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if !AppStrategyOverrides(nil).Applies(map[string]string{resources.AppDeployLabelKey: "dashboard"}, StrategyCompute) {
+		t.Error("nil overrides should apply every strategy")
+	}
+}
+
+type fakeOSUserAdmin struct {
+	users    map[string]madmin.UserInfo
+	statuses map[string]madmin.AccountStatus
+}
+
+func (f *fakeOSUserAdmin) ListUsers(_ context.Context) (map[string]madmin.UserInfo, error) {
+	return f.users, nil
+}
+
+func (f *fakeOSUserAdmin) SetUserStatus(_ context.Context, accessKey string, status madmin.AccountStatus) error {
+	f.statuses[accessKey] = status
+	return nil
+}
+
+func TestReconcile_MissingNamespace(t *testing.T) {
+	const namespace = "ns-alice"
+	quota := &corev1.ResourceQuota{ObjectMeta: v12.ObjectMeta{Name: DebtLimit0Name, Namespace: namespace}}
+	lock := &coordinationv1.Lease{ObjectMeta: v12.ObjectMeta{Name: "debt-suspend-" + namespace, Namespace: "sealos-system"}}
+
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		wantDisable bool
+	}{
+		{name: "suspended namespace force deleted", objects: []client.Object{quota.DeepCopy(), lock.DeepCopy()}, wantDisable: true},
+		{name: "normally deleted namespace", objects: []client.Object{lock.DeepCopy()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build()
+			admin := &fakeOSUserAdmin{
+				users:    map[string]madmin.UserInfo{"alice": {Status: madmin.AccountEnabled}},
+				statuses: map[string]madmin.AccountStatus{},
+			}
+			r := &NamespaceReconciler{
+				Client:           c,
+				Log:              logr.Discard(),
+				OSAdminClient:    admin,
+				OSNamespace:      "objectstorage-system",
+				OSAdminSecret:    "object-storage-sealos-user-0",
+				InternalEndpoint: "object-storage.objectstorage-system.svc.cluster.local",
+			}
+			ctx := context.Background()
+			if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace}}); err != nil {
+				t.Fatalf("Reconcile() error = %v", err)
+			}
+
+			status, disabled := admin.statuses["alice"]
+			if disabled != tt.wantDisable {
+				t.Fatalf("object storage user disabled = %v, want %v", disabled, tt.wantDisable)
+			}
+			if tt.wantDisable && status != madmin.AccountStatus(Disabled) {
+				t.Errorf("object storage user status = %q, want %q", status, Disabled)
+			}
+
+			err := c.Get(ctx, client.ObjectKeyFromObject(lock), &coordinationv1.Lease{})
+			if tt.wantDisable && !errors.IsNotFound(err) {
+				t.Errorf("lock lease should be deleted, get error = %v", err)
+			}
+			if !tt.wantDisable && err != nil {
+				t.Errorf("lock lease should be kept, get error = %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcile_ResumeNetworkOnly(t *testing.T) {
+	const namespace = "ns-test"
+	vsGVR := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testCertificateGVR: "CertificateList",
+			testServiceGVR:     "ServiceList",
+			testScalerGVR:      "ScalerList",
+			{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}:       "IngressList",
+			{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}: "GatewayList",
+			vsGVR: "VirtualServiceList",
+		})
+	ctx := context.Background()
+
+	service := newTestAppService("web", namespace)
+	vs := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"hosts": []interface{}{"web.example.com"}},
+	}}
+	vs.SetAPIVersion("networking.istio.io/v1beta1")
+	vs.SetKind("VirtualService")
+	vs.SetName("web-vs")
+	vs.SetNamespace(namespace)
+	replicas := int64(2)
+	for gvr, obj := range map[schema.GroupVersionResource]*unstructured.Unstructured{
+		testServiceGVR: service,
+		vsGVR:          vs,
+		testScalerGVR:  newTestScaler("db", namespace, &replicas),
+	} {
+		if _, err := dynamicClient.Resource(gvr).Namespace(namespace).Create(ctx, obj, v12.CreateOptions{}); err != nil {
+			t.Fatalf("create %s: %v", gvr.Resource, err)
+		}
+	}
+
+	// 先按完整暂停的方式暂停网络和计算资源
+	network := &NetworkStrategy{dynamicClient: dynamicClient, cache: NewResourceCache(DefaultCacheTTL)}
+	if err := network.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("network Suspend() error = %v", err)
+	}
+	scalable := &ScalableStrategy{
+		dynamicClient: dynamicClient,
+		cache:         NewResourceCache(DefaultCacheTTL),
+		resources: []ScalableResourceConfig{{
+			GVR:          "apps.example.com/v1/scalers",
+			SuspendPatch: `{"spec":{"replicas":0}}`,
+		}},
+	}
+	if err := scalable.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("scalable Suspend() error = %v", err)
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: v12.ObjectMeta{
+		Name:        namespace,
+		Annotations: map[string]string{v1.DebtNamespaceAnnoStatusKey: v1.ResumeNetworkOnlyDebtNamespaceAnnoStatus},
+	}}
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ns, GetLimit0ResourceQuota(namespace)).Build()
+	r := &NamespaceReconciler{
+		Client:        c,
+		dynamicClient: dynamicClient,
+		Log:           logr.Discard(),
+		suspensionConfig: &SuspensionConfig{
+			ScalableResources: scalable.resources,
+		},
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	// 路由恢复
+	for gvr, name := range map[schema.GroupVersionResource]string{testServiceGVR: "web", vsGVR: "web-vs"} {
+		got, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, v12.GetOptions{})
+		if err != nil {
+			t.Fatalf("get %s: %v", gvr.Resource, err)
+		}
+		if got.GetAnnotations()[DebtSuspendedAnnotation] == "true" {
+			t.Errorf("%s %s still suspended", gvr.Resource, name)
+		}
+	}
+	got, err := dynamicClient.Resource(vsGVR).Namespace(namespace).Get(ctx, "web-vs", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("get virtualservice: %v", err)
+	}
+	if hosts, _, _ := unstructured.NestedStringSlice(got.Object, "spec", "hosts"); !reflect.DeepEqual(hosts, []string{"web.example.com"}) {
+		t.Errorf("restored hosts = %v, want [web.example.com]", hosts)
+	}
+
+	// 计算资源保持暂停
+	scaler, err := dynamicClient.Resource(testScalerGVR).Namespace(namespace).Get(ctx, "db", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("get scaler: %v", err)
+	}
+	if r, _, _ := unstructured.NestedInt64(scaler.Object, "spec", "replicas"); r != 0 {
+		t.Errorf("scaler replicas = %d, want 0", r)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: DebtLimit0Name, Namespace: namespace}, &corev1.ResourceQuota{}); err != nil {
+		t.Errorf("limit-0 quota should be kept, get error = %v", err)
+	}
+
+	gotNS := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, gotNS); err != nil {
+		t.Fatalf("get namespace: %v", err)
+	}
+	if status := gotNS.Annotations[v1.DebtNamespaceAnnoStatusKey]; status != v1.ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus {
+		t.Errorf("debt status = %s, want %s", status, v1.ResumeNetworkOnlyCompletedDebtNamespaceAnnoStatus)
+	}
+}
+
+func newTestLockLease(name, holder string, renewed time.Time) *coordinationv1.Lease {
+	duration := int32(LockLeaseDuration / time.Second)
+	renewTime := v12.NewMicroTime(renewed)
+	return &coordinationv1.Lease{
+		ObjectMeta: v12.ObjectMeta{Name: name, Namespace: "sealos-system"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			AcquireTime:          &renewTime,
+			RenewTime:            &renewTime,
+		},
+	}
+}
+
+func TestSuspendWithLock(t *testing.T) {
+	const namespace = "ns-test"
+	lockName := "debt-suspend-" + namespace
+	tests := []struct {
+		name    string
+		objects []client.Object
+		wantRun bool
+	}{
+		{name: "free lock", wantRun: true},
+		{name: "contended lock", objects: []client.Object{newTestLockLease(lockName, "other-instance", time.Now())}},
+		{name: "expired lock is taken over", objects: []client.Object{newTestLockLease(lockName, "crashed-instance", time.Now().Add(-2*LockLeaseDuration))}, wantRun: true},
+		{name: "released lock without holder", objects: []client.Object{newTestLockLease(lockName, "", time.Now())}, wantRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build()
+			r := &NamespaceReconciler{Client: c, Log: logr.Discard()}
+			ctx := context.Background()
+
+			ran := false
+			err := r.suspendWithLock(ctx, namespace, "suspend", func(ctx context.Context) error {
+				ran = true
+				lease := &coordinationv1.Lease{}
+				if err := c.Get(ctx, client.ObjectKey{Name: lockName, Namespace: "sealos-system"}, lease); err != nil {
+					t.Fatalf("lock lease should be held during the operation, get error = %v", err)
+				}
+				if holder := ptr.Deref(lease.Spec.HolderIdentity, ""); !strings.HasPrefix(holder, "namespace-controller-") {
+					t.Errorf("lease holder = %q, want this instance", holder)
+				}
+				if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+					t.Errorf("operation context should be bounded by LockTimeout")
+				}
+				return nil
+			})
+			if ran != tt.wantRun {
+				t.Fatalf("operation ran = %v, want %v (error %v)", ran, tt.wantRun, err)
+			}
+
+			lease := &coordinationv1.Lease{}
+			getErr := c.Get(ctx, client.ObjectKey{Name: lockName, Namespace: "sealos-system"}, lease)
+			if !tt.wantRun {
+				if err == nil || err.Error() != "操作正在被其他实例执行" {
+					t.Errorf("suspendWithLock() error = %v, want lock held error", err)
+				}
+				if getErr != nil || ptr.Deref(lease.Spec.HolderIdentity, "") != "other-instance" {
+					t.Errorf("contended lease should keep its holder, got %v (get error %v)", lease.Spec.HolderIdentity, getErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("suspendWithLock() error = %v", err)
+			}
+			if !errors.IsNotFound(getErr) {
+				t.Errorf("lock lease should be released, get error = %v", getErr)
+			}
+		})
+	}
+}
+
+func TestSuspendWithLock_FakeClockExpiry(t *testing.T) {
+	const namespace = "ns-test"
+	lockName := "debt-suspend-" + namespace
+	renewed := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakePassiveClock(renewed.Add(LockLeaseDuration - time.Second))
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newTestLockLease(lockName, "crashed-instance", renewed)).Build()
+	r := &NamespaceReconciler{Client: c, Log: logr.Discard(), Clock: fakeClock}
+	ctx := context.Background()
+
+	// 有效期内锁仍被原持有者占用
+	ran := false
+	err := r.suspendWithLock(ctx, namespace, "suspend", func(context.Context) error {
+		ran = true
+		return nil
+	})
+	if ran || err != errLockHeld {
+		t.Fatalf("operation ran = %v, error = %v, want lock held before the lease expires", ran, err)
+	}
+
+	// 假时钟越过有效期后强制获取锁，新锁的续约时间来自假时钟
+	fakeClock.SetTime(renewed.Add(LockLeaseDuration + time.Second))
+	err = r.suspendWithLock(ctx, namespace, "suspend", func(ctx context.Context) error {
+		ran = true
+		lease := &coordinationv1.Lease{}
+		if err := c.Get(ctx, client.ObjectKey{Name: lockName, Namespace: "sealos-system"}, lease); err != nil {
+			t.Fatalf("lock lease should be held during the operation, get error = %v", err)
+		}
+		if lease.Spec.RenewTime == nil || !lease.Spec.RenewTime.Time.Equal(fakeClock.Now()) {
+			t.Errorf("lease renew time = %v, want %v", lease.Spec.RenewTime, fakeClock.Now())
+		}
+		return nil
+	})
+	if err != nil || !ran {
+		t.Fatalf("operation ran = %v, error = %v, want the expired lock taken over", ran, err)
+	}
+}
+
+func TestReleaseLockLease_KeepsTakenOverLease(t *testing.T) {
+	const lockName = "debt-resume-ns-test"
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newTestLockLease(lockName, "new-holder", time.Now())).Build()
+	r := &NamespaceReconciler{Client: c, Log: logr.Discard()}
+
+	// 原持有者超时后锁已被其他实例获取，释放时不能删除
+	r.releaseLockLease(lockName, "old-holder")
+	if err := c.Get(context.Background(), client.ObjectKey{Name: lockName, Namespace: "sealos-system"}, &coordinationv1.Lease{}); err != nil {
+		t.Errorf("lease taken over by another instance should be kept, get error = %v", err)
+	}
+}
+
+// recordingStrategy 记录 Resume 调用顺序的暂停策略
+type recordingStrategy struct {
+	name    string
+	resumed *[]string
+}
+
+func (s *recordingStrategy) Suspend(_ context.Context, _ string) error { return nil }
+
+func (s *recordingStrategy) Resume(_ context.Context, namespace string) error {
+	*s.resumed = append(*s.resumed, s.name+"/"+namespace)
+	return nil
+}
+
+func (s *recordingStrategy) IsSupported(_ string) bool { return true }
+
+func (s *recordingStrategy) GetName() string { return s.name }
+
+func newTestTransactionRecord(t *testing.T, txn *SuspensionTransaction) *corev1.ConfigMap {
+	t.Helper()
+	data, err := json.Marshal(txn)
+	if err != nil {
+		t.Fatalf("marshal transaction: %v", err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      transactionRecordName(txn.Namespace),
+			Namespace: TransactionRecordNamespace,
+			Labels:    map[string]string{TransactionRecordLabel: "true"},
+		},
+		Data: map[string]string{TransactionRecordKey: string(data)},
+	}
+}
+
+func TestRecoverPendingTransactions(t *testing.T) {
+	const namespace = "ns-test"
+	// 模拟暂停到一半时控制器崩溃：网络和 RBAC 已暂停，事务记录仍为 IN_PROGRESS
+	record := newTestTransactionRecord(t, &SuspensionTransaction{
+		Namespace: namespace,
+		Status:    TransactionInProgress,
+		Steps:     []string{StrategyNetwork + "_suspended", StrategyRBAC + "_suspended"},
+	})
+	tests := []struct {
+		name        string
+		objects     []client.Object
+		wantErr     bool
+		wantResumed []string
+		wantRecord  bool
+	}{
+		{
+			name:        "in-progress transaction is rolled back",
+			objects:     []client.Object{record.DeepCopy()},
+			wantResumed: []string{"rbac/" + namespace, "network/" + namespace},
+		},
+		{
+			name:       "lock still held by another instance",
+			objects:    []client.Object{record.DeepCopy(), newTestLockLease("debt-suspend-"+namespace, "other-instance", time.Now())},
+			wantErr:    true,
+			wantRecord: true,
+		},
+		{
+			name: "completed transaction is only cleaned up",
+			objects: []client.Object{newTestTransactionRecord(t, &SuspensionTransaction{
+				Namespace: namespace,
+				Status:    TransactionCompleted,
+				Steps:     []string{StrategyNetwork + "_suspended"},
+			})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build()
+			var resumed []string
+			r := &NamespaceReconciler{Client: c, Log: logr.Discard(), strategies: []SuspensionStrategy{
+				&recordingStrategy{name: StrategyNetwork, resumed: &resumed},
+				&recordingStrategy{name: StrategyRBAC, resumed: &resumed},
+			}}
+
+			err := r.recoverPendingTransactions(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("recoverPendingTransactions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(resumed, tt.wantResumed) {
+				t.Errorf("resumed = %v, want %v", resumed, tt.wantResumed)
+			}
+			getErr := c.Get(context.Background(), client.ObjectKey{Name: transactionRecordName(namespace), Namespace: TransactionRecordNamespace}, &corev1.ConfigMap{})
+			if tt.wantRecord && getErr != nil {
+				t.Errorf("transaction record should be kept for retry, get error = %v", getErr)
+			}
+			if !tt.wantRecord && !errors.IsNotFound(getErr) {
+				t.Errorf("transaction record should be deleted, get error = %v", getErr)
+			}
+		})
+	}
+}
+
+func TestRecordTransactionStep(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	r := &NamespaceReconciler{Client: c, Log: logr.Discard()}
+	ctx := context.Background()
+	txn := &SuspensionTransaction{Namespace: "ns-test", Status: TransactionInProgress, Steps: []string{}}
+
+	if err := r.saveTransaction(ctx, txn); err != nil {
+		t.Fatalf("saveTransaction() error = %v", err)
+	}
+	// 并行策略同时完成时每个步骤都要落盘
+	var wg sync.WaitGroup
+	for _, name := range []string{StrategyNetwork, StrategyCertManager} {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := r.recordTransactionStep(ctx, txn, name+"_suspended"); err != nil {
+				t.Errorf("recordTransactionStep() error = %v", err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: transactionRecordName("ns-test"), Namespace: TransactionRecordNamespace}, configMap); err != nil {
+		t.Fatalf("get transaction record: %v", err)
+	}
+	saved := &SuspensionTransaction{}
+	if err := json.Unmarshal([]byte(configMap.Data[TransactionRecordKey]), saved); err != nil {
+		t.Fatalf("unmarshal transaction record: %v", err)
+	}
+	if saved.Status != TransactionInProgress || len(saved.Steps) != 2 {
+		t.Errorf("saved transaction = %+v, want IN_PROGRESS with 2 steps", saved)
+	}
+
+	if err := r.deleteTransactionRecord(ctx, "ns-test"); err != nil {
+		t.Fatalf("deleteTransactionRecord() error = %v", err)
+	}
+	if err := r.deleteTransactionRecord(ctx, "ns-test"); err != nil {
+		t.Errorf("deleting a missing record should be a no-op, error = %v", err)
+	}
+}
+
+// noopStrategy 不做任何操作的暂停策略，可被并行调用
+type noopStrategy struct {
+	name string
+}
+
+func (s *noopStrategy) Suspend(_ context.Context, _ string) error { return nil }
+
+func (s *noopStrategy) Resume(_ context.Context, _ string) error { return nil }
+
+func (s *noopStrategy) IsSupported(_ string) bool { return true }
+
+func (s *noopStrategy) GetName() string { return s.name }
+
+func TestExecuteResumeStrategies_RecordsParallelSteps(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	names := []string{StrategyRBAC, StrategyCertManager, StrategyNetwork, StrategyScalable, StrategyPVC, StrategyWorkload}
+	var strategies []SuspensionStrategy
+	for _, name := range names {
+		strategies = append(strategies, &noopStrategy{name: name})
+	}
+	r := &NamespaceReconciler{Client: c, Log: logr.Discard(), strategies: strategies}
+	txn := &SuspensionTransaction{Namespace: "ns-test", Status: TransactionInProgress}
+
+	// 第二阶段的策略并行恢复并同时追加步骤，使用 -race 运行检查并发写入
+	if err := r.executeResumeStrategies(context.Background(), "ns-test", txn); err != nil {
+		t.Fatalf("executeResumeStrategies() error = %v", err)
+	}
+	if txn.Status != TransactionCompleted {
+		t.Errorf("transaction status = %s, want %s", txn.Status, TransactionCompleted)
+	}
+	if len(txn.Steps) != len(names) {
+		t.Fatalf("steps = %v, want one step per strategy", txn.Steps)
+	}
+	if txn.Steps[0] != StrategyRBAC+"_resumed" {
+		t.Errorf("first step = %s, want RBAC to be resumed first", txn.Steps[0])
+	}
+	for _, name := range names {
+		if !slices.Contains(txn.Steps, name+"_resumed") {
+			t.Errorf("steps = %v, missing %s_resumed", txn.Steps, name)
+		}
+	}
+}
+
+func newTestDryRunObjects(namespace string) []runtime.Object {
+	ingress := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{
+		"rules": []interface{}{map[string]interface{}{"host": "app.example.com"}},
+	}}}
+	ingress.SetAPIVersion("networking.k8s.io/v1")
+	ingress.SetKind("Ingress")
+	ingress.SetName("app")
+	ingress.SetNamespace(namespace)
+
+	service := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{
+		"ports": []interface{}{map[string]interface{}{"port": int64(80)}},
+	}}}
+	service.SetAPIVersion("v1")
+	service.SetKind("Service")
+	service.SetName("app")
+	service.SetNamespace(namespace)
+
+	// 已暂停的资源不会再次修改，演练时也不应列出
+	suspendedService := service.DeepCopy()
+	suspendedService.SetName("suspended")
+	suspendedService.SetAnnotations(map[string]string{DebtSuspendedAnnotation: "true"})
+
+	challenge := &unstructured.Unstructured{}
+	challenge.SetAPIVersion("acme.cert-manager.io/v1")
+	challenge.SetKind("Challenge")
+	challenge.SetName("app-challenge")
+	challenge.SetNamespace(namespace)
+
+	return []runtime.Object{ingress, service, suspendedService, challenge,
+		newTestCertificate("app", namespace), newTestScaler("worker", namespace, ptr.To[int64](2))}
+}
+
+func newTestDryRunReconciler(namespace string) (*NamespaceReconciler, *dynamicfake.FakeDynamicClient) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			testCertificateGVR: "CertificateList",
+			testServiceGVR:     "ServiceList",
+			testScalerGVR:      "ScalerList",
+			{Group: "acme.cert-manager.io", Version: "v1", Resource: "challenges"}:          "ChallengeList",
+			{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}:              "IngressList",
+			{Group: "networking.istio.io", Version: "v1beta1", Resource: "gateways"}:        "GatewayList",
+			{Group: "networking.istio.io", Version: "v1beta1", Resource: "virtualservices"}: "VirtualServiceList",
+		}, newTestDryRunObjects(namespace)...)
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&rbacv1.RoleBinding{
+			ObjectMeta: v12.ObjectMeta{Name: "user-binding", Namespace: namespace},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "user"},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: v12.ObjectMeta{Name: "system:controller", Namespace: namespace},
+			RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: "system"},
+		},
+	).Build()
+	cache := NewResourceCache(DefaultCacheTTL)
+	r := &NamespaceReconciler{Client: c, Log: logr.Discard(), DryRun: true, resourceCache: cache, strategies: []SuspensionStrategy{
+		&CertManagerStrategy{client: c, dynamicClient: dynamicClient, cache: cache},
+		&NetworkStrategy{client: c, dynamicClient: dynamicClient, cache: cache},
+		&RBACStrategy{client: c, cache: cache},
+		&ScalableStrategy{dynamicClient: dynamicClient, cache: cache, resources: []ScalableResourceConfig{{
+			GVR:          "apps.example.com/v1/scalers",
+			SuspendPatch: `{"spec":{"replicas":0}}`,
+		}}},
+	}}
+	return r, dynamicClient
+}
+
+func TestDryRunSuspension(t *testing.T) {
+	const namespace = "ns-test"
+	r, dynamicClient := newTestDryRunReconciler(namespace)
+	ctx := context.Background()
+
+	txn, err := r.dryRunSuspension(ctx, namespace)
+	if err != nil {
+		t.Fatalf("dryRunSuspension() error = %v", err)
+	}
+	for _, action := range dynamicClient.Actions() {
+		if verb := action.GetVerb(); verb != "list" && verb != "get" {
+			t.Errorf("dry-run issued a %s on %s", verb, action.GetResource().Resource)
+		}
+	}
+	rb := &rbacv1.RoleBinding{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: "user-binding", Namespace: namespace}, rb); err != nil {
+		t.Fatalf("get rolebinding: %v", err)
+	}
+	if rb.RoleRef.Name != "user" || rb.Annotations["debt.sealos.io/suspended"] != "" {
+		t.Errorf("dry-run modified the rolebinding: %+v", rb)
+	}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: "debt-restricted-role", Namespace: namespace}, &rbacv1.Role{}); !errors.IsNotFound(err) {
+		t.Errorf("dry-run should not create the restricted role, get error = %v", err)
+	}
+	if txn.Status != TransactionCompleted {
+		t.Errorf("transaction status = %s, want %s", txn.Status, TransactionCompleted)
+	}
+	// 演练不更新缓存，否则随后的真实暂停会被跳过
+	if _, found := r.resourceCache.IsSuspended(namespace, StrategyNetwork); found {
+		t.Errorf("dry-run should not mark strategies as suspended in the cache")
+	}
+
+	reported := make(map[string][]string)
+	for _, step := range txn.Steps {
+		strategy, resource, ok := strings.Cut(step, "_dryrun:")
+		if !ok {
+			t.Fatalf("unexpected dry-run step %q", step)
+		}
+		reported[strategy] = append(reported[strategy], resource)
+	}
+	for _, resources := range reported {
+		sort.Strings(resources)
+	}
+
+	// 真实暂停修改的资源与演练报告一致
+	dynamicClient.ClearActions()
+	for _, strategy := range r.strategies {
+		if err := strategy.Suspend(ctx, namespace); err != nil {
+			t.Fatalf("%s Suspend() error = %v", strategy.GetName(), err)
+		}
+	}
+	strategyByResource := map[string]string{
+		"certificates": StrategyCertManager, "ingresses": StrategyNetwork, "services": StrategyNetwork, "scalers": StrategyScalable,
+	}
+	modified := map[string][]string{StrategyRBAC: {"Role/debt-restricted-role", "RoleBinding/user-binding"}}
+	for _, action := range dynamicClient.Actions() {
+		switch action.GetVerb() {
+		case "update":
+			obj := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+			strategy := strategyByResource[action.GetResource().Resource]
+			modified[strategy] = append(modified[strategy], obj.GetKind()+"/"+obj.GetName())
+		case "delete-collection":
+			modified[StrategyCertManager] = append(modified[StrategyCertManager], "Challenge/app-challenge")
+		}
+	}
+	for _, resources := range modified {
+		sort.Strings(resources)
+	}
+	if !reflect.DeepEqual(reported, modified) {
+		t.Errorf("dry-run reported %v, real run modified %v", reported, modified)
+	}
+}
+
+func TestResumeUserResourceByStrategy(t *testing.T) {
+	const namespace = "ns-test"
+	r, dynamicClient := newTestDryRunReconciler(namespace)
+	r.DryRun = false
+	ctx := context.Background()
+	// 演练用例中预置的已暂停 Service 没有备份数据，这里不需要
+	if err := dynamicClient.Resource(testServiceGVR).Namespace(namespace).Delete(ctx, "suspended", v12.DeleteOptions{}); err != nil {
+		t.Fatalf("delete service: %v", err)
+	}
+
+	for _, strategy := range r.strategies {
+		if name := strategy.GetName(); name == StrategyNetwork || name == StrategyRBAC {
+			if err := strategy.Suspend(ctx, namespace); err != nil {
+				t.Fatalf("%s Suspend() error = %v", name, err)
+			}
+		}
+	}
+	// 命名空间整体处于暂停状态，部分恢复不应被幂等检查跳过，也不应改变整体状态
+	r.resourceCache.SetSuspended(namespace, "all", true)
+
+	if err := r.ResumeUserResourceByStrategy(ctx, namespace, "compute-typo"); err == nil {
+		t.Fatalf("ResumeUserResourceByStrategy() with an unknown strategy should fail")
+	}
+	if err := r.ResumeUserResourceByStrategy(ctx, namespace, StrategyNetwork); err != nil {
+		t.Fatalf("ResumeUserResourceByStrategy() error = %v", err)
+	}
+
+	// 网络资源恢复
+	service, err := dynamicClient.Resource(testServiceGVR).Namespace(namespace).Get(ctx, "app", v12.GetOptions{})
+	if err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if service.GetAnnotations()[DebtSuspendedAnnotation] == "true" {
+		t.Errorf("service still suspended after resuming the network strategy")
+	}
+
+	// RBAC 保持暂停
+	rb := &rbacv1.RoleBinding{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: "user-binding", Namespace: namespace}, rb); err != nil {
+		t.Fatalf("get rolebinding: %v", err)
+	}
+	if rb.RoleRef.Name != "debt-restricted-role" {
+		t.Errorf("rolebinding role = %s, want debt-restricted-role", rb.RoleRef.Name)
+	}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: "debt-restricted-role", Namespace: namespace}, &rbacv1.Role{}); err != nil {
+		t.Errorf("restricted role should be kept, get error = %v", err)
+	}
+
+	for strategy, want := range map[string]bool{StrategyNetwork: false, StrategyRBAC: true, "all": true} {
+		if suspended, found := r.resourceCache.IsSuspended(namespace, strategy); !found || suspended != want {
+			t.Errorf("cache %s = %v (found %v), want %v", strategy, suspended, found, want)
+		}
+	}
+}
+
+func newTestSnapshotObjects(namespace string) []runtime.Object {
+	newObject := func(apiVersion, kind, namespace, name string, fields map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: fields}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj
+	}
+	return []runtime.Object{
+		newObject("v1", "PersistentVolumeClaim", namespace, "data", map[string]interface{}{
+			"spec": map[string]interface{}{"storageClassName": "csi-rbd"},
+		}),
+		newObject("v1", "PersistentVolumeClaim", namespace, "cache", map[string]interface{}{
+			"spec": map[string]interface{}{"storageClassName": "local-path"},
+		}),
+		newObject("storage.k8s.io/v1", "StorageClass", "", "csi-rbd", map[string]interface{}{"provisioner": "rbd.csi.ceph.com"}),
+		newObject("storage.k8s.io/v1", "StorageClass", "", "local-path", map[string]interface{}{"provisioner": "rancher.io/local-path"}),
+		newObject("snapshot.storage.k8s.io/v1", "VolumeSnapshotClass", "", "rbd-snapshot", map[string]interface{}{"driver": "rbd.csi.ceph.com"}),
+	}
+}
+
+func TestDeleteUserResource_FinalDeletionSnapshot(t *testing.T) {
+	const namespace = "ns-test"
+	for _, enabled := range []bool{true, false} {
+		t.Run(fmt.Sprintf("snapshot enabled=%v", enabled), func(t *testing.T) {
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					pvcGVR:                 "PersistentVolumeClaimList",
+					storageClassGVR:        "StorageClassList",
+					volumeSnapshotGVR:      "VolumeSnapshotList",
+					volumeSnapshotClassGVR: "VolumeSnapshotClassList",
+				}, newTestSnapshotObjects(namespace)...)
+			var mu sync.Mutex
+			var deleted []string
+			dynamicClient.PrependReactor("delete-collection", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				deleted = append(deleted, action.GetResource().Resource)
+				return true, nil, nil
+			})
+			r := &NamespaceReconciler{
+				dynamicClient:         dynamicClient,
+				Log:                   logr.Discard(),
+				suspensionConfig:      &SuspensionConfig{},
+				FinalDeletionSnapshot: enabled,
+			}
+			ctx := context.Background()
+
+			if err := r.DeleteUserResource(ctx, namespace); err != nil {
+				t.Fatalf("DeleteUserResource() error = %v", err)
+			}
+			if !slices.Contains(deleted, "persistentvolumeclaims") {
+				t.Errorf("pvcs were not deleted, deleted resources = %v", deleted)
+			}
+
+			snapshots, err := dynamicClient.Resource(volumeSnapshotGVR).Namespace(namespace).List(ctx, v12.ListOptions{
+				LabelSelector: FinalDeletionSnapshotLabel + "=true",
+			})
+			if err != nil {
+				t.Fatalf("list volumesnapshots: %v", err)
+			}
+			if !enabled {
+				if len(snapshots.Items) != 0 {
+					t.Errorf("snapshots = %d, want none when disabled", len(snapshots.Items))
+				}
+				return
+			}
+			// 只有支持快照的存储类上的 PVC 会创建快照
+			if len(snapshots.Items) != 1 {
+				t.Fatalf("snapshots = %d, want 1", len(snapshots.Items))
+			}
+			snapshot := snapshots.Items[0]
+			source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+			class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+			if source != "data" || class != "rbd-snapshot" || snapshot.GetAnnotations()[FinalDeletionSourcePVCAnnotation] != "data" {
+				t.Errorf("snapshot %s: source = %s, class = %s, want data/rbd-snapshot", snapshot.GetName(), source, class)
+			}
+
+			// 快照在删除 PVC 之前创建
+			created, pvcDeleted := -1, -1
+			for i, action := range dynamicClient.Actions() {
+				switch {
+				case action.GetVerb() == "create" && action.GetResource() == volumeSnapshotGVR:
+					created = i
+				case action.GetVerb() == "delete-collection" && action.GetResource() == pvcGVR:
+					pvcDeleted = i
+				}
+			}
+			if created < 0 || pvcDeleted < created {
+				t.Errorf("snapshot created at action %d, pvcs deleted at action %d, want the snapshot first", created, pvcDeleted)
+			}
+		})
+	}
+}
+
+func newTestWorkload(kind, name, namespace string, replicas *int64) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}}
+	if replicas != nil {
+		obj.Object["spec"] = map[string]interface{}{"replicas": *replicas}
+	}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+func TestWorkloadStrategy_SuspendResume(t *testing.T) {
+	const namespace = "ns-test"
+	deployGVR, stsGVR := workloadGVRs[0], workloadGVRs[1]
+	kept := newTestWorkload("Deployment", "kept", namespace, ptr.To[int64](2))
+	kept.SetLabels(map[string]string{resources.AppDeployLabelKey: "kept"})
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{deployGVR: "DeploymentList", stsGVR: "StatefulSetList"},
+		newTestWorkload("Deployment", "web", namespace, ptr.To[int64](3)),
+		newTestWorkload("Deployment", "defaulted", namespace, nil),
+		newTestWorkload("Deployment", "idle", namespace, ptr.To[int64](0)),
+		newTestWorkload("StatefulSet", "db", namespace, ptr.To[int64](2)),
+		kept,
+	)
+	strategy := &WorkloadStrategy{dynamicClient: dynamicClient, cache: NewResourceCache(DefaultCacheTTL)}
+	// kept 应用只执行网络策略，计算资源保持运行
+	ctx := withAppStrategyOverrides(context.Background(), AppStrategyOverrides{"kept": {StrategyNetwork: true}})
+
+	get := func(gvr schema.GroupVersionResource, name string) *unstructured.Unstructured {
+		t.Helper()
+		obj, err := dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, v12.GetOptions{})
+		if err != nil {
+			t.Fatalf("get %s %s: %v", gvr.Resource, name, err)
+		}
+		return obj
+	}
+	replicasOf := func(obj *unstructured.Unstructured) int64 {
+		replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		return replicas
+	}
+
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	for _, tt := range []struct {
+		gvr          schema.GroupVersionResource
+		name         string
+		wantReplicas int64
+		wantOriginal string
+	}{
+		{deployGVR, "web", 0, "3"},
+		{deployGVR, "defaulted", 0, "1"},
+		{stsGVR, "db", 0, "2"},
+		{deployGVR, "idle", 0, ""},
+		{deployGVR, "kept", 2, ""},
+	} {
+		obj := get(tt.gvr, tt.name)
+		if got := replicasOf(obj); got != tt.wantReplicas {
+			t.Errorf("%s replicas after suspend = %d, want %d", tt.name, got, tt.wantReplicas)
+		}
+		if got := obj.GetAnnotations()[DebtOriginalReplicasAnnotation]; got != tt.wantOriginal {
+			t.Errorf("%s original replicas annotation = %q, want %q", tt.name, got, tt.wantOriginal)
+		}
+	}
+
+	// 已暂停时再次暂停不会覆盖记录的副本数
+	strategy.cache.ClearNamespace(namespace)
+	if err := strategy.Suspend(ctx, namespace); err != nil {
+		t.Fatalf("second Suspend() error = %v", err)
+	}
+	if got := get(deployGVR, "web").GetAnnotations()[DebtOriginalReplicasAnnotation]; got != "3" {
+		t.Errorf("original replicas after second suspend = %q, want 3", got)
+	}
+
+	dynamicClient.ClearActions()
+	if err := strategy.Resume(ctx, namespace); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	for _, tt := range []struct {
+		gvr          schema.GroupVersionResource
+		name         string
+		wantReplicas int64
+	}{
+		{deployGVR, "web", 3},
+		{deployGVR, "defaulted", 1},
+		{stsGVR, "db", 2},
+		{deployGVR, "idle", 0},
+		{deployGVR, "kept", 2},
+	} {
+		obj := get(tt.gvr, tt.name)
+		if got := replicasOf(obj); got != tt.wantReplicas {
+			t.Errorf("%s replicas after resume = %d, want %d", tt.name, got, tt.wantReplicas)
+		}
+		for _, key := range []string{DebtSuspendedAnnotation, DebtSuspendedAtAnnotation, DebtOriginalReplicasAnnotation} {
+			if _, ok := obj.GetAnnotations()[key]; ok {
+				t.Errorf("%s still has annotation %s after resume", tt.name, key)
+			}
+		}
+	}
+	// 零副本的工作负载没有被暂停，恢复时不更新
+	for _, action := range dynamicClient.Actions() {
+		if update, ok := action.(k8stesting.UpdateAction); ok {
+			if name := update.GetObject().(*unstructured.Unstructured).GetName(); name == "idle" || name == "kept" {
+				t.Errorf("Resume() updated workload %s that was not suspended", name)
+			}
+		}
+	}
+}
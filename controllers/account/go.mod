@@ -23,6 +23,7 @@ require (
 	github.com/labring/sealos/controllers/user v0.0.0
 	github.com/matoous/go-nanoid/v2 v2.0.0
 	github.com/minio/madmin-go/v3 v3.0.35
+	github.com/minio/minio-go/v7 v7.0.64
 	github.com/onsi/ginkgo v1.16.5
 	github.com/onsi/gomega v1.36.1
 	github.com/sirupsen/logrus v1.9.3
@@ -84,7 +85,6 @@ require (
 	github.com/mailru/easyjson v0.7.7 // indirect
 	github.com/matttproud/golang_protobuf_extensions v1.0.4 // indirect
 	github.com/minio/md5-simd v1.1.2 // indirect
-	github.com/minio/minio-go/v7 v7.0.64 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/montanaflynn/stats v0.6.6 // indirect
@@ -276,6 +276,19 @@ func main() {
 		setupLog.Error(err, "unable to add billing task runner")
 		os.Exit(1)
 	}
+	billingExportRunner, err := controllers.NewBillingExportRunnerFromEnv(mgr.GetClient(), ctrl.Log.WithName("controllers"), dbClient)
+	if err != nil {
+		setupLog.Error(err, "unable to create billing export runner")
+		os.Exit(1)
+	}
+	if billingExportRunner != nil {
+		if err := mgr.Add(billingExportRunner); err != nil {
+			setupLog.Error(err, "unable to add billing export runner")
+			os.Exit(1)
+		}
+	} else {
+		setupLog.Info("skip billing export", "reason", controllers.BillingExportBucketEnv+" is not set")
+	}
 	if env.GetEnvWithDefault("SUPPORT_DEBT", _true) == _true {
 		if err := mgr.Add(debtController); err != nil {
 			setupLog.Error(err, "unable to add debt controller")
@@ -30,6 +30,16 @@ const (
 	Nginx IngressType = "nginx"
 )
 
+// +kubebuilder:validation:Enum=mysql;postgresql;mongodb;redis
+type DatabaseEngine string
+
+const (
+	MySQL      DatabaseEngine = "mysql"
+	PostgreSQL DatabaseEngine = "postgresql"
+	MongoDB    DatabaseEngine = "mongodb"
+	Redis      DatabaseEngine = "redis"
+)
+
 // AdminerSpec defines the desired state of Adminer
 type AdminerSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -42,12 +52,44 @@ type AdminerSpec struct {
 	//+kubebuilder:validation:Optional
 	//+kubebuilder:default=nginx
 	IngressType IngressType `json:"ingressType"`
+	// Engine is the database engine the UI targets, it selects the engine-specific security headers.
+	// Empty means the generic adminer.
+	//+kubebuilder:validation:Optional
+	Engine DatabaseEngine `json:"engine,omitempty"`
+	// HostnamePrefix replaces the default "a" prefix of the generated hostname, e.g. the username.
+	// It must start with a lower case letter and contain only lower case letters, digits and '-'.
+	// It only takes effect when the hostname is first generated.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxLength=55
+	//+kubebuilder:validation:Pattern=`^[a-z][a-z0-9-]*$`
+	HostnamePrefix string `json:"hostnamePrefix,omitempty"`
+	// CustomDomain serves the adminer on the user's own domain instead of the generated one.
+	// It is validated by the admission webhook and requires a TLS secret named <name>-tls.
+	//+kubebuilder:validation:Optional
+	CustomDomain string `json:"customDomain,omitempty"`
+	// AdditionalDomains are extra custom domains served next to the primary domain (the generated
+	// one, or CustomDomain), e.g. a custom domain on top of the generated one. They are validated like
+	// CustomDomain, share the <name>-tls secret and are only served with the istio ingress type.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:MaxItems=10
+	AdditionalDomains []string `json:"additionalDomains,omitempty"`
 }
 
 // AdminerStatus defines the observed state of Adminer
 type AdminerStatus struct {
 	AvailableReplicas int32  `json:"availableReplicas"`
 	Domain            string `json:"domain"`
+	// GatewayReference is the namespace/name of the Istio Gateway serving the domain.
+	//+optional
+	GatewayReference string `json:"gatewayReference,omitempty"`
+	// DomainType is "public" for domains under the shared gateway and "custom" for user domains.
+	//+optional
+	DomainType string `json:"domainType,omitempty"`
+	// Conditions describe the admission of the instance, e.g. whether it was refused by the per-namespace instance limit.
+	//+optional
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
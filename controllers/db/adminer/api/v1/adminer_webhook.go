@@ -0,0 +1,100 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var adminerlog = logf.Log.WithName("adminer-webhook")
+
+// CustomDomainValidator validates user supplied custom domains, e.g. istio.DomainAllocator.
+type CustomDomainValidator interface {
+	ValidateCustomDomain(ctx context.Context, domain string) error
+}
+
+func (r *Adminer) SetupWebhookWithManager(mgr ctrl.Manager, domainValidator CustomDomainValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&AdminerValidator{DomainValidator: domainValidator}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-adminer-db-sealos-io-v1-adminer,mutating=false,failurePolicy=fail,sideEffects=None,groups=adminer.db.sealos.io,resources=adminers,verbs=create;update,versions=v1,name=vadminer.kb.io,admissionReviewVersions=v1
+//+kubebuilder:object:generate=false
+
+// AdminerValidator rejects adminers whose custom domain would never be served.
+type AdminerValidator struct {
+	DomainValidator CustomDomainValidator
+}
+
+var _ webhook.CustomValidator = &AdminerValidator{}
+
+func (v *AdminerValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	adminer, ok := obj.(*Adminer)
+	if !ok {
+		return admission.Warnings{}, errors.New("obj convert Adminer is error")
+	}
+	return admission.Warnings{}, v.validateCustomDomain(ctx, adminer)
+}
+
+func (v *AdminerValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldAdminer, ok := oldObj.(*Adminer)
+	if !ok {
+		return admission.Warnings{}, errors.New("obj convert Adminer is error")
+	}
+	newAdminer, ok := newObj.(*Adminer)
+	if !ok {
+		return admission.Warnings{}, errors.New("obj convert Adminer is error")
+	}
+	// keepalive updates must not fail because of a domain admitted before
+	if oldAdminer.Spec.CustomDomain == newAdminer.Spec.CustomDomain && slices.Equal(oldAdminer.Spec.AdditionalDomains, newAdminer.Spec.AdditionalDomains) {
+		return admission.Warnings{}, nil
+	}
+	return admission.Warnings{}, v.validateCustomDomain(ctx, newAdminer)
+}
+
+func (v *AdminerValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return admission.Warnings{}, nil
+}
+
+func (v *AdminerValidator) validateCustomDomain(ctx context.Context, adminer *Adminer) error {
+	if v.DomainValidator == nil {
+		return nil
+	}
+	domains := adminer.Spec.AdditionalDomains
+	if adminer.Spec.CustomDomain != "" {
+		domains = append([]string{adminer.Spec.CustomDomain}, domains...)
+	}
+	for _, domain := range domains {
+		if err := v.DomainValidator.ValidateCustomDomain(ctx, domain); err != nil {
+			adminerlog.Info("reject custom domain", "name", adminer.Name, "namespace", adminer.Namespace, "domain", domain, "reason", err.Error())
+			return fmt.Errorf("invalid custom domain %q: %w", domain, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestSyncNetworking_AdditionalDomains(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := adminerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add adminer scheme: %v", err)
+	}
+	adminer := &adminerv1.Adminer{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test", UID: "uid-1"},
+		Spec:       adminerv1.AdminerSpec{AdditionalDomains: []string{"db.example.com"}},
+	}
+	objects := []client.Object{
+		adminer,
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test"},
+			Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test-adminer-tls", Namespace: "ns-test"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(adminer).Build()
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+		SkipDNSValidation:    true,
+	}
+	r := &AdminerReconciler{
+		Client:          c,
+		Scheme:          scheme,
+		adminerDomain:   "cloud.sealos.io",
+		tlsEnabled:      true,
+		useIstio:        true,
+		istioHelper:     istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, istioConfig, "adminer"),
+		istioReconciler: NewAdminerIstioNetworkingReconciler(c, istioConfig, true, "cloud.sealos.io"),
+	}
+	ctx := context.Background()
+
+	if err := r.syncNetworking(ctx, adminer, "abcdef", map[string]string{"app.kubernetes.io/name": adminer.Name}); err != nil {
+		t.Fatalf("syncNetworking() error = %v", err)
+	}
+
+	// 一个 VirtualService 同时路由生成的公共域名和自定义域名，并绑定共享 Gateway 与应用 Gateway
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	if err := c.Get(ctx, client.ObjectKey{Name: adminer.Name + "-vs", Namespace: adminer.Namespace}, vs); err != nil {
+		t.Fatalf("failed to get virtualservice: %v", err)
+	}
+	hosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts")
+	if want := []string{"abcdef.cloud.sealos.io", "db.example.com"}; !slices.Equal(hosts, want) {
+		t.Errorf("virtualservice hosts = %v, want %v", hosts, want)
+	}
+	gateways, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "gateways")
+	for _, want := range []string{"istio-system/sealos-gateway", "ns-test/test-adminer-gateway"} {
+		if !slices.Contains(gateways, want) {
+			t.Errorf("virtualservice gateways = %v, want %s bound", gateways, want)
+		}
+	}
+	routes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if len(routes) == 0 {
+		t.Fatalf("virtualservice has no http routes")
+	}
+	route, _ := routes[0].(map[string]interface{})["route"].([]interface{})
+	if len(route) == 0 {
+		t.Fatalf("virtualservice http route has no destinations")
+	}
+	destination, _, _ := unstructured.NestedString(route[0].(map[string]interface{}), "destination", "host")
+	if destination != "test-adminer" {
+		t.Errorf("virtualservice routes to %q, want test-adminer", destination)
+	}
+
+	// 应用 Gateway 只声明自定义域名，公共域名留在共享 Gateway
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "Gateway"})
+	if err := c.Get(ctx, client.ObjectKey{Name: adminer.Name + "-gateway", Namespace: adminer.Namespace}, gateway); err != nil {
+		t.Fatalf("failed to get app gateway: %v", err)
+	}
+	servers, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "servers")
+	if len(servers) == 0 {
+		t.Fatalf("app gateway has no servers")
+	}
+	for _, server := range servers {
+		serverHosts, _, _ := unstructured.NestedStringSlice(server.(map[string]interface{}), "hosts")
+		if !slices.Equal(serverHosts, []string{"db.example.com"}) {
+			t.Errorf("app gateway server hosts = %v, want [db.example.com]", serverHosts)
+		}
+	}
+
+	got := &adminerv1.Adminer{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(adminer), got); err != nil {
+		t.Fatalf("failed to get adminer: %v", err)
+	}
+	if want := "https://abcdef.cloud.sealos.io"; got.Status.Domain != want {
+		t.Errorf("status domain = %q, want %q", got.Status.Domain, want)
+	}
+}
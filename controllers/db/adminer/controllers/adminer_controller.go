@@ -20,6 +20,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	nanoid "github.com/matoous/go-nanoid/v2"
@@ -36,10 +41,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
 	"github.com/labring/sealos/controllers/pkg/istio"
@@ -53,8 +62,21 @@ const (
 	HostnameLength      = 8
 	KeepaliveAnnotation = "lastUpdateTime"
 	LetterBytes         = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// MinHostnameLength and MaxHostnameLength bound the configurable nanoid length
+	MinHostnameLength = 4
+	MaxHostnameLength = 20
+	// DefaultHostnamePrefix is used when Spec.HostnamePrefix is empty
+	DefaultHostnamePrefix = "a"
+	// DefaultAppPort is the port the adminer container listens on and the Service exposes
+	DefaultAppPort int32 = 8080
 )
 
+// backingServiceRequeueInterval is how long to wait before retrying networking when the Service is not ready yet
+const backingServiceRequeueInterval = 5 * time.Second
+
+// hostnamePrefixRegex to keep pace with ingress host, hostname must start with a lower case letter
+var hostnamePrefixRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
 const (
 	AdminerPartOf = "adminer"
 )
@@ -132,6 +154,20 @@ type AdminerReconciler struct {
 	istioReconciler *AdminerIstioNetworkingReconciler     // 保留向后兼容
 	istioHelper     *istio.UniversalIstioNetworkingHelper // 🎯 新增通用助手
 	useIstio        bool
+	// istioMu guards useIstio, istioHelper and istioReconciler: Reconcile holds the read lock,
+	// the Istio mode reevaluator publishes the fully built components under the write lock
+	istioMu sync.RWMutex
+	// apiReader reads the domain registry ConfigMap without starting a cluster-wide ConfigMap informer
+	apiReader client.Reader
+	// hostnameAlphabet and hostnameLength tune the nanoid of generated hostnames, empty means the defaults
+	hostnameAlphabet string
+	hostnameLength   int
+	// appPort is the adminer container port shared by the probes, the Service and the networking, empty means DefaultAppPort
+	appPort int32
+	// maxInstancesPerNamespace caps the adminers started in one namespace, 0 disables the limit
+	maxInstancesPerNamespace int
+	// clock is the time source of keepalive timestamps and expiry, nil means the real time
+	clock clock.PassiveClock
 }
 
 //+kubebuilder:rbac:groups=adminer.db.sealos.io,resources=adminers,verbs=get;list;watch;create;update;patch;delete
@@ -148,10 +184,12 @@ type AdminerReconciler struct {
 //+kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.istio.io,resources=envoyfilters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cert-manager.io,resources=certificates/status,verbs=get;update;patch
 
-//-kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -163,6 +201,9 @@ type AdminerReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.12.1/pkg/reconcile
 func (r *AdminerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// 切换到 Istio 模式时等待进行中的协调结束，避免读到切换中的网络组件
+	r.istioMu.RLock()
+	defer r.istioMu.RUnlock()
 	logger := log.FromContext(ctx, "adminer", req.NamespacedName)
 	adminer := &adminerv1.Adminer{}
 	if err := r.Get(ctx, req.NamespacedName, adminer); err != nil {
@@ -178,6 +219,12 @@ func (r *AdminerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			}
 		}
 	} else {
+		// 释放为 Adminer 自动生成并登记的域名，失败时保留 finalizer 重试
+		if controllerutil.ContainsFinalizer(adminer, FinalizerName) {
+			if err := r.releaseGeneratedDomain(ctx, adminer); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		if controllerutil.RemoveFinalizer(adminer, FinalizerName) {
 			if err := retryUpdateOnConflict(ctx, r.Client, adminer, func() {
 				controllerutil.RemoveFinalizer(adminer, FinalizerName)
@@ -192,7 +239,7 @@ func (r *AdminerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, err
 	}
 
-	if isExpired(adminer) {
+	if isExpired(adminer, r.now()) {
 		if err := r.Delete(ctx, adminer); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -200,6 +247,15 @@ func (r *AdminerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, nil
 	}
 
+	admitted, err := r.admitInstance(ctx, adminer)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !admitted {
+		logger.Info("adminer refused by the per-namespace instance limit", "limit", r.maxInstancesPerNamespace)
+		return ctrl.Result{RequeueAfter: instanceLimitRequeueInterval}, nil
+	}
+
 	recLabels := label.RecommendedLabels(&label.Recommended{
 		Name:      adminer.Name,
 		ManagedBy: label.DefaultManagedBy,
@@ -226,10 +282,24 @@ func (r *AdminerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if err := r.syncNetworking(ctx, adminer, hostname, recLabels); err != nil {
+		if istio.IsBackingServiceNotReady(err) {
+			logger.Info("backing service not ready, requeue networking", "reason", err.Error())
+			return ctrl.Result{RequeueAfter: backingServiceRequeueInterval}, nil
+		}
+		if istio.IsTLSSecretNotFound(err) {
+			logger.Info("tls secret not found, requeue networking", "reason", err.Error())
+			if err := r.setTLSSecretCondition(ctx, adminer, err); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: tlsSecretRequeueInterval}, nil
+		}
 		logger.Error(err, "create networking failed")
 		r.recorder.Eventf(adminer, corev1.EventTypeWarning, "Create networking failed", "%v", err)
 		return ctrl.Result{}, err
 	}
+	if err := r.setTLSSecretCondition(ctx, adminer, nil); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// if err := r.waitEndpoints(ctx, adminer); err != nil {
 	// 	logger.Error(err, "endpoint wait failed")
@@ -313,7 +383,7 @@ func (r *AdminerReconciler) syncDeployment(ctx context.Context, adminer *adminer
 				{
 					Name:          "http",
 					Protocol:      corev1.ProtocolTCP,
-					ContainerPort: 8080,
+					ContainerPort: r.getAppPort(),
 				},
 			},
 			Resources: corev1.ResourceRequirements{
@@ -342,7 +412,7 @@ func (r *AdminerReconciler) syncDeployment(ctx context.Context, adminer *adminer
 				ProbeHandler: corev1.ProbeHandler{
 					HTTPGet: &corev1.HTTPGetAction{
 						Path: "/",
-						Port: intstr.FromInt(8080),
+						Port: intstr.FromInt32(r.getAppPort()),
 					},
 				},
 				InitialDelaySeconds: 1,
@@ -353,7 +423,7 @@ func (r *AdminerReconciler) syncDeployment(ctx context.Context, adminer *adminer
 				ProbeHandler: corev1.ProbeHandler{
 					HTTPGet: &corev1.HTTPGetAction{
 						Path: "/",
-						Port: intstr.FromInt(8080),
+						Port: intstr.FromInt32(r.getAppPort()),
 					},
 				},
 				InitialDelaySeconds: 1,
@@ -412,6 +482,9 @@ func (r *AdminerReconciler) syncDeployment(ctx context.Context, adminer *adminer
 			deployment.Spec.Template.Spec.Containers[0].Ports = containers[0].Ports
 			deployment.Spec.Template.Spec.Containers[0].Resources = containers[0].Resources
 			deployment.Spec.Template.Spec.Containers[0].VolumeMounts = containers[0].VolumeMounts
+			// 探针跟随容器端口变化
+			deployment.Spec.Template.Spec.Containers[0].StartupProbe = containers[0].StartupProbe
+			deployment.Spec.Template.Spec.Containers[0].ReadinessProbe = containers[0].ReadinessProbe
 		}
 		if len(deployment.Spec.Template.Spec.Volumes) == 0 {
 			deployment.Spec.Template.Spec.Volumes = volumes
@@ -421,12 +494,11 @@ func (r *AdminerReconciler) syncDeployment(ctx context.Context, adminer *adminer
 		}
 
 		if deployment.Spec.Template.Spec.Hostname == "" {
-			letterID, err := nanoid.Generate(LetterBytes, HostnameLength)
+			generated, err := generateHostname(adminer.Spec.HostnamePrefix, r.hostnameAlphabet, r.hostnameLength)
 			if err != nil {
 				return err
 			}
-			// to keep pace with ingress host, hostname must start with a lower case letter
-			*hostname = "a" + letterID
+			*hostname = generated
 			deployment.Spec.Template.Spec.Hostname = *hostname
 		} else {
 			*hostname = deployment.Spec.Template.Spec.Hostname
@@ -447,7 +519,7 @@ func (r *AdminerReconciler) syncService(ctx context.Context, adminer *adminerv1.
 		Selector: recLabels,
 		Type:     corev1.ServiceTypeClusterIP,
 		Ports: []corev1.ServicePort{
-			{Name: "adminer", Port: 8080, TargetPort: intstr.FromInt(8080), Protocol: corev1.ProtocolTCP},
+			{Name: "adminer", Port: r.getAppPort(), TargetPort: intstr.FromInt32(r.getAppPort()), Protocol: corev1.ProtocolTCP},
 		},
 	}
 
@@ -481,13 +553,45 @@ func (r *AdminerReconciler) syncService(ctx context.Context, adminer *adminerv1.
 func (r *AdminerReconciler) syncNetworking(ctx context.Context, adminer *adminerv1.Adminer, hostname string, recLabels map[string]string) error {
 	// 根据配置决定使用 Istio 还是 Ingress
 	if r.useIstio && r.istioReconciler != nil {
-		return r.syncIstioNetworking(ctx, adminer, hostname, recLabels)
+		if err := r.syncIstioNetworking(ctx, adminer, hostname, recLabels); err != nil {
+			return err
+		}
+		// 集群从 Ingress 模式切换到 Istio 后，VirtualService 就绪再删除旧 Ingress
+		return r.removeLegacyIngress(ctx, adminer)
 	}
 
 	// 回退到原有的 Ingress 模式
 	return r.syncIngress(ctx, adminer, hostname, recLabels)
 }
 
+// removeLegacyIngress 删除 Ingress 模式下创建的 Ingress，VirtualService 未就绪时保留以免切换期间中断访问
+func (r *AdminerReconciler) removeLegacyIngress(ctx context.Context, adminer *adminerv1.Adminer) error {
+	ingress := &networkingv1.Ingress{}
+	if err := r.Get(ctx, client.ObjectKey{Name: adminer.Name, Namespace: adminer.Namespace}, ingress); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(ingress, adminer) {
+		return nil
+	}
+
+	var (
+		status *istio.NetworkingStatus
+		err    error
+	)
+	if r.istioHelper != nil {
+		status, err = r.istioHelper.GetNetworkingStatus(ctx, adminer.Name, adminer.Namespace)
+	} else {
+		status, err = r.istioReconciler.GetNetworkingStatus(ctx, adminer)
+	}
+	if err != nil || !status.VirtualServiceReady {
+		// 下次协调再检查
+		return client.IgnoreNotFound(err)
+	}
+
+	log.FromContext(ctx).Info("virtualservice is ready, removing legacy ingress", "ingress", ingress.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, ingress))
+}
+
 func (r *AdminerReconciler) syncIngress(ctx context.Context, adminer *adminerv1.Adminer, hostname string, recLabels map[string]string) error {
 	var err error
 	host := hostname + "." + r.adminerDomain
@@ -531,15 +635,25 @@ func (r *AdminerReconciler) syncIstioNetworking(ctx context.Context, adminer *ad
 func (r *AdminerReconciler) syncOptimizedIstioNetworking(ctx context.Context, adminer *adminerv1.Adminer, hostname string, recLabels map[string]string) error {
 	// 构建域名
 	host := hostname + "." + r.adminerDomain
+	// 用户指定的自定义域名优先（已由准入 Webhook 验证）
+	if adminer.Spec.CustomDomain != "" {
+		host = adminer.Spec.CustomDomain
+	}
+
+	// 检查 VirtualService 域名是否被手动修改，observe 策略下沿用实际域名
+	host, err := r.istioHelper.ReconcileDomainDrift(ctx, adminer.Name, adminer.Namespace, host)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile domain drift: %w", err)
+	}
 
 	// 🎯 使用通用助手的智能网络配置
 	params := &istio.AppNetworkingParams{
 		Name:        adminer.Name,
 		Namespace:   adminer.Namespace,
 		AppType:     "adminer",
-		Hosts:       []string{host},
+		Hosts:       withAdditionalDomains(host, adminer.Spec.AdditionalDomains),
 		ServiceName: adminer.Name,
-		ServicePort: 8080,
+		ServicePort: r.getAppPort(),
 		Protocol:    istio.ProtocolHTTP,
 
 		// 数据库管理器专用配置
@@ -554,7 +668,7 @@ func (r *AdminerReconciler) syncOptimizedIstioNetworking(ctx context.Context, ad
 		},
 
 		// 安全头部配置（设置为响应头部）
-		ResponseHeaders: r.buildSecurityHeaders(),
+		ResponseHeaders: buildSecurityHeaders(adminer.Spec.Engine, r.adminerDomain),
 
 		// TLS 配置
 		TLSEnabled: r.tlsEnabled,
@@ -587,21 +701,35 @@ func (r *AdminerReconciler) syncOptimizedIstioNetworking(ctx context.Context, ad
 	domain := protocol + host
 
 	return retryStatusUpdateOnConflict(ctx, r.Client, adminer, func() {
-		adminer.Status.Domain = domain
+		setAdminerNetworkingStatus(adminer, domain, analysis)
+	})
+}
 
-		// 🎯 添加Gateway优化状态信息
-		if adminer.Annotations == nil {
-			adminer.Annotations = make(map[string]string)
+// withAdditionalDomains 返回主域名加上额外的自定义域名（去重、忽略空值），主域名始终在第一位，
+// 由域名分类器把公共域名绑定到共享 Gateway、自定义域名绑定到应用 Gateway
+func withAdditionalDomains(host string, additional []string) []string {
+	hosts := []string{host}
+	for _, domain := range additional {
+		if domain = strings.TrimSpace(domain); domain != "" && !slices.Contains(hosts, domain) {
+			hosts = append(hosts, domain)
 		}
-		adminer.Annotations["sealos.io/gateway-type"] = "optimized"
-		adminer.Annotations["sealos.io/domain-type"] = func() string {
-			if analysis.IsPublicDomain {
-				return "public"
-			}
-			return "custom"
-		}()
-		adminer.Annotations["sealos.io/gateway-reference"] = analysis.GatewayReference
-	})
+	}
+	return hosts
+}
+
+// setAdminerNetworkingStatus 按域名分析结果写入状态中的域名和 Gateway 信息，注解保留用于向后兼容
+func setAdminerNetworkingStatus(adminer *adminerv1.Adminer, domain string, analysis *istio.DomainAnalysis) {
+	adminer.Status.Domain = domain
+	adminer.Status.GatewayReference = analysis.GatewayReference
+	adminer.Status.DomainType = analysis.DomainType()
+
+	// 🎯 添加Gateway优化状态信息
+	if adminer.Annotations == nil {
+		adminer.Annotations = make(map[string]string)
+	}
+	adminer.Annotations["sealos.io/gateway-type"] = "optimized"
+	adminer.Annotations["sealos.io/domain-type"] = analysis.DomainType()
+	adminer.Annotations["sealos.io/gateway-reference"] = analysis.GatewayReference
 }
 
 // buildCorsOrigins 构建CORS源 - 使用精确匹配的adminer子域名
@@ -653,30 +781,6 @@ func (r *AdminerReconciler) buildCorsOrigins() []string {
 	return uniqueOrigins
 }
 
-// buildSecurityHeaders 构建安全响应头部
-func (r *AdminerReconciler) buildSecurityHeaders() map[string]string {
-	headers := make(map[string]string)
-
-	// 设置 X-Frame-Options，允许 iframe 嵌入（响应头部）
-	headers["X-Frame-Options"] = ""
-
-	// 设置 Content Security Policy（响应头部）
-	cspValue := fmt.Sprintf("default-src * blob: data: *.%s %s; img-src * data: blob: resource: *.%s %s; connect-src * wss: blob: resource:; style-src 'self' 'unsafe-inline' blob: *.%s %s resource:; script-src 'self' 'unsafe-inline' 'unsafe-eval' blob: *.%s %s resource: *.baidu.com *.bdstatic.com; frame-src 'self' %s *.%s mailto: tel: weixin: mtt: *.baidu.com; frame-ancestors 'self' https://%s https://*.%s",
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain)
-
-	headers["Content-Security-Policy"] = cspValue
-
-	// 设置 XSS 保护（响应头部）
-	headers["X-Xss-Protection"] = "1; mode=block"
-
-	return headers
-}
-
 func (r *AdminerReconciler) syncNginxIngress(ctx context.Context, adminer *adminerv1.Adminer, host string, recLabels map[string]string) error {
 	ingress := &networkingv1.Ingress{
 		ObjectMeta: metav1.ObjectMeta{
@@ -716,15 +820,23 @@ func (r *AdminerReconciler) fillDefaultValue(ctx context.Context, adminer *admin
 			if adminer.ObjectMeta.Annotations == nil {
 				adminer.ObjectMeta.Annotations = make(map[string]string)
 			}
-			adminer.ObjectMeta.Annotations[KeepaliveAnnotation] = time.Now().Format(time.RFC3339)
+			adminer.ObjectMeta.Annotations[KeepaliveAnnotation] = r.now().Format(time.RFC3339)
 		})
 	}
 
 	return nil
 }
 
-// isExpired return true if the adminer has expired
-func isExpired(adminer *adminerv1.Adminer) bool {
+// now returns the current time of the reconciler's clock
+func (r *AdminerReconciler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+// isExpired return true if the adminer has expired at now
+func isExpired(adminer *adminerv1.Adminer, now time.Time) bool {
 	anno := adminer.ObjectMeta.Annotations
 	lastUpdateTime, err := time.Parse(time.RFC3339, anno[KeepaliveAnnotation])
 	if err != nil {
@@ -733,7 +845,7 @@ func isExpired(adminer *adminerv1.Adminer) bool {
 	}
 
 	duration, _ := time.ParseDuration(adminer.Spec.Keepalived)
-	return lastUpdateTime.Add(duration).Before(time.Now())
+	return lastUpdateTime.Add(duration).Before(now)
 }
 
 func getDomain() string {
@@ -776,8 +888,71 @@ func getSecretNamespace() string {
 	return secretNamespace
 }
 
+func getHostnameAlphabet() string {
+	alphabet := os.Getenv("HOSTNAME_ALPHABET")
+	if alphabet == "" {
+		return LetterBytes
+	}
+	return alphabet
+}
+
+func getHostnameLength() (int, error) {
+	length := os.Getenv("HOSTNAME_LENGTH")
+	if length == "" {
+		return HostnameLength, nil
+	}
+	n, err := strconv.Atoi(length)
+	if err != nil {
+		return 0, fmt.Errorf("invalid HOSTNAME_LENGTH %q: %w", length, err)
+	}
+	return n, nil
+}
+
+// getAppPortEnv reads the adminer container port from APP_PORT, defaults to DefaultAppPort
+func getAppPortEnv() (int32, error) {
+	port := os.Getenv("APP_PORT")
+	if port == "" {
+		return DefaultAppPort, nil
+	}
+	n, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid APP_PORT %q: %w", port, err)
+	}
+	return int32(n), nil
+}
+
+// getMaxInstancesPerNamespaceEnv reads the per-namespace adminer limit from MAX_INSTANCES_PER_NAMESPACE, defaults to no limit
+func getMaxInstancesPerNamespaceEnv() (int, error) {
+	limit := os.Getenv("MAX_INSTANCES_PER_NAMESPACE")
+	if limit == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid MAX_INSTANCES_PER_NAMESPACE %q: %w", limit, err)
+	}
+	return n, nil
+}
+
+// validateAppPort checks the configured app port is a valid TCP port
+func validateAppPort(port int32) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid app port %d: must be between 1 and 65535", port)
+	}
+	return nil
+}
+
+// getAppPort returns the configured port of the adminer container, the Service and the networking
+func (r *AdminerReconciler) getAppPort() int32 {
+	if r.appPort > 0 {
+		return r.appPort
+	}
+	return DefaultAppPort
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *AdminerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.apiReader = mgr.GetAPIReader()
 	r.recorder = mgr.GetEventRecorderFor("sealos-db-adminer-controller")
 	r.adminerDomain = getDomain()
 	r.tlsEnabled = getTLSEnabled()
@@ -786,6 +961,31 @@ func (r *AdminerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.secretNamespace = getSecretNamespace()
 	r.Config = mgr.GetConfig()
 
+	hostnameLength, err := getHostnameLength()
+	if err != nil {
+		return err
+	}
+	r.hostnameAlphabet, r.hostnameLength = getHostnameAlphabet(), hostnameLength
+	if err := validateHostnameSettings(r.hostnameAlphabet, r.hostnameLength); err != nil {
+		return err
+	}
+	appPort, err := getAppPortEnv()
+	if err != nil {
+		return err
+	}
+	if err := validateAppPort(appPort); err != nil {
+		return err
+	}
+	r.appPort = appPort
+	maxInstances, err := getMaxInstancesPerNamespaceEnv()
+	if err != nil {
+		return err
+	}
+	if err := validateMaxInstancesPerNamespace(maxInstances); err != nil {
+		return err
+	}
+	r.maxInstancesPerNamespace = maxInstances
+
 	// 初始化 Istio 支持
 	ctx := context.Background()
 	if err := r.SetupIstioSupport(ctx); err != nil {
@@ -799,25 +999,113 @@ func (r *AdminerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	// 如果启用了 Istio，添加对 Istio 资源的监听
 	if r.useIstio {
-		// 使用 unstructured 类型来监听 Istio CRDs
-		virtualServiceType := &unstructured.Unstructured{}
-		virtualServiceType.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "networking.istio.io",
-			Version: "v1beta1",
-			Kind:    "VirtualService",
-		})
+		for _, obj := range istioWatchTypes() {
+			controllerBuilder = controllerBuilder.Owns(obj)
+		}
+	}
 
-		gatewayType := &unstructured.Unstructured{}
-		gatewayType.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "networking.istio.io",
-			Version: "v1beta1",
-			Kind:    "Gateway",
-		})
+	c, err := controllerBuilder.Build(r)
+	if err != nil {
+		return err
+	}
+
+	// 清理旧版本遗留的、缺少 OwnerReference 的孤立 Service
+	if err := mgr.Add(istio.NewOrphanServiceSweeper(r.Client, adminerv1.GroupVersion.WithKind("Adminer"), AdminerPartOf, istio.DefaultOrphanServiceSweepInterval)); err != nil {
+		return err
+	}
 
-		controllerBuilder = controllerBuilder.
-			Owns(virtualServiceType).
-			Owns(gatewayType)
+	// 同步网关的 X-Forwarded-For 可信跳数，让 Adminer 拿到真实客户端地址
+	if os.Getenv("USE_ISTIO") == "true" {
+		if err := mgr.Add(istio.NewTrustedHopsEnvoyFilterSyncer(r.Client, r.buildIstioNetworkConfig(), istio.DefaultTrustedHopsResyncInterval)); err != nil {
+			return err
+		}
+		// 修复被误配置为较窄主机列表的共享 Gateway，避免使用公共域名的 Adminer 返回 404
+		if err := mgr.Add(istio.NewSharedGatewayHostsSyncer(r.Client, r.buildIstioNetworkConfig(), istio.DefaultSharedGatewayHostsResyncInterval)); err != nil {
+			return err
+		}
 	}
 
-	return controllerBuilder.Complete(r)
+	// 启动时 Istio CRD 可能尚未安装完成，回退到 Ingress 后周期性检查，CRD 就绪后切换到 Istio 模式
+	if os.Getenv("USE_ISTIO") == "true" && !r.useIstio {
+		return mgr.Add(istio.NewModeReevaluator(r.Client, istio.DefaultModeReevaluateInterval, func(ctx context.Context) (bool, error) {
+			if err := r.EnableIstioMode(ctx); err != nil || !r.IsIstioEnabled() {
+				return false, err
+			}
+			if err := r.watchIstioResources(mgr, c); err != nil {
+				return true, err
+			}
+			// 已按 Ingress 模式协调过的 Adminer 重新入队，补建 Istio 网络资源
+			return true, c.Watch(istio.EnqueueExisting(mgr.GetClient(), &adminerv1.AdminerList{}))
+		}))
+	}
+	return nil
+}
+
+// istioWatchTypes 返回 Adminer 拥有的 Istio 资源类型（使用 unstructured 类型来监听 Istio CRDs）
+func istioWatchTypes() []client.Object {
+	virtualServiceType := &unstructured.Unstructured{}
+	virtualServiceType.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    "VirtualService",
+	})
+
+	gatewayType := &unstructured.Unstructured{}
+	gatewayType.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    "Gateway",
+	})
+
+	return []client.Object{virtualServiceType, gatewayType}
+}
+
+// watchIstioResources 在运行时切换到 Istio 模式后补充对 Istio 资源的监听，等同于 Owns
+func (r *AdminerReconciler) watchIstioResources(mgr ctrl.Manager, c controller.Controller) error {
+	for _, obj := range istioWatchTypes() {
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &adminerv1.Adminer{}, handler.OnlyControllerOwner()))); err != nil {
+			return fmt.Errorf("watch %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+	}
+	return nil
+}
+
+// validateHostnameSettings checks the configured nanoid alphabet and length of generated hostnames,
+// the alphabet must only contain lower case letters and digits so that the hostname stays a valid DNS label.
+func validateHostnameSettings(alphabet string, length int) error {
+	if length < MinHostnameLength || length > MaxHostnameLength {
+		return fmt.Errorf("invalid hostname length %d: must be between %d and %d", length, MinHostnameLength, MaxHostnameLength)
+	}
+	if alphabet == "" {
+		return fmt.Errorf("hostname alphabet cannot be empty")
+	}
+	for _, c := range alphabet {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid hostname alphabet %q: must only contain lower case letters and digits", alphabet)
+		}
+	}
+	return nil
+}
+
+// generateHostname builds the pod hostname from the prefix and a random nanoid,
+// the prefix defaults to DefaultHostnamePrefix, alphabet and length default to LetterBytes and HostnameLength.
+func generateHostname(prefix, alphabet string, length int) (string, error) {
+	if prefix == "" {
+		prefix = DefaultHostnamePrefix
+	}
+	if alphabet == "" {
+		alphabet = LetterBytes
+	}
+	if length == 0 {
+		length = HostnameLength
+	}
+	if !hostnamePrefixRegex.MatchString(prefix) || len(prefix)+length > 63 {
+		return "", fmt.Errorf("invalid hostname prefix %q: must start with a lower case letter, contain only lower case letters, digits or '-' and be at most %d characters", prefix, 63-length)
+	}
+	letterID, err := nanoid.Generate(alphabet, length)
+	if err != nil {
+		return "", err
+	}
+	return prefix + letterID, nil
 }
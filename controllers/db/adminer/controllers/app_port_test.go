@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestGetAppPortEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		wantPort int32
+		wantErr  bool
+	}{
+		{name: "unset uses default", wantPort: DefaultAppPort},
+		{name: "custom port", env: "3000", wantPort: 3000},
+		{name: "not a number", env: "http", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_PORT", tt.env)
+			port, err := getAppPortEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getAppPortEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && port != tt.wantPort {
+				t.Errorf("getAppPortEnv() = %d, want %d", port, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestValidateAppPort(t *testing.T) {
+	tests := []struct {
+		port    int32
+		wantErr bool
+	}{
+		{port: 8080},
+		{port: 1},
+		{port: 65535},
+		{port: 0, wantErr: true},
+		{port: 65536, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateAppPort(tt.port); (err != nil) != tt.wantErr {
+			t.Errorf("validateAppPort(%d) error = %v, wantErr %v", tt.port, err, tt.wantErr)
+		}
+	}
+}
+
+// TestAppPort_Consistent checks a custom app port reaches the container, the probes, the Service and the networking
+func TestAppPort_Consistent(t *testing.T) {
+	const port int32 = 3000
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := adminerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add adminer scheme: %v", err)
+	}
+	adminer := &adminerv1.Adminer{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test", UID: "uid-1"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(adminer).WithStatusSubresource(adminer).Build()
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	r := &AdminerReconciler{
+		Client:           c,
+		Scheme:           scheme,
+		adminerDomain:    "cloud.sealos.io",
+		tlsEnabled:       true,
+		image:            DefaultImage,
+		useIstio:         true,
+		istioHelper:      istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, istioConfig, "adminer"),
+		istioReconciler:  NewAdminerIstioNetworkingReconciler(c, istioConfig, true, "cloud.sealos.io"),
+		hostnameAlphabet: LetterBytes,
+		hostnameLength:   HostnameLength,
+		appPort:          port,
+	}
+	r.istioReconciler.servicePort = r.getAppPort()
+	ctx := context.Background()
+	recLabels := map[string]string{"app.kubernetes.io/name": adminer.Name}
+
+	var hostname string
+	if err := r.syncDeployment(ctx, adminer, &hostname, recLabels); err != nil {
+		t.Fatalf("syncDeployment() error = %v", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Name: adminer.Name, Namespace: adminer.Namespace}, deployment); err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if got := container.Ports[0].ContainerPort; got != port {
+		t.Errorf("container port = %d, want %d", got, port)
+	}
+	if got := container.StartupProbe.HTTPGet.Port.IntVal; got != port {
+		t.Errorf("startup probe port = %d, want %d", got, port)
+	}
+	if got := container.ReadinessProbe.HTTPGet.Port.IntVal; got != port {
+		t.Errorf("readiness probe port = %d, want %d", got, port)
+	}
+
+	if err := r.syncService(ctx, adminer, recLabels); err != nil {
+		t.Fatalf("syncService() error = %v", err)
+	}
+	service := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKey{Name: adminer.Name, Namespace: adminer.Namespace}, service); err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if got := service.Spec.Ports[0]; got.Port != port || got.TargetPort.IntVal != port {
+		t.Errorf("service port = %d -> %s, want %d -> %d", got.Port, got.TargetPort.String(), port, port)
+	}
+
+	if err := r.syncNetworking(ctx, adminer, hostname, recLabels); err != nil {
+		t.Fatalf("syncNetworking() error = %v", err)
+	}
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	if err := c.Get(ctx, client.ObjectKey{Name: adminer.Name + "-vs", Namespace: adminer.Namespace}, vs); err != nil {
+		t.Fatalf("get virtualservice: %v", err)
+	}
+	httpRoutes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if len(httpRoutes) == 0 {
+		t.Fatalf("virtualservice has no http routes")
+	}
+	routes, _, _ := unstructured.NestedSlice(httpRoutes[0].(map[string]interface{}), "route")
+	if len(routes) == 0 {
+		t.Fatalf("virtualservice http route has no destinations")
+	}
+	if got, _, _ := unstructured.NestedInt64(routes[0].(map[string]interface{}), "destination", "port", "number"); got != int64(port) {
+		t.Errorf("virtualservice destination port = %d, want %d", got, port)
+	}
+
+	if got := r.istioReconciler.buildNetworkingSpec(adminer, hostname).ServicePort; got != port {
+		t.Errorf("networking spec service port = %d, want %d", got, port)
+	}
+	if got := r.createNginxIngress(adminer, "test.cloud.sealos.io").Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number; got != port {
+		t.Errorf("ingress backend port = %d, want %d", got, port)
+	}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestSyncNetworking_RequiresBackingService(t *testing.T) {
+	tests := []struct {
+		name        string
+		withSvc     bool
+		wantRequeue bool
+	}{
+		{name: "service present", withSvc: true},
+		{name: "service absent", wantRequeue: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := clientgoscheme.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add client-go scheme: %v", err)
+			}
+			if err := adminerv1.AddToScheme(scheme); err != nil {
+				t.Fatalf("failed to add adminer scheme: %v", err)
+			}
+			adminer := &adminerv1.Adminer{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test", UID: "uid-1"},
+			}
+			objects := []client.Object{adminer}
+			if tt.withSvc {
+				objects = append(objects, &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test"},
+					Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+				})
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(adminer).Build()
+			istioConfig := &istio.NetworkConfig{
+				BaseDomain:           "cloud.sealos.io",
+				DefaultGateway:       "istio-system/sealos-gateway",
+				SharedGatewayEnabled: true,
+				PublicDomains:        []string{"cloud.sealos.io"},
+				PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+			}
+			r := &AdminerReconciler{
+				Client:          c,
+				Scheme:          scheme,
+				adminerDomain:   "cloud.sealos.io",
+				tlsEnabled:      true,
+				useIstio:        true,
+				istioHelper:     istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, istioConfig, "adminer"),
+				istioReconciler: NewAdminerIstioNetworkingReconciler(c, istioConfig, true, "cloud.sealos.io"),
+			}
+			ctx := context.Background()
+
+			err := r.syncNetworking(ctx, adminer, "abcdef", map[string]string{"app.kubernetes.io/name": adminer.Name})
+			if got := istio.IsBackingServiceNotReady(err); got != tt.wantRequeue {
+				t.Fatalf("syncNetworking() error = %v, want requeue %v", err, tt.wantRequeue)
+			}
+			if !tt.wantRequeue && err != nil {
+				t.Fatalf("syncNetworking() error = %v", err)
+			}
+
+			vs := &unstructured.Unstructured{}
+			vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+			err = c.Get(ctx, client.ObjectKey{Name: adminer.Name + "-vs", Namespace: adminer.Namespace}, vs)
+			if tt.wantRequeue && !errors.IsNotFound(err) {
+				t.Errorf("virtualservice should not be created without the backing service, get error = %v", err)
+			}
+			if !tt.wantRequeue && err != nil {
+				t.Errorf("virtualservice should be created: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+)
+
+// CSP 模板，%[1]s 为 adminer 域名
+const (
+	// defaultCSPTemplate 通用 adminer（MySQL/PostgreSQL 等）
+	defaultCSPTemplate = "default-src * blob: data: *.%[1]s %[1]s; img-src * data: blob: resource: *.%[1]s %[1]s; connect-src * wss: blob: resource:; style-src 'self' 'unsafe-inline' blob: *.%[1]s %[1]s resource:; script-src 'self' 'unsafe-inline' 'unsafe-eval' blob: *.%[1]s %[1]s resource: *.baidu.com *.bdstatic.com; frame-src 'self' %[1]s *.%[1]s mailto: tel: weixin: mtt: *.baidu.com; frame-ancestors 'self' https://%[1]s https://*.%[1]s"
+
+	// mongoCSPTemplate MongoDB Web UI 在 worker 中执行 shell 脚本，不加载第三方统计脚本
+	mongoCSPTemplate = "default-src 'self' blob: data: *.%[1]s %[1]s; img-src * data: blob:; connect-src 'self' wss: blob: *.%[1]s %[1]s; style-src 'self' 'unsafe-inline' blob: *.%[1]s %[1]s; script-src 'self' 'unsafe-inline' 'unsafe-eval' blob: *.%[1]s %[1]s; worker-src 'self' blob:; frame-src 'self'; frame-ancestors 'self' https://%[1]s https://*.%[1]s"
+
+	// redisCSPTemplate Redis Web UI 通过 websocket 推送实时监控数据
+	redisCSPTemplate = "default-src 'self' blob: data: *.%[1]s %[1]s; img-src * data: blob:; connect-src 'self' ws: wss: *.%[1]s %[1]s; style-src 'self' 'unsafe-inline' *.%[1]s %[1]s; script-src 'self' 'unsafe-inline' *.%[1]s %[1]s; frame-src 'self'; frame-ancestors 'self' https://%[1]s https://*.%[1]s"
+)
+
+// engineCSPTemplates 按数据库引擎选择 CSP 模板，未列出的引擎使用 defaultCSPTemplate
+var engineCSPTemplates = map[adminerv1.DatabaseEngine]string{
+	adminerv1.MongoDB: mongoCSPTemplate,
+	adminerv1.Redis:   redisCSPTemplate,
+}
+
+// buildCSPValue 根据 adminer 目标引擎和域名生成 Content-Security-Policy
+func buildCSPValue(engine adminerv1.DatabaseEngine, domain string) string {
+	template, ok := engineCSPTemplates[engine]
+	if !ok {
+		template = defaultCSPTemplate
+	}
+	return fmt.Sprintf(template, domain)
+}
+
+// buildSecurityHeaders 构建安全响应头部
+func buildSecurityHeaders(engine adminerv1.DatabaseEngine, domain string) map[string]string {
+	return map[string]string{
+		// 空值表示移除 adminer 返回的 X-Frame-Options，允许 iframe 嵌入
+		clearXFrameHeader: "",
+		defaultCSPHeader:  buildCSPValue(engine, domain),
+		defaultXSSHeader:  defaultXSSValue,
+	}
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+	"github.com/labring/sealos/controllers/pkg/istio"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// legacyCSPValue 引入按引擎区分之前的 CSP，默认模板必须与其保持一致
+const legacyCSPValue = "default-src * blob: data: *.cloud.sealos.io cloud.sealos.io; img-src * data: blob: resource: *.cloud.sealos.io cloud.sealos.io; connect-src * wss: blob: resource:; style-src 'self' 'unsafe-inline' blob: *.cloud.sealos.io cloud.sealos.io resource:; script-src 'self' 'unsafe-inline' 'unsafe-eval' blob: *.cloud.sealos.io cloud.sealos.io resource: *.baidu.com *.bdstatic.com; frame-src 'self' cloud.sealos.io *.cloud.sealos.io mailto: tel: weixin: mtt: *.baidu.com; frame-ancestors 'self' https://cloud.sealos.io https://*.cloud.sealos.io"
+
+func TestBuildCSPValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine adminerv1.DatabaseEngine
+		want   string
+	}{
+		{name: "default", engine: "", want: legacyCSPValue},
+		{name: "mysql uses default", engine: adminerv1.MySQL, want: legacyCSPValue},
+		{name: "mongodb", engine: adminerv1.MongoDB, want: strings.ReplaceAll(mongoCSPTemplate, "%[1]s", "cloud.sealos.io")},
+		{name: "redis", engine: adminerv1.Redis, want: strings.ReplaceAll(redisCSPTemplate, "%[1]s", "cloud.sealos.io")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildCSPValue(tt.engine, "cloud.sealos.io"); got != tt.want {
+				t.Errorf("buildCSPValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineSpecificSecurityHeaders(t *testing.T) {
+	newAdminer := func(engine adminerv1.DatabaseEngine) *adminerv1.Adminer {
+		return &adminerv1.Adminer{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test"},
+			Spec:       adminerv1.AdminerSpec{Engine: engine},
+		}
+	}
+	mongoCSP := buildCSPValue(adminerv1.MongoDB, "example.com")
+	defaultCSP := buildCSPValue("", "example.com")
+	if mongoCSP == defaultCSP {
+		t.Fatal("mongodb CSP should differ from the default CSP")
+	}
+
+	istioReconciler := &AdminerIstioNetworkingReconciler{
+		config:        &istio.NetworkConfig{BaseDomain: "example.com"},
+		adminerDomain: "example.com",
+	}
+	adminerReconciler := &AdminerReconciler{adminerDomain: "example.com"}
+
+	for engine, wantCSP := range map[adminerv1.DatabaseEngine]string{adminerv1.MongoDB: mongoCSP, "": defaultCSP} {
+		adminer := newAdminer(engine)
+
+		spec := istioReconciler.buildNetworkingSpec(adminer, "test-host")
+		if got := spec.ResponseHeaders["Content-Security-Policy"]; got != wantCSP {
+			t.Errorf("engine %q: istio CSP = %q, want %q", engine, got, wantCSP)
+		}
+
+		ingress := adminerReconciler.createNginxIngress(adminer, "test-host.example.com")
+		if snippet := ingress.Annotations["nginx.ingress.kubernetes.io/configuration-snippet"]; !strings.Contains(snippet, wantCSP) {
+			t.Errorf("engine %q: nginx snippet %q does not contain CSP %q", engine, snippet, wantCSP)
+		}
+		if update := ingress.Annotations["higress.io/response-header-control-update"]; !strings.Contains(update, wantCSP) {
+			t.Errorf("engine %q: higress annotation %q does not contain CSP %q", engine, update, wantCSP)
+		}
+	}
+}
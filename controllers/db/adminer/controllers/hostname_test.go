@@ -0,0 +1,123 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestGenerateHostname(t *testing.T) {
+	tests := []struct {
+		name       string
+		prefix     string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{name: "default prefix", prefix: "", wantPrefix: DefaultHostnamePrefix},
+		{name: "username prefix", prefix: "alice-", wantPrefix: "alice-"},
+		{name: "starts with digit", prefix: "1alice", wantErr: true},
+		{name: "upper case", prefix: "Alice", wantErr: true},
+		{name: "contains dot", prefix: "alice.dev", wantErr: true},
+		{name: "too long", prefix: "a" + strings.Repeat("b", 63-HostnameLength), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hostname, err := generateHostname(tt.prefix, "", 0)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("generateHostname(%q) = %q, want error", tt.prefix, hostname)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("generateHostname(%q) error = %v", tt.prefix, err)
+			}
+			if !strings.HasPrefix(hostname, tt.wantPrefix) {
+				t.Errorf("hostname %q should start with %q", hostname, tt.wantPrefix)
+			}
+			if len(hostname) != len(tt.wantPrefix)+HostnameLength {
+				t.Errorf("hostname %q should be the prefix followed by a %d character nanoid", hostname, HostnameLength)
+			}
+		})
+	}
+}
+
+func TestGenerateHostname_CustomSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		alphabet string
+		length   int
+	}{
+		{name: "shortest", alphabet: "abc", length: MinHostnameLength},
+		{name: "longest", alphabet: LetterBytes, length: MaxHostnameLength},
+		{name: "digits only", prefix: "alice-", alphabet: "0123456789", length: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateHostnameSettings(tt.alphabet, tt.length); err != nil {
+				t.Fatalf("validateHostnameSettings() error = %v", err)
+			}
+			for i := 0; i < 20; i++ {
+				hostname, err := generateHostname(tt.prefix, tt.alphabet, tt.length)
+				if err != nil {
+					t.Fatalf("generateHostname() error = %v", err)
+				}
+				if errs := validation.IsDNS1123Label(hostname); len(errs) > 0 {
+					t.Fatalf("hostname %q is not a valid DNS label: %v", hostname, errs)
+				}
+				id := strings.TrimPrefix(hostname, tt.prefix)
+				if tt.prefix == "" {
+					id = strings.TrimPrefix(hostname, DefaultHostnamePrefix)
+				}
+				if len(id) != tt.length || strings.Trim(id, tt.alphabet) != "" {
+					t.Fatalf("nanoid %q should be %d characters from %q", id, tt.length, tt.alphabet)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateHostnameSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet string
+		length   int
+		wantErr  bool
+	}{
+		{name: "defaults", alphabet: LetterBytes, length: HostnameLength},
+		{name: "too short", alphabet: LetterBytes, length: MinHostnameLength - 1, wantErr: true},
+		{name: "too long", alphabet: LetterBytes, length: MaxHostnameLength + 1, wantErr: true},
+		{name: "empty alphabet", alphabet: "", length: HostnameLength, wantErr: true},
+		{name: "upper case alphabet", alphabet: "ABCdef", length: HostnameLength, wantErr: true},
+		{name: "dash in alphabet", alphabet: "abc-", length: HostnameLength, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHostnameSettings(tt.alphabet, tt.length)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHostnameSettings(%q, %d) error = %v, wantErr %v", tt.alphabet, tt.length, err, tt.wantErr)
+			}
+		})
+	}
+}
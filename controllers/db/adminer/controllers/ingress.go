@@ -18,7 +18,6 @@ package controllers
 
 import (
 	"fmt"
-	"strings"
 
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,11 +42,11 @@ func (r *AdminerReconciler) createNginxIngress(adminer *adminerv1.Adminer, host
 		"nginx.ingress.kubernetes.io/cors-allow-origin":      cors,
 		"nginx.ingress.kubernetes.io/cors-allow-methods":     "PUT, GET, POST, PATCH, OPTIONS",
 		"nginx.ingress.kubernetes.io/cors-allow-credentials": "false",
-		"nginx.ingress.kubernetes.io/configuration-snippet":  r.getNginxConfigurationSnippet(),
+		"nginx.ingress.kubernetes.io/configuration-snippet":  r.getNginxConfigurationSnippet(adminer),
 	}
 
 	// add higress annotations support
-	higressAnno := r.getHigressAnnotations()
+	higressAnno := r.getHigressAnnotations(adminer)
 	for k, v := range higressAnno {
 		annotations[k] = v
 	}
@@ -66,7 +65,7 @@ func (r *AdminerReconciler) createNginxIngress(adminer *adminerv1.Adminer, host
 			Service: &networkingv1.IngressServiceBackend{
 				Name: adminer.Name,
 				Port: networkingv1.ServiceBackendPort{
-					Number: 8080,
+					Number: r.getAppPort(),
 				},
 			},
 		},
@@ -101,45 +100,24 @@ func (r *AdminerReconciler) createNginxIngress(adminer *adminerv1.Adminer, host
 const (
 	clearXFrameHeader = "X-Frame-Options"
 	defaultCSPHeader  = "Content-Security-Policy"
-	defaultCSPValue   = "default-src * blob: data: *.cloud.sealos.io cloud.sealos.io; img-src * data: blob: resource: *.cloud.sealos.io cloud.sealos.io; connect-src * wss: blob: resource:; style-src 'self' 'unsafe-inline' blob: *.cloud.sealos.io cloud.sealos.io resource:; script-src 'self' 'unsafe-inline' 'unsafe-eval' blob: *.cloud.sealos.io cloud.sealos.io resource: *.baidu.com *.bdstatic.com; frame-src 'self' cloud.sealos.io *.cloud.sealos.io mailto: tel: weixin: mtt: *.baidu.com; frame-ancestors 'self' https://cloud.sealos.io https://*.cloud.sealos.io"
 	defaultXSSHeader  = "X-Xss-Protection"
 	defaultXSSValue   = "1; mode=block"
-
-	defaultConfigDomain = "cloud.sealos.io"
 )
 
-var (
-	defaultNginxConfigurationSnippet = fmt.Sprintf(`
+func (r *AdminerReconciler) getNginxConfigurationSnippet(adminer *adminerv1.Adminer) string {
+	return fmt.Sprintf(`
 more_clear_headers "%s:";
 more_set_headers "%s: %s";
 more_set_headers "%s: %s";
-`, clearXFrameHeader, defaultCSPHeader, defaultCSPValue, defaultXSSHeader, defaultXSSValue)
-	defaultHigressAnnotations = map[string]string{
+`, clearXFrameHeader, defaultCSPHeader, buildCSPValue(adminer.Spec.Engine, r.adminerDomain), defaultXSSHeader, defaultXSSValue)
+}
+
+func (r *AdminerReconciler) getHigressAnnotations(adminer *adminerv1.Adminer) map[string]string {
+	return map[string]string{
 		"higress.io/response-header-control-remove": clearXFrameHeader,
 		"higress.io/response-header-control-update": fmt.Sprintf(`
 %s "%s"
 %s "%s"
-`, defaultCSPHeader, defaultCSPValue, defaultXSSHeader, defaultXSSValue),
+`, defaultCSPHeader, buildCSPValue(adminer.Spec.Engine, r.adminerDomain), defaultXSSHeader, defaultXSSValue),
 	}
-)
-
-func (r *AdminerReconciler) getNginxConfigurationSnippet() string {
-	if defaultConfigDomain != r.adminerDomain {
-		return strings.ReplaceAll(defaultNginxConfigurationSnippet, defaultConfigDomain, r.adminerDomain)
-	}
-
-	return defaultNginxConfigurationSnippet
-}
-
-func (r *AdminerReconciler) getHigressAnnotations() map[string]string {
-	if defaultConfigDomain != r.adminerDomain {
-		ret := map[string]string{}
-		for k, v := range defaultHigressAnnotations {
-			ret[k] = strings.ReplaceAll(v, defaultConfigDomain, r.adminerDomain)
-		}
-
-		return ret
-	}
-
-	return defaultHigressAnnotations
 }
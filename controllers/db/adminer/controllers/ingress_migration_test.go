@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestSyncNetworking_SwitchIngressToIstio(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := adminerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add adminer scheme: %v", err)
+	}
+	adminer := &adminerv1.Adminer{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test", UID: "uid-1"},
+		Spec:       adminerv1.AdminerSpec{IngressType: adminerv1.Nginx},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(adminer, svc).WithStatusSubresource(adminer).Build()
+	r := &AdminerReconciler{
+		Client:        c,
+		Scheme:        scheme,
+		adminerDomain: "cloud.sealos.io",
+		tlsEnabled:    true,
+	}
+	ctx := context.Background()
+	recLabels := map[string]string{"app.kubernetes.io/name": adminer.Name}
+
+	// Ingress 模式下创建的应用
+	if err := r.syncNetworking(ctx, adminer, "abcdef", recLabels); err != nil {
+		t.Fatalf("syncNetworking() in ingress mode error = %v", err)
+	}
+	ingressKey := client.ObjectKey{Name: adminer.Name, Namespace: adminer.Namespace}
+	if err := c.Get(ctx, ingressKey, &networkingv1.Ingress{}); err != nil {
+		t.Fatalf("ingress should be created in ingress mode: %v", err)
+	}
+	wantDomain := adminer.Status.Domain
+	if wantDomain != "https://abcdef.cloud.sealos.io" {
+		t.Fatalf("domain in ingress mode = %s", wantDomain)
+	}
+
+	// 集群启用 Istio 后原地切换
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	r.useIstio = true
+	r.istioHelper = istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, istioConfig, "adminer")
+	r.istioReconciler = NewAdminerIstioNetworkingReconciler(c, istioConfig, r.tlsEnabled, r.adminerDomain)
+	if err := r.syncNetworking(ctx, adminer, "abcdef", recLabels); err != nil {
+		t.Fatalf("syncNetworking() in istio mode error = %v", err)
+	}
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	if err := c.Get(ctx, client.ObjectKey{Name: adminer.Name + "-vs", Namespace: adminer.Namespace}, vs); err != nil {
+		t.Fatalf("virtualservice should be created after switching to istio: %v", err)
+	}
+	if hosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts"); len(hosts) != 1 || hosts[0] != "abcdef.cloud.sealos.io" {
+		t.Errorf("virtualservice hosts = %v, want the ingress hostname", hosts)
+	}
+	if err := c.Get(ctx, ingressKey, &networkingv1.Ingress{}); !errors.IsNotFound(err) {
+		t.Errorf("legacy ingress should be removed, get error = %v", err)
+	}
+	if adminer.Status.Domain != wantDomain {
+		t.Errorf("domain = %s, want unchanged %s", adminer.Status.Domain, wantDomain)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+)
+
+const (
+	// ConditionTypeAdmitted reports whether the adminer was admitted under the per-namespace instance limit
+	ConditionTypeAdmitted = "Admitted"
+	// ReasonWithinInstanceLimit means the adminer is admitted and its resources are created
+	ReasonWithinInstanceLimit = "WithinInstanceLimit"
+	// ReasonInstanceLimitExceeded means older adminers already fill the namespace limit, nothing is created
+	ReasonInstanceLimitExceeded = "InstanceLimitExceeded"
+
+	// instanceLimitRequeueInterval is how often a refused adminer is rechecked, so it starts once older ones are deleted
+	instanceLimitRequeueInterval = time.Minute
+)
+
+// admitInstance checks the adminer against the per-namespace instance limit and records the result as the
+// Admitted condition. Older adminers are admitted first and an admitted adminer stays admitted, so lowering
+// the limit never refuses adminers that are already running.
+func (r *AdminerReconciler) admitInstance(ctx context.Context, adminer *adminerv1.Adminer) (bool, error) {
+	if r.maxInstancesPerNamespace <= 0 {
+		return true, nil
+	}
+	if meta.IsStatusConditionTrue(adminer.Status.Conditions, ConditionTypeAdmitted) {
+		return true, nil
+	}
+
+	adminers := &adminerv1.AdminerList{}
+	if err := r.List(ctx, adminers, client.InNamespace(adminer.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list adminers in namespace %s: %w", adminer.Namespace, err)
+	}
+	older := 0
+	for i := range adminers.Items {
+		other := &adminers.Items[i]
+		if other.UID != adminer.UID && other.DeletionTimestamp.IsZero() && createdBefore(other, adminer) {
+			older++
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeAdmitted,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonWithinInstanceLimit,
+		Message:            fmt.Sprintf("within the limit of %d adminers per namespace", r.maxInstancesPerNamespace),
+		ObservedGeneration: adminer.Generation,
+	}
+	if older >= r.maxInstancesPerNamespace {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonInstanceLimitExceeded
+		condition.Message = fmt.Sprintf("namespace %s already has %d adminers, the limit is %d", adminer.Namespace, older, r.maxInstancesPerNamespace)
+	}
+
+	admitted := condition.Status == metav1.ConditionTrue
+	if existing := meta.FindStatusCondition(adminer.Status.Conditions, ConditionTypeAdmitted); existing != nil &&
+		existing.Status == condition.Status && existing.Message == condition.Message {
+		return admitted, nil
+	}
+
+	if !admitted {
+		r.recorder.Eventf(adminer, corev1.EventTypeWarning, ReasonInstanceLimitExceeded, "%s", condition.Message)
+	}
+	if err := retryStatusUpdateOnConflict(ctx, r.Client, adminer, func() {
+		meta.SetStatusCondition(&adminer.Status.Conditions, condition)
+	}); err != nil {
+		return false, err
+	}
+	return admitted, nil
+}
+
+// createdBefore orders adminers by creation time, falling back to the name for adminers created in the same second
+func createdBefore(a, b *adminerv1.Adminer) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// validateMaxInstancesPerNamespace checks the configured instance limit, 0 disables the limit
+func validateMaxInstancesPerNamespace(limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("MAX_INSTANCES_PER_NAMESPACE must not be negative, got %d", limit)
+	}
+	return nil
+}
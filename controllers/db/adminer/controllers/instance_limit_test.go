@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+)
+
+func newInstanceLimitTestAdminer(name, namespace string, created time.Time) *adminerv1.Adminer {
+	return &adminerv1.Adminer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               types.UID("uid-" + namespace + "-" + name),
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       map[string]string{KeepaliveAnnotation: time.Now().Format(time.RFC3339)},
+			Finalizers:        []string{FinalizerName},
+		},
+		Spec: adminerv1.AdminerSpec{
+			Connections: []string{"root:passw0rd@tcp(mysql.ns-test.svc:3306)/"},
+			Keepalived:  "1h",
+		},
+	}
+}
+
+// TestReconcile_InstanceLimit checks adminers past the per-namespace limit are refused without creating a Deployment
+func TestReconcile_InstanceLimit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := adminerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add adminer scheme: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		limit        int
+		existing     int
+		wantAdmitted bool
+	}{
+		{name: "under the limit", limit: 3, existing: 2, wantAdmitted: true},
+		{name: "at the limit", limit: 2, existing: 2},
+		{name: "no limit", existing: 5, wantAdmitted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().Truncate(time.Second)
+			objects := make([]client.Object, 0, tt.existing+2)
+			for i := 0; i < tt.existing; i++ {
+				objects = append(objects, newInstanceLimitTestAdminer(fmt.Sprintf("older-%d", i), "ns-test", now.Add(-time.Hour)))
+			}
+			// adminers in other namespaces do not count towards the limit
+			objects = append(objects, newInstanceLimitTestAdminer("other", "ns-other", now.Add(-time.Hour)))
+			adminer := newInstanceLimitTestAdminer("new", "ns-test", now)
+			objects = append(objects, adminer)
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&adminerv1.Adminer{}).Build()
+			r := &AdminerReconciler{
+				Client:                   c,
+				Scheme:                   scheme,
+				adminerDomain:            "cloud.sealos.io",
+				recorder:                 record.NewFakeRecorder(10),
+				hostnameAlphabet:         LetterBytes,
+				hostnameLength:           HostnameLength,
+				maxInstancesPerNamespace: tt.limit,
+			}
+			ctx := context.Background()
+			key := client.ObjectKeyFromObject(adminer)
+
+			result, _ := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+
+			err := c.Get(ctx, key, &appsv1.Deployment{})
+			if tt.wantAdmitted && err != nil {
+				t.Errorf("admitted adminer should get a deployment, get error = %v", err)
+			}
+			if !tt.wantAdmitted {
+				if !errors.IsNotFound(err) {
+					t.Errorf("refused adminer should not get a deployment, get error = %v", err)
+				}
+				if result.RequeueAfter != instanceLimitRequeueInterval {
+					t.Errorf("refused adminer requeue after %v, want %v", result.RequeueAfter, instanceLimitRequeueInterval)
+				}
+			}
+
+			got := &adminerv1.Adminer{}
+			if err := c.Get(ctx, key, got); err != nil {
+				t.Fatalf("get adminer: %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, ConditionTypeAdmitted)
+			if tt.limit == 0 {
+				if condition != nil {
+					t.Errorf("adminer without a limit should have no admitted condition, got %v", condition)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("adminer should have the %s condition", ConditionTypeAdmitted)
+			}
+			if admitted := condition.Status == metav1.ConditionTrue; admitted != tt.wantAdmitted {
+				t.Errorf("admitted condition = %s (%s), want admitted %v", condition.Status, condition.Message, tt.wantAdmitted)
+			}
+			if !tt.wantAdmitted && condition.Reason != ReasonInstanceLimitExceeded {
+				t.Errorf("condition reason = %s, want %s", condition.Reason, ReasonInstanceLimitExceeded)
+			}
+		})
+	}
+}
+
+// TestAdmitInstance_AdmittedStaysAdmitted checks lowering the limit does not refuse a running adminer
+func TestAdmitInstance_AdmittedStaysAdmitted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := adminerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add adminer scheme: %v", err)
+	}
+	now := time.Now()
+	adminer := newInstanceLimitTestAdminer("running", "ns-test", now)
+	meta.SetStatusCondition(&adminer.Status.Conditions, metav1.Condition{Type: ConditionTypeAdmitted, Status: metav1.ConditionTrue, Reason: ReasonWithinInstanceLimit})
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newInstanceLimitTestAdminer("older", "ns-test", now.Add(-time.Hour)),
+		adminer,
+	).WithStatusSubresource(&adminerv1.Adminer{}).Build()
+	r := &AdminerReconciler{Client: c, recorder: record.NewFakeRecorder(10), maxInstancesPerNamespace: 1}
+
+	admitted, err := r.admitInstance(context.Background(), adminer)
+	if err != nil {
+		t.Fatalf("admitInstance() error = %v", err)
+	}
+	if !admitted {
+		t.Errorf("admitInstance() = false, want an admitted adminer to stay admitted")
+	}
+}
+
+func TestGetMaxInstancesPerNamespaceEnv(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       string
+		wantLimit int
+		wantErr   bool
+	}{
+		{name: "unset disables the limit"},
+		{name: "custom limit", env: "20", wantLimit: 20},
+		{name: "not a number", env: "many", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MAX_INSTANCES_PER_NAMESPACE", tt.env)
+			limit, err := getMaxInstancesPerNamespaceEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getMaxInstancesPerNamespaceEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && limit != tt.wantLimit {
+				t.Errorf("getMaxInstancesPerNamespaceEnv() = %d, want %d", limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestValidateMaxInstancesPerNamespace(t *testing.T) {
+	for limit, wantErr := range map[int]bool{0: false, 10: false, -1: true} {
+		if err := validateMaxInstancesPerNamespace(limit); (err != nil) != wantErr {
+			t.Errorf("validateMaxInstancesPerNamespace(%d) error = %v, wantErr %v", limit, err, wantErr)
+		}
+	}
+}
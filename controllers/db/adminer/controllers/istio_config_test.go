@@ -0,0 +1,129 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestBuildIstioNetworkConfig_OptionsEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(t *testing.T, config *istio.NetworkConfig)
+	}{
+		{
+			name: "dns validation enabled by default",
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if config.SkipDNSValidation || len(config.DNSValidationSkipSuffixes) != 0 {
+					t.Errorf("SkipDNSValidation = %v, DNSValidationSkipSuffixes = %v, want defaults", config.SkipDNSValidation, config.DNSValidationSkipSuffixes)
+				}
+			},
+		},
+		{
+			name: "skip dns validation",
+			env: map[string]string{
+				"ISTIO_SKIP_DNS_VALIDATION": "true",
+				"ISTIO_DNS_SKIP_SUFFIXES":   ".internal, ,.svc.cluster.local",
+			},
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if !config.SkipDNSValidation {
+					t.Error("SkipDNSValidation = false, want true")
+				}
+				if want := []string{".internal", ".svc.cluster.local"}; !reflect.DeepEqual(config.DNSValidationSkipSuffixes, want) {
+					t.Errorf("DNSValidationSkipSuffixes = %v, want %v", config.DNSValidationSkipSuffixes, want)
+				}
+			},
+		},
+		{
+			name: "acme ca identifiers",
+			env:  map[string]string{"ISTIO_ACME_CA_IDENTIFIERS": "letsencrypt.org, sectigo.com"},
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if want := []string{"letsencrypt.org", "sectigo.com"}; !reflect.DeepEqual(config.ACMECAIdentifiers, want) {
+					t.Errorf("ACMECAIdentifiers = %v, want %v", config.ACMECAIdentifiers, want)
+				}
+			},
+		},
+		{
+			name: "debug gateway header",
+			env:  map[string]string{"ISTIO_DEBUG_GATEWAY_HEADER": "true"},
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if !config.DebugGatewayHeader {
+					t.Error("DebugGatewayHeader = false, want true")
+				}
+			},
+		},
+		{
+			name: "namespace base domains",
+			env:  map[string]string{"ISTIO_NAMESPACE_BASE_DOMAINS": "ns-brand-a=brand-a.io, ns-brand-b=brand-b.io"},
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if want := map[string]string{"ns-brand-a": "brand-a.io", "ns-brand-b": "brand-b.io"}; !reflect.DeepEqual(config.NamespaceBaseDomains, want) {
+					t.Errorf("NamespaceBaseDomains = %v, want %v", config.NamespaceBaseDomains, want)
+				}
+			},
+		},
+		{
+			name: "tenant reserved subdomains",
+			env:  map[string]string{"ISTIO_TENANT_RESERVED_SUBDOMAINS": "tenant-a=api|www"},
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if want := map[string][]string{"tenant-a": {"api", "www"}}; !reflect.DeepEqual(config.TenantReservedSubdomains, want) {
+					t.Errorf("TenantReservedSubdomains = %v, want %v", config.TenantReservedSubdomains, want)
+				}
+			},
+		},
+		{
+			name: "gateway override allowlist",
+			env:  map[string]string{"ISTIO_GATEWAY_OVERRIDE_ALLOWLIST": "istio-system/isolated-gateway, ,istio-system/internal-gateway"},
+			check: func(t *testing.T, config *istio.NetworkConfig) {
+				if want := []string{"istio-system/isolated-gateway", "istio-system/internal-gateway"}; !reflect.DeepEqual(config.GatewayOverrideAllowlist, want) {
+					t.Errorf("GatewayOverrideAllowlist = %v, want %v", config.GatewayOverrideAllowlist, want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			tt.check(t, (&AdminerReconciler{adminerDomain: "cloud.sealos.io"}).buildIstioNetworkConfig())
+		})
+	}
+}
+
+func TestNewCustomDomainValidator_ICPValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"registered": false}`))
+	}))
+	defer server.Close()
+	t.Setenv("ISTIO_BASE_DOMAIN", "cloud.sealos.io")
+	t.Setenv("ISTIO_SKIP_DNS_VALIDATION", "true")
+	t.Setenv("ISTIO_ICP_VALIDATION_ENDPOINT", server.URL)
+
+	err := (&AdminerReconciler{adminerDomain: "cloud.sealos.io"}).NewCustomDomainValidator().ValidateCustomDomain(context.Background(), "app.example.cn")
+	if !errors.Is(err, istio.ErrICPRecordNotFound) {
+		t.Errorf("ValidateCustomDomain() error = %v, want ErrICPRecordNotFound", err)
+	}
+}
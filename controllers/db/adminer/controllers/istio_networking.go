@@ -34,6 +34,8 @@ type AdminerIstioNetworkingReconciler struct {
 	config            *istio.NetworkConfig
 	tlsEnabled        bool
 	adminerDomain     string
+	// servicePort is the adminer Service port, empty means DefaultAppPort
+	servicePort int32
 }
 
 // NewAdminerIstioNetworkingReconciler 创建新的 DB Adminer Istio 网络协调器（使用优化管理器）
@@ -47,6 +49,14 @@ func NewAdminerIstioNetworkingReconciler(client client.Client, config *istio.Net
 	}
 }
 
+// getServicePort 返回 Adminer Service 的端口，未设置时使用 DefaultAppPort
+func (r *AdminerIstioNetworkingReconciler) getServicePort() int32 {
+	if r.servicePort > 0 {
+		return r.servicePort
+	}
+	return DefaultAppPort
+}
+
 // SyncIstioNetworking 同步 DB Adminer 的 Istio 网络配置
 func (r *AdminerIstioNetworkingReconciler) SyncIstioNetworking(ctx context.Context, adminer *adminerv1.Adminer, hostname string) error {
 	// 构建网络配置规范
@@ -125,7 +135,7 @@ func (r *AdminerIstioNetworkingReconciler) buildNetworkingSpec(adminer *adminerv
 		Protocol:    istio.ProtocolHTTP,
 		Hosts:       []string{domain},
 		ServiceName: adminer.Name,
-		ServicePort: 8080,
+		ServicePort: r.getServicePort(),
 
 		// 数据库管理器专用配置
 		Timeout: &[]time.Duration{86400 * time.Second}[0], // 24小时超时，支持长时间数据库操作
@@ -139,7 +149,7 @@ func (r *AdminerIstioNetworkingReconciler) buildNetworkingSpec(adminer *adminerv
 		},
 
 		// 安全头部配置，设置为响应头部
-		ResponseHeaders: r.buildSecurityHeaders(),
+		ResponseHeaders: buildSecurityHeaders(adminer.Spec.Engine, r.adminerDomain),
 
 		// 标签
 		Labels: map[string]string{
@@ -166,30 +176,6 @@ func (r *AdminerIstioNetworkingReconciler) buildNetworkingSpec(adminer *adminerv
 	return spec
 }
 
-// buildSecurityHeaders 构建安全响应头部
-func (r *AdminerIstioNetworkingReconciler) buildSecurityHeaders() map[string]string {
-	headers := make(map[string]string)
-
-	// 设置 X-Frame-Options，允许 iframe 嵌入（响应头部）
-	headers["X-Frame-Options"] = ""
-
-	// 设置 Content Security Policy（响应头部）
-	cspValue := fmt.Sprintf("default-src * blob: data: *.%s %s; img-src * data: blob: resource: *.%s %s; connect-src * wss: blob: resource:; style-src 'self' 'unsafe-inline' blob: *.%s %s resource:; script-src 'self' 'unsafe-inline' 'unsafe-eval' blob: *.%s %s resource: *.baidu.com *.bdstatic.com; frame-src 'self' %s *.%s mailto: tel: weixin: mtt: *.baidu.com; frame-ancestors 'self' https://%s https://*.%s",
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain,
-		r.adminerDomain, r.adminerDomain)
-
-	headers["Content-Security-Policy"] = cspValue
-
-	// 设置 XSS 保护（响应头部）
-	headers["X-Xss-Protection"] = "1; mode=block"
-
-	return headers
-}
-
 // needsUpdate 检查是否需要更新网络配置
 func (r *AdminerIstioNetworkingReconciler) needsUpdate(adminer *adminerv1.Adminer, status *istio.NetworkingStatus) bool {
 	// 简单检查：如果 VirtualService 或 Gateway 未就绪，需要更新
@@ -107,4 +107,46 @@ func TestBuildNetworkingSpec_CorsPolicy(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestSetAdminerNetworkingStatus(t *testing.T) {
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+		PublicDomains:  []string{"cloud.sealos.io"},
+	}
+	helper := istio.NewUniversalIstioNetworkingHelper(nil, istioConfig, "adminer")
+
+	tests := []struct {
+		name        string
+		host        string
+		wantType    string
+		wantGateway string
+	}{
+		{name: "public domain", host: "test-adminer.cloud.sealos.io", wantType: istio.DomainTypePublic, wantGateway: "istio-system/sealos-gateway"},
+		{name: "custom domain", host: "adminer.custom.example.com", wantType: istio.DomainTypeCustom, wantGateway: "test-namespace/test-adminer-gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := helper.AnalyzeDomainRequirements(&istio.AppNetworkingParams{
+				Name:      "test-adminer",
+				Namespace: "test-namespace",
+				AppType:   "adminer",
+				Hosts:     []string{tt.host},
+			})
+			adminer := &adminerv1.Adminer{}
+			setAdminerNetworkingStatus(adminer, "https://"+tt.host, analysis)
+
+			if adminer.Status.DomainType != tt.wantType {
+				t.Errorf("status.domainType = %s, want %s", adminer.Status.DomainType, tt.wantType)
+			}
+			if adminer.Status.GatewayReference != tt.wantGateway {
+				t.Errorf("status.gatewayReference = %s, want %s", adminer.Status.GatewayReference, tt.wantGateway)
+			}
+			// 注解保留用于向后兼容
+			if adminer.Annotations["sealos.io/domain-type"] != adminer.Status.DomainType ||
+				adminer.Annotations["sealos.io/gateway-reference"] != adminer.Status.GatewayReference {
+				t.Errorf("annotations %v do not match status %+v", adminer.Annotations, adminer.Status)
+			}
+		})
+	}
+}
@@ -52,7 +52,7 @@ func TestSecurityHeadersAsResponseHeaders(t *testing.T) {
 			Protocol:    istio.ProtocolHTTP,
 
 			// Security headers should be set as response headers
-			ResponseHeaders: reconciler.buildSecurityHeaders(),
+			ResponseHeaders: buildSecurityHeaders(adminer.Spec.Engine, reconciler.adminerDomain),
 
 			// Owner reference
 			OwnerObject: adminer,
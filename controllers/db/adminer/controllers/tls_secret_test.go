@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	adminerv1 "github.com/labring/sealos/controllers/db/adminer/api/v1"
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestSetTLSSecretCondition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := adminerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add adminer scheme: %v", err)
+	}
+	adminer := &adminerv1.Adminer{ObjectMeta: metav1.ObjectMeta{Name: "test-adminer", Namespace: "ns-test"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(adminer).WithStatusSubresource(adminer).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &AdminerReconciler{Client: c, Scheme: scheme, recorder: recorder}
+	ctx := context.Background()
+
+	get := func() *metav1.Condition {
+		t.Helper()
+		got := &adminerv1.Adminer{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(adminer), got); err != nil {
+			t.Fatalf("get adminer: %v", err)
+		}
+		return meta.FindStatusCondition(got.Status.Conditions, ConditionTypeTLSSecretReady)
+	}
+
+	// secret present from the start: no condition is added
+	if err := r.setTLSSecretCondition(ctx, adminer, nil); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	if condition := get(); condition != nil {
+		t.Fatalf("condition = %+v, want none while the secret exists", condition)
+	}
+
+	// secret missing: the condition explains why networking is not ready
+	missing := fmt.Errorf("%w: secret ns-test/test-adminer-tls", istio.ErrTLSSecretNotFound)
+	if err := r.setTLSSecretCondition(ctx, adminer, missing); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	condition := get()
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != ReasonTLSSecretNotFound ||
+		condition.Message != missing.Error() {
+		t.Fatalf("condition = %+v, want False/%s", condition, ReasonTLSSecretNotFound)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("events = %d, want 1 warning for the missing secret", len(recorder.Events))
+	}
+
+	// repeated requeues do not emit more events
+	if err := r.setTLSSecretCondition(ctx, adminer, missing); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("events = %d, want no new event for an unchanged condition", len(recorder.Events))
+	}
+
+	// secret created: the condition flips back
+	if err := r.setTLSSecretCondition(ctx, adminer, nil); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	if condition := get(); condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != ReasonTLSSecretFound {
+		t.Errorf("condition = %+v, want True/%s", condition, ReasonTLSSecretFound)
+	}
+}
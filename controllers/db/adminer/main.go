@@ -112,13 +112,20 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.AdminerReconciler{
+	adminerReconciler := &controllers.AdminerReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if err = adminerReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Adminer")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&adminerv1.Adminer{}).SetupWebhookWithManager(mgr, adminerReconciler.NewCustomDomainValidator()); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Adminer")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
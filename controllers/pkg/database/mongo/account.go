@@ -476,6 +476,26 @@ func (m *mongoDB) GetAllPayment() ([]resources.Billing, error) {
 	return payments, nil
 }
 
+// GetBillingRecords returns the billing records in [startTime, endTime), ordered by time
+func (m *mongoDB) GetBillingRecords(startTime, endTime time.Time) ([]resources.Billing, error) {
+	filter := bson.M{
+		"time": bson.M{
+			"$gte": startTime,
+			"$lt":  endTime,
+		},
+	}
+	cursor, err := m.getBillingCollection().Find(context.Background(), filter, options.Find().SetSort(bson.M{"time": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("get billing records error: %v", err)
+	}
+
+	var billings []resources.Billing
+	if err = cursor.All(context.Background(), &billings); err != nil {
+		return nil, fmt.Errorf("get billing records error: %v", err)
+	}
+	return billings, nil
+}
+
 func (m *mongoDB) InitDefaultPropertyTypeLS() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
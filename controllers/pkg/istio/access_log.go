@@ -0,0 +1,176 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// AccessLogFormatAnnotation 记录 EnvoyFilter 生成时使用的日志格式，用于判断格式是否变化
+	AccessLogFormatAnnotation = "network.sealos.io/access-log-format"
+
+	fileAccessLogType = "type.googleapis.com/envoy.extensions.access_loggers.file.v3.FileAccessLog"
+)
+
+// accessLogEnvoyFilterName 应用访问日志 EnvoyFilter 的名称。EnvoyFilter 必须与网关工作负载在同一命名空间才能生效，
+// 因此名称中带上应用命名空间以免不同租户的同名应用冲突
+func accessLogEnvoyFilterName(name, namespace string) string {
+	return fmt.Sprintf("%s-%s-access-log", namespace, name)
+}
+
+// accessLogAuthorityRegex 生成匹配应用域名（可带端口）的 :authority 正则，*.example.com 只匹配一级子域名
+func accessLogAuthorityRegex(hosts []string) string {
+	patterns := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.ToLower(host)
+		if suffix, found := strings.CutPrefix(host, "*."); found {
+			patterns = append(patterns, `[^.]+\.`+regexp.QuoteMeta(suffix))
+			continue
+		}
+		patterns = append(patterns, regexp.QuoteMeta(host))
+	}
+	return fmt.Sprintf(`^(%s)(:\d+)?$`, strings.Join(patterns, "|"))
+}
+
+// buildAccessLogEnvoyFilterSpec 生成作用于网关的 EnvoyFilter：在 HTTP 连接管理器上追加一个文件访问日志，
+// 通过 :authority 过滤只记录该应用域名的请求，集群默认关闭访问日志时不影响其他应用
+func buildAccessLogEnvoyFilterSpec(config *NetworkConfig, hosts []string, accessLog *AccessLogConfig) map[string]interface{} {
+	logger := map[string]interface{}{
+		"@type": fileAccessLogType,
+		"path":  "/dev/stdout",
+	}
+	if accessLog.Format != "" {
+		format := accessLog.Format
+		if !strings.HasSuffix(format, "\n") {
+			format += "\n"
+		}
+		logger["log_format"] = map[string]interface{}{
+			"text_format_source": map[string]interface{}{"inline_string": format},
+		}
+	}
+
+	spec := map[string]interface{}{
+		"configPatches": []interface{}{
+			map[string]interface{}{
+				"applyTo": "NETWORK_FILTER",
+				"match": map[string]interface{}{
+					"context": "GATEWAY",
+					"listener": map[string]interface{}{
+						"filterChain": map[string]interface{}{
+							"filter": map[string]interface{}{"name": httpConnectionManagerFilter},
+						},
+					},
+				},
+				"patch": map[string]interface{}{
+					"operation": "MERGE",
+					"value": map[string]interface{}{
+						"typed_config": map[string]interface{}{
+							"@type": httpConnectionManagerType,
+							"access_log": []interface{}{
+								map[string]interface{}{
+									"name": "envoy.access_loggers.file",
+									"filter": map[string]interface{}{
+										"header_filter": map[string]interface{}{
+											"header": map[string]interface{}{
+												"name": ":authority",
+												"string_match": map[string]interface{}{
+													"safe_regex": map[string]interface{}{"regex": accessLogAuthorityRegex(hosts)},
+												},
+											},
+										},
+									},
+									"typed_config": logger,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if len(config.GatewaySelector) > 0 {
+		labels := make(map[string]interface{}, len(config.GatewaySelector))
+		for k, v := range config.GatewaySelector {
+			labels[k] = v
+		}
+		spec["workloadSelector"] = map[string]interface{}{"labels": labels}
+	}
+	return spec
+}
+
+// syncAccessLogEnvoyFilter 按 spec.AccessLog 在网关命名空间创建或更新应用的访问日志 EnvoyFilter，
+// 未开启时删除已有的 EnvoyFilter。EnvoyFilter 与应用不在同一命名空间，无法设置 OwnerReference
+func syncAccessLogEnvoyFilter(ctx context.Context, c Client, config *NetworkConfig, spec *AppNetworkingSpec) error {
+	if spec.AccessLog == nil || len(spec.Hosts) == 0 || spec.Protocol == ProtocolTCP {
+		return deleteAccessLogEnvoyFilter(ctx, c, config, spec.Name, spec.Namespace)
+	}
+
+	filter := &unstructured.Unstructured{}
+	filter.SetGroupVersionKind(envoyFilterGVK)
+	filter.SetName(accessLogEnvoyFilterName(spec.Name, spec.Namespace))
+	filter.SetNamespace(getSystemNamespace(config))
+	_, err := controllerutil.CreateOrUpdate(ctx, c, filter, func() error {
+		filter.SetLabels(MergeLabels(filter.GetLabels(), map[string]string{
+			"app.kubernetes.io/managed-by": "sealos-istio",
+			"app.kubernetes.io/component":  "networking",
+			"network.sealos.io/app":        spec.Name,
+			"network.sealos.io/namespace":  spec.Namespace,
+		}))
+		filter.SetAnnotations(MergeLabels(filter.GetAnnotations(), map[string]string{
+			AccessLogFormatAnnotation: spec.AccessLog.Format,
+		}))
+		filterSpec := makeSafeForDeepCopy(buildAccessLogEnvoyFilterSpec(config, spec.Hosts, spec.AccessLog))
+		return unstructured.SetNestedMap(filter.Object, filterSpec.(map[string]interface{}), "spec")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync access log envoyfilter %s/%s: %w", filter.GetNamespace(), filter.GetName(), err)
+	}
+	return nil
+}
+
+// deleteAccessLogEnvoyFilter 删除应用的访问日志 EnvoyFilter，不存在或未安装 EnvoyFilter CRD 时忽略
+func deleteAccessLogEnvoyFilter(ctx context.Context, c Client, config *NetworkConfig, name, namespace string) error {
+	filter := &unstructured.Unstructured{}
+	filter.SetGroupVersionKind(envoyFilterGVK)
+	filter.SetName(accessLogEnvoyFilterName(name, namespace))
+	filter.SetNamespace(getSystemNamespace(config))
+	if err := c.Delete(ctx, filter); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("failed to delete access log envoyfilter %s/%s: %w", filter.GetNamespace(), filter.GetName(), err)
+	}
+	return nil
+}
+
+// getAccessLogConfig 返回应用当前生效的访问日志配置，未开启时返回 nil
+func getAccessLogConfig(ctx context.Context, c Client, config *NetworkConfig, name, namespace string) *AccessLogConfig {
+	filter := &unstructured.Unstructured{}
+	filter.SetGroupVersionKind(envoyFilterGVK)
+	key := types.NamespacedName{Name: accessLogEnvoyFilterName(name, namespace), Namespace: getSystemNamespace(config)}
+	if err := c.Get(ctx, key, filter); err != nil {
+		return nil
+	}
+	return &AccessLogConfig{Format: filter.GetAnnotations()[AccessLogFormatAnnotation]}
+}
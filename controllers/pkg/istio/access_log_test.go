@@ -0,0 +1,157 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestAccessLogManager(c Client, config *NetworkConfig) *optimizedNetworkingManager {
+	return &optimizedNetworkingManager{
+		client:            c,
+		scheme:            runtime.NewScheme(),
+		config:            config,
+		gatewayController: &mockGatewayController{},
+		vsController:      &mockVirtualServiceController{},
+		drController:      NewDestinationRuleController(c, config),
+		domainAllocator:   &mockDomainAllocator{},
+		certManager:       &mockCertificateManager{},
+		domainClassifier:  NewDomainClassifier(config),
+	}
+}
+
+func getTestAccessLogFilter(t *testing.T, c Client) (*unstructured.Unstructured, error) {
+	t.Helper()
+	filter := &unstructured.Unstructured{}
+	filter.SetGroupVersionKind(envoyFilterGVK)
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: "istio-system", Name: "ns-user1-app1-access-log"}, filter)
+	return filter, err
+}
+
+func TestAppNetworking_AccessLog(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+		GatewaySelector:      map[string]string{"istio": "ingressgateway"},
+	}
+	manager := newTestAccessLogManager(c, config)
+	ctx := context.Background()
+	spec := &AppNetworkingSpec{
+		Name:        "app1",
+		Namespace:   "ns-user1",
+		Protocol:    ProtocolHTTP,
+		Hosts:       []string{"app1.cloud.sealos.io"},
+		ServiceName: "app1",
+		ServicePort: 8080,
+	}
+
+	// 默认不开启访问日志
+	if err := manager.CreateAppNetworking(ctx, spec); err != nil {
+		t.Fatalf("CreateAppNetworking() error = %v", err)
+	}
+	if _, err := getTestAccessLogFilter(t, c); !apierrors.IsNotFound(err) {
+		t.Fatalf("access log envoyfilter should be absent when disabled, got err = %v", err)
+	}
+
+	// 开启访问日志并指定格式
+	spec.AccessLog = &AccessLogConfig{Format: "%REQ(:AUTHORITY)% %RESPONSE_CODE%"}
+	if err := manager.UpdateAppNetworking(ctx, spec); err != nil {
+		t.Fatalf("UpdateAppNetworking() error = %v", err)
+	}
+	filter, err := getTestAccessLogFilter(t, c)
+	if err != nil {
+		t.Fatalf("access log envoyfilter should exist when enabled: %v", err)
+	}
+	if got := filter.GetLabels()["network.sealos.io/namespace"]; got != "ns-user1" {
+		t.Errorf("namespace label = %q, want ns-user1", got)
+	}
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if len(patches) != 1 {
+		t.Fatalf("configPatches = %d, want 1", len(patches))
+	}
+	accessLogs, _, _ := unstructured.NestedSlice(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "access_log")
+	if len(accessLogs) != 1 {
+		t.Fatalf("access_log entries = %d, want 1", len(accessLogs))
+	}
+	entry := accessLogs[0].(map[string]interface{})
+	regex, _, _ := unstructured.NestedString(entry, "filter", "header_filter", "header", "string_match", "safe_regex", "regex")
+	if !regexp.MustCompile(regex).MatchString("app1.cloud.sealos.io:443") {
+		t.Errorf("authority regex %q should match the app host", regex)
+	}
+	format, _, _ := unstructured.NestedString(entry, "typed_config", "log_format", "text_format_source", "inline_string")
+	if format != "%REQ(:AUTHORITY)% %RESPONSE_CODE%\n" {
+		t.Errorf("log format = %q, want the configured format with a trailing newline", format)
+	}
+	selector, _, _ := unstructured.NestedStringMap(filter.Object, "spec", "workloadSelector", "labels")
+	if selector["istio"] != "ingressgateway" {
+		t.Errorf("workloadSelector = %v, want istio=ingressgateway", selector)
+	}
+
+	if got := getAccessLogConfig(ctx, c, config, "app1", "ns-user1"); got == nil || got.Format != spec.AccessLog.Format {
+		t.Errorf("getAccessLogConfig() = %+v, want format %q", got, spec.AccessLog.Format)
+	}
+
+	// 关闭后删除 EnvoyFilter
+	spec.AccessLog = nil
+	if err := manager.UpdateAppNetworking(ctx, spec); err != nil {
+		t.Fatalf("UpdateAppNetworking() error = %v", err)
+	}
+	if _, err := getTestAccessLogFilter(t, c); !apierrors.IsNotFound(err) {
+		t.Fatalf("access log envoyfilter should be deleted when disabled, got err = %v", err)
+	}
+
+	// 删除应用时一并清理
+	spec.AccessLog = &AccessLogConfig{}
+	if err := manager.UpdateAppNetworking(ctx, spec); err != nil {
+		t.Fatalf("UpdateAppNetworking() error = %v", err)
+	}
+	if err := manager.DeleteAppNetworking(ctx, "app1", "ns-user1"); err != nil {
+		t.Fatalf("DeleteAppNetworking() error = %v", err)
+	}
+	if _, err := getTestAccessLogFilter(t, c); !apierrors.IsNotFound(err) {
+		t.Errorf("access log envoyfilter should be deleted with the app, got err = %v", err)
+	}
+}
+
+func TestAccessLogAuthorityRegex(t *testing.T) {
+	re := regexp.MustCompile(accessLogAuthorityRegex([]string{"App.example.com", "*.apps.example.com"}))
+	tests := map[string]bool{
+		"app.example.com":          true,
+		"app.example.com:8443":     true,
+		"foo.apps.example.com":     true,
+		"a.b.apps.example.com":     false,
+		"appxexample.com":          false,
+		"other.example.com":        false,
+		"app.example.com.evil.com": false,
+	}
+	for authority, want := range tests {
+		if got := re.MatchString(authority); got != want {
+			t.Errorf("MatchString(%q) = %v, want %v", authority, got, want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ErrCAAForbidden 域名的 CAA 记录不允许配置的 CA 签发证书
+var ErrCAAForbidden = errors.New("CAA records forbid certificate issuance by the configured CA")
+
+// DefaultACMECAIdentifiers 默认允许的 CA 标识（cert-manager 默认使用 Let's Encrypt）
+var DefaultACMECAIdentifiers = []string{"letsencrypt.org"}
+
+// dnsTypeCAA CAA 记录类型（RFC 8659）
+const dnsTypeCAA dnsmessage.Type = 257
+
+// CAARecord CAA 记录
+type CAARecord struct {
+	Flag  uint8
+	Tag   string
+	Value string
+}
+
+// CAAResolver CAA 记录解析器
+type CAAResolver interface {
+	// LookupCAA 查询域名自身的 CAA 记录（不做域名树回溯），记录不存在时返回空列表
+	LookupCAA(ctx context.Context, domain string) ([]CAARecord, error)
+}
+
+// CheckCAA 检查域名的 CAA 记录是否允许 allowedCAs 中的 CA 签发证书
+func (d *domainAllocator) CheckCAA(ctx context.Context, domain string, allowedCAs []string) error {
+	// 内部域名无法从公网解析，CAA 检查与 DNS 验证一同跳过
+	if d.shouldSkipDNSValidation(strings.TrimPrefix(domain, "*.")) {
+		return nil
+	}
+
+	if len(allowedCAs) == 0 {
+		allowedCAs = DefaultACMECAIdentifiers
+	}
+
+	resolver := d.caaResolver
+	if resolver == nil {
+		resolver = newDNSCAAResolver()
+	}
+
+	wildcard := strings.HasPrefix(domain, "*.")
+	name := strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(domain), "*."), ".")
+
+	// 按 RFC 8659 从域名自身向上查找，第一个存在 CAA 记录的节点生效
+	for name != "" {
+		start := time.Now()
+		records, err := resolver.LookupCAA(ctx, name)
+		observeDomainLookup(domainLookupCAA, start, err)
+		if err != nil {
+			return fmt.Errorf("CAA lookup failed for %s: %w", name, err)
+		}
+		if len(records) > 0 {
+			return evaluateCAARecords(domain, records, wildcard, allowedCAs)
+		}
+
+		idx := strings.Index(name, ".")
+		if idx < 0 {
+			break
+		}
+		name = name[idx+1:]
+	}
+
+	// 没有 CAA 记录，任何 CA 均可签发
+	return nil
+}
+
+// evaluateCAARecords 根据 issue/issuewild 标签判断是否允许签发
+func evaluateCAARecords(domain string, records []CAARecord, wildcard bool, allowedCAs []string) error {
+	tag := "issue"
+	if wildcard {
+		for _, record := range records {
+			if strings.EqualFold(record.Tag, "issuewild") {
+				tag = "issuewild"
+				break
+			}
+		}
+	}
+
+	var issuers []string
+	for _, record := range records {
+		if !strings.EqualFold(record.Tag, tag) {
+			continue
+		}
+		// 值格式："ca.example.net; key=value"，分号前为 CA 标识，空值表示禁止任何 CA
+		issuer := strings.TrimSpace(strings.SplitN(record.Value, ";", 2)[0])
+		issuers = append(issuers, issuer)
+	}
+
+	// 只有 iodef 等其他标签时不限制签发
+	if len(issuers) == 0 {
+		return nil
+	}
+
+	for _, issuer := range issuers {
+		for _, allowed := range allowedCAs {
+			if issuer != "" && strings.EqualFold(issuer, allowed) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: %s only allows %v", ErrCAAForbidden, domain, issuers)
+}
+
+// dnsCAAResolver 基于 UDP DNS 查询的 CAA 解析器，响应被截断时改用 TCP
+type dnsCAAResolver struct {
+	server  string
+	timeout time.Duration
+}
+
+// newDNSCAAResolver 使用 /etc/resolv.conf 中的第一个 nameserver 创建解析器
+func newDNSCAAResolver() CAAResolver {
+	return &dnsCAAResolver{
+		server:  systemNameserver(),
+		timeout: 5 * time.Second,
+	}
+}
+
+func (r *dnsCAAResolver) LookupCAA(ctx context.Context, domain string) ([]CAARecord, error) {
+	name, err := dnsmessage.NewName(strings.TrimSuffix(domain, ".") + ".")
+	if err != nil {
+		return nil, err
+	}
+
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: uint16(rand.Intn(1 << 16)), RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: dnsTypeCAA, Class: dnsmessage.ClassINET},
+		},
+	}
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.exchange(ctx, "udp", packed, query.Header.ID)
+	if err != nil {
+		return nil, err
+	}
+	// UDP 响应被截断（TC 位）时通过 TCP 重新查询，避免只读到部分 CAA 记录
+	if resp.Header.Truncated {
+		if resp, err = r.exchange(ctx, "tcp", packed, query.Header.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	switch resp.Header.RCode {
+	case dnsmessage.RCodeSuccess:
+	case dnsmessage.RCodeNameError:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("DNS query returned %s", resp.Header.RCode)
+	}
+
+	var records []CAARecord
+	for _, answer := range resp.Answers {
+		if answer.Header.Type != dnsTypeCAA {
+			continue
+		}
+		body, ok := answer.Body.(*dnsmessage.UnknownResource)
+		if !ok {
+			continue
+		}
+		if record, ok := parseCAARecord(body.Data); ok {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+// exchange 通过 udp 或 tcp 发送查询并解析响应，TCP 消息带 2 字节长度前缀（RFC 1035 4.2.2）
+func (r *dnsCAAResolver) exchange(ctx context.Context, network string, packed []byte, id uint16) (*dnsmessage.Message, error) {
+	dialer := &net.Dialer{Timeout: r.timeout}
+	conn, err := dialer.DialContext(ctx, network, r.server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(r.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	if network == "tcp" {
+		msg := make([]byte, 2+len(packed))
+		binary.BigEndian.PutUint16(msg, uint16(len(packed)))
+		copy(msg[2:], packed)
+		if _, err := conn.Write(msg); err != nil {
+			return nil, err
+		}
+		var length [2]byte
+		if _, err := io.ReadFull(conn, length[:]); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := conn.Write(packed); err != nil {
+			return nil, err
+		}
+		buf = make([]byte, 4096)
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[:n]
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(buf); err != nil {
+		return nil, err
+	}
+	if resp.Header.ID != id {
+		return nil, fmt.Errorf("mismatched DNS response id")
+	}
+	return &resp, nil
+}
+
+// parseCAARecord 解析 CAA 记录的 RDATA：flag(1) + tag length(1) + tag + value
+func parseCAARecord(data []byte) (CAARecord, bool) {
+	if len(data) < 2 {
+		return CAARecord{}, false
+	}
+	tagLen := int(data[1])
+	if len(data) < 2+tagLen {
+		return CAARecord{}, false
+	}
+	return CAARecord{
+		Flag:  data[0],
+		Tag:   string(data[2 : 2+tagLen]),
+		Value: string(data[2+tagLen:]),
+	}, true
+}
+
+// systemNameserver 读取系统 nameserver，读取失败时使用公共 DNS
+func systemNameserver() string {
+	const fallback = "8.8.8.8:53"
+
+	f, err := os.Open("/etc/resolv.conf")
+	if err != nil {
+		return fallback
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return net.JoinHostPort(fields[1], "53")
+		}
+	}
+
+	return fallback
+}
@@ -21,6 +21,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"maps"
 	"strings"
 	"time"
 
@@ -158,7 +159,7 @@ func (c *certificateManager) copyDefaultCertificate(ctx context.Context, secretN
 	defaultSecret := &corev1.Secret{}
 	defaultKey := types.NamespacedName{
 		Name:      c.config.DefaultTLSSecret,
-		Namespace: "sealos-system", // 默认证书通常在系统命名空间
+		Namespace: DefaultTLSSecretNamespace,
 	}
 
 	if err := c.client.Get(ctx, defaultKey, defaultSecret); err != nil {
@@ -170,10 +171,8 @@ func (c *certificateManager) copyDefaultCertificate(ctx context.Context, secretN
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      secretName,
 			Namespace: namespace,
-			Labels: map[string]string{
-				"app.kubernetes.io/managed-by": "sealos-istio",
-				"sealos.io/cert-type":          "wildcard",
-			},
+			// 默认证书轮换时 TLSSecretMirror 按这些标签刷新副本
+			Labels: maps.Clone(mirroredTLSSecretLabels),
 		},
 		Type: corev1.SecretTypeTLS,
 		Data: map[string][]byte{
@@ -0,0 +1,287 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// TerminalSessionCookie Terminal 一致性哈希使用的会话 Cookie
+const TerminalSessionCookie = "sealos-terminal-session"
+
+// Istio simple 负载均衡算法
+var simpleLoadBalancers = map[LoadBalancerType]string{
+	LoadBalancerRoundRobin: "ROUND_ROBIN",
+	LoadBalancerLeastConn:  "LEAST_REQUEST",
+}
+
+var (
+	// Istio DestinationRule GVK
+	destinationRuleGVK = schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    "DestinationRule",
+	}
+)
+
+// destinationRuleController DestinationRule 控制器实现
+type destinationRuleController struct {
+	client client.Client
+	config *NetworkConfig
+}
+
+// NewDestinationRuleController 创建新的 DestinationRule 控制器
+func NewDestinationRuleController(client client.Client, config *NetworkConfig) DestinationRuleController {
+	return &destinationRuleController{
+		client: client,
+		config: config,
+	}
+}
+
+// CreateOrUpdateWithOwner 创建或更新 DestinationRule（支持设置 OwnerReference）
+func (d *destinationRuleController) CreateOrUpdateWithOwner(ctx context.Context, config *DestinationRuleConfig, owner metav1.Object, scheme *runtime.Scheme) error {
+	if err := validateSubsets(config.Subsets); err != nil {
+		return err
+	}
+	if err := validateLoadBalancer(config.LoadBalancer); err != nil {
+		return err
+	}
+
+	dr := &unstructured.Unstructured{}
+	dr.SetGroupVersionKind(destinationRuleGVK)
+	dr.SetName(config.Name)
+	dr.SetNamespace(config.Namespace)
+
+	_, err := controllerutil.CreateOrUpdate(ctx, d.client, dr, func() error {
+		// 设置标签
+		labels := dr.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, val := range config.Labels {
+			labels[k] = val
+		}
+		labels["app.kubernetes.io/managed-by"] = "sealos-istio"
+		labels["app.kubernetes.io/component"] = "networking"
+		dr.SetLabels(labels)
+
+		// 构建并设置 spec
+		spec := d.buildDestinationRuleSpec(config)
+		// 确保所有值都可以深拷贝
+		safeSpec := makeSafeForDeepCopy(spec)
+		if err := unstructured.SetNestedMap(dr.Object, safeSpec.(map[string]interface{}), "spec"); err != nil {
+			return fmt.Errorf("failed to set destinationrule spec: %w", err)
+		}
+
+		// 设置所有者引用
+		if owner != nil && scheme != nil {
+			return controllerutil.SetControllerReference(owner, dr, scheme)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update destinationrule: %w", err)
+	}
+
+	return nil
+}
+
+func (d *destinationRuleController) Delete(ctx context.Context, name, namespace string) error {
+	dr := &unstructured.Unstructured{}
+	dr.SetGroupVersionKind(destinationRuleGVK)
+	dr.SetName(name)
+	dr.SetNamespace(namespace)
+
+	return client.IgnoreNotFound(d.client.Delete(ctx, dr))
+}
+
+func (d *destinationRuleController) Get(ctx context.Context, name, namespace string) (*DestinationRuleConfig, error) {
+	dr := &unstructured.Unstructured{}
+	dr.SetGroupVersionKind(destinationRuleGVK)
+
+	key := types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	if err := d.client.Get(ctx, key, dr); err != nil {
+		return nil, err
+	}
+
+	return d.parseDestinationRule(dr)
+}
+
+// buildDestinationRuleSpec 构建 DestinationRule 规范
+func (d *destinationRuleController) buildDestinationRuleSpec(config *DestinationRuleConfig) map[string]interface{} {
+	spec := map[string]interface{}{
+		"host": config.Host,
+	}
+
+	if len(config.Subsets) > 0 {
+		subsets := make([]interface{}, 0, len(config.Subsets))
+		for _, subset := range config.Subsets {
+			subsets = append(subsets, map[string]interface{}{
+				"name":   subset.Name,
+				"labels": subset.Labels,
+			})
+		}
+		spec["subsets"] = subsets
+	}
+
+	if loadBalancer := buildLoadBalancer(config.LoadBalancer); loadBalancer != nil {
+		spec["trafficPolicy"] = map[string]interface{}{
+			"loadBalancer": loadBalancer,
+		}
+	}
+
+	return spec
+}
+
+// buildLoadBalancer 构建 trafficPolicy.loadBalancer
+func buildLoadBalancer(lb *LoadBalancerConfig) map[string]interface{} {
+	if lb == nil {
+		return nil
+	}
+	if simple, ok := simpleLoadBalancers[lb.Type]; ok {
+		return map[string]interface{}{"simple": simple}
+	}
+	if lb.Type != LoadBalancerConsistentHash {
+		return nil
+	}
+
+	consistentHash := map[string]interface{}{}
+	if lb.HashHeader != "" {
+		consistentHash["httpHeaderName"] = lb.HashHeader
+	} else {
+		consistentHash["httpCookie"] = map[string]interface{}{
+			"name": lb.HashCookie,
+			"ttl":  lb.CookieTTL.String(),
+		}
+	}
+	return map[string]interface{}{"consistentHash": consistentHash}
+}
+
+// parseLoadBalancer 解析 trafficPolicy.loadBalancer
+func parseLoadBalancer(spec map[string]interface{}) *LoadBalancerConfig {
+	loadBalancer, found, _ := unstructured.NestedMap(spec, "trafficPolicy", "loadBalancer")
+	if !found {
+		return nil
+	}
+	if simple, _, _ := unstructured.NestedString(loadBalancer, "simple"); simple != "" {
+		for lbType, value := range simpleLoadBalancers {
+			if value == simple {
+				return &LoadBalancerConfig{Type: lbType}
+			}
+		}
+		return nil
+	}
+	if _, found, _ := unstructured.NestedMap(loadBalancer, "consistentHash"); !found {
+		return nil
+	}
+
+	lb := &LoadBalancerConfig{Type: LoadBalancerConsistentHash}
+	lb.HashHeader, _, _ = unstructured.NestedString(loadBalancer, "consistentHash", "httpHeaderName")
+	lb.HashCookie, _, _ = unstructured.NestedString(loadBalancer, "consistentHash", "httpCookie", "name")
+	if ttl, _, _ := unstructured.NestedString(loadBalancer, "consistentHash", "httpCookie", "ttl"); ttl != "" {
+		lb.CookieTTL, _ = time.ParseDuration(ttl)
+	}
+	return lb
+}
+
+// parseDestinationRule 解析 DestinationRule
+func (d *destinationRuleController) parseDestinationRule(dr *unstructured.Unstructured) (*DestinationRuleConfig, error) {
+	host, _, err := unstructured.NestedString(dr.Object, "spec", "host")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get host from destinationrule spec: %w", err)
+	}
+
+	config := &DestinationRuleConfig{
+		Name:      dr.GetName(),
+		Namespace: dr.GetNamespace(),
+		Host:      host,
+		Labels:    dr.GetLabels(),
+	}
+
+	subsets, _, err := unstructured.NestedSlice(dr.Object, "spec", "subsets")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subsets from destinationrule spec: %w", err)
+	}
+	for _, subsetInterface := range subsets {
+		subset, ok := subsetInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(subset, "name")
+		labels, _, _ := unstructured.NestedStringMap(subset, "labels")
+		config.Subsets = append(config.Subsets, Subset{Name: name, Labels: labels})
+	}
+
+	if spec, found, _ := unstructured.NestedMap(dr.Object, "spec"); found {
+		config.LoadBalancer = parseLoadBalancer(spec)
+	}
+
+	return config, nil
+}
+
+// validateSubsets 校验子集名称唯一且标签非空
+func validateSubsets(subsets []Subset) error {
+	seen := make(map[string]bool, len(subsets))
+	for _, subset := range subsets {
+		if subset.Name == "" {
+			return fmt.Errorf("destinationrule subset name cannot be empty")
+		}
+		if seen[subset.Name] {
+			return fmt.Errorf("duplicate destinationrule subset %s", subset.Name)
+		}
+		if len(subset.Labels) == 0 {
+			return fmt.Errorf("destinationrule subset %s must select pods by labels", subset.Name)
+		}
+		seen[subset.Name] = true
+	}
+	return nil
+}
+
+// validateLoadBalancer 校验负载均衡算法及一致性哈希的哈希键
+func validateLoadBalancer(lb *LoadBalancerConfig) error {
+	if lb == nil {
+		return nil
+	}
+	if _, ok := simpleLoadBalancers[lb.Type]; ok {
+		return nil
+	}
+	if lb.Type != LoadBalancerConsistentHash {
+		return fmt.Errorf("unsupported load balancer type %q", lb.Type)
+	}
+	if (lb.HashHeader == "") == (lb.HashCookie == "") {
+		return fmt.Errorf("consistentHash load balancer requires exactly one of hash header or hash cookie")
+	}
+	if lb.CookieTTL < 0 {
+		return fmt.Errorf("consistentHash cookie ttl cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDestinationRuleController_Subsets(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewDestinationRuleController(c, &NetworkConfig{})
+
+	drConfig := &DestinationRuleConfig{
+		Name:      "app-dr",
+		Namespace: "ns-user1",
+		Host:      "app",
+		Subsets: []Subset{
+			{Name: "v1", Labels: map[string]string{"version": "v1"}},
+			{Name: "v2", Labels: map[string]string{"version": "v2"}},
+		},
+		Labels: map[string]string{"sealos.io/app-name": "app"},
+	}
+	ctx := context.Background()
+	if err := controller.CreateOrUpdateWithOwner(ctx, drConfig, nil, nil); err != nil {
+		t.Fatalf("CreateOrUpdateWithOwner() error = %v", err)
+	}
+
+	got, err := controller.Get(ctx, "app-dr", "ns-user1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Host != "app" {
+		t.Errorf("host = %s, want app", got.Host)
+	}
+	if !reflect.DeepEqual(got.Subsets, drConfig.Subsets) {
+		t.Errorf("subsets = %+v, want %+v", got.Subsets, drConfig.Subsets)
+	}
+	if got.Labels["app.kubernetes.io/managed-by"] != "sealos-istio" || got.Labels["sealos.io/app-name"] != "app" {
+		t.Errorf("unexpected labels: %v", got.Labels)
+	}
+
+	// 更新子集
+	drConfig.Subsets = drConfig.Subsets[1:]
+	if err := controller.CreateOrUpdateWithOwner(ctx, drConfig, nil, nil); err != nil {
+		t.Fatalf("CreateOrUpdateWithOwner() update error = %v", err)
+	}
+	if got, _ = controller.Get(ctx, "app-dr", "ns-user1"); len(got.Subsets) != 1 || got.Subsets[0].Name != "v2" {
+		t.Errorf("subsets after update = %+v, want only v2", got.Subsets)
+	}
+
+	if err := controller.Delete(ctx, "app-dr", "ns-user1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := controller.Get(ctx, "app-dr", "ns-user1"); err == nil {
+		t.Error("destinationrule should be deleted")
+	}
+}
+
+func TestDestinationRuleController_InvalidSubsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		subsets []Subset
+	}{
+		{name: "empty name", subsets: []Subset{{Labels: map[string]string{"version": "v1"}}}},
+		{name: "duplicate name", subsets: []Subset{
+			{Name: "v1", Labels: map[string]string{"version": "v1"}},
+			{Name: "v1", Labels: map[string]string{"version": "v2"}},
+		}},
+		{name: "no labels", subsets: []Subset{{Name: "v1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewDestinationRuleController(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(), &NetworkConfig{})
+			err := controller.CreateOrUpdateWithOwner(context.Background(), &DestinationRuleConfig{
+				Name: "app-dr", Namespace: "ns-user1", Host: "app", Subsets: tt.subsets,
+			}, nil, nil)
+			if err == nil {
+				t.Error("CreateOrUpdateWithOwner() expected error")
+			}
+		})
+	}
+}
+
+func TestVirtualServiceSpec_SubsetTrafficSplit(t *testing.T) {
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	spec := controller.buildVirtualServiceSpec(&VirtualServiceConfig{
+		Name:        "app-vs",
+		Namespace:   "ns-user1",
+		Hosts:       []string{"app.cloud.sealos.io"},
+		Gateways:    []string{"istio-system/sealos-gateway"},
+		ServiceName: "app",
+		ServicePort: 8080,
+		Destinations: []WeightedDestination{
+			{Subset: "v1", Weight: 90},
+			{Subset: "v2", Weight: 10},
+		},
+	})
+
+	vs := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if err := unstructured.SetNestedMap(vs.Object, makeSafeForDeepCopy(spec).(map[string]interface{}), "spec"); err != nil {
+		t.Fatalf("set spec: %v", err)
+	}
+	http, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	routes, _, _ := unstructured.NestedSlice(http[0].(map[string]interface{}), "route")
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 weighted destinations, got %d", len(routes))
+	}
+
+	want := []struct {
+		subset string
+		weight int64
+	}{{"v1", 90}, {"v2", 10}}
+	for i, w := range want {
+		route := routes[i].(map[string]interface{})
+		host, _, _ := unstructured.NestedString(route, "destination", "host")
+		port, _, _ := unstructured.NestedInt64(route, "destination", "port", "number")
+		subset, _, _ := unstructured.NestedString(route, "destination", "subset")
+		weight, _, _ := unstructured.NestedInt64(route, "weight")
+		if host != "app" || port != 8080 || subset != w.subset || weight != w.weight {
+			t.Errorf("route[%d] = %s:%d subset=%s weight=%d, want app:8080 subset=%s weight=%d",
+				i, host, port, subset, weight, w.subset, w.weight)
+		}
+	}
+}
+
+func TestDestinationRule_DefaultLoadBalancerByAppType(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	tests := []struct {
+		appType string
+		want    *LoadBalancerConfig
+	}{
+		{appType: "terminal", want: &LoadBalancerConfig{Type: LoadBalancerConsistentHash, HashCookie: TerminalSessionCookie}},
+		{appType: "adminer", want: &LoadBalancerConfig{Type: LoadBalancerRoundRobin}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.appType, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newTestService("app", "ns-user1", 8080)).Build()
+			helper := NewUniversalIstioNetworkingHelper(c, config, tt.appType)
+			ctx := context.Background()
+			params := &AppNetworkingParams{
+				Name:        "app",
+				Namespace:   "ns-user1",
+				AppType:     tt.appType,
+				Hosts:       []string{"app.cloud.sealos.io"},
+				ServiceName: "app",
+				ServicePort: 8080,
+				Protocol:    ProtocolHTTP,
+			}
+			if err := helper.CreateOrUpdateNetworking(ctx, params); err != nil {
+				t.Fatalf("CreateOrUpdateNetworking() error = %v", err)
+			}
+
+			got, err := NewDestinationRuleController(c, config).Get(ctx, "app-dr", "ns-user1")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got.Host != "app" {
+				t.Errorf("host = %s, want app", got.Host)
+			}
+			if !reflect.DeepEqual(got.LoadBalancer, tt.want) {
+				t.Errorf("load balancer = %+v, want %+v", got.LoadBalancer, tt.want)
+			}
+
+			if err := helper.DeleteNetworking(ctx, "app", "ns-user1"); err != nil {
+				t.Fatalf("DeleteNetworking() error = %v", err)
+			}
+			if _, err := NewDestinationRuleController(c, config).Get(ctx, "app-dr", "ns-user1"); err == nil {
+				t.Error("destinationrule should be deleted with the app networking")
+			}
+		})
+	}
+}
+
+func TestBuildLoadBalancer(t *testing.T) {
+	tests := []struct {
+		name    string
+		lb      *LoadBalancerConfig
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "round robin", lb: &LoadBalancerConfig{Type: LoadBalancerRoundRobin},
+			want: map[string]interface{}{"simple": "ROUND_ROBIN"}},
+		{name: "least conn", lb: &LoadBalancerConfig{Type: LoadBalancerLeastConn},
+			want: map[string]interface{}{"simple": "LEAST_REQUEST"}},
+		{name: "hash by header", lb: &LoadBalancerConfig{Type: LoadBalancerConsistentHash, HashHeader: "x-user-id"},
+			want: map[string]interface{}{"consistentHash": map[string]interface{}{"httpHeaderName": "x-user-id"}}},
+		{name: "hash by session cookie", lb: &LoadBalancerConfig{Type: LoadBalancerConsistentHash, HashCookie: "session"},
+			want: map[string]interface{}{"consistentHash": map[string]interface{}{
+				"httpCookie": map[string]interface{}{"name": "session", "ttl": "0s"},
+			}}},
+		{name: "unknown type", lb: &LoadBalancerConfig{Type: "random"}, wantErr: true},
+		{name: "hash without key", lb: &LoadBalancerConfig{Type: LoadBalancerConsistentHash}, wantErr: true},
+		{name: "hash with both keys", lb: &LoadBalancerConfig{Type: LoadBalancerConsistentHash, HashHeader: "x-user-id", HashCookie: "session"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateLoadBalancer(tt.lb); (err != nil) != tt.wantErr {
+				t.Fatalf("validateLoadBalancer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := buildLoadBalancer(tt.lb); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildLoadBalancer() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
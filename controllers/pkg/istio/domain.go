@@ -17,23 +17,113 @@ limitations under the License.
 package istio
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"net"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
 )
 
+// defaultDNSValidationTimeout 未配置 DNSValidationTimeout 时 DNS 解析验证的超时时间
+const defaultDNSValidationTimeout = 5 * time.Second
+
+// ErrDNSValidationTimeout 自定义域名 DNS 解析验证超时
+var ErrDNSValidationTimeout = errors.New("DNS validation timed out")
+
 // domainAllocator 域名分配器实现
 type domainAllocator struct {
-	config *NetworkConfig
+	config      *NetworkConfig
+	lookupHost  func(ctx context.Context, host string) ([]string, error)
+	caaResolver CAAResolver
+	txtResolver TXTResolver
+	// icpValidator ICP 备案查询后端，为空时跳过 ICP 验证
+	icpValidator ICPValidator
 }
 
 // NewDomainAllocator 创建新的域名分配器
 func NewDomainAllocator(config *NetworkConfig) DomainAllocator {
+	var icpValidator ICPValidator
+	if config != nil && config.ICPValidationEndpoint != "" {
+		icpValidator = NewHTTPICPValidator(config.ICPValidationEndpoint)
+	}
+	return NewDomainAllocatorWithICPValidator(config, icpValidator)
+}
+
+// NewDomainAllocatorWithICPValidator 创建使用指定 ICP 备案查询后端的域名分配器，validator 为空时跳过 ICP 验证
+func NewDomainAllocatorWithICPValidator(config *NetworkConfig, validator ICPValidator) DomainAllocator {
 	return &domainAllocator{
-		config: config,
+		config:       config,
+		lookupHost:   net.DefaultResolver.LookupHost,
+		caaResolver:  newDNSCAAResolver(),
+		txtResolver:  net.DefaultResolver,
+		icpValidator: validator,
+	}
+}
+
+// BaseDomainForNamespace 返回命名空间使用的基础域名，未配置覆盖时返回 BaseDomain
+func BaseDomainForNamespace(config *NetworkConfig, namespace string) string {
+	if baseDomain := strings.TrimSpace(config.NamespaceBaseDomains[namespace]); baseDomain != "" {
+		return baseDomain
+	}
+	return config.BaseDomain
+}
+
+// ParseNamespaceBaseDomains 解析 namespace=domain 格式、逗号分隔的命名空间基础域名覆盖，
+// 如 ns-brand-a=brand-a.io,ns-brand-b=brand-b.io；格式错误的条目被忽略，后出现的条目覆盖先出现的
+func ParseNamespaceBaseDomains(raw string) map[string]string {
+	result := map[string]string{}
+	for _, entry := range strings.Split(raw, ",") {
+		namespace, domain, ok := strings.Cut(entry, "=")
+		namespace, domain = strings.TrimSpace(namespace), strings.TrimSpace(domain)
+		if !ok || namespace == "" || domain == "" {
+			continue
+		}
+		result[namespace] = strings.ToLower(strings.TrimSuffix(domain, "."))
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// ParseTenantReservedSubdomains 解析 tenant=subdomain|subdomain 格式、逗号分隔的租户保留子域名放行配置，
+// 如 tenant-a=api|www,tenant-b=api；同一租户出现多次时合并，格式错误的条目被忽略
+func ParseTenantReservedSubdomains(raw string) map[string][]string {
+	result := map[string][]string{}
+	for _, entry := range strings.Split(raw, ",") {
+		tenantID, subdomains, ok := strings.Cut(entry, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		if !ok || tenantID == "" {
+			continue
+		}
+		for _, subdomain := range strings.Split(subdomains, "|") {
+			if subdomain = strings.ToLower(strings.TrimSpace(subdomain)); subdomain != "" {
+				result[tenantID] = append(result[tenantID], subdomain)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil
 	}
+	return result
+}
+
+// configForNamespace 返回用于命名空间内域名生成的配置，基础域名被覆盖时返回替换了 BaseDomain 的副本
+func configForNamespace(config *NetworkConfig, namespace string) *NetworkConfig {
+	baseDomain := BaseDomainForNamespace(config, namespace)
+	if baseDomain == config.BaseDomain {
+		return config
+	}
+	namespaced := *config
+	namespaced.BaseDomain = baseDomain
+	return &namespaced
 }
 
 func (d *domainAllocator) GenerateAppDomain(tenantID, appName string) string {
@@ -46,6 +136,7 @@ func (d *domainAllocator) GenerateAppDomain(tenantID, appName string) string {
 
 	// 生成短哈希以确保唯一性
 	hash := d.generateShortHash(tenantID + appName)
+	domainAllocationsTotal.WithLabelValues("app").Inc()
 
 	// 替换模板变量
 	domain := template
@@ -53,34 +144,70 @@ func (d *domainAllocator) GenerateAppDomain(tenantID, appName string) string {
 	domain = strings.ReplaceAll(domain, "{{.TenantID}}", d.sanitizeDomainPart(tenantID))
 	domain = strings.ReplaceAll(domain, "{{.Hash}}", hash)
 	domain = strings.ReplaceAll(domain, "{{.BaseDomain}}", d.config.BaseDomain)
+	domain = strings.ToLower(domain)
 
-	return strings.ToLower(domain)
+	return domain
 }
 
-func (d *domainAllocator) ValidateCustomDomain(domain string) error {
-	// 1. 基本格式验证
+func (d *domainAllocator) ClaimAppDomain(ctx context.Context, tenantID, appName string) (string, error) {
+	domain := d.GenerateAppDomain(tenantID, appName)
+	if d.config.DomainRegistry == nil {
+		return domain, nil
+	}
+	// 域名已被其他租户登记或登记表不可用时不使用该域名，由调用方重新协调
+	if err := d.config.DomainRegistry.Claim(ctx, tenantID, domain); err != nil {
+		return "", fmt.Errorf("failed to claim domain %s: %w", domain, err)
+	}
+	return domain, nil
+}
+
+func (d *domainAllocator) ReleaseAppDomain(ctx context.Context, tenantID, appName string) error {
+	if d.config.DomainRegistry == nil {
+		return nil
+	}
+	domain := d.GenerateAppDomain(tenantID, appName)
+	// 由其他租户登记的域名不属于该应用，无需释放
+	if err := d.config.DomainRegistry.Release(ctx, tenantID, domain); err != nil && !errors.Is(err, ErrDomainClaimed) {
+		return fmt.Errorf("failed to release domain %s: %w", domain, err)
+	}
+	return nil
+}
+
+func (d *domainAllocator) ValidateCustomDomain(ctx context.Context, domain string) error {
+	reason, err := d.validateCustomDomain(ctx, domain)
+	recordDomainValidation(reason)
+	return err
+}
+
+// validateCustomDomain 执行自定义域名验证，失败时同时返回失败原因
+func (d *domainAllocator) validateCustomDomain(ctx context.Context, domain string) (string, error) {
+	// 1. 基本格式验证（Unicode 域名按 punycode 形式验证）
 	if err := d.validateDomainFormat(domain); err != nil {
-		return err
+		return DomainValidationReasonFormat, err
 	}
+	unicodeDomain := domain
+	domain, _ = NormalizeDomain(domain)
 
 	// 2. 检查是否为保留域名
 	if d.isReservedDomain(domain) {
-		return fmt.Errorf("domain %s is reserved", domain)
+		return DomainValidationReasonReserved, fmt.Errorf("domain %s is reserved", domain)
 	}
 
-	// 3. DNS 解析验证
-	if err := d.validateDNSResolution(domain); err != nil {
-		return fmt.Errorf("DNS validation failed for %s: %w", domain, err)
+	// 3. DNS 解析验证（内部/私有域名可配置跳过）
+	if !d.shouldSkipDNSValidation(domain) {
+		if err := d.validateDNSResolution(ctx, domain); err != nil {
+			return DomainValidationReasonDNS, fmt.Errorf("DNS validation failed for %s: %w", domain, err)
+		}
 	}
 
 	// 4. ICP 备案验证（中国域名）
-	if d.isChinaDomain(domain) {
-		if err := d.validateICPRecord(domain); err != nil {
-			return fmt.Errorf("ICP validation failed for %s: %w", domain, err)
+	if d.isChinaDomain(unicodeDomain) || d.isChinaDomain(domain) {
+		if err := d.validateICPRecord(ctx, domain); err != nil {
+			return DomainValidationReasonICP, fmt.Errorf("ICP validation failed for %s: %w", domain, err)
 		}
 	}
 
-	return nil
+	return "", nil
 }
 
 func (d *domainAllocator) IsDomainAvailable(domain string) (bool, error) {
@@ -94,19 +221,77 @@ func (d *domainAllocator) IsDomainAvailable(domain string) (bool, error) {
 		return false, nil
 	}
 
-	// 这里可以添加更多检查，比如：
-	// - 检查域名是否已被其他租户使用
-	// - 检查域名是否在黑名单中
+	// 检查域名是否已被租户登记
+	if d.config.DomainRegistry != nil {
+		_, claimed, err := d.config.DomainRegistry.Owner(domain)
+		if err != nil {
+			return false, err
+		}
+		if claimed {
+			return false, nil
+		}
+	}
 
 	return true, nil
 }
 
-// validateDomainFormat 验证域名格式
+func (d *domainAllocator) IsDomainAvailableForTenant(tenantID, domain string) (bool, error) {
+	if err := d.validateDomainFormat(domain); err != nil {
+		return false, err
+	}
+
+	if d.isReservedDomainForTenant(tenantID, domain) {
+		return false, nil
+	}
+
+	// 已被其他租户登记的域名不可用，租户自己登记的域名仍可使用
+	if d.config.DomainRegistry != nil {
+		owner, claimed, err := d.config.DomainRegistry.Owner(domain)
+		if err != nil {
+			return false, err
+		}
+		if claimed && owner != tenantID {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// NormalizeDomain 将 Unicode（IDN）域名转换为 ASCII/punycode 形式，纯 ASCII 域名原样返回
+func NormalizeDomain(domain string) (string, error) {
+	if isASCII(domain) {
+		return domain, nil
+	}
+
+	normalized, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized domain %s: %w", domain, err)
+	}
+	return normalized, nil
+}
+
+// isASCII 判断字符串是否只包含 ASCII 字符
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// validateDomainFormat 验证域名格式，Unicode 域名先转换为 punycode 形式
 func (d *domainAllocator) validateDomainFormat(domain string) error {
 	if domain == "" {
 		return fmt.Errorf("domain cannot be empty")
 	}
 
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return err
+	}
+
 	if len(domain) > 253 {
 		return fmt.Errorf("domain %s is too long (max 253 characters)", domain)
 	}
@@ -130,40 +315,111 @@ func (d *domainAllocator) validateDomainFormat(domain string) error {
 	return nil
 }
 
+// defaultReservedSubdomains 未配置 ReservedSubdomains 时使用的内置保留子域名
+var defaultReservedSubdomains = []string{
+	"api", "www", "mail", "ftp", "admin", "root", "system",
+	"console", "dashboard", "management", "cluster", "istio",
+	"kubernetes", "k8s", "sealos", "cloud",
+}
+
+// reservedSubdomains 返回生效的保留子域名列表，配置了 ReservedSubdomains 时完全替换内置列表
+func (d *domainAllocator) reservedSubdomains() []string {
+	if len(d.config.ReservedSubdomains) > 0 {
+		return d.config.ReservedSubdomains
+	}
+	return defaultReservedSubdomains
+}
+
 // isReservedDomain 检查是否为保留域名
 func (d *domainAllocator) isReservedDomain(domain string) bool {
+	return d.isReservedDomainForTenant("", domain)
+}
+
+// isReservedDomainForTenant 检查对指定租户是否为保留域名，
+// 租户放行的保留子域名只在 <子域名>.<tenantID>.<BaseDomain> 上生效，配置的保留域名不可放行
+func (d *domainAllocator) isReservedDomainForTenant(tenantID, domain string) bool {
+	domain = strings.ToLower(domain)
+
 	// 检查配置的保留域名
 	for _, reserved := range d.config.ReservedDomains {
+		reserved = strings.ToLower(reserved)
 		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
 			return true
 		}
 	}
 
-	// 检查常见的保留子域名
-	parts := strings.Split(domain, ".")
-	if len(parts) > 0 {
-		subdomain := parts[0]
-		reservedSubdomains := []string{
-			"api", "www", "mail", "ftp", "admin", "root", "system",
-			"console", "dashboard", "management", "cluster", "istio",
-			"kubernetes", "k8s", "sealos", "cloud",
+	// 检查保留子域名（第一级标签）
+	subdomain, _, _ := strings.Cut(domain, ".")
+	for _, reserved := range d.reservedSubdomains() {
+		if strings.EqualFold(subdomain, strings.TrimSpace(reserved)) {
+			return !d.isTenantReservedSubdomainAllowed(tenantID, domain, subdomain)
 		}
+	}
 
-		for _, reserved := range reservedSubdomains {
-			if subdomain == reserved {
-				return true
-			}
+	return false
+}
+
+// isTenantReservedSubdomainAllowed 判断租户是否可以在自己的基础域名下使用该保留子域名
+func (d *domainAllocator) isTenantReservedSubdomainAllowed(tenantID, domain, subdomain string) bool {
+	if tenantID == "" || d.config.BaseDomain == "" {
+		return false
+	}
+
+	tenantBaseDomain := strings.ToLower(d.sanitizeDomainPart(tenantID) + "." + d.config.BaseDomain)
+	if strings.ToLower(domain) != subdomain+"."+tenantBaseDomain {
+		return false
+	}
+
+	for _, allowed := range d.config.TenantReservedSubdomains[tenantID] {
+		if strings.EqualFold(allowed, subdomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldSkipDNSValidation 判断是否跳过 DNS 解析验证（split-horizon/内网域名只能在集群网络内解析）
+func (d *domainAllocator) shouldSkipDNSValidation(domain string) bool {
+	if d.config.SkipDNSValidation {
+		return true
+	}
+
+	domain = strings.ToLower(domain)
+	for _, suffix := range d.config.DNSValidationSkipSuffixes {
+		suffix = strings.Trim(strings.ToLower(strings.TrimSpace(suffix)), ".")
+		if suffix == "" {
+			continue
+		}
+		if domain == suffix || strings.HasSuffix(domain, "."+suffix) {
+			return true
 		}
 	}
 
 	return false
 }
 
-// validateDNSResolution 验证 DNS 解析
-func (d *domainAllocator) validateDNSResolution(domain string) error {
+// validateDNSResolution 验证 DNS 解析，超过 DNSValidationTimeout 时返回 ErrDNSValidationTimeout
+func (d *domainAllocator) validateDNSResolution(ctx context.Context, domain string) error {
+	lookupHost := d.lookupHost
+	if lookupHost == nil {
+		lookupHost = net.DefaultResolver.LookupHost
+	}
+
+	timeout := d.config.DNSValidationTimeout
+	if timeout <= 0 {
+		timeout = defaultDNSValidationTimeout
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// 检查域名是否可以解析
-	_, err := net.LookupHost(domain)
+	start := time.Now()
+	_, err := lookupHost(lookupCtx, domain)
+	observeDomainLookup(domainLookupDNS, start, err)
 	if err != nil {
+		if errors.Is(lookupCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s", ErrDNSValidationTimeout, timeout)
+		}
 		// DNS 解析失败通常意味着域名不存在或配置错误
 		return fmt.Errorf("DNS lookup failed: %w", err)
 	}
@@ -187,20 +443,29 @@ func (d *domainAllocator) isChinaDomain(domain string) bool {
 	return false
 }
 
-// validateICPRecord 验证 ICP 备案
-func (d *domainAllocator) validateICPRecord(domain string) error {
-	// 注意：实际的 ICP 验证需要调用相关的 API 服务
-	// 这里只是一个示例实现
-
-	// 对于中国的域名，通常需要有效的 ICP 备案号
-	// 这里可以集成第三方 ICP 查询 API
+// validateICPRecord 验证 ICP 备案，未配置 ICP 查询后端时跳过
+func (d *domainAllocator) validateICPRecord(ctx context.Context, domain string) error {
+	if d.icpValidator == nil {
+		return nil
+	}
 
-	// 暂时返回成功，实际实现时需要调用真实的 ICP 验证服务
+	registered, err := d.icpValidator.HasValidRecord(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return ErrICPRecordNotFound
+	}
 	return nil
 }
 
 // sanitizeDomainPart 清理域名部分
 func (d *domainAllocator) sanitizeDomainPart(part string) string {
+	// Unicode 标签转换为 punycode，避免被替换为连字符
+	if normalized, err := NormalizeDomain(part); err == nil {
+		part = normalized
+	}
+
 	// 移除非法字符
 	reg := regexp.MustCompile(`[^a-zA-Z0-9\-]`)
 	cleaned := reg.ReplaceAllString(part, "-")
@@ -221,11 +486,43 @@ func (d *domainAllocator) sanitizeDomainPart(part string) string {
 	return strings.ToLower(cleaned)
 }
 
-// generateShortHash 生成短哈希
+// 域名哈希算法
+const (
+	HashAlgorithmMD5    = "md5"
+	HashAlgorithmSHA256 = "sha256"
+)
+
+// 域名哈希长度（十六进制字符数）
+const (
+	defaultHashLength = 6
+	minHashLength     = 4
+	maxHashLength     = 32
+)
+
+// generateShortHash 生成短哈希，算法和长度由 HashAlgorithm/HashLength 配置，未配置时使用 md5 前 6 个字符
 func (d *domainAllocator) generateShortHash(input string) string {
-	hash := md5.Sum([]byte(input))
-	// 取前6个字符
-	return fmt.Sprintf("%x", hash)[:6]
+	var sum string
+	switch strings.ToLower(d.config.HashAlgorithm) {
+	case HashAlgorithmSHA256:
+		sum = fmt.Sprintf("%x", sha256.Sum256([]byte(input)))
+	default:
+		sum = fmt.Sprintf("%x", md5.Sum([]byte(input)))
+	}
+	return sum[:d.hashLength()]
+}
+
+// hashLength 返回生效的哈希长度，限制在 [minHashLength, maxHashLength] 范围内
+func (d *domainAllocator) hashLength() int {
+	length := d.config.HashLength
+	switch {
+	case length == 0:
+		return defaultHashLength
+	case length < minHashLength:
+		return minHashLength
+	case length > maxHashLength:
+		return maxHashLength
+	}
+	return length
 }
 
 // GetDomainForTerminal 为 Terminal 生成域名
@@ -236,6 +533,7 @@ func (d *domainAllocator) GetDomainForTerminal(tenantID, terminalID string) stri
 	}
 
 	hash := d.generateShortHash(tenantID + terminalID)
+	domainAllocationsTotal.WithLabelValues("terminal").Inc()
 
 	domain := template
 	domain = strings.ReplaceAll(domain, "{{.TenantID}}", d.sanitizeDomainPart(tenantID))
@@ -253,6 +551,7 @@ func (d *domainAllocator) GetDomainForDatabase(tenantID, dbName string) string {
 	}
 
 	hash := d.generateShortHash(tenantID + dbName)
+	domainAllocationsTotal.WithLabelValues("database").Inc()
 
 	domain := template
 	domain = strings.ReplaceAll(domain, "{{.TenantID}}", d.sanitizeDomainPart(tenantID))
@@ -17,15 +17,34 @@ limitations under the License.
 package istio
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
 
+// DomainClassificationTraceAnnotation VirtualService 上记录域名分类决策过程的调试注解
+const DomainClassificationTraceAnnotation = "network.sealos.io/domain-classification-trace"
+
+// PreviousNameAnnotation 应用重命名时在 AppNetworkingSpec 上标注旧的应用名称，用于迁移旧名称的 VirtualService
+const PreviousNameAnnotation = "network.sealos.io/previous-name"
+
+// GatewayOverrideAnnotation 将应用固定到指定 Gateway（namespace/name，省略 namespace 时为应用所在命名空间），
+// 设置后忽略域名分类结果，也不再创建应用专属 Gateway
+const GatewayOverrideAnnotation = "network.sealos.io/gateway-override"
+
+// GatewayDebugResponseHeader 调试模式下注入的响应头，标识处理请求的 Gateway
+const GatewayDebugResponseHeader = "X-Sealos-Gateway"
+
 // DomainClassifier 域名分类器
 type DomainClassifier struct {
+	baseDomain     string
 	publicDomains  []string
 	systemGateway  string
 	systemNamespace string
+	debugGatewayHeader bool
+	gatewayShards  int
+	namespaceBaseDomains map[string]string // 命名空间 -> 覆盖的基础域名
+	overrideAllowlist map[string]bool // gateway-override 允许指向的其他命名空间 Gateway
 }
 
 // NewDomainClassifier 创建域名分类器
@@ -50,10 +69,34 @@ func NewDomainClassifier(config *NetworkConfig) *DomainClassifier {
 	// 4. 添加保留域名（全部视为公共域名，无过滤）
 	publicDomains = append(publicDomains, config.ReservedDomains...)
 	
+	namespaceBaseDomains := make(map[string]string, len(config.NamespaceBaseDomains))
+	for namespace, baseDomain := range config.NamespaceBaseDomains {
+		if baseDomain = strings.ToLower(strings.TrimSpace(baseDomain)); baseDomain != "" {
+			namespaceBaseDomains[namespace] = baseDomain
+		}
+	}
+	
+	// gateway-override 只允许指向系统 Gateway（含分片）和明确配置的 Gateway
+	systemGateway := getSystemGateway(config)
+	overrideAllowlist := map[string]bool{systemGateway: true}
+	for shard := 0; shard < config.SharedGatewayShards; shard++ {
+		overrideAllowlist[fmt.Sprintf("%s-%d", systemGateway, shard)] = true
+	}
+	for _, gateway := range config.GatewayOverrideAllowlist {
+		if gateway = strings.TrimSpace(gateway); gateway != "" {
+			overrideAllowlist[gateway] = true
+		}
+	}
+	
 	return &DomainClassifier{
+		baseDomain:      strings.ToLower(config.BaseDomain),
 		publicDomains:   deduplicateSlice(publicDomains),
-		systemGateway:   getSystemGateway(config),
+		systemGateway:   systemGateway,
 		systemNamespace: getSystemNamespace(config),
+		debugGatewayHeader: config.DebugGatewayHeader,
+		gatewayShards:   config.SharedGatewayShards,
+		namespaceBaseDomains: namespaceBaseDomains,
+		overrideAllowlist: overrideAllowlist,
 	}
 }
 
@@ -74,6 +117,22 @@ func (dc *DomainClassifier) IsPublicDomain(host string) bool {
 	return false
 }
 
+// IsPublicDomainForNamespace 判断域名在指定命名空间内是否为公共域名，
+// 命名空间覆盖的基础域名及其子域名同样视为公共域名
+func (dc *DomainClassifier) IsPublicDomainForNamespace(namespace, host string) bool {
+	return dc.IsPublicDomain(host) || dc.matchesNamespaceBaseDomain(namespace, host)
+}
+
+// matchesNamespaceBaseDomain 判断域名是否为命名空间覆盖的基础域名或其子域名
+func (dc *DomainClassifier) matchesNamespaceBaseDomain(namespace, host string) bool {
+	baseDomain, ok := dc.namespaceBaseDomains[namespace]
+	if !ok || host == "" {
+		return false
+	}
+	host = strings.ToLower(host)
+	return host == baseDomain || strings.HasSuffix(host, "."+baseDomain)
+}
+
 // matchesDomainPattern 检查域名是否匹配域名模式（支持通配符）
 func (dc *DomainClassifier) matchesDomainPattern(host, pattern string) bool {
 	pattern = strings.ToLower(pattern)
@@ -105,6 +164,11 @@ func (dc *DomainClassifier) matchesDomainPattern(host, pattern string) bool {
 
 // ClassifyHosts 对主机列表进行分类
 func (dc *DomainClassifier) ClassifyHosts(hosts []string) *HostClassification {
+	return dc.ClassifyHostsForNamespace("", hosts)
+}
+
+// ClassifyHostsForNamespace 按命名空间（考虑覆盖的基础域名）对主机列表进行分类
+func (dc *DomainClassifier) ClassifyHostsForNamespace(namespace string, hosts []string) *HostClassification {
 	classification := &HostClassification{
 		PublicHosts:  []string{},
 		CustomHosts:  []string{},
@@ -114,7 +178,7 @@ func (dc *DomainClassifier) ClassifyHosts(hosts []string) *HostClassification {
 	}
 	
 	for _, host := range hosts {
-		if dc.IsPublicDomain(host) {
+		if dc.IsPublicDomainForNamespace(namespace, host) {
 			classification.PublicHosts = append(classification.PublicHosts, host)
 			classification.AllCustom = false
 		} else {
@@ -128,18 +192,128 @@ func (dc *DomainClassifier) ClassifyHosts(hosts []string) *HostClassification {
 	return classification
 }
 
+// HostTraceReason 主机分类原因
+type HostTraceReason string
+
+const (
+	// HostTraceReasonBaseDomain 匹配基础域名（或其子域名）
+	HostTraceReasonBaseDomain HostTraceReason = "base-domain"
+	// HostTraceReasonPattern 匹配公共域名、公共域名模式或保留域名
+	HostTraceReasonPattern HostTraceReason = "pattern"
+	// HostTraceReasonCustom 未匹配任何公共域名，视为自定义域名
+	HostTraceReasonCustom HostTraceReason = "custom"
+)
+
+// HostTrace 单个主机的分类决策记录
+type HostTrace struct {
+	Host           string          `json:"host"`
+	Reason         HostTraceReason `json:"reason"`
+	MatchedPattern string          `json:"matchedPattern,omitempty"`
+}
+
+// ClassifyHostsWithTrace 对主机列表进行分类，并返回每个主机的分类原因（用于调试）
+func (dc *DomainClassifier) ClassifyHostsWithTrace(hosts []string) (*HostClassification, []HostTrace) {
+	return dc.classifyHostsWithTrace("", hosts)
+}
+
+// classifyHostsWithTrace 按命名空间对主机列表进行分类并记录分类原因
+func (dc *DomainClassifier) classifyHostsWithTrace(namespace string, hosts []string) (*HostClassification, []HostTrace) {
+	classification := dc.ClassifyHostsForNamespace(namespace, hosts)
+	traces := make([]HostTrace, 0, len(hosts))
+
+	for _, host := range hosts {
+		traces = append(traces, dc.traceHost(namespace, host))
+	}
+
+	return classification, traces
+}
+
+// traceHost 记录单个主机的分类原因，匹配顺序与 IsPublicDomain 保持一致
+func (dc *DomainClassifier) traceHost(namespace, host string) HostTrace {
+	trace := HostTrace{Host: host, Reason: HostTraceReasonCustom}
+	if host == "" {
+		return trace
+	}
+
+	for _, domainPattern := range dc.publicDomains {
+		if !dc.matchesDomainPattern(host, domainPattern) {
+			continue
+		}
+
+		trace.MatchedPattern = domainPattern
+		if dc.baseDomain != "" && (strings.ToLower(domainPattern) == dc.baseDomain || strings.ToLower(domainPattern) == "."+dc.baseDomain) {
+			trace.Reason = HostTraceReasonBaseDomain
+		} else {
+			trace.Reason = HostTraceReasonPattern
+		}
+		return trace
+	}
+
+	if dc.matchesNamespaceBaseDomain(namespace, host) {
+		trace.Reason = HostTraceReasonBaseDomain
+		trace.MatchedPattern = dc.namespaceBaseDomains[namespace]
+	}
+
+	return trace
+}
+
+// encodeHostTraces 将分类记录编码为注解值
+func encodeHostTraces(traces []HostTrace) string {
+	data, err := json.Marshal(traces)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// gatewayOverride 返回注解指定的 Gateway 引用（补全命名空间），未设置时返回空
+func gatewayOverride(annotations map[string]string, namespace string) string {
+	override := strings.TrimSpace(annotations[GatewayOverrideAnnotation])
+	if override == "" {
+		return ""
+	}
+	if !strings.Contains(override, "/") {
+		override = namespace + "/" + override
+	}
+	return override
+}
+
+// IsGatewayOverrideAllowed 判断应用是否可以固定到指定 Gateway：
+// 只允许应用所在命名空间的 Gateway、系统 Gateway（含分片）和 GatewayOverrideAllowlist 中的 Gateway，
+// 避免租户把 VirtualService 绑定到其他租户的 Gateway
+func (dc *DomainClassifier) IsGatewayOverrideAllowed(namespace, gateway string) bool {
+	if strings.HasPrefix(gateway, namespace+"/") {
+		return true
+	}
+	return dc.overrideAllowlist[gateway]
+}
+
+// allowedGatewayOverride 返回允许使用的注解 Gateway，未设置或不允许时返回空（按域名分类处理）
+func (dc *DomainClassifier) allowedGatewayOverride(annotations map[string]string, namespace string) string {
+	override := gatewayOverride(annotations, namespace)
+	if override == "" || !dc.IsGatewayOverrideAllowed(namespace, override) {
+		return ""
+	}
+	return override
+}
+
 // ShouldCreateGateway 判断是否需要创建Gateway
 func (dc *DomainClassifier) ShouldCreateGateway(spec *AppNetworkingSpec) bool {
+	// 固定到指定 Gateway 时不创建应用专属 Gateway
+	if dc.allowedGatewayOverride(spec.Annotations, spec.Namespace) != "" {
+		return false
+	}
+	
 	// 用户明确指定了TLS配置且使用自定义域名
 	if spec.TLSConfig != nil {
-		classification := dc.ClassifyHosts(spec.TLSConfig.Hosts)
+		classification := dc.ClassifyHostsForNamespace(spec.Namespace, spec.TLSConfig.Hosts)
 		if len(classification.CustomHosts) > 0 {
 			return true
 		}
 	}
 	
 	// 分类主机
-	classification := dc.ClassifyHosts(spec.Hosts)
+	classification := dc.ClassifyHostsForNamespace(spec.Namespace, spec.Hosts)
 	
 	// 如果有自定义域名，需要创建Gateway
 	if len(classification.CustomHosts) > 0 {
@@ -152,36 +326,44 @@ func (dc *DomainClassifier) ShouldCreateGateway(spec *AppNetworkingSpec) bool {
 
 // GetGatewayReference 获取Gateway引用
 func (dc *DomainClassifier) GetGatewayReference(spec *AppNetworkingSpec) string {
+	// 注解固定的 Gateway 优先
+	if override := dc.allowedGatewayOverride(spec.Annotations, spec.Namespace); override != "" {
+		return override
+	}
+	
 	// 检查是否需要创建自定义Gateway
 	if dc.ShouldCreateGateway(spec) {
 		return fmt.Sprintf("%s/%s-gateway", spec.Namespace, spec.Name)
 	}
 	
 	// 使用系统Gateway
-	return dc.systemGateway
+	return dc.sharedGateway(spec.Namespace)
 }
 
-// GetGatewayReferencesForHosts 为不同类型的主机获取Gateway引用
-func (dc *DomainClassifier) GetGatewayReferencesForHosts(hosts []string, appName string) []string {
-	classification := dc.ClassifyHosts(hosts)
-	gateways := []string{}
-	
-	// 公共域名使用系统Gateway
-	if len(classification.PublicHosts) > 0 {
-		gateways = append(gateways, dc.systemGateway)
-	}
-	
-	// 自定义域名使用应用Gateway
-	if len(classification.CustomHosts) > 0 {
-		gateways = append(gateways, fmt.Sprintf("%s-gateway", appName))
-	}
-	
-	return gateways
+// sharedGateway 返回租户所在分片的系统Gateway
+func (dc *DomainClassifier) sharedGateway(namespace string) string {
+	return ShardedGatewayName(dc.systemGateway, namespace, dc.gatewayShards)
 }
 
 // BuildOptimizedGatewayConfig 构建优化的Gateway配置
 func (dc *DomainClassifier) BuildOptimizedGatewayConfig(spec *AppNetworkingSpec) *GatewayConfig {
-	classification := dc.ClassifyHosts(spec.Hosts)
+	// 固定到指定 Gateway 时不创建应用专属 Gateway
+	if dc.allowedGatewayOverride(spec.Annotations, spec.Namespace) != "" {
+		return nil
+	}
+	
+	// TCP 端口无法复用共享 Gateway，总是创建只暴露该端口的应用 Gateway
+	if spec.Protocol == ProtocolTCP {
+		return &GatewayConfig{
+			Name:      fmt.Sprintf("%s-gateway", spec.Name),
+			Namespace: spec.Namespace,
+			Hosts:     spec.Hosts,
+			Labels:    buildGatewayLabels(spec, "tcp"),
+			TCPPort:   spec.TCPPort,
+		}
+	}
+	
+	classification := dc.ClassifyHostsForNamespace(spec.Namespace, spec.Hosts)
 	
 	// 只为自定义域名创建Gateway
 	if len(classification.CustomHosts) == 0 {
@@ -200,7 +382,7 @@ func (dc *DomainClassifier) BuildOptimizedGatewayConfig(spec *AppNetworkingSpec)
 	if spec.TLSConfig != nil {
 		customTLSHosts := []string{}
 		for _, host := range spec.TLSConfig.Hosts {
-			if !dc.IsPublicDomain(host) {
+			if !dc.IsPublicDomainForNamespace(spec.Namespace, host) {
 				customTLSHosts = append(customTLSHosts, host)
 			}
 		}
@@ -218,16 +400,24 @@ func (dc *DomainClassifier) BuildOptimizedGatewayConfig(spec *AppNetworkingSpec)
 
 // BuildOptimizedVirtualServiceConfig 构建优化的VirtualService配置
 func (dc *DomainClassifier) BuildOptimizedVirtualServiceConfig(spec *AppNetworkingSpec) *VirtualServiceConfig {
-	classification := dc.ClassifyHosts(spec.Hosts)
+	classification, traces := dc.classifyHostsWithTrace(spec.Namespace, spec.Hosts)
 	
 	// 智能选择Gateway
 	gateways := []string{}
 	if len(classification.PublicHosts) > 0 {
-		gateways = append(gateways, dc.systemGateway)
+		gateways = append(gateways, dc.sharedGateway(spec.Namespace))
 	}
 	if len(classification.CustomHosts) > 0 {
 		gateways = append(gateways, fmt.Sprintf("%s/%s-gateway", spec.Namespace, spec.Name))
 	}
+	// TCP 路由只能经过应用 Gateway 上暴露的端口
+	if spec.Protocol == ProtocolTCP {
+		gateways = []string{fmt.Sprintf("%s/%s-gateway", spec.Namespace, spec.Name)}
+	}
+	// 注解固定的 Gateway 覆盖分类结果
+	if override := dc.allowedGatewayOverride(spec.Annotations, spec.Namespace); override != "" {
+		gateways = []string{override}
+	}
 	
 	config := &VirtualServiceConfig{
 		Name:            fmt.Sprintf("%s-vs", spec.Name),
@@ -237,12 +427,19 @@ func (dc *DomainClassifier) BuildOptimizedVirtualServiceConfig(spec *AppNetworki
 		Protocol:        spec.Protocol,
 		ServiceName:     spec.ServiceName,
 		ServicePort:     spec.ServicePort,
+		TCPPort:         spec.TCPPort,
 		Timeout:         spec.Timeout,
 		Retries:         spec.Retries,
 		CorsPolicy:      spec.CorsPolicy,
 		Headers:         spec.Headers,
-		ResponseHeaders: spec.ResponseHeaders, // 添加响应头部支持
+		ResponseHeaders: dc.buildResponseHeaders(spec.ResponseHeaders, gateways), // 添加响应头部支持
 		Labels:          buildVirtualServiceLabels(spec, classification),
+		Annotations:     buildVirtualServiceAnnotations(traces),
+	}
+	
+	// 应用重命名：迁移旧名称的 VirtualService
+	if previous := spec.Annotations[PreviousNameAnnotation]; previous != "" && previous != spec.Name {
+		config.PreviousName = fmt.Sprintf("%s-vs", previous)
 	}
 	
 	return config
@@ -324,10 +521,29 @@ func buildVirtualServiceLabels(spec *AppNetworkingSpec, classification *HostClas
 	return labels
 }
 
+// buildVirtualServiceAnnotations 构建VirtualService注解（记录域名分类决策，便于排查Gateway选择）
+func buildVirtualServiceAnnotations(traces []HostTrace) map[string]string {
+	annotations := make(map[string]string)
+	if trace := encodeHostTraces(traces); trace != "" {
+		annotations[DomainClassificationTraceAnnotation] = trace
+	}
+	return annotations
+}
+
+// buildResponseHeaders 构建响应头部，调试模式下追加服务 Gateway 标识
+func (dc *DomainClassifier) buildResponseHeaders(headers map[string]string, gateways []string) map[string]string {
+	if !dc.debugGatewayHeader || len(gateways) == 0 {
+		return headers
+	}
+	return MergeLabels(headers, map[string]string{
+		GatewayDebugResponseHeader: strings.Join(gateways, ","),
+	})
+}
+
 // ValidateCustomDomainCertificates 验证自定义域名的证书配置
 func (dc *DomainClassifier) ValidateCustomDomainCertificates(spec *AppNetworkingSpec) error {
 	// 首先检查是否有自定义域名
-	classification := dc.ClassifyHosts(spec.Hosts)
+	classification := dc.ClassifyHostsForNamespace(spec.Namespace, spec.Hosts)
 	
 	// 如果没有自定义域名，不需要验证
 	if len(classification.CustomHosts) == 0 {
@@ -350,7 +566,7 @@ func (dc *DomainClassifier) ValidateCustomDomainCertificates(spec *AppNetworking
 	}
 	
 	// 验证TLS hosts必须覆盖所有自定义域名
-	tlsClassification := dc.ClassifyHosts(spec.TLSConfig.Hosts)
+	tlsClassification := dc.ClassifyHostsForNamespace(spec.Namespace, spec.TLSConfig.Hosts)
 	missingHosts := []string{}
 	for _, customHost := range classification.CustomHosts {
 		found := false
@@ -460,6 +676,16 @@ func ValidateNetworkConfig(config *NetworkConfig) error {
 		}
 	}
 	
+	// 验证 Gateway TLS 配置
+	if err := validateTLSOptions(config.GatewayTLSMinProtocolVersion, config.GatewayTLSCipherSuites); err != nil {
+		return err
+	}
+	
+	// 验证采样决策
+	if config.TraceSamplingDecision != "" && config.TraceSamplingDecision != "0" && config.TraceSamplingDecision != "1" {
+		return fmt.Errorf("invalid trace sampling decision '%s': must be \"0\" or \"1\"", config.TraceSamplingDecision)
+	}
+	
 	return nil
 }
 
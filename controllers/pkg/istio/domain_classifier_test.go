@@ -515,4 +515,265 @@ func TestDomainClassifier_ValidateCustomDomainCertificates(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestDomainClassifier_ClassifyHostsWithTrace(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		PublicDomains:        []string{"public.example.com"},
+		PublicDomainPatterns: []string{"*.apps.example.org"},
+	}
+
+	dc := NewDomainClassifier(config)
+
+	tests := []struct {
+		name            string
+		host            string
+		expectedReason  HostTraceReason
+		expectedPattern string
+	}{
+		{
+			name:            "exact base domain",
+			host:            "cloud.sealos.io",
+			expectedReason:  HostTraceReasonBaseDomain,
+			expectedPattern: "cloud.sealos.io",
+		},
+		{
+			name:            "subdomain of base domain",
+			host:            "app.cloud.sealos.io",
+			expectedReason:  HostTraceReasonBaseDomain,
+			expectedPattern: "cloud.sealos.io",
+		},
+		{
+			name:            "explicit public domain",
+			host:            "public.example.com",
+			expectedReason:  HostTraceReasonPattern,
+			expectedPattern: "public.example.com",
+		},
+		{
+			name:            "wildcard pattern",
+			host:            "foo.apps.example.org",
+			expectedReason:  HostTraceReasonPattern,
+			expectedPattern: "*.apps.example.org",
+		},
+		{
+			name:           "custom domain",
+			host:           "www.custom.com",
+			expectedReason: HostTraceReasonCustom,
+		},
+	}
+
+	hosts := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		hosts = append(hosts, tt.host)
+	}
+
+	classification, traces := dc.ClassifyHostsWithTrace(hosts)
+	if len(traces) != len(tests) {
+		t.Fatalf("traces count = %d, want %d", len(traces), len(tests))
+	}
+	if !classification.Mixed {
+		t.Errorf("classification.Mixed = false, want true")
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trace := traces[i]
+			if trace.Host != tt.host {
+				t.Errorf("trace.Host = %s, want %s", trace.Host, tt.host)
+			}
+			if trace.Reason != tt.expectedReason {
+				t.Errorf("trace.Reason = %s, want %s", trace.Reason, tt.expectedReason)
+			}
+			if trace.MatchedPattern != tt.expectedPattern {
+				t.Errorf("trace.MatchedPattern = %s, want %s", trace.MatchedPattern, tt.expectedPattern)
+			}
+		})
+	}
+}
+
+func TestDomainClassifier_BuildOptimizedVirtualServiceConfig_TraceAnnotation(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+	}
+
+	dc := NewDomainClassifier(config)
+	result := dc.BuildOptimizedVirtualServiceConfig(&AppNetworkingSpec{
+		Name:        "app1",
+		Namespace:   "ns1",
+		Hosts:       []string{"app.cloud.sealos.io", "custom.com"},
+		ServiceName: "app1-svc",
+		ServicePort: 8080,
+		Protocol:    ProtocolHTTP,
+	})
+
+	trace := result.Annotations[DomainClassificationTraceAnnotation]
+	if trace == "" {
+		t.Fatalf("annotation %s not set", DomainClassificationTraceAnnotation)
+	}
+	if !strings.Contains(trace, `"host":"custom.com","reason":"custom"`) {
+		t.Errorf("trace annotation = %s, want custom.com marked as custom", trace)
+	}
+	if !strings.Contains(trace, `"host":"app.cloud.sealos.io","reason":"base-domain"`) {
+		t.Errorf("trace annotation = %s, want app.cloud.sealos.io marked as base-domain", trace)
+	}
+}
+
+func TestDomainClassifier_BuildOptimizedVirtualServiceConfig_DebugGatewayHeader(t *testing.T) {
+	spec := &AppNetworkingSpec{
+		Name:            "app1",
+		Namespace:       "ns1",
+		Hosts:           []string{"app.cloud.sealos.io", "custom.com"},
+		ServiceName:     "app1-svc",
+		ServicePort:     8080,
+		Protocol:        ProtocolHTTP,
+		ResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+	}
+
+	tests := []struct {
+		name         string
+		debug        bool
+		expectHeader string
+	}{
+		{name: "debug enabled", debug: true, expectHeader: "istio-system/sealos-gateway,ns1/app1-gateway"},
+		{name: "debug disabled", debug: false, expectHeader: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &NetworkConfig{
+				BaseDomain:         "cloud.sealos.io",
+				DefaultGateway:     "istio-system/sealos-gateway",
+				DebugGatewayHeader: tt.debug,
+			}
+
+			vsConfig := NewDomainClassifier(config).BuildOptimizedVirtualServiceConfig(spec)
+			controller := &virtualServiceController{config: config}
+			vsSpec := controller.buildVirtualServiceSpec(vsConfig)
+
+			route := vsSpec["http"].([]interface{})[0].(map[string]interface{})
+			headers := route["headers"].(map[string]interface{})
+			set := headers["response"].(map[string]interface{})["set"].(map[string]string)
+
+			got, ok := set[GatewayDebugResponseHeader]
+			if tt.expectHeader == "" {
+				if ok {
+					t.Errorf("response header %s = %q, want absent", GatewayDebugResponseHeader, got)
+				}
+			} else if got != tt.expectHeader {
+				t.Errorf("response header %s = %q, want %q", GatewayDebugResponseHeader, got, tt.expectHeader)
+			}
+			if set["X-Frame-Options"] != "DENY" {
+				t.Errorf("existing response headers not preserved: %v", set)
+			}
+		})
+	}
+
+	if _, ok := spec.ResponseHeaders[GatewayDebugResponseHeader]; ok {
+		t.Error("spec.ResponseHeaders should not be mutated")
+	}
+}
+
+func TestDomainClassifier_NamespaceBaseDomain(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+		NamespaceBaseDomains: map[string]string{
+			"ns-tenanta": "brand1.com",
+			"ns-tenantb": "Brand2.com",
+		},
+	}
+	dc := NewDomainClassifier(config)
+
+	tests := []struct {
+		name      string
+		namespace string
+		host      string
+		expected  bool
+	}{
+		{name: "shared base domain", namespace: "ns-tenanta", host: "app.cloud.sealos.io", expected: true},
+		{name: "overridden base domain", namespace: "ns-tenanta", host: "app.tenanta.brand1.com", expected: true},
+		{name: "overridden base domain itself", namespace: "ns-tenanta", host: "brand1.com", expected: true},
+		{name: "overridden base domain is case insensitive", namespace: "ns-tenantb", host: "APP.brand2.com", expected: true},
+		{name: "other namespace brand is custom", namespace: "ns-tenantb", host: "app.brand1.com", expected: false},
+		{name: "namespace without override", namespace: "ns-other", host: "app.brand1.com", expected: false},
+		{name: "suffix without dot is custom", namespace: "ns-tenanta", host: "evilbrand1.com", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dc.IsPublicDomainForNamespace(tt.namespace, tt.host); got != tt.expected {
+				t.Errorf("IsPublicDomainForNamespace(%s, %s) = %v, want %v", tt.namespace, tt.host, got, tt.expected)
+			}
+		})
+	}
+
+	if dc.IsPublicDomain("app.brand1.com") {
+		t.Errorf("IsPublicDomain() without namespace should not treat overridden base domains as public")
+	}
+
+	// 覆盖的基础域名使用系统 Gateway，不创建应用 Gateway
+	spec := &AppNetworkingSpec{
+		Name:        "app",
+		Namespace:   "ns-tenanta",
+		Hosts:       []string{"app.tenanta.brand1.com"},
+		ServiceName: "app-svc",
+		ServicePort: 8080,
+		Protocol:    ProtocolHTTP,
+	}
+	if dc.ShouldCreateGateway(spec) {
+		t.Errorf("ShouldCreateGateway() = true, want false for the namespace base domain")
+	}
+	vsConfig := dc.BuildOptimizedVirtualServiceConfig(spec)
+	if trace := vsConfig.Annotations[DomainClassificationTraceAnnotation]; !strings.Contains(trace, `"reason":"base-domain","matchedPattern":"brand1.com"`) {
+		t.Errorf("trace annotation = %s, want host marked as base-domain", trace)
+	}
+}
+
+func TestDomainClassifier_GatewayOverrideAllowlist(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:               "cloud.sealos.io",
+		DefaultGateway:           "istio-system/sealos-gateway",
+		SharedGatewayShards:      2,
+		GatewayOverrideAllowlist: []string{" istio-system/isolated-gateway "},
+	}
+	dc := NewDomainClassifier(config)
+
+	tests := []struct {
+		name     string
+		gateway  string
+		expected bool
+	}{
+		{name: "own namespace", gateway: "ns1/custom-gateway", expected: true},
+		{name: "system gateway", gateway: "istio-system/sealos-gateway", expected: true},
+		{name: "system gateway shard", gateway: "istio-system/sealos-gateway-1", expected: true},
+		{name: "shard out of range", gateway: "istio-system/sealos-gateway-2", expected: false},
+		{name: "allowlisted gateway", gateway: "istio-system/isolated-gateway", expected: true},
+		{name: "other tenant", gateway: "ns2/custom-gateway", expected: false},
+		{name: "namespace prefix of other tenant", gateway: "ns10/custom-gateway", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dc.IsGatewayOverrideAllowed("ns1", tt.gateway); got != tt.expected {
+				t.Errorf("IsGatewayOverrideAllowed(ns1, %s) = %v, want %v", tt.gateway, got, tt.expected)
+			}
+		})
+	}
+
+	// 不允许的注解被忽略，按域名分类选择 Gateway
+	spec := &AppNetworkingSpec{
+		Name:        "app1",
+		Namespace:   "ns1",
+		Hosts:       []string{"custom.com"},
+		Annotations: map[string]string{GatewayOverrideAnnotation: "ns2/custom-gateway"},
+	}
+	if got := dc.GetGatewayReference(spec); got != "ns1/app1-gateway" {
+		t.Errorf("GetGatewayReference() = %s, want ns1/app1-gateway", got)
+	}
+	if dc.BuildOptimizedGatewayConfig(spec) == nil {
+		t.Error("BuildOptimizedGatewayConfig() = nil, want dedicated gateway when the override is rejected")
+	}
+}
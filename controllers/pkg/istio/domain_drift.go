@@ -0,0 +1,75 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DomainDriftPolicy VirtualService 域名被手动修改后的处理策略
+type DomainDriftPolicy string
+
+const (
+	// DomainDriftEnforce 将 VirtualService 域名改回期望值（默认）
+	DomainDriftEnforce DomainDriftPolicy = "enforce"
+	// DomainDriftObserve 保留手动修改，以 VirtualService 实际域名更新 Status.Domain
+	DomainDriftObserve DomainDriftPolicy = "observe"
+)
+
+// ReconcileDomainDrift 比较应用 VirtualService 的实际域名与期望域名，返回 Status.Domain 应使用的域名。
+// enforce 策略下将 VirtualService 改回 desiredHost；observe 策略下返回 VirtualService 当前的域名。
+// VirtualService 不存在或没有漂移时直接返回 desiredHost。
+func (h *UniversalIstioNetworkingHelper) ReconcileDomainDrift(
+	ctx context.Context,
+	name, namespace, desiredHost string,
+) (string, error) {
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(virtualServiceGVK)
+	key := client.ObjectKey{Name: fmt.Sprintf("%s-vs", name), Namespace: namespace}
+	if err := h.client.Get(ctx, key, vs); err != nil {
+		if apierrors.IsNotFound(err) {
+			return desiredHost, nil
+		}
+		return "", fmt.Errorf("failed to get virtualservice %s: %w", key, err)
+	}
+
+	liveHosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts")
+	if len(liveHosts) == 0 || liveHosts[0] == desiredHost {
+		return desiredHost, nil
+	}
+
+	logger := log.FromContext(ctx).WithValues("virtualservice", key, "desiredHost", desiredHost, "liveHost", liveHosts[0])
+	if h.config.DomainDriftPolicy == DomainDriftObserve {
+		logger.Info("virtualservice host drifted, keeping live host")
+		return liveHosts[0], nil
+	}
+
+	logger.Info("virtualservice host drifted, restoring desired host")
+	if err := unstructured.SetNestedStringSlice(vs.Object, []string{desiredHost}, "spec", "hosts"); err != nil {
+		return "", fmt.Errorf("failed to set virtualservice hosts: %w", err)
+	}
+	if err := h.client.Update(ctx, vs); err != nil {
+		return "", fmt.Errorf("failed to restore virtualservice %s host: %w", key, err)
+	}
+	return desiredHost, nil
+}
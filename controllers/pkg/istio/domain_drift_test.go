@@ -0,0 +1,104 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUniversalIstioNetworkingHelper_ReconcileDomainDrift(t *testing.T) {
+	const desiredHost = "terminal-abc.ns-test.cloud.sealos.io"
+
+	tests := []struct {
+		name       string
+		policy     DomainDriftPolicy
+		existing   []client.Object
+		wantHost   string
+		wantVSHost string
+	}{
+		{
+			name:       "enforce restores drifted host",
+			policy:     DomainDriftEnforce,
+			existing:   []client.Object{newTestManagedVirtualService("test-app-vs", "ns-test", "manual.example.com")},
+			wantHost:   desiredHost,
+			wantVSHost: desiredHost,
+		},
+		{
+			name:       "empty policy defaults to enforce",
+			existing:   []client.Object{newTestManagedVirtualService("test-app-vs", "ns-test", "manual.example.com")},
+			wantHost:   desiredHost,
+			wantVSHost: desiredHost,
+		},
+		{
+			name:       "observe keeps drifted host and reports it",
+			policy:     DomainDriftObserve,
+			existing:   []client.Object{newTestManagedVirtualService("test-app-vs", "ns-test", "manual.example.com")},
+			wantHost:   "manual.example.com",
+			wantVSHost: "manual.example.com",
+		},
+		{
+			name:       "no drift",
+			policy:     DomainDriftObserve,
+			existing:   []client.Object{newTestManagedVirtualService("test-app-vs", "ns-test", desiredHost)},
+			wantHost:   desiredHost,
+			wantVSHost: desiredHost,
+		},
+		{
+			name:     "virtualservice not created yet",
+			policy:   DomainDriftEnforce,
+			wantHost: desiredHost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(tt.existing...).Build()
+			helper := NewUniversalIstioNetworkingHelper(c, &NetworkConfig{
+				BaseDomain:        "cloud.sealos.io",
+				DefaultGateway:    "istio-system/sealos-gateway",
+				DomainDriftPolicy: tt.policy,
+			}, "terminal")
+
+			host, err := helper.ReconcileDomainDrift(context.Background(), "test-app", "ns-test", desiredHost)
+			if err != nil {
+				t.Fatalf("ReconcileDomainDrift() error = %v", err)
+			}
+			if host != tt.wantHost {
+				t.Errorf("ReconcileDomainDrift() = %s, want %s", host, tt.wantHost)
+			}
+
+			if tt.wantVSHost == "" {
+				return
+			}
+			vs, found := getTestVirtualService(t, c, "test-app-vs", "ns-test")
+			if !found {
+				t.Fatal("virtualservice should exist")
+			}
+			hosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts")
+			if strings.Join(hosts, ",") != tt.wantVSHost {
+				t.Errorf("virtualservice hosts = %v, want [%s]", hosts, tt.wantVSHost)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 域名验证失败原因
+const (
+	DomainValidationReasonFormat   = "format"
+	DomainValidationReasonReserved = "reserved"
+	DomainValidationReasonDNS      = "dns"
+	DomainValidationReasonICP      = "icp"
+)
+
+// 域名查询类型
+const (
+	domainLookupDNS = "dns"
+	domainLookupCAA = "caa"
+	domainLookupTXT = "txt"
+)
+
+// 域名分配与验证指标，由 RegisterDomainMetrics 显式注册
+var (
+	domainAllocationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sealos_istio_domain_allocations_total",
+			Help: "域名分配次数",
+		},
+		[]string{"type"},
+	)
+
+	domainValidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sealos_istio_domain_validations_total",
+			Help: "自定义域名验证次数",
+		},
+		[]string{"result"},
+	)
+
+	domainValidationFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sealos_istio_domain_validation_failures_total",
+			Help: "自定义域名验证失败次数（按原因）",
+		},
+		[]string{"reason"},
+	)
+
+	domainLookupDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sealos_istio_domain_lookup_duration_seconds",
+			Help:    "域名 DNS/CAA/TXT 查询耗时",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"type", "result"},
+	)
+)
+
+// RegisterDomainMetrics 注册域名分配与验证指标，重复注册（多个控制器共用同一进程）时忽略
+func RegisterDomainMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{domainAllocationsTotal, domainValidationsTotal, domainValidationFailuresTotal, domainLookupDuration} {
+		if err := registerer.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+				continue
+			}
+			return fmt.Errorf("failed to register domain metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordDomainValidation 记录一次自定义域名验证结果，reason 为空表示验证通过
+func recordDomainValidation(reason string) {
+	if reason == "" {
+		domainValidationsTotal.WithLabelValues("success").Inc()
+		return
+	}
+	domainValidationsTotal.WithLabelValues("failure").Inc()
+	domainValidationFailuresTotal.WithLabelValues(reason).Inc()
+}
+
+// observeDomainLookup 记录一次 DNS/CAA/TXT 查询的耗时
+func observeDomainLookup(lookupType string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	domainLookupDuration.WithLabelValues(lookupType, result).Observe(time.Since(start).Seconds())
+}
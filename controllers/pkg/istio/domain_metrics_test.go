@@ -0,0 +1,122 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// lookupCount 返回查询耗时直方图中指定标签的样本数
+func lookupCount(t *testing.T, lookupType, result string) uint64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	if err := domainLookupDuration.WithLabelValues(lookupType, result).(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatalf("failed to read lookup histogram: %v", err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestDomainMetrics_ValidationReasons(t *testing.T) {
+	d := &domainAllocator{
+		config: &NetworkConfig{
+			BaseDomain:      "cloud.sealos.io",
+			ReservedDomains: []string{"internal.example.com"},
+		},
+		lookupHost: func(_ context.Context, host string) ([]string, error) {
+			if host == "missing.example.com" {
+				return nil, errors.New("no such host")
+			}
+			return []string{"203.0.113.10"}, nil
+		},
+	}
+
+	reasons := []string{DomainValidationReasonFormat, DomainValidationReasonReserved, DomainValidationReasonDNS, DomainValidationReasonICP}
+	before := map[string]float64{}
+	for _, reason := range reasons {
+		before[reason] = testutil.ToFloat64(domainValidationFailuresTotal.WithLabelValues(reason))
+	}
+	successBefore := testutil.ToFloat64(domainValidationsTotal.WithLabelValues("success"))
+	failureBefore := testutil.ToFloat64(domainValidationsTotal.WithLabelValues("failure"))
+	dnsSuccessBefore := lookupCount(t, domainLookupDNS, "success")
+	dnsErrorBefore := lookupCount(t, domainLookupDNS, "error")
+
+	domains := []string{
+		"app.example.com",         // 通过
+		"shop.example.org",        // 通过
+		"bad_domain!.com",         // 格式错误
+		"-leading.example.com",    // 格式错误
+		"api.example.com",         // 保留子域名
+		"db.internal.example.com", // 配置的保留域名
+		"missing.example.com",     // DNS 解析失败
+	}
+	for _, domain := range domains {
+		_ = d.ValidateCustomDomain(context.Background(), domain)
+	}
+
+	want := map[string]float64{
+		DomainValidationReasonFormat:   2,
+		DomainValidationReasonReserved: 2,
+		DomainValidationReasonDNS:      1,
+		DomainValidationReasonICP:      0,
+	}
+	for _, reason := range reasons {
+		if got := testutil.ToFloat64(domainValidationFailuresTotal.WithLabelValues(reason)) - before[reason]; got != want[reason] {
+			t.Errorf("failures{reason=%q} increased by %v, want %v", reason, got, want[reason])
+		}
+	}
+	if got := testutil.ToFloat64(domainValidationsTotal.WithLabelValues("success")) - successBefore; got != 2 {
+		t.Errorf("validations{result=success} increased by %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(domainValidationsTotal.WithLabelValues("failure")) - failureBefore; got != 5 {
+		t.Errorf("validations{result=failure} increased by %v, want 5", got)
+	}
+	// 只有通过格式和保留域名检查的域名才会查询 DNS
+	if got := lookupCount(t, domainLookupDNS, "success") - dnsSuccessBefore; got != 2 {
+		t.Errorf("dns lookups{result=success} increased by %d, want 2", got)
+	}
+	if got := lookupCount(t, domainLookupDNS, "error") - dnsErrorBefore; got != 1 {
+		t.Errorf("dns lookups{result=error} increased by %d, want 1", got)
+	}
+}
+
+func TestDomainMetrics_Allocations(t *testing.T) {
+	d := &domainAllocator{config: &NetworkConfig{BaseDomain: "cloud.sealos.io"}}
+	before := testutil.ToFloat64(domainAllocationsTotal.WithLabelValues("app"))
+
+	for _, app := range []string{"web", "api", "docs"} {
+		d.GenerateAppDomain("ns-test", app)
+	}
+
+	if got := testutil.ToFloat64(domainAllocationsTotal.WithLabelValues("app")) - before; got != 3 {
+		t.Errorf("allocations{type=app} increased by %v, want 3", got)
+	}
+}
+
+func TestRegisterDomainMetrics_Idempotent(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	for i := 0; i < 2; i++ {
+		if err := RegisterDomainMetrics(registry); err != nil {
+			t.Fatalf("RegisterDomainMetrics() call %d error = %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// DomainOwnershipChallengeLabel 域名所有权验证 TXT 记录所在的子域名标签
+const DomainOwnershipChallengeLabel = "_sealos-challenge"
+
+// domainOwnershipTokenPrefix 所有权验证令牌前缀
+const domainOwnershipTokenPrefix = "sealos-verification="
+
+// TXTResolver TXT 记录解析器，*net.Resolver 满足该接口
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// GenerateDomainOwnershipToken 根据租户 ID 生成确定的所有权验证令牌，
+// 用户需要在 _sealos-challenge.<domain> 上添加值为该令牌的 TXT 记录
+func GenerateDomainOwnershipToken(tenantID string) string {
+	sum := sha256.Sum256([]byte("sealos-domain-ownership:" + tenantID))
+	return fmt.Sprintf("%s%x", domainOwnershipTokenPrefix, sum[:16])
+}
+
+// DomainOwnershipChallengeName 返回域名所有权验证 TXT 记录的完整名称
+func DomainOwnershipChallengeName(domain string) string {
+	domain = strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(domain), "*."), ".")
+	return DomainOwnershipChallengeLabel + "." + domain
+}
+
+// VerifyDomainOwnership 查询 _sealos-challenge.<domain> 的 TXT 记录，任一记录等于 expectedToken 时返回 true，
+// 没有 TXT 记录时返回 false 且不返回错误
+func (d *domainAllocator) VerifyDomainOwnership(ctx context.Context, domain, expectedToken string) (bool, error) {
+	if expectedToken == "" {
+		return false, errors.New("expected ownership token cannot be empty")
+	}
+
+	resolver := d.txtResolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	name := DomainOwnershipChallengeName(domain)
+	start := time.Now()
+	records, err := resolver.LookupTXT(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		err, records = nil, nil
+	}
+	observeDomainLookup(domainLookupTXT, start, err)
+	if err != nil {
+		return false, fmt.Errorf("TXT lookup failed for %s: %w", name, err)
+	}
+
+	for _, record := range records {
+		if strings.TrimSpace(record) == expectedToken {
+			return true, nil
+		}
+	}
+	return false, nil
+}
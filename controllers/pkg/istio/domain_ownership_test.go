@@ -0,0 +1,122 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeTXTResolver 按名称返回预置的 TXT 记录，未预置的名称返回 NXDOMAIN
+type fakeTXTResolver struct {
+	records map[string][]string
+	err     error
+	queried []string
+}
+
+func (f *fakeTXTResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	f.queried = append(f.queried, name)
+	if f.err != nil {
+		return nil, f.err
+	}
+	records, ok := f.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+func TestDomainAllocator_VerifyDomainOwnership(t *testing.T) {
+	token := GenerateDomainOwnershipToken("user1")
+
+	tests := []struct {
+		name      string
+		domain    string
+		records   map[string][]string
+		err       error
+		wantOwned bool
+		wantErr   bool
+	}{
+		{
+			name:      "matching record",
+			domain:    "shop.example.com",
+			records:   map[string][]string{"_sealos-challenge.shop.example.com": {"v=spf1 -all", token}},
+			wantOwned: true,
+		},
+		{
+			name:      "wildcard domain uses the base challenge name",
+			domain:    "*.Example.com",
+			records:   map[string][]string{"_sealos-challenge.example.com": {" " + token + " "}},
+			wantOwned: true,
+		},
+		{
+			name:    "non-matching records",
+			domain:  "shop.example.com",
+			records: map[string][]string{"_sealos-challenge.shop.example.com": {GenerateDomainOwnershipToken("user2"), "other"}},
+		},
+		{
+			name:   "no records",
+			domain: "shop.example.com",
+		},
+		{
+			name:    "lookup failure",
+			domain:  "shop.example.com",
+			err:     &net.DNSError{Err: "server misbehaving", Name: "_sealos-challenge.shop.example.com", IsTemporary: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &fakeTXTResolver{records: tt.records, err: tt.err}
+			d := &domainAllocator{config: &NetworkConfig{}, txtResolver: resolver}
+
+			owned, err := d.VerifyDomainOwnership(context.Background(), tt.domain, token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyDomainOwnership() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if owned != tt.wantOwned {
+				t.Errorf("VerifyDomainOwnership() = %v, want %v", owned, tt.wantOwned)
+			}
+			if len(resolver.queried) != 1 || !strings.HasPrefix(resolver.queried[0], DomainOwnershipChallengeLabel+".") {
+				t.Errorf("queried names = %v, want a single _sealos-challenge lookup", resolver.queried)
+			}
+		})
+	}
+}
+
+func TestDomainAllocator_VerifyDomainOwnership_EmptyToken(t *testing.T) {
+	d := &domainAllocator{config: &NetworkConfig{}, txtResolver: &fakeTXTResolver{err: errors.New("should not be queried")}}
+	if owned, err := d.VerifyDomainOwnership(context.Background(), "shop.example.com", ""); owned || err == nil {
+		t.Errorf("VerifyDomainOwnership() = %v, %v, want an error for an empty token", owned, err)
+	}
+}
+
+func TestGenerateDomainOwnershipToken(t *testing.T) {
+	if GenerateDomainOwnershipToken("user1") != GenerateDomainOwnershipToken("user1") {
+		t.Error("token should be deterministic for the same tenant")
+	}
+	if GenerateDomainOwnershipToken("user1") == GenerateDomainOwnershipToken("user2") {
+		t.Error("tokens of different tenants should differ")
+	}
+	if token := GenerateDomainOwnershipToken("user1"); !strings.HasPrefix(token, domainOwnershipTokenPrefix) {
+		t.Errorf("token = %s, want prefix %s", token, domainOwnershipTokenPrefix)
+	}
+}
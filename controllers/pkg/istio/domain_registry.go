@@ -0,0 +1,213 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrDomainClaimed 域名已被其他租户登记
+var ErrDomainClaimed = errors.New("domain is claimed by another tenant")
+
+// domainRegistryLookupTimeout ConfigMap 登记表查询域名归属的超时时间
+const domainRegistryLookupTimeout = 3 * time.Second
+
+// DomainRegistry 跨租户域名登记表，保证同一域名只属于一个租户
+type DomainRegistry interface {
+	// Claim 为租户登记域名，同一租户重复登记幂等，已被其他租户登记时返回 ErrDomainClaimed
+	Claim(ctx context.Context, tenantID, domain string) error
+
+	// Release 释放租户登记的域名，未登记时忽略，由其他租户登记时返回 ErrDomainClaimed
+	Release(ctx context.Context, tenantID, domain string) error
+
+	// Owner 返回登记域名的租户，登记表读取失败时返回错误
+	Owner(domain string) (tenantID string, ok bool, err error)
+}
+
+// normalizeRegistryDomain 统一登记表中域名的形式（小写、punycode、去掉末尾的点）
+func normalizeRegistryDomain(domain string) string {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if normalized, err := NormalizeDomain(domain); err == nil {
+		domain = normalized
+	}
+	return domain
+}
+
+// inMemoryDomainRegistry 进程内的域名登记表，适用于单副本部署和测试
+type inMemoryDomainRegistry struct {
+	mu     sync.RWMutex
+	owners map[string]string
+}
+
+// NewInMemoryDomainRegistry 创建进程内的域名登记表
+func NewInMemoryDomainRegistry() DomainRegistry {
+	return &inMemoryDomainRegistry{owners: make(map[string]string)}
+}
+
+func (r *inMemoryDomainRegistry) Claim(_ context.Context, tenantID, domain string) error {
+	domain = normalizeRegistryDomain(domain)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if owner, ok := r.owners[domain]; ok && owner != tenantID {
+		return fmt.Errorf("%w: %s is owned by %s", ErrDomainClaimed, domain, owner)
+	}
+	r.owners[domain] = tenantID
+	return nil
+}
+
+func (r *inMemoryDomainRegistry) Release(_ context.Context, tenantID, domain string) error {
+	domain = normalizeRegistryDomain(domain)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner, ok := r.owners[domain]
+	if !ok {
+		return nil
+	}
+	if owner != tenantID {
+		return fmt.Errorf("%w: %s is owned by %s", ErrDomainClaimed, domain, owner)
+	}
+	delete(r.owners, domain)
+	return nil
+}
+
+func (r *inMemoryDomainRegistry) Owner(domain string) (string, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	owner, ok := r.owners[normalizeRegistryDomain(domain)]
+	return owner, ok, nil
+}
+
+// configMapDomainRegistry 以 ConfigMap 保存域名归属（key 为域名，value 为租户 ID），多副本共享
+type configMapDomainRegistry struct {
+	client    client.Client
+	reader    client.Reader
+	namespace string
+	name      string
+}
+
+// NewConfigMapDomainRegistry 创建以 namespace/name ConfigMap 保存的域名登记表，ConfigMap 不存在时在首次登记时创建。
+// reader 用于读取登记表，应传入不经过缓存的 mgr.GetAPIReader()，避免缓存客户端为全集群 ConfigMap 建立 informer；
+// 为空时使用 client 读取
+func NewConfigMapDomainRegistry(client client.Client, reader client.Reader, namespace, name string) DomainRegistry {
+	if reader == nil {
+		reader = client
+	}
+	return &configMapDomainRegistry{
+		client:    client,
+		reader:    reader,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// configMapKey 将域名转换为合法的 ConfigMap key，通配符 "*" 替换为 "_"
+func configMapKey(domain string) string {
+	return strings.ReplaceAll(normalizeRegistryDomain(domain), "*", "_")
+}
+
+func (r *configMapDomainRegistry) Claim(ctx context.Context, tenantID, domain string) error {
+	key := configMapKey(domain)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &corev1.ConfigMap{}
+		err := r.reader.Get(ctx, client.ObjectKey{Namespace: r.namespace, Name: r.name}, cm)
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: r.namespace,
+					Name:      r.name,
+					Labels:    map[string]string{"app.kubernetes.io/managed-by": "sealos-istio"},
+				},
+				Data: map[string]string{key: tenantID},
+			}
+			if err := r.client.Create(ctx, cm); err != nil {
+				if apierrors.IsAlreadyExists(err) {
+					// 并发创建时按冲突重试
+					return apierrors.NewConflict(corev1.Resource("configmaps"), r.name, err)
+				}
+				return fmt.Errorf("failed to create domain registry %s/%s: %w", r.namespace, r.name, err)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get domain registry %s/%s: %w", r.namespace, r.name, err)
+		}
+
+		if owner, ok := cm.Data[key]; ok {
+			if owner != tenantID {
+				return fmt.Errorf("%w: %s is owned by %s", ErrDomainClaimed, domain, owner)
+			}
+			return nil
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[key] = tenantID
+		return r.client.Update(ctx, cm)
+	})
+}
+
+func (r *configMapDomainRegistry) Release(ctx context.Context, tenantID, domain string) error {
+	key := configMapKey(domain)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &corev1.ConfigMap{}
+		if err := r.reader.Get(ctx, client.ObjectKey{Namespace: r.namespace, Name: r.name}, cm); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get domain registry %s/%s: %w", r.namespace, r.name, err)
+		}
+
+		owner, ok := cm.Data[key]
+		if !ok {
+			return nil
+		}
+		if owner != tenantID {
+			return fmt.Errorf("%w: %s is owned by %s", ErrDomainClaimed, domain, owner)
+		}
+		delete(cm.Data, key)
+		return r.client.Update(ctx, cm)
+	})
+}
+
+// Owner 查询域名归属；ConfigMap 不存在时视为未登记，查询失败时返回错误
+func (r *configMapDomainRegistry) Owner(domain string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), domainRegistryLookupTimeout)
+	defer cancel()
+
+	cm := &corev1.ConfigMap{}
+	if err := r.reader.Get(ctx, client.ObjectKey{Namespace: r.namespace, Name: r.name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to get domain registry %s/%s: %w", r.namespace, r.name, err)
+	}
+	owner, ok := cm.Data[configMapKey(domain)]
+	return owner, ok, nil
+}
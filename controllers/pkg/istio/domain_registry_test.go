@@ -0,0 +1,180 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestDomainRegistry_ClaimAndRelease(t *testing.T) {
+	registries := map[string]func() DomainRegistry{
+		"in-memory": NewInMemoryDomainRegistry,
+		"configmap": func() DomainRegistry {
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+			return NewConfigMapDomainRegistry(c, c, DefaultTLSSecretNamespace, "sealos-domain-registry")
+		},
+	}
+
+	for name, newRegistry := range registries {
+		t.Run(name, func(t *testing.T) {
+			registry := newRegistry()
+			ctx := context.Background()
+			const domain = "shop.example.com"
+
+			if _, ok, err := registry.Owner(domain); err != nil || ok {
+				t.Fatalf("Owner() before any claim = %v, %v, want no owner", ok, err)
+			}
+			if err := registry.Claim(ctx, "tenant-a", domain); err != nil {
+				t.Fatalf("Claim() error = %v", err)
+			}
+			// 同一租户重复登记幂等
+			if err := registry.Claim(ctx, "tenant-a", "Shop.Example.com."); err != nil {
+				t.Errorf("re-Claim() by the same tenant error = %v", err)
+			}
+			if owner, ok, err := registry.Owner(domain); err != nil || !ok || owner != "tenant-a" {
+				t.Errorf("Owner() = %q, %v, %v, want tenant-a, true", owner, ok, err)
+			}
+
+			// 其他租户登记或释放时冲突
+			if err := registry.Claim(ctx, "tenant-b", domain); !errors.Is(err, ErrDomainClaimed) {
+				t.Errorf("Claim() by another tenant error = %v, want ErrDomainClaimed", err)
+			}
+			if err := registry.Release(ctx, "tenant-b", domain); !errors.Is(err, ErrDomainClaimed) {
+				t.Errorf("Release() by another tenant error = %v, want ErrDomainClaimed", err)
+			}
+
+			if err := registry.Release(ctx, "tenant-a", domain); err != nil {
+				t.Fatalf("Release() error = %v", err)
+			}
+			if _, ok, _ := registry.Owner(domain); ok {
+				t.Error("Owner() still reports an owner after release")
+			}
+			if err := registry.Release(ctx, "tenant-a", domain); err != nil {
+				t.Errorf("Release() of an unclaimed domain error = %v", err)
+			}
+			if err := registry.Claim(ctx, "tenant-b", domain); err != nil {
+				t.Errorf("Claim() after release error = %v", err)
+			}
+
+			// 通配符域名可以登记
+			if err := registry.Claim(ctx, "tenant-a", "*.apps.example.com"); err != nil {
+				t.Errorf("Claim() wildcard error = %v", err)
+			}
+			if owner, ok, _ := registry.Owner("*.apps.example.com"); !ok || owner != "tenant-a" {
+				t.Errorf("Owner() wildcard = %q, %v, want tenant-a, true", owner, ok)
+			}
+		})
+	}
+}
+
+func TestDomainAllocator_DomainRegistry(t *testing.T) {
+	registry := NewInMemoryDomainRegistry()
+	d := NewDomainAllocator(&NetworkConfig{BaseDomain: "cloud.sealos.io", DomainRegistry: registry})
+
+	ctx := context.Background()
+	domain, err := d.ClaimAppDomain(ctx, "tenant-a", "app")
+	if err != nil {
+		t.Fatalf("ClaimAppDomain() error = %v", err)
+	}
+	if owner, ok, _ := registry.Owner(domain); !ok || owner != "tenant-a" {
+		t.Fatalf("ClaimAppDomain() did not register %s, owner = %q", domain, owner)
+	}
+
+	tests := []struct {
+		name      string
+		tenantID  string
+		domain    string
+		available bool
+	}{
+		{name: "owner tenant", tenantID: "tenant-a", domain: domain, available: true},
+		{name: "other tenant", tenantID: "tenant-b", domain: domain, available: false},
+		{name: "unclaimed domain", tenantID: "tenant-b", domain: "other.example.com", available: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			available, err := d.IsDomainAvailableForTenant(tt.tenantID, tt.domain)
+			if err != nil {
+				t.Fatalf("IsDomainAvailableForTenant() error = %v", err)
+			}
+			if available != tt.available {
+				t.Errorf("IsDomainAvailableForTenant(%q, %q) = %v, want %v", tt.tenantID, tt.domain, available, tt.available)
+			}
+		})
+	}
+
+	if available, _ := d.IsDomainAvailable(domain); available {
+		t.Error("IsDomainAvailable() should report a claimed domain as unavailable")
+	}
+
+	// 生成的域名已被其他租户登记时分配失败
+	taken := d.GenerateAppDomain("tenant-a", "taken")
+	if err := registry.Claim(ctx, "tenant-b", taken); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if _, err := d.ClaimAppDomain(ctx, "tenant-a", "taken"); !errors.Is(err, ErrDomainClaimed) {
+		t.Errorf("ClaimAppDomain() of a domain claimed by another tenant error = %v, want ErrDomainClaimed", err)
+	}
+	if err := d.ReleaseAppDomain(ctx, "tenant-a", "taken"); err != nil {
+		t.Errorf("ReleaseAppDomain() of a domain claimed by another tenant error = %v", err)
+	}
+	if owner, _, _ := registry.Owner(taken); owner != "tenant-b" {
+		t.Errorf("Owner() = %q, release must not drop another tenant's claim", owner)
+	}
+
+	if err := d.ReleaseAppDomain(ctx, "tenant-a", "app"); err != nil {
+		t.Fatalf("ReleaseAppDomain() error = %v", err)
+	}
+	if _, ok, _ := registry.Owner(domain); ok {
+		t.Error("ReleaseAppDomain() did not release the generated domain")
+	}
+}
+
+func TestConfigMapDomainRegistry_ReadsThroughAPIReader(t *testing.T) {
+	ctx := context.Background()
+	apiReader := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build()
+	// 缓存客户端没有 ConfigMap 的 list/watch 权限，读取会失败
+	cached := interceptor.NewClient(apiReader, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return errors.New("configmaps is forbidden: cannot list resource at the cluster scope")
+		},
+	})
+	registry := NewConfigMapDomainRegistry(cached, apiReader, DefaultTLSSecretNamespace, "sealos-domain-registry")
+
+	if err := registry.Claim(ctx, "tenant-a", "shop.example.com"); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if owner, ok, err := registry.Owner("shop.example.com"); err != nil || !ok || owner != "tenant-a" {
+		t.Errorf("Owner() = %q, %v, %v, want tenant-a, true", owner, ok, err)
+	}
+
+	// 登记表读取失败时返回错误，而不是视为未登记
+	failing := NewConfigMapDomainRegistry(cached, cached, DefaultTLSSecretNamespace, "sealos-domain-registry")
+	if _, _, err := failing.Owner("shop.example.com"); err == nil {
+		t.Error("Owner() error = nil, want the registry read error")
+	}
+	d := NewDomainAllocator(&NetworkConfig{BaseDomain: "cloud.sealos.io", DomainRegistry: failing})
+	if _, err := d.IsDomainAvailableForTenant("tenant-b", "shop.example.com"); err == nil {
+		t.Error("IsDomainAvailableForTenant() error = nil, want the registry read error")
+	}
+}
@@ -0,0 +1,725 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDomainAllocator_ValidateCustomDomain_SkipDNS(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *NetworkConfig
+		domain       string
+		expectLookup bool
+		expectError  bool
+	}{
+		{
+			name: "internal suffix skips DNS",
+			config: &NetworkConfig{
+				DNSValidationSkipSuffixes: []string{"corp.internal"},
+			},
+			domain:       "app.corp.internal",
+			expectLookup: false,
+			expectError:  false,
+		},
+		{
+			name: "suffix with leading dot skips DNS",
+			config: &NetworkConfig{
+				DNSValidationSkipSuffixes: []string{".svc.cluster.local"},
+			},
+			domain:       "db.ns.svc.cluster.local",
+			expectLookup: false,
+			expectError:  false,
+		},
+		{
+			name: "global flag skips DNS",
+			config: &NetworkConfig{
+				SkipDNSValidation: true,
+			},
+			domain:       "example.com",
+			expectLookup: false,
+			expectError:  false,
+		},
+		{
+			name: "public domain still validates DNS",
+			config: &NetworkConfig{
+				DNSValidationSkipSuffixes: []string{"corp.internal"},
+			},
+			domain:       "shop.example.com",
+			expectLookup: true,
+			expectError:  true,
+		},
+		{
+			name: "partial suffix does not match",
+			config: &NetworkConfig{
+				DNSValidationSkipSuffixes: []string{"internal"},
+			},
+			domain:       "shop.notinternal",
+			expectLookup: true,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			looked := false
+			d := &domainAllocator{
+				config: tt.config,
+				lookupHost: func(_ context.Context, host string) ([]string, error) {
+					looked = true
+					return nil, fmt.Errorf("no such host")
+				},
+			}
+
+			err := d.ValidateCustomDomain(context.Background(), tt.domain)
+			if looked != tt.expectLookup {
+				t.Errorf("DNS lookup called = %v, want %v", looked, tt.expectLookup)
+			}
+			if (err != nil) != tt.expectError {
+				t.Errorf("ValidateCustomDomain() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+// fakeCAAResolver 按域名返回预置的 CAA 记录
+type fakeCAAResolver struct {
+	records map[string][]CAARecord
+	queried []string
+}
+
+func (f *fakeCAAResolver) LookupCAA(ctx context.Context, domain string) ([]CAARecord, error) {
+	f.queried = append(f.queried, domain)
+	return f.records[domain], nil
+}
+
+func TestDomainAllocator_CheckCAA(t *testing.T) {
+	tests := []struct {
+		name        string
+		records     map[string][]CAARecord
+		domain      string
+		allowedCAs  []string
+		expectError bool
+	}{
+		{
+			name:    "no CAA records permits issuance",
+			records: nil,
+			domain:  "app.example.com",
+		},
+		{
+			name: "permissive CAA record",
+			records: map[string][]CAARecord{
+				"app.example.com": {{Tag: "issue", Value: "letsencrypt.org"}},
+			},
+			domain: "app.example.com",
+		},
+		{
+			name: "restrictive CAA record",
+			records: map[string][]CAARecord{
+				"app.example.com": {{Tag: "issue", Value: "digicert.com"}},
+			},
+			domain:      "app.example.com",
+			expectError: true,
+		},
+		{
+			name: "parent domain CAA record applies",
+			records: map[string][]CAARecord{
+				"example.com": {{Tag: "issue", Value: "pki.goog; cansignhttpexchanges=yes"}},
+			},
+			domain:      "app.example.com",
+			expectError: true,
+		},
+		{
+			name: "closest CAA record wins",
+			records: map[string][]CAARecord{
+				"app.example.com": {{Tag: "issue", Value: "LetsEncrypt.org"}},
+				"example.com":     {{Tag: "issue", Value: "digicert.com"}},
+			},
+			domain: "app.example.com",
+		},
+		{
+			name: "iodef only does not restrict",
+			records: map[string][]CAARecord{
+				"example.com": {{Tag: "iodef", Value: "mailto:security@example.com"}},
+			},
+			domain: "app.example.com",
+		},
+		{
+			name: "empty issue value forbids all CAs",
+			records: map[string][]CAARecord{
+				"example.com": {{Tag: "issue", Value: ";"}},
+			},
+			domain:      "app.example.com",
+			expectError: true,
+		},
+		{
+			name: "issuewild takes precedence for wildcard",
+			records: map[string][]CAARecord{
+				"example.com": {
+					{Tag: "issue", Value: "letsencrypt.org"},
+					{Tag: "issuewild", Value: "digicert.com"},
+				},
+			},
+			domain:      "*.example.com",
+			expectError: true,
+		},
+		{
+			name: "custom allowed CA",
+			records: map[string][]CAARecord{
+				"example.com": {{Tag: "issue", Value: "sectigo.com"}},
+			},
+			domain:     "app.example.com",
+			allowedCAs: []string{"sectigo.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &domainAllocator{
+				config:      &NetworkConfig{},
+				caaResolver: &fakeCAAResolver{records: tt.records},
+			}
+
+			err := d.CheckCAA(context.Background(), tt.domain, tt.allowedCAs)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("CheckCAA() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !errors.Is(err, ErrCAAForbidden) {
+				t.Errorf("CheckCAA() error = %v, want ErrCAAForbidden", err)
+			}
+		})
+	}
+}
+
+// caaTestResponse 构造包含 count 条 issue 记录的 CAA 响应，查询无法解析时返回 nil
+func caaTestResponse(query []byte, count int, truncated bool) []byte {
+	var req dnsmessage.Message
+	if err := req.Unpack(query); err != nil {
+		return nil
+	}
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: req.Header.ID, Response: true, Truncated: truncated},
+		Questions: req.Questions,
+	}
+	for i := 0; i < count; i++ {
+		value := fmt.Sprintf("ca%d.example.net", i)
+		resp.Answers = append(resp.Answers, dnsmessage.Resource{
+			Header: dnsmessage.ResourceHeader{Name: req.Questions[0].Name, Type: dnsTypeCAA, Class: dnsmessage.ClassINET, TTL: 60},
+			Body:   &dnsmessage.UnknownResource{Type: dnsTypeCAA, Data: append([]byte{0, 5}, []byte("issue"+value)...)},
+		})
+	}
+	packed, _ := resp.Pack()
+	return packed
+}
+
+func TestDNSCAAResolver_RetriesTruncatedResponseOverTCP(t *testing.T) {
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen tcp: %v", err)
+	}
+	defer tcpListener.Close()
+	udpConn, err := net.ListenPacket("udp", tcpListener.Addr().String())
+	if err != nil {
+		t.Skipf("udp port %s unavailable: %v", tcpListener.Addr(), err)
+	}
+	defer udpConn.Close()
+
+	// UDP 只返回部分记录并设置 TC 位，TCP 返回完整记录集
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := udpConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = udpConn.WriteTo(caaTestResponse(buf[:n], 1, true), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcpListener.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, query); err == nil {
+					resp := caaTestResponse(query, 20, false)
+					binary.BigEndian.PutUint16(length[:], uint16(len(resp)))
+					_, _ = conn.Write(append(length[:], resp...))
+				}
+			}
+			conn.Close()
+		}
+	}()
+
+	resolver := &dnsCAAResolver{server: tcpListener.Addr().String(), timeout: 2 * time.Second}
+	records, err := resolver.LookupCAA(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("LookupCAA() error = %v", err)
+	}
+	if len(records) != 20 {
+		t.Fatalf("LookupCAA() returned %d records, want 20 from the TCP retry", len(records))
+	}
+	if records[19].Tag != "issue" || records[19].Value != "ca19.example.net" {
+		t.Errorf("records[19] = %+v, want issue ca19.example.net", records[19])
+	}
+}
+
+func TestDomainAllocator_CheckCAA_SkipsInternalDomains(t *testing.T) {
+	resolver := &fakeCAAResolver{}
+	d := &domainAllocator{
+		config:      &NetworkConfig{DNSValidationSkipSuffixes: []string{"corp.internal"}},
+		caaResolver: resolver,
+	}
+
+	if err := d.CheckCAA(context.Background(), "app.corp.internal", nil); err != nil {
+		t.Fatalf("CheckCAA() error = %v", err)
+	}
+	if len(resolver.queried) != 0 {
+		t.Errorf("CAA lookups = %v, want none", resolver.queried)
+	}
+}
+
+func TestParseCAARecord(t *testing.T) {
+	data := append([]byte{0, 5}, []byte("issueletsencrypt.org")...)
+	record, ok := parseCAARecord(data)
+	if !ok {
+		t.Fatal("parseCAARecord() ok = false, want true")
+	}
+	if record.Tag != "issue" || record.Value != "letsencrypt.org" {
+		t.Errorf("parseCAARecord() = %+v", record)
+	}
+	if _, ok := parseCAARecord([]byte{0, 9, 'a'}); ok {
+		t.Error("parseCAARecord() with truncated data ok = true, want false")
+	}
+}
+
+func TestDomainAllocator_IsDomainAvailableForTenant(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *NetworkConfig
+		tenantID  string
+		domain    string
+		available bool
+	}{
+		{
+			name: "premium tenant allocates allowed reserved subdomain",
+			config: &NetworkConfig{
+				BaseDomain:               "example.com",
+				TenantReservedSubdomains: map[string][]string{"tenant": {"api"}},
+			},
+			tenantID:  "tenant",
+			domain:    "api.tenant.example.com",
+			available: true,
+		},
+		{
+			name: "normal tenant cannot allocate reserved subdomain",
+			config: &NetworkConfig{
+				BaseDomain:               "example.com",
+				TenantReservedSubdomains: map[string][]string{"tenant": {"api"}},
+			},
+			tenantID:  "other",
+			domain:    "api.other.example.com",
+			available: false,
+		},
+		{
+			name: "premium tenant cannot use allow-list on another tenant's base domain",
+			config: &NetworkConfig{
+				BaseDomain:               "example.com",
+				TenantReservedSubdomains: map[string][]string{"tenant": {"api"}},
+			},
+			tenantID:  "tenant",
+			domain:    "api.other.example.com",
+			available: false,
+		},
+		{
+			name: "only listed subdomains are allowed",
+			config: &NetworkConfig{
+				BaseDomain:               "example.com",
+				TenantReservedSubdomains: map[string][]string{"tenant": {"api"}},
+			},
+			tenantID:  "tenant",
+			domain:    "admin.tenant.example.com",
+			available: false,
+		},
+		{
+			name: "no tenant can claim reserved subdomain of shared base domain",
+			config: &NetworkConfig{
+				BaseDomain:               "cloud.sealos.io",
+				TenantReservedSubdomains: map[string][]string{"tenant": {"api"}, "cloud": {"api"}},
+			},
+			tenantID:  "tenant",
+			domain:    "api.cloud.sealos.io",
+			available: false,
+		},
+		{
+			name: "configured reserved domains cannot be overridden",
+			config: &NetworkConfig{
+				BaseDomain:               "example.com",
+				ReservedDomains:          []string{"tenant.example.com"},
+				TenantReservedSubdomains: map[string][]string{"tenant": {"api"}},
+			},
+			tenantID:  "tenant",
+			domain:    "api.tenant.example.com",
+			available: false,
+		},
+		{
+			name:      "regular subdomain is available",
+			config:    &NetworkConfig{BaseDomain: "example.com"},
+			tenantID:  "other",
+			domain:    "shop.other.example.com",
+			available: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDomainAllocator(tt.config)
+			available, err := d.IsDomainAvailableForTenant(tt.tenantID, tt.domain)
+			if err != nil {
+				t.Fatalf("IsDomainAvailableForTenant() error = %v", err)
+			}
+			if available != tt.available {
+				t.Errorf("IsDomainAvailableForTenant(%q, %q) = %v, want %v", tt.tenantID, tt.domain, available, tt.available)
+			}
+		})
+	}
+
+	// 未指定租户时保持原有的保留子域名检查
+	d := NewDomainAllocator(&NetworkConfig{
+		BaseDomain:               "example.com",
+		TenantReservedSubdomains: map[string][]string{"tenant": {"api"}},
+	})
+	if available, _ := d.IsDomainAvailable("api.tenant.example.com"); available {
+		t.Error("IsDomainAvailable() should keep blocking reserved subdomains without a tenant")
+	}
+}
+
+func TestDomainAllocator_IsReservedDomain(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *NetworkConfig
+		domain   string
+		reserved bool
+	}{
+		{
+			name:     "default list when unset",
+			config:   &NetworkConfig{},
+			domain:   "console.example.com",
+			reserved: true,
+		},
+		{
+			name:     "empty list means default",
+			config:   &NetworkConfig{ReservedSubdomains: []string{}},
+			domain:   "api.example.com",
+			reserved: true,
+		},
+		{
+			name:     "override reserves configured subdomain",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git", "registry", "minio"}},
+			domain:   "registry.example.com",
+			reserved: true,
+		},
+		{
+			name:     "override releases built-in subdomain",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git", "registry", "minio"}},
+			domain:   "console.example.com",
+			reserved: false,
+		},
+		{
+			name:     "subdomain comparison is case insensitive",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"Git"}},
+			domain:   "GIT.example.com",
+			reserved: true,
+		},
+		{
+			name:     "only the first label is matched",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git"}},
+			domain:   "app.git.example.com",
+			reserved: false,
+		},
+		{
+			name:     "subdomain must match the whole label",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git"}},
+			domain:   "gitea.example.com",
+			reserved: false,
+		},
+		{
+			name:     "reserved domain matches itself",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git"}, ReservedDomains: []string{"internal.example.com"}},
+			domain:   "internal.example.com",
+			reserved: true,
+		},
+		{
+			name:     "reserved domain matches subdomains by suffix",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git"}, ReservedDomains: []string{"internal.example.com"}},
+			domain:   "app.Internal.Example.com",
+			reserved: true,
+		},
+		{
+			name:     "reserved domain suffix requires a label boundary",
+			config:   &NetworkConfig{ReservedSubdomains: []string{"git"}, ReservedDomains: []string{"internal.example.com"}},
+			domain:   "myinternal.example.com",
+			reserved: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator := NewDomainAllocator(tt.config).(*domainAllocator)
+			if got := allocator.isReservedDomain(tt.domain); got != tt.reserved {
+				t.Errorf("isReservedDomain(%s) = %v, want %v", tt.domain, got, tt.reserved)
+			}
+		})
+	}
+}
+
+func TestDomainAllocator_GenerateShortHash(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *NetworkConfig
+		wantLength int
+	}{
+		{name: "default keeps md5 with 6 chars", config: &NetworkConfig{}, wantLength: 6},
+		{name: "configured length", config: &NetworkConfig{HashLength: 12}, wantLength: 12},
+		{name: "length below minimum is clamped", config: &NetworkConfig{HashLength: 2}, wantLength: 4},
+		{name: "negative length is clamped", config: &NetworkConfig{HashLength: -1}, wantLength: 4},
+		{name: "length above maximum is clamped", config: &NetworkConfig{HashLength: 64}, wantLength: 32},
+		{name: "sha256", config: &NetworkConfig{HashAlgorithm: HashAlgorithmSHA256, HashLength: 32}, wantLength: 32},
+		{name: "unknown algorithm falls back to md5", config: &NetworkConfig{HashAlgorithm: "crc32"}, wantLength: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator := NewDomainAllocator(tt.config).(*domainAllocator)
+			got := allocator.generateShortHash("tenant-app")
+			if len(got) != tt.wantLength {
+				t.Errorf("generateShortHash() = %s, length %d, want %d", got, len(got), tt.wantLength)
+			}
+			if again := allocator.generateShortHash("tenant-app"); again != got {
+				t.Errorf("generateShortHash() not deterministic: %s != %s", got, again)
+			}
+		})
+	}
+
+	// 默认配置保持原有的 md5 前 6 位，已分配的域名不变
+	if got := NewDomainAllocator(&NetworkConfig{}).(*domainAllocator).generateShortHash("tenant-app"); got != fmt.Sprintf("%x", md5.Sum([]byte("tenant-app")))[:6] {
+		t.Errorf("default generateShortHash() = %s, want md5 prefix", got)
+	}
+	md5Hash := NewDomainAllocator(&NetworkConfig{HashAlgorithm: HashAlgorithmMD5}).(*domainAllocator).generateShortHash("tenant-app")
+	sha256Hash := NewDomainAllocator(&NetworkConfig{HashAlgorithm: HashAlgorithmSHA256}).(*domainAllocator).generateShortHash("tenant-app")
+	if md5Hash == sha256Hash {
+		t.Errorf("md5 and sha256 hashes should differ, both %s", md5Hash)
+	}
+}
+
+func TestDomainAllocator_GenerateShortHash_NoCollisions(t *testing.T) {
+	for _, algorithm := range []string{HashAlgorithmMD5, HashAlgorithmSHA256} {
+		t.Run(algorithm, func(t *testing.T) {
+			allocator := NewDomainAllocator(&NetworkConfig{HashAlgorithm: algorithm, HashLength: 12}).(*domainAllocator)
+			seen := make(map[string]string, 10000)
+			for i := 0; i < 10000; i++ {
+				input := fmt.Sprintf("tenant-app-%d", i)
+				hash := allocator.generateShortHash(input)
+				if previous, ok := seen[hash]; ok {
+					t.Fatalf("hash collision between %s and %s: %s", previous, input, hash)
+				}
+				seen[hash] = input
+			}
+		})
+	}
+}
+
+func TestDomainAllocator_DomainsUseConfiguredHash(t *testing.T) {
+	allocator := NewDomainAllocator(&NetworkConfig{BaseDomain: "cloud.sealos.io", HashLength: 10}).(*domainAllocator)
+	hashPattern := regexp.MustCompile(`^[a-z-]+-[0-9a-f]{10}\.tenant\.cloud\.sealos\.io$`)
+	for _, domain := range []string{
+		allocator.GenerateAppDomain("tenant", "app"),
+		allocator.GetDomainForTerminal("tenant", "terminal"),
+		allocator.GetDomainForDatabase("tenant", "db"),
+	} {
+		if !hashPattern.MatchString(domain) {
+			t.Errorf("domain %s does not use the configured hash length", domain)
+		}
+	}
+}
+
+func TestDomainAllocator_ValidateCustomDomain_DNSTimeout(t *testing.T) {
+	// 模拟无响应的 DNS 服务器：拨号阻塞直到上下文结束
+	stalled := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	d := &domainAllocator{
+		config:     &NetworkConfig{DNSValidationTimeout: 50 * time.Millisecond},
+		lookupHost: stalled.LookupHost,
+	}
+
+	start := time.Now()
+	err := d.ValidateCustomDomain(context.Background(), "unreachable.example.com")
+	if !errors.Is(err, ErrDNSValidationTimeout) {
+		t.Fatalf("ValidateCustomDomain() error = %v, want ErrDNSValidationTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ValidateCustomDomain() took %s, want the configured timeout to fire", elapsed)
+	}
+
+	// 普通的解析失败不是超时
+	d.lookupHost = func(_ context.Context, host string) ([]string, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	if err := d.ValidateCustomDomain(context.Background(), "missing.example.com"); err == nil || errors.Is(err, ErrDNSValidationTimeout) {
+		t.Errorf("ValidateCustomDomain() error = %v, want a non-timeout DNS error", err)
+	}
+}
+
+func TestDomainAllocator_ValidateDNSResolution_DefaultTimeout(t *testing.T) {
+	var deadline time.Time
+	d := &domainAllocator{
+		config: &NetworkConfig{},
+		lookupHost: func(ctx context.Context, host string) ([]string, error) {
+			deadline, _ = ctx.Deadline()
+			return []string{"203.0.113.10"}, nil
+		},
+	}
+
+	start := time.Now()
+	if err := d.validateDNSResolution(context.Background(), "app.example.com"); err != nil {
+		t.Fatalf("validateDNSResolution() error = %v", err)
+	}
+	if remaining := deadline.Sub(start); remaining < 4*time.Second || remaining > 6*time.Second {
+		t.Errorf("lookup deadline = %s after start, want about %s", remaining, defaultDNSValidationTimeout)
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain ascii unchanged", domain: "App.Cloud.Sealos.io", want: "App.Cloud.Sealos.io"},
+		{name: "unicode label", domain: "测试.cloud.sealos.io", want: "xn--0zwm56d.cloud.sealos.io"},
+		{name: "mixed-case unicode", domain: "Bücher.Example.COM", want: "xn--bcher-kva.example.com"},
+		{name: "unicode tld", domain: "商店.中国", want: "xn--czrs0t.xn--fiqs8s"},
+		{name: "invalid unicode label", domain: "测试_bad.cloud.sealos.io", wantErr: true},
+		{name: "label starting with hyphen", domain: "-测试.cloud.sealos.io", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDomain(tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeDomain(%s) error = %v, wantErr %v", tt.domain, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeDomain(%s) = %s, want %s", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainAllocator_IDNDomains(t *testing.T) {
+	d := &domainAllocator{config: &NetworkConfig{BaseDomain: "cloud.sealos.io", SkipDNSValidation: true}}
+
+	tests := []struct {
+		name    string
+		domain  string
+		wantErr bool
+	}{
+		{name: "unicode subdomain", domain: "测试.cloud.sealos.io"},
+		{name: "mixed-case unicode", domain: "Bücher.Example.com"},
+		{name: "plain ascii", domain: "shop.example.com"},
+		{name: "invalid unicode label", domain: "测试_bad.example.com", wantErr: true},
+		{name: "invalid ascii label", domain: "bad_domain.example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.validateDomainFormat(tt.domain); (err != nil) != tt.wantErr {
+				t.Errorf("validateDomainFormat(%s) error = %v, wantErr %v", tt.domain, err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := d.sanitizeDomainPart("测试"); got != "xn--0zwm56d" {
+		t.Errorf("sanitizeDomainPart(测试) = %s, want punycode label", got)
+	}
+	if got := d.sanitizeDomainPart("My_App"); got != "my-app" {
+		t.Errorf("sanitizeDomainPart(My_App) = %s, want my-app", got)
+	}
+}
+
+func TestParseNamespaceBaseDomains(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want map[string]string
+	}{
+		{raw: ""},
+		{raw: "ns-a"},
+		{
+			raw:  " ns-a = Brand-A.io. ,ns-b=brand-b.io,=missing.io,ns-c=",
+			want: map[string]string{"ns-a": "brand-a.io", "ns-b": "brand-b.io"},
+		},
+		{raw: "ns-a=old.io,ns-a=new.io", want: map[string]string{"ns-a": "new.io"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseNamespaceBaseDomains(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseNamespaceBaseDomains(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseTenantReservedSubdomains(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want map[string][]string
+	}{
+		{raw: ""},
+		{raw: "tenant-a=,=api,tenant-b"},
+		{
+			raw:  " tenant-a = API | www ,tenant-b=api,tenant-a=git",
+			want: map[string][]string{"tenant-a": {"api", "www", "git"}, "tenant-b": {"api"}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := ParseTenantReservedSubdomains(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTenantReservedSubdomains(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
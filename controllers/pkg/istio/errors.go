@@ -0,0 +1,106 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	// ErrVSConflict VirtualService 已存在，或更新时版本冲突
+	ErrVSConflict = errors.New("virtualservice conflict")
+	// ErrVSNotFound VirtualService 不存在
+	ErrVSNotFound = errors.New("virtualservice not found")
+	// ErrGatewayConflict Gateway 已存在，或更新时版本冲突
+	ErrGatewayConflict = errors.New("gateway conflict")
+	// ErrGatewayNotFound Gateway 不存在
+	ErrGatewayNotFound = errors.New("gateway not found")
+	// ErrTLSSecretNotFound Gateway 引用的 TLS Secret 在 Gateway 所在命名空间中不存在，调用方应稍后重新协调
+	ErrTLSSecretNotFound = errors.New("gateway tls secret not found")
+)
+
+// ResourceError Istio 资源操作失败的错误，errors.Is 可匹配哨兵错误（如 ErrVSNotFound），
+// 同时保留原始 API 错误，apierrors.IsNotFound 等判断仍然有效
+type ResourceError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Sentinel 错误类别，取值为 ErrVSConflict、ErrVSNotFound、ErrGatewayConflict 或 ErrGatewayNotFound
+	Sentinel error
+	Err      error
+}
+
+func (e *ResourceError) Error() string {
+	return fmt.Sprintf("%s %s/%s: %v", e.Kind, e.Namespace, e.Name, e.Err)
+}
+
+func (e *ResourceError) Unwrap() []error {
+	return []error{e.Sentinel, e.Err}
+}
+
+// classifyResourceError 把 API Server 返回的已存在/版本冲突/不存在错误转换为 ResourceError，其他错误原样返回
+func classifyResourceError(kind, namespace, name string, conflict, notFound, err error) error {
+	if err == nil {
+		return nil
+	}
+	var sentinel error
+	switch {
+	case apierrors.IsAlreadyExists(err), apierrors.IsConflict(err):
+		sentinel = conflict
+	case apierrors.IsNotFound(err):
+		sentinel = notFound
+	default:
+		return err
+	}
+	return &ResourceError{Kind: kind, Namespace: namespace, Name: name, Sentinel: sentinel, Err: err}
+}
+
+func virtualServiceError(namespace, name string, err error) error {
+	return classifyResourceError("virtualservice", namespace, name, ErrVSConflict, ErrVSNotFound, err)
+}
+
+func gatewayError(namespace, name string, err error) error {
+	return classifyResourceError("gateway", namespace, name, ErrGatewayConflict, ErrGatewayNotFound, err)
+}
+
+// IsVSConflict 判断错误是否为 VirtualService 已存在或版本冲突
+func IsVSConflict(err error) bool {
+	return errors.Is(err, ErrVSConflict)
+}
+
+// IsVSNotFound 判断错误是否为 VirtualService 不存在
+func IsVSNotFound(err error) bool {
+	return errors.Is(err, ErrVSNotFound)
+}
+
+// IsGatewayConflict 判断错误是否为 Gateway 已存在或版本冲突
+func IsGatewayConflict(err error) bool {
+	return errors.Is(err, ErrGatewayConflict)
+}
+
+// IsGatewayNotFound 判断错误是否为 Gateway 不存在
+func IsGatewayNotFound(err error) bool {
+	return errors.Is(err, ErrGatewayNotFound)
+}
+
+// IsTLSSecretNotFound 判断错误是否由 Gateway 引用的 TLS Secret 不存在引起
+func IsTLSSecretNotFound(err error) bool {
+	return errors.Is(err, ErrTLSSecretNotFound)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestErrorsVSConfig() *VirtualServiceConfig {
+	return &VirtualServiceConfig{
+		Name:        "app-vs",
+		Namespace:   "ns-user1",
+		Hosts:       []string{"app.example.com"},
+		Gateways:    []string{"istio-system/sealos-gateway"},
+		ServiceName: "app",
+		ServicePort: 8080,
+	}
+}
+
+func TestVirtualServiceController_TypedErrors(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewVirtualServiceController(c, &NetworkConfig{})
+	ctx := context.Background()
+
+	notFoundOps := map[string]func() error{
+		"get": func() error {
+			_, err := controller.Get(ctx, "app-vs", "ns-user1")
+			return err
+		},
+		"update":  func() error { return controller.Update(ctx, newTestErrorsVSConfig()) },
+		"suspend": func() error { return controller.Suspend(ctx, "app-vs", "ns-user1") },
+		"resume":  func() error { return controller.Resume(ctx, "app-vs", "ns-user1") },
+	}
+	for name, op := range notFoundOps {
+		t.Run(name+" missing", func(t *testing.T) {
+			err := op()
+			if !IsVSNotFound(err) {
+				t.Fatalf("error = %v, want ErrVSNotFound", err)
+			}
+			if IsVSConflict(err) || IsGatewayNotFound(err) {
+				t.Errorf("error = %v, matched an unrelated sentinel", err)
+			}
+			// 原始 API 错误仍然可以判断
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("apierrors.IsNotFound(%v) = false, want true", err)
+			}
+		})
+	}
+
+	t.Run("create existing", func(t *testing.T) {
+		if err := controller.Create(ctx, newTestErrorsVSConfig()); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		err := controller.Create(ctx, newTestErrorsVSConfig())
+		if !IsVSConflict(err) {
+			t.Fatalf("Create() error = %v, want ErrVSConflict", err)
+		}
+		if !apierrors.IsAlreadyExists(err) {
+			t.Errorf("apierrors.IsAlreadyExists(%v) = false, want true", err)
+		}
+		var resourceErr *ResourceError
+		if !errors.As(err, &resourceErr) || resourceErr.Kind != "virtualservice" ||
+			resourceErr.Namespace != "ns-user1" || resourceErr.Name != "app-vs" {
+			t.Errorf("errors.As(%v) = %+v, want virtualservice ns-user1/app-vs", err, resourceErr)
+		}
+	})
+}
+
+func TestGatewayController_TypedErrors(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewGatewayController(c, &NetworkConfig{})
+	ctx := context.Background()
+	config := &GatewayConfig{Name: "app-gateway", Namespace: "ns-user1", Hosts: []string{"app.example.com"}}
+
+	if _, err := controller.Get(ctx, "app-gateway", "ns-user1"); !IsGatewayNotFound(err) {
+		t.Errorf("Get() error = %v, want ErrGatewayNotFound", err)
+	}
+	if err := controller.Update(ctx, config); !IsGatewayNotFound(err) || !apierrors.IsNotFound(err) {
+		t.Errorf("Update() error = %v, want ErrGatewayNotFound", err)
+	}
+	// Exists 依赖 apierrors.IsNotFound，包装后仍返回 false 而不是错误
+	if exists, err := controller.Exists(ctx, "app-gateway", "ns-user1"); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false, nil", exists, err)
+	}
+
+	if err := controller.Create(ctx, config); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	err := controller.Create(ctx, config)
+	if !IsGatewayConflict(err) {
+		t.Fatalf("Create() error = %v, want ErrGatewayConflict", err)
+	}
+	if IsVSConflict(err) {
+		t.Errorf("gateway conflict should not match ErrVSConflict")
+	}
+}
+
+func TestClassifyResourceError(t *testing.T) {
+	gr := virtualServiceGVK.GroupVersion().WithResource("virtualservices").GroupResource()
+	plain := fmt.Errorf("connection refused")
+	tests := []struct {
+		name         string
+		err          error
+		wantConflict bool
+		wantNotFound bool
+	}{
+		{name: "nil", err: nil},
+		{name: "not an api error", err: plain},
+		{name: "forbidden", err: apierrors.NewForbidden(gr, "app-vs", plain)},
+		{name: "already exists", err: apierrors.NewAlreadyExists(gr, "app-vs"), wantConflict: true},
+		{name: "conflict", err: apierrors.NewConflict(gr, "app-vs", plain), wantConflict: true},
+		{name: "not found", err: apierrors.NewNotFound(gr, "app-vs"), wantNotFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := virtualServiceError("ns-user1", "app-vs", tt.err)
+			if got := IsVSConflict(err); got != tt.wantConflict {
+				t.Errorf("IsVSConflict() = %v, want %v", got, tt.wantConflict)
+			}
+			if got := IsVSNotFound(err); got != tt.wantNotFound {
+				t.Errorf("IsVSNotFound() = %v, want %v", got, tt.wantNotFound)
+			}
+			// 未分类的错误原样返回
+			if !tt.wantConflict && !tt.wantNotFound && err != tt.err {
+				t.Errorf("virtualServiceError() = %v, want the original error %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -54,6 +54,10 @@ func NewGatewayController(client client.Client, config *NetworkConfig) GatewayCo
 }
 
 func (g *gatewayController) Create(ctx context.Context, config *GatewayConfig) error {
+	if err := g.validateGatewayTLS(config); err != nil {
+		return err
+	}
+
 	gateway := &unstructured.Unstructured{}
 	gateway.SetGroupVersionKind(gatewayGVK)
 	gateway.SetName(config.Name)
@@ -77,10 +81,14 @@ func (g *gatewayController) Create(ctx context.Context, config *GatewayConfig) e
 		return fmt.Errorf("failed to set gateway spec: %w", err)
 	}
 
-	return g.client.Create(ctx, gateway)
+	return gatewayError(config.Namespace, config.Name, g.client.Create(ctx, gateway))
 }
 
 func (g *gatewayController) Update(ctx context.Context, config *GatewayConfig) error {
+	if err := g.validateGatewayTLS(config); err != nil {
+		return err
+	}
+
 	gateway := &unstructured.Unstructured{}
 	gateway.SetGroupVersionKind(gatewayGVK)
 
@@ -90,7 +98,7 @@ func (g *gatewayController) Update(ctx context.Context, config *GatewayConfig) e
 	}
 
 	if err := g.client.Get(ctx, key, gateway); err != nil {
-		return fmt.Errorf("failed to get gateway: %w", err)
+		return fmt.Errorf("failed to get gateway: %w", gatewayError(config.Namespace, config.Name, err))
 	}
 
 	// 更新 spec
@@ -111,7 +119,7 @@ func (g *gatewayController) Update(ctx context.Context, config *GatewayConfig) e
 	}
 	gateway.SetLabels(labels)
 
-	return g.client.Update(ctx, gateway)
+	return gatewayError(config.Namespace, config.Name, g.client.Update(ctx, gateway))
 }
 
 func (g *gatewayController) Delete(ctx context.Context, name, namespace string) error {
@@ -133,7 +141,7 @@ func (g *gatewayController) Get(ctx context.Context, name, namespace string) (*G
 	}
 
 	if err := g.client.Get(ctx, key, gateway); err != nil {
-		return nil, err
+		return nil, gatewayError(namespace, name, err)
 	}
 
 	return g.parseGateway(gateway)
@@ -178,6 +186,19 @@ func (g *gatewayController) buildGatewaySpec(config *GatewayConfig) map[string]i
 func (g *gatewayController) buildServers(config *GatewayConfig) []interface{} {
 	servers := []interface{}{}
 
+	// TCP 服务器（数据库代理等原始 TCP 端口）
+	if config.TCPPort > 0 {
+		tcpServer := map[string]interface{}{
+			"port": map[string]interface{}{
+				"number":   int64(config.TCPPort),
+				"name":     fmt.Sprintf("tcp-%d", config.TCPPort),
+				"protocol": "TCP",
+			},
+			"hosts": stringSliceToInterface(config.Hosts),
+		}
+		return append(servers, tcpServer)
+	}
+
 	// HTTP 服务器
 	httpServer := map[string]interface{}{
 		"port": map[string]interface{}{
@@ -198,10 +219,7 @@ func (g *gatewayController) buildServers(config *GatewayConfig) []interface{} {
 				"protocol": "HTTPS",
 			},
 			"hosts": stringSliceToInterface(config.TLSConfig.Hosts),
-			"tls": map[string]interface{}{
-				"mode":           "SIMPLE",
-				"credentialName": config.TLSConfig.SecretName,
-			},
+			"tls":   g.buildServerTLS(config.TLSConfig),
 		}
 		servers = append(servers, httpsServer)
 	}
@@ -209,6 +227,49 @@ func (g *gatewayController) buildServers(config *GatewayConfig) []interface{} {
 	return servers
 }
 
+// buildServerTLS 构建 HTTPS 服务器的 tls 配置，未配置 TLS 版本和加密套件时使用 NetworkConfig 的默认值
+func (g *gatewayController) buildServerTLS(tlsConfig *TLSConfig) map[string]interface{} {
+	tls := map[string]interface{}{
+		"mode":           "SIMPLE",
+		"credentialName": tlsConfig.SecretName,
+	}
+
+	minVersion, cipherSuites := g.gatewayTLSOptions(tlsConfig)
+	if minVersion != "" {
+		tls["minProtocolVersion"] = minVersion
+	}
+	if len(cipherSuites) > 0 {
+		tls["cipherSuites"] = stringSliceToInterface(cipherSuites)
+	}
+	return tls
+}
+
+// gatewayTLSOptions 返回生效的最低 TLS 版本和加密套件
+func (g *gatewayController) gatewayTLSOptions(tlsConfig *TLSConfig) (string, []string) {
+	minVersion, cipherSuites := tlsConfig.MinProtocolVersion, tlsConfig.CipherSuites
+	if g.config != nil {
+		if minVersion == "" {
+			minVersion = g.config.GatewayTLSMinProtocolVersion
+		}
+		if len(cipherSuites) == 0 {
+			cipherSuites = g.config.GatewayTLSCipherSuites
+		}
+	}
+	return minVersion, cipherSuites
+}
+
+// validateGatewayTLS 校验 Gateway 的 TLS 版本和加密套件是 Istio 支持的取值
+func (g *gatewayController) validateGatewayTLS(config *GatewayConfig) error {
+	if config.TLSConfig == nil {
+		return nil
+	}
+	minVersion, cipherSuites := g.gatewayTLSOptions(config.TLSConfig)
+	if err := validateTLSOptions(minVersion, cipherSuites); err != nil {
+		return fmt.Errorf("gateway %s/%s: %w", config.Namespace, config.Name, err)
+	}
+	return nil
+}
+
 // parseGateway 解析 Gateway 资源
 func (g *gatewayController) parseGateway(gateway *unstructured.Unstructured) (*Gateway, error) {
 	name := gateway.GetName()
@@ -332,6 +393,10 @@ func (g *gatewayController) isGatewayReady(gateway *unstructured.Unstructured) b
 
 // CreateOrUpdate 创建或更新 Gateway（工具方法）
 func (g *gatewayController) CreateOrUpdate(ctx context.Context, config *GatewayConfig, owner metav1.Object, scheme *runtime.Scheme) error {
+	if err := g.validateGatewayTLS(config); err != nil {
+		return err
+	}
+
 	gateway := &unstructured.Unstructured{}
 	gateway.SetGroupVersionKind(gatewayGVK)
 	gateway.SetName(config.Name)
@@ -367,7 +432,7 @@ func (g *gatewayController) CreateOrUpdate(ctx context.Context, config *GatewayC
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to create or update gateway: %w", err)
+		return fmt.Errorf("failed to create or update gateway: %w", gatewayError(config.Namespace, config.Name, err))
 	}
 
 	if result == controllerutil.OperationResultCreated {
@@ -438,6 +503,10 @@ func makeSafeForDeepCopy(obj interface{}) interface{} {
 
 // CreateOrUpdateWithOwner 创建或更新 Gateway（支持设置 OwnerReference）
 func (g *gatewayController) CreateOrUpdateWithOwner(ctx context.Context, config *GatewayConfig, owner metav1.Object, scheme *runtime.Scheme) error {
+	if err := g.validateGatewayTLS(config); err != nil {
+		return err
+	}
+
 	gateway := &unstructured.Unstructured{}
 	gateway.SetGroupVersionKind(gatewayGVK)
 	gateway.SetName(config.Name)
@@ -475,7 +544,7 @@ func (g *gatewayController) CreateOrUpdateWithOwner(ctx context.Context, config
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to create or update gateway: %w", err)
+		return fmt.Errorf("failed to create or update gateway: %w", gatewayError(config.Namespace, config.Name, err))
 	}
 
 	// 记录操作结果（注：可以在需要时添加日志）
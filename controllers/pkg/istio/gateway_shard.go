@@ -0,0 +1,51 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// SharedGatewayShard 按租户计算共享 Gateway 分片序号，范围 [0, shards)。
+// 使用 jump consistent hash：同一租户总是落在同一分片，分片数增加时只有约 1/N 的租户迁移。
+func SharedGatewayShard(tenant string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(tenant))
+	key := h.Sum64()
+
+	var b, j int64 = -1, 0
+	for j < int64(shards) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// ShardedGatewayName 返回租户使用的共享 Gateway，shards 不大于 1 时不分片，
+// 否则为 <gateway>-<shard>（如 istio-system/sealos-gateway-3）
+func ShardedGatewayName(gateway, tenant string, shards int) string {
+	if shards <= 1 || gateway == "" {
+		return gateway
+	}
+	return fmt.Sprintf("%s-%d", gateway, SharedGatewayShard(tenant, shards))
+}
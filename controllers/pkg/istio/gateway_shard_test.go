@@ -0,0 +1,140 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSharedGatewayShard_Deterministic(t *testing.T) {
+	for _, shards := range []int{2, 3, 8, 16} {
+		for i := 0; i < 100; i++ {
+			tenant := fmt.Sprintf("ns-tenant-%d", i)
+			first := SharedGatewayShard(tenant, shards)
+			if first < 0 || first >= shards {
+				t.Fatalf("SharedGatewayShard(%q, %d) = %d, out of range", tenant, shards, first)
+			}
+			for j := 0; j < 5; j++ {
+				if got := SharedGatewayShard(tenant, shards); got != first {
+					t.Fatalf("SharedGatewayShard(%q, %d) = %d, previously %d", tenant, shards, got, first)
+				}
+			}
+		}
+	}
+}
+
+func TestSharedGatewayShard_EvenDistribution(t *testing.T) {
+	const tenants = 20000
+	for _, shards := range []int{4, 8, 10} {
+		counts := make([]int, shards)
+		for i := 0; i < tenants; i++ {
+			counts[SharedGatewayShard(fmt.Sprintf("ns-%08x", i), shards)]++
+		}
+
+		expected := tenants / shards
+		for shard, count := range counts {
+			// 每个分片偏离平均值不超过 10%
+			if diff := count - expected; diff > expected/10 || -diff > expected/10 {
+				t.Errorf("shards=%d: shard %d has %d tenants, expected about %d (%v)", shards, shard, count, expected, counts)
+			}
+		}
+	}
+}
+
+func TestSharedGatewayShard_MinimalMovement(t *testing.T) {
+	const tenants = 10000
+	moved := 0
+	for i := 0; i < tenants; i++ {
+		tenant := fmt.Sprintf("ns-%08x", i)
+		before, after := SharedGatewayShard(tenant, 8), SharedGatewayShard(tenant, 9)
+		if before != after {
+			// 扩容时租户只能迁移到新增的分片
+			if after != 8 {
+				t.Fatalf("tenant %s moved from shard %d to existing shard %d", tenant, before, after)
+			}
+			moved++
+		}
+	}
+	// 期望约 1/9 的租户迁移
+	if moved > tenants/9*12/10 {
+		t.Errorf("%d of %d tenants moved when adding a shard, expected about %d", moved, tenants, tenants/9)
+	}
+}
+
+func TestShardedGatewayName(t *testing.T) {
+	tenant := "ns-user1"
+	tests := []struct {
+		name    string
+		gateway string
+		shards  int
+		want    string
+	}{
+		{name: "sharding disabled", gateway: "istio-system/sealos-gateway", shards: 0, want: "istio-system/sealos-gateway"},
+		{name: "single shard", gateway: "istio-system/sealos-gateway", shards: 1, want: "istio-system/sealos-gateway"},
+		{
+			name:    "sharded",
+			gateway: "istio-system/sealos-gateway",
+			shards:  4,
+			want:    fmt.Sprintf("istio-system/sealos-gateway-%d", SharedGatewayShard(tenant, 4)),
+		},
+		{name: "empty gateway", gateway: "", shards: 4, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShardedGatewayName(tt.gateway, tenant, tt.shards); got != tt.want {
+				t.Errorf("ShardedGatewayName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainClassifier_ShardedSharedGateway(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:          "cloud.sealos.io",
+		DefaultGateway:      "istio-system/sealos-gateway",
+		SharedGatewayShards: 4,
+	}
+	classifier := NewDomainClassifier(config)
+
+	for i := 0; i < 20; i++ {
+		namespace := fmt.Sprintf("ns-user%d", i)
+		want := fmt.Sprintf("istio-system/sealos-gateway-%d", SharedGatewayShard(namespace, 4))
+
+		// 同一租户的多个应用使用同一个分片
+		for _, app := range []string{"app1", "app2"} {
+			spec := &AppNetworkingSpec{
+				Name:      app,
+				Namespace: namespace,
+				Hosts:     []string{app + "." + namespace + ".cloud.sealos.io"},
+			}
+			if got := classifier.GetGatewayReference(spec); got != want {
+				t.Errorf("GetGatewayReference(%s/%s) = %s, want %s", namespace, app, got, want)
+			}
+			vsConfig := classifier.BuildOptimizedVirtualServiceConfig(spec)
+			if len(vsConfig.Gateways) != 1 || vsConfig.Gateways[0] != want {
+				t.Errorf("VirtualService gateways for %s/%s = %v, want [%s]", namespace, app, vsConfig.Gateways, want)
+			}
+		}
+	}
+
+	// 自定义域名仍使用应用专属 Gateway
+	spec := &AppNetworkingSpec{Name: "app1", Namespace: "ns-user1", Hosts: []string{"custom.com"}}
+	if got := classifier.GetGatewayReference(spec); got != "ns-user1/app1-gateway" {
+		t.Errorf("GetGatewayReference() for custom domain = %s, want ns-user1/app1-gateway", got)
+	}
+}
@@ -18,6 +18,7 @@ package istio
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -266,4 +267,124 @@ func newTestScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	// Add any required schemes here if needed
 	return scheme
-}
\ No newline at end of file
+}
+func TestGatewayBuildServers_TCP(t *testing.T) {
+	controller := &gatewayController{config: &NetworkConfig{}}
+	servers := controller.buildServers(&GatewayConfig{
+		Name:      "db-proxy-gateway",
+		Namespace: "ns-test",
+		Hosts:     []string{"db.example.com"},
+		TCPPort:   33306,
+	})
+
+	if len(servers) != 1 {
+		t.Fatalf("servers = %v, want only the tcp server", servers)
+	}
+	port := servers[0].(map[string]interface{})["port"].(map[string]interface{})
+	if port["number"] != int64(33306) || port["protocol"] != "TCP" || port["name"] != "tcp-33306" {
+		t.Errorf("tcp server port = %v, want 33306/TCP named tcp-33306", port)
+	}
+}
+
+func TestGatewayBuildServers_TLSOptions(t *testing.T) {
+	cipherSuites := []string{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384"}
+	tests := []struct {
+		name            string
+		networkConfig   *NetworkConfig
+		tlsConfig       *TLSConfig
+		wantMinVersion  interface{}
+		wantCipherSuite []interface{}
+	}{
+		{
+			name:          "istio defaults",
+			networkConfig: &NetworkConfig{},
+			tlsConfig:     &TLSConfig{SecretName: "app-tls", Hosts: []string{"app.example.com"}},
+		},
+		{
+			name:            "network config defaults",
+			networkConfig:   &NetworkConfig{GatewayTLSMinProtocolVersion: TLSProtocolV1_2, GatewayTLSCipherSuites: cipherSuites},
+			tlsConfig:       &TLSConfig{SecretName: "app-tls", Hosts: []string{"app.example.com"}},
+			wantMinVersion:  TLSProtocolV1_2,
+			wantCipherSuite: []interface{}{"ECDHE-ECDSA-AES256-GCM-SHA384", "ECDHE-RSA-AES256-GCM-SHA384"},
+		},
+		{
+			name:          "gateway overrides network config",
+			networkConfig: &NetworkConfig{GatewayTLSMinProtocolVersion: TLSProtocolV1_2, GatewayTLSCipherSuites: cipherSuites},
+			tlsConfig: &TLSConfig{
+				SecretName:         "app-tls",
+				Hosts:              []string{"app.example.com"},
+				MinProtocolVersion: TLSProtocolV1_3,
+				CipherSuites:       []string{"ECDHE-RSA-CHACHA20-POLY1305"},
+			},
+			wantMinVersion:  TLSProtocolV1_3,
+			wantCipherSuite: []interface{}{"ECDHE-RSA-CHACHA20-POLY1305"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &gatewayController{config: tt.networkConfig}
+			servers := controller.buildServers(&GatewayConfig{
+				Name:      "app-gateway",
+				Namespace: "ns-test",
+				Hosts:     []string{"app.example.com"},
+				TLSConfig: tt.tlsConfig,
+			})
+			if len(servers) != 2 {
+				t.Fatalf("servers = %v, want http and https servers", servers)
+			}
+			tls := servers[1].(map[string]interface{})["tls"].(map[string]interface{})
+			if tls["mode"] != "SIMPLE" || tls["credentialName"] != "app-tls" {
+				t.Errorf("tls = %v, want SIMPLE mode with app-tls credential", tls)
+			}
+			if got := tls["minProtocolVersion"]; got != tt.wantMinVersion {
+				t.Errorf("minProtocolVersion = %v, want %v", got, tt.wantMinVersion)
+			}
+			got, _ := tls["cipherSuites"].([]interface{})
+			if !reflect.DeepEqual(got, tt.wantCipherSuite) {
+				t.Errorf("cipherSuites = %v, want %v", got, tt.wantCipherSuite)
+			}
+		})
+	}
+}
+
+func TestGatewayController_RejectsUnsupportedTLSOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		networkConfig *NetworkConfig
+		tlsConfig     *TLSConfig
+		wantErr       bool
+	}{
+		{name: "tls 1.2 with supported ciphers", networkConfig: &NetworkConfig{}, tlsConfig: &TLSConfig{MinProtocolVersion: TLSProtocolV1_2, CipherSuites: []string{"ECDHE-RSA-AES128-GCM-SHA256"}}},
+		{name: "unknown version", networkConfig: &NetworkConfig{}, tlsConfig: &TLSConfig{MinProtocolVersion: "TLS1.2"}, wantErr: true},
+		{name: "unknown cipher", networkConfig: &NetworkConfig{}, tlsConfig: &TLSConfig{CipherSuites: []string{"RC4-MD5"}}, wantErr: true},
+		{name: "duplicate cipher", networkConfig: &NetworkConfig{}, tlsConfig: &TLSConfig{CipherSuites: []string{"AES128-SHA", "AES128-SHA"}}, wantErr: true},
+		{name: "invalid network config default", networkConfig: &NetworkConfig{GatewayTLSMinProtocolVersion: "SSLV3"}, tlsConfig: &TLSConfig{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(newTestScheme()).Build()
+			controller := NewGatewayController(c, tt.networkConfig)
+			tt.tlsConfig.SecretName = "app-tls"
+			tt.tlsConfig.Hosts = []string{"app.example.com"}
+			err := controller.CreateOrUpdateWithOwner(context.Background(), &GatewayConfig{
+				Name:      "app-gateway",
+				Namespace: "ns-test",
+				Hosts:     []string{"app.example.com"},
+				TLSConfig: tt.tlsConfig,
+			}, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateOrUpdateWithOwner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := ValidateNetworkConfig(&NetworkConfig{
+		DefaultGateway:         "istio-system/sealos-gateway",
+		BaseDomain:             "cloud.sealos.io",
+		GatewayTLSCipherSuites: []string{"NULL-SHA"},
+	}); err == nil {
+		t.Error("ValidateNetworkConfig() expected error for unsupported cipher suite")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import "fmt"
+
+// Istio ServerTLSSettings 支持的 TLS 版本
+const (
+	TLSProtocolAuto = "TLS_AUTO"
+	TLSProtocolV1_0 = "TLSV1_0"
+	TLSProtocolV1_1 = "TLSV1_1"
+	TLSProtocolV1_2 = "TLSV1_2"
+	TLSProtocolV1_3 = "TLSV1_3"
+)
+
+// supportedTLSProtocolVersions Gateway minProtocolVersion 允许的取值
+var supportedTLSProtocolVersions = map[string]bool{
+	TLSProtocolAuto: true,
+	TLSProtocolV1_0: true,
+	TLSProtocolV1_1: true,
+	TLSProtocolV1_2: true,
+	TLSProtocolV1_3: true,
+}
+
+// supportedCipherSuites Istio Gateway（Envoy/BoringSSL）支持的 TLS 1.2 及以下加密套件
+var supportedCipherSuites = map[string]bool{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": true,
+	"ECDHE-RSA-AES128-GCM-SHA256":   true,
+	"ECDHE-ECDSA-AES256-GCM-SHA384": true,
+	"ECDHE-RSA-AES256-GCM-SHA384":   true,
+	"ECDHE-ECDSA-CHACHA20-POLY1305": true,
+	"ECDHE-RSA-CHACHA20-POLY1305":   true,
+	"ECDHE-ECDSA-AES128-SHA":        true,
+	"ECDHE-RSA-AES128-SHA":          true,
+	"ECDHE-ECDSA-AES256-SHA":        true,
+	"ECDHE-RSA-AES256-SHA":          true,
+	"AES128-GCM-SHA256":             true,
+	"AES256-GCM-SHA384":             true,
+	"AES128-SHA":                    true,
+	"AES256-SHA":                    true,
+	"DES-CBC3-SHA":                  true,
+}
+
+// validateTLSOptions 校验最低 TLS 版本和加密套件，空值表示使用 Istio 默认值
+func validateTLSOptions(minProtocolVersion string, cipherSuites []string) error {
+	if minProtocolVersion != "" && !supportedTLSProtocolVersions[minProtocolVersion] {
+		return fmt.Errorf("unsupported TLS min protocol version %q", minProtocolVersion)
+	}
+
+	seen := make(map[string]bool, len(cipherSuites))
+	for _, suite := range cipherSuites {
+		if !supportedCipherSuites[suite] {
+			return fmt.Errorf("unsupported TLS cipher suite %q", suite)
+		}
+		if seen[suite] {
+			return fmt.Errorf("duplicate TLS cipher suite %q", suite)
+		}
+		seen[suite] = true
+	}
+	return nil
+}
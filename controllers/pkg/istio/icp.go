@@ -0,0 +1,131 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrICPRecordNotFound 中国域名没有有效的 ICP 备案
+var ErrICPRecordNotFound = errors.New("no valid ICP record")
+
+// HTTP ICP 查询的默认参数
+const (
+	defaultICPRequestTimeout = 5 * time.Second
+	defaultICPMaxRetries     = 3
+	defaultICPRetryBackoff   = 200 * time.Millisecond
+)
+
+// ICPValidator ICP 备案查询后端
+type ICPValidator interface {
+	// HasValidRecord 查询域名是否有有效的 ICP 备案
+	HasValidRecord(ctx context.Context, domain string) (bool, error)
+}
+
+// HTTPICPValidator 通过 HTTP 接口查询 ICP 备案：GET <endpoint>?domain=<domain>，
+// 响应为 {"registered": bool}；网络错误、429 和 5xx 按指数退避重试
+type HTTPICPValidator struct {
+	endpoint   string
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewHTTPICPValidator 创建基于 HTTP 接口的 ICP 备案查询后端
+func NewHTTPICPValidator(endpoint string) *HTTPICPValidator {
+	return &HTTPICPValidator{
+		endpoint:   endpoint,
+		client:     &http.Client{Timeout: defaultICPRequestTimeout},
+		maxRetries: defaultICPMaxRetries,
+		backoff:    defaultICPRetryBackoff,
+	}
+}
+
+// icpResponse ICP 查询接口的响应
+type icpResponse struct {
+	Registered bool `json:"registered"`
+}
+
+// HasValidRecord 查询域名是否有有效的 ICP 备案
+func (v *HTTPICPValidator) HasValidRecord(ctx context.Context, domain string) (bool, error) {
+	requestURL, err := url.Parse(v.endpoint)
+	if err != nil {
+		return false, fmt.Errorf("invalid ICP validation endpoint %s: %w", v.endpoint, err)
+	}
+	query := requestURL.Query()
+	query.Set("domain", domain)
+	requestURL.RawQuery = query.Encode()
+
+	backoff := v.backoff
+	var lastErr error
+	for attempt := 0; attempt <= v.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return false, fmt.Errorf("ICP lookup for %s canceled: %w (last error: %v)", domain, ctx.Err(), lastErr)
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		registered, retryable, err := v.query(ctx, requestURL.String())
+		if err == nil {
+			return registered, nil
+		}
+		if !retryable {
+			return false, err
+		}
+		lastErr = err
+	}
+	return false, fmt.Errorf("ICP lookup for %s failed after %d attempts: %w", domain, v.maxRetries+1, lastErr)
+}
+
+// query 执行一次 ICP 查询，返回错误是否可以重试
+func (v *HTTPICPValidator) query(ctx context.Context, requestURL string) (bool, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to build ICP request: %w", err)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		// 上下文取消或超时不再重试
+		return false, ctx.Err() == nil, fmt.Errorf("ICP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, true, fmt.Errorf("ICP service returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, false, fmt.Errorf("ICP service returned status %d", resp.StatusCode)
+	}
+
+	var result icpResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, false, fmt.Errorf("failed to decode ICP response: %w", err)
+	}
+	return result.Registered, false, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestICPServer 返回按域名应答的 ICP 查询服务，前 failures 次请求返回 status
+func newTestICPServer(t *testing.T, registered map[string]bool, failures int32, status int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n := atomic.AddInt32(&requests, 1); n <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprintf(w, `{"registered": %t}`, registered[r.URL.Query().Get("domain")])
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func newTestHTTPICPValidator(endpoint string) *HTTPICPValidator {
+	validator := NewHTTPICPValidator(endpoint)
+	validator.backoff = time.Millisecond
+	return validator
+}
+
+func TestHTTPICPValidator_HasValidRecord(t *testing.T) {
+	tests := []struct {
+		name         string
+		domain       string
+		failures     int32
+		status       int
+		want         bool
+		wantErr      bool
+		wantRequests int32
+	}{
+		{name: "registered", domain: "shop.example.cn", want: true, wantRequests: 1},
+		{name: "unregistered", domain: "new.example.cn", want: false, wantRequests: 1},
+		{name: "5xx then success", domain: "shop.example.cn", failures: 2, status: http.StatusServiceUnavailable, want: true, wantRequests: 3},
+		{name: "429 retried", domain: "shop.example.cn", failures: 1, status: http.StatusTooManyRequests, want: true, wantRequests: 2},
+		{name: "persistent 5xx", domain: "shop.example.cn", failures: 100, status: http.StatusInternalServerError, wantErr: true, wantRequests: defaultICPMaxRetries + 1},
+		{name: "4xx not retried", domain: "shop.example.cn", failures: 100, status: http.StatusBadRequest, wantErr: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newTestICPServer(t, map[string]bool{"shop.example.cn": true}, tt.failures, tt.status)
+			got, err := newTestHTTPICPValidator(server.URL).HasValidRecord(context.Background(), tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HasValidRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("HasValidRecord() = %v, want %v", got, tt.want)
+			}
+			if n := atomic.LoadInt32(requests); n != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDomainAllocator_ValidateCustomDomain_ICP(t *testing.T) {
+	server, _ := newTestICPServer(t, map[string]bool{"shop.example.cn": true}, 0, 0)
+	config := &NetworkConfig{BaseDomain: "cloud.sealos.io", SkipDNSValidation: true}
+
+	tests := []struct {
+		name      string
+		validator ICPValidator
+		domain    string
+		wantErr   bool
+	}{
+		{name: "no validator skips ICP check", domain: "new.example.cn"},
+		{name: "registered china domain", validator: newTestHTTPICPValidator(server.URL), domain: "shop.example.cn"},
+		{name: "unregistered china domain", validator: newTestHTTPICPValidator(server.URL), domain: "new.example.cn", wantErr: true},
+		{name: "non-china domain not checked", validator: newTestHTTPICPValidator(server.URL), domain: "new.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator := NewDomainAllocatorWithICPValidator(config, tt.validator)
+			err := allocator.ValidateCustomDomain(context.Background(), tt.domain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateCustomDomain(%s) error = %v, wantErr %v", tt.domain, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrICPRecordNotFound) {
+				t.Errorf("ValidateCustomDomain(%s) error = %v, want ErrICPRecordNotFound", tt.domain, err)
+			}
+		})
+	}
+}
@@ -57,14 +57,17 @@ func NewNetworkingManager(client client.Client, config *NetworkConfig) Networkin
 func (m *networkingManager) CreateAppNetworking(ctx context.Context, spec *AppNetworkingSpec) error {
 	// 1. 分配域名（如果没有指定）
 	if len(spec.Hosts) == 0 {
-		domain := m.domainAllocator.GenerateAppDomain(spec.TenantID, spec.AppName)
+		domain, err := m.domainAllocator.ClaimAppDomain(ctx, spec.TenantID, spec.AppName)
+		if err != nil {
+			return err
+		}
 		spec.Hosts = []string{domain}
 	}
 
 	// 2. 验证自定义域名（如果有）
 	for _, host := range spec.Hosts {
 		if !strings.HasSuffix(host, m.config.BaseDomain) {
-			if err := m.domainAllocator.ValidateCustomDomain(host); err != nil {
+			if err := m.domainAllocator.ValidateCustomDomain(ctx, host); err != nil {
 				return fmt.Errorf("invalid custom domain %s: %w", host, err)
 			}
 		}
@@ -241,6 +244,7 @@ func (m *networkingManager) GetNetworkingStatus(ctx context.Context, name, names
 
 	status.VirtualServiceReady = vs.Ready
 	status.Hosts = vs.Hosts
+	status.ServicePort = vs.ServicePort
 
 	// 检查 Gateway 状态（如果存在）
 	gatewayName := m.getGatewayNameFromApp(name)
@@ -273,7 +277,7 @@ func (m *networkingManager) buildVirtualServiceConfig(spec *AppNetworkingSpec, g
 
 	// 设置 Gateway
 	if m.config.SharedGatewayEnabled && spec.TLSConfig == nil {
-		config.Gateways = []string{m.config.DefaultGateway}
+		config.Gateways = []string{ShardedGatewayName(m.config.DefaultGateway, spec.Namespace, m.config.SharedGatewayShards)}
 	} else {
 		config.Gateways = []string{gatewayName}
 	}
@@ -312,7 +316,7 @@ func (m *networkingManager) buildLabels(spec *AppNetworkingSpec) map[string]stri
 // getGatewayName 获取 Gateway 名称
 func (m *networkingManager) getGatewayName(spec *AppNetworkingSpec) string {
 	if m.config.SharedGatewayEnabled && spec.TLSConfig == nil {
-		return m.config.DefaultGateway
+		return ShardedGatewayName(m.config.DefaultGateway, spec.Namespace, m.config.SharedGatewayShards)
 	}
 	return fmt.Sprintf("%s-gateway", spec.Name)
 }
@@ -0,0 +1,115 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// DefaultModeReevaluateInterval 默认的 Istio 模式重新检查间隔
+const DefaultModeReevaluateInterval = 30 * time.Second
+
+// PromoteFunc 将控制器切换到 Istio 模式，返回切换后是否已处于 Istio 模式
+type PromoteFunc func(ctx context.Context) (bool, error)
+
+// ModeReevaluator 在控制器回退到 Ingress 模式后周期性检查 Istio CRD，
+// CRD 就绪后调用 promote 切换到 Istio 模式，无需重启控制器。
+// 实现 manager.Runnable，切换成功后退出。
+type ModeReevaluator struct {
+	client   Client
+	interval time.Duration
+	promote  PromoteFunc
+}
+
+// NewModeReevaluator 创建 Istio 模式重新检查器，interval 为 0 时使用 DefaultModeReevaluateInterval
+func NewModeReevaluator(client Client, interval time.Duration, promote PromoteFunc) *ModeReevaluator {
+	if interval <= 0 {
+		interval = DefaultModeReevaluateInterval
+	}
+	return &ModeReevaluator{
+		client:   client,
+		interval: interval,
+		promote:  promote,
+	}
+}
+
+// Start 周期性检查直到切换成功或 ctx 结束
+func (m *ModeReevaluator) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("istio-mode-reevaluator")
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if promoted := m.reevaluate(ctx); promoted {
+			logger.Info("Istio CRDs became available, switched to Istio mode")
+			return nil
+		}
+	}
+}
+
+// reevaluate 执行一次检查，返回是否已切换到 Istio 模式
+func (m *ModeReevaluator) reevaluate(ctx context.Context) bool {
+	enabled, err := IsIstioEnabled(m.client)
+	if err != nil || !enabled {
+		return false
+	}
+
+	promoted, err := m.promote(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to switch to Istio mode, will retry")
+		return false
+	}
+	return promoted
+}
+
+// NeedLeaderElection 每个副本都需要切换自身的网络模式，不依赖选主
+func (m *ModeReevaluator) NeedLeaderElection() bool {
+	return false
+}
+
+// EnqueueExisting 返回把 list 类型的全部现有对象加入协调队列的 Source。
+// 切换到 Istio 模式后通过 Controller.Watch 注册，让已按 Ingress 模式协调过的资源补建 Istio 网络资源
+func EnqueueExisting(reader client.Reader, list client.ObjectList) source.Source {
+	return source.Func(func(ctx context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+		items := list.DeepCopyObject().(client.ObjectList)
+		if err := reader.List(ctx, items); err != nil {
+			return err
+		}
+		return meta.EachListItem(items, func(obj runtime.Object) error {
+			if o, ok := obj.(client.Object); ok {
+				queue.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+			}
+			return nil
+		})
+	})
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// crdToggleClient 模拟 Istio CRD 在控制器启动后才安装完成
+type crdToggleClient struct {
+	client.Client
+	installed atomic.Bool
+}
+
+func (c *crdToggleClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if list.GetObjectKind().GroupVersionKind().Group == "networking.istio.io" && !c.installed.Load() {
+		return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "networking.istio.io", Kind: "Gateway"}}
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func newCRDToggleClient() *crdToggleClient {
+	return &crdToggleClient{Client: fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()}
+}
+
+func TestModeReevaluator_PromotesWhenCRDsAppear(t *testing.T) {
+	c := newCRDToggleClient()
+	var useIstio atomic.Bool
+	var promoteCalls atomic.Int32
+	reevaluator := NewModeReevaluator(c, 5*time.Millisecond, func(ctx context.Context) (bool, error) {
+		promoteCalls.Add(1)
+		useIstio.Store(true)
+		return true, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- reevaluator.Start(ctx) }()
+
+	// CRD 未安装时保持 Ingress 模式
+	time.Sleep(30 * time.Millisecond)
+	if useIstio.Load() || promoteCalls.Load() != 0 {
+		t.Fatal("should not promote before Istio CRDs are installed")
+	}
+
+	c.installed.Store(true)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("reevaluator did not promote after Istio CRDs became available")
+	}
+	if !useIstio.Load() || promoteCalls.Load() != 1 {
+		t.Errorf("useIstio = %v, promote calls = %d", useIstio.Load(), promoteCalls.Load())
+	}
+}
+
+func TestModeReevaluator_RetriesFailedPromotion(t *testing.T) {
+	c := newCRDToggleClient()
+	c.installed.Store(true)
+	var attempts atomic.Int32
+	reevaluator := NewModeReevaluator(c, 5*time.Millisecond, func(ctx context.Context) (bool, error) {
+		switch attempts.Add(1) {
+		case 1:
+			return false, errors.New("validation failed")
+		case 2:
+			// 校验失败时控制器保持 Ingress 模式
+			return false, nil
+		}
+		return true, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := reevaluator.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("reevaluator did not promote before timeout")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("promote attempts = %d, want 3", got)
+	}
+}
+
+func TestModeReevaluator_StopsWithContext(t *testing.T) {
+	reevaluator := NewModeReevaluator(newCRDToggleClient(), time.Millisecond, func(ctx context.Context) (bool, error) {
+		t.Error("promote should not be called without Istio CRDs")
+		return false, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := reevaluator.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if reevaluator.NeedLeaderElection() {
+		t.Error("every replica must reevaluate its own mode")
+	}
+}
+
+func TestEnqueueExisting(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "ns1"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app2", Namespace: "ns2"}},
+	).Build()
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	if err := EnqueueExisting(c, &corev1.ConfigMapList{}).Start(context.Background(), queue); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if got := queue.Len(); got != 2 {
+		t.Fatalf("queue length = %d, want 2", got)
+	}
+	got := map[types.NamespacedName]bool{}
+	for queue.Len() > 0 {
+		req, _ := queue.Get()
+		got[req.NamespacedName] = true
+		queue.Done(req)
+	}
+	for _, want := range []types.NamespacedName{{Namespace: "ns1", Name: "app1"}, {Namespace: "ns2", Name: "app2"}} {
+		if !got[want] {
+			t.Errorf("request %s was not enqueued", want)
+		}
+	}
+}
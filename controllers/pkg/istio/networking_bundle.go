@@ -0,0 +1,197 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AppNetworkingBundleVersion 应用网络配置导出格式版本
+const AppNetworkingBundleVersion = "network.sealos.io/v1"
+
+// bundleResourceGVKs 导出/导入的资源类型，按导入顺序排列（VirtualService 引用 Gateway 和 DestinationRule 子集）
+var bundleResourceGVKs = []schema.GroupVersionKind{gatewayGVK, destinationRuleGVK, virtualServiceGVK}
+
+// AppNetworkingBundle 应用网络配置的可移植快照，用于灾备和跨集群迁移
+type AppNetworkingBundle struct {
+	Version    string                   `json:"version"`
+	Namespace  string                   `json:"namespace"`
+	App        string                   `json:"app"`
+	ExportedAt time.Time                `json:"exportedAt"`
+	Resources  []map[string]interface{} `json:"resources"`
+}
+
+// ExportAppNetworking 导出应用的 Gateway、VirtualService 和 DestinationRule。
+// 只保留可移植的字段（标签、注解和 spec），OwnerReference、UID、status 等集群相关信息不导出。
+func (h *UniversalIstioNetworkingHelper) ExportAppNetworking(ctx context.Context, namespace, app string) ([]byte, error) {
+	bundle := &AppNetworkingBundle{
+		Version:    AppNetworkingBundleVersion,
+		Namespace:  namespace,
+		App:        app,
+		ExportedAt: time.Now().UTC(),
+		Resources:  []map[string]interface{}{},
+	}
+
+	for _, gvk := range bundleResourceGVKs {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := h.client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+
+		items := list.Items
+		sort.Slice(items, func(i, j int) bool { return items[i].GetName() < items[j].GetName() })
+		for i := range items {
+			if belongsToApp(&items[i], gvk, app) {
+				bundle.Resources = append(bundle.Resources, portableResource(&items[i], gvk))
+			}
+		}
+	}
+
+	if len(bundle.Resources) == 0 {
+		return nil, fmt.Errorf("no networking resources found for app %s/%s", namespace, app)
+	}
+
+	return json.MarshalIndent(bundle, "", "  ")
+}
+
+// ImportAppNetworking 将导出的网络配置应用到当前集群，已存在的资源会被覆盖
+func (h *UniversalIstioNetworkingHelper) ImportAppNetworking(ctx context.Context, data []byte) error {
+	bundle := &AppNetworkingBundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return fmt.Errorf("failed to decode networking bundle: %w", err)
+	}
+	if bundle.Version != AppNetworkingBundleVersion {
+		return fmt.Errorf("unsupported networking bundle version %q, expected %q", bundle.Version, AppNetworkingBundleVersion)
+	}
+	if bundle.Namespace == "" {
+		return fmt.Errorf("networking bundle namespace cannot be empty")
+	}
+
+	resources := make([]*unstructured.Unstructured, 0, len(bundle.Resources))
+	for i, object := range bundle.Resources {
+		resource := &unstructured.Unstructured{Object: object}
+		if !isBundleResourceGVK(resource.GroupVersionKind()) {
+			return fmt.Errorf("resources[%d]: unsupported kind %s", i, resource.GroupVersionKind())
+		}
+		if resource.GetNamespace() != bundle.Namespace {
+			return fmt.Errorf("resources[%d]: %s %s is not in bundle namespace %s",
+				i, resource.GetKind(), resource.GetName(), bundle.Namespace)
+		}
+		resources = append(resources, resource)
+	}
+
+	// 全部校验通过后再按 Gateway、DestinationRule、VirtualService 顺序应用，避免部分导入
+	for _, gvk := range bundleResourceGVKs {
+		for _, resource := range resources {
+			if resource.GroupVersionKind() != gvk {
+				continue
+			}
+			if err := h.applyBundleResource(ctx, resource); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyBundleResource 创建或覆盖资源
+func (h *UniversalIstioNetworkingHelper) applyBundleResource(ctx context.Context, resource *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(resource.GroupVersionKind())
+	err := h.client.Get(ctx, client.ObjectKeyFromObject(resource), existing)
+	if apierrors.IsNotFound(err) {
+		if err := h.client.Create(ctx, resource); err != nil {
+			return fmt.Errorf("failed to create %s %s/%s: %w", resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s/%s: %w", resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+	}
+
+	existing.SetLabels(resource.GetLabels())
+	existing.SetAnnotations(resource.GetAnnotations())
+	spec, _, _ := unstructured.NestedMap(resource.Object, "spec")
+	if err := unstructured.SetNestedMap(existing.Object, spec, "spec"); err != nil {
+		return fmt.Errorf("failed to set %s spec: %w", resource.GetKind(), err)
+	}
+	if err := h.client.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update %s %s/%s: %w", resource.GetKind(), resource.GetNamespace(), resource.GetName(), err)
+	}
+	return nil
+}
+
+// belongsToApp 判断资源是否属于应用：按 app.kubernetes.io/name 标签或 <app>-gateway/<app>-vs/<app>-dr 命名约定
+func belongsToApp(resource *unstructured.Unstructured, gvk schema.GroupVersionKind, app string) bool {
+	if resource.GetLabels()["app.kubernetes.io/name"] == app {
+		return true
+	}
+
+	switch gvk.Kind {
+	case gatewayGVK.Kind:
+		return resource.GetName() == fmt.Sprintf("%s-gateway", app)
+	case virtualServiceGVK.Kind:
+		return resource.GetName() == fmt.Sprintf("%s-vs", app)
+	case destinationRuleGVK.Kind:
+		return resource.GetName() == fmt.Sprintf("%s-dr", app)
+	}
+	return false
+}
+
+// portableResource 只保留可以在其他集群重新应用的字段
+func portableResource(resource *unstructured.Unstructured, gvk schema.GroupVersionKind) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name":      resource.GetName(),
+		"namespace": resource.GetNamespace(),
+	}
+	if labels := resource.GetLabels(); len(labels) > 0 {
+		metadata["labels"] = makeSafeForDeepCopy(labels)
+	}
+	if annotations := resource.GetAnnotations(); len(annotations) > 0 {
+		metadata["annotations"] = makeSafeForDeepCopy(annotations)
+	}
+
+	object := map[string]interface{}{
+		"apiVersion": gvk.GroupVersion().String(),
+		"kind":       gvk.Kind,
+		"metadata":   metadata,
+	}
+	if spec, found, _ := unstructured.NestedMap(resource.Object, "spec"); found {
+		object["spec"] = spec
+	}
+	return object
+}
+
+func isBundleResourceGVK(gvk schema.GroupVersionKind) bool {
+	for _, supported := range bundleResourceGVKs {
+		if gvk == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestBundleResource(gvk schema.GroupVersionKind, name, namespace, app string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	if app != "" {
+		obj.SetLabels(map[string]string{
+			"app.kubernetes.io/name":       app,
+			"app.kubernetes.io/managed-by": "sealos-istio",
+		})
+	}
+	obj.SetAnnotations(map[string]string{"network.sealos.io/note": "exported"})
+	obj.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: "terminal.sealos.io/v1",
+		Kind:       "Terminal",
+		Name:       app,
+		UID:        "source-cluster-uid",
+	}})
+	obj.Object["spec"] = spec
+	obj.Object["status"] = map[string]interface{}{"observedGeneration": int64(1)}
+	return obj
+}
+
+func newTestBundleHelper(c client.Client) *UniversalIstioNetworkingHelper {
+	return NewUniversalIstioNetworkingHelper(c, &NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+	}, "terminal")
+}
+
+func TestUniversalIstioNetworkingHelper_ExportImportAppNetworking(t *testing.T) {
+	ctx := context.Background()
+	namespace := "ns-test"
+
+	gateway := newTestBundleResource(gatewayGVK, "test-app-gateway", namespace, "test-app", map[string]interface{}{
+		"selector": map[string]interface{}{"istio": "ingressgateway"},
+		"servers": []interface{}{map[string]interface{}{
+			"hosts": []interface{}{"app.example.com"},
+			"port":  map[string]interface{}{"name": "http", "number": int64(80), "protocol": "HTTP"},
+		}},
+	})
+	destinationRule := newTestBundleResource(destinationRuleGVK, "test-app-dr", namespace, "", map[string]interface{}{
+		"host": "test-svc",
+		"subsets": []interface{}{
+			map[string]interface{}{"name": "v1", "labels": map[string]interface{}{"version": "v1"}},
+			map[string]interface{}{"name": "v2", "labels": map[string]interface{}{"version": "v2"}},
+		},
+	})
+	virtualService := newTestBundleResource(virtualServiceGVK, "test-app-vs", namespace, "test-app", map[string]interface{}{
+		"hosts":    []interface{}{"app.example.com"},
+		"gateways": []interface{}{namespace + "/test-app-gateway"},
+		"http": []interface{}{map[string]interface{}{
+			"route": []interface{}{
+				map[string]interface{}{"destination": map[string]interface{}{"host": "test-svc", "subset": "v1"}, "weight": int64(90)},
+				map[string]interface{}{"destination": map[string]interface{}{"host": "test-svc", "subset": "v2"}, "weight": int64(10)},
+			},
+		}},
+	})
+	otherApp := newTestBundleResource(virtualServiceGVK, "other-app-vs", namespace, "other-app", map[string]interface{}{
+		"hosts": []interface{}{"other.example.com"},
+	})
+
+	source := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).
+		WithObjects(gateway, destinationRule, virtualService, otherApp).Build()
+	data, err := newTestBundleHelper(source).ExportAppNetworking(ctx, namespace, "test-app")
+	if err != nil {
+		t.Fatalf("ExportAppNetworking() error = %v", err)
+	}
+
+	bundle := &AppNetworkingBundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		t.Fatalf("exported bundle is not valid JSON: %v", err)
+	}
+	if bundle.Version != AppNetworkingBundleVersion {
+		t.Errorf("bundle version = %q, want %q", bundle.Version, AppNetworkingBundleVersion)
+	}
+	if len(bundle.Resources) != 3 {
+		t.Fatalf("exported %d resources, want 3 (other apps must not be included)", len(bundle.Resources))
+	}
+	for _, field := range []string{"ownerReferences", "resourceVersion", "uid", "\"status\""} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("exported bundle should not contain cluster-specific field %s", field)
+		}
+	}
+
+	target := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	importer := newTestBundleHelper(target)
+	if err := importer.ImportAppNetworking(ctx, data); err != nil {
+		t.Fatalf("ImportAppNetworking() error = %v", err)
+	}
+	// 重复导入应覆盖已有资源
+	if err := importer.ImportAppNetworking(ctx, data); err != nil {
+		t.Fatalf("ImportAppNetworking() on existing resources error = %v", err)
+	}
+
+	for _, want := range []*unstructured.Unstructured{gateway, destinationRule, virtualService} {
+		got := &unstructured.Unstructured{}
+		got.SetGroupVersionKind(want.GroupVersionKind())
+		if err := target.Get(ctx, client.ObjectKeyFromObject(want), got); err != nil {
+			t.Fatalf("imported %s %s not found: %v", want.GetKind(), want.GetName(), err)
+		}
+		if !reflect.DeepEqual(got.Object["spec"], want.Object["spec"]) {
+			t.Errorf("%s spec = %v, want %v", want.GetKind(), got.Object["spec"], want.Object["spec"])
+		}
+		if !reflect.DeepEqual(got.GetLabels(), want.GetLabels()) {
+			t.Errorf("%s labels = %v, want %v", want.GetKind(), got.GetLabels(), want.GetLabels())
+		}
+		if !reflect.DeepEqual(got.GetAnnotations(), want.GetAnnotations()) {
+			t.Errorf("%s annotations = %v, want %v", want.GetKind(), got.GetAnnotations(), want.GetAnnotations())
+		}
+		if len(got.GetOwnerReferences()) != 0 {
+			t.Errorf("%s should not carry owner references from the source cluster", want.GetKind())
+		}
+	}
+
+	missing := &unstructured.Unstructured{}
+	missing.SetGroupVersionKind(virtualServiceGVK)
+	if err := target.Get(ctx, client.ObjectKeyFromObject(otherApp), missing); err == nil {
+		t.Error("resources of other apps should not be imported")
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_ExportAppNetworkingNotFound(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	helper := newTestBundleHelper(c)
+	if _, err := helper.ExportAppNetworking(context.Background(), "ns-test", "missing"); err == nil {
+		t.Error("ExportAppNetworking() should fail when the app has no networking resources")
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_ImportAppNetworkingRejectsInvalidBundle(t *testing.T) {
+	vs := portableResource(newTestBundleResource(virtualServiceGVK, "test-app-vs", "ns-test", "test-app",
+		map[string]interface{}{"hosts": []interface{}{"app.example.com"}}), virtualServiceGVK)
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "token", "namespace": "ns-test"},
+	}
+
+	tests := []struct {
+		name   string
+		bundle AppNetworkingBundle
+	}{
+		{
+			name:   "unknown version",
+			bundle: AppNetworkingBundle{Version: "network.sealos.io/v0", Namespace: "ns-test", Resources: []map[string]interface{}{vs}},
+		},
+		{
+			name:   "missing namespace",
+			bundle: AppNetworkingBundle{Version: AppNetworkingBundleVersion, Resources: []map[string]interface{}{vs}},
+		},
+		{
+			name:   "resource outside bundle namespace",
+			bundle: AppNetworkingBundle{Version: AppNetworkingBundleVersion, Namespace: "ns-other", Resources: []map[string]interface{}{vs}},
+		},
+		{
+			name:   "unsupported kind",
+			bundle: AppNetworkingBundle{Version: AppNetworkingBundleVersion, Namespace: "ns-test", Resources: []map[string]interface{}{vs, secret}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.bundle)
+			if err != nil {
+				t.Fatalf("failed to marshal bundle: %v", err)
+			}
+
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+			helper := newTestBundleHelper(c)
+			if err := helper.ImportAppNetworking(context.Background(), data); err == nil {
+				t.Fatal("ImportAppNetworking() should reject the bundle")
+			}
+
+			// 校验失败时不应部分导入
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(virtualServiceGVK)
+			if err := c.Get(context.Background(), client.ObjectKey{Namespace: "ns-test", Name: "test-app-vs"}, got); err == nil {
+				t.Error("no resource should be applied from a rejected bundle")
+			}
+		})
+	}
+}
@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // optimizedNetworkingManager 优化的网络管理器实现
@@ -34,6 +37,7 @@ type optimizedNetworkingManager struct {
 	config            *NetworkConfig
 	gatewayController GatewayController
 	vsController      VirtualServiceController
+	drController      DestinationRuleController
 	domainAllocator   DomainAllocator
 	certManager       CertificateManager
 	domainClassifier  *DomainClassifier // 新增域名分类器
@@ -63,6 +67,7 @@ func NewOptimizedNetworkingManagerWithScheme(client client.Client, scheme *runti
 		config:            config,
 		gatewayController: gatewayCtrl,
 		vsController:      vsCtrl,
+		drController:      NewDestinationRuleController(client, config),
 		domainAllocator:   domainAlloc,
 		certManager:       certMgr,
 		domainClassifier:  domainClassifier,
@@ -72,12 +77,18 @@ func NewOptimizedNetworkingManagerWithScheme(client client.Client, scheme *runti
 func (m *optimizedNetworkingManager) CreateAppNetworking(ctx context.Context, spec *AppNetworkingSpec) error {
 	// 1. 分配域名（如果没有指定）
 	if len(spec.Hosts) == 0 {
-		domain := m.domainAllocator.GenerateAppDomain(spec.TenantID, spec.AppName)
+		domain, err := m.generateDomain(ctx, spec)
+		if err != nil {
+			return err
+		}
 		spec.Hosts = []string{domain}
 	}
 
 	// 2. 验证自定义域名
-	if err := m.validateCustomDomains(spec.Hosts); err != nil {
+	if err := m.validateCustomDomains(ctx, spec.Namespace, spec.Hosts); err != nil {
+		return err
+	}
+	if err := m.checkCustomDomainCAA(ctx, spec); err != nil {
 		return err
 	}
 
@@ -96,11 +107,21 @@ func (m *optimizedNetworkingManager) CreateAppNetworking(ctx context.Context, sp
 		return err
 	}
 
-	// 6. 创建优化的 VirtualService
+	// 6. 创建负载均衡 DestinationRule
+	if err := m.syncDestinationRule(ctx, spec); err != nil {
+		return err
+	}
+
+	// 7. 创建优化的 VirtualService
 	if err := m.createOptimizedVirtualService(ctx, spec); err != nil {
 		return err
 	}
 
+	// 8. 按需开启网关访问日志
+	if err := syncAccessLogEnvoyFilter(ctx, m.client, m.config, spec); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -115,11 +136,21 @@ func (m *optimizedNetworkingManager) UpdateAppNetworking(ctx context.Context, sp
 		return err
 	}
 
-	// 3. 更新 VirtualService
+	// 3. 更新负载均衡 DestinationRule
+	if err := m.syncDestinationRule(ctx, spec); err != nil {
+		return err
+	}
+
+	// 4. 更新 VirtualService
 	if err := m.updateOptimizedVirtualService(ctx, spec); err != nil {
 		return err
 	}
 
+	// 5. 更新网关访问日志
+	if err := syncAccessLogEnvoyFilter(ctx, m.client, m.config, spec); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -130,7 +161,17 @@ func (m *optimizedNetworkingManager) DeleteAppNetworking(ctx context.Context, na
 		return fmt.Errorf("failed to delete virtualservice: %w", err)
 	}
 
-	// 2. 删除 Gateway（如果存在且不是系统Gateway）
+	// 2. 删除负载均衡 DestinationRule
+	if err := m.drController.Delete(ctx, fmt.Sprintf("%s-dr", name), namespace); err != nil {
+		return fmt.Errorf("failed to delete destinationrule: %w", err)
+	}
+
+	// 3. 删除网关命名空间中的访问日志 EnvoyFilter（跨命名空间，不会随 OwnerReference 回收）
+	if err := deleteAccessLogEnvoyFilter(ctx, m.client, m.config, name, namespace); err != nil {
+		return err
+	}
+
+	// 4. 删除 Gateway（如果存在且不是系统Gateway）
 	gatewayName := fmt.Sprintf("%s-gateway", name)
 	if exists, err := m.gatewayController.Exists(ctx, gatewayName, namespace); err != nil {
 		return fmt.Errorf("failed to check gateway existence: %w", err)
@@ -206,6 +247,7 @@ func (m *optimizedNetworkingManager) GetNetworkingStatus(ctx context.Context, na
 
 	status.VirtualServiceReady = vs.Ready
 	status.Hosts = vs.Hosts
+	status.ServicePort = vs.ServicePort
 
 	// 检查 Gateway 状态
 	gatewayName := fmt.Sprintf("%s-gateway", name)
@@ -216,15 +258,40 @@ func (m *optimizedNetworkingManager) GetNetworkingStatus(ctx context.Context, na
 		// 使用系统 Gateway，假设总是就绪
 		status.GatewayReady = true
 	}
+	status.AccessLog = getAccessLogConfig(ctx, m.client, m.config, name, namespace)
 
 	return status, nil
 }
 
+// generateDomain 自动分配并登记域名，命名空间覆盖了基础域名时使用覆盖的基础域名
+func (m *optimizedNetworkingManager) generateDomain(ctx context.Context, spec *AppNetworkingSpec) (string, error) {
+	if namespaced := configForNamespace(m.config, spec.Namespace); namespaced != m.config {
+		return NewDomainAllocator(namespaced).ClaimAppDomain(ctx, spec.TenantID, spec.AppName)
+	}
+	return m.domainAllocator.ClaimAppDomain(ctx, spec.TenantID, spec.AppName)
+}
+
 // validateCustomDomains 验证自定义域名
-func (m *optimizedNetworkingManager) validateCustomDomains(hosts []string) error {
+func (m *optimizedNetworkingManager) validateCustomDomains(ctx context.Context, namespace string, hosts []string) error {
 	for _, host := range hosts {
-		if !m.domainClassifier.IsPublicDomain(host) {
-			if err := m.domainAllocator.ValidateCustomDomain(host); err != nil {
+		if !m.domainClassifier.IsPublicDomainForNamespace(namespace, host) {
+			if err := m.domainAllocator.ValidateCustomDomain(ctx, host); err != nil {
+				return fmt.Errorf("invalid custom domain %s: %w", host, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkCustomDomainCAA 申请证书前检查自定义域名的 CAA 记录是否允许配置的 CA 签发
+func (m *optimizedNetworkingManager) checkCustomDomainCAA(ctx context.Context, spec *AppNetworkingSpec) error {
+	if !m.config.TLSEnabled || spec.TLSConfig == nil {
+		return nil
+	}
+
+	for _, host := range spec.TLSConfig.Hosts {
+		if !m.domainClassifier.IsPublicDomainForNamespace(spec.Namespace, host) {
+			if err := m.domainAllocator.CheckCAA(ctx, host, m.config.ACMECAIdentifiers); err != nil {
 				return fmt.Errorf("invalid custom domain %s: %w", host, err)
 			}
 		}
@@ -240,7 +307,7 @@ func (m *optimizedNetworkingManager) handleCertificates(ctx context.Context, spe
 
 	// 只为自定义域名创建证书，公共域名使用系统证书
 	for _, host := range spec.TLSConfig.Hosts {
-		if !m.domainClassifier.IsPublicDomain(host) {
+		if !m.domainClassifier.IsPublicDomainForNamespace(spec.Namespace, host) {
 			// 创建或更新证书
 			if err := m.certManager.CreateOrUpdate(ctx, host, spec.Namespace); err != nil {
 				return fmt.Errorf("failed to create certificate for custom domain %s: %w", host, err)
@@ -251,7 +318,7 @@ func (m *optimizedNetworkingManager) handleCertificates(ctx context.Context, spe
 				secretName := certMgr.getSecretName(host)
 				if ready, err := certMgr.IsCertificateReady(ctx, secretName, spec.Namespace); err != nil {
 					return fmt.Errorf("failed to check certificate readiness for %s: %w", host, err)
-				} else if !ready {
+				} else if !ready && !m.config.WithholdHostsUntilCertReady {
 					return fmt.Errorf("certificate not ready for custom domain %s, please check cert-manager status", host)
 				}
 			}
@@ -261,6 +328,62 @@ func (m *optimizedNetworkingManager) handleCertificates(ctx context.Context, spe
 	return nil
 }
 
+// checkGatewayTLSSecret 检查 Gateway 引用的 TLS Secret 是否存在于 Gateway 所在命名空间，
+// 避免创建引用不存在凭据的 Gateway 导致 TLS 握手静默失败；不存在时返回 ErrTLSSecretNotFound
+func (m *optimizedNetworkingManager) checkGatewayTLSSecret(ctx context.Context, gatewayConfig *GatewayConfig) error {
+	if gatewayConfig.TLSConfig == nil || gatewayConfig.TLSConfig.SecretName == "" {
+		return nil
+	}
+
+	key := client.ObjectKey{Name: gatewayConfig.TLSConfig.SecretName, Namespace: gatewayConfig.Namespace}
+	if err := m.client.Get(ctx, key, &corev1.Secret{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: secret %s referenced by gateway %s/%s", ErrTLSSecretNotFound, key, gatewayConfig.Namespace, gatewayConfig.Name)
+		}
+		return fmt.Errorf("failed to get tls secret %s: %w", key, err)
+	}
+	return nil
+}
+
+// withholdUnreadyTLSHosts 开启 WithholdHostsUntilCertReady 时，TLS Secret 不存在或缺少 tls.crt/tls.key 前
+// 从 Gateway 配置中移除启用 TLS 的自定义域名；没有剩余域名时返回 nil，表示暂不需要 Gateway
+func (m *optimizedNetworkingManager) withholdUnreadyTLSHosts(ctx context.Context, gatewayConfig *GatewayConfig) (*GatewayConfig, error) {
+	if !m.config.WithholdHostsUntilCertReady || gatewayConfig.TLSConfig == nil || gatewayConfig.TLSConfig.SecretName == "" {
+		return gatewayConfig, nil
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Name: gatewayConfig.TLSConfig.SecretName, Namespace: gatewayConfig.Namespace}
+	if err := m.client.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get tls secret %s: %w", key, err)
+		}
+	} else if len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0 {
+		return gatewayConfig, nil
+	}
+
+	withheld := make(map[string]bool, len(gatewayConfig.TLSConfig.Hosts))
+	for _, host := range gatewayConfig.TLSConfig.Hosts {
+		withheld[host] = true
+	}
+	hosts := make([]string, 0, len(gatewayConfig.Hosts))
+	for _, host := range gatewayConfig.Hosts {
+		if !withheld[host] {
+			hosts = append(hosts, host)
+		}
+	}
+	log.FromContext(ctx).Info("tls secret not populated yet, withholding hosts from gateway",
+		"gateway", gatewayConfig.Namespace+"/"+gatewayConfig.Name, "secret", key.String(), "hosts", gatewayConfig.TLSConfig.Hosts)
+
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+	withheldConfig := *gatewayConfig
+	withheldConfig.Hosts = hosts
+	withheldConfig.TLSConfig = nil
+	return &withheldConfig, nil
+}
+
 // createOptimizedGateway 智能创建Gateway
 func (m *optimizedNetworkingManager) createOptimizedGateway(ctx context.Context, spec *AppNetworkingSpec) error {
 	// 使用域名分类器构建优化的Gateway配置
@@ -270,6 +393,16 @@ func (m *optimizedNetworkingManager) createOptimizedGateway(ctx context.Context,
 	if gatewayConfig == nil {
 		return nil
 	}
+	gatewayConfig, err := m.withholdUnreadyTLSHosts(ctx, gatewayConfig)
+	if err != nil {
+		return err
+	}
+	if gatewayConfig == nil {
+		return nil
+	}
+	if err := m.checkGatewayTLSSecret(ctx, gatewayConfig); err != nil {
+		return err
+	}
 
 	// 🎯 使用支持 OwnerReference 的方法创建Gateway
 	if spec.OwnerObject != nil && m.scheme != nil {
@@ -288,9 +421,15 @@ func (m *optimizedNetworkingManager) createOptimizedGateway(ctx context.Context,
 // updateOptimizedGateway 智能更新Gateway
 func (m *optimizedNetworkingManager) updateOptimizedGateway(ctx context.Context, spec *AppNetworkingSpec) error {
 	gatewayConfig := m.domainClassifier.BuildOptimizedGatewayConfig(spec)
+	if gatewayConfig != nil {
+		var err error
+		if gatewayConfig, err = m.withholdUnreadyTLSHosts(ctx, gatewayConfig); err != nil {
+			return err
+		}
+	}
 
 	if gatewayConfig == nil {
-		// 不需要Gateway，删除如果存在
+		// 不需要Gateway（或域名全部等待证书），删除如果存在
 		gatewayName := fmt.Sprintf("%s-gateway", spec.Name)
 		if exists, err := m.gatewayController.Exists(ctx, gatewayName, spec.Namespace); err != nil {
 			return fmt.Errorf("failed to check gateway existence: %w", err)
@@ -301,6 +440,9 @@ func (m *optimizedNetworkingManager) updateOptimizedGateway(ctx context.Context,
 		}
 		return nil
 	}
+	if err := m.checkGatewayTLSSecret(ctx, gatewayConfig); err != nil {
+		return err
+	}
 
 	// 🎯 使用支持 OwnerReference 的方法（总是使用 CreateOrUpdate）
 	if spec.OwnerObject != nil && m.scheme != nil {
@@ -327,6 +469,29 @@ func (m *optimizedNetworkingManager) updateOptimizedGateway(ctx context.Context,
 	return nil
 }
 
+// syncDestinationRule 按负载均衡配置创建或更新 <name>-dr，未配置时删除已有的 DestinationRule
+func (m *optimizedNetworkingManager) syncDestinationRule(ctx context.Context, spec *AppNetworkingSpec) error {
+	drName := fmt.Sprintf("%s-dr", spec.Name)
+	if spec.LoadBalancer == nil {
+		if err := m.drController.Delete(ctx, drName, spec.Namespace); err != nil {
+			return fmt.Errorf("failed to delete destinationrule: %w", err)
+		}
+		return nil
+	}
+
+	drConfig := &DestinationRuleConfig{
+		Name:         drName,
+		Namespace:    spec.Namespace,
+		Host:         spec.ServiceName,
+		LoadBalancer: spec.LoadBalancer,
+		Labels:       spec.Labels,
+	}
+	if err := m.drController.CreateOrUpdateWithOwner(ctx, drConfig, spec.OwnerObject, m.scheme); err != nil {
+		return fmt.Errorf("failed to sync destinationrule: %w", err)
+	}
+	return nil
+}
+
 // createOptimizedVirtualService 创建优化的VirtualService
 func (m *optimizedNetworkingManager) createOptimizedVirtualService(ctx context.Context, spec *AppNetworkingSpec) error {
 	// 使用域名分类器构建优化的VirtualService配置
@@ -22,14 +22,24 @@ import (
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestOptimizedNetworkingManager_CreateAppNetworking(t *testing.T) {
 	scheme := runtime.NewScheme()
-	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core scheme: %v", err)
+	}
+	// 自定义域名 Gateway 引用的 TLS Secret
+	var tlsSecrets []client.Object
+	for _, ns := range []string{"ns2", "ns3", "ns4"} {
+		tlsSecrets = append(tlsSecrets, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "custom-tls", Namespace: ns}})
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tlsSecrets...).Build()
 
 	config := &NetworkConfig{
 		BaseDomain:           "cloud.sealos.io",
@@ -50,6 +60,7 @@ func TestOptimizedNetworkingManager_CreateAppNetworking(t *testing.T) {
 		config:            config,
 		gatewayController: mockGatewayCtrl,
 		vsController:      mockVSCtrl,
+		drController:      NewDestinationRuleController(client, config),
 		domainAllocator:   &mockDomainAllocator{},
 		certManager:       mockCertMgr,
 		domainClassifier:  NewDomainClassifier(config),
@@ -61,6 +72,7 @@ func TestOptimizedNetworkingManager_CreateAppNetworking(t *testing.T) {
 		expectGateway    bool
 		expectVS         bool
 		expectError      bool
+		expectTLSMissing bool
 		testDescription  string
 	}{
 		{
@@ -161,6 +173,28 @@ func TestOptimizedNetworkingManager_CreateAppNetworking(t *testing.T) {
 			expectError:     true,
 			testDescription: "自定义域名没有TLS配置应该报错",
 		},
+		{
+			name: "custom domain with missing TLS secret",
+			spec: &AppNetworkingSpec{
+				Name:        "app7",
+				Namespace:   "ns7",
+				TenantID:    "tenant7",
+				AppName:     "testapp",
+				Protocol:    ProtocolHTTP,
+				Hosts:       []string{"custom.example.com"},
+				ServiceName: "app7-svc",
+				ServicePort: 8080,
+				TLSConfig: &TLSConfig{
+					SecretName: "custom-tls",
+					Hosts:      []string{"custom.example.com"},
+				},
+			},
+			expectGateway:    false,
+			expectVS:         false,
+			expectError:      true,
+			expectTLSMissing: true,
+			testDescription:  "TLS Secret 不存在时不应创建引用它的Gateway",
+		},
 		{
 			name: "terminal app with websocket",
 			spec: &AppNetworkingSpec{
@@ -197,6 +231,9 @@ func TestOptimizedNetworkingManager_CreateAppNetworking(t *testing.T) {
 			if !tt.expectError && err != nil {
 				t.Errorf("CreateAppNetworking() unexpected error: %v. %s", err, tt.testDescription)
 			}
+			if got := IsTLSSecretNotFound(err); got != tt.expectTLSMissing {
+				t.Errorf("IsTLSSecretNotFound(%v) = %v, want %v. %s", err, got, tt.expectTLSMissing, tt.testDescription)
+			}
 
 			if tt.expectGateway && !mockGatewayCtrl.createOrUpdateCalled {
 				t.Errorf("Expected Gateway to be created but it wasn't. %s", tt.testDescription)
@@ -225,6 +262,90 @@ func TestOptimizedNetworkingManager_CreateAppNetworking(t *testing.T) {
 	}
 }
 
+func TestOptimizedNetworkingManager_WithholdHostsUntilCertReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core scheme: %v", err)
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	config := &NetworkConfig{
+		BaseDomain:                  "cloud.sealos.io",
+		DefaultGateway:              "istio-system/sealos-gateway",
+		TLSEnabled:                  true,
+		PublicDomainPatterns:        []string{"*.cloud.sealos.io"},
+		WithholdHostsUntilCertReady: true,
+	}
+	mockGatewayCtrl := &mockGatewayController{}
+	manager := &optimizedNetworkingManager{
+		client:            client,
+		scheme:            scheme,
+		config:            config,
+		gatewayController: mockGatewayCtrl,
+		vsController:      &mockVirtualServiceController{},
+		drController:      NewDestinationRuleController(client, config),
+		domainAllocator:   &mockDomainAllocator{},
+		certManager:       &mockCertificateManager{},
+		domainClassifier:  NewDomainClassifier(config),
+	}
+
+	newSpec := func(host string) *AppNetworkingSpec {
+		return &AppNetworkingSpec{
+			Name:        "app",
+			Namespace:   "ns1",
+			TenantID:    "tenant1",
+			AppName:     "app",
+			Protocol:    ProtocolHTTP,
+			Hosts:       []string{host},
+			ServiceName: "app-svc",
+			ServicePort: 8080,
+			TLSConfig: &TLSConfig{
+				SecretName: "custom-tls",
+				Hosts:      []string{host},
+			},
+		}
+	}
+	ctx := context.Background()
+
+	// Secret 不存在时暂不创建 Gateway，也不返回错误
+	if err := manager.CreateAppNetworking(ctx, newSpec("secure.example.com")); err != nil {
+		t.Fatalf("CreateAppNetworking() unexpected error: %v", err)
+	}
+	if mockGatewayCtrl.createOrUpdateCalled {
+		t.Fatalf("gateway created before tls secret exists: %+v", mockGatewayCtrl.lastConfig)
+	}
+
+	// Secret 已创建但证书尚未写入，仍然暂缓
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "custom-tls", Namespace: "ns1"}}
+	if err := client.Create(ctx, secret); err != nil {
+		t.Fatalf("failed to create secret: %v", err)
+	}
+	mockGatewayCtrl.reset()
+	if err := manager.UpdateAppNetworking(ctx, newSpec("secure.example.com")); err != nil {
+		t.Fatalf("UpdateAppNetworking() unexpected error: %v", err)
+	}
+	if mockGatewayCtrl.createOrUpdateCalled {
+		t.Fatalf("gateway created before tls secret is populated: %+v", mockGatewayCtrl.lastConfig)
+	}
+
+	// 证书写入后声明该域名
+	secret.Data = map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")}
+	if err := client.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	mockGatewayCtrl.reset()
+	if err := manager.UpdateAppNetworking(ctx, newSpec("secure.example.com")); err != nil {
+		t.Fatalf("UpdateAppNetworking() unexpected error: %v", err)
+	}
+	got := mockGatewayCtrl.lastConfig
+	if got == nil || len(got.Hosts) != 1 || got.Hosts[0] != "secure.example.com" {
+		t.Fatalf("gateway config = %+v, want secure.example.com", got)
+	}
+	if got.TLSConfig == nil || got.TLSConfig.SecretName != "custom-tls" {
+		t.Errorf("gateway TLS config = %+v, want custom-tls", got.TLSConfig)
+	}
+}
+
 func TestOptimizedNetworkingManager_DeleteAppNetworking(t *testing.T) {
 	scheme := runtime.NewScheme()
 	client := fake.NewClientBuilder().WithScheme(scheme).Build()
@@ -243,6 +364,7 @@ func TestOptimizedNetworkingManager_DeleteAppNetworking(t *testing.T) {
 		config:            config,
 		gatewayController: mockGatewayCtrl,
 		vsController:      mockVSCtrl,
+		drController:      NewDestinationRuleController(client, config),
 		domainAllocator:   &mockDomainAllocator{},
 		certManager:       &mockCertificateManager{},
 		domainClassifier:  NewDomainClassifier(config),
@@ -394,6 +516,10 @@ func (m *mockVirtualServiceController) Get(ctx context.Context, name, namespace
 	return nil, nil
 }
 
+func (m *mockVirtualServiceController) List(ctx context.Context, namespace string, opts ...client.ListOption) ([]*VirtualService, error) {
+	return nil, nil
+}
+
 func (m *mockVirtualServiceController) Suspend(ctx context.Context, name, namespace string) error {
 	return nil
 }
@@ -448,11 +574,31 @@ func (m *mockDomainAllocator) GenerateAppDomain(tenantID, appName string) string
 	return fmt.Sprintf("%s.%s.cloud.sealos.io", appName, tenantID)
 }
 
-func (m *mockDomainAllocator) ValidateCustomDomain(domain string) error {
+func (m *mockDomainAllocator) ClaimAppDomain(ctx context.Context, tenantID, appName string) (string, error) {
+	return m.GenerateAppDomain(tenantID, appName), nil
+}
+
+func (m *mockDomainAllocator) ReleaseAppDomain(ctx context.Context, tenantID, appName string) error {
+	return nil
+}
+
+func (m *mockDomainAllocator) ValidateCustomDomain(ctx context.Context, domain string) error {
 	// 测试环境不进行真实的DNS验证
 	return m.validateReturns
 }
 
 func (m *mockDomainAllocator) IsDomainAvailable(domain string) (bool, error) {
 	return true, nil
+}
+
+func (m *mockDomainAllocator) IsDomainAvailableForTenant(tenantID, domain string) (bool, error) {
+	return true, nil
+}
+
+func (m *mockDomainAllocator) CheckCAA(ctx context.Context, domain string, allowedCAs []string) error {
+	return nil
+}
+
+func (m *mockDomainAllocator) VerifyDomainOwnership(ctx context.Context, domain, expectedToken string) (bool, error) {
+	return true, nil
 }
\ No newline at end of file
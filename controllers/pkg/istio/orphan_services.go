@@ -0,0 +1,135 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/labring/sealos/controllers/pkg/utils/label"
+)
+
+// DefaultOrphanServiceSweepInterval 默认的孤立 Service 清理间隔
+const DefaultOrphanServiceSweepInterval = 10 * time.Minute
+
+// OrphanServiceSweeper 周期性清理应用 CR 已删除但仍残留的 Service。
+// 旧版本创建的 Service 可能缺少 OwnerReference，不会被垃圾回收；
+// 按 part-of 标签找到应用的 Service，再按 name 标签检查所属 CR 是否存在。
+// 实现 manager.Runnable，只在主副本运行。
+type OrphanServiceSweeper struct {
+	client   Client
+	ownerGVK schema.GroupVersionKind
+	partOf   string
+	interval time.Duration
+}
+
+// NewOrphanServiceSweeper 创建孤立 Service 清理器，ownerGVK 为应用 CR 的类型，partOf 为应用 Service 的 part-of 标签，
+// interval 为 0 时使用 DefaultOrphanServiceSweepInterval
+func NewOrphanServiceSweeper(client Client, ownerGVK schema.GroupVersionKind, partOf string, interval time.Duration) *OrphanServiceSweeper {
+	if interval <= 0 {
+		interval = DefaultOrphanServiceSweepInterval
+	}
+	return &OrphanServiceSweeper{
+		client:   client,
+		ownerGVK: ownerGVK,
+		partOf:   partOf,
+		interval: interval,
+	}
+}
+
+// Start 启动后立即清理一次，之后周期性清理直到 ctx 结束
+func (s *OrphanServiceSweeper) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("orphan-service-sweeper").WithValues("partOf", s.partOf)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := s.Sweep(ctx)
+		if err != nil {
+			logger.Error(err, "failed to sweep orphaned services, will retry")
+		} else if deleted > 0 {
+			logger.Info("swept orphaned services", "count", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep 删除所属 CR 已不存在的应用 Service，返回删除的数量
+func (s *OrphanServiceSweeper) Sweep(ctx context.Context) (int, error) {
+	services := &corev1.ServiceList{}
+	if err := s.client.List(ctx, services, client.MatchingLabels{
+		label.AppPartOf:    s.partOf,
+		label.AppManagedBy: label.DefaultManagedBy,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to list %s services: %w", s.partOf, err)
+	}
+
+	deleted := 0
+	for i := range services.Items {
+		svc := &services.Items[i]
+		ownerName := svc.Labels[label.AppName]
+		if ownerName == "" || !svc.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		orphaned, err := s.ownerMissing(ctx, svc.Namespace, ownerName)
+		if err != nil {
+			return deleted, err
+		}
+		if !orphaned {
+			continue
+		}
+		if err := client.IgnoreNotFound(s.client.Delete(ctx, svc)); err != nil {
+			return deleted, fmt.Errorf("failed to delete orphaned service %s/%s: %w", svc.Namespace, svc.Name, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// ownerMissing 检查 Service 所属的 CR 是否已被删除
+func (s *OrphanServiceSweeper) ownerMissing(ctx context.Context, namespace, name string) (bool, error) {
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(s.ownerGVK)
+	err := s.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, owner)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s %s/%s: %w", s.ownerGVK.Kind, namespace, name, err)
+	}
+	return false, nil
+}
+
+// NeedLeaderElection 删除操作只由主副本执行
+func (s *OrphanServiceSweeper) NeedLeaderElection() bool {
+	return true
+}
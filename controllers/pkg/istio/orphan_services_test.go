@@ -0,0 +1,93 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/utils/label"
+)
+
+var testAdminerGVK = schema.GroupVersionKind{Group: "adminer.db.sealos.io", Version: "v1", Kind: "Adminer"}
+
+func newTestAppLabeledService(name, namespace, app, partOf string) *corev1.Service {
+	labels := map[string]string{label.AppManagedBy: label.DefaultManagedBy, label.AppName: app}
+	if partOf != "" {
+		labels[label.AppPartOf] = partOf
+	}
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+	}
+}
+
+func TestOrphanServiceSweeper_Sweep(t *testing.T) {
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(testAdminerGVK)
+	owner.SetName("owned")
+	owner.SetNamespace("ns-user1")
+
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		owner,
+		newTestAppLabeledService("owned", "ns-user1", "owned", "adminer"),
+		newTestAppLabeledService("deleted", "ns-user1", "deleted", "adminer"),
+		// 同名 CR 在其他命名空间，不影响判断
+		newTestAppLabeledService("owned", "ns-user2", "owned", "adminer"),
+		newTestAppLabeledService("terminal-svc", "ns-user1", "deleted", "terminal"),
+		newTestAppLabeledService("user-app", "ns-user1", "deleted", ""),
+	).Build()
+	sweeper := NewOrphanServiceSweeper(c, testAdminerGVK, "adminer", 0)
+
+	deleted, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Sweep() deleted %d services, want 2", deleted)
+	}
+
+	tests := []struct {
+		namespace string
+		name      string
+		wantKept  bool
+	}{
+		{namespace: "ns-user1", name: "owned", wantKept: true},
+		{namespace: "ns-user1", name: "deleted"},
+		{namespace: "ns-user2", name: "owned"},
+		{namespace: "ns-user1", name: "terminal-svc", wantKept: true},
+		{namespace: "ns-user1", name: "user-app", wantKept: true},
+	}
+	for _, tt := range tests {
+		err := c.Get(context.Background(), client.ObjectKey{Namespace: tt.namespace, Name: tt.name}, &corev1.Service{})
+		if tt.wantKept && err != nil {
+			t.Errorf("service %s/%s should be kept, get error = %v", tt.namespace, tt.name, err)
+		}
+		if !tt.wantKept && !errors.IsNotFound(err) {
+			t.Errorf("service %s/%s should be deleted, get error = %v", tt.namespace, tt.name, err)
+		}
+	}
+}
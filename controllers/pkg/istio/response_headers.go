@@ -0,0 +1,64 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrResponseHeaderNotAllowed 响应头部不在 NetworkConfig.AllowedResponseHeaders 允许列表中
+var ErrResponseHeaderNotAllowed = errors.New("response header is not allowed")
+
+// validateResponseHeaders 校验 VirtualService 设置或移除的响应头部都在允许列表中，
+// 允许列表为空时不限制；控制器自身注入的调试头部不受限制
+func validateResponseHeaders(networkConfig *NetworkConfig, config *VirtualServiceConfig) error {
+	if networkConfig == nil || len(networkConfig.AllowedResponseHeaders) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(config.ResponseHeaders))
+	for name := range config.ResponseHeaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if containsFold(networkConfig.AllowedResponseHeaders, name) || name == GatewayDebugResponseHeader {
+			continue
+		}
+		return fmt.Errorf("%w: virtualservice %s/%s sets %s", ErrResponseHeaderNotAllowed, config.Namespace, config.Name, name)
+	}
+	return nil
+}
+
+// splitResponseHeaders 拆分响应头部：值为空表示移除上游返回的同名头部（如允许 iframe 嵌入时移除 X-Frame-Options），
+// 其余头部按值设置
+func splitResponseHeaders(headers map[string]string) (map[string]string, []string) {
+	set := make(map[string]string, len(headers))
+	var remove []string
+	for name, value := range headers {
+		if value == "" {
+			remove = append(remove, name)
+			continue
+		}
+		set[name] = value
+	}
+	sort.Strings(remove)
+	return set, remove
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateResponseHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		headers map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "empty allowlist allows any header",
+			headers: map[string]string{"X-Custom": "1"},
+		},
+		{
+			name:    "allowed headers are case-insensitive",
+			allowed: []string{"content-security-policy", "X-Frame-Options"},
+			headers: map[string]string{"Content-Security-Policy": "default-src 'self'", "x-frame-options": ""},
+		},
+		{
+			name:    "debug gateway header is always allowed",
+			allowed: []string{"Content-Security-Policy"},
+			headers: map[string]string{GatewayDebugResponseHeader: "istio-system/sealos-gateway"},
+		},
+		{
+			name:    "disallowed header rejected",
+			allowed: []string{"Content-Security-Policy"},
+			headers: map[string]string{"Content-Security-Policy": "default-src 'self'", "Set-Cookie": "session=1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateResponseHeaders(&NetworkConfig{AllowedResponseHeaders: tt.allowed}, &VirtualServiceConfig{
+				Name:            "app-vs",
+				Namespace:       "ns-user1",
+				ResponseHeaders: tt.headers,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateResponseHeaders() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrResponseHeaderNotAllowed) {
+				t.Errorf("validateResponseHeaders() error = %v, want ErrResponseHeaderNotAllowed", err)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_ResponseHeaders(t *testing.T) {
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	routes := controller.buildHTTPRoutes(&VirtualServiceConfig{
+		Name:        "app-vs",
+		Namespace:   "ns-user1",
+		Hosts:       []string{"app.cloud.sealos.io"},
+		ServiceName: "app",
+		ServicePort: 8080,
+		ResponseHeaders: map[string]string{
+			"X-Frame-Options":         "",
+			"Content-Security-Policy": "default-src 'self'",
+		},
+	})
+
+	route := routes[0].(map[string]interface{})
+	headers, ok := route["headers"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("route has no headers: %v", route)
+	}
+	response := headers["response"].(map[string]interface{})
+
+	wantSet := map[string]string{"Content-Security-Policy": "default-src 'self'"}
+	if got := response["set"]; !reflect.DeepEqual(got, wantSet) {
+		t.Errorf("response set = %v, want %v", got, wantSet)
+	}
+	// 空值的 X-Frame-Options 应被移除，而不是设置为空字符串
+	wantRemove := []string{"X-Frame-Options"}
+	if got := response["remove"]; !reflect.DeepEqual(got, wantRemove) {
+		t.Errorf("response remove = %v, want %v", got, wantRemove)
+	}
+}
+
+func TestVirtualServiceController_RejectsDisallowedResponseHeaders(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewVirtualServiceController(c, &NetworkConfig{AllowedResponseHeaders: []string{"Content-Security-Policy"}})
+	config := &VirtualServiceConfig{
+		Name:            "app-vs",
+		Namespace:       "ns-user1",
+		Hosts:           []string{"app.cloud.sealos.io"},
+		ServiceName:     "app",
+		ServicePort:     8080,
+		ResponseHeaders: map[string]string{"Access-Control-Allow-Origin": "*"},
+	}
+
+	if err := controller.Create(context.Background(), config); !errors.Is(err, ErrResponseHeaderNotAllowed) {
+		t.Errorf("Create() error = %v, want ErrResponseHeaderNotAllowed", err)
+	}
+	if err := controller.CreateOrUpdateWithOwner(context.Background(), config, nil, nil); !errors.Is(err, ErrResponseHeaderNotAllowed) {
+		t.Errorf("CreateOrUpdateWithOwner() error = %v, want ErrResponseHeaderNotAllowed", err)
+	}
+	if _, exists := getTestVirtualService(t, c, "app-vs", "ns-user1"); exists {
+		t.Error("virtualservice should not be created with disallowed response headers")
+	}
+
+	// 允许的头部正常创建
+	config.ResponseHeaders = map[string]string{"Content-Security-Policy": "default-src 'self'"}
+	if err := controller.CreateOrUpdateWithOwner(context.Background(), config, nil, nil); err != nil {
+		t.Fatalf("CreateOrUpdateWithOwner() error = %v", err)
+	}
+	if _, exists := getTestVirtualService(t, c, "app-vs", "ns-user1"); !exists {
+		t.Error("virtualservice should be created with allowed response headers")
+	}
+}
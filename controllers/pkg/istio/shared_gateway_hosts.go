@@ -0,0 +1,194 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultSharedGatewayHostsResyncInterval 默认的共享 Gateway 主机列表同步间隔
+const DefaultSharedGatewayHostsResyncInterval = 10 * time.Minute
+
+// SharedGatewayWildcardHosts 返回共享 Gateway 需要声明的公共通配符主机：
+// *.<BaseDomain>、按命名空间覆盖的基础域名 *.<domain>，以及 PublicDomainPatterns 中的通配符模式
+func SharedGatewayWildcardHosts(config *NetworkConfig) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	add := func(host string) {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || host == "*." || seen[host] {
+			return
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+
+	if config.BaseDomain != "" {
+		add("*." + config.BaseDomain)
+	}
+	for _, domain := range config.NamespaceBaseDomains {
+		if domain != "" {
+			add("*." + domain)
+		}
+	}
+	for _, pattern := range config.PublicDomainPatterns {
+		if strings.HasPrefix(pattern, "*.") {
+			add(pattern)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// sharedGatewayRefs 返回共享 Gateway 及其全部分片的 namespace/name
+func sharedGatewayRefs(config *NetworkConfig) []types.NamespacedName {
+	namespace, name := getSystemNamespace(config), getSystemGateway(config)
+	if _, gatewayName, found := strings.Cut(name, "/"); found {
+		name = gatewayName
+	}
+	if config.SharedGatewayShards <= 1 {
+		return []types.NamespacedName{{Namespace: namespace, Name: name}}
+	}
+	refs := make([]types.NamespacedName, 0, config.SharedGatewayShards)
+	for i := 0; i < config.SharedGatewayShards; i++ {
+		refs = append(refs, types.NamespacedName{Namespace: namespace, Name: fmt.Sprintf("%s-%d", name, i)})
+	}
+	return refs
+}
+
+// EnsureSharedGatewayHosts 确保共享 Gateway（含分片）的 HTTP/HTTPS server 声明了公共通配符主机，
+// 缺少的主机追加到 hosts 末尾，已存在的不做修改。共享 Gateway 由集群安装时创建，不存在时跳过
+func EnsureSharedGatewayHosts(ctx context.Context, c Client, config *NetworkConfig) error {
+	if !config.SharedGatewayEnabled {
+		return nil
+	}
+	wanted := SharedGatewayWildcardHosts(config)
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	for _, ref := range sharedGatewayRefs(config) {
+		gateway := &unstructured.Unstructured{}
+		gateway.SetGroupVersionKind(gatewayGVK)
+		if err := c.Get(ctx, ref, gateway); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get shared gateway %s: %w", ref, gatewayError(ref.Namespace, ref.Name, err))
+		}
+
+		servers, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "servers")
+		if !addSharedGatewayHosts(servers, wanted) {
+			continue
+		}
+		if err := unstructured.SetNestedSlice(gateway.Object, servers, "spec", "servers"); err != nil {
+			return err
+		}
+		if err := c.Update(ctx, gateway); err != nil {
+			return fmt.Errorf("failed to update shared gateway %s: %w", ref, gatewayError(ref.Namespace, ref.Name, err))
+		}
+		log.FromContext(ctx).Info("added missing public wildcard hosts to shared gateway", "gateway", ref.String(), "hosts", wanted)
+	}
+	return nil
+}
+
+// addSharedGatewayHosts 在 HTTP/HTTPS server 上追加缺少的主机，返回是否有修改；TCP/TLS server 保持不变
+func addSharedGatewayHosts(servers []interface{}, wanted []string) bool {
+	changed := false
+	for _, item := range servers {
+		server, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		protocol, _, _ := unstructured.NestedString(server, "port", "protocol")
+		if protocol = strings.ToUpper(protocol); protocol != "HTTP" && protocol != "HTTPS" && protocol != "HTTP2" {
+			continue
+		}
+
+		hosts, _ := server["hosts"].([]interface{})
+		existing := make(map[string]bool, len(hosts))
+		for _, h := range hosts {
+			host, _ := h.(string)
+			// server 主机可带命名空间前缀（如 */*.cloud.sealos.io），* 前缀对所有命名空间生效
+			if ns, dnsName, found := strings.Cut(host, "/"); found && ns == "*" {
+				host = dnsName
+			}
+			existing[strings.ToLower(host)] = true
+		}
+		if existing["*"] {
+			continue
+		}
+		missing := false
+		for _, host := range wanted {
+			if !existing[host] {
+				hosts = append(hosts, host)
+				missing = true
+			}
+		}
+		if missing {
+			server["hosts"] = hosts
+			changed = true
+		}
+	}
+	return changed
+}
+
+// SharedGatewayHostsSyncer 启动时及周期性地修复共享 Gateway 的主机列表，
+// 避免共享 Gateway 被误配置为较窄的主机列表导致使用公共域名的应用返回 404。实现 manager.Runnable
+type SharedGatewayHostsSyncer struct {
+	client   Client
+	config   *NetworkConfig
+	interval time.Duration
+}
+
+// NewSharedGatewayHostsSyncer 创建共享 Gateway 主机同步器，interval 为 0 时使用 DefaultSharedGatewayHostsResyncInterval
+func NewSharedGatewayHostsSyncer(client Client, config *NetworkConfig, interval time.Duration) *SharedGatewayHostsSyncer {
+	if interval <= 0 {
+		interval = DefaultSharedGatewayHostsResyncInterval
+	}
+	return &SharedGatewayHostsSyncer{client: client, config: config, interval: interval}
+}
+
+// Start 启动后立即同步一次，之后周期性同步直到 ctx 结束
+func (s *SharedGatewayHostsSyncer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("shared-gateway-hosts")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := EnsureSharedGatewayHosts(ctx, s.client, s.config); err != nil {
+			logger.Error(err, "failed to sync shared gateway hosts, will retry")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestSharedGateway(name string, servers ...interface{}) *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	gateway.SetName(name)
+	gateway.SetNamespace("istio-system")
+	_ = unstructured.SetNestedSlice(gateway.Object, servers, "spec", "servers")
+	return gateway
+}
+
+func newTestGatewayServer(protocol string, port int64, hosts ...interface{}) interface{} {
+	return map[string]interface{}{
+		"port":  map[string]interface{}{"number": port, "name": "port", "protocol": protocol},
+		"hosts": hosts,
+	}
+}
+
+func TestEnsureSharedGatewayHosts(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomainPatterns: []string{"*.sealos.run", "console.sealos.run"},
+	}
+	tests := []struct {
+		name        string
+		servers     []interface{}
+		wantHosts   [][]interface{}
+		wantUpdated bool
+	}{
+		{
+			name: "narrow host list gets the wildcards",
+			servers: []interface{}{
+				newTestGatewayServer("HTTP", 80, "console.cloud.sealos.io"),
+				newTestGatewayServer("HTTPS", 443, "*.sealos.run"),
+				newTestGatewayServer("TCP", 5432, "db.cloud.sealos.io"),
+			},
+			wantHosts: [][]interface{}{
+				{"console.cloud.sealos.io", "*.cloud.sealos.io", "*.sealos.run"},
+				{"*.sealos.run", "*.cloud.sealos.io"},
+				{"db.cloud.sealos.io"},
+			},
+			wantUpdated: true,
+		},
+		{
+			name: "already correct",
+			servers: []interface{}{
+				newTestGatewayServer("HTTP", 80, "*/*.cloud.sealos.io", "*.sealos.run"),
+				newTestGatewayServer("HTTPS", 443, "*"),
+			},
+			wantHosts: [][]interface{}{
+				{"*/*.cloud.sealos.io", "*.sealos.run"},
+				{"*"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := newTestSharedGateway("sealos-gateway", tt.servers...)
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(existing).Build()
+			ctx := context.Background()
+
+			// 重复执行结果不变
+			for i := 0; i < 2; i++ {
+				if err := EnsureSharedGatewayHosts(ctx, c, config); err != nil {
+					t.Fatalf("EnsureSharedGatewayHosts() error = %v", err)
+				}
+			}
+
+			gateway := &unstructured.Unstructured{}
+			gateway.SetGroupVersionKind(gatewayGVK)
+			if err := c.Get(ctx, types.NamespacedName{Name: "sealos-gateway", Namespace: "istio-system"}, gateway); err != nil {
+				t.Fatalf("get gateway: %v", err)
+			}
+			servers, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "servers")
+			for i, want := range tt.wantHosts {
+				if got := servers[i].(map[string]interface{})["hosts"]; !reflect.DeepEqual(got, want) {
+					t.Errorf("servers[%d].hosts = %v, want %v", i, got, want)
+				}
+			}
+			if updated := gateway.GetResourceVersion() != existing.GetResourceVersion(); updated != tt.wantUpdated {
+				t.Errorf("gateway updated = %v, want %v", updated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestEnsureSharedGatewayHosts_Shards(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		SharedGatewayEnabled: true,
+		SharedGatewayShards:  2,
+	}
+	// 分片 1 尚未创建时跳过，不报错
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).
+		WithObjects(newTestSharedGateway("sealos-gateway-0", newTestGatewayServer("HTTP", 80, "console.cloud.sealos.io"))).Build()
+	if err := EnsureSharedGatewayHosts(context.Background(), c, config); err != nil {
+		t.Fatalf("EnsureSharedGatewayHosts() error = %v", err)
+	}
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "sealos-gateway-0", Namespace: "istio-system"}, gateway); err != nil {
+		t.Fatalf("get gateway: %v", err)
+	}
+	servers, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "servers")
+	want := []interface{}{"console.cloud.sealos.io", "*.cloud.sealos.io"}
+	if got := servers[0].(map[string]interface{})["hosts"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("shard hosts = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// DefaultTLSSecretNamespace 默认通配符证书所在的命名空间
+const DefaultTLSSecretNamespace = "sealos-system"
+
+// mirroredTLSSecretLabels 从默认证书复制出的 Secret 的标签
+var mirroredTLSSecretLabels = map[string]string{
+	"app.kubernetes.io/managed-by": "sealos-istio",
+	"sealos.io/cert-type":          "wildcard",
+}
+
+// TLSSecretMirror 监听默认通配符证书 Secret，证书轮换后把新证书同步到各命名空间中复制的 Secret。
+// 通过 credentialName 直接引用默认证书的 Gateway 会自动生效，复制的 Secret 需要显式刷新。
+type TLSSecretMirror struct {
+	client client.Client
+	// reader 读取默认证书和副本，不经过管理器缓存，避免为全集群 Secret 建立 informer
+	reader client.Reader
+	config *NetworkConfig
+}
+
+// NewTLSSecretMirror 创建默认证书同步器
+func NewTLSSecretMirror(client client.Client, config *NetworkConfig) *TLSSecretMirror {
+	return &TLSSecretMirror{
+		client: client,
+		reader: client,
+		config: config,
+	}
+}
+
+// SetupWithManager 注册只关注默认证书 Secret 的控制器。
+// 使用只缓存默认证书 Secret 的独立缓存监听证书轮换，读取通过 APIReader，管理器缓存不会缓存任何 Secret
+func (m *TLSSecretMirror) SetupWithManager(mgr ctrl.Manager) error {
+	if m.config.DefaultTLSSecret == "" {
+		return nil
+	}
+
+	sourceCache, err := cache.New(mgr.GetConfig(), m.sourceCacheOptions(mgr.GetScheme()))
+	if err != nil {
+		return fmt.Errorf("failed to create TLS secret mirror cache: %w", err)
+	}
+	if err := mgr.Add(sourceCache); err != nil {
+		return err
+	}
+	m.reader = mgr.GetAPIReader()
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("tls-secret-mirror").
+		WatchesRawSource(source.Kind(sourceCache, &corev1.Secret{}, &handler.TypedEnqueueRequestForObject[*corev1.Secret]{},
+			predicate.NewTypedPredicateFuncs(func(secret *corev1.Secret) bool { return m.isSourceSecret(secret) }))).
+		Complete(m)
+}
+
+// sourceCacheOptions 返回只缓存默认证书 Secret 的缓存配置
+func (m *TLSSecretMirror) sourceCacheOptions(scheme *runtime.Scheme) cache.Options {
+	return cache.Options{
+		Scheme:            scheme,
+		DefaultNamespaces: map[string]cache.Config{DefaultTLSSecretNamespace: {}},
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {Field: fields.OneTermEqualSelector("metadata.name", m.config.DefaultTLSSecret)},
+		},
+	}
+}
+
+// isSourceSecret 判断对象是否为默认证书 Secret
+func (m *TLSSecretMirror) isSourceSecret(obj client.Object) bool {
+	return m.config.DefaultTLSSecret != "" &&
+		obj.GetNamespace() == DefaultTLSSecretNamespace &&
+		obj.GetName() == m.config.DefaultTLSSecret
+}
+
+// Reconcile 将默认证书的内容同步到所有复制的 Secret
+func (m *TLSSecretMirror) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("tls-secret-mirror")
+
+	source := &corev1.Secret{}
+	if err := m.reader.Get(ctx, req.NamespacedName, source); err != nil {
+		// 默认证书被删除时保留已有副本，等待重新创建
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	refreshed, err := m.Refresh(ctx, source)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if refreshed > 0 {
+		logger.Info("refreshed mirrored TLS secrets", "source", req.NamespacedName, "count", refreshed)
+	}
+	return ctrl.Result{}, nil
+}
+
+// Refresh 用 source 的证书数据更新所有内容不一致的副本，返回更新的副本数量
+func (m *TLSSecretMirror) Refresh(ctx context.Context, source *corev1.Secret) (int, error) {
+	mirrors := &corev1.SecretList{}
+	if err := m.reader.List(ctx, mirrors, client.MatchingLabels(mirroredTLSSecretLabels)); err != nil {
+		return 0, fmt.Errorf("failed to list mirrored TLS secrets: %w", err)
+	}
+
+	refreshed := 0
+	for i := range mirrors.Items {
+		mirror := &mirrors.Items[i]
+		if mirror.Namespace == source.Namespace && mirror.Name == source.Name {
+			continue
+		}
+		if bytes.Equal(mirror.Data[corev1.TLSCertKey], source.Data[corev1.TLSCertKey]) &&
+			bytes.Equal(mirror.Data[corev1.TLSPrivateKeyKey], source.Data[corev1.TLSPrivateKeyKey]) {
+			continue
+		}
+
+		patch := client.MergeFrom(mirror.DeepCopy())
+		if mirror.Data == nil {
+			mirror.Data = make(map[string][]byte)
+		}
+		mirror.Data[corev1.TLSCertKey] = source.Data[corev1.TLSCertKey]
+		mirror.Data[corev1.TLSPrivateKeyKey] = source.Data[corev1.TLSPrivateKeyKey]
+		if err := m.client.Patch(ctx, mirror, patch); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return refreshed, fmt.Errorf("failed to refresh mirrored TLS secret %s: %w",
+				types.NamespacedName{Namespace: mirror.Namespace, Name: mirror.Name}, err)
+		}
+		refreshed++
+	}
+	return refreshed, nil
+}
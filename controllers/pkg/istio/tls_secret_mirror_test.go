@@ -0,0 +1,141 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestTLSSecret(name, namespace string, labels map[string]string, cert, key string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte(cert),
+			corev1.TLSPrivateKeyKey: []byte(key),
+		},
+	}
+}
+
+func TestTLSSecretMirror_RefreshesMirroredCopies(t *testing.T) {
+	source := newTestTLSSecret("wildcard-cert", DefaultTLSSecretNamespace, nil, "old-cert", "old-key")
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+		source,
+		newTestTLSSecret("app-tls", "ns-a", maps.Clone(mirroredTLSSecretLabels), "old-cert", "old-key"),
+		newTestTLSSecret("app-tls", "ns-b", maps.Clone(mirroredTLSSecretLabels), "old-cert", "old-key"),
+		// 用户自己的证书不应被覆盖
+		newTestTLSSecret("custom-tls", "ns-a", nil, "custom-cert", "custom-key"),
+	).Build()
+	mirror := NewTLSSecretMirror(c, &NetworkConfig{DefaultTLSSecret: "wildcard-cert"})
+	ctx := context.Background()
+
+	// 模拟默认证书轮换
+	source.Data[corev1.TLSCertKey] = []byte("new-cert")
+	source.Data[corev1.TLSPrivateKeyKey] = []byte("new-key")
+	if err := c.Update(ctx, source); err != nil {
+		t.Fatalf("update source secret: %v", err)
+	}
+
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(source)}
+	if _, err := mirror.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	tests := []struct {
+		namespace, name string
+		wantCert        string
+		wantKey         string
+	}{
+		{namespace: "ns-a", name: "app-tls", wantCert: "new-cert", wantKey: "new-key"},
+		{namespace: "ns-b", name: "app-tls", wantCert: "new-cert", wantKey: "new-key"},
+		{namespace: "ns-a", name: "custom-tls", wantCert: "custom-cert", wantKey: "custom-key"},
+	}
+	for _, tt := range tests {
+		got := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: tt.namespace, Name: tt.name}, got); err != nil {
+			t.Fatalf("get secret %s/%s: %v", tt.namespace, tt.name, err)
+		}
+		if cert := string(got.Data[corev1.TLSCertKey]); cert != tt.wantCert {
+			t.Errorf("secret %s/%s tls.crt = %q, want %q", tt.namespace, tt.name, cert, tt.wantCert)
+		}
+		if key := string(got.Data[corev1.TLSPrivateKeyKey]); key != tt.wantKey {
+			t.Errorf("secret %s/%s tls.key = %q, want %q", tt.namespace, tt.name, key, tt.wantKey)
+		}
+	}
+
+	// 副本已是最新时不再更新
+	refreshed, err := mirror.Refresh(ctx, source)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if refreshed != 0 {
+		t.Errorf("Refresh() refreshed = %d, want 0 for up-to-date copies", refreshed)
+	}
+}
+
+func TestTLSSecretMirror_IsSourceSecret(t *testing.T) {
+	mirror := NewTLSSecretMirror(nil, &NetworkConfig{DefaultTLSSecret: "wildcard-cert"})
+	tests := []struct {
+		name   string
+		secret *corev1.Secret
+		want   bool
+	}{
+		{name: "default secret", secret: newTestTLSSecret("wildcard-cert", DefaultTLSSecretNamespace, nil, "", ""), want: true},
+		{name: "same name in other namespace", secret: newTestTLSSecret("wildcard-cert", "ns-a", nil, "", "")},
+		{name: "other secret", secret: newTestTLSSecret("other", DefaultTLSSecretNamespace, nil, "", "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mirror.isSourceSecret(tt.secret); got != tt.want {
+				t.Errorf("isSourceSecret() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLSSecretMirror_SourceCacheOptions(t *testing.T) {
+	mirror := NewTLSSecretMirror(nil, &NetworkConfig{DefaultTLSSecret: "wildcard-cert"})
+	opts := mirror.sourceCacheOptions(clientgoscheme.Scheme)
+
+	if len(opts.DefaultNamespaces) != 1 {
+		t.Fatalf("DefaultNamespaces = %v, want only %s", opts.DefaultNamespaces, DefaultTLSSecretNamespace)
+	}
+	if _, ok := opts.DefaultNamespaces[DefaultTLSSecretNamespace]; !ok {
+		t.Fatalf("DefaultNamespaces = %v, want %s", opts.DefaultNamespaces, DefaultTLSSecretNamespace)
+	}
+	for obj, byObject := range opts.ByObject {
+		if _, ok := obj.(*corev1.Secret); !ok {
+			t.Errorf("unexpected cached type %T", obj)
+			continue
+		}
+		if byObject.Field == nil || byObject.Field.String() != "metadata.name=wildcard-cert" {
+			t.Errorf("Secret field selector = %v, want metadata.name=wildcard-cert", byObject.Field)
+		}
+	}
+	if len(opts.ByObject) != 1 {
+		t.Errorf("ByObject = %v, want only the Secret restriction", opts.ByObject)
+	}
+}
@@ -35,6 +35,7 @@ const (
 	ProtocolGRPC      Protocol = "grpc"
 	ProtocolWebSocket Protocol = "websocket"
 	ProtocolTCP       Protocol = "tcp"
+	ProtocolTLS       Protocol = "tls" // TLS 透传，按 SNI 路由，由后端终止 TLS
 )
 
 // NetworkingManager 统一管理所有网络资源
@@ -71,6 +72,7 @@ type AppNetworkingSpec struct {
 	Hosts       []string
 	ServiceName string
 	ServicePort int32
+	TCPPort     int32 // ProtocolTCP 时在 Gateway 上暴露的端口
 
 	// TLS 配置
 	TLSConfig *TLSConfig
@@ -85,6 +87,12 @@ type AppNetworkingSpec struct {
 	// 安全配置
 	SecretHeader string // Terminal 专用
 
+	// 负载均衡配置，非空时为 Service 生成 DestinationRule
+	LoadBalancer *LoadBalancerConfig
+
+	// 访问日志配置，非空时在网关上为该应用的域名开启 Envoy 访问日志
+	AccessLog *AccessLogConfig
+
 	// 标签和注解
 	Labels      map[string]string
 	Annotations map[string]string
@@ -95,14 +103,38 @@ type AppNetworkingSpec struct {
 
 // TLSConfig TLS 配置
 type TLSConfig struct {
-	SecretName string
-	Hosts      []string
+	SecretName         string
+	Hosts              []string
+	MinProtocolVersion string   // 最低 TLS 版本（TLSV1_2 等），为空时使用 NetworkConfig.GatewayTLSMinProtocolVersion
+	CipherSuites       []string // 允许的加密套件（仅对 TLS 1.2 及以下生效），为空时使用 NetworkConfig.GatewayTLSCipherSuites
 }
 
 // RetryPolicy 重试策略
 type RetryPolicy struct {
 	Attempts      int32
 	PerTryTimeout *time.Duration
+	// RetryOn 重试条件（如 5xx、reset、connect-failure），为空且未配置状态码时使用 Istio 默认条件
+	RetryOn []string
+	// RetriableStatusCodes 额外重试的 HTTP 状态码，Istio 通过 retryOn 中的数字状态码配置
+	RetriableStatusCodes []int
+}
+
+// FaultConfig 故障注入配置（用于混沌测试），Delay 与 Abort 至少设置一个
+type FaultConfig struct {
+	Delay *FaultDelay
+	Abort *FaultAbort
+}
+
+// FaultDelay 按比例为请求注入固定延迟
+type FaultDelay struct {
+	Percentage float64 // 0-100，支持小数
+	FixedDelay time.Duration
+}
+
+// FaultAbort 按比例直接返回 HTTPStatus
+type FaultAbort struct {
+	Percentage float64 // 0-100，支持小数
+	HTTPStatus int32
 }
 
 // CorsPolicy CORS 策略
@@ -121,8 +153,10 @@ type NetworkingStatus struct {
 	VirtualServiceReady bool
 
 	// 配置状态
-	Hosts      []string
-	TLSEnabled bool
+	Hosts       []string
+	TLSEnabled  bool
+	ServicePort int32            // VirtualService 当前的路由目标端口
+	AccessLog   *AccessLogConfig // 当前生效的访问日志配置，未开启时为 nil
 
 	// 错误信息
 	LastError string
@@ -138,7 +172,8 @@ type GatewayConfig struct {
 	Hosts     []string
 	TLSConfig *TLSConfig
 	Labels    map[string]string
-	UseShared bool // 是否使用共享 Gateway
+	UseShared bool  // 是否使用共享 Gateway
+	TCPPort   int32 // 大于 0 时只暴露该 TCP 端口，不再暴露 HTTP/HTTPS
 }
 
 // VirtualServiceConfig VirtualService 配置
@@ -150,12 +185,116 @@ type VirtualServiceConfig struct {
 	Protocol    Protocol
 	ServiceName string
 	ServicePort int32
+	TCPPort     int32 // ProtocolTCP 时匹配的 Gateway 端口
 	Timeout         *time.Duration
 	Retries         *RetryPolicy
 	CorsPolicy      *CorsPolicy
 	Headers         map[string]string // 请求头部
 	ResponseHeaders map[string]string // 响应头部
 	Labels          map[string]string
+	Annotations     map[string]string
+	PreviousName    string // 应用重命名前的 VirtualService 名称，非空时迁移旧 VirtualService 并保留其域名
+	Destinations    []WeightedDestination // 按权重分流到多个目标（如 DestinationRule 子集），权重总和必须为 100，为空时路由到 ServiceName:ServicePort
+	Routes          []HTTPRoute           // 按 URI 匹配的额外路由，排在默认 "/" 路由之前
+	Rewrite         *URIRewrite           // 默认路由转发前改写 URI/Authority
+	Redirect        *HTTPRedirect         // 默认路由直接返回重定向，不再转发到目标
+	FaultInjection  *FaultConfig          // 为所有 HTTP 路由注入延迟/中断故障
+	TCPRoutes       []TCPRoute            // 按端口匹配的 TCP 路由，配置后只生成四层路由
+	TLSRoutes       []TLSRoute            // 按 SNI 匹配的 TLS 透传路由，配置后只生成四层路由
+	// PreserveRouteOrder 按配置顺序生成 Routes，不按匹配精确度排序；被前面的路由遮蔽的配置会被拒绝
+	PreserveRouteOrder bool
+}
+
+// RouteMatch URI 匹配规则，Exact 与 Prefix 只能设置一个
+type RouteMatch struct {
+	Exact  string
+	Prefix string
+}
+
+// HTTPRoute 按 URI 匹配转发的路由
+type HTTPRoute struct {
+	Match       RouteMatch
+	ServiceName string // 为空时使用 VirtualServiceConfig.ServiceName
+	ServicePort int32  // 为 0 时使用 VirtualServiceConfig.ServicePort
+	Rewrite     *URIRewrite   // 转发前改写 URI/Authority（如去掉路径前缀）
+	Redirect    *HTTPRedirect // 直接返回重定向，不再转发到目标
+}
+
+// TCPRoute 按 Gateway 端口匹配转发的 TCP 路由
+type TCPRoute struct {
+	Port        int32  // 匹配的 Gateway 端口，为 0 时匹配所有端口
+	ServiceName string // 为空时使用 VirtualServiceConfig.ServiceName
+	ServicePort int32  // 为 0 时使用 VirtualServiceConfig.ServicePort
+}
+
+// TLSRoute 按 SNI 匹配转发的 TLS 透传路由
+type TLSRoute struct {
+	SNIHosts    []string // 为空时使用 VirtualServiceConfig.Hosts
+	Port        int32    // 匹配的 Gateway 端口，为 0 时匹配所有端口
+	ServiceName string   // 为空时使用 VirtualServiceConfig.ServiceName
+	ServicePort int32    // 为 0 时使用 VirtualServiceConfig.ServicePort
+}
+
+// URIRewrite 转发前改写请求，URI 替换匹配到的路径部分（前缀匹配时只替换前缀）
+type URIRewrite struct {
+	URI       string
+	Authority string
+}
+
+// HTTPRedirect 返回重定向响应，RedirectCode 为 0 时使用 Istio 默认的 301
+type HTTPRedirect struct {
+	URI          string
+	Authority    string
+	RedirectCode int32
+}
+
+// WeightedDestination 带权重的路由目标
+type WeightedDestination struct {
+	Host   string // 为空时使用 ServiceName
+	Port   int32  // 为 0 时使用 ServicePort
+	Subset string // DestinationRule 子集名称
+	Weight int32
+}
+
+// DestinationRuleConfig DestinationRule 配置
+type DestinationRuleConfig struct {
+	Name         string
+	Namespace    string
+	Host         string
+	Subsets      []Subset
+	LoadBalancer *LoadBalancerConfig // 为空时使用 Istio 默认负载均衡
+	Labels       map[string]string
+}
+
+// LoadBalancerType 负载均衡算法
+type LoadBalancerType string
+
+const (
+	LoadBalancerRoundRobin     LoadBalancerType = "roundRobin"
+	LoadBalancerLeastConn      LoadBalancerType = "leastConn"
+	LoadBalancerConsistentHash LoadBalancerType = "consistentHash"
+)
+
+// AccessLogConfig 应用级访问日志配置
+type AccessLogConfig struct {
+	// Format Envoy 文本日志格式（支持 %REQ(:AUTHORITY)% 等命令运算符），为空时使用 Envoy 默认格式
+	Format string
+}
+
+// LoadBalancerConfig 负载均衡配置
+type LoadBalancerConfig struct {
+	Type LoadBalancerType
+
+	// consistentHash 的哈希键，二者只能设置一个
+	HashHeader string        // 按请求头哈希
+	HashCookie string        // 按 Cookie 哈希，Cookie 不存在时由 Envoy 生成
+	CookieTTL  time.Duration // Cookie 有效期，为 0 时生成会话 Cookie
+}
+
+// Subset 按 Pod 标签划分的服务子集（如 v1/v2）
+type Subset struct {
+	Name   string
+	Labels map[string]string
 }
 
 // GatewayController Gateway 控制器接口
@@ -193,16 +332,31 @@ type VirtualServiceController interface {
 	// 获取 VirtualService
 	Get(ctx context.Context, name, namespace string) (*VirtualService, error)
 
-	// 暂停 VirtualService（设置为不可达）
+	// 列出命名空间中由 sealos-istio 管理的 VirtualService，opts 中的 MatchingLabels 会覆盖默认的标签过滤
+	List(ctx context.Context, namespace string, opts ...client.ListOption) ([]*VirtualService, error)
+
+	// 暂停 VirtualService（设置为不可达），原始路由保存在 OriginalSpecAnnotation 或 ConfigMap 中
 	Suspend(ctx context.Context, name, namespace string) error
 
-	// 恢复 VirtualService
+	// 恢复 VirtualService 暂停前的路由
 	Resume(ctx context.Context, name, namespace string) error
 
 	// 创建或更新 VirtualService（支持设置 OwnerReference）
 	CreateOrUpdateWithOwner(ctx context.Context, config *VirtualServiceConfig, owner metav1.Object, scheme *runtime.Scheme) error
 }
 
+// DestinationRuleController DestinationRule 控制器接口
+type DestinationRuleController interface {
+	// 删除 DestinationRule
+	Delete(ctx context.Context, name, namespace string) error
+
+	// 获取 DestinationRule
+	Get(ctx context.Context, name, namespace string) (*DestinationRuleConfig, error)
+
+	// 创建或更新 DestinationRule（支持设置 OwnerReference）
+	CreateOrUpdateWithOwner(ctx context.Context, config *DestinationRuleConfig, owner metav1.Object, scheme *runtime.Scheme) error
+}
+
 // Gateway Istio Gateway 资源（简化版本）
 type Gateway struct {
 	Name      string
@@ -227,14 +381,29 @@ type VirtualService struct {
 
 // DomainAllocator 域名分配器接口
 type DomainAllocator interface {
-	// 生成应用域名
+	// 生成应用域名（不登记）
 	GenerateAppDomain(tenantID, appName string) string
 
+	// 生成应用域名并在域名登记表中登记，已被其他租户登记时返回 ErrDomainClaimed
+	ClaimAppDomain(ctx context.Context, tenantID, appName string) (string, error)
+
+	// 释放 ClaimAppDomain 为应用登记的域名，应用删除时调用
+	ReleaseAppDomain(ctx context.Context, tenantID, appName string) error
+
 	// 验证自定义域名
-	ValidateCustomDomain(domain string) error
+	ValidateCustomDomain(ctx context.Context, domain string) error
 
 	// 检查域名是否可用
 	IsDomainAvailable(domain string) (bool, error)
+
+	// 检查域名对指定租户是否可用（应用租户的保留子域名放行配置）
+	IsDomainAvailableForTenant(tenantID, domain string) (bool, error)
+
+	// 检查 CAA 记录是否允许指定 CA 签发证书
+	CheckCAA(ctx context.Context, domain string, allowedCAs []string) error
+
+	// 通过 _sealos-challenge.<domain> 的 TXT 记录验证域名所有权
+	VerifyDomainOwnership(ctx context.Context, domain, expectedToken string) (bool, error)
 }
 
 // CertificateManager 证书管理器接口
@@ -263,18 +432,74 @@ type NetworkConfig struct {
 	// 域名配置
 	DomainTemplates map[string]string
 	ReservedDomains []string
+	// ReservedSubdomains 保留的第一级子域名（如 git、registry），不区分大小写；为空时使用内置列表
+	ReservedSubdomains []string
+	HashLength         int    // 生成域名中哈希的长度（4-32），为 0 时为 6
+	HashAlgorithm      string // 生成域名使用的哈希算法（md5/sha256），为空时为 md5
+	// TenantReservedSubdomains 按租户放行的保留子域名（如高级租户使用 api），
+	// 只对该租户自己的基础域名 <tenantID>.<BaseDomain> 生效，不影响共享基础域名
+	TenantReservedSubdomains map[string][]string
+	// NamespaceBaseDomains 按命名空间覆盖 BaseDomain（多品牌部署），
+	// 该命名空间内生成的域名使用覆盖的基础域名，且覆盖的基础域名在该命名空间内视为公共域名
+	NamespaceBaseDomains map[string]string
+	// DomainRegistry 跨租户域名登记表，为空时不检查域名是否已被其他租户使用
+	DomainRegistry DomainRegistry
 	
 	// 公共域名配置（新增）
 	PublicDomains        []string          // 精确匹配的公共域名列表
 	PublicDomainPatterns []string          // 支持通配符的公共域名模式
+
+	// DNS 验证配置
+	SkipDNSValidation         bool     // 全局跳过自定义域名的 DNS 解析验证
+	DNSValidationSkipSuffixes []string // 跳过 DNS 解析验证的域名后缀（内部/私有域名）
+	DNSValidationTimeout      time.Duration // DNS 解析验证超时时间，为 0 时为 5s
+
+	// ICP 备案验证配置
+	ICPValidationEndpoint string // ICP 备案查询接口（GET ?domain=，返回 {"registered": bool}），为空时跳过 ICP 验证
 	
 	// 证书配置
-	CertManager string
-	AutoTLS     bool
+	CertManager       string
+	AutoTLS           bool
+	ACMECAIdentifiers []string // 签发证书的 ACME CA 标识，用于 CAA 记录检查（默认 letsencrypt.org）
+	// WithholdHostsUntilCertReady 自定义域名的 TLS Secret 写入证书数据前不在应用 Gateway 上声明该域名，
+	// 避免证书签发期间网关使用默认/自签证书响应；未就绪时不再返回错误，由下一次协调补上域名
+	WithholdHostsUntilCertReady bool
+
+	// 专用 Gateway 的 TLS 配置，为空时使用 Istio 默认值
+	GatewayTLSMinProtocolVersion string   // 最低 TLS 版本：TLS_AUTO、TLSV1_0、TLSV1_1、TLSV1_2、TLSV1_3
+	GatewayTLSCipherSuites       []string // 允许的加密套件（Envoy 支持的 OpenSSL 名称）
 
 	// Gateway 配置
 	GatewaySelector      map[string]string
 	SharedGatewayEnabled bool
+	SharedGatewayShards  int // 共享 Gateway 分片数，大于 1 时公共域名应用按租户（命名空间）分布到 <DefaultGateway>-0..N-1
+	// GatewayOverrideAllowlist gateway-override 注解允许指向的其他命名空间 Gateway（namespace/name），
+	// 应用所在命名空间的 Gateway 以及 DefaultGateway（含分片）总是允许
+	GatewayOverrideAllowlist []string
+
+	// 默认 CORS 策略，应用未配置 CORS 时使用；AllowOrigins 为空时允许应用自身域名和公共域名
+	DefaultCorsPolicy *CorsPolicy
+
+	// 后端 Service 配置
+	RequireServiceEndpoints bool // 后端 Service 有就绪端点后才创建/更新 VirtualService（Service 本身必须存在）
+
+	// 转发头部配置
+	InjectTrustedProxyHeaders bool // 在生成的路由上注入由 Envoy 连接信息生成的 X-Real-IP/X-Forwarded-Host
+	XFFNumTrustedHops         int  // 网关前可信代理（LB/CDN）的跳数，大于 0 时为网关生成设置 xff_num_trusted_hops 的 EnvoyFilter
+
+	// 响应头部配置
+	AllowedResponseHeaders []string // 允许控制器在 VirtualService 上设置的响应头部（不区分大小写），为空时不限制
+
+	// 链路追踪头部配置
+	PreserveTraceHeaders  bool     // 透传链路追踪头部：应用配置的请求头不能覆盖追踪头部，且追踪头部加入 CORS allowHeaders
+	TraceHeaders          []string // 透传的追踪头部，为空时使用 DefaultTraceHeaders
+	TraceSamplingDecision string   // 非空时在请求上设置 x-b3-sampled 采样决策（"1" 采样，"0" 不采样）
+
+	// 域名漂移处理策略（enforce/observe），为空时按 enforce 处理
+	DomainDriftPolicy DomainDriftPolicy
+
+	// 调试配置
+	DebugGatewayHeader bool // 在响应头中注入 X-Sealos-Gateway，生产环境应关闭
 }
 
 // NamespacedName 带命名空间的名称
@@ -18,15 +18,37 @@ package istio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ErrWildcardGatewayConflict 通配符域名与其他 Gateway 上的通配符域名重叠
+var ErrWildcardGatewayConflict = errors.New("wildcard host conflicts with another gateway")
+
+// ErrGatewayOverrideNotFound gateway-override 注解指定的 Gateway 不存在
+var ErrGatewayOverrideNotFound = errors.New("gateway override target not found")
+
+// ErrGatewayOverrideForbidden gateway-override 注解指定的 Gateway 不在应用命名空间内，也不是允许的系统 Gateway
+var ErrGatewayOverrideForbidden = errors.New("gateway override target is not allowed")
+
+// ErrBackingServiceNotReady 后端 Service 不存在（或要求就绪端点时尚无就绪端点），调用方应稍后重新协调
+var ErrBackingServiceNotReady = errors.New("backing service is not ready")
+
+// IsBackingServiceNotReady 判断错误是否由后端 Service 未就绪引起
+func IsBackingServiceNotReady(err error) bool {
+	return errors.Is(err, ErrBackingServiceNotReady)
+}
+
 // UniversalIstioNetworkingHelper 通用的Istio网络配置助手
 // 可用于Terminal、Resources、Devbox等控制器
 type UniversalIstioNetworkingHelper struct {
@@ -74,12 +96,16 @@ type AppNetworkingParams struct {
 	ServiceName        string
 	ServicePort        int32
 	Protocol           Protocol
+	TCPPort            int32             // ProtocolTCP 时在应用 Gateway 上暴露的端口（如数据库代理端口）
 	
 	// 可选配置
 	CustomDomain       string            // 用户指定的自定义域名
 	Timeout            *time.Duration
 	SecretHeader       string            // Terminal专用
-	CorsPolicy         *CorsPolicy
+	LoadBalancer       *LoadBalancerConfig // 为空时按应用类型选择默认策略
+	AccessLog          *AccessLogConfig  // 非空时为该应用开启网关访问日志（集群默认关闭）
+	CorsPolicy         *CorsPolicy       // 为空时使用 NetworkConfig.DefaultCorsPolicy
+	DisableCors        bool              // 不配置 CORS（包括默认策略）
 	Headers            map[string]string // 请求头部
 	ResponseHeaders    map[string]string // 响应头部
 	
@@ -100,6 +126,20 @@ func (h *UniversalIstioNetworkingHelper) CreateOrUpdateNetworking(
 	ctx context.Context,
 	params *AppNetworkingParams,
 ) error {
+	// 按 Service 当前端口修正路由目标端口，Service 不存在时不创建指向缺失 host 的 VirtualService
+	servicePort, err := h.resolveServicePort(ctx, params)
+	if err != nil {
+		return err
+	}
+	if err := h.checkServiceEndpoints(ctx, params); err != nil {
+		return err
+	}
+	if servicePort != params.ServicePort {
+		resolved := *params
+		resolved.ServicePort = servicePort
+		params = &resolved
+	}
+	
 	// 构建网络配置规范
 	spec := h.buildNetworkingSpec(params)
 	
@@ -108,6 +148,21 @@ func (h *UniversalIstioNetworkingHelper) CreateOrUpdateNetworking(
 		return fmt.Errorf("invalid networking spec: %w", err)
 	}
 	
+	// 检查通配符域名是否与其他独立 Gateway 冲突
+	if err := h.checkWildcardGatewayConflicts(ctx, spec); err != nil {
+		return err
+	}
+	
+	// 检查固定的 Gateway 是否存在
+	if err := h.validateGatewayOverride(ctx, spec); err != nil {
+		return err
+	}
+	
+	// 登记自动生成的域名，已被其他租户登记时不创建网络配置
+	if err := h.claimGeneratedDomain(ctx, params); err != nil {
+		return err
+	}
+	
 	// 检查是否已存在
 	status, err := h.networkingManager.GetNetworkingStatus(ctx, params.Name, params.Namespace)
 	if err != nil {
@@ -153,26 +208,43 @@ func (h *UniversalIstioNetworkingHelper) GetOptimalDomain(params *AppNetworkingP
 	
 	// 自动生成域名
 	tenantID := h.extractTenantID(params.Namespace)
-	domainAllocator := NewDomainAllocator(h.config)
+	domainAllocator := NewDomainAllocator(configForNamespace(h.config, params.Namespace))
 	
-	// 根据应用类型生成合适的域名前缀
-	var appName string
+	return domainAllocator.GenerateAppDomain(tenantID, generatedAppName(params))
+}
+
+// generatedAppName 根据应用类型生成合适的域名前缀
+func generatedAppName(params *AppNetworkingParams) string {
 	switch params.AppType {
 	case "terminal":
-		appName = fmt.Sprintf("terminal-%s", params.Name)
+		return fmt.Sprintf("terminal-%s", params.Name)
 	case "database", "adminer":
-		appName = fmt.Sprintf("db-%s", params.Name)
+		return fmt.Sprintf("db-%s", params.Name)
 	default:
-		appName = params.Name
+		return params.Name
 	}
-	
-	return domainAllocator.GenerateAppDomain(tenantID, appName)
+}
+
+// claimGeneratedDomain 在域名登记表中登记自动生成的域名，用户指定域名时不登记
+func (h *UniversalIstioNetworkingHelper) claimGeneratedDomain(ctx context.Context, params *AppNetworkingParams) error {
+	if params.CustomDomain != "" || len(params.Hosts) > 0 {
+		return nil
+	}
+	domainAllocator := NewDomainAllocator(configForNamespace(h.config, params.Namespace))
+	_, err := domainAllocator.ClaimAppDomain(ctx, h.extractTenantID(params.Namespace), generatedAppName(params))
+	return err
+}
+
+// ReleaseGeneratedDomain 释放为应用自动生成并登记的域名，应用删除时调用；未登记时忽略
+func (h *UniversalIstioNetworkingHelper) ReleaseGeneratedDomain(ctx context.Context, params *AppNetworkingParams) error {
+	domainAllocator := NewDomainAllocator(configForNamespace(h.config, params.Namespace))
+	return domainAllocator.ReleaseAppDomain(ctx, h.extractTenantID(params.Namespace), generatedAppName(params))
 }
 
 // AnalyzeDomainRequirements 分析域名需求
 func (h *UniversalIstioNetworkingHelper) AnalyzeDomainRequirements(params *AppNetworkingParams) *DomainAnalysis {
 	domain := h.GetOptimalDomain(params)
-	isPublic := h.domainClassifier.IsPublicDomain(domain)
+	isPublic := h.domainClassifier.IsPublicDomainForNamespace(params.Namespace, domain)
 	
 	analysis := &DomainAnalysis{
 		Domain:            domain,
@@ -183,6 +255,13 @@ func (h *UniversalIstioNetworkingHelper) AnalyzeDomainRequirements(params *AppNe
 		CertificateNeeds:  h.analyzeCertificateNeeds(domain, isPublic, params.TLSEnabled),
 	}
 	
+	// 注解固定的 Gateway 优先
+	if override := h.domainClassifier.allowedGatewayOverride(params.Annotations, params.Namespace); override != "" {
+		analysis.NeedsGateway = false
+		analysis.UseSystemGateway = false
+		analysis.GatewayReference = override
+	}
+	
 	return analysis
 }
 
@@ -196,7 +275,7 @@ func (h *UniversalIstioNetworkingHelper) buildNetworkingSpec(params *AppNetworki
 	}
 	
 	// 分析域名类型
-	classification := h.domainClassifier.ClassifyHosts(hosts)
+	classification := h.domainClassifier.ClassifyHostsForNamespace(params.Namespace, hosts)
 	
 	spec := &AppNetworkingSpec{
 		Name:        params.Name,
@@ -207,13 +286,16 @@ func (h *UniversalIstioNetworkingHelper) buildNetworkingSpec(params *AppNetworki
 		Hosts:       hosts,
 		ServiceName: params.ServiceName,
 		ServicePort: params.ServicePort,
+		TCPPort:     params.TCPPort,
 		
 		// 高级配置
 		Timeout:         params.Timeout,
-		CorsPolicy:      params.CorsPolicy,
+		CorsPolicy:      h.corsPolicy(params, hosts),
 		Headers:         params.Headers,
 		ResponseHeaders: params.ResponseHeaders,
 		SecretHeader:    params.SecretHeader,
+		LoadBalancer:    params.LoadBalancer,
+		AccessLog:       params.AccessLog,
 		
 		// 标签和注解
 		Labels:      h.buildLabels(params, classification),
@@ -228,9 +310,26 @@ func (h *UniversalIstioNetworkingHelper) buildNetworkingSpec(params *AppNetworki
 		spec.TLSConfig = h.buildTLSConfig(params, hosts, classification)
 	}
 	
+	if spec.LoadBalancer == nil {
+		spec.LoadBalancer = defaultLoadBalancer(params.AppType)
+	}
+	
 	return spec
 }
 
+// defaultLoadBalancer 按应用类型返回默认负载均衡策略。
+// Terminal 是有状态的长连接会话，按会话 Cookie 做一致性哈希保持亲和性；Adminer 无状态，轮询即可
+func defaultLoadBalancer(appType string) *LoadBalancerConfig {
+	switch appType {
+	case "terminal":
+		return &LoadBalancerConfig{Type: LoadBalancerConsistentHash, HashCookie: TerminalSessionCookie}
+	case "adminer":
+		return &LoadBalancerConfig{Type: LoadBalancerRoundRobin}
+	default:
+		return nil
+	}
+}
+
 // buildLabels 构建标签
 func (h *UniversalIstioNetworkingHelper) buildLabels(
 	params *AppNetworkingParams,
@@ -283,7 +382,7 @@ func (h *UniversalIstioNetworkingHelper) buildAnnotations(
 	// 添加优化相关注解
 	if classification.AllPublic {
 		annotations["sealos.io/gateway-optimization"] = "public-domain-shared-gateway"
-		annotations["sealos.io/gateway-reference"] = h.config.DefaultGateway
+		annotations["sealos.io/gateway-reference"] = ShardedGatewayName(h.config.DefaultGateway, params.Namespace, h.config.SharedGatewayShards)
 	} else if classification.AllCustom {
 		annotations["sealos.io/gateway-optimization"] = "custom-domain-dedicated-gateway"
 		annotations["sealos.io/gateway-reference"] = fmt.Sprintf("%s/%s-gateway", params.Namespace, params.Name)
@@ -320,7 +419,7 @@ func (h *UniversalIstioNetworkingHelper) buildTLSConfig(
 // getGatewayReference 获取Gateway引用
 func (h *UniversalIstioNetworkingHelper) getGatewayReference(name string, namespace string, isPublic bool) string {
 	if isPublic {
-		return h.config.DefaultGateway
+		return ShardedGatewayName(h.config.DefaultGateway, namespace, h.config.SharedGatewayShards)
 	}
 	return fmt.Sprintf("%s/%s-gateway", namespace, name)
 }
@@ -371,9 +470,238 @@ func (h *UniversalIstioNetworkingHelper) needsUpdate(
 		return true
 	}
 	
+	// 目标端口变化检查
+	if status.ServicePort != 0 && status.ServicePort != params.ServicePort {
+		return true
+	}
+	
+	// 访问日志开关或格式变化检查
+	if (params.AccessLog == nil) != (status.AccessLog == nil) {
+		return true
+	}
+	if params.AccessLog != nil && params.AccessLog.Format != status.AccessLog.Format {
+		return true
+	}
+	
 	return false
 }
 
+// corsPolicy 返回应用使用的 CORS 策略：显式配置的策略原样使用，未配置时使用默认策略，
+// 默认策略未指定 AllowOrigins 时允许应用自身域名和公共域名
+func (h *UniversalIstioNetworkingHelper) corsPolicy(params *AppNetworkingParams, hosts []string) *CorsPolicy {
+	if params.DisableCors {
+		return nil
+	}
+	if params.CorsPolicy != nil {
+		return params.CorsPolicy
+	}
+	if h.config.DefaultCorsPolicy == nil {
+		return nil
+	}
+	
+	policy := *h.config.DefaultCorsPolicy
+	if len(policy.AllowOrigins) == 0 {
+		policy.AllowOrigins = h.defaultCorsOrigins(params, hosts)
+	}
+	return &policy
+}
+
+// defaultCorsOrigins 生成默认 CORS 策略允许的来源：应用自身域名、命名空间基础域名及其子域名、公共域名
+func (h *UniversalIstioNetworkingHelper) defaultCorsOrigins(params *AppNetworkingParams, hosts []string) []string {
+	scheme := "http://"
+	if params.TLSEnabled {
+		scheme = "https://"
+	}
+	
+	origins := []string{}
+	for _, host := range hosts {
+		origins = append(origins, scheme+host)
+	}
+	if baseDomain := BaseDomainForNamespace(h.config, params.Namespace); baseDomain != "" {
+		origins = append(origins, scheme+baseDomain, scheme+"*."+baseDomain)
+	}
+	for _, domain := range h.config.PublicDomains {
+		origins = append(origins, scheme+domain)
+	}
+	for _, pattern := range h.config.PublicDomainPatterns {
+		origins = append(origins, scheme+pattern)
+	}
+	return deduplicateSlice(origins)
+}
+
+// resolveServicePort 返回 VirtualService 应路由到的 Service 端口。
+// Service 仍暴露配置的端口时直接使用；端口被修改时按 targetPort 匹配原端口，
+// 只有一个端口时使用该端口；无法确定时保持配置的端口；Service 不存在时返回 ErrBackingServiceNotReady。
+func (h *UniversalIstioNetworkingHelper) resolveServicePort(ctx context.Context, params *AppNetworkingParams) (int32, error) {
+	if params.ServiceName == "" || h.client == nil {
+		return params.ServicePort, nil
+	}
+	
+	svc := &corev1.Service{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: params.ServiceName, Namespace: params.Namespace}, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("%w: service %s/%s not found", ErrBackingServiceNotReady, params.Namespace, params.ServiceName)
+		}
+		return 0, fmt.Errorf("failed to get service %s/%s: %w", params.Namespace, params.ServiceName, err)
+	}
+	
+	for _, port := range svc.Spec.Ports {
+		if port.Port == params.ServicePort {
+			return params.ServicePort, nil
+		}
+	}
+	for _, port := range svc.Spec.Ports {
+		if port.TargetPort.IntValue() == int(params.ServicePort) {
+			return port.Port, nil
+		}
+	}
+	if len(svc.Spec.Ports) == 1 {
+		return svc.Spec.Ports[0].Port, nil
+	}
+	return params.ServicePort, nil
+}
+
+// checkServiceEndpoints 配置 RequireServiceEndpoints 时要求后端 Service 至少有一个就绪端点
+func (h *UniversalIstioNetworkingHelper) checkServiceEndpoints(ctx context.Context, params *AppNetworkingParams) error {
+	if !h.config.RequireServiceEndpoints || params.ServiceName == "" || h.client == nil {
+		return nil
+	}
+	
+	endpoints := &corev1.Endpoints{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: params.ServiceName, Namespace: params.Namespace}, endpoints); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: service %s/%s has no endpoints", ErrBackingServiceNotReady, params.Namespace, params.ServiceName)
+		}
+		return fmt.Errorf("failed to get endpoints %s/%s: %w", params.Namespace, params.ServiceName, err)
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: service %s/%s has no ready endpoints", ErrBackingServiceNotReady, params.Namespace, params.ServiceName)
+}
+
+// checkWildcardGatewayConflicts 检查自定义通配符域名是否与集群中其他 Gateway 的通配符域名重叠
+// 重叠的通配符会导致路由不确定，后创建的 Gateway 会被拒绝
+func (h *UniversalIstioNetworkingHelper) checkWildcardGatewayConflicts(
+	ctx context.Context,
+	spec *AppNetworkingSpec,
+) error {
+	var wildcards []string
+	for _, host := range spec.Hosts {
+		if isWildcardHost(host) && !h.domainClassifier.IsPublicDomainForNamespace(spec.Namespace, host) {
+			wildcards = append(wildcards, strings.ToLower(host))
+		}
+	}
+	if len(wildcards) == 0 {
+		return nil
+	}
+
+	gatewayList := &unstructured.UnstructuredList{}
+	gatewayList.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   gatewayGVK.Group,
+		Version: gatewayGVK.Version,
+		Kind:    "GatewayList",
+	})
+	if err := h.client.List(ctx, gatewayList); err != nil {
+		return fmt.Errorf("failed to list gateways: %w", err)
+	}
+
+	ownName := fmt.Sprintf("%s-gateway", spec.Name)
+	var own *unstructured.Unstructured
+	for i := range gatewayList.Items {
+		if gatewayList.Items[i].GetNamespace() == spec.Namespace && gatewayList.Items[i].GetName() == ownName {
+			own = &gatewayList.Items[i]
+			break
+		}
+	}
+
+	for i := range gatewayList.Items {
+		gateway := &gatewayList.Items[i]
+		if gateway == own {
+			continue
+		}
+		// 已存在的自身 Gateway 比对方更早创建时，由对方承担冲突
+		if own != nil && own.GetCreationTimestamp().Time.Before(gateway.GetCreationTimestamp().Time) {
+			continue
+		}
+
+		for _, existing := range gatewayServerHosts(gateway) {
+			if !isWildcardHost(existing) {
+				continue
+			}
+			for _, host := range wildcards {
+				if wildcardHostsOverlap(host, existing) {
+					return fmt.Errorf("%w: %s overlaps %s on gateway %s/%s",
+						ErrWildcardGatewayConflict, host, existing, gateway.GetNamespace(), gateway.GetName())
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateGatewayOverride 校验 gateway-override 注解指定的 Gateway 允许使用且存在
+func (h *UniversalIstioNetworkingHelper) validateGatewayOverride(ctx context.Context, spec *AppNetworkingSpec) error {
+	override := gatewayOverride(spec.Annotations, spec.Namespace)
+	if override == "" {
+		return nil
+	}
+
+	parts := strings.SplitN(override, "/", 2)
+	if parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid %s annotation %q, expected namespace/name", GatewayOverrideAnnotation, override)
+	}
+	if !h.domainClassifier.IsGatewayOverrideAllowed(spec.Namespace, override) {
+		return fmt.Errorf("%w: %s", ErrGatewayOverrideForbidden, override)
+	}
+
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	if err := h.client.Get(ctx, client.ObjectKey{Namespace: parts[0], Name: parts[1]}, gateway); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %s", ErrGatewayOverrideNotFound, override)
+		}
+		return fmt.Errorf("failed to get gateway %s: %w", override, err)
+	}
+	return nil
+}
+
+// gatewayServerHosts 提取 Gateway 所有 server 的主机（去掉 namespace/ 前缀）
+func gatewayServerHosts(gateway *unstructured.Unstructured) []string {
+	servers, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "servers")
+
+	var hosts []string
+	for _, serverInterface := range servers {
+		server, ok := serverInterface.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		serverHosts, _, _ := unstructured.NestedStringSlice(server, "hosts")
+		for _, host := range serverHosts {
+			if idx := strings.Index(host, "/"); idx >= 0 {
+				host = host[idx+1:]
+			}
+			hosts = append(hosts, strings.ToLower(host))
+		}
+	}
+	return hosts
+}
+
+// isWildcardHost 判断是否为具体的通配符域名（"*" 本身不算）
+func isWildcardHost(host string) bool {
+	return strings.HasPrefix(host, "*.") && len(host) > 2
+}
+
+// wildcardHostsOverlap 判断两个通配符域名是否重叠（相同或一方覆盖另一方）
+func wildcardHostsOverlap(a, b string) bool {
+	a = strings.TrimPrefix(a, "*.")
+	b = strings.TrimPrefix(b, "*.")
+	return a == b || strings.HasSuffix(a, "."+b) || strings.HasSuffix(b, "."+a)
+}
+
 // extractTenantID 从命名空间提取租户ID
 func (h *UniversalIstioNetworkingHelper) extractTenantID(namespace string) string {
 	if len(namespace) > 3 && namespace[:3] == "ns-" {
@@ -392,6 +720,20 @@ type DomainAnalysis struct {
 	CertificateNeeds  CertificateNeeds
 }
 
+// 域名类型，写入应用状态和 sealos.io/domain-type 注解
+const (
+	DomainTypePublic = "public"
+	DomainTypeCustom = "custom"
+)
+
+// DomainType 返回域名类型（public/custom）
+func (a *DomainAnalysis) DomainType() string {
+	if a.IsPublicDomain {
+		return DomainTypePublic
+	}
+	return DomainTypeCustom
+}
+
 // CertificateNeeds 证书需求
 type CertificateNeeds struct {
 	Required   bool   // 是否需要证书
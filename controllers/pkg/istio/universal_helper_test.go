@@ -18,15 +18,24 @@ package istio
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -209,8 +218,8 @@ func TestUniversalIstioNetworkingHelper_AnalyzeDomainRequirements(t *testing.T)
 }
 
 func TestUniversalIstioNetworkingHelper_CreateOrUpdateNetworking(t *testing.T) {
-	scheme := runtime.NewScheme()
-	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	scheme := clientgoscheme.Scheme
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newTestService("test-svc", "ns-test", 8080)).Build()
 
 	config := &NetworkConfig{
 		BaseDomain:     "cloud.sealos.io",
@@ -458,4 +467,639 @@ func (m *mockOwner) GetManagedFields() []metav1.ManagedFieldsEntry {
 	return nil
 }
 
-func (m *mockOwner) SetManagedFields(managedFields []metav1.ManagedFieldsEntry) {}
\ No newline at end of file
+func (m *mockOwner) SetManagedFields(managedFields []metav1.ManagedFieldsEntry) {}
+func newTestGateway(name, namespace string, hosts []string, created time.Time) *unstructured.Unstructured {
+	gateway := &unstructured.Unstructured{}
+	gateway.SetGroupVersionKind(gatewayGVK)
+	gateway.SetName(name)
+	gateway.SetNamespace(namespace)
+	gateway.SetCreationTimestamp(metav1.NewTime(created))
+	_ = unstructured.SetNestedSlice(gateway.Object, []interface{}{
+		map[string]interface{}{
+			"hosts": stringSliceToInterface(hosts),
+			"port":  map[string]interface{}{"number": int64(443), "name": "https", "protocol": "HTTPS"},
+		},
+	}, "spec", "servers")
+	return gateway
+}
+
+func TestUniversalIstioNetworkingHelper_WildcardGatewayConflicts(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		existing    []*unstructured.Unstructured
+		hosts       []string
+		expectError bool
+	}{
+		{
+			name: "non-overlapping wildcards allowed",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("app-a-gateway", "ns-a", []string{"*.a.example.com"}, now),
+			},
+			hosts: []string{"*.b.example.com"},
+		},
+		{
+			name: "identical wildcard rejected",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("app-a-gateway", "ns-a", []string{"*.shared.example.com"}, now),
+			},
+			hosts:       []string{"*.shared.example.com"},
+			expectError: true,
+		},
+		{
+			name: "nested wildcard rejected",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("app-a-gateway", "ns-a", []string{"ns-a/*.example.com"}, now),
+			},
+			hosts:       []string{"*.shared.example.com"},
+			expectError: true,
+		},
+		{
+			name: "exact host does not conflict with wildcard",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("app-a-gateway", "ns-a", []string{"www.shared.example.com"}, now),
+			},
+			hosts: []string{"*.shared.example.com"},
+		},
+		{
+			name: "own gateway is ignored",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("test-app-gateway", "ns-test", []string{"*.shared.example.com"}, now),
+			},
+			hosts: []string{"*.shared.example.com"},
+		},
+		{
+			name: "earlier gateway keeps wildcard",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("test-app-gateway", "ns-test", []string{"*.shared.example.com"}, now.Add(-time.Hour)),
+				newTestGateway("app-b-gateway", "ns-b", []string{"*.shared.example.com"}, now),
+			},
+			hosts: []string{"*.shared.example.com"},
+		},
+		{
+			name: "public wildcard is not checked",
+			existing: []*unstructured.Unstructured{
+				newTestGateway("app-a-gateway", "ns-a", []string{"*.cloud.sealos.io"}, now),
+			},
+			hosts: []string{"*.cloud.sealos.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newTestService("test-svc", "ns-test", 8080))
+			for _, gateway := range tt.existing {
+				builder = builder.WithObjects(gateway)
+			}
+			mockManager := &mockNetworkingManager{}
+			helper := &UniversalIstioNetworkingHelper{
+				client:            builder.Build(),
+				networkingManager: mockManager,
+				domainClassifier:  NewDomainClassifier(config),
+				config:            config,
+				appType:           "terminal",
+			}
+
+			err := helper.CreateOrUpdateNetworking(context.Background(), &AppNetworkingParams{
+				Name:        "test-app",
+				Namespace:   "ns-test",
+				Hosts:       tt.hosts,
+				ServiceName: "test-svc",
+				ServicePort: 8080,
+				Protocol:    ProtocolHTTP,
+			})
+
+			if (err != nil) != tt.expectError {
+				t.Fatalf("CreateOrUpdateNetworking() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !errors.Is(err, ErrWildcardGatewayConflict) {
+				t.Errorf("CreateOrUpdateNetworking() error = %v, want ErrWildcardGatewayConflict", err)
+			}
+			if tt.expectError == mockManager.createCalled {
+				t.Errorf("CreateAppNetworking called = %v, want %v", mockManager.createCalled, !tt.expectError)
+			}
+		})
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_GatewayOverride(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+		GatewayOverrideAllowlist: []string{"istio-system/isolated-gateway", "istio-system/missing-gateway"},
+	}
+	isolated := newTestGateway("isolated-gateway", "istio-system", []string{"*.cloud.sealos.io"}, time.Now())
+	own := newTestGateway("own-gateway", "ns-test", []string{"app.example.com"}, time.Now())
+	otherTenant := newTestGateway("their-gateway", "ns-other", []string{"*.cloud.sealos.io"}, time.Now())
+	system := newTestGateway("sealos-gateway", "istio-system", []string{"*.cloud.sealos.io"}, time.Now())
+
+	tests := []struct {
+		name         string
+		override     string
+		hosts        []string
+		wantErr      error
+		wantGateways []string
+	}{
+		{
+			name:         "override to existing gateway",
+			override:     "istio-system/isolated-gateway",
+			hosts:        []string{"app.cloud.sealos.io"},
+			wantGateways: []string{"istio-system/isolated-gateway"},
+		},
+		{
+			name:         "override replaces dedicated gateway for custom domain",
+			override:     "istio-system/isolated-gateway",
+			hosts:        []string{"app.example.com"},
+			wantGateways: []string{"istio-system/isolated-gateway"},
+		},
+		{
+			name:     "override to missing gateway",
+			override: "istio-system/missing-gateway",
+			hosts:    []string{"app.cloud.sealos.io"},
+			wantErr:  ErrGatewayOverrideNotFound,
+		},
+		{
+			name:     "override without namespace resolves in app namespace",
+			override: "isolated-gateway",
+			hosts:    []string{"app.cloud.sealos.io"},
+			wantErr:  ErrGatewayOverrideNotFound,
+		},
+		{
+			name:         "override to gateway in app namespace",
+			override:     "own-gateway",
+			hosts:        []string{"app.example.com"},
+			wantGateways: []string{"ns-test/own-gateway"},
+		},
+		{
+			name:         "override to system gateway",
+			override:     "istio-system/sealos-gateway",
+			hosts:        []string{"app.example.com"},
+			wantGateways: []string{"istio-system/sealos-gateway"},
+		},
+		{
+			name:     "override to another tenant's gateway is rejected",
+			override: "ns-other/their-gateway",
+			hosts:    []string{"app.cloud.sealos.io"},
+			wantErr:  ErrGatewayOverrideForbidden,
+		},
+		{
+			name:     "override to gateway outside allowlist is rejected",
+			override: "istio-system/internal-gateway",
+			hosts:    []string{"app.cloud.sealos.io"},
+			wantErr:  ErrGatewayOverrideForbidden,
+		},
+		{
+			name:         "no override lets classifier decide",
+			hosts:        []string{"app.cloud.sealos.io"},
+			wantGateways: []string{"istio-system/sealos-gateway"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockManager := &mockNetworkingManager{}
+			helper := &UniversalIstioNetworkingHelper{
+				client:            fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(isolated.DeepCopy(), own.DeepCopy(), otherTenant.DeepCopy(), system.DeepCopy(), newTestService("test-svc", "ns-test", 8080)).Build(),
+				networkingManager: mockManager,
+				domainClassifier:  NewDomainClassifier(config),
+				config:            config,
+				appType:           "terminal",
+			}
+
+			params := &AppNetworkingParams{
+				Name:        "test-app",
+				Namespace:   "ns-test",
+				Hosts:       tt.hosts,
+				ServiceName: "test-svc",
+				ServicePort: 8080,
+				Protocol:    ProtocolHTTP,
+			}
+			if tt.override != "" {
+				params.Annotations = map[string]string{GatewayOverrideAnnotation: tt.override}
+			}
+
+			err := helper.CreateOrUpdateNetworking(context.Background(), params)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateOrUpdateNetworking() error = %v, want %v", err, tt.wantErr)
+				}
+				if mockManager.createCalled {
+					t.Error("CreateAppNetworking should not be called when the override gateway is rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateOrUpdateNetworking() error = %v", err)
+			}
+
+			spec := mockManager.lastSpec
+			classifier := helper.domainClassifier
+			if got := classifier.GetGatewayReference(spec); got != tt.wantGateways[0] {
+				t.Errorf("GetGatewayReference() = %s, want %s", got, tt.wantGateways[0])
+			}
+			vsConfig := classifier.BuildOptimizedVirtualServiceConfig(spec)
+			if strings.Join(vsConfig.Gateways, ",") != strings.Join(tt.wantGateways, ",") {
+				t.Errorf("VirtualService gateways = %v, want %v", vsConfig.Gateways, tt.wantGateways)
+			}
+			if tt.override != "" && classifier.BuildOptimizedGatewayConfig(spec) != nil {
+				t.Error("no dedicated gateway should be built when the app is pinned to a gateway")
+			}
+		})
+	}
+}
+
+func newTestService(name, namespace string, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: port, TargetPort: intstr.FromInt32(port)}},
+		},
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_FollowsServicePortChange(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService("test-svc", "ns-test", 8080)
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(svc).Build()
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	helper := NewUniversalIstioNetworkingHelper(c, config, "terminal")
+	params := &AppNetworkingParams{
+		Name:        "test-app",
+		Namespace:   "ns-test",
+		Hosts:       []string{"app.cloud.sealos.io"},
+		ServiceName: "test-svc",
+		ServicePort: 8080,
+		Protocol:    ProtocolHTTP,
+	}
+
+	destinationPort := func() int32 {
+		t.Helper()
+		vs, err := NewVirtualServiceController(c, config).Get(ctx, "test-app-vs", "ns-test")
+		if err != nil {
+			t.Fatalf("failed to get virtualservice: %v", err)
+		}
+		return vs.ServicePort
+	}
+
+	if err := helper.CreateOrUpdateNetworking(ctx, params); err != nil {
+		t.Fatalf("CreateOrUpdateNetworking() error = %v", err)
+	}
+	if got := destinationPort(); got != 8080 {
+		t.Fatalf("destination port = %d, want 8080", got)
+	}
+
+	// 用户把 Service 端口从 8080 改为 9090，容器端口不变
+	svc.Spec.Ports[0].Port = 9090
+	if err := c.Update(ctx, svc); err != nil {
+		t.Fatalf("failed to update service: %v", err)
+	}
+	if err := helper.CreateOrUpdateNetworking(ctx, params); err != nil {
+		t.Fatalf("CreateOrUpdateNetworking() after port change error = %v", err)
+	}
+	if got := destinationPort(); got != 9090 {
+		t.Errorf("destination port = %d, want 9090 after the service port changed", got)
+	}
+	if params.ServicePort != 8080 {
+		t.Errorf("caller params should not be modified, got ServicePort %d", params.ServicePort)
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_RequiresBackingService(t *testing.T) {
+	readyEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-svc", Namespace: "ns-test"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+	emptyEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-svc", Namespace: "ns-test"},
+	}
+
+	tests := []struct {
+		name             string
+		objects          []client.Object
+		requireEndpoints bool
+		wantErr          bool
+	}{
+		{
+			name:    "service present",
+			objects: []client.Object{newTestService("test-svc", "ns-test", 8080)},
+		},
+		{
+			name:    "service absent",
+			wantErr: true,
+		},
+		{
+			name:             "service with ready endpoints",
+			objects:          []client.Object{newTestService("test-svc", "ns-test", 8080), readyEndpoints},
+			requireEndpoints: true,
+		},
+		{
+			name:             "service without ready endpoints",
+			objects:          []client.Object{newTestService("test-svc", "ns-test", 8080), emptyEndpoints},
+			requireEndpoints: true,
+			wantErr:          true,
+		},
+		{
+			name:             "service without endpoints object",
+			objects:          []client.Object{newTestService("test-svc", "ns-test", 8080)},
+			requireEndpoints: true,
+			wantErr:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(tt.objects...).Build()
+			config := &NetworkConfig{
+				BaseDomain:              "cloud.sealos.io",
+				DefaultGateway:          "istio-system/sealos-gateway",
+				SharedGatewayEnabled:    true,
+				PublicDomains:           []string{"cloud.sealos.io"},
+				PublicDomainPatterns:    []string{"*.cloud.sealos.io"},
+				RequireServiceEndpoints: tt.requireEndpoints,
+			}
+			helper := NewUniversalIstioNetworkingHelper(c, config, "terminal")
+
+			err := helper.CreateOrUpdateNetworking(ctx, &AppNetworkingParams{
+				Name:        "test-app",
+				Namespace:   "ns-test",
+				Hosts:       []string{"app.cloud.sealos.io"},
+				ServiceName: "test-svc",
+				ServicePort: 8080,
+				Protocol:    ProtocolHTTP,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrBackingServiceNotReady) {
+					t.Fatalf("CreateOrUpdateNetworking() error = %v, want ErrBackingServiceNotReady", err)
+				}
+			} else if err != nil {
+				t.Fatalf("CreateOrUpdateNetworking() error = %v", err)
+			}
+
+			_, err = NewVirtualServiceController(c, config).Get(ctx, "test-app-vs", "ns-test")
+			if tt.wantErr && !apierrors.IsNotFound(err) {
+				t.Errorf("virtualservice should not be created, Get() error = %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("virtualservice should be created, Get() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_NamespaceBaseDomain(t *testing.T) {
+	config := &NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+		NamespaceBaseDomains: map[string]string{
+			"ns-tenanta": "brand1.com",
+			"ns-tenantb": "brand2.com",
+		},
+	}
+	helper := &UniversalIstioNetworkingHelper{
+		domainClassifier: NewDomainClassifier(config),
+		config:           config,
+		appType:          "terminal",
+	}
+
+	tests := []struct {
+		name       string
+		namespace  string
+		wantSuffix string
+	}{
+		{name: "brand one tenant", namespace: "ns-tenanta", wantSuffix: ".tenanta.brand1.com"},
+		{name: "brand two tenant", namespace: "ns-tenantb", wantSuffix: ".tenantb.brand2.com"},
+		{name: "tenant without override", namespace: "ns-tenantc", wantSuffix: ".tenantc.cloud.sealos.io"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &AppNetworkingParams{
+				Name:      "app",
+				Namespace: tt.namespace,
+				AppType:   "terminal",
+			}
+			analysis := helper.AnalyzeDomainRequirements(params)
+			if !strings.HasSuffix(analysis.Domain, tt.wantSuffix) {
+				t.Errorf("Domain = %s, want suffix %s", analysis.Domain, tt.wantSuffix)
+			}
+			if !analysis.IsPublicDomain || !analysis.UseSystemGateway {
+				t.Errorf("domain %s should be classified as public in %s", analysis.Domain, tt.namespace)
+			}
+		})
+	}
+
+	if config.BaseDomain != "cloud.sealos.io" {
+		t.Errorf("shared config BaseDomain modified to %s", config.BaseDomain)
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_DefaultCorsPolicy(t *testing.T) {
+	maxAge := time.Hour
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+		DefaultCorsPolicy: &CorsPolicy{
+			AllowMethods: []string{"GET", "POST", "OPTIONS"},
+			AllowHeaders: []string{"content-type"},
+			MaxAge:       &maxAge,
+		},
+	}
+	explicit := &CorsPolicy{AllowOrigins: []string{"https://example.com"}, AllowMethods: []string{"GET"}}
+
+	tests := []struct {
+		name   string
+		config *NetworkConfig
+		params *AppNetworkingParams
+		want   *CorsPolicy
+	}{
+		{
+			name:   "default applied",
+			config: config,
+			params: &AppNetworkingParams{Name: "app", Namespace: "ns-test", Hosts: []string{"app.cloud.sealos.io"}, TLSEnabled: true},
+			want: &CorsPolicy{
+				AllowOrigins: []string{"https://app.cloud.sealos.io", "https://cloud.sealos.io", "https://*.cloud.sealos.io"},
+				AllowMethods: []string{"GET", "POST", "OPTIONS"},
+				AllowHeaders: []string{"content-type"},
+				MaxAge:       &maxAge,
+			},
+		},
+		{
+			name:   "default applied to custom domain without tls",
+			config: config,
+			params: &AppNetworkingParams{Name: "app", Namespace: "ns-test", Hosts: []string{"app.example.com"}},
+			want: &CorsPolicy{
+				AllowOrigins: []string{"http://app.example.com", "http://cloud.sealos.io", "http://*.cloud.sealos.io"},
+				AllowMethods: []string{"GET", "POST", "OPTIONS"},
+				AllowHeaders: []string{"content-type"},
+				MaxAge:       &maxAge,
+			},
+		},
+		{
+			name:   "explicit policy used as-is",
+			config: config,
+			params: &AppNetworkingParams{Name: "app", Namespace: "ns-test", Hosts: []string{"app.cloud.sealos.io"}, CorsPolicy: explicit},
+			want:   explicit,
+		},
+		{
+			name:   "opt-out",
+			config: config,
+			params: &AppNetworkingParams{Name: "app", Namespace: "ns-test", Hosts: []string{"app.cloud.sealos.io"}, DisableCors: true},
+		},
+		{
+			name:   "no default configured",
+			config: &NetworkConfig{BaseDomain: "cloud.sealos.io"},
+			params: &AppNetworkingParams{Name: "app", Namespace: "ns-test", Hosts: []string{"app.cloud.sealos.io"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helper := &UniversalIstioNetworkingHelper{
+				domainClassifier: NewDomainClassifier(tt.config),
+				config:           tt.config,
+				appType:          "terminal",
+			}
+			got := helper.buildNetworkingSpec(tt.params).CorsPolicy
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CorsPolicy = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if len(config.DefaultCorsPolicy.AllowOrigins) != 0 {
+		t.Errorf("default policy modified: %v", config.DefaultCorsPolicy.AllowOrigins)
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_DefaultCorsPolicyOrigins(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newTestService("test-svc", "ns-test", 8080)).Build()
+	config := &NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+		DefaultCorsPolicy:    &CorsPolicy{AllowMethods: []string{"GET"}},
+	}
+	helper := NewUniversalIstioNetworkingHelper(c, config, "terminal")
+	err := helper.CreateOrUpdateNetworking(ctx, &AppNetworkingParams{
+		Name:        "test-app",
+		Namespace:   "ns-test",
+		Hosts:       []string{"app.cloud.sealos.io"},
+		ServiceName: "test-svc",
+		ServicePort: 8080,
+		Protocol:    ProtocolHTTP,
+		TLSEnabled:  true,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateNetworking() error = %v", err)
+	}
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(virtualServiceGVK)
+	if err := c.Get(ctx, types.NamespacedName{Name: "test-app-vs", Namespace: "ns-test"}, vs); err != nil {
+		t.Fatalf("failed to get virtualservice: %v", err)
+	}
+	httpRoutes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if len(httpRoutes) == 0 {
+		t.Fatalf("virtualservice has no http routes")
+	}
+	origins, _, _ := unstructured.NestedSlice(httpRoutes[0].(map[string]interface{}), "corsPolicy", "allowOrigins")
+	want := []interface{}{
+		map[string]interface{}{"exact": "https://app.cloud.sealos.io"},
+		map[string]interface{}{"exact": "https://cloud.sealos.io"},
+		map[string]interface{}{"regex": `^https://[^.]+\.cloud\.sealos\.io$`},
+	}
+	if !reflect.DeepEqual(origins, want) {
+		t.Fatalf("corsPolicy.allowOrigins = %v, want %v", origins, want)
+	}
+
+	// 通配符只匹配一级子域名，且不能匹配其他域名
+	pattern := regexp.MustCompile(origins[2].(map[string]interface{})["regex"].(string))
+	for origin, match := range map[string]bool{
+		"https://other.cloud.sealos.io":   true,
+		"https://a.b.cloud.sealos.io":     false,
+		"https://cloud.sealos.io.evil.io": false,
+		"https://evilcloud.sealos.io":     false,
+		"http://other.cloud.sealos.io":    false,
+	} {
+		if got := pattern.MatchString(origin); got != match {
+			t.Errorf("origin %s matched = %v, want %v", origin, got, match)
+		}
+	}
+}
+
+func TestUniversalIstioNetworkingHelper_ClaimsGeneratedDomain(t *testing.T) {
+	ctx := context.Background()
+	registry := NewInMemoryDomainRegistry()
+	config := &NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+		DomainRegistry: registry,
+	}
+	newHelper := func(mockManager *mockNetworkingManager) *UniversalIstioNetworkingHelper {
+		return &UniversalIstioNetworkingHelper{
+			client:            fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newTestService("test-svc", "ns-tenant-a", 8080)).Build(),
+			networkingManager: mockManager,
+			domainClassifier:  NewDomainClassifier(config),
+			config:            config,
+			appType:           "terminal",
+		}
+	}
+	params := &AppNetworkingParams{
+		Name:        "app",
+		Namespace:   "ns-tenant-a",
+		AppType:     "terminal",
+		ServiceName: "test-svc",
+		ServicePort: 8080,
+		Protocol:    ProtocolHTTP,
+	}
+
+	mockManager := &mockNetworkingManager{}
+	helper := newHelper(mockManager)
+	if err := helper.CreateOrUpdateNetworking(ctx, params); err != nil {
+		t.Fatalf("CreateOrUpdateNetworking() error = %v", err)
+	}
+	domain := helper.GetOptimalDomain(params)
+	if owner, ok, _ := registry.Owner(domain); !ok || owner != "tenant-a" {
+		t.Fatalf("generated domain %s owner = %q, want tenant-a", domain, owner)
+	}
+
+	if err := helper.ReleaseGeneratedDomain(ctx, params); err != nil {
+		t.Fatalf("ReleaseGeneratedDomain() error = %v", err)
+	}
+	if _, ok, _ := registry.Owner(domain); ok {
+		t.Fatalf("generated domain %s should be released", domain)
+	}
+
+	// 生成的域名已被其他租户登记时不创建网络配置
+	if err := registry.Claim(ctx, "tenant-b", domain); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	mockManager = &mockNetworkingManager{}
+	err := newHelper(mockManager).CreateOrUpdateNetworking(ctx, params)
+	if !errors.Is(err, ErrDomainClaimed) {
+		t.Fatalf("CreateOrUpdateNetworking() error = %v, want ErrDomainClaimed", err)
+	}
+	if mockManager.createCalled {
+		t.Error("CreateAppNetworking should not be called when the generated domain is claimed by another tenant")
+	}
+}
@@ -233,6 +233,10 @@ func ValidateNetworkingSpec(spec *AppNetworkingSpec) error {
 		return fmt.Errorf("at least one host is required")
 	}
 
+	if spec.Protocol == ProtocolTCP && (spec.TCPPort <= 0 || spec.TCPPort > 65535) {
+		return fmt.Errorf("tcpPort must be between 1 and 65535 for tcp protocol")
+	}
+
 	return nil
 }
 
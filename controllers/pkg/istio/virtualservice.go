@@ -19,8 +19,13 @@ package istio
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,6 +44,27 @@ var (
 	}
 )
 
+// trustedProxyHeaders 由 Envoy 连接信息生成的可信转发头部，覆盖客户端传入的同名头部，
+// 后端应用可以信任这些头部获取真实客户端地址。X-Forwarded-For 交给 Envoy 按可信跳数维护，
+// 这里不覆盖，否则会丢掉转发链
+var trustedProxyHeaders = map[string]string{
+	"X-Real-IP":        "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+	"X-Forwarded-Host": "%REQ(:AUTHORITY)%",
+}
+
+// DefaultTraceHeaders 默认透传的链路追踪头部（W3C Trace Context、B3 和 Envoy 请求 ID）
+var DefaultTraceHeaders = []string{
+	"traceparent", "tracestate",
+	"x-b3-traceid", "x-b3-spanid", "x-b3-parentspanid", "x-b3-sampled", "x-b3-flags", "b3",
+	"x-request-id",
+}
+
+// TraceSampledHeader 注入采样决策使用的头部
+const TraceSampledHeader = "x-b3-sampled"
+
+// virtualServiceHostRegex VirtualService hosts 允许的格式：域名、短服务名，或以 "*." 开头的通配符域名（另外允许单独的 "*"）
+var virtualServiceHostRegex = regexp.MustCompile(`^(\*\.)?[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?)*$`)
+
 // virtualServiceController VirtualService 控制器实现
 type virtualServiceController struct {
 	client client.Client
@@ -54,6 +80,22 @@ func NewVirtualServiceController(client client.Client, config *NetworkConfig) Vi
 }
 
 func (v *virtualServiceController) Create(ctx context.Context, config *VirtualServiceConfig) error {
+	if err := validateVirtualServiceHosts(config); err != nil {
+		return err
+	}
+	if err := validateHTTPRoutes(config); err != nil {
+		return err
+	}
+	if err := validateDestinations(config); err != nil {
+		return err
+	}
+	if err := validateL4Routes(config); err != nil {
+		return err
+	}
+	if err := validateResponseHeaders(v.config, config); err != nil {
+		return err
+	}
+
 	vs := &unstructured.Unstructured{}
 	vs.SetGroupVersionKind(virtualServiceGVK)
 	vs.SetName(config.Name)
@@ -69,18 +111,42 @@ func (v *virtualServiceController) Create(ctx context.Context, config *VirtualSe
 	labels["app.kubernetes.io/component"] = "networking"
 	vs.SetLabels(labels)
 
+	// 设置注解
+	if len(config.Annotations) > 0 {
+		vs.SetAnnotations(MergeLabels(nil, config.Annotations))
+	}
+
 	// 构建 VirtualService spec
 	spec := v.buildVirtualServiceSpec(config)
+	if err := validateVirtualServiceSpec(spec); err != nil {
+		return fmt.Errorf("virtualservice %s/%s: %w", config.Namespace, config.Name, err)
+	}
 	// 确保所有值都可以深拷贝
 	safeSpec := makeSafeForDeepCopy(spec)
 	if err := unstructured.SetNestedMap(vs.Object, safeSpec.(map[string]interface{}), "spec"); err != nil {
 		return fmt.Errorf("failed to set virtualservice spec: %w", err)
 	}
 
-	return v.client.Create(ctx, vs)
+	return virtualServiceError(config.Namespace, config.Name, v.client.Create(ctx, vs))
 }
 
 func (v *virtualServiceController) Update(ctx context.Context, config *VirtualServiceConfig) error {
+	if err := validateVirtualServiceHosts(config); err != nil {
+		return err
+	}
+	if err := validateHTTPRoutes(config); err != nil {
+		return err
+	}
+	if err := validateDestinations(config); err != nil {
+		return err
+	}
+	if err := validateL4Routes(config); err != nil {
+		return err
+	}
+	if err := validateResponseHeaders(v.config, config); err != nil {
+		return err
+	}
+
 	vs := &unstructured.Unstructured{}
 	vs.SetGroupVersionKind(virtualServiceGVK)
 
@@ -90,11 +156,14 @@ func (v *virtualServiceController) Update(ctx context.Context, config *VirtualSe
 	}
 
 	if err := v.client.Get(ctx, key, vs); err != nil {
-		return fmt.Errorf("failed to get virtualservice: %w", err)
+		return fmt.Errorf("failed to get virtualservice: %w", virtualServiceError(config.Namespace, config.Name, err))
 	}
 
 	// 更新 spec
 	spec := v.buildVirtualServiceSpec(config)
+	if err := validateVirtualServiceSpec(spec); err != nil {
+		return fmt.Errorf("virtualservice %s/%s: %w", config.Namespace, config.Name, err)
+	}
 	// 确保所有值都可以深拷贝
 	safeSpec := makeSafeForDeepCopy(spec)
 	if err := unstructured.SetNestedMap(vs.Object, safeSpec.(map[string]interface{}), "spec"); err != nil {
@@ -111,7 +180,12 @@ func (v *virtualServiceController) Update(ctx context.Context, config *VirtualSe
 	}
 	vs.SetLabels(labels)
 
-	return v.client.Update(ctx, vs)
+	// 更新注解
+	if len(config.Annotations) > 0 {
+		vs.SetAnnotations(MergeLabels(vs.GetAnnotations(), config.Annotations))
+	}
+
+	return virtualServiceError(config.Namespace, config.Name, v.client.Update(ctx, vs))
 }
 
 func (v *virtualServiceController) Delete(ctx context.Context, name, namespace string) error {
@@ -133,12 +207,36 @@ func (v *virtualServiceController) Get(ctx context.Context, name, namespace stri
 	}
 
 	if err := v.client.Get(ctx, key, vs); err != nil {
-		return nil, err
+		return nil, virtualServiceError(namespace, name, err)
 	}
 
 	return v.parseVirtualService(vs)
 }
 
+func (v *virtualServiceController) List(ctx context.Context, namespace string, opts ...client.ListOption) ([]*VirtualService, error) {
+	vsList := &unstructured.UnstructuredList{}
+	vsList.SetGroupVersionKind(virtualServiceGVK.GroupVersion().WithKind("VirtualServiceList"))
+
+	// 默认过滤放在最前面，调用方传入的同类选项会覆盖它
+	listOpts := append([]client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels{"app.kubernetes.io/managed-by": "sealos-istio"},
+	}, opts...)
+	if err := v.client.List(ctx, vsList, listOpts...); err != nil {
+		return nil, fmt.Errorf("failed to list virtualservices in namespace %s: %w", namespace, err)
+	}
+
+	result := make([]*VirtualService, 0, len(vsList.Items))
+	for i := range vsList.Items {
+		vs, err := v.parseVirtualService(&vsList.Items[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse virtualservice %s/%s: %w", namespace, vsList.Items[i].GetName(), err)
+		}
+		result = append(result, vs)
+	}
+	return result, nil
+}
+
 func (v *virtualServiceController) Suspend(ctx context.Context, name, namespace string) error {
 	vs := &unstructured.Unstructured{}
 	vs.SetGroupVersionKind(virtualServiceGVK)
@@ -149,7 +247,14 @@ func (v *virtualServiceController) Suspend(ctx context.Context, name, namespace
 	}
 
 	if err := v.client.Get(ctx, key, vs); err != nil {
-		return fmt.Errorf("failed to get virtualservice: %w", err)
+		return fmt.Errorf("failed to get virtualservice: %w", virtualServiceError(namespace, name, err))
+	}
+
+	// 保存原始路由供 Resume 还原；已暂停时保留首次保存的配置，避免被暂停路由覆盖
+	if vs.GetLabels()["network.sealos.io/suspended"] != "true" {
+		if err := v.saveOriginalSpec(ctx, vs); err != nil {
+			return err
+		}
 	}
 
 	// 通过设置路由到不存在的服务来暂停
@@ -162,20 +267,16 @@ func (v *virtualServiceController) Suspend(ctx context.Context, name, namespace
 					},
 				},
 			},
-			"fault": map[string]interface{}{
-				"abort": map[string]interface{}{
-					"percentage": map[string]interface{}{
-						"value": int64(100),
-					},
-					"httpStatus": int64(503),
-				},
-			},
+			"fault": buildAbortFault(100, 503),
 		},
 	}
 
 	if err := unstructured.SetNestedSlice(vs.Object, suspendedRoute, "spec", "http"); err != nil {
 		return fmt.Errorf("failed to suspend virtualservice: %w", err)
 	}
+	// TCP/TLS 路由无法返回错误响应，暂停时直接移除
+	unstructured.RemoveNestedField(vs.Object, "spec", "tcp")
+	unstructured.RemoveNestedField(vs.Object, "spec", "tls")
 
 	// 添加暂停标签
 	labels := vs.GetLabels()
@@ -185,7 +286,109 @@ func (v *virtualServiceController) Suspend(ctx context.Context, name, namespace
 	labels["network.sealos.io/suspended"] = "true"
 	vs.SetLabels(labels)
 
-	return v.client.Update(ctx, vs)
+	return virtualServiceError(namespace, name, v.client.Update(ctx, vs))
+}
+
+// buildFaultPercentage 生成 Istio 的 Percent，value 为 double，支持 0.1 这样的小数比例（灰度测试）
+func buildFaultPercentage(value float64) map[string]interface{} {
+	return map[string]interface{}{
+		"value": value,
+	}
+}
+
+// buildAbortFault 生成按比例直接返回 httpStatus 的中断故障
+func buildAbortFault(percentage float64, httpStatus int32) map[string]interface{} {
+	return map[string]interface{}{
+		"abort": map[string]interface{}{
+			"percentage": buildFaultPercentage(percentage),
+			"httpStatus": int64(httpStatus),
+		},
+	}
+}
+
+// validRetryOnConditions Envoy 支持的 HTTP 和 gRPC 重试条件
+var validRetryOnConditions = map[string]bool{
+	"5xx": true, "gateway-error": true, "reset": true, "reset-before-request": true, "connect-failure": true,
+	"envoy-ratelimited": true, "retriable-4xx": true, "refused-stream": true, "retriable-status-codes": true,
+	"retriable-headers": true, "http3-post-connect-failure": true,
+	"cancelled": true, "deadline-exceeded": true, "internal": true, "resource-exhausted": true, "unavailable": true,
+}
+
+// buildRetryOn 拼接重试条件和状态码，例如 5xx,reset,connect-failure,409
+func buildRetryOn(policy *RetryPolicy) string {
+	parts := make([]string, 0, len(policy.RetryOn)+len(policy.RetriableStatusCodes))
+	parts = append(parts, policy.RetryOn...)
+	for _, code := range policy.RetriableStatusCodes {
+		parts = append(parts, strconv.Itoa(code))
+	}
+	return strings.Join(parts, ",")
+}
+
+// validateRetryPolicy 校验重试条件是 Envoy 支持的条件，状态码在 100-599 之间
+func validateRetryPolicy(policy *RetryPolicy) error {
+	if policy == nil {
+		return nil
+	}
+	for i, condition := range policy.RetryOn {
+		if !validRetryOnConditions[condition] {
+			return fmt.Errorf("retries.retryOn[%d] %q is not a supported retry condition", i, condition)
+		}
+	}
+	for i, code := range policy.RetriableStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("retries.retriableStatusCodes[%d] %d must be between 100 and 599", i, code)
+		}
+	}
+	return nil
+}
+
+// buildFaultInjection 生成 Istio HTTPFaultInjection
+func buildFaultInjection(fault *FaultConfig) map[string]interface{} {
+	block := map[string]interface{}{}
+	if fault.Delay != nil {
+		block["delay"] = map[string]interface{}{
+			"percentage": buildFaultPercentage(fault.Delay.Percentage),
+			"fixedDelay": formatProtoDuration(fault.Delay.FixedDelay),
+		}
+	}
+	if fault.Abort != nil {
+		for key, value := range buildAbortFault(fault.Abort.Percentage, fault.Abort.HTTPStatus) {
+			block[key] = value
+		}
+	}
+	return block
+}
+
+// formatProtoDuration 按 protobuf Duration 的 JSON 格式（秒数加 s 后缀）输出
+func formatProtoDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// validateFaultInjection 校验故障注入比例在 0-100 之间，且至少配置了延迟或中断
+func validateFaultInjection(fault *FaultConfig) error {
+	if fault == nil {
+		return nil
+	}
+	if fault.Delay == nil && fault.Abort == nil {
+		return fmt.Errorf("fault injection requires delay or abort")
+	}
+	if fault.Delay != nil {
+		if fault.Delay.Percentage < 0 || fault.Delay.Percentage > 100 {
+			return fmt.Errorf("fault delay percentage %v must be between 0 and 100", fault.Delay.Percentage)
+		}
+		if fault.Delay.FixedDelay <= 0 {
+			return fmt.Errorf("fault delay requires a positive fixed delay")
+		}
+	}
+	if fault.Abort != nil {
+		if fault.Abort.Percentage < 0 || fault.Abort.Percentage > 100 {
+			return fmt.Errorf("fault abort percentage %v must be between 0 and 100", fault.Abort.Percentage)
+		}
+		if fault.Abort.HTTPStatus < 200 || fault.Abort.HTTPStatus > 599 {
+			return fmt.Errorf("fault abort http status %d must be between 200 and 599", fault.Abort.HTTPStatus)
+		}
+	}
+	return nil
 }
 
 func (v *virtualServiceController) Resume(ctx context.Context, name, namespace string) error {
@@ -198,7 +401,24 @@ func (v *virtualServiceController) Resume(ctx context.Context, name, namespace s
 	}
 
 	if err := v.client.Get(ctx, key, vs); err != nil {
-		return fmt.Errorf("failed to get virtualservice: %w", err)
+		return fmt.Errorf("failed to get virtualservice: %w", virtualServiceError(namespace, name, err))
+	}
+
+	original, found, err := v.loadOriginalSpec(ctx, vs)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if vs.GetLabels()["network.sealos.io/suspended"] != "true" {
+			return nil
+		}
+		// 旧版本暂停时没有保存原始配置，只能重新创建
+		return fmt.Errorf("resume requires recreating virtualservice with original configuration")
+	}
+
+	cmName, err := restoreOriginalSpec(vs, original)
+	if err != nil {
+		return err
 	}
 
 	// 移除暂停标签
@@ -208,9 +428,159 @@ func (v *virtualServiceController) Resume(ctx context.Context, name, namespace s
 		vs.SetLabels(labels)
 	}
 
-	// 这里需要恢复原始的路由配置
-	// 由于我们没有存储原始配置，这里只能返回错误或要求重新创建
-	return fmt.Errorf("resume requires recreating virtualservice with original configuration")
+	if err := v.client.Update(ctx, vs); err != nil {
+		return fmt.Errorf("failed to resume virtualservice: %w", virtualServiceError(namespace, name, err))
+	}
+	if cmName != "" {
+		return v.deleteOriginalSpecConfigMap(ctx, namespace, cmName)
+	}
+	return nil
+}
+
+// validateVirtualServiceHosts 校验 hosts 非空且格式合法，避免提交后被 Istio 以难以排查的错误拒绝
+func validateVirtualServiceHosts(config *VirtualServiceConfig) error {
+	if len(config.Hosts) == 0 {
+		return fmt.Errorf("virtualservice %s/%s: hosts cannot be empty", config.Namespace, config.Name)
+	}
+
+	for i, host := range config.Hosts {
+		if strings.TrimSpace(host) == "" {
+			return fmt.Errorf("virtualservice %s/%s: hosts[%d] is blank", config.Namespace, config.Name, i)
+		}
+		if host != "*" && (len(host) > 253 || !virtualServiceHostRegex.MatchString(host)) {
+			return fmt.Errorf("virtualservice %s/%s: hosts[%d] %q is not a valid host", config.Namespace, config.Name, i, host)
+		}
+	}
+	return nil
+}
+
+// validateDestinations 校验按权重分流的路由目标，权重不能为负且总和必须为 100
+func validateDestinations(config *VirtualServiceConfig) error {
+	if len(config.Destinations) == 0 {
+		return nil
+	}
+
+	var total int32
+	for i, dest := range config.Destinations {
+		if dest.Weight < 0 {
+			return fmt.Errorf("virtualservice %s/%s: destinations[%d] weight %d must not be negative", config.Namespace, config.Name, i, dest.Weight)
+		}
+		total += dest.Weight
+	}
+	if total != 100 {
+		return fmt.Errorf("virtualservice %s/%s: destination weights sum to %d, want 100", config.Namespace, config.Name, total)
+	}
+	return nil
+}
+
+// validateL4Routes 校验 TCP/TLS 路由的端口和目标服务，TLS 路由必须有可匹配的 SNI
+func validateL4Routes(config *VirtualServiceConfig) error {
+	for i, route := range config.TCPRoutes {
+		if err := validateL4Route(config, route.Port, route.ServiceName); err != nil {
+			return fmt.Errorf("virtualservice %s/%s: tcpRoutes[%d] %w", config.Namespace, config.Name, i, err)
+		}
+	}
+	for i, route := range config.TLSRoutes {
+		if err := validateL4Route(config, route.Port, route.ServiceName); err != nil {
+			return fmt.Errorf("virtualservice %s/%s: tlsRoutes[%d] %w", config.Namespace, config.Name, i, err)
+		}
+		if len(route.SNIHosts) == 0 && len(config.Hosts) == 0 {
+			return fmt.Errorf("virtualservice %s/%s: tlsRoutes[%d] requires sni hosts", config.Namespace, config.Name, i)
+		}
+	}
+	return nil
+}
+
+func validateL4Route(config *VirtualServiceConfig, port int32, serviceName string) error {
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("port %d must be between 0 and 65535", port)
+	}
+	if serviceName == "" && config.ServiceName == "" {
+		return fmt.Errorf("requires a destination service")
+	}
+	return nil
+}
+
+// validRedirectCodes Istio 支持的重定向状态码
+var validRedirectCodes = map[int32]bool{301: true, 302: true, 303: true, 307: true, 308: true}
+
+// validateRouteAction 校验 rewrite 和 redirect 不能同时设置，且重定向状态码合法
+func validateRouteAction(rewrite *URIRewrite, redirect *HTTPRedirect) error {
+	if redirect == nil {
+		if rewrite != nil && rewrite.URI == "" && rewrite.Authority == "" {
+			return fmt.Errorf("rewrite requires uri or authority")
+		}
+		return nil
+	}
+	if rewrite != nil {
+		return fmt.Errorf("rewrite and redirect cannot both be set")
+	}
+	if redirect.URI == "" && redirect.Authority == "" {
+		return fmt.Errorf("redirect requires uri or authority")
+	}
+	if redirect.RedirectCode != 0 && !validRedirectCodes[redirect.RedirectCode] {
+		return fmt.Errorf("invalid redirect code %d", redirect.RedirectCode)
+	}
+	return nil
+}
+
+// validateHTTPRoutes 校验额外路由的匹配规则；保持配置顺序时拒绝被前面路由遮蔽的路由
+func validateHTTPRoutes(config *VirtualServiceConfig) error {
+	if err := validateRouteAction(config.Rewrite, config.Redirect); err != nil {
+		return fmt.Errorf("virtualservice %s/%s: %w", config.Namespace, config.Name, err)
+	}
+	if err := validateFaultInjection(config.FaultInjection); err != nil {
+		return fmt.Errorf("virtualservice %s/%s: %w", config.Namespace, config.Name, err)
+	}
+	if err := validateRetryPolicy(config.Retries); err != nil {
+		return fmt.Errorf("virtualservice %s/%s: %w", config.Namespace, config.Name, err)
+	}
+	for i, route := range config.Routes {
+		if err := validateRouteAction(route.Rewrite, route.Redirect); err != nil {
+			return fmt.Errorf("virtualservice %s/%s: routes[%d] %w", config.Namespace, config.Name, i, err)
+		}
+		match := route.Match
+		if (match.Exact == "") == (match.Prefix == "") {
+			return fmt.Errorf("virtualservice %s/%s: routes[%d] requires exactly one of exact or prefix match", config.Namespace, config.Name, i)
+		}
+		if path := match.Exact + match.Prefix; !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("virtualservice %s/%s: routes[%d] path %q must start with /", config.Namespace, config.Name, i, path)
+		}
+	}
+	if !config.PreserveRouteOrder {
+		return nil
+	}
+
+	for j := range config.Routes {
+		for i := 0; i < j; i++ {
+			if routeMatchShadows(config.Routes[i].Match, config.Routes[j].Match) {
+				return fmt.Errorf("virtualservice %s/%s: routes[%d] is shadowed by routes[%d]", config.Namespace, config.Name, j, i)
+			}
+		}
+	}
+	return nil
+}
+
+// routeMatchShadows 判断 earlier 是否匹配 later 能匹配的所有请求
+func routeMatchShadows(earlier, later RouteMatch) bool {
+	if earlier.Exact != "" {
+		return later.Exact == earlier.Exact
+	}
+	return strings.HasPrefix(later.Exact+later.Prefix, earlier.Prefix)
+}
+
+// sortHTTPRoutes 按匹配精确度排序：精确匹配优先，前缀匹配按前缀长度从长到短，相同精确度保持原顺序
+func sortHTTPRoutes(routes []HTTPRoute) []HTTPRoute {
+	sorted := make([]HTTPRoute, len(routes))
+	copy(sorted, routes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Match, sorted[j].Match
+		if (a.Exact != "") != (b.Exact != "") {
+			return a.Exact != ""
+		}
+		return len(a.Prefix) > len(b.Prefix)
+	})
+	return sorted
 }
 
 // buildVirtualServiceSpec 构建 VirtualService 规范
@@ -220,6 +590,23 @@ func (v *virtualServiceController) buildVirtualServiceSpec(config *VirtualServic
 		"gateways": stringSliceToInterface(config.Gateways),
 	}
 
+	// 配置了四层路由时只生成 tcp/tls 路由
+	if len(config.TCPRoutes) > 0 || len(config.TLSRoutes) > 0 {
+		if len(config.TCPRoutes) > 0 {
+			spec["tcp"] = v.buildTCPRoutes(config)
+		}
+		if len(config.TLSRoutes) > 0 {
+			spec["tls"] = v.buildTLSRoutes(config)
+		}
+		return spec
+	}
+
+	// TCP 协议只生成按端口匹配的 tcp 路由
+	if config.Protocol == ProtocolTCP {
+		spec["tcp"] = v.buildTCPRoutes(config)
+		return spec
+	}
+
 	// 构建 HTTP 路由
 	httpRoutes := v.buildHTTPRoutes(config)
 	if len(httpRoutes) > 0 {
@@ -229,30 +616,75 @@ func (v *virtualServiceController) buildVirtualServiceSpec(config *VirtualServic
 	return spec
 }
 
-// buildHTTPRoutes 构建 HTTP 路由
+// buildHTTPRoutes 构建 HTTP 路由，额外路由在前，默认 "/" 路由兜底排在最后（Istio 自上而下匹配）
 func (v *virtualServiceController) buildHTTPRoutes(config *VirtualServiceConfig) []interface{} {
 	routes := []interface{}{}
 
+	extraRoutes := config.Routes
+	if !config.PreserveRouteOrder {
+		extraRoutes = sortHTTPRoutes(extraRoutes)
+	}
+	for _, extra := range extraRoutes {
+		uri := map[string]interface{}{}
+		if extra.Match.Exact != "" {
+			uri["exact"] = extra.Match.Exact
+		} else {
+			uri["prefix"] = extra.Match.Prefix
+		}
+		destinations := buildServiceDestination(config, extra.ServiceName, extra.ServicePort)
+		route := v.buildHTTPRoute(config, map[string]interface{}{"uri": uri}, destinations)
+		routes = append(routes, withRouteAction(route, extra.Rewrite, extra.Redirect))
+	}
+
+	route := v.buildHTTPRoute(config, v.buildMatch(config), v.buildRouteDestinations(config))
+	routes = append(routes, withRouteAction(route, config.Rewrite, config.Redirect))
+	return routes
+}
+
+// withRouteAction 为路由附加 rewrite 或 redirect；重定向的路由不能再带转发目标
+func withRouteAction(route map[string]interface{}, rewrite *URIRewrite, redirect *HTTPRedirect) map[string]interface{} {
+	if redirect != nil {
+		delete(route, "route")
+		block := map[string]interface{}{}
+		if redirect.URI != "" {
+			block["uri"] = redirect.URI
+		}
+		if redirect.Authority != "" {
+			block["authority"] = redirect.Authority
+		}
+		if redirect.RedirectCode != 0 {
+			block["redirectCode"] = int64(redirect.RedirectCode)
+		}
+		route["redirect"] = block
+		return route
+	}
+
+	if rewrite != nil {
+		block := map[string]interface{}{}
+		if rewrite.URI != "" {
+			block["uri"] = rewrite.URI
+		}
+		if rewrite.Authority != "" {
+			block["authority"] = rewrite.Authority
+		}
+		route["rewrite"] = block
+	}
+	return route
+}
+
+// buildHTTPRoute 构建单条 HTTP 路由，附加超时、重试、CORS 和头部配置
+func (v *virtualServiceController) buildHTTPRoute(config *VirtualServiceConfig, match map[string]interface{}, destinations []interface{}) map[string]interface{} {
 	// 基础路由配置
 	route := map[string]interface{}{
 		"match": []interface{}{
-			v.buildMatch(config),
-		},
-		"route": []interface{}{
-			map[string]interface{}{
-				"destination": map[string]interface{}{
-					"host": config.ServiceName,
-					"port": map[string]interface{}{
-						"number": int64(config.ServicePort),
-					},
-				},
-			},
+			match,
 		},
+		"route": destinations,
 	}
 
 	// 添加超时配置
 	if config.Timeout != nil {
-		route["timeout"] = config.Timeout.String()
+		route["timeout"] = formatProtoDuration(*config.Timeout)
 	}
 
 	// 添加重试配置
@@ -261,41 +693,234 @@ func (v *virtualServiceController) buildHTTPRoutes(config *VirtualServiceConfig)
 			"attempts": int64(config.Retries.Attempts),
 		}
 		if config.Retries.PerTryTimeout != nil {
-			retries["perTryTimeout"] = config.Retries.PerTryTimeout.String()
+			retries["perTryTimeout"] = formatProtoDuration(*config.Retries.PerTryTimeout)
+		}
+		if retryOn := buildRetryOn(config.Retries); retryOn != "" {
+			retries["retryOn"] = retryOn
 		}
 		route["retries"] = retries
 	}
 
+	// 添加故障注入
+	if config.FaultInjection != nil {
+		route["fault"] = buildFaultInjection(config.FaultInjection)
+	}
+
 	// 添加 CORS 配置
 	if config.CorsPolicy != nil {
-		route["corsPolicy"] = v.buildCorsPolicy(config.CorsPolicy)
+		route["corsPolicy"] = v.buildCorsPolicy(v.withTraceCorsHeaders(config.CorsPolicy))
+	}
+
+	// 透传链路追踪头部时，不允许应用配置的请求头覆盖追踪上下文
+	requestHeaders := config.Headers
+	if v.config != nil && v.config.PreserveTraceHeaders {
+		requestHeaders = withoutHeaders(requestHeaders, v.traceHeaders())
+	}
+
+	// 注入可信转发头部，优先于应用配置的同名头部，防止伪造
+	if v.config != nil && v.config.InjectTrustedProxyHeaders {
+		requestHeaders = MergeLabels(requestHeaders, trustedProxyHeaders)
+	}
+
+	// 注入采样决策
+	if v.config != nil && v.config.TraceSamplingDecision != "" {
+		requestHeaders = MergeLabels(requestHeaders, map[string]string{TraceSampledHeader: v.config.TraceSamplingDecision})
 	}
 
 	// 添加头部配置（请求和响应）
-	if len(config.Headers) > 0 || len(config.ResponseHeaders) > 0 {
+	if len(requestHeaders) > 0 || len(config.ResponseHeaders) > 0 {
 		headers := map[string]interface{}{}
 
 		// 设置请求头部
-		if len(config.Headers) > 0 {
+		if len(requestHeaders) > 0 {
 			headers["request"] = map[string]interface{}{
-				"set": config.Headers,
+				"set": requestHeaders,
 			}
 		}
 
-		// 设置响应头部
+		// 设置响应头部，值为空的头部从上游响应中移除
 		if len(config.ResponseHeaders) > 0 {
-			headers["response"] = map[string]interface{}{
-				"set": config.ResponseHeaders,
+			setHeaders, removeHeaders := splitResponseHeaders(config.ResponseHeaders)
+			response := map[string]interface{}{}
+			if len(setHeaders) > 0 {
+				response["set"] = setHeaders
 			}
+			if len(removeHeaders) > 0 {
+				response["remove"] = removeHeaders
+			}
+			headers["response"] = response
 		}
 
 		route["headers"] = headers
 	}
 
-	routes = append(routes, route)
+	return route
+}
+
+// traceHeaders 返回需要透传的链路追踪头部
+func (v *virtualServiceController) traceHeaders() []string {
+	if len(v.config.TraceHeaders) > 0 {
+		return v.config.TraceHeaders
+	}
+	return DefaultTraceHeaders
+}
+
+// withTraceCorsHeaders 透传链路追踪头部时把追踪头部加入 CORS allowHeaders，避免浏览器预检拒绝带追踪头部的请求
+func (v *virtualServiceController) withTraceCorsHeaders(cors *CorsPolicy) *CorsPolicy {
+	if v.config == nil || !v.config.PreserveTraceHeaders {
+		return cors
+	}
+
+	result := *cors
+	result.AllowHeaders = append([]string{}, cors.AllowHeaders...)
+	for _, header := range v.traceHeaders() {
+		if !containsFold(result.AllowHeaders, header) {
+			result.AllowHeaders = append(result.AllowHeaders, header)
+		}
+	}
+	return &result
+}
+
+// withoutHeaders 返回移除了指定头部（不区分大小写）的头部副本
+func withoutHeaders(headers map[string]string, names []string) map[string]string {
+	if len(headers) == 0 {
+		return headers
+	}
+
+	result := make(map[string]string, len(headers))
+	for name, value := range headers {
+		if !containsFold(names, name) {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// containsFold 判断列表中是否包含指定字符串（不区分大小写）
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildTCPRoutes 构建 TCP 路由，匹配 Gateway 上暴露的端口并转发到后端服务
+func (v *virtualServiceController) buildTCPRoutes(config *VirtualServiceConfig) []interface{} {
+	if len(config.TCPRoutes) > 0 {
+		routes := make([]interface{}, 0, len(config.TCPRoutes))
+		for _, tcp := range config.TCPRoutes {
+			route := map[string]interface{}{
+				"route": buildServiceDestination(config, tcp.ServiceName, tcp.ServicePort),
+			}
+			if tcp.Port > 0 {
+				route["match"] = []interface{}{
+					map[string]interface{}{"port": int64(tcp.Port)},
+				}
+			}
+			routes = append(routes, route)
+		}
+		return routes
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"match": []interface{}{
+				map[string]interface{}{
+					"port": int64(config.TCPPort),
+				},
+			},
+			"route": v.buildRouteDestinations(config),
+		},
+	}
+}
+
+// buildTLSRoutes 构建按 SNI 匹配的 TLS 透传路由
+func (v *virtualServiceController) buildTLSRoutes(config *VirtualServiceConfig) []interface{} {
+	routes := make([]interface{}, 0, len(config.TLSRoutes))
+	for _, tls := range config.TLSRoutes {
+		sniHosts := tls.SNIHosts
+		if len(sniHosts) == 0 {
+			sniHosts = config.Hosts
+		}
+		match := map[string]interface{}{
+			"sniHosts": stringSliceToInterface(sniHosts),
+		}
+		if tls.Port > 0 {
+			match["port"] = int64(tls.Port)
+		}
+		routes = append(routes, map[string]interface{}{
+			"match": []interface{}{match},
+			"route": buildServiceDestination(config, tls.ServiceName, tls.ServicePort),
+		})
+	}
 	return routes
 }
 
+// buildServiceDestination 构建单个目标服务，名称或端口为空时使用 VirtualServiceConfig 中的默认值
+func buildServiceDestination(config *VirtualServiceConfig, serviceName string, servicePort int32) []interface{} {
+	if serviceName == "" {
+		serviceName = config.ServiceName
+	}
+	if servicePort == 0 {
+		servicePort = config.ServicePort
+	}
+	return []interface{}{
+		map[string]interface{}{
+			"destination": map[string]interface{}{
+				"host": serviceName,
+				"port": map[string]interface{}{
+					"number": int64(servicePort),
+				},
+			},
+		},
+	}
+}
+
+// buildRouteDestinations 构建路由目标，配置了 Destinations 时按权重分流到各目标（可指定子集）
+func (v *virtualServiceController) buildRouteDestinations(config *VirtualServiceConfig) []interface{} {
+	if len(config.Destinations) == 0 {
+		return []interface{}{
+			map[string]interface{}{
+				"destination": map[string]interface{}{
+					"host": config.ServiceName,
+					"port": map[string]interface{}{
+						"number": int64(config.ServicePort),
+					},
+				},
+			},
+		}
+	}
+
+	destinations := make([]interface{}, 0, len(config.Destinations))
+	for _, dest := range config.Destinations {
+		host := dest.Host
+		if host == "" {
+			host = config.ServiceName
+		}
+		port := dest.Port
+		if port == 0 {
+			port = config.ServicePort
+		}
+
+		destination := map[string]interface{}{
+			"host": host,
+			"port": map[string]interface{}{
+				"number": int64(port),
+			},
+		}
+		if dest.Subset != "" {
+			destination["subset"] = dest.Subset
+		}
+		destinations = append(destinations, map[string]interface{}{
+			"destination": destination,
+			"weight":      int64(dest.Weight),
+		})
+	}
+	return destinations
+}
+
 // buildMatch 构建匹配规则
 func (v *virtualServiceController) buildMatch(config *VirtualServiceConfig) map[string]interface{} {
 	match := map[string]interface{}{
@@ -318,6 +943,10 @@ func (v *virtualServiceController) buildCorsPolicy(cors *CorsPolicy) map[string]
 				origins = append(origins, map[string]interface{}{
 					"regex": ".*",
 				})
+			} else if strings.Contains(origin, "*") {
+				origins = append(origins, map[string]interface{}{
+					"regex": corsOriginRegex(origin),
+				})
 			} else {
 				origins = append(origins, map[string]interface{}{
 					"exact": origin,
@@ -338,12 +967,22 @@ func (v *virtualServiceController) buildCorsPolicy(cors *CorsPolicy) map[string]
 	policy["allowCredentials"] = cors.AllowCredentials
 
 	if cors.MaxAge != nil {
-		policy["maxAge"] = cors.MaxAge.String()
+		policy["maxAge"] = formatProtoDuration(*cors.MaxAge)
 	}
 
 	return policy
 }
 
+// corsOriginRegex 把带通配符的来源（如 https://*.cloud.sealos.io）转换为 Istio 的 regex 匹配，
+// 通配符只匹配一级子域名
+func corsOriginRegex(origin string) string {
+	parts := strings.Split(origin, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return "^" + strings.Join(parts, "[^.]+") + "$"
+}
+
 // parseVirtualService 解析 VirtualService 资源
 func (v *virtualServiceController) parseVirtualService(vs *unstructured.Unstructured) (*VirtualService, error) {
 	name := vs.GetName()
@@ -361,15 +1000,15 @@ func (v *virtualServiceController) parseVirtualService(vs *unstructured.Unstruct
 		return nil, err
 	}
 
-	// 获取服务信息
+	// 检查是否暂停
+	suspended := v.isSuspended(vs)
+
+	// 获取服务信息，暂停的 VirtualService 只有 abort 路由，没有目标服务
 	serviceName, servicePort, protocol, err := v.extractServiceInfo(vs)
-	if err != nil {
+	if err != nil && !suspended {
 		return nil, err
 	}
 
-	// 检查是否暂停
-	suspended := v.isSuspended(vs)
-
 	// 检查就绪状态
 	ready := v.isVirtualServiceReady(vs)
 
@@ -422,19 +1061,35 @@ func (v *virtualServiceController) extractGateways(vs *unstructured.Unstructured
 
 // extractServiceInfo 提取服务信息
 func (v *virtualServiceController) extractServiceInfo(vs *unstructured.Unstructured) (string, int32, Protocol, error) {
-	// 获取 HTTP 路由
-	httpRoutes, found, err := unstructured.NestedSlice(vs.Object, "spec", "http")
-	if err != nil || !found || len(httpRoutes) == 0 {
-		return "", 0, ProtocolHTTP, fmt.Errorf("no http routes found")
+	// 获取 HTTP 路由，没有时依次回退到 TCP、TLS 路由
+	var (
+		routes     []interface{}
+		l4Protocol Protocol
+		found      bool
+		err        error
+	)
+	for _, candidate := range []struct {
+		field    string
+		protocol Protocol
+	}{{"http", ""}, {"tcp", ProtocolTCP}, {"tls", ProtocolTLS}} {
+		routes, found, err = unstructured.NestedSlice(vs.Object, "spec", candidate.field)
+		if err == nil && found && len(routes) > 0 {
+			l4Protocol = candidate.protocol
+			break
+		}
+		routes = nil
+	}
+	if len(routes) == 0 {
+		return "", 0, ProtocolHTTP, fmt.Errorf("no http, tcp or tls routes found")
 	}
 
-	// 获取第一个路由的目标服务
-	firstRoute, ok := httpRoutes[0].(map[string]interface{})
+	// 获取默认路由（排在最后）的目标服务
+	defaultRoute, ok := routes[len(routes)-1].(map[string]interface{})
 	if !ok {
 		return "", 0, ProtocolHTTP, fmt.Errorf("invalid http route format")
 	}
 
-	routeSlice, found, err := unstructured.NestedSlice(firstRoute, "route")
+	routeSlice, found, err := unstructured.NestedSlice(defaultRoute, "route")
 	if err != nil || !found || len(routeSlice) == 0 {
 		return "", 0, ProtocolHTTP, fmt.Errorf("no route destinations found")
 	}
@@ -460,7 +1115,10 @@ func (v *virtualServiceController) extractServiceInfo(vs *unstructured.Unstructu
 	}
 
 	// 检测协议
-	protocol := v.detectProtocol(firstRoute)
+	protocol := v.detectProtocol(defaultRoute)
+	if l4Protocol != "" {
+		protocol = l4Protocol
+	}
 
 	return serviceName, int32(port), protocol, nil
 }
@@ -542,6 +1200,28 @@ func (v *virtualServiceController) isVirtualServiceReady(vs *unstructured.Unstru
 
 // CreateOrUpdateWithOwner 创建或更新 VirtualService（支持设置 OwnerReference）
 func (v *virtualServiceController) CreateOrUpdateWithOwner(ctx context.Context, config *VirtualServiceConfig, owner metav1.Object, scheme *runtime.Scheme) error {
+	if err := validateVirtualServiceHosts(config); err != nil {
+		return err
+	}
+	if err := validateHTTPRoutes(config); err != nil {
+		return err
+	}
+	if err := validateDestinations(config); err != nil {
+		return err
+	}
+	if err := validateL4Routes(config); err != nil {
+		return err
+	}
+	if err := validateResponseHeaders(v.config, config); err != nil {
+		return err
+	}
+
+	// 应用重命名时沿用旧 VirtualService 的域名，避免访问地址变化
+	config, renamed, err := v.adoptRenamedVirtualService(ctx, config)
+	if err != nil {
+		return err
+	}
+
 	vs := &unstructured.Unstructured{}
 	vs.SetGroupVersionKind(virtualServiceGVK)
 	vs.SetName(config.Name)
@@ -560,8 +1240,16 @@ func (v *virtualServiceController) CreateOrUpdateWithOwner(ctx context.Context,
 		labels["app.kubernetes.io/component"] = "networking"
 		vs.SetLabels(labels)
 
+		// 设置注解
+		if len(config.Annotations) > 0 {
+			vs.SetAnnotations(MergeLabels(vs.GetAnnotations(), config.Annotations))
+		}
+
 		// 构建并设置 spec
 		spec := v.buildVirtualServiceSpec(config)
+		if err := validateVirtualServiceSpec(spec); err != nil {
+			return fmt.Errorf("virtualservice %s/%s: %w", config.Namespace, config.Name, err)
+		}
 		// 确保所有值都可以深拷贝
 		safeSpec := makeSafeForDeepCopy(spec)
 		if err := unstructured.SetNestedMap(vs.Object, safeSpec.(map[string]interface{}), "spec"); err != nil {
@@ -577,7 +1265,7 @@ func (v *virtualServiceController) CreateOrUpdateWithOwner(ctx context.Context,
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to create or update virtualservice: %w", err)
+		return fmt.Errorf("failed to create or update virtualservice: %w", virtualServiceError(config.Namespace, config.Name, err))
 	}
 
 	if result == controllerutil.OperationResultCreated {
@@ -586,5 +1274,44 @@ func (v *virtualServiceController) CreateOrUpdateWithOwner(ctx context.Context,
 		// VirtualService 已更新
 	}
 
+	// 新 VirtualService 就绪后再删除旧名称的 VirtualService
+	if renamed {
+		if err := v.Delete(ctx, config.PreviousName, config.Namespace); err != nil {
+			return fmt.Errorf("failed to delete renamed virtualservice %s: %w", config.PreviousName, err)
+		}
+	}
+
 	return nil
 }
+
+// adoptRenamedVirtualService 查找重命名前的 VirtualService，存在时返回沿用其域名的配置。
+// 只迁移由 sealos-istio 管理的 VirtualService，避免误删同名的其他资源。
+func (v *virtualServiceController) adoptRenamedVirtualService(ctx context.Context, config *VirtualServiceConfig) (*VirtualServiceConfig, bool, error) {
+	if config.PreviousName == "" || config.PreviousName == config.Name {
+		return config, false, nil
+	}
+
+	previous := &unstructured.Unstructured{}
+	previous.SetGroupVersionKind(virtualServiceGVK)
+	key := types.NamespacedName{Name: config.PreviousName, Namespace: config.Namespace}
+	if err := v.client.Get(ctx, key, previous); err != nil {
+		if errors.IsNotFound(err) {
+			return config, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get renamed virtualservice %s: %w", config.PreviousName, err)
+	}
+	if previous.GetLabels()["app.kubernetes.io/managed-by"] != "sealos-istio" {
+		return config, false, nil
+	}
+
+	hosts, err := v.extractHosts(previous)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to extract hosts from renamed virtualservice %s: %w", config.PreviousName, err)
+	}
+
+	migrated := *config
+	if len(hosts) > 0 {
+		migrated.Hosts = hosts
+	}
+	return &migrated, true, nil
+}
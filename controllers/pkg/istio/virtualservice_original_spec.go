@@ -0,0 +1,162 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// 暂停 VirtualService 时保存原始路由的位置
+const (
+	// OriginalSpecAnnotation 暂停前的 spec.http/tcp/tls（JSON）
+	OriginalSpecAnnotation = "network.sealos.io/original-spec"
+	// OriginalSpecConfigMapAnnotation 原始路由过大时保存它的 ConfigMap 名称
+	OriginalSpecConfigMapAnnotation = "network.sealos.io/original-spec-configmap"
+
+	originalSpecConfigMapKey = "spec.json"
+	// maxOriginalSpecAnnotationSize 超过该大小时改用 ConfigMap 保存，避免超出注解总大小限制
+	maxOriginalSpecAnnotationSize = 200 * 1024 // 200KB
+)
+
+// originalSpecFields 暂停时会被改写、恢复时需要还原的路由字段
+var originalSpecFields = []string{"http", "tcp", "tls"}
+
+// originalSpecConfigMapName 保存 VirtualService 原始路由的 ConfigMap 名称
+func originalSpecConfigMapName(vsName string) string {
+	return fmt.Sprintf("%s-vs-original-spec", vsName)
+}
+
+// saveOriginalSpec 保存 VirtualService 当前的路由字段，较小时写入注解，否则写入同命名空间的 ConfigMap
+func (v *virtualServiceController) saveOriginalSpec(ctx context.Context, vs *unstructured.Unstructured) error {
+	original := make(map[string]interface{})
+	for _, field := range originalSpecFields {
+		if value, found, _ := unstructured.NestedFieldNoCopy(vs.Object, "spec", field); found {
+			original[field] = value
+		}
+	}
+	data, err := json.Marshal(original)
+	if err != nil {
+		return fmt.Errorf("failed to marshal original spec of virtualservice %s/%s: %w", vs.GetNamespace(), vs.GetName(), err)
+	}
+
+	annotations := vs.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if len(data) <= maxOriginalSpecAnnotationSize {
+		annotations[OriginalSpecAnnotation] = string(data)
+		delete(annotations, OriginalSpecConfigMapAnnotation)
+		vs.SetAnnotations(annotations)
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      originalSpecConfigMapName(vs.GetName()),
+			Namespace: vs.GetNamespace(),
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by":     "sealos-istio",
+				"network.sealos.io/virtualservice": vs.GetName(),
+			},
+		},
+		Data: map[string]string{originalSpecConfigMapKey: string(data)},
+	}
+	if err := v.client.Create(ctx, cm); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create original spec configmap %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+		// 上次暂停更新失败时 ConfigMap 可能已存在，覆盖为当前配置
+		existing := &corev1.ConfigMap{}
+		if err := v.client.Get(ctx, client.ObjectKeyFromObject(cm), existing); err != nil {
+			return fmt.Errorf("failed to get original spec configmap %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+		existing.Data = cm.Data
+		if err := v.client.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update original spec configmap %s/%s: %w", cm.Namespace, cm.Name, err)
+		}
+	}
+
+	delete(annotations, OriginalSpecAnnotation)
+	annotations[OriginalSpecConfigMapAnnotation] = cm.Name
+	vs.SetAnnotations(annotations)
+	return nil
+}
+
+// loadOriginalSpec 读取暂停时保存的路由字段，没有保存时返回 false
+func (v *virtualServiceController) loadOriginalSpec(ctx context.Context, vs *unstructured.Unstructured) (map[string]interface{}, bool, error) {
+	annotations := vs.GetAnnotations()
+	data, ok := annotations[OriginalSpecAnnotation]
+	if cmName := annotations[OriginalSpecConfigMapAnnotation]; !ok && cmName != "" {
+		cm := &corev1.ConfigMap{}
+		if err := v.client.Get(ctx, client.ObjectKey{Namespace: vs.GetNamespace(), Name: cmName}, cm); err != nil {
+			return nil, false, fmt.Errorf("failed to get original spec configmap %s/%s: %w", vs.GetNamespace(), cmName, err)
+		}
+		data, ok = cm.Data[originalSpecConfigMapKey]
+		if !ok {
+			return nil, false, fmt.Errorf("original spec configmap %s/%s has no %s", vs.GetNamespace(), cmName, originalSpecConfigMapKey)
+		}
+	}
+	if !ok {
+		return nil, false, nil
+	}
+
+	// 使用 apimachinery 的 JSON 解码，整数保持 int64，与 API Server 返回的对象一致
+	original := make(map[string]interface{})
+	if err := utiljson.Unmarshal([]byte(data), &original); err != nil {
+		return nil, false, fmt.Errorf("failed to decode original spec of virtualservice %s/%s: %w", vs.GetNamespace(), vs.GetName(), err)
+	}
+	return original, true, nil
+}
+
+// restoreOriginalSpec 用保存的路由字段覆盖暂停路由，并移除原始配置注解，返回需要清理的 ConfigMap 名称
+func restoreOriginalSpec(vs *unstructured.Unstructured, original map[string]interface{}) (string, error) {
+	for _, field := range originalSpecFields {
+		value, ok := original[field]
+		if !ok {
+			unstructured.RemoveNestedField(vs.Object, "spec", field)
+			continue
+		}
+		if err := unstructured.SetNestedField(vs.Object, value, "spec", field); err != nil {
+			return "", fmt.Errorf("failed to restore spec.%s of virtualservice %s/%s: %w", field, vs.GetNamespace(), vs.GetName(), err)
+		}
+	}
+
+	annotations := vs.GetAnnotations()
+	cmName := annotations[OriginalSpecConfigMapAnnotation]
+	delete(annotations, OriginalSpecAnnotation)
+	delete(annotations, OriginalSpecConfigMapAnnotation)
+	vs.SetAnnotations(annotations)
+	return cmName, nil
+}
+
+// deleteOriginalSpecConfigMap 恢复完成后删除保存原始路由的 ConfigMap
+func (v *virtualServiceController) deleteOriginalSpecConfigMap(ctx context.Context, namespace, name string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := client.IgnoreNotFound(v.client.Delete(ctx, cm)); err != nil {
+		return fmt.Errorf("failed to delete original spec configmap %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestRoutedVirtualService 创建带多条 HTTP 路由和 TCP 路由的 VirtualService，headerValue 用于控制配置大小
+func newTestRoutedVirtualService(name, namespace, headerValue string) *unstructured.Unstructured {
+	vs := newTestManagedVirtualService(name, namespace, "app.example.com")
+	route := func(prefix, host string, port int64) interface{} {
+		return map[string]interface{}{
+			"match": []interface{}{
+				map[string]interface{}{"uri": map[string]interface{}{"prefix": prefix}},
+			},
+			"route": []interface{}{
+				map[string]interface{}{
+					"destination": map[string]interface{}{
+						"host": host,
+						"port": map[string]interface{}{"number": port},
+					},
+					"weight": int64(100),
+				},
+			},
+			"headers": map[string]interface{}{
+				"request": map[string]interface{}{
+					"set": map[string]interface{}{"X-Test": headerValue},
+				},
+			},
+			"timeout": "30s",
+		}
+	}
+	_ = unstructured.SetNestedSlice(vs.Object, []interface{}{
+		route("/api", "api.ns-user1.svc.cluster.local", 8080),
+		route("/static", "static.ns-user1.svc.cluster.local", 80),
+		route("/", "web.ns-user1.svc.cluster.local", 3000),
+	}, "spec", "http")
+	_ = unstructured.SetNestedSlice(vs.Object, []interface{}{
+		map[string]interface{}{
+			"match": []interface{}{map[string]interface{}{"port": int64(5432)}},
+			"route": []interface{}{
+				map[string]interface{}{
+					"destination": map[string]interface{}{
+						"host": "db.ns-user1.svc.cluster.local",
+						"port": map[string]interface{}{"number": int64(5432)},
+					},
+				},
+			},
+		},
+	}, "spec", "tcp")
+	return vs
+}
+
+func TestVirtualServiceSuspendResume_RestoresOriginalSpec(t *testing.T) {
+	tests := []struct {
+		name          string
+		headerValue   string
+		wantConfigMap bool
+	}{
+		{name: "original spec in annotation", headerValue: "small"},
+		{name: "large original spec in configmap", headerValue: strings.Repeat("x", maxOriginalSpecAnnotationSize/2), wantConfigMap: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := newTestRoutedVirtualService("app-vs", "ns-user1", tt.headerValue)
+			wantSpec, err := json.Marshal(original.Object["spec"])
+			if err != nil {
+				t.Fatalf("marshal original spec: %v", err)
+			}
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(original).Build()
+			controller := NewVirtualServiceController(c, &NetworkConfig{})
+			ctx := context.Background()
+			cmKey := client.ObjectKey{Namespace: "ns-user1", Name: originalSpecConfigMapName("app-vs")}
+
+			if err := controller.Suspend(ctx, "app-vs", "ns-user1"); err != nil {
+				t.Fatalf("Suspend() error = %v", err)
+			}
+			// 重复暂停不应覆盖首次保存的原始路由
+			if err := controller.Suspend(ctx, "app-vs", "ns-user1"); err != nil {
+				t.Fatalf("second Suspend() error = %v", err)
+			}
+
+			suspended, _ := getTestVirtualService(t, c, "app-vs", "ns-user1")
+			if _, found, _ := unstructured.NestedSlice(suspended.Object, "spec", "tcp"); found {
+				t.Errorf("suspended virtualservice should have no tcp routes")
+			}
+			_, inAnnotation := suspended.GetAnnotations()[OriginalSpecAnnotation]
+			if inAnnotation == tt.wantConfigMap {
+				t.Errorf("original spec in annotation = %v, want %v", inAnnotation, !tt.wantConfigMap)
+			}
+			err = c.Get(ctx, cmKey, &corev1.ConfigMap{})
+			if tt.wantConfigMap && err != nil {
+				t.Errorf("original spec configmap should exist, get error = %v", err)
+			}
+
+			if err := controller.Resume(ctx, "app-vs", "ns-user1"); err != nil {
+				t.Fatalf("Resume() error = %v", err)
+			}
+
+			resumed, _ := getTestVirtualService(t, c, "app-vs", "ns-user1")
+			gotSpec, err := json.Marshal(resumed.Object["spec"])
+			if err != nil {
+				t.Fatalf("marshal resumed spec: %v", err)
+			}
+			if !bytes.Equal(gotSpec, wantSpec) {
+				t.Errorf("resumed spec = %s, want %s", truncate(gotSpec), truncate(wantSpec))
+			}
+			for _, key := range []string{OriginalSpecAnnotation, OriginalSpecConfigMapAnnotation} {
+				if _, ok := resumed.GetAnnotations()[key]; ok {
+					t.Errorf("annotation %s should be removed after resume", key)
+				}
+			}
+			if resumed.GetLabels()["network.sealos.io/suspended"] == "true" {
+				t.Errorf("suspended label should be removed after resume")
+			}
+			if err := c.Get(ctx, cmKey, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+				t.Errorf("original spec configmap should be deleted, get error = %v", err)
+			}
+		})
+	}
+}
+
+func TestVirtualServiceResume(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "not suspended is a no-op", labels: map[string]string{"app.kubernetes.io/managed-by": "sealos-istio"}},
+		{name: "suspended without original spec", labels: map[string]string{"network.sealos.io/suspended": "true"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := newTestManagedVirtualService("app-vs", "ns-user1", "app.example.com")
+			vs.SetLabels(tt.labels)
+			c := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(vs).Build()
+			controller := NewVirtualServiceController(c, &NetworkConfig{})
+
+			err := controller.Resume(context.Background(), "app-vs", "ns-user1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Resume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// truncate 截断过长的 spec，避免测试失败时输出过多内容
+func truncate(data []byte) string {
+	const max = 512
+	if len(data) <= max {
+		return string(data)
+	}
+	return string(data[:max]) + "..."
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// protoDurationRegex protobuf Duration 的 JSON 格式，只接受秒数加 s 后缀（如 30s、1.5s），不接受 1m30s
+var protoDurationRegex = regexp.MustCompile(`^\d+(\.\d{1,9})?s$`)
+
+// validateVirtualServiceSpec 在提交前按 Istio 的 schema 和语义校验生成的 spec，
+// 返回带字段路径的错误，避免 API Server 返回难以排查的错误
+func validateVirtualServiceSpec(spec map[string]interface{}) error {
+	hosts, _ := spec["hosts"].([]interface{})
+	if len(hosts) == 0 {
+		return fmt.Errorf("spec.hosts: required")
+	}
+	for i, host := range hosts {
+		if s, ok := host.(string); !ok || s == "" {
+			return fmt.Errorf("spec.hosts[%d]: must be a non-empty string", i)
+		}
+	}
+
+	gateways, _ := spec["gateways"].([]interface{})
+	for i, gateway := range gateways {
+		ref, _ := gateway.(string)
+		if err := validateGatewayRef(ref); err != nil {
+			return fmt.Errorf("spec.gateways[%d]: %w", i, err)
+		}
+	}
+
+	httpRoutes, _ := spec["http"].([]interface{})
+	tcpRoutes, _ := spec["tcp"].([]interface{})
+	tlsRoutes, _ := spec["tls"].([]interface{})
+	if len(httpRoutes)+len(tcpRoutes)+len(tlsRoutes) == 0 {
+		return fmt.Errorf("spec: at least one http, tcp or tls route is required")
+	}
+
+	for i, item := range httpRoutes {
+		if err := validateHTTPRouteSpec(item); err != nil {
+			return fmt.Errorf("spec.http[%d].%w", i, err)
+		}
+	}
+	for i, item := range tcpRoutes {
+		if err := validateL4RouteSpec(item); err != nil {
+			return fmt.Errorf("spec.tcp[%d].%w", i, err)
+		}
+	}
+	for i, item := range tlsRoutes {
+		if err := validateL4RouteSpec(item); err != nil {
+			return fmt.Errorf("spec.tls[%d].%w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateGatewayRef 校验 Gateway 引用，格式为 mesh、<name> 或 <namespace>/<name>
+func validateGatewayRef(ref string) error {
+	if ref == "mesh" {
+		return nil
+	}
+	name := ref
+	if namespace, gatewayName, found := strings.Cut(ref, "/"); found {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("gateway %q has an invalid namespace: %s", ref, strings.Join(errs, "; "))
+		}
+		name = gatewayName
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("gateway %q has an invalid name, want mesh, <name> or <namespace>/<name>: %s", ref, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func validateHTTPRouteSpec(item interface{}) error {
+	route, ok := item.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("route: must be an object")
+	}
+	_, hasRedirect := route["redirect"]
+	destinations, _ := route["route"].([]interface{})
+	if len(destinations) == 0 && !hasRedirect {
+		return fmt.Errorf("route: required unless redirect is set")
+	}
+	if err := validateDestinationsSpec(destinations); err != nil {
+		return err
+	}
+
+	if err := validateDurationField(route, "timeout"); err != nil {
+		return err
+	}
+	if retries, ok := route["retries"].(map[string]interface{}); ok {
+		if err := validateDurationField(retries, "perTryTimeout"); err != nil {
+			return fmt.Errorf("retries.%w", err)
+		}
+	}
+	if fault, ok := route["fault"].(map[string]interface{}); ok {
+		if delay, ok := fault["delay"].(map[string]interface{}); ok {
+			if err := validateDurationField(delay, "fixedDelay"); err != nil {
+				return fmt.Errorf("fault.delay.%w", err)
+			}
+		}
+	}
+	if cors, ok := route["corsPolicy"].(map[string]interface{}); ok {
+		if err := validateDurationField(cors, "maxAge"); err != nil {
+			return fmt.Errorf("corsPolicy.%w", err)
+		}
+	}
+	return nil
+}
+
+func validateL4RouteSpec(item interface{}) error {
+	route, ok := item.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("route: must be an object")
+	}
+	matches, _ := route["match"].([]interface{})
+	for i, m := range matches {
+		match, _ := m.(map[string]interface{})
+		if port, ok := match["port"]; ok {
+			if number, ok := port.(int64); !ok || number < 1 || number > 65535 {
+				return fmt.Errorf("match[%d].port: %v must be between 1 and 65535", i, port)
+			}
+		}
+	}
+	destinations, _ := route["route"].([]interface{})
+	if len(destinations) == 0 {
+		return fmt.Errorf("route: required")
+	}
+	return validateDestinationsSpec(destinations)
+}
+
+func validateDestinationsSpec(destinations []interface{}) error {
+	for i, d := range destinations {
+		item, _ := d.(map[string]interface{})
+		destination, _ := item["destination"].(map[string]interface{})
+		if host, _ := destination["host"].(string); host == "" {
+			return fmt.Errorf("route[%d].destination.host: required", i)
+		}
+		if port, ok := destination["port"].(map[string]interface{}); ok {
+			if number, ok := port["number"].(int64); !ok || number < 1 || number > 65535 {
+				return fmt.Errorf("route[%d].destination.port.number: %v must be between 1 and 65535", i, port["number"])
+			}
+		}
+		if weight, ok := item["weight"]; ok {
+			if number, ok := weight.(int64); !ok || number < 0 || number > 100 {
+				return fmt.Errorf("route[%d].weight: %v must be between 0 and 100", i, weight)
+			}
+		}
+	}
+	return nil
+}
+
+// validateDurationField 校验可选的 Duration 字段
+func validateDurationField(obj map[string]interface{}, field string) error {
+	value, ok := obj[field]
+	if !ok {
+		return nil
+	}
+	if s, _ := value.(string); !protoDurationRegex.MatchString(s) {
+		return fmt.Errorf("%s: %v is not a valid duration, want seconds such as \"30s\" or \"1.5s\"", field, value)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestSchemaConfig() *VirtualServiceConfig {
+	timeout := 90 * time.Second
+	return &VirtualServiceConfig{
+		Name:        "app-vs",
+		Namespace:   "ns-user1",
+		Hosts:       []string{"app.example.com"},
+		Gateways:    []string{"istio-system/sealos-gateway", "app-gateway", "mesh"},
+		ServiceName: "app",
+		ServicePort: 8080,
+		Timeout:     &timeout,
+	}
+}
+
+func TestValidateVirtualServiceSpec(t *testing.T) {
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	tests := []struct {
+		name      string
+		mutate    func(spec map[string]interface{})
+		wantField string
+	}{
+		{name: "valid spec", mutate: func(map[string]interface{}) {}},
+		{
+			name: "bad duration",
+			mutate: func(spec map[string]interface{}) {
+				spec["http"].([]interface{})[0].(map[string]interface{})["timeout"] = "1m30s"
+			},
+			wantField: "spec.http[0].timeout",
+		},
+		{
+			name: "bad cors max age",
+			mutate: func(spec map[string]interface{}) {
+				spec["http"].([]interface{})[0].(map[string]interface{})["corsPolicy"] = map[string]interface{}{"maxAge": "1h0m0s"}
+			},
+			wantField: "spec.http[0].corsPolicy.maxAge",
+		},
+		{
+			name: "bad gateway ref",
+			mutate: func(spec map[string]interface{}) {
+				spec["gateways"] = []interface{}{"istio-system/sealos_gateway"}
+			},
+			wantField: "spec.gateways[0]",
+		},
+		{
+			name: "gateway ref with too many segments",
+			mutate: func(spec map[string]interface{}) {
+				spec["gateways"] = []interface{}{"istio-system/sealos-gateway/extra"}
+			},
+			wantField: "spec.gateways[0]",
+		},
+		{
+			name: "destination port out of range",
+			mutate: func(spec map[string]interface{}) {
+				route := spec["http"].([]interface{})[0].(map[string]interface{})
+				destination := route["route"].([]interface{})[0].(map[string]interface{})["destination"].(map[string]interface{})
+				destination["port"] = map[string]interface{}{"number": int64(70000)}
+			},
+			wantField: "spec.http[0].route[0].destination.port.number",
+		},
+		{
+			name: "missing destination host",
+			mutate: func(spec map[string]interface{}) {
+				route := spec["http"].([]interface{})[0].(map[string]interface{})
+				delete(route["route"].([]interface{})[0].(map[string]interface{})["destination"].(map[string]interface{}), "host")
+			},
+			wantField: "spec.http[0].route[0].destination.host",
+		},
+		{
+			name:      "missing hosts",
+			mutate:    func(spec map[string]interface{}) { delete(spec, "hosts") },
+			wantField: "spec.hosts",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := controller.buildVirtualServiceSpec(newTestSchemaConfig())
+			tt.mutate(spec)
+			err := validateVirtualServiceSpec(spec)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("validateVirtualServiceSpec() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantField+":") {
+				t.Errorf("validateVirtualServiceSpec() error = %v, want an error for %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestVirtualServiceController_CreateValidatesSpec(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewVirtualServiceController(c, &NetworkConfig{})
+	ctx := context.Background()
+
+	bad := newTestSchemaConfig()
+	bad.Gateways = []string{"Sealos Gateway"}
+	err := controller.Create(ctx, bad)
+	if err == nil || !strings.Contains(err.Error(), "spec.gateways[0]") {
+		t.Fatalf("Create() error = %v, want a spec.gateways[0] error", err)
+	}
+
+	// 超过一分钟的超时按秒数输出，能通过校验
+	if err := controller.Create(ctx, newTestSchemaConfig()); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	vs, found := getTestVirtualService(t, c, "app-vs", "ns-user1")
+	if !found {
+		t.Fatalf("virtualservice was not created")
+	}
+	http, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if got := http[0].(map[string]interface{})["timeout"]; got != "90s" {
+		t.Errorf("timeout = %v, want 90s", got)
+	}
+}
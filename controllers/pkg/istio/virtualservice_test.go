@@ -18,12 +18,18 @@ package istio
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -178,14 +184,7 @@ func TestVirtualServiceSuspendSpec(t *testing.T) {
 					},
 				},
 			},
-			"fault": map[string]interface{}{
-				"abort": map[string]interface{}{
-					"percentage": map[string]interface{}{
-						"value": int64(100),
-					},
-					"httpStatus": int64(503),
-				},
-			},
+			"fault": buildAbortFault(100, 503),
 		},
 	}
 	
@@ -208,8 +207,8 @@ func TestVirtualServiceSuspendSpec(t *testing.T) {
 	abort := fault["abort"].(map[string]interface{})
 	percentage := abort["percentage"].(map[string]interface{})
 	
-	if value := percentage["value"]; value != int64(100) {
-		t.Errorf("Percentage value is not int64(100): got %v (%T)", value, value)
+	if value := percentage["value"]; value != float64(100) {
+		t.Errorf("Percentage value is not float64(100): got %v (%T)", value, value)
 	}
 	
 	if status := abort["httpStatus"]; status != int64(503) {
@@ -246,4 +245,969 @@ func TestBuildCorsPolicy(t *testing.T) {
 	if wildcardOrigin["regex"] != ".*" {
 		t.Errorf("Wildcard origin should have regex: .*, got %v", wildcardOrigin["regex"])
 	}
-}
\ No newline at end of file
+
+	// Check maxAge uses the protobuf Duration format
+	if maxAge := policy["maxAge"]; maxAge != "300s" {
+		t.Errorf("maxAge should be 300s, got %v", maxAge)
+	}
+}
+func newTestManagedVirtualService(name, namespace string, hosts ...string) *unstructured.Unstructured {
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(virtualServiceGVK)
+	vs.SetName(name)
+	vs.SetNamespace(namespace)
+	vs.SetLabels(map[string]string{"app.kubernetes.io/managed-by": "sealos-istio"})
+	hostList := make([]interface{}, 0, len(hosts))
+	for _, host := range hosts {
+		hostList = append(hostList, host)
+	}
+	_ = unstructured.SetNestedSlice(vs.Object, hostList, "spec", "hosts")
+	return vs
+}
+
+func getTestVirtualService(t *testing.T, c client.Client, name, namespace string) (*unstructured.Unstructured, bool) {
+	t.Helper()
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(virtualServiceGVK)
+	err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: namespace}, vs)
+	if errors.IsNotFound(err) {
+		return nil, false
+	}
+	if err != nil {
+		t.Fatalf("get virtualservice %s: %v", name, err)
+	}
+	return vs, true
+}
+
+func TestVirtualServiceControllerList(t *testing.T) {
+	unmanaged := newTestRoutedVirtualService("user-vs", "ns-user1", "")
+	unmanaged.SetLabels(map[string]string{"app": "user"})
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(
+		newTestRoutedVirtualService("app-vs", "ns-user1", ""),
+		newTestRoutedVirtualService("suspended-vs", "ns-user1", ""),
+		unmanaged,
+		newTestRoutedVirtualService("other-vs", "ns-user2", ""),
+	).Build()
+	controller := NewVirtualServiceController(c, &NetworkConfig{})
+	// 暂停后只剩 abort 路由，仍应能被列出
+	if err := controller.Suspend(context.Background(), "suspended-vs", "ns-user1"); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      []client.ListOption
+		wantNames []string
+	}{
+		{name: "managed only by default", wantNames: []string{"app-vs", "suspended-vs"}},
+		{
+			name:      "custom labels override default",
+			opts:      []client.ListOption{client.MatchingLabels{"network.sealos.io/suspended": "true"}},
+			wantNames: []string{"suspended-vs"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := controller.List(context.Background(), "ns-user1", tt.opts...)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			names := make([]string, 0, len(list))
+			for _, vs := range list {
+				names = append(names, vs.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("List() names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestVirtualServiceCreateOrUpdateWithOwner_Rename(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      []client.Object
+		previousName  string
+		wantHosts     []string
+		wantOldExists bool
+	}{
+		{
+			name:         "rename preserves host and removes old virtualservice",
+			existing:     []client.Object{newTestManagedVirtualService("old-app-vs", "ns-user1", "abcdefgh.cloud.sealos.io")},
+			previousName: "old-app-vs",
+			wantHosts:    []string{"abcdefgh.cloud.sealos.io"},
+		},
+		{
+			name:         "old virtualservice already migrated",
+			previousName: "old-app-vs",
+			wantHosts:    []string{"newhost.cloud.sealos.io"},
+		},
+		{
+			name: "unmanaged virtualservice is left alone",
+			existing: []client.Object{func() client.Object {
+				vs := newTestManagedVirtualService("old-app-vs", "ns-user1", "abcdefgh.cloud.sealos.io")
+				vs.SetLabels(nil)
+				return vs
+			}()},
+			previousName:  "old-app-vs",
+			wantHosts:     []string{"newhost.cloud.sealos.io"},
+			wantOldExists: true,
+		},
+		{
+			name:          "no previous name",
+			existing:      []client.Object{newTestManagedVirtualService("old-app-vs", "ns-user1", "abcdefgh.cloud.sealos.io")},
+			wantHosts:     []string{"newhost.cloud.sealos.io"},
+			wantOldExists: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(tt.existing...).Build()
+			controller := NewVirtualServiceController(c, &NetworkConfig{})
+
+			vsConfig := &VirtualServiceConfig{
+				Name:         "new-app-vs",
+				Namespace:    "ns-user1",
+				Hosts:        []string{"newhost.cloud.sealos.io"},
+				Gateways:     []string{"istio-system/sealos-gateway"},
+				Protocol:     ProtocolHTTP,
+				ServiceName:  "new-app",
+				ServicePort:  8080,
+				PreviousName: tt.previousName,
+			}
+			if err := controller.CreateOrUpdateWithOwner(context.Background(), vsConfig, nil, nil); err != nil {
+				t.Fatalf("CreateOrUpdateWithOwner() error = %v", err)
+			}
+
+			vs, ok := getTestVirtualService(t, c, "new-app-vs", "ns-user1")
+			if !ok {
+				t.Fatal("new virtualservice not created")
+			}
+			hosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts")
+			if strings.Join(hosts, ",") != strings.Join(tt.wantHosts, ",") {
+				t.Errorf("hosts = %v, want %v", hosts, tt.wantHosts)
+			}
+			if _, exists := getTestVirtualService(t, c, "old-app-vs", "ns-user1"); exists != tt.wantOldExists {
+				t.Errorf("old virtualservice exists = %v, want %v", exists, tt.wantOldExists)
+			}
+		})
+	}
+}
+
+func TestDomainClassifier_BuildOptimizedVirtualServiceConfig_PreviousName(t *testing.T) {
+	classifier := NewDomainClassifier(&NetworkConfig{BaseDomain: "cloud.sealos.io"})
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{name: "renamed", annotations: map[string]string{PreviousNameAnnotation: "old-app"}, want: "old-app-vs"},
+		{name: "same name", annotations: map[string]string{PreviousNameAnnotation: "new-app"}, want: ""},
+		{name: "no annotation", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &AppNetworkingSpec{
+				Name:        "new-app",
+				Namespace:   "ns-user1",
+				Hosts:       []string{"abc.cloud.sealos.io"},
+				Annotations: tt.annotations,
+			}
+			if got := classifier.BuildOptimizedVirtualServiceConfig(spec).PreviousName; got != tt.want {
+				t.Errorf("PreviousName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_TrustedProxyHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		inject      bool
+		headers     map[string]string
+		wantHeaders map[string]string
+	}{
+		{
+			name:   "inject on route without headers",
+			inject: true,
+			wantHeaders: map[string]string{
+				"X-Real-IP":        "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+				"X-Forwarded-Host": "%REQ(:AUTHORITY)%",
+			},
+		},
+		{
+			name:    "trusted headers override application headers",
+			inject:  true,
+			headers: map[string]string{"X-Real-IP": "1.2.3.4", "secret-header": "1"},
+			wantHeaders: map[string]string{
+				"X-Real-IP":        "%DOWNSTREAM_REMOTE_ADDRESS_WITHOUT_PORT%",
+				"X-Forwarded-Host": "%REQ(:AUTHORITY)%",
+				"secret-header":    "1",
+			},
+		},
+		{
+			name:        "disabled keeps application headers only",
+			headers:     map[string]string{"secret-header": "1"},
+			wantHeaders: map[string]string{"secret-header": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &virtualServiceController{config: &NetworkConfig{InjectTrustedProxyHeaders: tt.inject}}
+			routes := controller.buildHTTPRoutes(&VirtualServiceConfig{
+				Name:        "app-vs",
+				Namespace:   "ns-user1",
+				Hosts:       []string{"app.cloud.sealos.io"},
+				ServiceName: "app",
+				ServicePort: 8080,
+				Headers:     tt.headers,
+			})
+
+			route := routes[0].(map[string]interface{})
+			headers, ok := route["headers"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("route has no headers: %v", route)
+			}
+			got := headers["request"].(map[string]interface{})["set"].(map[string]string)
+			if len(got) != len(tt.wantHeaders) {
+				t.Errorf("request headers = %v, want %v", got, tt.wantHeaders)
+			}
+			for k, want := range tt.wantHeaders {
+				if got[k] != want {
+					t.Errorf("request header %s = %q, want %q", k, got[k], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateVirtualServiceHosts(t *testing.T) {
+	tests := []struct {
+		name      string
+		hosts     []string
+		wantError string
+	}{
+		{name: "empty hosts", hosts: nil, wantError: "hosts cannot be empty"},
+		{name: "blank host entry", hosts: []string{"app.cloud.sealos.io", "  "}, wantError: "hosts[1] is blank"},
+		{name: "invalid host", hosts: []string{"app_1.cloud.sealos.io"}, wantError: "is not a valid host"},
+		{name: "host with scheme", hosts: []string{"https://app.cloud.sealos.io"}, wantError: "is not a valid host"},
+		{name: "valid hosts", hosts: []string{"app.cloud.sealos.io", "*.example.com", "app-svc"}},
+		{name: "match-all host", hosts: []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &VirtualServiceConfig{Name: "app-vs", Namespace: "ns-user1", Hosts: tt.hosts}
+			err := validateVirtualServiceHosts(config)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("validateVirtualServiceHosts() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Fatalf("validateVirtualServiceHosts() error = %v, want containing %q", err, tt.wantError)
+			}
+			if !strings.Contains(err.Error(), "ns-user1/app-vs") {
+				t.Errorf("error should identify the virtualservice: %v", err)
+			}
+		})
+	}
+}
+
+func TestVirtualServiceController_RejectsEmptyHostsBeforeApply(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewVirtualServiceController(c, &NetworkConfig{})
+	config := &VirtualServiceConfig{Name: "app-vs", Namespace: "ns-user1", ServiceName: "app", ServicePort: 8080}
+
+	if err := controller.Create(context.Background(), config); err == nil {
+		t.Error("Create() expected error for empty hosts")
+	}
+	if err := controller.CreateOrUpdateWithOwner(context.Background(), config, nil, nil); err == nil {
+		t.Error("CreateOrUpdateWithOwner() expected error for empty hosts")
+	}
+	if _, exists := getTestVirtualService(t, c, "app-vs", "ns-user1"); exists {
+		t.Error("virtualservice should not be created with empty hosts")
+	}
+}
+
+func TestBuildAbortFault_FractionalPercentage(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		wantJSON   string
+	}{
+		{name: "canary fraction", percentage: 0.1, wantJSON: `{"value":0.1}`},
+		{name: "half", percentage: 50, wantJSON: `{"value":50}`},
+		{name: "all requests", percentage: 100, wantJSON: `{"value":100}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := &unstructured.Unstructured{Object: map[string]interface{}{}}
+			route := []interface{}{map[string]interface{}{"fault": buildAbortFault(tt.percentage, 503)}}
+			if err := unstructured.SetNestedSlice(vs.Object, route, "spec", "http"); err != nil {
+				t.Fatalf("SetNestedSlice() error = %v", err)
+			}
+
+			httpRoutes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+			percentage, found, err := unstructured.NestedMap(httpRoutes[0].(map[string]interface{}), "fault", "abort", "percentage")
+			if err != nil || !found {
+				t.Fatalf("percentage not found in emitted fault: %v", err)
+			}
+			if value := percentage["value"]; value != tt.percentage {
+				t.Errorf("percentage.value = %v (%T), want %v", value, value, tt.percentage)
+			}
+
+			data, err := json.Marshal(percentage)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.wantJSON {
+				t.Errorf("emitted percentage = %s, want %s", data, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestBuildVirtualServiceSpec_TCP(t *testing.T) {
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	vsConfig := &VirtualServiceConfig{
+		Name:        "db-proxy-vs",
+		Namespace:   "ns-test",
+		Hosts:       []string{"db.example.com"},
+		Gateways:    []string{"ns-test/db-proxy-gateway"},
+		Protocol:    ProtocolTCP,
+		ServiceName: "mysql",
+		ServicePort: 3306,
+		TCPPort:     33306,
+	}
+
+	vs := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if err := unstructured.SetNestedMap(vs.Object, controller.buildVirtualServiceSpec(vsConfig), "spec"); err != nil {
+		t.Fatalf("failed to set spec: %v", err)
+	}
+
+	if _, found, _ := unstructured.NestedSlice(vs.Object, "spec", "http"); found {
+		t.Errorf("tcp virtualservice should not have http routes")
+	}
+	tcpRoutes, found, err := unstructured.NestedSlice(vs.Object, "spec", "tcp")
+	if err != nil || !found || len(tcpRoutes) != 1 {
+		t.Fatalf("tcp routes = %v, want 1 route", tcpRoutes)
+	}
+	route := tcpRoutes[0].(map[string]interface{})
+	matches := route["match"].([]interface{})
+	if port := matches[0].(map[string]interface{})["port"]; port != int64(33306) {
+		t.Errorf("match port = %v, want 33306", port)
+	}
+
+	serviceName, servicePort, protocol, err := controller.extractServiceInfo(vs)
+	if err != nil {
+		t.Fatalf("extractServiceInfo() error = %v", err)
+	}
+	if serviceName != "mysql" || servicePort != 3306 || protocol != ProtocolTCP {
+		t.Errorf("extractServiceInfo() = %s:%d %s, want mysql:3306 tcp", serviceName, servicePort, protocol)
+	}
+}
+
+func TestDomainClassifier_TCPNetworking(t *testing.T) {
+	dc := NewDomainClassifier(&NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+		PublicDomains:  []string{"cloud.sealos.io"},
+	})
+	spec := &AppNetworkingSpec{
+		Name:        "db-proxy",
+		Namespace:   "ns-test",
+		Protocol:    ProtocolTCP,
+		Hosts:       []string{"db-proxy.cloud.sealos.io"},
+		ServiceName: "mysql",
+		ServicePort: 3306,
+		TCPPort:     33306,
+	}
+
+	if err := ValidateNetworkingSpec(spec); err != nil {
+		t.Fatalf("ValidateNetworkingSpec() error = %v", err)
+	}
+	missingPort := *spec
+	missingPort.TCPPort = 0
+	if err := ValidateNetworkingSpec(&missingPort); err == nil {
+		t.Errorf("ValidateNetworkingSpec() should reject tcp spec without tcp port")
+	}
+
+	// 公共域名也需要应用 Gateway 暴露 TCP 端口
+	gatewayConfig := dc.BuildOptimizedGatewayConfig(spec)
+	if gatewayConfig == nil || gatewayConfig.TCPPort != 33306 {
+		t.Fatalf("gateway config = %+v, want tcp port 33306", gatewayConfig)
+	}
+	vsConfig := dc.BuildOptimizedVirtualServiceConfig(spec)
+	if len(vsConfig.Gateways) != 1 || vsConfig.Gateways[0] != "ns-test/db-proxy-gateway" {
+		t.Errorf("gateways = %v, want [ns-test/db-proxy-gateway]", vsConfig.Gateways)
+	}
+	if vsConfig.TCPPort != 33306 {
+		t.Errorf("virtualservice tcp port = %d, want 33306", vsConfig.TCPPort)
+	}
+}
+
+func TestVirtualServiceController_L4Routes(t *testing.T) {
+	mysqlDestination := []interface{}{
+		map[string]interface{}{
+			"destination": map[string]interface{}{
+				"host": "mysql.ns-user1.svc.cluster.local",
+				"port": map[string]interface{}{"number": int64(3306)},
+			},
+		},
+	}
+	tests := []struct {
+		name         string
+		config       *VirtualServiceConfig
+		field        string
+		wantRoutes   []interface{}
+		wantProtocol Protocol
+	}{
+		{
+			name: "pure tcp on 3306",
+			config: &VirtualServiceConfig{
+				Name:        "mysql-vs",
+				Namespace:   "ns-user1",
+				Hosts:       []string{"mysql.cloud.sealos.io"},
+				Gateways:    []string{"ns-user1/mysql-gateway"},
+				ServiceName: "mysql.ns-user1.svc.cluster.local",
+				ServicePort: 3306,
+				TCPRoutes:   []TCPRoute{{Port: 3306}},
+			},
+			field: "tcp",
+			wantRoutes: []interface{}{
+				map[string]interface{}{
+					"match": []interface{}{map[string]interface{}{"port": int64(3306)}},
+					"route": mysqlDestination,
+				},
+			},
+			wantProtocol: ProtocolTCP,
+		},
+		{
+			name: "tls passthrough by sni",
+			config: &VirtualServiceConfig{
+				Name:      "mysql-vs",
+				Namespace: "ns-user1",
+				Hosts:     []string{"mysql.cloud.sealos.io"},
+				Gateways:  []string{"ns-user1/mysql-gateway"},
+				TLSRoutes: []TLSRoute{{
+					SNIHosts:    []string{"mysql.cloud.sealos.io"},
+					Port:        443,
+					ServiceName: "mysql.ns-user1.svc.cluster.local",
+					ServicePort: 3306,
+				}},
+			},
+			field: "tls",
+			wantRoutes: []interface{}{
+				map[string]interface{}{
+					"match": []interface{}{map[string]interface{}{
+						"port":     int64(443),
+						"sniHosts": []interface{}{"mysql.cloud.sealos.io"},
+					}},
+					"route": mysqlDestination,
+				},
+			},
+			wantProtocol: ProtocolTLS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+			controller := NewVirtualServiceController(c, &NetworkConfig{})
+			ctx := context.Background()
+
+			if err := controller.Create(ctx, tt.config); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			vs, _ := getTestVirtualService(t, c, tt.config.Name, tt.config.Namespace)
+			if _, found, _ := unstructured.NestedSlice(vs.Object, "spec", "http"); found {
+				t.Errorf("l4 virtualservice should not have http routes")
+			}
+			routes, _, _ := unstructured.NestedSlice(vs.Object, "spec", tt.field)
+			if !reflect.DeepEqual(routes, tt.wantRoutes) {
+				t.Errorf("spec.%s = %v, want %v", tt.field, routes, tt.wantRoutes)
+			}
+
+			got, err := controller.Get(ctx, tt.config.Name, tt.config.Namespace)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got.ServiceName != "mysql.ns-user1.svc.cluster.local" || got.ServicePort != 3306 || got.Protocol != tt.wantProtocol {
+				t.Errorf("Get() = %s:%d %s, want mysql.ns-user1.svc.cluster.local:3306 %s", got.ServiceName, got.ServicePort, got.Protocol, tt.wantProtocol)
+			}
+
+			// 暂停后四层路由全部移除，恢复后还原
+			if err := controller.Suspend(ctx, tt.config.Name, tt.config.Namespace); err != nil {
+				t.Fatalf("Suspend() error = %v", err)
+			}
+			suspended, _ := getTestVirtualService(t, c, tt.config.Name, tt.config.Namespace)
+			if _, found, _ := unstructured.NestedSlice(suspended.Object, "spec", tt.field); found {
+				t.Errorf("suspended virtualservice should have no %s routes", tt.field)
+			}
+			if err := controller.Resume(ctx, tt.config.Name, tt.config.Namespace); err != nil {
+				t.Fatalf("Resume() error = %v", err)
+			}
+			resumed, _ := getTestVirtualService(t, c, tt.config.Name, tt.config.Namespace)
+			if routes, _, _ := unstructured.NestedSlice(resumed.Object, "spec", tt.field); !reflect.DeepEqual(routes, tt.wantRoutes) {
+				t.Errorf("resumed spec.%s = %v, want %v", tt.field, routes, tt.wantRoutes)
+			}
+		})
+	}
+}
+
+func TestValidateL4Routes(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *VirtualServiceConfig
+		wantErr bool
+	}{
+		{name: "tcp with default service", config: &VirtualServiceConfig{ServiceName: "mysql", TCPRoutes: []TCPRoute{{Port: 3306}}}},
+		{name: "tls with default hosts", config: &VirtualServiceConfig{Hosts: []string{"db.example.com"}, TLSRoutes: []TLSRoute{{ServiceName: "mysql"}}}},
+		{name: "tcp without service", config: &VirtualServiceConfig{TCPRoutes: []TCPRoute{{Port: 3306}}}, wantErr: true},
+		{name: "tcp invalid port", config: &VirtualServiceConfig{ServiceName: "mysql", TCPRoutes: []TCPRoute{{Port: 70000}}}, wantErr: true},
+		{name: "tls without sni hosts", config: &VirtualServiceConfig{TLSRoutes: []TLSRoute{{ServiceName: "mysql"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateL4Routes(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("validateL4Routes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_MatchOrdering(t *testing.T) {
+	routes := []HTTPRoute{
+		{Match: RouteMatch{Prefix: "/api"}, ServiceName: "api"},
+		{Match: RouteMatch{Prefix: "/api/v2"}, ServiceName: "api-v2"},
+		{Match: RouteMatch{Exact: "/healthz"}, ServiceName: "health"},
+	}
+	uris := func(httpRoutes []interface{}) []string {
+		var got []string
+		for _, r := range httpRoutes {
+			match := r.(map[string]interface{})["match"].([]interface{})[0].(map[string]interface{})
+			uri := match["uri"].(map[string]interface{})
+			for kind, value := range uri {
+				got = append(got, kind+":"+value.(string))
+			}
+		}
+		return got
+	}
+
+	tests := []struct {
+		name     string
+		preserve bool
+		want     []string
+	}{
+		{name: "sorted by specificity", want: []string{"exact:/healthz", "prefix:/api/v2", "prefix:/api", "prefix:/"}},
+		{name: "explicit order preserved", preserve: true, want: []string{"prefix:/api", "prefix:/api/v2", "exact:/healthz", "prefix:/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &virtualServiceController{config: &NetworkConfig{}}
+			got := uris(controller.buildHTTPRoutes(&VirtualServiceConfig{
+				ServiceName:        "app",
+				ServicePort:        8080,
+				Routes:             routes,
+				PreserveRouteOrder: tt.preserve,
+			}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("route order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHTTPRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		routes   []HTTPRoute
+		preserve bool
+		wantErr  bool
+	}{
+		{name: "valid routes", routes: []HTTPRoute{{Match: RouteMatch{Prefix: "/api"}}, {Match: RouteMatch{Exact: "/healthz"}}}},
+		{name: "missing match", routes: []HTTPRoute{{}}, wantErr: true},
+		{name: "both exact and prefix", routes: []HTTPRoute{{Match: RouteMatch{Exact: "/a", Prefix: "/a"}}}, wantErr: true},
+		{name: "relative path", routes: []HTTPRoute{{Match: RouteMatch{Prefix: "api"}}}, wantErr: true},
+		{name: "shadowed route is sorted", routes: []HTTPRoute{{Match: RouteMatch{Prefix: "/api"}}, {Match: RouteMatch{Prefix: "/api/v2"}}}},
+		{name: "shadowed route in preserved order", routes: []HTTPRoute{{Match: RouteMatch{Prefix: "/api"}}, {Match: RouteMatch{Prefix: "/api/v2"}}}, preserve: true, wantErr: true},
+		{name: "specific first in preserved order", routes: []HTTPRoute{{Match: RouteMatch{Prefix: "/api/v2"}}, {Match: RouteMatch{Prefix: "/api"}}}, preserve: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHTTPRoutes(&VirtualServiceConfig{Name: "app-vs", Namespace: "ns-user1", Routes: tt.routes, PreserveRouteOrder: tt.preserve})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHTTPRoutes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDestinations(t *testing.T) {
+	tests := []struct {
+		name         string
+		destinations []WeightedDestination
+		wantErr      bool
+	}{
+		{name: "single destination path", destinations: nil},
+		{name: "90/10 split", destinations: []WeightedDestination{{Host: "app-blue", Weight: 90}, {Host: "app-green", Weight: 10}}},
+		{name: "weights sum below 100", destinations: []WeightedDestination{{Host: "app-blue", Weight: 80}, {Host: "app-green", Weight: 10}}, wantErr: true},
+		{name: "weights sum above 100", destinations: []WeightedDestination{{Weight: 100}, {Weight: 10}}, wantErr: true},
+		{name: "negative weight", destinations: []WeightedDestination{{Weight: 110}, {Weight: -10}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDestinations(&VirtualServiceConfig{Name: "app-vs", Namespace: "ns-user1", Destinations: tt.destinations})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateDestinations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_WeightedDestinations(t *testing.T) {
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	config := &VirtualServiceConfig{
+		Name:        "app-vs",
+		Namespace:   "ns-user1",
+		ServiceName: "app",
+		ServicePort: 8080,
+		Destinations: []WeightedDestination{
+			{Host: "app-blue", Port: 80, Weight: 90},
+			{Host: "app-green", Subset: "canary", Weight: 10},
+		},
+	}
+	routes := controller.buildHTTPRoutes(config)
+	destinations := routes[len(routes)-1].(map[string]interface{})["route"].([]interface{})
+	want := []map[string]interface{}{
+		{"destination": map[string]interface{}{"host": "app-blue", "port": map[string]interface{}{"number": int64(80)}}, "weight": int64(90)},
+		{"destination": map[string]interface{}{"host": "app-green", "port": map[string]interface{}{"number": int64(8080)}, "subset": "canary"}, "weight": int64(10)},
+	}
+	if len(destinations) != len(want) {
+		t.Fatalf("destinations = %v, want %v", destinations, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(destinations[i], want[i]) {
+			t.Errorf("destinations[%d] = %v, want %v", i, destinations[i], want[i])
+		}
+	}
+
+	// 未配置 Destinations 时仍路由到 ServiceName:ServicePort，且不带权重
+	config.Destinations = nil
+	routes = controller.buildHTTPRoutes(config)
+	destinations = routes[len(routes)-1].(map[string]interface{})["route"].([]interface{})
+	single := map[string]interface{}{"destination": map[string]interface{}{"host": "app", "port": map[string]interface{}{"number": int64(8080)}}}
+	if len(destinations) != 1 || !reflect.DeepEqual(destinations[0], single) {
+		t.Errorf("single destination = %v, want %v", destinations, single)
+	}
+}
+
+func TestVirtualServiceCreate_RejectsWeightMismatch(t *testing.T) {
+	controller := NewVirtualServiceController(fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(), &NetworkConfig{})
+	err := controller.Create(context.Background(), &VirtualServiceConfig{
+		Name:         "app-vs",
+		Namespace:    "ns-user1",
+		Hosts:        []string{"app.cloud.sealos.io"},
+		ServiceName:  "app",
+		ServicePort:  8080,
+		Destinations: []WeightedDestination{{Subset: "v1", Weight: 90}, {Subset: "v2", Weight: 20}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "sum to 110") {
+		t.Errorf("Create() error = %v, want weight sum error", err)
+	}
+}
+
+func TestBuildHTTPRoutes_RewriteAndRedirect(t *testing.T) {
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	config := &VirtualServiceConfig{
+		Name:        "app-vs",
+		Namespace:   "ns-user1",
+		ServiceName: "app",
+		ServicePort: 8080,
+		Routes: []HTTPRoute{
+			{Match: RouteMatch{Prefix: "/api"}, ServiceName: "api", Rewrite: &URIRewrite{URI: "/", Authority: "api.internal"}},
+			{Match: RouteMatch{Prefix: "/old"}, Redirect: &HTTPRedirect{URI: "/new", RedirectCode: 301}},
+		},
+	}
+	routes := controller.buildHTTPRoutes(config)
+	if len(routes) != 3 {
+		t.Fatalf("routes = %d, want 3", len(routes))
+	}
+
+	rewriteRoute := routes[0].(map[string]interface{})
+	wantRewrite := map[string]interface{}{"uri": "/", "authority": "api.internal"}
+	if !reflect.DeepEqual(rewriteRoute["rewrite"], wantRewrite) {
+		t.Errorf("rewrite = %v, want %v", rewriteRoute["rewrite"], wantRewrite)
+	}
+	if _, ok := rewriteRoute["route"]; !ok {
+		t.Errorf("rewrite route should keep its destination")
+	}
+
+	redirectRoute := routes[1].(map[string]interface{})
+	wantRedirect := map[string]interface{}{"uri": "/new", "redirectCode": int64(301)}
+	if !reflect.DeepEqual(redirectRoute["redirect"], wantRedirect) {
+		t.Errorf("redirect = %v, want %v", redirectRoute["redirect"], wantRedirect)
+	}
+	if _, ok := redirectRoute["route"]; ok {
+		t.Errorf("redirect route should not have a destination, got %v", redirectRoute["route"])
+	}
+
+	// 默认路由未配置时不带 rewrite/redirect
+	defaultRoute := routes[2].(map[string]interface{})
+	for _, key := range []string{"rewrite", "redirect"} {
+		if _, ok := defaultRoute[key]; ok {
+			t.Errorf("default route should not have %s", key)
+		}
+	}
+}
+
+func TestValidateRouteAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		rewrite  *URIRewrite
+		redirect *HTTPRedirect
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "rewrite", rewrite: &URIRewrite{URI: "/"}},
+		{name: "redirect with default code", redirect: &HTTPRedirect{Authority: "new.example.com"}},
+		{name: "redirect 308", redirect: &HTTPRedirect{URI: "/new", RedirectCode: 308}},
+		{name: "both set", rewrite: &URIRewrite{URI: "/"}, redirect: &HTTPRedirect{URI: "/new"}, wantErr: true},
+		{name: "empty rewrite", rewrite: &URIRewrite{}, wantErr: true},
+		{name: "empty redirect", redirect: &HTTPRedirect{RedirectCode: 301}, wantErr: true},
+		{name: "invalid code", redirect: &HTTPRedirect{URI: "/new", RedirectCode: 200}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateRouteAction(tt.rewrite, tt.redirect); (err != nil) != tt.wantErr {
+				t.Errorf("validateRouteAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_TraceHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          *NetworkConfig
+		wantHeaders     map[string]string
+		wantCorsHeaders []string
+	}{
+		{
+			name:            "disabled keeps application headers",
+			config:          &NetworkConfig{},
+			wantHeaders:     map[string]string{"X-App": "1", "traceparent": "overwritten"},
+			wantCorsHeaders: []string{"content-type"},
+		},
+		{
+			name:            "default trace headers are preserved",
+			config:          &NetworkConfig{PreserveTraceHeaders: true},
+			wantHeaders:     map[string]string{"X-App": "1"},
+			wantCorsHeaders: append([]string{"content-type"}, DefaultTraceHeaders...),
+		},
+		{
+			name:            "configured trace headers are preserved",
+			config:          &NetworkConfig{PreserveTraceHeaders: true, TraceHeaders: []string{"Traceparent", "x-custom-trace"}},
+			wantHeaders:     map[string]string{"X-App": "1"},
+			wantCorsHeaders: []string{"content-type", "Traceparent", "x-custom-trace"},
+		},
+		{
+			name:            "sampling decision is injected",
+			config:          &NetworkConfig{PreserveTraceHeaders: true, TraceHeaders: []string{"traceparent"}, TraceSamplingDecision: "1"},
+			wantHeaders:     map[string]string{"X-App": "1", TraceSampledHeader: "1"},
+			wantCorsHeaders: []string{"content-type", "traceparent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &virtualServiceController{config: tt.config}
+			routes := controller.buildHTTPRoutes(&VirtualServiceConfig{
+				Name:        "app-vs",
+				Namespace:   "ns-user1",
+				Hosts:       []string{"app.cloud.sealos.io"},
+				ServiceName: "app",
+				ServicePort: 8080,
+				Headers:     map[string]string{"X-App": "1", "traceparent": "overwritten"},
+				CorsPolicy:  &CorsPolicy{AllowOrigins: []string{"https://cloud.sealos.io"}, AllowHeaders: []string{"content-type"}},
+			})
+
+			route := routes[0].(map[string]interface{})
+			got := route["headers"].(map[string]interface{})["request"].(map[string]interface{})["set"].(map[string]string)
+			if !reflect.DeepEqual(got, tt.wantHeaders) {
+				t.Errorf("request headers = %v, want %v", got, tt.wantHeaders)
+			}
+
+			allowHeaders := route["corsPolicy"].(map[string]interface{})["allowHeaders"]
+			if !reflect.DeepEqual(allowHeaders, stringSliceToInterface(tt.wantCorsHeaders)) {
+				t.Errorf("cors allowHeaders = %v, want %v", allowHeaders, tt.wantCorsHeaders)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_FaultInjection(t *testing.T) {
+	tests := []struct {
+		name      string
+		fault     *FaultConfig
+		wantFault map[string]interface{}
+	}{
+		{
+			name:  "delay only",
+			fault: &FaultConfig{Delay: &FaultDelay{Percentage: 10, FixedDelay: 1500 * time.Millisecond}},
+			wantFault: map[string]interface{}{
+				"delay": map[string]interface{}{"percentage": map[string]interface{}{"value": float64(10)}, "fixedDelay": "1.5s"},
+			},
+		},
+		{
+			name:  "abort only",
+			fault: &FaultConfig{Abort: &FaultAbort{Percentage: 0.5, HTTPStatus: 503}},
+			wantFault: map[string]interface{}{
+				"abort": map[string]interface{}{"percentage": map[string]interface{}{"value": 0.5}, "httpStatus": int64(503)},
+			},
+		},
+		{
+			name: "delay and abort",
+			fault: &FaultConfig{
+				Delay: &FaultDelay{Percentage: 100, FixedDelay: 2 * time.Minute},
+				Abort: &FaultAbort{Percentage: 20, HTTPStatus: 500},
+			},
+			wantFault: map[string]interface{}{
+				"delay": map[string]interface{}{"percentage": map[string]interface{}{"value": float64(100)}, "fixedDelay": "120s"},
+				"abort": map[string]interface{}{"percentage": map[string]interface{}{"value": float64(20)}, "httpStatus": int64(500)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &virtualServiceController{config: &NetworkConfig{}}
+			config := &VirtualServiceConfig{
+				Name:           "app-vs",
+				Namespace:      "ns-user1",
+				ServiceName:    "app",
+				ServicePort:    8080,
+				FaultInjection: tt.fault,
+			}
+			routes := controller.buildHTTPRoutes(config)
+			if len(routes) != 1 {
+				t.Fatalf("routes = %d, want 1", len(routes))
+			}
+			route := routes[0].(map[string]interface{})
+			if !reflect.DeepEqual(route["fault"], tt.wantFault) {
+				t.Errorf("fault = %v, want %v", route["fault"], tt.wantFault)
+			}
+		})
+	}
+
+	// 未配置故障注入时不生成 fault
+	controller := &virtualServiceController{config: &NetworkConfig{}}
+	routes := controller.buildHTTPRoutes(&VirtualServiceConfig{Name: "app-vs", Namespace: "ns-user1", ServiceName: "app", ServicePort: 8080})
+	if _, ok := routes[0].(map[string]interface{})["fault"]; ok {
+		t.Errorf("route without fault injection should not have fault")
+	}
+}
+
+func TestValidateFaultInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   *FaultConfig
+		wantErr bool
+	}{
+		{name: "not configured"},
+		{name: "delay", fault: &FaultConfig{Delay: &FaultDelay{Percentage: 50, FixedDelay: time.Second}}},
+		{name: "abort", fault: &FaultConfig{Abort: &FaultAbort{Percentage: 100, HTTPStatus: 503}}},
+		{name: "neither delay nor abort", fault: &FaultConfig{}, wantErr: true},
+		{name: "delay percentage above 100", fault: &FaultConfig{Delay: &FaultDelay{Percentage: 101, FixedDelay: time.Second}}, wantErr: true},
+		{name: "negative abort percentage", fault: &FaultConfig{Abort: &FaultAbort{Percentage: -1, HTTPStatus: 503}}, wantErr: true},
+		{name: "delay without duration", fault: &FaultConfig{Delay: &FaultDelay{Percentage: 10}}, wantErr: true},
+		{name: "invalid abort status", fault: &FaultConfig{Abort: &FaultAbort{Percentage: 10, HTTPStatus: 99}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateFaultInjection(tt.fault); (err != nil) != tt.wantErr {
+				t.Errorf("validateFaultInjection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildHTTPRoutes_RetryOn(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     *RetryPolicy
+		wantRetryOn string
+	}{
+		{name: "istio default conditions", retries: &RetryPolicy{Attempts: 3}},
+		{name: "conditions", retries: &RetryPolicy{Attempts: 3, RetryOn: []string{"5xx", "reset", "connect-failure"}}, wantRetryOn: "5xx,reset,connect-failure"},
+		{name: "status codes", retries: &RetryPolicy{Attempts: 2, RetriableStatusCodes: []int{409, 429}}, wantRetryOn: "409,429"},
+		{
+			name:        "conditions and status codes",
+			retries:     &RetryPolicy{Attempts: 2, RetryOn: []string{"connect-failure", "retriable-status-codes"}, RetriableStatusCodes: []int{503}},
+			wantRetryOn: "connect-failure,retriable-status-codes,503",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := &virtualServiceController{config: &NetworkConfig{}}
+			config := &VirtualServiceConfig{Name: "app-vs", Namespace: "ns-user1", ServiceName: "app", ServicePort: 8080, Retries: tt.retries}
+			route := controller.buildHTTPRoutes(config)[0].(map[string]interface{})
+			retries := route["retries"].(map[string]interface{})
+			retryOn, ok := retries["retryOn"]
+			if tt.wantRetryOn == "" {
+				if ok {
+					t.Errorf("retryOn = %v, want Istio default conditions", retryOn)
+				}
+				return
+			}
+			if retryOn != tt.wantRetryOn {
+				t.Errorf("retryOn = %v, want %s", retryOn, tt.wantRetryOn)
+			}
+			if retries["attempts"] != int64(tt.retries.Attempts) {
+				t.Errorf("attempts = %v, want %d", retries["attempts"], tt.retries.Attempts)
+			}
+		})
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries *RetryPolicy
+		wantErr string
+	}{
+		{name: "not configured"},
+		{name: "supported conditions", retries: &RetryPolicy{RetryOn: []string{"5xx", "gateway-error", "unavailable"}, RetriableStatusCodes: []int{503}}},
+		{name: "unknown condition", retries: &RetryPolicy{RetryOn: []string{"5xx", "timeout"}}, wantErr: `retries.retryOn[1] "timeout" is not a supported retry condition`},
+		{name: "status code as condition", retries: &RetryPolicy{RetryOn: []string{"503"}}, wantErr: `retries.retryOn[0] "503" is not a supported retry condition`},
+		{name: "invalid status code", retries: &RetryPolicy{RetriableStatusCodes: []int{600}}, wantErr: "retries.retriableStatusCodes[0] 600 must be between 100 and 599"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRetryPolicy(tt.retries)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateRetryPolicy() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("validateRetryPolicy() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 通过控制器创建时同样拒绝
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	controller := NewVirtualServiceController(c, &NetworkConfig{})
+	err := controller.Create(context.Background(), &VirtualServiceConfig{
+		Name: "app-vs", Namespace: "ns-user1", Hosts: []string{"app.example.com"}, ServiceName: "app", ServicePort: 8080,
+		Retries: &RetryPolicy{Attempts: 3, RetryOn: []string{"5xx", "on-error"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `"on-error" is not a supported retry condition`) {
+		t.Errorf("Create() error = %v, want an unsupported retry condition error", err)
+	}
+}
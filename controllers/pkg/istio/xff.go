@@ -0,0 +1,163 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// TrustedHopsEnvoyFilterName 设置网关 X-Forwarded-For 可信跳数的 EnvoyFilter 名称
+	TrustedHopsEnvoyFilterName = "sealos-xff-trusted-hops"
+
+	// DefaultTrustedHopsResyncInterval 默认的 EnvoyFilter 重新同步间隔，用于修复被手动修改或删除的配置
+	DefaultTrustedHopsResyncInterval = 10 * time.Minute
+
+	httpConnectionManagerFilter = "envoy.filters.network.http_connection_manager"
+	httpConnectionManagerType   = "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager"
+)
+
+var envoyFilterGVK = schema.GroupVersionKind{
+	Group:   "networking.istio.io",
+	Version: "v1alpha3",
+	Kind:    "EnvoyFilter",
+}
+
+// buildTrustedHopsEnvoyFilterSpec 生成按 GatewaySelector 作用于网关的 EnvoyFilter，
+// 在 HTTP 连接管理器上设置 xff_num_trusted_hops，让 Envoy 从 X-Forwarded-For 中取真实客户端地址
+func buildTrustedHopsEnvoyFilterSpec(config *NetworkConfig) map[string]interface{} {
+	spec := map[string]interface{}{
+		"configPatches": []interface{}{
+			map[string]interface{}{
+				"applyTo": "NETWORK_FILTER",
+				"match": map[string]interface{}{
+					"context": "GATEWAY",
+					"listener": map[string]interface{}{
+						"filterChain": map[string]interface{}{
+							"filter": map[string]interface{}{"name": httpConnectionManagerFilter},
+						},
+					},
+				},
+				"patch": map[string]interface{}{
+					"operation": "MERGE",
+					"value": map[string]interface{}{
+						"typed_config": map[string]interface{}{
+							"@type":                httpConnectionManagerType,
+							"use_remote_address":   true,
+							"xff_num_trusted_hops": int64(config.XFFNumTrustedHops),
+						},
+					},
+				},
+			},
+		},
+	}
+	if len(config.GatewaySelector) > 0 {
+		labels := make(map[string]interface{}, len(config.GatewaySelector))
+		for k, v := range config.GatewaySelector {
+			labels[k] = v
+		}
+		spec["workloadSelector"] = map[string]interface{}{"labels": labels}
+	}
+	return spec
+}
+
+// EnsureTrustedHopsEnvoyFilter 按 NetworkConfig.XFFNumTrustedHops 创建或更新网关命名空间中的 EnvoyFilter，
+// 跳数为 0 时删除由 sealos-istio 创建的 EnvoyFilter，恢复 Envoy 默认行为
+func EnsureTrustedHopsEnvoyFilter(ctx context.Context, c Client, config *NetworkConfig) error {
+	namespace := getSystemNamespace(config)
+	filter := &unstructured.Unstructured{}
+	filter.SetGroupVersionKind(envoyFilterGVK)
+	filter.SetName(TrustedHopsEnvoyFilterName)
+	filter.SetNamespace(namespace)
+
+	if config.XFFNumTrustedHops <= 0 {
+		err := c.Get(ctx, types.NamespacedName{Name: TrustedHopsEnvoyFilterName, Namespace: namespace}, filter)
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get envoyfilter %s/%s: %w", namespace, TrustedHopsEnvoyFilterName, err)
+		}
+		if filter.GetLabels()["app.kubernetes.io/managed-by"] != "sealos-istio" {
+			return nil
+		}
+		if err := c.Delete(ctx, filter); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete envoyfilter %s/%s: %w", namespace, TrustedHopsEnvoyFilterName, err)
+		}
+		return nil
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, c, filter, func() error {
+		filter.SetLabels(MergeLabels(filter.GetLabels(), map[string]string{
+			"app.kubernetes.io/managed-by": "sealos-istio",
+			"app.kubernetes.io/component":  "networking",
+		}))
+		spec := makeSafeForDeepCopy(buildTrustedHopsEnvoyFilterSpec(config))
+		return unstructured.SetNestedMap(filter.Object, spec.(map[string]interface{}), "spec")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create or update envoyfilter %s/%s: %w", namespace, TrustedHopsEnvoyFilterName, err)
+	}
+	return nil
+}
+
+// TrustedHopsEnvoyFilterSyncer 启动时及周期性地同步 X-Forwarded-For 可信跳数的 EnvoyFilter。
+// 共享网关前有 LB/CDN 时，后端应用只能看到网关的地址，配置可信跳数后 Envoy 才会把真实客户端地址
+// 写入 X-Forwarded-For/X-Envoy-External-Address。实现 manager.Runnable，只在主副本运行。
+type TrustedHopsEnvoyFilterSyncer struct {
+	client   Client
+	config   *NetworkConfig
+	interval time.Duration
+}
+
+// NewTrustedHopsEnvoyFilterSyncer 创建 EnvoyFilter 同步器，interval 为 0 时使用 DefaultTrustedHopsResyncInterval
+func NewTrustedHopsEnvoyFilterSyncer(client Client, config *NetworkConfig, interval time.Duration) *TrustedHopsEnvoyFilterSyncer {
+	if interval <= 0 {
+		interval = DefaultTrustedHopsResyncInterval
+	}
+	return &TrustedHopsEnvoyFilterSyncer{client: client, config: config, interval: interval}
+}
+
+// Start 启动后立即同步一次，之后周期性同步直到 ctx 结束
+func (s *TrustedHopsEnvoyFilterSyncer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("xff-trusted-hops").WithValues("hops", s.config.XFFNumTrustedHops)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := EnsureTrustedHopsEnvoyFilter(ctx, s.client, s.config); err != nil {
+			logger.Error(err, "failed to sync trusted hops envoyfilter, will retry")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package istio
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getTestEnvoyFilter(t *testing.T, c client.Client, namespace string) (*unstructured.Unstructured, bool) {
+	t.Helper()
+	filter := &unstructured.Unstructured{}
+	filter.SetGroupVersionKind(envoyFilterGVK)
+	err := c.Get(context.Background(), types.NamespacedName{Name: TrustedHopsEnvoyFilterName, Namespace: namespace}, filter)
+	if errors.IsNotFound(err) {
+		return nil, false
+	}
+	if err != nil {
+		t.Fatalf("get envoyfilter: %v", err)
+	}
+	return filter, true
+}
+
+func TestEnsureTrustedHopsEnvoyFilter(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	config := DefaultNetworkConfig()
+	config.DefaultGateway = "gateway-system/sealos-gateway"
+	config.XFFNumTrustedHops = 2
+	ctx := context.Background()
+
+	if err := EnsureTrustedHopsEnvoyFilter(ctx, c, config); err != nil {
+		t.Fatalf("EnsureTrustedHopsEnvoyFilter() error = %v", err)
+	}
+	filter, found := getTestEnvoyFilter(t, c, "gateway-system")
+	if !found {
+		t.Fatalf("envoyfilter should be created in the gateway namespace")
+	}
+	patches, _, _ := unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if len(patches) != 1 {
+		t.Fatalf("configPatches = %v, want one patch", patches)
+	}
+	patch := patches[0].(map[string]interface{})
+	typedConfig, _, _ := unstructured.NestedMap(patch, "patch", "value", "typed_config")
+	if got := typedConfig["xff_num_trusted_hops"]; got != int64(2) {
+		t.Errorf("xff_num_trusted_hops = %v, want 2", got)
+	}
+	if got, _, _ := unstructured.NestedString(patch, "match", "context"); got != "GATEWAY" {
+		t.Errorf("match context = %s, want GATEWAY", got)
+	}
+	selector, _, _ := unstructured.NestedStringMap(filter.Object, "spec", "workloadSelector", "labels")
+	if !reflect.DeepEqual(selector, config.GatewaySelector) {
+		t.Errorf("workloadSelector = %v, want %v", selector, config.GatewaySelector)
+	}
+
+	// 修改跳数后更新已有的 EnvoyFilter
+	config.XFFNumTrustedHops = 1
+	if err := EnsureTrustedHopsEnvoyFilter(ctx, c, config); err != nil {
+		t.Fatalf("EnsureTrustedHopsEnvoyFilter() error = %v", err)
+	}
+	filter, _ = getTestEnvoyFilter(t, c, "gateway-system")
+	patches, _, _ = unstructured.NestedSlice(filter.Object, "spec", "configPatches")
+	if got, _, _ := unstructured.NestedInt64(patches[0].(map[string]interface{}), "patch", "value", "typed_config", "xff_num_trusted_hops"); got != 1 {
+		t.Errorf("updated xff_num_trusted_hops = %d, want 1", got)
+	}
+
+	// 跳数为 0 时删除 EnvoyFilter
+	config.XFFNumTrustedHops = 0
+	if err := EnsureTrustedHopsEnvoyFilter(ctx, c, config); err != nil {
+		t.Fatalf("EnsureTrustedHopsEnvoyFilter() error = %v", err)
+	}
+	if _, found := getTestEnvoyFilter(t, c, "gateway-system"); found {
+		t.Errorf("envoyfilter should be deleted when trusted hops is 0")
+	}
+}
+
+func TestEnsureTrustedHopsEnvoyFilter_KeepsUnmanagedFilter(t *testing.T) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(envoyFilterGVK)
+	existing.SetName(TrustedHopsEnvoyFilterName)
+	existing.SetNamespace("istio-system")
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(existing).Build()
+
+	config := DefaultNetworkConfig()
+	if err := EnsureTrustedHopsEnvoyFilter(context.Background(), c, config); err != nil {
+		t.Fatalf("EnsureTrustedHopsEnvoyFilter() error = %v", err)
+	}
+	if _, found := getTestEnvoyFilter(t, c, "istio-system"); !found {
+		t.Errorf("envoyfilter not created by sealos-istio should be kept")
+	}
+}
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -30,6 +32,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -38,9 +41,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/labring/sealos/controllers/pkg/istio"
 )
@@ -51,6 +57,16 @@ type NetworkReconciler struct {
 	Log              logr.Logger
 	networkingManager istio.NetworkingManager
 	useIstio         bool
+	// istioMu guards useIstio and networkingManager: Reconcile holds the read lock,
+	// the Istio mode reevaluator publishes the fully built manager under the write lock
+	istioMu sync.RWMutex
+	// namespaceSelector limits which namespaces the controller watches, nil means all namespaces
+	namespaceSelector labels.Selector
+	// suspendExemptMethods are answered with suspendExemptStatus instead of 503 while suspended (e.g. OPTIONS/HEAD health checks)
+	suspendExemptMethods []string
+	suspendExemptStatus  int
+	// resumeWaitForEndpoints keeps the suspend route on resume until the backing Services have ready endpoints
+	resumeWaitForEndpoints bool
 }
 
 const (
@@ -59,10 +75,29 @@ const (
 	NetworkResume          = "Resume"
 	NetworkResumeCompleted = "ResumeCompleted"
 	NodePortLabelKey       = "network.sealos.io/original-nodeport"
+	NodePortsAnnoKey       = "network.sealos.io/original-nodeports"
+	NodePortExemptAnnoKey  = "network.sealos.io/nodeport-exempt"
 	IngressClassKey        = "kubernetes.io/ingress.class"
 
 	Disable = "disable"
 	True    = "true"
+
+	// NetworkNamespaceSelectorEnv is a label selector (e.g. "sealos.io/network-managed=true") restricting watched namespaces
+	NetworkNamespaceSelectorEnv = "NETWORK_NAMESPACE_SELECTOR"
+
+	// SuspendExemptMethodsEnv is a comma separated list of HTTP methods (e.g. "OPTIONS,HEAD") exempted from the suspend 503
+	SuspendExemptMethodsEnv = "SUSPEND_EXEMPT_METHODS"
+	// SuspendExemptStatusEnv is the status returned to exempted methods, defaults to DefaultSuspendExemptStatus
+	SuspendExemptStatusEnv = "SUSPEND_EXEMPT_STATUS"
+	// ResumeWaitForEndpointsEnv set to "true" keeps the suspend route until the backing Services have ready endpoints
+	ResumeWaitForEndpointsEnv = "RESUME_WAIT_FOR_ENDPOINTS"
+
+	SuspendedStatus            = 503
+	SuspendedMessage           = "Service temporarily suspended for resource management"
+	DefaultSuspendExemptStatus = 200
+
+	// resumeEndpointsRequeueInterval is how often a resume waiting for backend endpoints is retried
+	resumeEndpointsRequeueInterval = 5 * time.Second
 )
 
 // retryUpdateOnConflict retries the update operation when there's a resource version conflict
@@ -94,8 +129,13 @@ func retryUpdateOnConflict(ctx context.Context, c client.Client, obj client.Obje
 //+kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;patch
 
 func (r *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Wait for an in-flight switch to Istio mode instead of reading half-published state
+	r.istioMu.RLock()
+	defer r.istioMu.RUnlock()
 	logger := r.Log.WithValues("Namespace", req.Namespace, "Name", req.NamespacedName)
 
 	logger.Info("Reconciling Network")
@@ -152,10 +192,15 @@ func (r *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			namespace = req.Name
 		}
 		// Handle namespace resumption
-		if err := r.resumeNetworkResources(ctx, namespace); err != nil {
+		pending, err := r.resumeNetworkResources(ctx, namespace)
+		if err != nil {
 			logger.Error(err, "failed to resume network resources")
 			return ctrl.Result{}, err
 		}
+		if pending > 0 {
+			logger.Info("keeping suspend routes until backends are ready", "pending", pending)
+			return ctrl.Result{RequeueAfter: resumeEndpointsRequeueInterval}, nil
+		}
 		// Update namespace status
 		if ns.Annotations == nil {
 			ns.Annotations = make(map[string]string)
@@ -210,13 +255,9 @@ func (r *NetworkReconciler) handleIngressResource(ctx context.Context, key clien
 	// Try fetching as Service
 	svc := corev1.Service{}
 	if err := r.Client.Get(ctx, key, &svc); err == nil {
-		if svc.Spec.Type == corev1.ServiceTypeNodePort && (svc.Labels == nil || svc.Labels[NodePortLabelKey] != True) {
-			if svc.Labels == nil {
-				svc.Labels = make(map[string]string)
-			}
+		if svc.Spec.Type == corev1.ServiceTypeNodePort && !isNodePortExempt(&svc) && (svc.Labels == nil || svc.Labels[NodePortLabelKey] != True) {
 			if err := retryUpdateOnConflict(ctx, r.Client, &svc, func() {
-				svc.Labels[NodePortLabelKey] = True
-				svc.Spec.Type = corev1.ServiceTypeClusterIP
+				suspendNodePortService(&svc)
 			}); err != nil {
 				return fmt.Errorf("failed to suspend service %s: %w", key.Name, err)
 			}
@@ -264,23 +305,7 @@ func (r *NetworkReconciler) handleIstioResource(ctx context.Context, key client.
 		vs.SetAnnotations(annotations)
 		
 		// 设置暂停路由
-		suspendRoute := []interface{}{
-			map[string]interface{}{
-				"match": []interface{}{
-					map[string]interface{}{
-						"uri": map[string]interface{}{
-							"prefix": "/",
-						},
-					},
-				},
-				"directResponse": map[string]interface{}{
-					"status": 503,
-					"body": map[string]interface{}{
-						"string": "Service temporarily suspended for resource management",
-					},
-				},
-			},
-		}
+		suspendRoute := r.buildSuspendRoutes()
 		
 		if err := retryUpdateOnConflict(ctx, r.Client, vs, func() {
 			unstructured.SetNestedSlice(vs.Object, suspendRoute, "spec", "http")
@@ -296,13 +321,9 @@ func (r *NetworkReconciler) handleIstioResource(ctx context.Context, key client.
 	// Try fetching as Service (NodePort services still need to be handled in Istio mode)
 	svc := corev1.Service{}
 	if err := r.Client.Get(ctx, key, &svc); err == nil {
-		if svc.Spec.Type == corev1.ServiceTypeNodePort && (svc.Labels == nil || svc.Labels[NodePortLabelKey] != True) {
-			if svc.Labels == nil {
-				svc.Labels = make(map[string]string)
-			}
+		if svc.Spec.Type == corev1.ServiceTypeNodePort && !isNodePortExempt(&svc) && (svc.Labels == nil || svc.Labels[NodePortLabelKey] != True) {
 			if err := retryUpdateOnConflict(ctx, r.Client, &svc, func() {
-				svc.Labels[NodePortLabelKey] = True
-				svc.Spec.Type = corev1.ServiceTypeClusterIP
+				suspendNodePortService(&svc)
 			}); err != nil {
 				return fmt.Errorf("failed to suspend service %s: %w", key.Name, err)
 			}
@@ -354,12 +375,12 @@ func (r *NetworkReconciler) suspendIngressResources(ctx context.Context, namespa
 		if svc.Spec.Type != corev1.ServiceTypeNodePort {
 			continue
 		}
-		if svc.Labels == nil {
-			svc.Labels = make(map[string]string)
+		if isNodePortExempt(&svc) {
+			r.Log.V(1).Info("Skipping exempt NodePort service", "name", svc.Name)
+			continue
 		}
 		if err := retryUpdateOnConflict(ctx, r.Client, &svc, func() {
-			svc.Labels[NodePortLabelKey] = True
-			svc.Spec.Type = corev1.ServiceTypeClusterIP
+			suspendNodePortService(&svc)
 		}); err != nil {
 			return fmt.Errorf("failed to suspend service %s: %w", svc.Name, err)
 		}
@@ -369,13 +390,14 @@ func (r *NetworkReconciler) suspendIngressResources(ctx context.Context, namespa
 	return nil
 }
 
-func (r *NetworkReconciler) resumeNetworkResources(ctx context.Context, namespace string) error {
+// resumeNetworkResources 恢复命名空间的网络资源，返回因后端未就绪而仍保留暂停路由的 VirtualService 数量
+func (r *NetworkReconciler) resumeNetworkResources(ctx context.Context, namespace string) (int, error) {
 	// 根据配置决定使用 Istio 还是 Ingress
 	if r.useIstio && r.networkingManager != nil {
 		return r.resumeIstioResources(ctx, namespace)
 	}
 	
-	return r.resumeIngressResources(ctx, namespace)
+	return 0, r.resumeIngressResources(ctx, namespace)
 }
 
 func (r *NetworkReconciler) resumeIngressResources(ctx context.Context, namespace string) error {
@@ -405,10 +427,7 @@ func (r *NetworkReconciler) resumeIngressResources(ctx context.Context, namespac
 		if svc.Labels == nil || svc.Labels[NodePortLabelKey] != True {
 			continue
 		}
-		if err := retryUpdateOnConflict(ctx, r.Client, &svc, func() {
-			svc.Spec.Type = corev1.ServiceTypeNodePort
-			delete(svc.Labels, NodePortLabelKey)
-		}); err != nil {
+		if err := r.resumeNodePortService(ctx, &svc); err != nil {
 			return fmt.Errorf("failed to resume service %s: %w", svc.Name, err)
 		}
 		r.Log.V(1).Info("Resumed service", "name", svc.Name)
@@ -459,23 +478,7 @@ func (r *NetworkReconciler) suspendIstioResources(ctx context.Context, namespace
 		}
 		
 		// 设置暂停路由
-		suspendRoute := []interface{}{
-			map[string]interface{}{
-				"match": []interface{}{
-					map[string]interface{}{
-						"uri": map[string]interface{}{
-							"prefix": "/",
-						},
-					},
-				},
-				"directResponse": map[string]interface{}{
-					"status": 503,
-					"body": map[string]interface{}{
-						"string": "Service temporarily suspended for resource management",
-					},
-				},
-			},
-		}
+		suspendRoute := r.buildSuspendRoutes()
 		
 		if err := retryUpdateOnConflict(ctx, r.Client, &vs, func() {
 			unstructured.SetNestedSlice(vs.Object, suspendRoute, "spec", "http")
@@ -494,12 +497,12 @@ func (r *NetworkReconciler) suspendIstioResources(ctx context.Context, namespace
 		if svc.Spec.Type != corev1.ServiceTypeNodePort {
 			continue
 		}
-		if svc.Labels == nil {
-			svc.Labels = make(map[string]string)
+		if isNodePortExempt(&svc) {
+			r.Log.V(1).Info("Skipping exempt NodePort service", "name", svc.Name)
+			continue
 		}
 		if err := retryUpdateOnConflict(ctx, r.Client, &svc, func() {
-			svc.Labels[NodePortLabelKey] = True
-			svc.Spec.Type = corev1.ServiceTypeClusterIP
+			suspendNodePortService(&svc)
 		}); err != nil {
 			return fmt.Errorf("failed to suspend service %s: %w", svc.Name, err)
 		}
@@ -509,7 +512,7 @@ func (r *NetworkReconciler) suspendIstioResources(ctx context.Context, namespace
 	return nil
 }
 
-func (r *NetworkReconciler) resumeIstioResources(ctx context.Context, namespace string) error {
+func (r *NetworkReconciler) resumeIstioResources(ctx context.Context, namespace string) (int, error) {
 	// Resume VirtualServices
 	vsList := &unstructured.UnstructuredList{}
 	vsList.SetGroupVersionKind(schema.GroupVersionKind{
@@ -519,9 +522,10 @@ func (r *NetworkReconciler) resumeIstioResources(ctx context.Context, namespace
 	})
 	
 	if err := r.Client.List(ctx, vsList, client.InNamespace(namespace)); err != nil {
-		return fmt.Errorf("failed to list virtual services in namespace %s: %w", namespace, err)
+		return 0, fmt.Errorf("failed to list virtual services in namespace %s: %w", namespace, err)
 	}
 	
+	pending := 0
 	for _, vs := range vsList.Items {
 		// 检查是否被暂停
 		annotations := vs.GetAnnotations()
@@ -532,6 +536,18 @@ func (r *NetworkReconciler) resumeIstioResources(ctx context.Context, namespace
 		// 恢复原始路由
 		if originalHTTP, exists := annotations["network.sealos.io/original-http"]; exists {
 			if routes := r.decodeRoutes(originalHTTP); routes != nil {
+				// 后端未就绪时保留暂停路由，用户看到的是 503 提示页而不是连接错误
+				if r.resumeWaitForEndpoints {
+					ready, err := r.routeBackendsReady(ctx, vs.GetNamespace(), routes)
+					if err != nil {
+						return pending, err
+					}
+					if !ready {
+						r.Log.V(1).Info("Backends not ready, keeping suspend route", "name", vs.GetName())
+						pending++
+						continue
+					}
+				}
 				unstructured.SetNestedSlice(vs.Object, routes, "spec", "http")
 			}
 			delete(annotations, "network.sealos.io/original-http")
@@ -542,7 +558,7 @@ func (r *NetworkReconciler) resumeIstioResources(ctx context.Context, namespace
 			delete(annotations, "network.sealos.io/suspended")
 			vs.SetAnnotations(annotations)
 		}); err != nil {
-			return fmt.Errorf("failed to resume virtual service %s: %w", vs.GetName(), err)
+			return pending, fmt.Errorf("failed to resume virtual service %s: %w", vs.GetName(), err)
 		}
 		r.Log.V(1).Info("Resumed virtual service", "name", vs.GetName())
 	}
@@ -550,22 +566,144 @@ func (r *NetworkReconciler) resumeIstioResources(ctx context.Context, namespace
 	// Resume NodePort Services
 	serviceList := corev1.ServiceList{}
 	if err := r.Client.List(ctx, &serviceList, client.InNamespace(namespace)); err != nil {
-		return fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
+		return pending, fmt.Errorf("failed to list services in namespace %s: %w", namespace, err)
 	}
 	for _, svc := range serviceList.Items {
 		if svc.Labels == nil || svc.Labels[NodePortLabelKey] != True {
 			continue
 		}
-		if err := retryUpdateOnConflict(ctx, r.Client, &svc, func() {
-			svc.Spec.Type = corev1.ServiceTypeNodePort
-			delete(svc.Labels, NodePortLabelKey)
-		}); err != nil {
-			return fmt.Errorf("failed to resume service %s: %w", svc.Name, err)
+		if err := r.resumeNodePortService(ctx, &svc); err != nil {
+			return pending, fmt.Errorf("failed to resume service %s: %w", svc.Name, err)
 		}
 		r.Log.V(1).Info("Resumed service", "name", svc.Name)
 	}
 	
-	return nil
+	return pending, nil
+}
+
+// routeBackendsReady reports whether every Service referenced by the routes' destinations has at least one ready endpoint
+func (r *NetworkReconciler) routeBackendsReady(ctx context.Context, namespace string, routes []interface{}) (bool, error) {
+	for _, key := range routeDestinationServices(namespace, routes) {
+		endpoints := &corev1.Endpoints{}
+		if err := r.Client.Get(ctx, key, endpoints); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to get endpoints %s: %w", key, err)
+		}
+		ready := false
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// routeDestinationServices extracts the Services referenced by HTTP route destinations. Hosts are either a short
+// Service name or "<service>.<namespace>.svc[.cluster.local]"; external hosts like "example.com" are not probed.
+func routeDestinationServices(namespace string, routes []interface{}) []types.NamespacedName {
+	var keys []types.NamespacedName
+	seen := make(map[types.NamespacedName]bool)
+	for _, route := range routes {
+		routeMap, ok := route.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		destinations, _, _ := unstructured.NestedSlice(routeMap, "route")
+		for _, destination := range destinations {
+			destinationMap, ok := destination.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			host, _, _ := unstructured.NestedString(destinationMap, "destination", "host")
+			if host == "" {
+				continue
+			}
+			parts := strings.Split(host, ".")
+			key := types.NamespacedName{Namespace: namespace, Name: parts[0]}
+			switch {
+			case len(parts) == 1:
+			case len(parts) >= 3 && parts[2] == "svc":
+				key.Namespace = parts[1]
+			default:
+				continue
+			}
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// buildSuspendRoutes builds the HTTP routes of a suspended VirtualService: exempted methods get a
+// bodyless directResponse with the configured status, everything else gets the 503 page
+func (r *NetworkReconciler) buildSuspendRoutes() []interface{} {
+	status := r.suspendExemptStatus
+	if status == 0 {
+		status = DefaultSuspendExemptStatus
+	}
+	routes := make([]interface{}, 0, len(r.suspendExemptMethods)+1)
+	for _, method := range r.suspendExemptMethods {
+		routes = append(routes, map[string]interface{}{
+			"match": []interface{}{
+				map[string]interface{}{
+					"method": map[string]interface{}{
+						"exact": method,
+					},
+				},
+			},
+			"directResponse": map[string]interface{}{
+				"status": int64(status),
+			},
+		})
+	}
+	return append(routes, map[string]interface{}{
+		"match": []interface{}{
+			map[string]interface{}{
+				"uri": map[string]interface{}{
+					"prefix": "/",
+				},
+			},
+		},
+		"directResponse": map[string]interface{}{
+			"status": int64(SuspendedStatus),
+			"body": map[string]interface{}{
+				"string": SuspendedMessage,
+			},
+		},
+	})
+}
+
+// parseSuspendExemptMethods parses a comma separated method list, normalizing to upper case and dropping duplicates
+func parseSuspendExemptMethods(raw string) []string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, method := range strings.Split(raw, ",") {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" || seen[method] {
+			continue
+		}
+		seen[method] = true
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+// parseSuspendExemptStatus parses the exempt status, falling back to DefaultSuspendExemptStatus when unset or invalid
+func parseSuspendExemptStatus(raw string) int {
+	status, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || status < 100 || status > 599 {
+		return DefaultSuspendExemptStatus
+	}
+	return status
 }
 
 // encodeRoutes 编码路由为字符串
@@ -588,6 +726,17 @@ func (r *NetworkReconciler) decodeRoutes(encoded string) []interface{} {
 type SuspendedNamespaceHandler struct {
 	Client client.Client
 	Logger logr.Logger
+	// NamespaceSelector filters namespaces by labels, nil means all namespaces are handled
+	NamespaceSelector labels.Selector
+}
+
+// isHandled reports whether events in the namespace should be enqueued
+func (e *SuspendedNamespaceHandler) isHandled(ns *corev1.Namespace) bool {
+	if !namespaceMatchesSelector(e.NamespaceSelector, ns) {
+		return false
+	}
+	networkStatus, ok := ns.Annotations[NetworkStatusAnnoKey]
+	return ok && networkStatus == NetworkSuspend
 }
 
 func (e *SuspendedNamespaceHandler) Create(ctx context.Context, evt event.TypedCreateEvent[client.Object], q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
@@ -602,8 +751,7 @@ func (e *SuspendedNamespaceHandler) Create(ctx context.Context, evt event.TypedC
 		return
 	}
 
-	networkStatus, ok := ns.Annotations[NetworkStatusAnnoKey]
-	if !ok || networkStatus != NetworkSuspend {
+	if !e.isHandled(&ns) {
 		return
 	}
 
@@ -622,8 +770,7 @@ func (e *SuspendedNamespaceHandler) Update(ctx context.Context, evt event.TypedU
 			return
 		}
 
-		networkStatus, ok := ns.Annotations[NetworkStatusAnnoKey]
-		if !ok || networkStatus != NetworkSuspend {
+		if !e.isHandled(&ns) {
 			return
 		}
 
@@ -639,8 +786,7 @@ func (e *SuspendedNamespaceHandler) Update(ctx context.Context, evt event.TypedU
 			return
 		}
 
-		networkStatus, ok := ns.Annotations[NetworkStatusAnnoKey]
-		if !ok || networkStatus != NetworkSuspend {
+		if !e.isHandled(&ns) {
 			return
 		}
 
@@ -662,6 +808,101 @@ func (e *SuspendedNamespaceHandler) Generic(ctx context.Context, evt event.Typed
 	// No action needed for generic events
 }
 
+// isNodePortExempt reports whether the service is annotated to keep its NodePort during suspension
+func isNodePortExempt(svc *corev1.Service) bool {
+	return svc.Annotations != nil && svc.Annotations[NodePortExemptAnnoKey] == True
+}
+
+// servicePortKey identifies a service port by number and protocol, which are unique within a service
+func servicePortKey(port *corev1.ServicePort) string {
+	return fmt.Sprintf("%d/%s", port.Port, port.Protocol)
+}
+
+// suspendNodePortService turns the service into ClusterIP, recording the allocated node ports
+// so that resume can request the same ports again
+func suspendNodePortService(svc *corev1.Service) {
+	if svc.Labels == nil {
+		svc.Labels = make(map[string]string)
+	}
+	if svc.Annotations == nil {
+		svc.Annotations = make(map[string]string)
+	}
+	nodePorts := make(map[string]int32, len(svc.Spec.Ports))
+	for i := range svc.Spec.Ports {
+		port := &svc.Spec.Ports[i]
+		if port.NodePort > 0 {
+			nodePorts[servicePortKey(port)] = port.NodePort
+		}
+		port.NodePort = 0
+	}
+	if data, err := json.Marshal(nodePorts); err == nil && len(nodePorts) > 0 {
+		svc.Annotations[NodePortsAnnoKey] = string(data)
+	}
+	svc.Labels[NodePortLabelKey] = True
+	svc.Spec.Type = corev1.ServiceTypeClusterIP
+}
+
+// restoreNodePortService turns the service back into NodePort with the node ports recorded at suspension,
+// ports listed in unavailable (by index) are left for Kubernetes to assign
+func restoreNodePortService(svc *corev1.Service, unavailable map[int]bool) {
+	nodePorts := map[string]int32{}
+	if data := svc.Annotations[NodePortsAnnoKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &nodePorts); err != nil {
+			nodePorts = map[string]int32{}
+		}
+	}
+	for i := range svc.Spec.Ports {
+		port := &svc.Spec.Ports[i]
+		port.NodePort = 0
+		if !unavailable[i] {
+			port.NodePort = nodePorts[servicePortKey(port)]
+		}
+	}
+	svc.Spec.Type = corev1.ServiceTypeNodePort
+	delete(svc.Labels, NodePortLabelKey)
+	delete(svc.Annotations, NodePortsAnnoKey)
+}
+
+// unavailableNodePorts returns the indexes of the ports rejected by the API server, e.g. because the
+// node port has been allocated to another service while suspended; all ports when the causes are missing
+func unavailableNodePorts(err error, ports int) map[int]bool {
+	unavailable := make(map[int]bool)
+	if status, ok := err.(errors.APIStatus); ok && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			var index int
+			if _, scanErr := fmt.Sscanf(cause.Field, "spec.ports[%d].nodePort", &index); scanErr == nil {
+				unavailable[index] = true
+			}
+		}
+	}
+	if len(unavailable) == 0 {
+		for i := 0; i < ports; i++ {
+			unavailable[i] = true
+		}
+	}
+	return unavailable
+}
+
+// resumeNodePortService restores a suspended NodePort service. When a recorded node port can no longer be
+// allocated, the service is resumed anyway and Kubernetes assigns a new port for it
+func (r *NetworkReconciler) resumeNodePortService(ctx context.Context, svc *corev1.Service) error {
+	err := retryUpdateOnConflict(ctx, r.Client, svc, func() {
+		restoreNodePortService(svc, nil)
+	})
+	if err == nil || !errors.IsInvalid(err) {
+		return err
+	}
+
+	unavailable := unavailableNodePorts(err, len(svc.Spec.Ports))
+	r.Log.Info("Original node ports unavailable, letting Kubernetes reassign them", "name", svc.Name, "error", err.Error())
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(svc), svc); err != nil {
+		return err
+	}
+	return retryUpdateOnConflict(ctx, r.Client, svc, func() {
+		restoreNodePortService(svc, unavailable)
+	})
+}
+
 func isNil(arg any) bool {
 	if v := reflect.ValueOf(arg); !v.IsValid() || ((v.Kind() == reflect.Ptr ||
 		v.Kind() == reflect.Interface ||
@@ -677,18 +918,32 @@ func isNil(arg any) bool {
 func (r *NetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.Log = ctrl.Log.WithName("controllers").WithName("Network")
 	r.Client = mgr.GetClient()
-	suspendedHandler := &SuspendedNamespaceHandler{Client: r.Client, Logger: r.Log}
+
+	selector, err := parseNamespaceSelector(os.Getenv(NetworkNamespaceSelectorEnv))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", NetworkNamespaceSelectorEnv, err)
+	}
+	r.namespaceSelector = selector
+	if selector != nil {
+		r.Log.Info("network controller scoped to namespaces", "selector", selector.String())
+	}
+	r.suspendExemptMethods = parseSuspendExemptMethods(os.Getenv(SuspendExemptMethodsEnv))
+	r.suspendExemptStatus = parseSuspendExemptStatus(os.Getenv(SuspendExemptStatusEnv))
+	if len(r.suspendExemptMethods) > 0 {
+		r.Log.Info("suspended routes exempt methods", "methods", r.suspendExemptMethods, "status", r.suspendExemptStatus)
+	}
+	r.resumeWaitForEndpoints = os.Getenv(ResumeWaitForEndpointsEnv) == True
+	suspendedHandler := &SuspendedNamespaceHandler{Client: r.Client, Logger: r.Log, NamespaceSelector: r.namespaceSelector}
 
 	// 初始化 Istio 支持
 	ctx := context.Background()
 	if err := r.SetupIstioSupport(ctx); err != nil {
 		r.Log.Error(err, "failed to setup Istio support, continuing with Ingress mode")
-		r.useIstio = false
-		r.networkingManager = nil
+		r.setIstioState(nil)
 	}
 
 	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
-		For(&corev1.Namespace{}, builder.WithPredicates(NetworkAnnotationPredicate{})).
+		For(&corev1.Namespace{}, builder.WithPredicates(NamespaceSelectorPredicate(r.namespaceSelector), NetworkAnnotationPredicate{})).
 		Watches(
 			&networkingv1.Ingress{},
 			suspendedHandler,
@@ -740,35 +995,93 @@ func (r *NetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	// 如果启用了 Istio，添加对 VirtualService 的监听
 	if r.useIstio {
-		virtualServiceType := &unstructured.Unstructured{}
-		virtualServiceType.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "networking.istio.io",
-			Version: "v1beta1",
-			Kind:    "VirtualService",
-		})
-		
 		controllerBuilder = controllerBuilder.Watches(
-			virtualServiceType,
+			virtualServiceWatchType(),
 			suspendedHandler,
-			builder.WithPredicates(predicate.Funcs{
-				CreateFunc: func(e event.CreateEvent) bool {
-					return true
-				},
-				UpdateFunc: func(e event.UpdateEvent) bool {
-					// 监听 VirtualService 的变化
-					return true
-				},
-				DeleteFunc: func(e event.DeleteEvent) bool {
-					return false
-				},
-				GenericFunc: func(e event.GenericEvent) bool {
-					return false
-				},
-			}),
+			builder.WithPredicates(virtualServicePredicate()),
 		)
 	}
 
-	return controllerBuilder.Complete(r)
+	c, err := controllerBuilder.Build(r)
+	if err != nil {
+		return err
+	}
+
+	// 默认通配符证书轮换后刷新复制到各命名空间的证书副本
+	if os.Getenv("USE_ISTIO") == "true" {
+		if err := istio.NewTLSSecretMirror(r.Client, r.buildIstioNetworkConfig()).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("failed to setup TLS secret mirror: %w", err)
+		}
+	}
+
+	// Istio CRDs may not be installed yet at startup; keep re-checking and switch to Istio mode once they appear
+	if os.Getenv("USE_ISTIO") == "true" && !r.useIstio {
+		return mgr.Add(istio.NewModeReevaluator(r.Client, istio.DefaultModeReevaluateInterval, func(ctx context.Context) (bool, error) {
+			if err := r.EnableIstioMode(ctx); err != nil || !r.IsIstioEnabled() {
+				return false, err
+			}
+			if err := c.Watch(source.Kind(mgr.GetCache(), virtualServiceWatchType(), handler.EventHandler(suspendedHandler), virtualServicePredicate())); err != nil {
+				return true, err
+			}
+			// Re-enqueue namespaces so that suspensions applied in Ingress mode also cover the VirtualServices
+			return true, c.Watch(istio.EnqueueExisting(mgr.GetClient(), &corev1.NamespaceList{}))
+		}))
+	}
+	return nil
+}
+
+// virtualServiceWatchType returns the unstructured VirtualService type watched in Istio mode
+func virtualServiceWatchType() client.Object {
+	virtualServiceType := &unstructured.Unstructured{}
+	virtualServiceType.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    "VirtualService",
+	})
+	return virtualServiceType
+}
+
+// virtualServicePredicate filters VirtualService events, only create and update are relevant
+func virtualServicePredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return true
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			// 监听 VirtualService 的变化
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
+
+// parseNamespaceSelector parses a label selector string, an empty string means no filtering
+func parseNamespaceSelector(raw string) (labels.Selector, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	return labels.Parse(raw)
+}
+
+// namespaceMatchesSelector reports whether the namespace labels match the selector, a nil selector matches everything
+func namespaceMatchesSelector(selector labels.Selector, ns client.Object) bool {
+	if selector == nil || selector.Empty() {
+		return true
+	}
+	return selector.Matches(labels.Set(ns.GetLabels()))
+}
+
+// NamespaceSelectorPredicate filters namespace events by the configured label selector
+func NamespaceSelectorPredicate(selector labels.Selector) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return namespaceMatchesSelector(selector, obj)
+	})
 }
 
 // NetworkAnnotationPredicate filters namespace events based on network status annotation changes
@@ -808,7 +1121,7 @@ func (r *NetworkReconciler) SetupIstioSupport(ctx context.Context) error {
 	useIstio := os.Getenv("USE_ISTIO")
 	if useIstio != "true" {
 		logger.Info("Istio support is disabled for Resources controller")
-		r.useIstio = false
+		r.setIstioState(nil)
 		return nil
 	}
 	
@@ -821,30 +1134,43 @@ func (r *NetworkReconciler) SetupIstioSupport(ctx context.Context) error {
 	
 	if !isEnabled {
 		logger.Info("Istio is not installed, falling back to Ingress mode for Resources controller")
-		r.useIstio = false
+		r.setIstioState(nil)
 		return nil
 	}
 	
 	// 构建 Istio 网络配置
 	config := r.buildIstioNetworkConfig()
 	
+	// 注册域名分配与验证指标
+	if err := istio.RegisterDomainMetrics(metrics.Registry); err != nil {
+		return err
+	}
+	
 	// 🎯 使用优化的 Istio 网络管理器
-	r.networkingManager = istio.NewOptimizedNetworkingManager(r.Client, config)
+	networkingManager := istio.NewOptimizedNetworkingManager(r.Client, config)
 	
 	// 验证 Istio 安装
 	if err := r.validateIstioInstallation(ctx); err != nil {
 		logger.Error(err, "Istio validation failed, falling back to Ingress mode for Resources controller")
-		r.useIstio = false
-		r.networkingManager = nil
+		r.setIstioState(nil)
 		return nil
 	}
 	
-	r.useIstio = true
+	// 网络管理器构建并校验完成后再发布，协调中的 worker 不会看到半初始化的状态
+	r.setIstioState(networkingManager)
 	logger.Info("Istio support enabled for Resources controller")
 	
 	return nil
 }
 
+// setIstioState 在写锁内发布 Istio 网络管理器，manager 为空时回退到 Ingress 模式
+func (r *NetworkReconciler) setIstioState(manager istio.NetworkingManager) {
+	r.istioMu.Lock()
+	defer r.istioMu.Unlock()
+	r.networkingManager = manager
+	r.useIstio = manager != nil
+}
+
 // buildIstioNetworkConfig 构建 Istio 网络配置（使用智能Gateway优化）
 func (r *NetworkReconciler) buildIstioNetworkConfig() *istio.NetworkConfig {
 	config := istio.DefaultNetworkConfig()
@@ -888,6 +1214,91 @@ func (r *NetworkReconciler) buildIstioNetworkConfig() *istio.NetworkConfig {
 		config.SharedGatewayEnabled = true // 默认启用智能共享Gateway
 	}
 	
+	// 共享 Gateway 按租户分片
+	if shards := os.Getenv("ISTIO_SHARED_GATEWAY_SHARDS"); shards != "" {
+		if n, err := strconv.Atoi(shards); err == nil && n > 1 {
+			config.SharedGatewayShards = n
+		}
+	}
+	
+	// 注入可信转发头部
+	if trusted := os.Getenv("ISTIO_TRUSTED_PROXY_HEADERS"); trusted == "true" {
+		config.InjectTrustedProxyHeaders = true
+	}
+	
+	// 透传链路追踪头部，可指定追踪头部列表和 x-b3-sampled 采样决策
+	if preserve := os.Getenv("ISTIO_PRESERVE_TRACE_HEADERS"); preserve == "true" {
+		config.PreserveTraceHeaders = true
+	}
+	if traceHeaders := os.Getenv("ISTIO_TRACE_HEADERS"); traceHeaders != "" {
+		for _, header := range strings.Split(traceHeaders, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				config.TraceHeaders = append(config.TraceHeaders, header)
+			}
+		}
+	}
+	if sampled := os.Getenv("ISTIO_TRACE_SAMPLING_DECISION"); sampled == "1" || sampled == "0" {
+		config.TraceSamplingDecision = sampled
+	}
+	
+	// 应用未配置 CORS 时使用的默认策略，允许应用自身域名和公共域名（通配符模式按一级子域名匹配）
+	if cors := os.Getenv("ISTIO_DEFAULT_CORS_POLICY"); cors == "true" {
+		config.DefaultCorsPolicy = &istio.CorsPolicy{
+			AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders: []string{"content-type", "authorization"},
+		}
+		if maxAge, err := time.ParseDuration(os.Getenv("ISTIO_DEFAULT_CORS_MAX_AGE")); err == nil && maxAge > 0 {
+			config.DefaultCorsPolicy.MaxAge = &maxAge
+		}
+	}
+	
+	// 内部/私有域名跳过 DNS 解析验证
+	if skipDNS := os.Getenv("ISTIO_SKIP_DNS_VALIDATION"); skipDNS == "true" {
+		config.SkipDNSValidation = true
+	}
+	if suffixes := os.Getenv("ISTIO_DNS_SKIP_SUFFIXES"); suffixes != "" {
+		for _, suffix := range strings.Split(suffixes, ",") {
+			suffix = strings.TrimSpace(suffix)
+			if suffix != "" {
+				config.DNSValidationSkipSuffixes = append(config.DNSValidationSkipSuffixes, suffix)
+			}
+		}
+	}
+	
+	// 中国域名的 ICP 备案查询接口
+	if endpoint := os.Getenv("ISTIO_ICP_VALIDATION_ENDPOINT"); endpoint != "" {
+		config.ICPValidationEndpoint = endpoint
+	}
+	
+	// CAA 检查允许的 ACME CA 标识
+	if cas := os.Getenv("ISTIO_ACME_CA_IDENTIFIERS"); cas != "" {
+		for _, ca := range strings.Split(cas, ",") {
+			ca = strings.TrimSpace(ca)
+			if ca != "" {
+				config.ACMECAIdentifiers = append(config.ACMECAIdentifiers, ca)
+			}
+		}
+	}
+	
+	// 调试：在响应头中暴露服务 Gateway
+	if debug := os.Getenv("ISTIO_DEBUG_GATEWAY_HEADER"); debug == "true" {
+		config.DebugGatewayHeader = true
+	}
+	
+	// 按命名空间覆盖基础域名（多品牌部署），格式 namespace=domain，逗号分隔
+	if overrides := os.Getenv("ISTIO_NAMESPACE_BASE_DOMAINS"); overrides != "" {
+		config.NamespaceBaseDomains = istio.ParseNamespaceBaseDomains(overrides)
+	}
+	
+	// gateway-override 注解允许指向的其他命名空间 Gateway（逗号分隔的 namespace/name）
+	if gateways := os.Getenv("ISTIO_GATEWAY_OVERRIDE_ALLOWLIST"); gateways != "" {
+		for _, gateway := range strings.Split(gateways, ",") {
+			if gateway = strings.TrimSpace(gateway); gateway != "" {
+				config.GatewayOverrideAllowlist = append(config.GatewayOverrideAllowlist, gateway)
+			}
+		}
+	}
+	
 	return config
 }
 
@@ -945,12 +1356,14 @@ func (r *NetworkReconciler) validateIstioInstallation(ctx context.Context) error
 
 // IsIstioEnabled 检查是否启用了 Istio 模式
 func (r *NetworkReconciler) IsIstioEnabled() bool {
+	r.istioMu.RLock()
+	defer r.istioMu.RUnlock()
 	return r.useIstio
 }
 
 // EnableIstioMode 动态启用 Istio 模式
 func (r *NetworkReconciler) EnableIstioMode(ctx context.Context) error {
-	if r.useIstio {
+	if r.IsIstioEnabled() {
 		return nil // 已经启用
 	}
 	
@@ -959,13 +1372,12 @@ func (r *NetworkReconciler) EnableIstioMode(ctx context.Context) error {
 
 // DisableIstioMode 禁用 Istio 模式，回退到 Ingress
 func (r *NetworkReconciler) DisableIstioMode() {
-	r.useIstio = false
-	r.networkingManager = nil
+	r.setIstioState(nil)
 }
 
 // GetNetworkingMode 获取当前网络模式
 func (r *NetworkReconciler) GetNetworkingMode() string {
-	if r.useIstio {
+	if r.IsIstioEnabled() {
 		return "Istio"
 	}
 	return "Ingress"
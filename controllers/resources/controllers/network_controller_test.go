@@ -0,0 +1,566 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newNetworkTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	return scheme
+}
+
+func newNodePortService(name, namespace string, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeNodePort,
+			Ports: []corev1.ServicePort{
+				{Name: "game", Port: 7777, NodePort: 30777},
+			},
+		},
+	}
+}
+
+func TestSuspendIngressResources_NodePortExemption(t *testing.T) {
+	namespace := "ns-test"
+	exempt := newNodePortService("game-server", namespace, map[string]string{NodePortExemptAnnoKey: True})
+	normal := newNodePortService("web", namespace, nil)
+
+	c := fake.NewClientBuilder().
+		WithScheme(newNetworkTestScheme(t)).
+		WithObjects(exempt, normal).
+		Build()
+
+	r := &NetworkReconciler{Client: c, Log: logr.Discard()}
+	ctx := context.Background()
+
+	if err := r.suspendIngressResources(ctx, namespace); err != nil {
+		t.Fatalf("suspendIngressResources() error = %v", err)
+	}
+
+	got := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(exempt), got); err != nil {
+		t.Fatalf("failed to get exempt service: %v", err)
+	}
+	if got.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("exempt service type = %s, want %s", got.Spec.Type, corev1.ServiceTypeNodePort)
+	}
+	if got.Labels[NodePortLabelKey] == True {
+		t.Errorf("exempt service should not carry label %s", NodePortLabelKey)
+	}
+
+	got = &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(normal), got); err != nil {
+		t.Fatalf("failed to get normal service: %v", err)
+	}
+	if got.Spec.Type != corev1.ServiceTypeClusterIP {
+		t.Errorf("normal service type = %s, want %s", got.Spec.Type, corev1.ServiceTypeClusterIP)
+	}
+	if got.Labels[NodePortLabelKey] != True {
+		t.Errorf("normal service label %s = %q, want %q", NodePortLabelKey, got.Labels[NodePortLabelKey], True)
+	}
+}
+
+func newTwoPortNodePortService(name, namespace string) *corev1.Service {
+	svc := newNodePortService(name, namespace, nil)
+	svc.Spec.Ports = []corev1.ServicePort{
+		{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, NodePort: 30080},
+		{Name: "game", Port: 7777, Protocol: corev1.ProtocolUDP, NodePort: 30777},
+	}
+	return svc
+}
+
+func TestSuspendResumeIngressResources_RestoresNodePorts(t *testing.T) {
+	namespace := "ns-test"
+	svc := newTwoPortNodePortService("web", namespace)
+
+	c := fake.NewClientBuilder().
+		WithScheme(newNetworkTestScheme(t)).
+		WithObjects(svc).
+		Build()
+
+	r := &NetworkReconciler{Client: c, Log: logr.Discard()}
+	ctx := context.Background()
+
+	if err := r.suspendIngressResources(ctx, namespace); err != nil {
+		t.Fatalf("suspendIngressResources() error = %v", err)
+	}
+	got := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if got.Spec.Type != corev1.ServiceTypeClusterIP {
+		t.Errorf("suspended service type = %s, want %s", got.Spec.Type, corev1.ServiceTypeClusterIP)
+	}
+	for _, port := range got.Spec.Ports {
+		if port.NodePort != 0 {
+			t.Errorf("suspended port %s nodePort = %d, want cleared", port.Name, port.NodePort)
+		}
+	}
+	if got.Annotations[NodePortsAnnoKey] == "" {
+		t.Fatalf("suspended service should record the node ports in %s", NodePortsAnnoKey)
+	}
+
+	if err := r.resumeIngressResources(ctx, namespace); err != nil {
+		t.Fatalf("resumeIngressResources() error = %v", err)
+	}
+	got = &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if got.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("resumed service type = %s, want %s", got.Spec.Type, corev1.ServiceTypeNodePort)
+	}
+	for i, port := range got.Spec.Ports {
+		if want := svc.Spec.Ports[i].NodePort; port.NodePort != want {
+			t.Errorf("resumed port %s nodePort = %d, want %d", port.Name, port.NodePort, want)
+		}
+	}
+	if _, ok := got.Annotations[NodePortsAnnoKey]; ok {
+		t.Errorf("resumed service should not keep annotation %s", NodePortsAnnoKey)
+	}
+	if _, ok := got.Labels[NodePortLabelKey]; ok {
+		t.Errorf("resumed service should not keep label %s", NodePortLabelKey)
+	}
+}
+
+func TestResumeNodePortService_PortTaken(t *testing.T) {
+	namespace := "ns-test"
+	svc := newTwoPortNodePortService("web", namespace)
+	suspendNodePortService(svc)
+
+	// 30777 was allocated to another service while suspended, the API server rejects it
+	c := fake.NewClientBuilder().
+		WithScheme(newNetworkTestScheme(t)).
+		WithObjects(svc).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if s, ok := obj.(*corev1.Service); ok && len(s.Spec.Ports) > 1 && s.Spec.Ports[1].NodePort == 30777 {
+					return apierrors.NewInvalid(schema.GroupKind{Kind: "Service"}, s.Name, field.ErrorList{
+						field.Invalid(field.NewPath("spec", "ports").Index(1).Child("nodePort"), 30777, "provided port is already allocated"),
+					})
+				}
+				return c.Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	r := &NetworkReconciler{Client: c, Log: logr.Discard()}
+	ctx := context.Background()
+
+	got := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if err := r.resumeNodePortService(ctx, got); err != nil {
+		t.Fatalf("resumeNodePortService() error = %v", err)
+	}
+
+	got = &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("failed to get service: %v", err)
+	}
+	if got.Spec.Type != corev1.ServiceTypeNodePort {
+		t.Errorf("resumed service type = %s, want %s", got.Spec.Type, corev1.ServiceTypeNodePort)
+	}
+	if got.Spec.Ports[0].NodePort != 30080 {
+		t.Errorf("available port nodePort = %d, want 30080", got.Spec.Ports[0].NodePort)
+	}
+	if got.Spec.Ports[1].NodePort != 0 {
+		t.Errorf("taken port nodePort = %d, want 0 so that Kubernetes reassigns it", got.Spec.Ports[1].NodePort)
+	}
+}
+
+func TestIsNodePortExempt(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{name: "no annotations", annotations: nil, expected: false},
+		{name: "exempt annotation true", annotations: map[string]string{NodePortExemptAnnoKey: True}, expected: true},
+		{name: "exempt annotation false", annotations: map[string]string{NodePortExemptAnnoKey: "false"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newNodePortService("svc", "ns-test", tt.annotations)
+			if got := isNodePortExempt(svc); got != tt.expected {
+				t.Errorf("isNodePortExempt() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func newSuspendedNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: map[string]string{NetworkStatusAnnoKey: NetworkSuspend},
+		},
+	}
+}
+
+func TestNamespaceSelectorPredicate(t *testing.T) {
+	selector, err := parseNamespaceSelector("sealos.io/network-managed=true")
+	if err != nil {
+		t.Fatalf("parseNamespaceSelector() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		selector bool
+		labels   map[string]string
+		expected bool
+	}{
+		{name: "selected namespace", selector: true, labels: map[string]string{"sealos.io/network-managed": "true"}, expected: true},
+		{name: "namespace outside selector", selector: true, labels: map[string]string{"team": "a"}, expected: false},
+		{name: "namespace without labels", selector: true, labels: nil, expected: false},
+		{name: "no selector configured", selector: false, labels: nil, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NamespaceSelectorPredicate(nil)
+			if tt.selector {
+				p = NamespaceSelectorPredicate(selector)
+			}
+			ns := newSuspendedNamespace("ns-test", tt.labels)
+			if got := p.Create(event.CreateEvent{Object: ns}); got != tt.expected {
+				t.Errorf("Create() = %v, want %v", got, tt.expected)
+			}
+			if got := p.Update(event.UpdateEvent{ObjectOld: ns, ObjectNew: ns}); got != tt.expected {
+				t.Errorf("Update() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSuspendedNamespaceHandler_NamespaceSelector(t *testing.T) {
+	selector, err := parseNamespaceSelector("sealos.io/network-managed=true")
+	if err != nil {
+		t.Fatalf("parseNamespaceSelector() error = %v", err)
+	}
+
+	selected := newSuspendedNamespace("ns-selected", map[string]string{"sealos.io/network-managed": "true"})
+	ignored := newSuspendedNamespace("ns-ignored", nil)
+	c := fake.NewClientBuilder().
+		WithScheme(newNetworkTestScheme(t)).
+		WithObjects(selected, ignored).
+		Build()
+
+	handler := &SuspendedNamespaceHandler{Client: c, Logger: logr.Discard(), NamespaceSelector: selector}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		namespace string
+		expected  int
+	}{
+		{name: "selected namespace enqueues", namespace: selected.Name, expected: 1},
+		{name: "namespace outside selector is ignored", namespace: ignored.Name, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+			defer q.ShutDown()
+
+			svc := newNodePortService("svc", tt.namespace, nil)
+			handler.Create(ctx, event.TypedCreateEvent[client.Object]{Object: svc}, q)
+			handler.Update(ctx, event.TypedUpdateEvent[client.Object]{ObjectOld: svc, ObjectNew: svc}, q)
+			// requests for the same object are deduplicated by the queue
+			if got := q.Len(); got != tt.expected {
+				t.Errorf("queue length = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseNamespaceSelector(t *testing.T) {
+	if selector, err := parseNamespaceSelector(""); err != nil || selector != nil {
+		t.Errorf("parseNamespaceSelector(\"\") = %v, %v, want nil, nil", selector, err)
+	}
+	if _, err := parseNamespaceSelector("a in (b"); err == nil {
+		t.Error("parseNamespaceSelector() with invalid selector = nil, want error")
+	}
+}
+
+// matchSuspendRoute returns the directResponse of the first route matching the method, mirroring Istio's first-match semantics
+func matchSuspendRoute(t *testing.T, routes []interface{}, method string) map[string]interface{} {
+	t.Helper()
+	for _, route := range routes {
+		r := route.(map[string]interface{})
+		for _, m := range r["match"].([]interface{}) {
+			match := m.(map[string]interface{})
+			if methodMatch, ok := match["method"].(map[string]interface{}); ok && methodMatch["exact"] != method {
+				continue
+			}
+			return r["directResponse"].(map[string]interface{})
+		}
+	}
+	t.Fatalf("no suspend route matches method %s", method)
+	return nil
+}
+
+func TestBuildSuspendRoutes_ExemptMethods(t *testing.T) {
+	tests := []struct {
+		name       string
+		reconciler *NetworkReconciler
+		method     string
+		wantStatus int64
+		wantBody   bool
+	}{
+		{name: "no exemption GET", reconciler: &NetworkReconciler{}, method: "GET", wantStatus: SuspendedStatus, wantBody: true},
+		{name: "no exemption OPTIONS", reconciler: &NetworkReconciler{}, method: "OPTIONS", wantStatus: SuspendedStatus, wantBody: true},
+		{name: "exempt GET", reconciler: &NetworkReconciler{suspendExemptMethods: []string{"OPTIONS", "HEAD"}}, method: "GET", wantStatus: SuspendedStatus, wantBody: true},
+		{name: "exempt POST", reconciler: &NetworkReconciler{suspendExemptMethods: []string{"OPTIONS", "HEAD"}}, method: "POST", wantStatus: SuspendedStatus, wantBody: true},
+		{name: "exempt OPTIONS default status", reconciler: &NetworkReconciler{suspendExemptMethods: []string{"OPTIONS", "HEAD"}}, method: "OPTIONS", wantStatus: DefaultSuspendExemptStatus},
+		{name: "exempt HEAD custom status", reconciler: &NetworkReconciler{suspendExemptMethods: []string{"OPTIONS", "HEAD"}, suspendExemptStatus: 204}, method: "HEAD", wantStatus: 204},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := matchSuspendRoute(t, tt.reconciler.buildSuspendRoutes(), tt.method)
+			if got := resp["status"]; got != tt.wantStatus {
+				t.Errorf("status = %v, want %d", got, tt.wantStatus)
+			}
+			if _, hasBody := resp["body"]; hasBody != tt.wantBody {
+				t.Errorf("has body = %v, want %v", hasBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestParseSuspendExemptConfig(t *testing.T) {
+	if got := parseSuspendExemptMethods(" options, HEAD,,Options "); !reflect.DeepEqual(got, []string{"OPTIONS", "HEAD"}) {
+		t.Errorf("parseSuspendExemptMethods() = %v, want [OPTIONS HEAD]", got)
+	}
+	if got := parseSuspendExemptMethods(""); got != nil {
+		t.Errorf("parseSuspendExemptMethods(\"\") = %v, want nil", got)
+	}
+
+	tests := []struct {
+		raw  string
+		want int
+	}{
+		{raw: "", want: DefaultSuspendExemptStatus},
+		{raw: "204", want: 204},
+		{raw: "abc", want: DefaultSuspendExemptStatus},
+		{raw: "700", want: DefaultSuspendExemptStatus},
+	}
+	for _, tt := range tests {
+		if got := parseSuspendExemptStatus(tt.raw); got != tt.want {
+			t.Errorf("parseSuspendExemptStatus(%q) = %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func newSuspendedVirtualService(t *testing.T, r *NetworkReconciler, name, namespace, serviceHost string) *unstructured.Unstructured {
+	originalHTTP := []interface{}{
+		map[string]interface{}{
+			"route": []interface{}{
+				map[string]interface{}{
+					"destination": map[string]interface{}{
+						"host": serviceHost,
+						"port": map[string]interface{}{"number": int64(8080)},
+					},
+				},
+			},
+		},
+	}
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	vs.SetName(name)
+	vs.SetNamespace(namespace)
+	vs.SetAnnotations(map[string]string{
+		"network.sealos.io/suspended":     "true",
+		"network.sealos.io/original-http": r.encodeRoutes(originalHTTP),
+	})
+	if err := unstructured.SetNestedSlice(vs.Object, r.buildSuspendRoutes(), "spec", "http"); err != nil {
+		t.Fatalf("failed to set suspend routes: %v", err)
+	}
+	return vs
+}
+
+func TestResumeIstioResources_WaitForEndpoints(t *testing.T) {
+	namespace := "ns-test"
+	tests := []struct {
+		name        string
+		host        string
+		endpoints   *corev1.Endpoints
+		wantPending int
+	}{
+		{name: "no endpoints object", host: "web", wantPending: 1},
+		{
+			name: "endpoints without ready addresses",
+			host: "web",
+			endpoints: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+				Subsets:    []corev1.EndpointSubset{{NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+			},
+			wantPending: 1,
+		},
+		{
+			name: "ready endpoints",
+			host: "web.ns-test.svc.cluster.local",
+			endpoints: &corev1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+				Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &NetworkReconciler{Log: logr.Discard(), resumeWaitForEndpoints: true}
+			vs := newSuspendedVirtualService(t, r, "web", namespace, tt.host)
+			builder := fake.NewClientBuilder().WithScheme(newNetworkTestScheme(t)).WithObjects(vs)
+			if tt.endpoints != nil {
+				builder = builder.WithObjects(tt.endpoints)
+			}
+			r.Client = builder.Build()
+			ctx := context.Background()
+
+			pending, err := r.resumeIstioResources(ctx, namespace)
+			if err != nil {
+				t.Fatalf("resumeIstioResources() error = %v", err)
+			}
+			if pending != tt.wantPending {
+				t.Errorf("resumeIstioResources() pending = %d, want %d", pending, tt.wantPending)
+			}
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(vs.GroupVersionKind())
+			if err := r.Client.Get(ctx, client.ObjectKeyFromObject(vs), got); err != nil {
+				t.Fatalf("failed to get virtual service: %v", err)
+			}
+			routes, _, _ := unstructured.NestedSlice(got.Object, "spec", "http")
+			suspended := got.GetAnnotations()["network.sealos.io/suspended"] == "true"
+			_, hasDirectResponse := routes[len(routes)-1].(map[string]interface{})["directResponse"]
+			if tt.wantPending > 0 {
+				if !suspended || !hasDirectResponse {
+					t.Errorf("suspend route should be retained while backend is not ready, got routes %v", routes)
+				}
+				return
+			}
+			if suspended || hasDirectResponse {
+				t.Errorf("real routes should be applied once backend is ready, got routes %v", routes)
+			}
+		})
+	}
+}
+
+func TestRouteDestinationServices(t *testing.T) {
+	routes := []interface{}{
+		map[string]interface{}{
+			"route": []interface{}{
+				map[string]interface{}{"destination": map[string]interface{}{"host": "web"}},
+				map[string]interface{}{"destination": map[string]interface{}{"host": "api.other-ns.svc.cluster.local"}},
+				map[string]interface{}{"destination": map[string]interface{}{"host": "example.com"}},
+				map[string]interface{}{"destination": map[string]interface{}{"host": "web"}},
+			},
+		},
+	}
+	want := []types.NamespacedName{
+		{Namespace: "ns-test", Name: "web"},
+		{Namespace: "other-ns", Name: "api"},
+	}
+	if got := routeDestinationServices("ns-test", routes); !reflect.DeepEqual(got, want) {
+		t.Errorf("routeDestinationServices() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildIstioNetworkConfig_DefaultCorsPolicy(t *testing.T) {
+	r := &NetworkReconciler{}
+	if config := r.buildIstioNetworkConfig(); config.DefaultCorsPolicy != nil {
+		t.Fatalf("DefaultCorsPolicy = %+v, want nil when ISTIO_DEFAULT_CORS_POLICY is unset", config.DefaultCorsPolicy)
+	}
+
+	t.Setenv("ISTIO_DEFAULT_CORS_POLICY", "true")
+	t.Setenv("ISTIO_DEFAULT_CORS_MAX_AGE", "1h")
+	config := r.buildIstioNetworkConfig()
+	if config.DefaultCorsPolicy == nil {
+		t.Fatal("DefaultCorsPolicy is nil, want it set by ISTIO_DEFAULT_CORS_POLICY")
+	}
+	if len(config.DefaultCorsPolicy.AllowOrigins) != 0 {
+		t.Errorf("AllowOrigins = %v, want empty so origins follow the app and public domains", config.DefaultCorsPolicy.AllowOrigins)
+	}
+	if maxAge := config.DefaultCorsPolicy.MaxAge; maxAge == nil || *maxAge != time.Hour {
+		t.Errorf("MaxAge = %v, want 1h", maxAge)
+	}
+}
+
+func TestBuildIstioNetworkConfig_TraceHeaders(t *testing.T) {
+	t.Setenv("ISTIO_PRESERVE_TRACE_HEADERS", "true")
+	t.Setenv("ISTIO_TRACE_HEADERS", "traceparent, ,x-request-id")
+	t.Setenv("ISTIO_TRACE_SAMPLING_DECISION", "0")
+	config := (&NetworkReconciler{}).buildIstioNetworkConfig()
+	if !config.PreserveTraceHeaders {
+		t.Error("PreserveTraceHeaders = false, want true")
+	}
+	if !reflect.DeepEqual(config.TraceHeaders, []string{"traceparent", "x-request-id"}) {
+		t.Errorf("TraceHeaders = %v, want [traceparent x-request-id]", config.TraceHeaders)
+	}
+	if config.TraceSamplingDecision != "0" {
+		t.Errorf("TraceSamplingDecision = %q, want 0", config.TraceSamplingDecision)
+	}
+
+	// Only 1 and 0 are accepted as sampling decisions
+	t.Setenv("ISTIO_TRACE_SAMPLING_DECISION", "yes")
+	if got := (&NetworkReconciler{}).buildIstioNetworkConfig().TraceSamplingDecision; got != "" {
+		t.Errorf("TraceSamplingDecision = %q, want empty for an invalid value", got)
+	}
+}
+
+func TestBuildIstioNetworkConfig_NamespaceBaseDomains(t *testing.T) {
+	t.Setenv("ISTIO_NAMESPACE_BASE_DOMAINS", "ns-brand-a=brand-a.io")
+	config := (&NetworkReconciler{}).buildIstioNetworkConfig()
+	if want := map[string]string{"ns-brand-a": "brand-a.io"}; !reflect.DeepEqual(config.NamespaceBaseDomains, want) {
+		t.Errorf("NamespaceBaseDomains = %v, want %v", config.NamespaceBaseDomains, want)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var terminallog = logf.Log.WithName("terminal-webhook")
+
+// CustomDomainValidator validates user supplied custom domains, e.g. istio.DomainAllocator.
+type CustomDomainValidator interface {
+	ValidateCustomDomain(ctx context.Context, domain string) error
+}
+
+func (r *Terminal) SetupWebhookWithManager(mgr ctrl.Manager, domainValidator CustomDomainValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&TerminalValidator{DomainValidator: domainValidator}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-terminal-sealos-io-v1-terminal,mutating=false,failurePolicy=fail,sideEffects=None,groups=terminal.sealos.io,resources=terminals,verbs=create;update,versions=v1,name=vterminal.kb.io,admissionReviewVersions=v1
+//+kubebuilder:object:generate=false
+
+// TerminalValidator rejects terminals whose custom domain would never be served.
+type TerminalValidator struct {
+	DomainValidator CustomDomainValidator
+}
+
+var _ webhook.CustomValidator = &TerminalValidator{}
+
+func (v *TerminalValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	terminal, ok := obj.(*Terminal)
+	if !ok {
+		return admission.Warnings{}, errors.New("obj convert Terminal is error")
+	}
+	return admission.Warnings{}, v.validateCustomDomain(ctx, terminal)
+}
+
+func (v *TerminalValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldTerminal, ok := oldObj.(*Terminal)
+	if !ok {
+		return admission.Warnings{}, errors.New("obj convert Terminal is error")
+	}
+	newTerminal, ok := newObj.(*Terminal)
+	if !ok {
+		return admission.Warnings{}, errors.New("obj convert Terminal is error")
+	}
+	// keepalive updates must not fail because of a domain admitted before
+	if oldTerminal.Spec.CustomDomain == newTerminal.Spec.CustomDomain && slices.Equal(oldTerminal.Spec.AdditionalDomains, newTerminal.Spec.AdditionalDomains) {
+		return admission.Warnings{}, nil
+	}
+	return admission.Warnings{}, v.validateCustomDomain(ctx, newTerminal)
+}
+
+func (v *TerminalValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return admission.Warnings{}, nil
+}
+
+func (v *TerminalValidator) validateCustomDomain(ctx context.Context, terminal *Terminal) error {
+	if v.DomainValidator == nil {
+		return nil
+	}
+	domains := terminal.Spec.AdditionalDomains
+	if terminal.Spec.CustomDomain != "" {
+		domains = append([]string{terminal.Spec.CustomDomain}, domains...)
+	}
+	for _, domain := range domains {
+		if err := v.DomainValidator.ValidateCustomDomain(ctx, domain); err != nil {
+			terminallog.Info("reject custom domain", "name", terminal.Name, "namespace", terminal.Namespace, "domain", domain, "reason", err.Error())
+			return fmt.Errorf("invalid custom domain %q: %w", domain, err)
+		}
+	}
+	return nil
+}
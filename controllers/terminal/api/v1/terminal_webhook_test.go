@@ -0,0 +1,64 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestTerminalValidator_CustomDomain(t *testing.T) {
+	config := istio.DefaultNetworkConfig()
+	config.BaseDomain = "cloud.sealos.io"
+	config.SkipDNSValidation = true
+	validator := &TerminalValidator{DomainValidator: istio.NewDomainAllocator(config)}
+
+	tests := []struct {
+		name              string
+		customDomain      string
+		additionalDomains []string
+		wantErr           bool
+	}{
+		{name: "valid custom domain", customDomain: "shell.example.com"},
+		{name: "reserved custom domain", customDomain: "api.example.com", wantErr: true},
+		{name: "no custom domain"},
+		{name: "valid additional domain", additionalDomains: []string{"shell.example.com"}},
+		{name: "reserved additional domain", customDomain: "shell.example.com", additionalDomains: []string{"api.example.com"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminal := &Terminal{
+				ObjectMeta: metav1.ObjectMeta{Name: "terminal", Namespace: "ns-user1"},
+				Spec:       TerminalSpec{CustomDomain: tt.customDomain, AdditionalDomains: tt.additionalDomains},
+			}
+			if _, err := validator.ValidateCreate(context.Background(), terminal); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			updated := terminal.DeepCopy()
+			terminal.Spec.CustomDomain = ""
+			terminal.Spec.AdditionalDomains = nil
+			if _, err := validator.ValidateUpdate(context.Background(), terminal, updated); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/config"
+	"github.com/labring/sealos/controllers/pkg/istio"
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+func TestValidateAppPort(t *testing.T) {
+	tests := []struct {
+		port    int32
+		wantErr bool
+	}{
+		{port: 8080},
+		{port: 1},
+		{port: 65535},
+		{port: 0, wantErr: true},
+		{port: -1, wantErr: true},
+		{port: 65536, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := validateAppPort(tt.port); (err != nil) != tt.wantErr {
+			t.Errorf("validateAppPort(%d) error = %v, wantErr %v", tt.port, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGetAppPort_Default(t *testing.T) {
+	r := &TerminalReconciler{}
+	if port := r.getAppPort(); port != DefaultAppPort {
+		t.Errorf("getAppPort() = %d, want %d", port, DefaultAppPort)
+	}
+}
+
+// TestAppPort_Consistent checks a custom app port reaches the container, the Service and the networking
+func TestAppPort_Consistent(t *testing.T) {
+	const port int32 = 3000
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+	terminal := &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test", UID: "uid-1"},
+		Spec:       terminalv1.TerminalSpec{User: "test", Token: "token"},
+		Status:     terminalv1.TerminalStatus{ServiceName: "test-terminal-svc", SecretHeader: "X-SEALOS-ABCDE"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terminal).WithStatusSubresource(terminal).Build()
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	r := &TerminalReconciler{
+		Client:           c,
+		Scheme:           scheme,
+		CtrConfig:        &Config{Global: config.Global{CloudDomain: "cloud.sealos.io"}},
+		recorder:         record.NewFakeRecorder(10),
+		useIstio:         true,
+		istioHelper:      istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, istioConfig, "terminal"),
+		istioReconciler:  NewIstioNetworkingReconciler(c, istioConfig),
+		hostnameAlphabet: LetterBytes,
+		hostnameLength:   HostnameLength,
+		appPort:          port,
+	}
+	r.istioReconciler.servicePort = r.getAppPort()
+	ctx := context.Background()
+	recLabels := map[string]string{"app.kubernetes.io/name": terminal.Name}
+
+	var hostname string
+	if err := r.syncDeployment(ctx, terminal, &hostname, recLabels); err != nil {
+		t.Fatalf("syncDeployment() error = %v", err)
+	}
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, client.ObjectKey{Name: terminal.Name, Namespace: terminal.Namespace}, deployment); err != nil {
+		t.Fatalf("get deployment: %v", err)
+	}
+	if got := deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort; got != port {
+		t.Errorf("container port = %d, want %d", got, port)
+	}
+
+	if err := r.syncService(ctx, terminal, recLabels); err != nil {
+		t.Fatalf("syncService() error = %v", err)
+	}
+	service := &corev1.Service{}
+	if err := c.Get(ctx, client.ObjectKey{Name: terminal.Status.ServiceName, Namespace: terminal.Namespace}, service); err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if got := service.Spec.Ports[0]; got.Port != port || got.TargetPort.IntVal != port {
+		t.Errorf("service port = %d -> %s, want %d -> %d", got.Port, got.TargetPort.String(), port, port)
+	}
+
+	if err := r.syncNetworking(ctx, terminal, hostname, recLabels); err != nil {
+		t.Fatalf("syncNetworking() error = %v", err)
+	}
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	if err := c.Get(ctx, client.ObjectKey{Name: terminal.Name + "-vs", Namespace: terminal.Namespace}, vs); err != nil {
+		t.Fatalf("get virtualservice: %v", err)
+	}
+	httpRoutes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if len(httpRoutes) == 0 {
+		t.Fatalf("virtualservice has no http routes")
+	}
+	routes, _, _ := unstructured.NestedSlice(httpRoutes[0].(map[string]interface{}), "route")
+	if len(routes) == 0 {
+		t.Fatalf("virtualservice http route has no destinations")
+	}
+	if got, _, _ := unstructured.NestedInt64(routes[0].(map[string]interface{}), "destination", "port", "number"); got != int64(port) {
+		t.Errorf("virtualservice destination port = %d, want %d", got, port)
+	}
+
+	if got := r.istioReconciler.buildNetworkingSpec(terminal, hostname).ServicePort; got != port {
+		t.Errorf("networking spec service port = %d, want %d", got, port)
+	}
+	if got := r.createNginxIngress(terminal, "test.cloud.sealos.io").Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number; got != port {
+		t.Errorf("ingress backend port = %d, want %d", got, port)
+	}
+}
@@ -25,4 +25,18 @@ type Config struct {
 
 type TerminalConfig struct {
 	IngressTLSSecretName string `yaml:"ingressTLSSecretName"`
+	// HostnameAlphabet is the nanoid alphabet of generated hostnames, defaults to LetterBytes
+	HostnameAlphabet string `yaml:"hostnameAlphabet"`
+	// HostnameLength is the nanoid length of generated hostnames, defaults to HostnameLength
+	HostnameLength int `yaml:"hostnameLength"`
+	// TerminationGracePeriodSeconds is how long terminating tty pods may keep serving sessions,
+	// defaults to DefaultTerminationGracePeriodSeconds
+	TerminationGracePeriodSeconds int64 `yaml:"terminationGracePeriodSeconds"`
+	// SessionDrainPath is the tty image endpoint called by the preStop hook to drain sessions, empty disables draining
+	SessionDrainPath string `yaml:"sessionDrainPath"`
+	// AppPort is the port the tty image listens on, used by the container, the Service and the networking,
+	// defaults to DefaultAppPort
+	AppPort int32 `yaml:"appPort"`
+	// MaxInstancesPerNamespace caps the terminals started in one namespace, 0 disables the limit
+	MaxInstancesPerNamespace int `yaml:"maxInstancesPerNamespace"`
 }
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/labring/sealos/controllers/pkg/istio"
 	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
@@ -184,4 +185,24 @@ func findSubstring(s, substr string) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+func TestBuildIstioNetworkConfig_DefaultCorsPolicy(t *testing.T) {
+	t.Setenv("ISTIO_BASE_DOMAIN", "cloud.sealos.io")
+	r := &TerminalReconciler{}
+	if config := r.buildIstioNetworkConfig(); config.DefaultCorsPolicy != nil {
+		t.Fatalf("DefaultCorsPolicy = %+v, want nil when ISTIO_DEFAULT_CORS_POLICY is unset", config.DefaultCorsPolicy)
+	}
+
+	t.Setenv("ISTIO_DEFAULT_CORS_POLICY", "true")
+	t.Setenv("ISTIO_DEFAULT_CORS_MAX_AGE", "1h")
+	config := r.buildIstioNetworkConfig()
+	if config.DefaultCorsPolicy == nil {
+		t.Fatal("DefaultCorsPolicy is nil, want it set by ISTIO_DEFAULT_CORS_POLICY")
+	}
+	if len(config.DefaultCorsPolicy.AllowOrigins) != 0 {
+		t.Errorf("AllowOrigins = %v, want empty so origins follow the app and public domains", config.DefaultCorsPolicy.AllowOrigins)
+	}
+	if maxAge := config.DefaultCorsPolicy.MaxAge; maxAge == nil || *maxAge != time.Hour {
+		t.Errorf("MaxAge = %v, want 1h", maxAge)
+	}
+}
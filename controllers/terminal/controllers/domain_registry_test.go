@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/istio"
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+func TestReconcile_ReleasesGeneratedDomainOnDelete(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+	now := metav1.NewTime(time.Now())
+	terminal := &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test-terminal",
+			Namespace:         "ns-tenant-a",
+			Finalizers:        []string{FinalizerName},
+			DeletionTimestamp: &now,
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terminal).Build()
+
+	registry := istio.NewInMemoryDomainRegistry()
+	config := &istio.NetworkConfig{BaseDomain: "cloud.sealos.io", DefaultGateway: "istio-system/sealos-gateway", DomainRegistry: registry}
+	helper := istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, config, "terminal")
+	generated := helper.GetOptimalDomain(&istio.AppNetworkingParams{Name: terminal.Name, Namespace: terminal.Namespace, AppType: "terminal"})
+	ctx := context.Background()
+	if _, err := istio.NewDomainAllocator(config).ClaimAppDomain(ctx, "tenant-a", "terminal-"+terminal.Name); err != nil {
+		t.Fatalf("ClaimAppDomain() error = %v", err)
+	}
+	if _, ok, _ := registry.Owner(generated); !ok {
+		t.Fatalf("domain %s should be claimed before the terminal is deleted", generated)
+	}
+
+	r := &TerminalReconciler{
+		Client:      c,
+		Scheme:      scheme,
+		recorder:    record.NewFakeRecorder(10),
+		useIstio:    true,
+		istioHelper: helper,
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(terminal)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if owner, ok, _ := registry.Owner(generated); ok {
+		t.Errorf("domain %s is still claimed by %s after the terminal was deleted", generated, owner)
+	}
+}
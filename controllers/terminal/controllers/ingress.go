@@ -72,7 +72,7 @@ Authorization ""
 			Service: &networkingv1.IngressServiceBackend{
 				Name: terminal.Status.ServiceName,
 				Port: networkingv1.ServiceBackendPort{
-					Number: 8080,
+					Number: r.getAppPort(),
 				},
 			},
 		},
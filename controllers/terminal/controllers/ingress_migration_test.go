@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/config"
+	"github.com/labring/sealos/controllers/pkg/istio"
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+func TestSyncNetworking_SwitchIngressToIstio(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+	terminal := &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test", UID: "uid-1"},
+		Spec:       terminalv1.TerminalSpec{IngressType: terminalv1.Nginx},
+		Status:     terminalv1.TerminalStatus{ServiceName: "test-terminal-svc"},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-terminal-svc", Namespace: "ns-test"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terminal, svc).WithStatusSubresource(terminal).Build()
+	r := &TerminalReconciler{
+		Client:    c,
+		Scheme:    scheme,
+		CtrConfig: &Config{Global: config.Global{CloudDomain: "cloud.sealos.io"}},
+		recorder:  record.NewFakeRecorder(10),
+	}
+	ctx := context.Background()
+	recLabels := map[string]string{"app.kubernetes.io/name": terminal.Name}
+
+	// Ingress 模式下创建的应用
+	if err := r.syncNetworking(ctx, terminal, "abcdef", recLabels); err != nil {
+		t.Fatalf("syncNetworking() in ingress mode error = %v", err)
+	}
+	ingressKey := client.ObjectKey{Name: terminal.Name, Namespace: terminal.Namespace}
+	if err := c.Get(ctx, ingressKey, &networkingv1.Ingress{}); err != nil {
+		t.Fatalf("ingress should be created in ingress mode: %v", err)
+	}
+	wantDomain := terminal.Status.Domain
+	if wantDomain != "https://abcdef.cloud.sealos.io" {
+		t.Fatalf("domain in ingress mode = %s", wantDomain)
+	}
+
+	// 集群启用 Istio 后原地切换
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:           "cloud.sealos.io",
+		DefaultGateway:       "istio-system/sealos-gateway",
+		SharedGatewayEnabled: true,
+		PublicDomains:        []string{"cloud.sealos.io"},
+		PublicDomainPatterns: []string{"*.cloud.sealos.io"},
+	}
+	r.useIstio = true
+	r.istioHelper = istio.NewUniversalIstioNetworkingHelperWithScheme(c, scheme, istioConfig, "terminal")
+	r.istioReconciler = NewIstioNetworkingReconciler(c, istioConfig)
+	if err := r.syncNetworking(ctx, terminal, "abcdef", recLabels); err != nil {
+		t.Fatalf("syncNetworking() in istio mode error = %v", err)
+	}
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	if err := c.Get(ctx, client.ObjectKey{Name: terminal.Name + "-vs", Namespace: terminal.Namespace}, vs); err != nil {
+		t.Fatalf("virtualservice should be created after switching to istio: %v", err)
+	}
+	if hosts, _, _ := unstructured.NestedStringSlice(vs.Object, "spec", "hosts"); len(hosts) != 1 || hosts[0] != "abcdef.cloud.sealos.io" {
+		t.Errorf("virtualservice hosts = %v, want the ingress hostname", hosts)
+	}
+	if err := c.Get(ctx, ingressKey, &networkingv1.Ingress{}); !errors.IsNotFound(err) {
+		t.Errorf("legacy ingress should be removed, get error = %v", err)
+	}
+	if terminal.Status.Domain != wantDomain {
+		t.Errorf("domain = %s, want unchanged %s", terminal.Status.Domain, wantDomain)
+	}
+}
+
+func TestRemoveLegacyIngress_KeepsUnownedIngress(t *testing.T) {
+	terminal := &terminalv1.Terminal{ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test", UID: "uid-1"}}
+	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: terminal.Name, Namespace: terminal.Namespace}}
+	r := newSecretHeaderTestReconciler(t, terminal, ingress)
+
+	if err := r.removeLegacyIngress(context.Background(), terminal); err != nil {
+		t.Fatalf("removeLegacyIngress() error = %v", err)
+	}
+	if err := r.Get(context.Background(), client.ObjectKeyFromObject(ingress), &networkingv1.Ingress{}); err != nil {
+		t.Errorf("ingress not controlled by the terminal should be kept: %v", err)
+	}
+}
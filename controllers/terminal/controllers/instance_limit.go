@@ -0,0 +1,111 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+const (
+	// ConditionTypeAdmitted reports whether the terminal was admitted under the per-namespace instance limit
+	ConditionTypeAdmitted = "Admitted"
+	// ReasonWithinInstanceLimit means the terminal is admitted and its resources are created
+	ReasonWithinInstanceLimit = "WithinInstanceLimit"
+	// ReasonInstanceLimitExceeded means older terminals already fill the namespace limit, nothing is created
+	ReasonInstanceLimitExceeded = "InstanceLimitExceeded"
+
+	// instanceLimitRequeueInterval is how often a refused terminal is rechecked, so it starts once older ones are deleted
+	instanceLimitRequeueInterval = time.Minute
+)
+
+// admitInstance checks the terminal against the per-namespace instance limit and records the result as the
+// Admitted condition. Older terminals are admitted first and an admitted terminal stays admitted, so lowering
+// the limit never refuses terminals that are already running.
+func (r *TerminalReconciler) admitInstance(ctx context.Context, terminal *terminalv1.Terminal) (bool, error) {
+	if r.maxInstancesPerNamespace <= 0 {
+		return true, nil
+	}
+	if meta.IsStatusConditionTrue(terminal.Status.Conditions, ConditionTypeAdmitted) {
+		return true, nil
+	}
+
+	terminals := &terminalv1.TerminalList{}
+	if err := r.List(ctx, terminals, client.InNamespace(terminal.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list terminals in namespace %s: %w", terminal.Namespace, err)
+	}
+	older := 0
+	for i := range terminals.Items {
+		other := &terminals.Items[i]
+		if other.UID != terminal.UID && other.DeletionTimestamp.IsZero() && createdBefore(other, terminal) {
+			older++
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeAdmitted,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonWithinInstanceLimit,
+		Message:            fmt.Sprintf("within the limit of %d terminals per namespace", r.maxInstancesPerNamespace),
+		ObservedGeneration: terminal.Generation,
+	}
+	if older >= r.maxInstancesPerNamespace {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonInstanceLimitExceeded
+		condition.Message = fmt.Sprintf("namespace %s already has %d terminals, the limit is %d", terminal.Namespace, older, r.maxInstancesPerNamespace)
+	}
+
+	admitted := condition.Status == metav1.ConditionTrue
+	if existing := meta.FindStatusCondition(terminal.Status.Conditions, ConditionTypeAdmitted); existing != nil &&
+		existing.Status == condition.Status && existing.Message == condition.Message {
+		return admitted, nil
+	}
+
+	if !admitted {
+		r.recorder.Eventf(terminal, corev1.EventTypeWarning, ReasonInstanceLimitExceeded, "%s", condition.Message)
+	}
+	if err := retryStatusUpdateOnConflict(ctx, r.Client, terminal, func() {
+		meta.SetStatusCondition(&terminal.Status.Conditions, condition)
+	}); err != nil {
+		return false, err
+	}
+	return admitted, nil
+}
+
+// createdBefore orders terminals by creation time, falling back to the name for terminals created in the same second
+func createdBefore(a, b *terminalv1.Terminal) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// validateMaxInstancesPerNamespace checks the configured instance limit, 0 disables the limit
+func validateMaxInstancesPerNamespace(limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("maxInstancesPerNamespace must not be negative, got %d", limit)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/config"
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+func newInstanceLimitTestTerminal(name, namespace string, created time.Time) *terminalv1.Terminal {
+	return &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			UID:               types.UID("uid-" + namespace + "-" + name),
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       map[string]string{KeepaliveAnnotation: time.Now().Format(time.RFC3339)},
+			Finalizers:        []string{FinalizerName},
+		},
+		Spec: terminalv1.TerminalSpec{
+			User:       "test",
+			Token:      "token",
+			APIServer:  "https://apiserver.local",
+			Keepalived: "1h",
+			TTYImage:   "tty:latest",
+			Replicas:   new(int32),
+		},
+	}
+}
+
+// TestReconcile_InstanceLimit checks terminals past the per-namespace limit are refused without creating a Deployment
+func TestReconcile_InstanceLimit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		limit        int
+		existing     int
+		wantAdmitted bool
+	}{
+		{name: "under the limit", limit: 3, existing: 2, wantAdmitted: true},
+		{name: "at the limit", limit: 2, existing: 2},
+		{name: "no limit", existing: 5, wantAdmitted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now().Truncate(time.Second)
+			objects := make([]client.Object, 0, tt.existing+2)
+			for i := 0; i < tt.existing; i++ {
+				objects = append(objects, newInstanceLimitTestTerminal(fmt.Sprintf("older-%d", i), "ns-test", now.Add(-time.Hour)))
+			}
+			// terminals in other namespaces do not count towards the limit
+			objects = append(objects, newInstanceLimitTestTerminal("other", "ns-other", now.Add(-time.Hour)))
+			terminal := newInstanceLimitTestTerminal("new", "ns-test", now)
+			objects = append(objects, terminal)
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&terminalv1.Terminal{}).Build()
+			r := &TerminalReconciler{
+				Client:                   c,
+				Scheme:                   scheme,
+				Config:                   &rest.Config{Host: "https://apiserver.local"},
+				CtrConfig:                &Config{Global: config.Global{CloudDomain: "cloud.sealos.io"}},
+				recorder:                 record.NewFakeRecorder(10),
+				hostnameAlphabet:         LetterBytes,
+				hostnameLength:           HostnameLength,
+				maxInstancesPerNamespace: tt.limit,
+			}
+			ctx := context.Background()
+			key := client.ObjectKeyFromObject(terminal)
+
+			result, _ := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+
+			err := c.Get(ctx, key, &appsv1.Deployment{})
+			if tt.wantAdmitted && err != nil {
+				t.Errorf("admitted terminal should get a deployment, get error = %v", err)
+			}
+			if !tt.wantAdmitted {
+				if !errors.IsNotFound(err) {
+					t.Errorf("refused terminal should not get a deployment, get error = %v", err)
+				}
+				if result.RequeueAfter != instanceLimitRequeueInterval {
+					t.Errorf("refused terminal requeue after %v, want %v", result.RequeueAfter, instanceLimitRequeueInterval)
+				}
+			}
+
+			got := &terminalv1.Terminal{}
+			if err := c.Get(ctx, key, got); err != nil {
+				t.Fatalf("get terminal: %v", err)
+			}
+			condition := meta.FindStatusCondition(got.Status.Conditions, ConditionTypeAdmitted)
+			if tt.limit == 0 {
+				if condition != nil {
+					t.Errorf("terminal without a limit should have no admitted condition, got %v", condition)
+				}
+				return
+			}
+			if condition == nil {
+				t.Fatalf("terminal should have the %s condition", ConditionTypeAdmitted)
+			}
+			if admitted := condition.Status == metav1.ConditionTrue; admitted != tt.wantAdmitted {
+				t.Errorf("admitted condition = %s (%s), want admitted %v", condition.Status, condition.Message, tt.wantAdmitted)
+			}
+			if !tt.wantAdmitted && condition.Reason != ReasonInstanceLimitExceeded {
+				t.Errorf("condition reason = %s, want %s", condition.Reason, ReasonInstanceLimitExceeded)
+			}
+		})
+	}
+}
+
+// TestAdmitInstance_AdmittedStaysAdmitted checks lowering the limit does not refuse a running terminal
+func TestAdmitInstance_AdmittedStaysAdmitted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+	now := time.Now()
+	terminal := newInstanceLimitTestTerminal("running", "ns-test", now)
+	meta.SetStatusCondition(&terminal.Status.Conditions, metav1.Condition{Type: ConditionTypeAdmitted, Status: metav1.ConditionTrue, Reason: ReasonWithinInstanceLimit})
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newInstanceLimitTestTerminal("older", "ns-test", now.Add(-time.Hour)),
+		terminal,
+	).WithStatusSubresource(&terminalv1.Terminal{}).Build()
+	r := &TerminalReconciler{Client: c, recorder: record.NewFakeRecorder(10), maxInstancesPerNamespace: 1}
+
+	admitted, err := r.admitInstance(context.Background(), terminal)
+	if err != nil {
+		t.Fatalf("admitInstance() error = %v", err)
+	}
+	if !admitted {
+		t.Errorf("admitInstance() = false, want an admitted terminal to stay admitted")
+	}
+}
+
+func TestValidateMaxInstancesPerNamespace(t *testing.T) {
+	for limit, wantErr := range map[int]bool{0: false, 10: false, -1: true} {
+		if err := validateMaxInstancesPerNamespace(limit); (err != nil) != wantErr {
+			t.Errorf("validateMaxInstancesPerNamespace(%d) error = %v, wantErr %v", limit, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/config"
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+// TestEnableIstioMode_ConcurrentWithReconcile switches to Istio mode while Reconcile workers are running,
+// run with -race to check that the Istio state is published safely.
+func TestEnableIstioMode_ConcurrentWithReconcile(t *testing.T) {
+	t.Setenv("USE_ISTIO", "true")
+	t.Setenv("ISTIO_BASE_DOMAIN", "cloud.sealos.io")
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+	terminal := &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-terminal",
+			Namespace:   "ns-test",
+			UID:         "uid-1",
+			Annotations: map[string]string{KeepaliveAnnotation: time.Now().Format(time.RFC3339)},
+		},
+		Spec: terminalv1.TerminalSpec{Replicas: new(int32), APIServer: "https://apiserver.cloud.sealos.io:6443", Keepalived: "1h"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terminal).WithStatusSubresource(terminal).Build()
+	r := &TerminalReconciler{
+		Client:    c,
+		Scheme:    scheme,
+		CtrConfig: &Config{Global: config.Global{CloudDomain: "cloud.sealos.io"}},
+		recorder:  record.NewFakeRecorder(100),
+	}
+
+	ctx := context.Background()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: terminal.Name, Namespace: terminal.Namespace}}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				_, _ = r.Reconcile(ctx, req)
+			}
+		}()
+	}
+	if err := r.EnableIstioMode(ctx); err != nil {
+		t.Fatalf("EnableIstioMode() error = %v", err)
+	}
+	wg.Wait()
+
+	if !r.IsIstioEnabled() {
+		t.Fatal("IsIstioEnabled() = false after EnableIstioMode")
+	}
+	if r.istioHelper == nil || r.istioReconciler == nil {
+		t.Error("Istio components should be published together with useIstio")
+	}
+}
@@ -32,6 +32,8 @@ type IstioNetworkingReconciler struct {
 	client.Client
 	networkingManager istio.NetworkingManager
 	config            *istio.NetworkConfig
+	// servicePort is the tty Service port, empty means DefaultAppPort
+	servicePort int32
 }
 
 // NewIstioNetworkingReconciler 创建新的 Istio 网络协调器（使用优化管理器）
@@ -73,6 +75,14 @@ func (r *IstioNetworkingReconciler) DeleteIstioNetworking(ctx context.Context, t
 	return r.networkingManager.DeleteAppNetworking(ctx, terminal.Name, terminal.Namespace)
 }
 
+// getServicePort 返回 Terminal Service 的端口，未设置时使用 DefaultAppPort
+func (r *IstioNetworkingReconciler) getServicePort() int32 {
+	if r.servicePort > 0 {
+		return r.servicePort
+	}
+	return DefaultAppPort
+}
+
 // buildNetworkingSpec 构建 Terminal 的网络配置规范
 func (r *IstioNetworkingReconciler) buildNetworkingSpec(terminal *terminalv1.Terminal, hostname string) *istio.AppNetworkingSpec {
 	// 构建域名
@@ -89,7 +99,7 @@ func (r *IstioNetworkingReconciler) buildNetworkingSpec(terminal *terminalv1.Ter
 		Protocol:     istio.ProtocolWebSocket,
 		Hosts:        []string{domain},
 		ServiceName:  terminal.Status.ServiceName,
-		ServicePort:  8080,
+		ServicePort:  r.getServicePort(),
 		SecretHeader: terminal.Status.SecretHeader,
 
 		// WebSocket 专用配置
@@ -229,8 +239,7 @@ func (r *IstioNetworkingReconciler) buildCorsOrigins() []string {
 func (r *IstioNetworkingReconciler) buildSecurityResponseHeaders() map[string]string {
 	headers := make(map[string]string)
 
-	// 设置 X-Frame-Options，防止点击劫持
-	headers["X-Frame-Options"] = ""
+	// 不设置 X-Frame-Options：终端通过 iframe 嵌入在桌面中（跨子域名），SAMEORIGIN 会导致无法加载
 
 	// 设置 X-Content-Type-Options，防止 MIME 类型嗅探
 	headers["X-Content-Type-Options"] = "nosniff"
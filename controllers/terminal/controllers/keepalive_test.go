@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+// TestReconcile_KeepaliveExpiry drives the keepalive with a fake clock: the timestamp comes from the
+// reconciler's clock and the terminal is deleted once the clock passes the keepalive duration
+func TestReconcile_KeepaliveExpiry(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	fakeClock := clocktesting.NewFakeClock(start)
+	terminal := newInstanceLimitTestTerminal("terminal", "ns-test", start)
+	delete(terminal.Annotations, KeepaliveAnnotation)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terminal).Build()
+	r := &TerminalReconciler{
+		Client:   c,
+		Scheme:   scheme,
+		recorder: record.NewFakeRecorder(10),
+		clock:    fakeClock,
+	}
+	ctx := context.Background()
+
+	if err := r.fillDefaultValue(ctx, terminal); err != nil {
+		t.Fatalf("fillDefaultValue() error = %v", err)
+	}
+	if got, want := terminal.Annotations[KeepaliveAnnotation], start.Format(time.RFC3339); got != want {
+		t.Fatalf("keepalive annotation = %q, want the fake clock time %q", got, want)
+	}
+
+	fakeClock.Step(59 * time.Minute)
+	if isExpired(terminal, r.now()) {
+		t.Fatalf("terminal expired before its keepalive of %s", terminal.Spec.Keepalived)
+	}
+
+	fakeClock.Step(2 * time.Minute)
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(terminal)}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	got := &terminalv1.Terminal{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(terminal), got); err != nil {
+		t.Fatalf("failed to get terminal: %v", err)
+	}
+	if got.DeletionTimestamp.IsZero() {
+		t.Errorf("expired terminal should be deleted")
+	}
+}
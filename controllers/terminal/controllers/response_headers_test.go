@@ -49,7 +49,6 @@ func TestTerminalSecurityResponseHeaders(t *testing.T) {
 
 		// Check for security headers
 		expectedHeaders := map[string]string{
-			"X-Frame-Options":         "",
 			"X-Content-Type-Options":  "nosniff",
 			"X-XSS-Protection":        "1; mode=block",
 			"Referrer-Policy":         "strict-origin-when-cross-origin",
@@ -63,6 +62,9 @@ func TestTerminalSecurityResponseHeaders(t *testing.T) {
 				t.Errorf("Security header %s should be '%s', got '%s'", key, expectedValue, actualValue)
 			}
 		}
+		if _, exists := headers["X-Frame-Options"]; exists {
+			t.Error("X-Frame-Options should not be set for terminal")
+		}
 	})
 
 	t.Run("IstioNetworkingReconciler SecurityHeaders", func(t *testing.T) {
@@ -90,7 +92,6 @@ func TestTerminalSecurityResponseHeaders(t *testing.T) {
 
 		// Check for security headers
 		expectedHeaders := []string{
-			"X-Frame-Options",
 			"X-Content-Type-Options",
 			"X-XSS-Protection",
 			"Referrer-Policy",
@@ -103,6 +104,11 @@ func TestTerminalSecurityResponseHeaders(t *testing.T) {
 			}
 		}
 
+		// X-Frame-Options would block embedding the terminal in the desktop iframe
+		if _, exists := spec.ResponseHeaders["X-Frame-Options"]; exists {
+			t.Error("X-Frame-Options should not be set for terminal")
+		}
+
 		// Verify CSP contains WebSocket support
 		csp := spec.ResponseHeaders["Content-Security-Policy"]
 		if csp == "" {
@@ -193,3 +199,46 @@ func TestTerminalGatewaySelection(t *testing.T) {
 		}
 	})
 }
+
+func TestSetTerminalNetworkingStatus(t *testing.T) {
+	istioConfig := &istio.NetworkConfig{
+		BaseDomain:     "cloud.sealos.io",
+		DefaultGateway: "istio-system/sealos-gateway",
+		PublicDomains:  []string{"cloud.sealos.io"},
+	}
+	helper := istio.NewUniversalIstioNetworkingHelper(nil, istioConfig, "terminal")
+
+	tests := []struct {
+		name        string
+		host        string
+		wantType    string
+		wantGateway string
+	}{
+		{name: "public domain", host: "test-terminal.cloud.sealos.io", wantType: istio.DomainTypePublic, wantGateway: "istio-system/sealos-gateway"},
+		{name: "custom domain", host: "terminal.custom.example.com", wantType: istio.DomainTypeCustom, wantGateway: "test-namespace/test-terminal-gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := helper.AnalyzeDomainRequirements(&istio.AppNetworkingParams{
+				Name:      "test-terminal",
+				Namespace: "test-namespace",
+				AppType:   "terminal",
+				Hosts:     []string{tt.host},
+			})
+			terminal := &terminalv1.Terminal{}
+			setTerminalNetworkingStatus(terminal, "https://"+tt.host, analysis)
+
+			if terminal.Status.DomainType != tt.wantType {
+				t.Errorf("status.domainType = %s, want %s", terminal.Status.DomainType, tt.wantType)
+			}
+			if terminal.Status.GatewayReference != tt.wantGateway {
+				t.Errorf("status.gatewayReference = %s, want %s", terminal.Status.GatewayReference, tt.wantGateway)
+			}
+			// 注解保留用于向后兼容
+			if terminal.Annotations["sealos.io/domain-type"] != terminal.Status.DomainType ||
+				terminal.Annotations["sealos.io/gateway-reference"] != terminal.Status.GatewayReference {
+				t.Errorf("annotations %v do not match status %+v", terminal.Annotations, terminal.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newSecretHeaderTestReconciler(t *testing.T, objects ...client.Object) *TerminalReconciler {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add client-go scheme: %v", err)
+	}
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+
+	return &TerminalReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		Scheme:   scheme,
+		recorder: record.NewFakeRecorder(10),
+	}
+}
+
+func TestSyncDeployment_CorrectsDesyncedAuthHeader(t *testing.T) {
+	terminal := &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test", UID: "uid-1"},
+		Spec:       terminalv1.TerminalSpec{TTYImage: "tty:latest"},
+		Status:     terminalv1.TerminalStatus{SecretHeader: "X-SEALOS-NEW01"},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: terminal.Name, Namespace: terminal.Namespace},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Hostname: "tabcdefgh",
+					Containers: []corev1.Container{
+						{Name: "sidecar", Image: "sidecar:latest"},
+						{
+							Name:  TTYContainerName,
+							Image: "tty:latest",
+							Env:   []corev1.EnvVar{{Name: AuthHeaderEnvName, Value: "X-SEALOS-OLD01"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	r := newSecretHeaderTestReconciler(t, terminal, deployment)
+	ctx := context.Background()
+
+	var hostname string
+	if err := r.syncDeployment(ctx, terminal, &hostname, map[string]string{"app": terminal.Name}); err != nil {
+		t.Fatalf("syncDeployment() error = %v", err)
+	}
+
+	got := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(deployment), got); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if value, ok := getAuthHeaderEnv(&got.Spec.Template.Spec); !ok || value != terminal.Status.SecretHeader {
+		t.Errorf("AUTH_HEADER = %q (found %v), want %q", value, ok, terminal.Status.SecretHeader)
+	}
+	if sidecar := got.Spec.Template.Spec.Containers[0]; sidecar.Name != "sidecar" || len(sidecar.Env) != 0 {
+		t.Errorf("sidecar container should be untouched, got %+v", sidecar)
+	}
+	if hostname != "tabcdefgh" {
+		t.Errorf("hostname = %q, want existing hostname preserved", hostname)
+	}
+
+	recorder := r.recorder.(*record.FakeRecorder)
+	select {
+	case e := <-recorder.Events:
+		if e == "" {
+			t.Error("expected SecretHeaderSynced event")
+		}
+	default:
+		t.Error("expected SecretHeaderSynced event to be recorded")
+	}
+}
+
+func TestSyncDeployment_AuthHeaderInSync(t *testing.T) {
+	terminal := &terminalv1.Terminal{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test", UID: "uid-1"},
+		Spec:       terminalv1.TerminalSpec{TTYImage: "tty:latest"},
+		Status:     terminalv1.TerminalStatus{SecretHeader: "X-SEALOS-SAME1"},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: terminal.Name, Namespace: terminal.Namespace},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Hostname: "tabcdefgh",
+					Containers: []corev1.Container{{
+						Name: TTYContainerName,
+						Env:  []corev1.EnvVar{{Name: AuthHeaderEnvName, Value: "X-SEALOS-SAME1"}},
+					}},
+				},
+			},
+		},
+	}
+
+	r := newSecretHeaderTestReconciler(t, terminal, deployment)
+	var hostname string
+	if err := r.syncDeployment(context.Background(), terminal, &hostname, map[string]string{"app": terminal.Name}); err != nil {
+		t.Fatalf("syncDeployment() error = %v", err)
+	}
+
+	recorder := r.recorder.(*record.FakeRecorder)
+	select {
+	case e := <-recorder.Events:
+		t.Errorf("unexpected event %q for in-sync deployment", e)
+	default:
+	}
+}
+
+func TestSecretHeaderChangedPredicate(t *testing.T) {
+	p := secretHeaderChangedPredicate()
+	oldTerminal := &terminalv1.Terminal{Status: terminalv1.TerminalStatus{SecretHeader: "X-SEALOS-A"}}
+
+	tests := []struct {
+		name     string
+		header   string
+		expected bool
+	}{
+		{name: "secret header edited", header: "X-SEALOS-B", expected: true},
+		{name: "secret header unchanged", header: "X-SEALOS-A", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTerminal := oldTerminal.DeepCopy()
+			newTerminal.Status.SecretHeader = tt.header
+			if got := p.Update(event.UpdateEvent{ObjectOld: oldTerminal, ObjectNew: newTerminal}); got != tt.expected {
+				t.Errorf("Update() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestSyncDeployment_SessionDraining(t *testing.T) {
+	tests := []struct {
+		name            string
+		gracePeriod     int64
+		drainPath       string
+		existing        bool
+		wantGracePeriod int64
+	}{
+		{name: "defaults", wantGracePeriod: DefaultTerminationGracePeriodSeconds},
+		{name: "configured grace period and drain endpoint", gracePeriod: 600, drainPath: "/drain", wantGracePeriod: 600},
+		{name: "existing deployment is updated", gracePeriod: 300, drainPath: "/drain", existing: true, wantGracePeriod: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminal := &terminalv1.Terminal{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test", UID: "uid-1"},
+				Spec:       terminalv1.TerminalSpec{TTYImage: "tty:latest"},
+			}
+			objects := []client.Object{terminal}
+			if tt.existing {
+				objects = append(objects, &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: terminal.Name, Namespace: terminal.Namespace},
+					Spec: appsv1.DeploymentSpec{
+						Template: corev1.PodTemplateSpec{
+							Spec: corev1.PodSpec{
+								Hostname:   "tabcdefgh",
+								Containers: []corev1.Container{{Name: TTYContainerName, Image: "tty:latest"}},
+							},
+						},
+					},
+				})
+			}
+			r := newSecretHeaderTestReconciler(t, objects...)
+			r.hostnameAlphabet, r.hostnameLength = LetterBytes, HostnameLength
+			r.terminationGracePeriodSeconds = tt.gracePeriod
+			r.sessionDrainPath = tt.drainPath
+			ctx := context.Background()
+
+			var hostname string
+			if err := r.syncDeployment(ctx, terminal, &hostname, map[string]string{"app": terminal.Name}); err != nil {
+				t.Fatalf("syncDeployment() error = %v", err)
+			}
+
+			got := &appsv1.Deployment{}
+			if err := r.Get(ctx, client.ObjectKey{Name: terminal.Name, Namespace: terminal.Namespace}, got); err != nil {
+				t.Fatalf("failed to get deployment: %v", err)
+			}
+			podSpec := got.Spec.Template.Spec
+			if podSpec.TerminationGracePeriodSeconds == nil || *podSpec.TerminationGracePeriodSeconds != tt.wantGracePeriod {
+				t.Errorf("terminationGracePeriodSeconds = %v, want %d", podSpec.TerminationGracePeriodSeconds, tt.wantGracePeriod)
+			}
+
+			lifecycle := podSpec.Containers[ttyContainerIndex(&podSpec)].Lifecycle
+			if lifecycle == nil || lifecycle.PreStop == nil {
+				t.Fatalf("tty container has no preStop hook: %+v", lifecycle)
+			}
+			preStop := lifecycle.PreStop
+			if tt.drainPath == "" {
+				if preStop.Exec == nil || len(preStop.Exec.Command) == 0 {
+					t.Errorf("preStop = %+v, want a sleep command without drain endpoint", preStop)
+				}
+				return
+			}
+			if preStop.HTTPGet == nil {
+				t.Fatalf("preStop = %+v, want the drain endpoint", preStop)
+			}
+			if preStop.HTTPGet.Path != tt.drainPath || preStop.HTTPGet.Port != intstr.FromString("http") {
+				t.Errorf("preStop httpGet = %s on %s, want %s on the http port", preStop.HTTPGet.Path, preStop.HTTPGet.Port.String(), tt.drainPath)
+			}
+		})
+	}
+}
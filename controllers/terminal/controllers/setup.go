@@ -20,10 +20,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/labring/sealos/controllers/pkg/istio"
 	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
@@ -37,7 +40,7 @@ func (r *TerminalReconciler) SetupIstioSupport(ctx context.Context) error {
 	useIstio := os.Getenv("USE_ISTIO")
 	if useIstio != "true" {
 		logger.Info("Istio support is disabled")
-		r.useIstio = false
+		r.setIstioState(nil, nil)
 		return nil
 	}
 	
@@ -50,34 +53,60 @@ func (r *TerminalReconciler) SetupIstioSupport(ctx context.Context) error {
 	
 	if !isEnabled {
 		logger.Info("Istio is not installed, falling back to Ingress mode")
-		r.useIstio = false
+		r.setIstioState(nil, nil)
 		return nil
 	}
 	
 	// 构建 Istio 网络配置
 	config := r.buildIstioNetworkConfig()
 	
+	// 注册域名分配与验证指标
+	if err := istio.RegisterDomainMetrics(metrics.Registry); err != nil {
+		return err
+	}
+	
 	// 🎯 使用通用 Istio 网络助手（替代自定义协调器）
-	r.istioHelper = istio.NewUniversalIstioNetworkingHelperWithScheme(r.Client, r.Scheme, config, "terminal")
+	istioHelper := istio.NewUniversalIstioNetworkingHelperWithScheme(r.Client, r.Scheme, config, "terminal")
 	
 	// 保留旧协调器用于向后兼容和验证
-	r.istioReconciler = NewIstioNetworkingReconciler(r.Client, config)
+	istioReconciler := NewIstioNetworkingReconciler(r.Client, config)
+	istioReconciler.servicePort = r.getAppPort()
 	
 	// 验证 Istio 安装
-	if err := r.istioReconciler.ValidateIstioInstallation(ctx); err != nil {
+	if err := istioReconciler.ValidateIstioInstallation(ctx); err != nil {
 		logger.Error(err, "Istio validation failed, falling back to Ingress mode")
-		r.useIstio = false
-		r.istioReconciler = nil
-		r.istioHelper = nil
+		r.setIstioState(nil, nil)
 		return nil
 	}
 	
-	r.useIstio = true
+	// 全部组件构建并校验完成后再发布，协调中的 worker 不会看到半初始化的状态
+	r.setIstioState(istioHelper, istioReconciler)
 	logger.Info("Istio support enabled for Terminal controller")
 	
 	return nil
 }
 
+// setIstioState 在写锁内发布 Istio 网络组件，helper 为空时回退到 Ingress 模式
+func (r *TerminalReconciler) setIstioState(helper *istio.UniversalIstioNetworkingHelper, reconciler *IstioNetworkingReconciler) {
+	r.istioMu.Lock()
+	defer r.istioMu.Unlock()
+	r.istioHelper = helper
+	r.istioReconciler = reconciler
+	r.useIstio = helper != nil
+}
+
+// releaseGeneratedDomain 释放为 Terminal 自动生成并登记的域名，未启用 Istio 时忽略
+func (r *TerminalReconciler) releaseGeneratedDomain(ctx context.Context, terminal *terminalv1.Terminal) error {
+	if r.istioHelper == nil {
+		return nil
+	}
+	return r.istioHelper.ReleaseGeneratedDomain(ctx, &istio.AppNetworkingParams{
+		Name:      terminal.Name,
+		Namespace: terminal.Namespace,
+		AppType:   "terminal",
+	})
+}
+
 // buildIstioNetworkConfig 构建 Istio 网络配置（使用智能Gateway优化）
 func (r *TerminalReconciler) buildIstioNetworkConfig() *istio.NetworkConfig {
 	config := istio.DefaultNetworkConfig()
@@ -121,6 +150,146 @@ func (r *TerminalReconciler) buildIstioNetworkConfig() *istio.NetworkConfig {
 		config.SharedGatewayEnabled = true // 默认启用智能共享Gateway
 	}
 	
+	// 共享 Gateway 按租户分片
+	if shards := os.Getenv("ISTIO_SHARED_GATEWAY_SHARDS"); shards != "" {
+		if n, err := strconv.Atoi(shards); err == nil && n > 1 {
+			config.SharedGatewayShards = n
+		}
+	}
+	
+	// 注入可信转发头部
+	if trusted := os.Getenv("ISTIO_TRUSTED_PROXY_HEADERS"); trusted == "true" {
+		config.InjectTrustedProxyHeaders = true
+	}
+	
+	// 网关前可信代理的跳数，用于从 X-Forwarded-For 中取真实客户端地址
+	if hops := os.Getenv("ISTIO_XFF_NUM_TRUSTED_HOPS"); hops != "" {
+		if n, err := strconv.Atoi(hops); err == nil && n > 0 {
+			config.XFFNumTrustedHops = n
+		}
+	}
+	
+	// 透传链路追踪头部，可指定追踪头部列表和 x-b3-sampled 采样决策
+	if preserve := os.Getenv("ISTIO_PRESERVE_TRACE_HEADERS"); preserve == "true" {
+		config.PreserveTraceHeaders = true
+	}
+	if traceHeaders := os.Getenv("ISTIO_TRACE_HEADERS"); traceHeaders != "" {
+		for _, header := range strings.Split(traceHeaders, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				config.TraceHeaders = append(config.TraceHeaders, header)
+			}
+		}
+	}
+	if sampled := os.Getenv("ISTIO_TRACE_SAMPLING_DECISION"); sampled == "1" || sampled == "0" {
+		config.TraceSamplingDecision = sampled
+	}
+	
+	// 应用未配置 CORS 时使用的默认策略，允许应用自身域名和公共域名（通配符模式按一级子域名匹配）
+	if cors := os.Getenv("ISTIO_DEFAULT_CORS_POLICY"); cors == "true" {
+		config.DefaultCorsPolicy = &istio.CorsPolicy{
+			AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders: []string{"content-type", "authorization"},
+		}
+		if maxAge, err := time.ParseDuration(os.Getenv("ISTIO_DEFAULT_CORS_MAX_AGE")); err == nil && maxAge > 0 {
+			config.DefaultCorsPolicy.MaxAge = &maxAge
+		}
+	}
+	
+	// 后端 Service 有就绪端点后才创建 VirtualService
+	if requireEndpoints := os.Getenv("ISTIO_REQUIRE_SERVICE_ENDPOINTS"); requireEndpoints == "true" {
+		config.RequireServiceEndpoints = true
+	}
+	
+	// 证书 Secret 写入证书数据前不在 Gateway 上声明自定义域名
+	if withhold := os.Getenv("ISTIO_WITHHOLD_HOSTS_UNTIL_CERT_READY"); withhold == "true" {
+		config.WithholdHostsUntilCertReady = true
+	}
+	
+	// 专用 Gateway 的 TLS 最低版本和加密套件
+	if minVersion := os.Getenv("ISTIO_GATEWAY_TLS_MIN_VERSION"); minVersion != "" {
+		config.GatewayTLSMinProtocolVersion = minVersion
+	}
+	if cipherSuites := os.Getenv("ISTIO_GATEWAY_TLS_CIPHER_SUITES"); cipherSuites != "" {
+		for _, suite := range strings.Split(cipherSuites, ",") {
+			if suite = strings.TrimSpace(suite); suite != "" {
+				config.GatewayTLSCipherSuites = append(config.GatewayTLSCipherSuites, suite)
+			}
+		}
+	}
+	
+	// 跨租户域名登记表（namespace/name 格式的 ConfigMap）
+	if registry := os.Getenv("ISTIO_DOMAIN_REGISTRY_CONFIGMAP"); registry != "" {
+		if namespace, name, ok := strings.Cut(registry, "/"); ok && namespace != "" && name != "" {
+			config.DomainRegistry = istio.NewConfigMapDomainRegistry(r.Client, r.apiReader, namespace, name)
+		}
+	}
+	
+	// 允许控制器设置的响应头部
+	if allowedHeaders := os.Getenv("ISTIO_ALLOWED_RESPONSE_HEADERS"); allowedHeaders != "" {
+		for _, header := range strings.Split(allowedHeaders, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				config.AllowedResponseHeaders = append(config.AllowedResponseHeaders, header)
+			}
+		}
+	}
+	
+	// VirtualService 域名漂移处理策略
+	if policy := os.Getenv("ISTIO_DOMAIN_DRIFT_POLICY"); policy == string(istio.DomainDriftObserve) {
+		config.DomainDriftPolicy = istio.DomainDriftObserve
+	} else {
+		config.DomainDriftPolicy = istio.DomainDriftEnforce
+	}
+	
+	// 内部/私有域名跳过 DNS 解析验证
+	if skipDNS := os.Getenv("ISTIO_SKIP_DNS_VALIDATION"); skipDNS == "true" {
+		config.SkipDNSValidation = true
+	}
+	if suffixes := os.Getenv("ISTIO_DNS_SKIP_SUFFIXES"); suffixes != "" {
+		for _, suffix := range strings.Split(suffixes, ",") {
+			if suffix = strings.TrimSpace(suffix); suffix != "" {
+				config.DNSValidationSkipSuffixes = append(config.DNSValidationSkipSuffixes, suffix)
+			}
+		}
+	}
+	
+	// 中国域名的 ICP 备案查询接口，配置后域名分配器和准入 Webhook 会校验备案
+	if endpoint := os.Getenv("ISTIO_ICP_VALIDATION_ENDPOINT"); endpoint != "" {
+		config.ICPValidationEndpoint = endpoint
+	}
+	
+	// CAA 检查允许的 ACME CA 标识
+	if cas := os.Getenv("ISTIO_ACME_CA_IDENTIFIERS"); cas != "" {
+		for _, ca := range strings.Split(cas, ",") {
+			if ca = strings.TrimSpace(ca); ca != "" {
+				config.ACMECAIdentifiers = append(config.ACMECAIdentifiers, ca)
+			}
+		}
+	}
+	
+	// 调试：在响应头中暴露服务 Gateway
+	if debug := os.Getenv("ISTIO_DEBUG_GATEWAY_HEADER"); debug == "true" {
+		config.DebugGatewayHeader = true
+	}
+	
+	// 按命名空间覆盖基础域名（多品牌部署），格式 namespace=domain，逗号分隔
+	if overrides := os.Getenv("ISTIO_NAMESPACE_BASE_DOMAINS"); overrides != "" {
+		config.NamespaceBaseDomains = istio.ParseNamespaceBaseDomains(overrides)
+	}
+	
+	// 按租户放行的保留子域名，格式 tenant=subdomain|subdomain，逗号分隔
+	if reserved := os.Getenv("ISTIO_TENANT_RESERVED_SUBDOMAINS"); reserved != "" {
+		config.TenantReservedSubdomains = istio.ParseTenantReservedSubdomains(reserved)
+	}
+	
+	// gateway-override 注解允许指向的其他命名空间 Gateway（逗号分隔的 namespace/name）
+	if gateways := os.Getenv("ISTIO_GATEWAY_OVERRIDE_ALLOWLIST"); gateways != "" {
+		for _, gateway := range strings.Split(gateways, ",") {
+			if gateway = strings.TrimSpace(gateway); gateway != "" {
+				config.GatewayOverrideAllowlist = append(config.GatewayOverrideAllowlist, gateway)
+			}
+		}
+	}
+	
 	return config
 }
 
@@ -164,13 +333,22 @@ func (r *TerminalReconciler) configurePublicDomains(config *istio.NetworkConfig)
 	}
 }
 
+// NewCustomDomainValidator 创建准入 Webhook 使用的自定义域名验证器，与协调时使用相同的网络配置
+func (r *TerminalReconciler) NewCustomDomainValidator() istio.DomainAllocator {
+	return istio.NewDomainAllocator(r.buildIstioNetworkConfig())
+}
+
 // IsIstioEnabled 检查是否启用了 Istio 模式
 func (r *TerminalReconciler) IsIstioEnabled() bool {
+	r.istioMu.RLock()
+	defer r.istioMu.RUnlock()
 	return r.useIstio
 }
 
 // GetNetworkingStatus 获取 Terminal 的网络状态
 func (r *TerminalReconciler) GetNetworkingStatus(ctx context.Context, terminalName, namespace string) (*istio.NetworkingStatus, error) {
+	r.istioMu.RLock()
+	defer r.istioMu.RUnlock()
 	if !r.useIstio || r.istioReconciler == nil {
 		return nil, fmt.Errorf("Istio mode is not enabled")
 	}
@@ -186,7 +364,7 @@ func (r *TerminalReconciler) GetNetworkingStatus(ctx context.Context, terminalNa
 
 // EnableIstioMode 动态启用 Istio 模式
 func (r *TerminalReconciler) EnableIstioMode(ctx context.Context) error {
-	if r.useIstio {
+	if r.IsIstioEnabled() {
 		return nil // 已经启用
 	}
 	
@@ -195,6 +373,5 @@ func (r *TerminalReconciler) EnableIstioMode(ctx context.Context) error {
 
 // DisableIstioMode 禁用 Istio 模式，回退到 Ingress
 func (r *TerminalReconciler) DisableIstioMode() {
-	r.useIstio = false
-	r.istioReconciler = nil
+	r.setIstioState(nil, nil)
 }
\ No newline at end of file
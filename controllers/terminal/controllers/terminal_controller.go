@@ -19,7 +19,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	nanoid "github.com/matoous/go-nanoid/v2"
@@ -37,12 +41,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/labring/sealos/controllers/pkg/istio"
 	"github.com/labring/sealos/controllers/pkg/utils/label"
@@ -57,8 +66,21 @@ const (
 	HostnameLength      = 8
 	KeepaliveAnnotation = "lastUpdateTime"
 	LetterBytes         = "abcdefghijklmnopqrstuvwxyz0123456789"
+	// MinHostnameLength and MaxHostnameLength bound the configurable nanoid length
+	MinHostnameLength = 4
+	MaxHostnameLength = 20
+	// DefaultHostnamePrefix is used when Spec.HostnamePrefix is empty
+	DefaultHostnamePrefix = "t"
+	// DefaultAppPort is the port the tty container listens on and the Service exposes
+	DefaultAppPort int32 = 8080
 )
 
+// backingServiceRequeueInterval is how long to wait before retrying networking when the Service is not ready yet
+const backingServiceRequeueInterval = 5 * time.Second
+
+// hostnamePrefixRegex to keep pace with ingress host, hostname must start with a lower case letter
+var hostnamePrefixRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
 const (
 	DefaultDomain          = "cloud.sealos.io"
 	DefaultPort            = ""
@@ -76,6 +98,16 @@ const (
 
 const (
 	SecretHeaderPrefix = "X-SEALOS-"
+	AuthHeaderEnvName  = "AUTH_HEADER"
+	TTYContainerName   = "tty"
+)
+
+// session draining of terminating tty pods
+const (
+	// DefaultTerminationGracePeriodSeconds gives in-flight sessions time to finish before the tty pod is killed
+	DefaultTerminationGracePeriodSeconds int64 = 120
+	// PreStopSleepSeconds delays shutdown until the pod is removed from the endpoints when draining is disabled
+	PreStopSleepSeconds = 5
 )
 
 // retryUpdateOnConflict retries the update operation when there's a resource version conflict
@@ -128,6 +160,23 @@ type TerminalReconciler struct {
 	istioReconciler *IstioNetworkingReconciler            // 保留向后兼容
 	istioHelper     *istio.UniversalIstioNetworkingHelper // 🎯 新增通用助手
 	useIstio        bool
+	// istioMu guards useIstio, istioHelper and istioReconciler: Reconcile holds the read lock,
+	// the Istio mode reevaluator publishes the fully built components under the write lock
+	istioMu sync.RWMutex
+	// apiReader reads the domain registry ConfigMap without starting a cluster-wide ConfigMap informer
+	apiReader client.Reader
+	// hostnameAlphabet and hostnameLength tune the nanoid of generated hostnames, empty means the defaults
+	hostnameAlphabet string
+	hostnameLength   int
+	// terminationGracePeriodSeconds and sessionDrainPath configure session draining of terminating pods, empty means the defaults
+	terminationGracePeriodSeconds int64
+	sessionDrainPath              string
+	// appPort is the tty container port shared by the Service and the networking, empty means DefaultAppPort
+	appPort int32
+	// maxInstancesPerNamespace caps the terminals started in one namespace, 0 disables the limit
+	maxInstancesPerNamespace int
+	// clock is the time source of keepalive timestamps and expiry, nil means the real time
+	clock clock.PassiveClock
 }
 
 //+kubebuilder:rbac:groups=terminal.sealos.io,resources=terminals,verbs=get;list;watch;create;update;patch;delete
@@ -135,6 +184,8 @@ type TerminalReconciler struct {
 //+kubebuilder:rbac:groups=terminal.sealos.io,resources=terminals/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;create;update
 //+kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=gateways,verbs=get;list;watch;create;update;patch;delete
@@ -143,8 +194,12 @@ type TerminalReconciler struct {
 //+kubebuilder:rbac:groups=networking.istio.io,resources=virtualservices/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.istio.io,resources=destinationrules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=networking.istio.io,resources=envoyfilters,verbs=get;list;watch;create;update;patch;delete
 
 func (r *TerminalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// 切换到 Istio 模式时等待进行中的协调结束，避免读到切换中的网络组件
+	r.istioMu.RLock()
+	defer r.istioMu.RUnlock()
 	logger := log.FromContext(ctx, "terminal", req.NamespacedName)
 	terminal := &terminalv1.Terminal{}
 	if err := r.Get(ctx, req.NamespacedName, terminal); err != nil {
@@ -160,6 +215,12 @@ func (r *TerminalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			}
 		}
 	} else {
+		// 释放为 Terminal 自动生成并登记的域名，失败时保留 finalizer 重试
+		if controllerutil.ContainsFinalizer(terminal, FinalizerName) {
+			if err := r.releaseGeneratedDomain(ctx, terminal); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		if controllerutil.RemoveFinalizer(terminal, FinalizerName) {
 			if err := retryUpdateOnConflict(ctx, r.Client, terminal, func() {
 				controllerutil.RemoveFinalizer(terminal, FinalizerName)
@@ -174,7 +235,7 @@ func (r *TerminalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
-	if isExpired(terminal) {
+	if isExpired(terminal, r.now()) {
 		if err := r.Delete(ctx, terminal); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -182,6 +243,15 @@ func (r *TerminalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, nil
 	}
 
+	admitted, err := r.admitInstance(ctx, terminal)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !admitted {
+		logger.Info("terminal refused by the per-namespace instance limit", "limit", r.maxInstancesPerNamespace)
+		return ctrl.Result{RequeueAfter: instanceLimitRequeueInterval}, nil
+	}
+
 	if terminal.Status.ServiceName == "" {
 		if err := retryStatusUpdateOnConflict(ctx, r.Client, terminal, func() {
 			terminal.Status.ServiceName = terminal.Name + "-svc" + rand.String(5)
@@ -221,10 +291,24 @@ func (r *TerminalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	if err := r.syncNetworking(ctx, terminal, hostname, recLabels); err != nil {
+		if istio.IsBackingServiceNotReady(err) {
+			logger.Info("backing service not ready, requeue networking", "reason", err.Error())
+			return ctrl.Result{RequeueAfter: backingServiceRequeueInterval}, nil
+		}
+		if istio.IsTLSSecretNotFound(err) {
+			logger.Info("tls secret not found, requeue networking", "reason", err.Error())
+			if err := r.setTLSSecretCondition(ctx, terminal, err); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: tlsSecretRequeueInterval}, nil
+		}
 		logger.Error(err, "create networking failed")
 		r.recorder.Eventf(terminal, corev1.EventTypeWarning, "Create networking failed", "%v", err)
 		return ctrl.Result{}, err
 	}
+	if err := r.setTLSSecretCondition(ctx, terminal, nil); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	r.recorder.Eventf(terminal, corev1.EventTypeNormal, "Created", "create terminal success: %v", terminal.Name)
 	duration, _ := time.ParseDuration(terminal.Spec.Keepalived)
@@ -234,13 +318,45 @@ func (r *TerminalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 func (r *TerminalReconciler) syncNetworking(ctx context.Context, terminal *terminalv1.Terminal, hostname string, recLabels map[string]string) error {
 	// 根据配置决定使用 Istio 还是 Ingress
 	if r.useIstio && r.istioReconciler != nil {
-		return r.syncIstioNetworking(ctx, terminal, hostname, recLabels)
+		if err := r.syncIstioNetworking(ctx, terminal, hostname, recLabels); err != nil {
+			return err
+		}
+		// 集群从 Ingress 模式切换到 Istio 后，VirtualService 就绪再删除旧 Ingress
+		return r.removeLegacyIngress(ctx, terminal)
 	}
 
 	// 回退到原有的 Ingress 模式
 	return r.syncIngress(ctx, terminal, hostname, recLabels)
 }
 
+// removeLegacyIngress 删除 Ingress 模式下创建的 Ingress，VirtualService 未就绪时保留以免切换期间中断访问
+func (r *TerminalReconciler) removeLegacyIngress(ctx context.Context, terminal *terminalv1.Terminal) error {
+	ingress := &networkingv1.Ingress{}
+	if err := r.Get(ctx, client.ObjectKey{Name: terminal.Name, Namespace: terminal.Namespace}, ingress); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(ingress, terminal) {
+		return nil
+	}
+
+	var (
+		status *istio.NetworkingStatus
+		err    error
+	)
+	if r.istioHelper != nil {
+		status, err = r.istioHelper.GetNetworkingStatus(ctx, terminal.Name, terminal.Namespace)
+	} else {
+		status, err = r.istioReconciler.GetNetworkingStatus(ctx, terminal)
+	}
+	if err != nil || !status.VirtualServiceReady {
+		// 下次协调再检查
+		return client.IgnoreNotFound(err)
+	}
+
+	log.FromContext(ctx).Info("virtualservice is ready, removing legacy ingress", "ingress", ingress.Name)
+	return client.IgnoreNotFound(r.Delete(ctx, ingress))
+}
+
 func (r *TerminalReconciler) syncIngress(ctx context.Context, terminal *terminalv1.Terminal, hostname string, recLabels map[string]string) error {
 	var err error
 	host := hostname + "." + r.CtrConfig.Global.CloudDomain
@@ -278,15 +394,25 @@ func (r *TerminalReconciler) syncIstioNetworking(ctx context.Context, terminal *
 func (r *TerminalReconciler) syncOptimizedIstioNetworking(ctx context.Context, terminal *terminalv1.Terminal, hostname string, recLabels map[string]string) error {
 	// 构建域名
 	host := hostname + "." + r.CtrConfig.Global.CloudDomain
+	// 用户指定的自定义域名优先（已由准入 Webhook 验证）
+	if terminal.Spec.CustomDomain != "" {
+		host = terminal.Spec.CustomDomain
+	}
+
+	// 检查 VirtualService 域名是否被手动修改，observe 策略下沿用实际域名
+	host, err := r.istioHelper.ReconcileDomainDrift(ctx, terminal.Name, terminal.Namespace, host)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile domain drift: %w", err)
+	}
 
 	// 🎯 使用通用助手的智能网络配置
 	params := &istio.AppNetworkingParams{
 		Name:        terminal.Name,
 		Namespace:   terminal.Namespace,
 		AppType:     "terminal",
-		Hosts:       []string{host},
+		Hosts:       withAdditionalDomains(host, terminal.Spec.AdditionalDomains),
 		ServiceName: terminal.Status.ServiceName,
-		ServicePort: 8080,
+		ServicePort: r.getAppPort(),
 		Protocol:    istio.ProtocolWebSocket, // Terminal使用WebSocket协议
 
 		// Terminal专用配置
@@ -331,21 +457,35 @@ func (r *TerminalReconciler) syncOptimizedIstioNetworking(ctx context.Context, t
 	domain := Protocol + host + r.getPort()
 
 	return retryStatusUpdateOnConflict(ctx, r.Client, terminal, func() {
-		terminal.Status.Domain = domain
+		setTerminalNetworkingStatus(terminal, domain, analysis)
+	})
+}
 
-		// 🎯 添加Gateway优化状态信息
-		if terminal.Annotations == nil {
-			terminal.Annotations = make(map[string]string)
+// withAdditionalDomains 返回主域名加上额外的自定义域名（去重、忽略空值），主域名始终在第一位，
+// 由域名分类器把公共域名绑定到共享 Gateway、自定义域名绑定到应用 Gateway
+func withAdditionalDomains(host string, additional []string) []string {
+	hosts := []string{host}
+	for _, domain := range additional {
+		if domain = strings.TrimSpace(domain); domain != "" && !slices.Contains(hosts, domain) {
+			hosts = append(hosts, domain)
 		}
-		terminal.Annotations["sealos.io/gateway-type"] = "optimized"
-		terminal.Annotations["sealos.io/domain-type"] = func() string {
-			if analysis.IsPublicDomain {
-				return "public"
-			}
-			return "custom"
-		}()
-		terminal.Annotations["sealos.io/gateway-reference"] = analysis.GatewayReference
-	})
+	}
+	return hosts
+}
+
+// setTerminalNetworkingStatus 按域名分析结果写入状态中的域名和 Gateway 信息，注解保留用于向后兼容
+func setTerminalNetworkingStatus(terminal *terminalv1.Terminal, domain string, analysis *istio.DomainAnalysis) {
+	terminal.Status.Domain = domain
+	terminal.Status.GatewayReference = analysis.GatewayReference
+	terminal.Status.DomainType = analysis.DomainType()
+
+	// 🎯 添加Gateway优化状态信息
+	if terminal.Annotations == nil {
+		terminal.Annotations = make(map[string]string)
+	}
+	terminal.Annotations["sealos.io/gateway-type"] = "optimized"
+	terminal.Annotations["sealos.io/domain-type"] = analysis.DomainType()
+	terminal.Annotations["sealos.io/gateway-reference"] = analysis.GatewayReference
 }
 
 // buildTerminalCorsOrigins 构建Terminal的CORS源 - 使用精确匹配的terminal子域名
@@ -405,8 +545,7 @@ func (r *TerminalReconciler) buildTerminalCorsOrigins() []string {
 func (r *TerminalReconciler) buildSecurityResponseHeaders() map[string]string {
 	headers := make(map[string]string)
 
-	// 设置 X-Frame-Options，防止点击劫持
-	headers["X-Frame-Options"] = ""
+	// 不设置 X-Frame-Options：终端通过 iframe 嵌入在桌面中（跨子域名），SAMEORIGIN 会导致无法加载
 
 	// 设置 X-Content-Type-Options，防止 MIME 类型嗅探
 	headers["X-Content-Type-Options"] = "nosniff"
@@ -457,7 +596,7 @@ func (r *TerminalReconciler) syncService(ctx context.Context, terminal *terminal
 		Selector: recLabels,
 		Type:     corev1.ServiceTypeClusterIP,
 		Ports: []corev1.ServicePort{
-			{Name: "tty", Port: 8080, TargetPort: intstr.FromInt(8080), Protocol: corev1.ProtocolTCP},
+			{Name: "tty", Port: r.getAppPort(), TargetPort: intstr.FromInt32(r.getAppPort()), Protocol: corev1.ProtocolTCP},
 		},
 	}
 
@@ -513,7 +652,7 @@ func (r *TerminalReconciler) syncDeployment(ctx context.Context, terminal *termi
 		{
 			Name:          "http",
 			Protocol:      corev1.ProtocolTCP,
-			ContainerPort: 8080,
+			ContainerPort: r.getAppPort(),
 		},
 	}
 	envs = []corev1.EnvVar{
@@ -522,12 +661,12 @@ func (r *TerminalReconciler) syncDeployment(ctx context.Context, terminal *termi
 		{Name: "NAMESPACE", Value: terminal.Namespace},
 		{Name: "USER_NAME", Value: terminal.Spec.User},
 		// Add secret header
-		{Name: "AUTH_HEADER", Value: terminal.Status.SecretHeader},
+		{Name: AuthHeaderEnvName, Value: terminal.Status.SecretHeader},
 	}
 
 	containers = []corev1.Container{
 		{
-			Name:  "tty",
+			Name:  TTYContainerName,
 			Image: terminal.Spec.TTYImage,
 			Ports: ports,
 			Env:   envs,
@@ -541,8 +680,10 @@ func (r *TerminalReconciler) syncDeployment(ctx context.Context, terminal *termi
 					"memory": resource.MustParse(MemoryLimit),
 				},
 			},
+			Lifecycle: buildTTYLifecycle(r.sessionDrainPath),
 		},
 	}
+	gracePeriod := r.getTerminationGracePeriodSeconds()
 
 	expectDeploymentSpec := appsv1.DeploymentSpec{
 		Replicas: terminal.Spec.Replicas,
@@ -550,7 +691,8 @@ func (r *TerminalReconciler) syncDeployment(ctx context.Context, terminal *termi
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: templateObjMeta,
 			Spec: corev1.PodSpec{
-				Containers: containers,
+				Containers:                    containers,
+				TerminationGracePeriodSeconds: &gracePeriod,
 			},
 		},
 	}
@@ -567,20 +709,29 @@ func (r *TerminalReconciler) syncDeployment(ctx context.Context, terminal *termi
 		if len(deployment.Spec.Template.Spec.Containers) == 0 {
 			deployment.Spec.Template.Spec.Containers = containers
 		} else {
-			deployment.Spec.Template.Spec.Containers[0].Name = containers[0].Name
-			deployment.Spec.Template.Spec.Containers[0].Image = containers[0].Image
-			deployment.Spec.Template.Spec.Containers[0].Ports = containers[0].Ports
-			deployment.Spec.Template.Spec.Containers[0].Env = containers[0].Env
-			deployment.Spec.Template.Spec.Containers[0].Resources = containers[0].Resources
+			// status 被手动修改后 Pod 中的 AUTH_HEADER 会与 Status.SecretHeader 不一致，导致鉴权失败
+			if authHeader, ok := getAuthHeaderEnv(&deployment.Spec.Template.Spec); !ok || authHeader != terminal.Status.SecretHeader {
+				log.FromContext(ctx).Info("deployment AUTH_HEADER out of sync with status, updating pod template",
+					"deployment", deployment.Name)
+				r.recorder.Eventf(terminal, corev1.EventTypeNormal, "SecretHeaderSynced",
+					"update deployment %s AUTH_HEADER to match status", deployment.Name)
+			}
+			idx := ttyContainerIndex(&deployment.Spec.Template.Spec)
+			deployment.Spec.Template.Spec.Containers[idx].Name = containers[0].Name
+			deployment.Spec.Template.Spec.Containers[idx].Image = containers[0].Image
+			deployment.Spec.Template.Spec.Containers[idx].Ports = containers[0].Ports
+			deployment.Spec.Template.Spec.Containers[idx].Env = containers[0].Env
+			deployment.Spec.Template.Spec.Containers[idx].Resources = containers[0].Resources
+			deployment.Spec.Template.Spec.Containers[idx].Lifecycle = containers[0].Lifecycle
 		}
+		deployment.Spec.Template.Spec.TerminationGracePeriodSeconds = expectDeploymentSpec.Template.Spec.TerminationGracePeriodSeconds
 
 		if deployment.Spec.Template.Spec.Hostname == "" {
-			letterID, err := nanoid.Generate(LetterBytes, HostnameLength)
+			generated, err := generateHostname(terminal.Spec.HostnamePrefix, r.hostnameAlphabet, r.hostnameLength)
 			if err != nil {
 				return err
 			}
-			// to keep pace with ingress host, hostname must start with a lower case letter
-			*hostname = "t" + letterID
+			*hostname = generated
 			deployment.Spec.Template.Spec.Hostname = *hostname
 		} else {
 			*hostname = deployment.Spec.Template.Spec.Hostname
@@ -608,7 +759,7 @@ func (r *TerminalReconciler) fillDefaultValue(ctx context.Context, terminal *ter
 	}
 
 	if _, ok := terminal.ObjectMeta.Annotations[KeepaliveAnnotation]; !ok {
-		terminal.ObjectMeta.Annotations[KeepaliveAnnotation] = time.Now().Format(time.RFC3339)
+		terminal.ObjectMeta.Annotations[KeepaliveAnnotation] = r.now().Format(time.RFC3339)
 		hasUpdate = true
 	}
 
@@ -617,15 +768,23 @@ func (r *TerminalReconciler) fillDefaultValue(ctx context.Context, terminal *ter
 			if terminal.ObjectMeta.Annotations == nil {
 				terminal.ObjectMeta.Annotations = make(map[string]string)
 			}
-			terminal.ObjectMeta.Annotations[KeepaliveAnnotation] = time.Now().Format(time.RFC3339)
+			terminal.ObjectMeta.Annotations[KeepaliveAnnotation] = r.now().Format(time.RFC3339)
 		})
 	}
 
 	return nil
 }
 
-// isExpired return true if the terminal has expired
-func isExpired(terminal *terminalv1.Terminal) bool {
+// now returns the current time of the reconciler's clock
+func (r *TerminalReconciler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+// isExpired return true if the terminal has expired at now
+func isExpired(terminal *terminalv1.Terminal, now time.Time) bool {
 	anno := terminal.ObjectMeta.Annotations
 	lastUpdateTime, err := time.Parse(time.RFC3339, anno[KeepaliveAnnotation])
 	if err != nil {
@@ -634,7 +793,7 @@ func isExpired(terminal *terminalv1.Terminal) bool {
 	}
 
 	duration, _ := time.ParseDuration(terminal.Spec.Keepalived)
-	return lastUpdateTime.Add(duration).Before(time.Now())
+	return lastUpdateTime.Add(duration).Before(now)
 }
 
 func (r *TerminalReconciler) getPort() string {
@@ -648,11 +807,123 @@ func (r *TerminalReconciler) generateSecretHeader() string {
 	return SecretHeaderPrefix + strings.ToUpper(rand.String(5))
 }
 
+// getAppPort returns the configured port of the tty container, the Service and the networking
+func (r *TerminalReconciler) getAppPort() int32 {
+	if r.appPort > 0 {
+		return r.appPort
+	}
+	return DefaultAppPort
+}
+
+// validateAppPort checks the configured app port is a valid TCP port
+func validateAppPort(port int32) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid app port %d: must be between 1 and 65535", port)
+	}
+	return nil
+}
+
+// getTerminationGracePeriodSeconds returns the configured grace period of tty pods
+func (r *TerminalReconciler) getTerminationGracePeriodSeconds() int64 {
+	if r.terminationGracePeriodSeconds > 0 {
+		return r.terminationGracePeriodSeconds
+	}
+	return DefaultTerminationGracePeriodSeconds
+}
+
+// buildTTYLifecycle returns the preStop hook of the tty container. With a drain path the hook calls the
+// drain endpoint of the tty image, which returns once in-flight sessions finish; otherwise it only waits
+// for the pod to be removed from the Service endpoints so no new session lands on it.
+func buildTTYLifecycle(drainPath string) *corev1.Lifecycle {
+	handler := &corev1.LifecycleHandler{
+		Exec: &corev1.ExecAction{Command: []string{"sh", "-c", fmt.Sprintf("sleep %d", PreStopSleepSeconds)}},
+	}
+	if drainPath != "" {
+		handler = &corev1.LifecycleHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: drainPath, Port: intstr.FromString("http")},
+		}
+	}
+	return &corev1.Lifecycle{PreStop: handler}
+}
+
+// ttyContainerIndex returns the index of the tty container, falling back to the first container
+func ttyContainerIndex(podSpec *corev1.PodSpec) int {
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == TTYContainerName {
+			return i
+		}
+	}
+	return 0
+}
+
+// getAuthHeaderEnv returns the AUTH_HEADER env value of the tty container
+func getAuthHeaderEnv(podSpec *corev1.PodSpec) (string, bool) {
+	if len(podSpec.Containers) == 0 {
+		return "", false
+	}
+	for _, env := range podSpec.Containers[ttyContainerIndex(podSpec)].Env {
+		if env.Name == AuthHeaderEnvName {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
+
+// secretHeaderChangedPredicate triggers reconciliation when Status.SecretHeader is edited,
+// status updates do not bump the generation
+func secretHeaderChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldTerminal, ok := e.ObjectOld.(*terminalv1.Terminal)
+			if !ok {
+				return false
+			}
+			newTerminal, ok := e.ObjectNew.(*terminalv1.Terminal)
+			if !ok {
+				return false
+			}
+			return oldTerminal.Status.SecretHeader != newTerminal.Status.SecretHeader
+		},
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *TerminalReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.apiReader = mgr.GetAPIReader()
 	r.recorder = mgr.GetEventRecorderFor("sealos-terminal-controller")
 	r.Config = mgr.GetConfig()
 
+	r.hostnameAlphabet, r.hostnameLength = LetterBytes, HostnameLength
+	if r.CtrConfig != nil {
+		if alphabet := r.CtrConfig.TerminalConfig.HostnameAlphabet; alphabet != "" {
+			r.hostnameAlphabet = alphabet
+		}
+		if length := r.CtrConfig.TerminalConfig.HostnameLength; length != 0 {
+			r.hostnameLength = length
+		}
+	}
+	if err := validateHostnameSettings(r.hostnameAlphabet, r.hostnameLength); err != nil {
+		return err
+	}
+	if r.CtrConfig != nil {
+		if r.CtrConfig.TerminalConfig.TerminationGracePeriodSeconds < 0 {
+			return fmt.Errorf("terminationGracePeriodSeconds must not be negative, got %d", r.CtrConfig.TerminalConfig.TerminationGracePeriodSeconds)
+		}
+		r.terminationGracePeriodSeconds = r.CtrConfig.TerminalConfig.TerminationGracePeriodSeconds
+		r.sessionDrainPath = r.CtrConfig.TerminalConfig.SessionDrainPath
+		r.appPort = r.CtrConfig.TerminalConfig.AppPort
+		r.maxInstancesPerNamespace = r.CtrConfig.TerminalConfig.MaxInstancesPerNamespace
+	}
+	if err := validateAppPort(r.getAppPort()); err != nil {
+		return err
+	}
+	if err := validateMaxInstancesPerNamespace(r.maxInstancesPerNamespace); err != nil {
+		return err
+	}
+
 	// 初始化 Istio 支持
 	ctx := context.Background()
 	if err := r.SetupIstioSupport(ctx); err != nil {
@@ -661,32 +932,120 @@ func (r *TerminalReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
-		For(&terminalv1.Terminal{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&terminalv1.Terminal{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, secretHeaderChangedPredicate()))).
 		Owns(&appsv1.Deployment{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Owns(&corev1.Service{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Owns(&networkingv1.Ingress{}, builder.WithPredicates(predicate.GenerationChangedPredicate{}))
 
 	// 如果启用了 Istio，添加对 Istio 资源的监听
 	if r.useIstio {
-		// 使用 unstructured 类型来监听 Istio CRDs
-		virtualServiceType := &unstructured.Unstructured{}
-		virtualServiceType.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "networking.istio.io",
-			Version: "v1beta1",
-			Kind:    "VirtualService",
-		})
+		for _, obj := range istioWatchTypes() {
+			controllerBuilder = controllerBuilder.Owns(obj)
+		}
+	}
 
-		gatewayType := &unstructured.Unstructured{}
-		gatewayType.SetGroupVersionKind(schema.GroupVersionKind{
-			Group:   "networking.istio.io",
-			Version: "v1beta1",
-			Kind:    "Gateway",
-		})
+	c, err := controllerBuilder.Build(r)
+	if err != nil {
+		return err
+	}
+
+	// 清理旧版本遗留的、缺少 OwnerReference 的孤立 Service
+	if err := mgr.Add(istio.NewOrphanServiceSweeper(r.Client, terminalv1.GroupVersion.WithKind("Terminal"), TerminalPartOf, istio.DefaultOrphanServiceSweepInterval)); err != nil {
+		return err
+	}
 
-		controllerBuilder = controllerBuilder.
-			Owns(virtualServiceType).
-			Owns(gatewayType)
+	// 同步网关的 X-Forwarded-For 可信跳数，让 Terminal 拿到真实客户端地址
+	if os.Getenv("USE_ISTIO") == "true" {
+		if err := mgr.Add(istio.NewTrustedHopsEnvoyFilterSyncer(r.Client, r.buildIstioNetworkConfig(), istio.DefaultTrustedHopsResyncInterval)); err != nil {
+			return err
+		}
+		// 修复被误配置为较窄主机列表的共享 Gateway，避免使用公共域名的 Terminal 返回 404
+		if err := mgr.Add(istio.NewSharedGatewayHostsSyncer(r.Client, r.buildIstioNetworkConfig(), istio.DefaultSharedGatewayHostsResyncInterval)); err != nil {
+			return err
+		}
 	}
 
-	return controllerBuilder.Complete(r)
+	// 启动时 Istio CRD 可能尚未安装完成，回退到 Ingress 后周期性检查，CRD 就绪后切换到 Istio 模式
+	if os.Getenv("USE_ISTIO") == "true" && !r.useIstio {
+		return mgr.Add(istio.NewModeReevaluator(r.Client, istio.DefaultModeReevaluateInterval, func(ctx context.Context) (bool, error) {
+			if err := r.EnableIstioMode(ctx); err != nil || !r.IsIstioEnabled() {
+				return false, err
+			}
+			if err := r.watchIstioResources(mgr, c); err != nil {
+				return true, err
+			}
+			// 已按 Ingress 模式协调过的 Terminal 重新入队，补建 Istio 网络资源
+			return true, c.Watch(istio.EnqueueExisting(mgr.GetClient(), &terminalv1.TerminalList{}))
+		}))
+	}
+	return nil
+}
+
+// istioWatchTypes 返回 Terminal 拥有的 Istio 资源类型（使用 unstructured 类型来监听 Istio CRDs）
+func istioWatchTypes() []client.Object {
+	virtualServiceType := &unstructured.Unstructured{}
+	virtualServiceType.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    "VirtualService",
+	})
+
+	gatewayType := &unstructured.Unstructured{}
+	gatewayType.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   "networking.istio.io",
+		Version: "v1beta1",
+		Kind:    "Gateway",
+	})
+
+	return []client.Object{virtualServiceType, gatewayType}
+}
+
+// watchIstioResources 在运行时切换到 Istio 模式后补充对 Istio 资源的监听，等同于 Owns
+func (r *TerminalReconciler) watchIstioResources(mgr ctrl.Manager, c controller.Controller) error {
+	for _, obj := range istioWatchTypes() {
+		if err := c.Watch(source.Kind(mgr.GetCache(), obj,
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &terminalv1.Terminal{}, handler.OnlyControllerOwner()))); err != nil {
+			return fmt.Errorf("watch %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+	}
+	return nil
+}
+
+// validateHostnameSettings checks the configured nanoid alphabet and length of generated hostnames,
+// the alphabet must only contain lower case letters and digits so that the hostname stays a valid DNS label.
+func validateHostnameSettings(alphabet string, length int) error {
+	if length < MinHostnameLength || length > MaxHostnameLength {
+		return fmt.Errorf("invalid hostname length %d: must be between %d and %d", length, MinHostnameLength, MaxHostnameLength)
+	}
+	if alphabet == "" {
+		return fmt.Errorf("hostname alphabet cannot be empty")
+	}
+	for _, c := range alphabet {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid hostname alphabet %q: must only contain lower case letters and digits", alphabet)
+		}
+	}
+	return nil
+}
+
+// generateHostname builds the pod hostname from the prefix and a random nanoid,
+// the prefix defaults to DefaultHostnamePrefix, alphabet and length default to LetterBytes and HostnameLength.
+func generateHostname(prefix, alphabet string, length int) (string, error) {
+	if prefix == "" {
+		prefix = DefaultHostnamePrefix
+	}
+	if alphabet == "" {
+		alphabet = LetterBytes
+	}
+	if length == 0 {
+		length = HostnameLength
+	}
+	if !hostnamePrefixRegex.MatchString(prefix) || len(prefix)+length > 63 {
+		return "", fmt.Errorf("invalid hostname prefix %q: must start with a lower case letter, contain only lower case letters, digits or '-' and be at most %d characters", prefix, 63-length)
+	}
+	letterID, err := nanoid.Generate(alphabet, length)
+	if err != nil {
+		return "", err
+	}
+	return prefix + letterID, nil
 }
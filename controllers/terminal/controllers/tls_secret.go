@@ -0,0 +1,73 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+const (
+	// ConditionTypeTLSSecretReady reports whether the TLS secret referenced by the custom-domain gateway exists
+	ConditionTypeTLSSecretReady = "TLSSecretReady"
+	// ReasonTLSSecretFound means the TLS secret exists in the gateway namespace and the gateway was synced
+	ReasonTLSSecretFound = "TLSSecretFound"
+	// ReasonTLSSecretNotFound means the gateway was not created because its TLS secret is missing
+	ReasonTLSSecretNotFound = "TLSSecretNotFound"
+
+	// tlsSecretRequeueInterval is how often networking is retried while the TLS secret is missing
+	tlsSecretRequeueInterval = 30 * time.Second
+)
+
+// setTLSSecretCondition records whether the TLS secret of the custom-domain gateway exists, syncErr is the
+// networking error or nil. The condition is only added once the secret was found missing, so terminals without a
+// custom domain never carry it.
+func (r *TerminalReconciler) setTLSSecretCondition(ctx context.Context, terminal *terminalv1.Terminal, syncErr error) error {
+	condition := metav1.Condition{
+		Type:               ConditionTypeTLSSecretReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonTLSSecretFound,
+		Message:            "the TLS secret referenced by the gateway exists",
+		ObservedGeneration: terminal.Generation,
+	}
+	if syncErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonTLSSecretNotFound
+		condition.Message = syncErr.Error()
+	}
+
+	existing := meta.FindStatusCondition(terminal.Status.Conditions, ConditionTypeTLSSecretReady)
+	if existing == nil && syncErr == nil {
+		return nil
+	}
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return nil
+	}
+
+	if syncErr != nil {
+		r.recorder.Eventf(terminal, corev1.EventTypeWarning, ReasonTLSSecretNotFound, "%s", condition.Message)
+	}
+	return retryStatusUpdateOnConflict(ctx, r.Client, terminal, func() {
+		meta.SetStatusCondition(&terminal.Status.Conditions, condition)
+	})
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/istio"
+	terminalv1 "github.com/labring/sealos/controllers/terminal/api/v1"
+)
+
+func TestSetTLSSecretCondition(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := terminalv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add terminal scheme: %v", err)
+	}
+	terminal := &terminalv1.Terminal{ObjectMeta: metav1.ObjectMeta{Name: "test-terminal", Namespace: "ns-test"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(terminal).WithStatusSubresource(terminal).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &TerminalReconciler{Client: c, Scheme: scheme, recorder: recorder}
+	ctx := context.Background()
+
+	get := func() *metav1.Condition {
+		t.Helper()
+		got := &terminalv1.Terminal{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(terminal), got); err != nil {
+			t.Fatalf("get terminal: %v", err)
+		}
+		return meta.FindStatusCondition(got.Status.Conditions, ConditionTypeTLSSecretReady)
+	}
+
+	// secret present from the start: no condition is added
+	if err := r.setTLSSecretCondition(ctx, terminal, nil); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	if condition := get(); condition != nil {
+		t.Fatalf("condition = %+v, want none while the secret exists", condition)
+	}
+
+	// secret missing: the condition explains why networking is not ready
+	missing := fmt.Errorf("%w: secret ns-test/test-terminal-tls", istio.ErrTLSSecretNotFound)
+	if err := r.setTLSSecretCondition(ctx, terminal, missing); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	condition := get()
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != ReasonTLSSecretNotFound ||
+		condition.Message != missing.Error() {
+		t.Fatalf("condition = %+v, want False/%s", condition, ReasonTLSSecretNotFound)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("events = %d, want 1 warning for the missing secret", len(recorder.Events))
+	}
+
+	// repeated requeues do not emit more events
+	if err := r.setTLSSecretCondition(ctx, terminal, missing); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("events = %d, want no new event for an unchanged condition", len(recorder.Events))
+	}
+
+	// secret created: the condition flips back
+	if err := r.setTLSSecretCondition(ctx, terminal, nil); err != nil {
+		t.Fatalf("setTLSSecretCondition() error = %v", err)
+	}
+	if condition := get(); condition == nil || condition.Status != metav1.ConditionTrue || condition.Reason != ReasonTLSSecretFound {
+		t.Errorf("condition = %+v, want True/%s", condition, ReasonTLSSecretFound)
+	}
+}
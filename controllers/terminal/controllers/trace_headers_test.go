@@ -0,0 +1,80 @@
+/*
+Copyright 2025 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/labring/sealos/controllers/pkg/istio"
+)
+
+func TestBuildIstioNetworkConfig_TraceHeaders(t *testing.T) {
+	t.Setenv("ISTIO_BASE_DOMAIN", "cloud.sealos.io")
+	t.Setenv("ISTIO_PRESERVE_TRACE_HEADERS", "true")
+	t.Setenv("ISTIO_TRACE_HEADERS", "traceparent, x-request-id")
+	t.Setenv("ISTIO_TRACE_SAMPLING_DECISION", "1")
+	config := (&TerminalReconciler{}).buildIstioNetworkConfig()
+	if !config.PreserveTraceHeaders || config.TraceSamplingDecision != "1" || !slices.Equal(config.TraceHeaders, []string{"traceparent", "x-request-id"}) {
+		t.Fatalf("trace config = %v/%v/%q, want env values", config.PreserveTraceHeaders, config.TraceHeaders, config.TraceSamplingDecision)
+	}
+
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	err := istio.NewVirtualServiceController(c, config).Create(ctx, &istio.VirtualServiceConfig{
+		Name:        "terminal-vs",
+		Namespace:   "ns-test",
+		Hosts:       []string{"terminal.cloud.sealos.io"},
+		Gateways:    []string{config.DefaultGateway},
+		ServiceName: "terminal",
+		ServicePort: 8080,
+		Headers:     map[string]string{"traceparent": "forged", "secret-header": "1"},
+		CorsPolicy:  &istio.CorsPolicy{AllowHeaders: []string{"content-type"}},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"})
+	if err := c.Get(ctx, types.NamespacedName{Name: "terminal-vs", Namespace: "ns-test"}, vs); err != nil {
+		t.Fatalf("failed to get virtualservice: %v", err)
+	}
+	routes, _, _ := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if len(routes) == 0 {
+		t.Fatal("virtualservice has no http routes")
+	}
+	route := routes[0].(map[string]interface{})
+	set, _, _ := unstructured.NestedStringMap(route, "headers", "request", "set")
+	if _, ok := set["traceparent"]; ok {
+		t.Errorf("request headers %v should not overwrite traceparent", set)
+	}
+	if set["secret-header"] != "1" || set[istio.TraceSampledHeader] != "1" {
+		t.Errorf("request headers = %v, want secret-header and %s=1", set, istio.TraceSampledHeader)
+	}
+	allowHeaders, _, _ := unstructured.NestedStringSlice(route, "corsPolicy", "allowHeaders")
+	if !slices.Contains(allowHeaders, "traceparent") || !slices.Contains(allowHeaders, "x-request-id") {
+		t.Errorf("corsPolicy.allowHeaders = %v, want the trace headers", allowHeaders)
+	}
+}
@@ -120,14 +120,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.TerminalReconciler{
+	terminalReconciler := &controllers.TerminalReconciler{
 		Client:    mgr.GetClient(),
 		Scheme:    mgr.GetScheme(),
 		CtrConfig: config,
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if err = terminalReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Terminal")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&terminalv1.Terminal{}).SetupWebhookWithManager(mgr, terminalReconciler.NewCustomDomainValidator()); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Terminal")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
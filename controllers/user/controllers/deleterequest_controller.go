@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -44,6 +45,8 @@ type DeleteRequestReconciler struct {
 	expirationTime time.Duration
 	// retentionTime is the time duration of the request is retained after it is isCompleted
 	retentionTime time.Duration
+	// clock is the time source of expiration and retention, nil means the real time
+	clock clock.PassiveClock
 }
 
 const DeleteRequestRequeueDuration time.Duration = 30 * time.Second
@@ -138,9 +141,17 @@ func (r *DeleteRequestReconciler) reconcile(ctx context.Context, request *userv1
 	return ctrl.Result{}, nil
 }
 
+// now returns the current time of the reconciler's clock
+func (r *DeleteRequestReconciler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
 // isRetained returns true if the request is isCompleted and exist for retention time
 func (r *DeleteRequestReconciler) isRetained(request *userv1.DeleteRequest) bool {
-	if request.Status.Phase == userv1.RequestCompleted && request.CreationTimestamp.Add(r.retentionTime).Before(time.Now()) {
+	if request.Status.Phase == userv1.RequestCompleted && request.CreationTimestamp.Add(r.retentionTime).Before(r.now()) {
 		return true
 	}
 	return false
@@ -153,7 +164,7 @@ func (r *DeleteRequestReconciler) isCompleted(request *userv1.DeleteRequest) boo
 
 // isExpired returns true if the request is expired
 func (r *DeleteRequestReconciler) isExpired(request *userv1.DeleteRequest) bool {
-	if request.Status.Phase != userv1.RequestCompleted && request.CreationTimestamp.Add(r.expirationTime).Before(time.Now()) {
+	if request.Status.Phase != userv1.RequestCompleted && request.CreationTimestamp.Add(r.expirationTime).Before(r.now()) {
 		return true
 	}
 	return false
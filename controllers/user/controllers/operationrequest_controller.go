@@ -37,6 +37,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	controller "sigs.k8s.io/controller-runtime/pkg/controller"
@@ -58,6 +59,8 @@ type OperationReqReconciler struct {
 	expirationTime time.Duration
 	// retentionTime is the time duration of the request is retained after it is isCompleted
 	retentionTime time.Duration
+	// clock is the time source of expiration and retention, nil means the real time
+	clock clock.PassiveClock
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -237,9 +240,17 @@ func (r *OperationReqReconciler) reconcile(ctx context.Context, request *userv1.
 	return ctrl.Result{RequeueAfter: OperationReqRequeueDuration}, nil
 }
 
+// now returns the current time of the reconciler's clock
+func (r *OperationReqReconciler) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
 // isRetained returns true if the request is isCompleted and exist for retention time
 func (r *OperationReqReconciler) isRetained(request *userv1.Operationrequest) bool {
-	if request.Status.Phase == userv1.RequestCompleted && request.CreationTimestamp.Add(r.retentionTime).Before(time.Now()) {
+	if request.Status.Phase == userv1.RequestCompleted && request.CreationTimestamp.Add(r.retentionTime).Before(r.now()) {
 		return true
 	}
 	return false
@@ -252,7 +263,7 @@ func (r *OperationReqReconciler) isCompleted(request *userv1.Operationrequest) b
 
 // isExpired returns true if the request is expired
 func (r *OperationReqReconciler) isExpired(request *userv1.Operationrequest) bool {
-	if request.Status.Phase != userv1.RequestCompleted && request.CreationTimestamp.Add(r.expirationTime).Before(time.Now()) {
+	if request.Status.Phase != userv1.RequestCompleted && request.CreationTimestamp.Add(r.expirationTime).Before(r.now()) {
 		return true
 	}
 	return false
@@ -58,6 +58,8 @@ func AdminGetAccountWithWorkspaceID(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "failed to charge billing"
 // @Router /admin/v1alpha1/charge [post]
 func AdminChargeBilling(c *gin.Context) {
+	audit := newAdminAudit(c, "ChargeBilling")
+	defer audit.emit()
 	err := authenticateAdminRequest(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)})
@@ -68,6 +70,13 @@ func AdminChargeBilling(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request : %v", err)})
 		return
 	}
+	audit.target(billingReq.UserUID.String(), map[string]interface{}{
+		"amount":    billingReq.Amount,
+		"namespace": billingReq.Namespace,
+		"owner":     billingReq.Owner,
+		"appType":   billingReq.AppType,
+		"appName":   billingReq.AppName,
+	})
 	helper.CallCounter.WithLabelValues("ChargeBilling", billingReq.UserUID.String()).Inc()
 	err = dao.DBClient.ChargeBilling(billingReq)
 	if err != nil {
@@ -175,18 +184,53 @@ func authenticateAdminRequest(c *gin.Context) error {
 	if user.Requester != AdminUserName {
 		return fmt.Errorf("user is not admin")
 	}
+	c.Set(helper.AuditAdminContextKey, user.Requester)
 	return nil
 }
 
+// adminAudit collects the audit record of an admin call, emitted when the handler returns
+type adminAudit struct {
+	c      *gin.Context
+	record *helper.AuditRecord
+}
+
+func newAdminAudit(c *gin.Context, operation string) *adminAudit {
+	return &adminAudit{
+		c: c,
+		record: &helper.AuditRecord{
+			Operation: operation,
+			ClientIP:  c.ClientIP(),
+		},
+	}
+}
+
+// target records the user the operation acts on and its parameters; secrets must not be passed here
+func (a *adminAudit) target(userUID string, params map[string]interface{}) {
+	a.record.TargetUser = userUID
+	a.record.Params = params
+}
+
+func (a *adminAudit) emit() {
+	a.record.Admin = a.c.GetString(helper.AuditAdminContextKey)
+	a.record.StatusCode = a.c.Writer.Status()
+	a.record.Outcome = helper.AuditOutcomeSuccess
+	if a.record.StatusCode >= http.StatusBadRequest {
+		a.record.Outcome = helper.AuditOutcomeFailure
+	}
+	helper.EmitAudit(a.record)
+}
+
 func AdminSuspendUserTraffic(c *gin.Context) {
-	adminUserTrafficOperator(c, SuspendNetworkNamespaceAnnoStatus)
+	adminUserTrafficOperator(c, "SuspendUserTraffic", SuspendNetworkNamespaceAnnoStatus)
 }
 
 func AdminResumeUserTraffic(c *gin.Context) {
-	adminUserTrafficOperator(c, ResumeNetworkNamespaceAnnoStatus)
+	adminUserTrafficOperator(c, "ResumeUserTraffic", ResumeNetworkNamespaceAnnoStatus)
 }
 
-func adminUserTrafficOperator(c *gin.Context, networkStatus string) {
+func adminUserTrafficOperator(c *gin.Context, operation, networkStatus string) {
+	audit := newAdminAudit(c, operation)
+	defer audit.emit()
 	err := authenticateAdminRequest(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)})
@@ -197,6 +241,7 @@ func adminUserTrafficOperator(c *gin.Context, networkStatus string) {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: "empty userUID"})
 		return
 	}
+	audit.target(userUIDStr, map[string]interface{}{"networkStatus": networkStatus})
 	userUID, err := uuid.Parse(userUIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: fmt.Sprintf("invalid userUID format: %v", err)})
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/controllers/pkg/utils"
+
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+)
+
+type captureAuditSink struct {
+	mu      sync.Mutex
+	records []*helper.AuditRecord
+}
+
+func (s *captureAuditSink) Write(record *helper.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+// auditTestDB 只实现审计测试涉及的 DB 方法
+type auditTestDB struct {
+	dao.Interface
+	user    *types.User
+	charged *helper.AdminChargeBillingReq
+}
+
+func (d *auditTestDB) ChargeBilling(req *helper.AdminChargeBillingReq) error {
+	d.charged = req
+	return nil
+}
+
+func (d *auditTestDB) GetUserID(types.UserQueryOpts) (string, error) {
+	return d.user.ID, nil
+}
+
+func (d *auditTestDB) GetUser(*types.UserQueryOpts) (*types.User, error) {
+	return d.user, nil
+}
+
+func (d *auditTestDB) GetLocalRegion() types.Region {
+	return types.Region{UID: uuid.New()}
+}
+
+func setupAuditTest(t *testing.T) (*captureAuditSink, *auditTestDB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	oldDB, oldJwt := dao.DBClient, dao.JwtMgr
+	db := &auditTestDB{user: &types.User{UID: uuid.New(), ID: "user-id", Name: "testuser"}}
+	dao.DBClient = db
+	dao.JwtMgr = utils.NewJWTManager("audit-test-secret", time.Minute)
+
+	sink := &captureAuditSink{}
+	helper.SetAuditSink(sink)
+	t.Cleanup(func() {
+		dao.DBClient, dao.JwtMgr = oldDB, oldJwt
+		helper.SetAuditSink(helper.NewWriterAuditSink(bytes.NewBuffer(nil)))
+	})
+	return sink, db
+}
+
+func doAdminRequest(t *testing.T, handler gin.HandlerFunc, requester string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.POST("/admin", handler)
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("marshal body: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/admin", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if requester != "" {
+		token, err := dao.JwtMgr.GenerateToken(utils.JwtUser{Requester: requester})
+		if err != nil {
+			t.Fatalf("generate token: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminChargeBilling_EmitsAudit(t *testing.T) {
+	sink, db := setupAuditTest(t)
+	userUID := uuid.New()
+
+	w := doAdminRequest(t, AdminChargeBilling, AdminUserName, helper.AdminChargeBillingReq{
+		Amount:    100,
+		Namespace: "ns-test",
+		Owner:     "test",
+		AppType:   "APP-STORE",
+		AppName:   "demo",
+		UserUID:   userUID,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if db.charged == nil || db.charged.Amount != 100 {
+		t.Fatalf("ChargeBilling not called with request: %+v", db.charged)
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Operation != "ChargeBilling" || record.Admin != AdminUserName || record.TargetUser != userUID.String() {
+		t.Errorf("unexpected audit record: %+v", record)
+	}
+	if record.Outcome != helper.AuditOutcomeSuccess || record.StatusCode != http.StatusOK {
+		t.Errorf("outcome = %s/%d, want success/200", record.Outcome, record.StatusCode)
+	}
+	if record.Params["amount"] != int64(100) || record.Params["namespace"] != "ns-test" || record.Params["appName"] != "demo" {
+		t.Errorf("unexpected params: %v", record.Params)
+	}
+	if record.Time.IsZero() {
+		t.Error("audit record time not set")
+	}
+}
+
+func TestAdminChargeBilling_AuditsRejectedRequest(t *testing.T) {
+	sink, db := setupAuditTest(t)
+
+	w := doAdminRequest(t, AdminChargeBilling, "not-admin", helper.AdminChargeBillingReq{Amount: 100, UserUID: uuid.New()})
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+	if db.charged != nil {
+		t.Fatal("ChargeBilling should not be called for non-admin")
+	}
+	if len(sink.records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Outcome != helper.AuditOutcomeFailure || record.Admin != "" || record.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unexpected audit record: %+v", record)
+	}
+}
+
+func TestAdminGetUserToken_EmitsAudit(t *testing.T) {
+	sink, db := setupAuditTest(t)
+
+	w := doAdminRequest(t, AdminGetUserToken, AdminUserName, helper.GetUserTokenReq{Username: "testuser"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp helper.GetUserTokenResp
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Token == "" {
+		t.Fatalf("no token in response: %v, %s", err, w.Body.String())
+	}
+
+	if len(sink.records) != 1 {
+		t.Fatalf("expected 1 audit record, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Operation != "GetUserToken" || record.Admin != AdminUserName || record.TargetUser != db.user.UID.String() {
+		t.Errorf("unexpected audit record: %+v", record)
+	}
+	if record.Outcome != helper.AuditOutcomeSuccess || record.Params["username"] != "testuser" {
+		t.Errorf("unexpected audit record: %+v", record)
+	}
+	// 签发的 token 不能出现在审计记录中
+	data, _ := json.Marshal(record)
+	if bytes.Contains(data, []byte(resp.Token)) {
+		t.Error("audit record leaks the issued token")
+	}
+}
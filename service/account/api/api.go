@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -438,7 +439,7 @@ func SetPaymentInvoice(c *gin.Context) {
 // @Produce json
 // @Param request body helper.TransferAmountReq true "Transfer amount request"
 // @Success 200 {object} map[string]interface{} "successfully transfer amount"
-// @Failure 400 {object} map[string]interface{} "failed to parse transfer amount request"
+// @Failure 400 {object} map[string]interface{} "failed to parse transfer amount request, or the recipient is in a debt state"
 // @Failure 401 {object} map[string]interface{} "authenticate error"
 // @Failure 500 {object} map[string]interface{} "failed to transfer amount"
 // @Router /account/v1alpha1/transfer [post]
@@ -452,6 +453,21 @@ func TransferAmount(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)})
 		return
 	}
+	// 用户在同一区域内转账，账户币种即区域支付币种，不支持跨币种转账
+	if err := helper.CheckCurrency(req.Currency, dao.PaymentCurrency); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// 收款用户处于欠费暂停或删除阶段时转入的余额会被搁置，拒绝转账
+	toUserDebtStatus, err := dao.DBClient.GetUserDebtStatus(&types.UserQueryOpts{ID: req.ToUser})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get recipient debt status : %v", err)})
+		return
+	}
+	if types.ContainDebtStatus(types.DebtStates, toUserDebtStatus) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("recipient %s is in %s, transfer is not allowed", req.ToUser, toUserDebtStatus)})
+		return
+	}
 	if err := dao.DBClient.Transfer(req); err != nil {
 		if err == cockroach.ErrInsufficientBalance {
 			c.JSON(http.StatusOK, gin.H{
@@ -463,7 +479,8 @@ func TransferAmount(c *gin.Context) {
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "successfully transfer amount",
+		"message":  "successfully transfer amount",
+		"currency": dao.PaymentCurrency,
 	})
 }
 
@@ -538,6 +555,16 @@ func GetAPPCosts(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get app cost : %v", err)})
 		return
 	}
+	if req.DetectAnomaly {
+		multiple := req.AnomalyMultiple
+		if multiple <= 0 {
+			if multiple, err = helper.GetCostAnomalyMultiple(); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		helper.FlagAnomalousCosts(cost.Costs, multiple)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"app_costs": cost,
 	})
@@ -1007,7 +1034,7 @@ func UseGiftCode(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param request body helper.UserUsageReq true "User usage request"
-// @Success 200 {object} map[string]interface{} "successfully retrieved user usage"
+// @Success 200 {object} map[string]interface{} "successfully retrieved user usage, errors holds per-namespace failures when only part of the namespaces succeeded"
 // @Failure 400 {object} map[string]interface{} "failed to parse user usage request"
 // @Failure 401 {object} map[string]interface{} "authenticate error"
 // @Failure 500 {object} map[string]interface{} "failed to get user usage"
@@ -1022,14 +1049,50 @@ func UserUsage(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)})
 		return
 	}
-	usage, err := dao.DBClient.GetMonitorUniqueValues(req.StartTime, req.EndTime, req.NamespaceList)
+	usage, nsErrors, err := getUserUsage(req.StartTime, req.EndTime, req.NamespaceList)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get user usage : %v", err)})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to get user usage : %v", err), "errors": nsErrors})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"data": usage,
-	})
+	}
+	if len(nsErrors) > 0 {
+		resp["errors"] = nsErrors
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// getUserUsage 先批量查询所有命名空间的用量，批量查询失败时逐个命名空间查询，
+// 返回成功命名空间的用量以及失败命名空间的错误；全部失败时返回 error
+func getUserUsage(startTime, endTime time.Time, namespaces []string) ([]common.Monitor, map[string]string, error) {
+	usage, err := dao.DBClient.GetMonitorUniqueValues(startTime, endTime, namespaces)
+	if err == nil || len(namespaces) == 0 {
+		return usage, nil, err
+	}
+	usage = []common.Monitor{}
+	nsErrors := make(map[string]string)
+	seen := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		if _, ok := seen[ns]; ok {
+			continue
+		}
+		seen[ns] = struct{}{}
+		if strings.TrimSpace(ns) == "" {
+			nsErrors[ns] = "namespace is empty"
+			continue
+		}
+		nsUsage, nsErr := dao.DBClient.GetMonitorUniqueValues(startTime, endTime, []string{ns})
+		if nsErr != nil {
+			nsErrors[ns] = nsErr.Error()
+			continue
+		}
+		usage = append(usage, nsUsage...)
+	}
+	if len(nsErrors) == len(seen) {
+		return nil, nsErrors, err
+	}
+	return usage, nsErrors, nil
 }
 
 // GetRechargeDiscount
@@ -25,6 +25,7 @@ import (
 	"github.com/labring/sealos/controllers/pkg/types"
 	"github.com/labring/sealos/service/account/dao"
 	"github.com/labring/sealos/service/account/helper"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -34,6 +35,8 @@ func init() {
 }
 
 func AdminFlushDebtResourceStatus(c *gin.Context) {
+	audit := newAdminAudit(c, "FlushDebtResourceStatus")
+	defer audit.emit()
 	err := authenticateAdminRequest(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)})
@@ -44,6 +47,11 @@ func AdminFlushDebtResourceStatus(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)})
 		return
 	}
+	audit.target(req.UserUID.String(), map[string]interface{}{
+		"lastDebtStatus":    req.LastDebtStatus,
+		"currentDebtStatus": req.CurrentDebtStatus,
+		"isBasicUser":       req.IsBasicUser,
+	})
 	owner, err := dao.DBClient.GetUserCrName(types.UserQueryOpts{UID: req.UserUID})
 	if err != nil && err != gorm.ErrRecordNotFound {
 		c.JSON(http.StatusInternalServerError, helper.ErrorMessage{Error: fmt.Sprintf("failed to get user cr name: %v", err)})
@@ -62,9 +70,34 @@ func AdminFlushDebtResourceStatus(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, helper.ErrorMessage{Error: fmt.Sprintf("failed to flush user resource status: %v", err)})
 		return
 	}
+	notifyDebtStatusTransition(dao.DebtStatusWebhook, req)
 	c.JSON(http.StatusOK, gin.H{"success": true})
 }
 
+// notifyDebtStatusTransition 异步通知外部系统用户欠费状态变更，投递失败不影响本次请求
+func notifyDebtStatusTransition(webhook *helper.DebtStatusWebhook, req *helper.AdminFlushDebtResourceStatusReq) <-chan error {
+	done := make(chan error, 1)
+	if webhook == nil || req.LastDebtStatus == req.CurrentDebtStatus {
+		close(done)
+		return done
+	}
+	event := &helper.DebtStatusEvent{
+		UserUID:   req.UserUID,
+		OldStatus: req.LastDebtStatus,
+		NewStatus: req.CurrentDebtStatus,
+		Timestamp: time.Now().UTC(),
+	}
+	go func() {
+		err := webhook.Send(context.Background(), event)
+		if err != nil {
+			logrus.Errorf("failed to notify debt status transition: %v", err)
+		}
+		done <- err
+		close(done)
+	}()
+	return done
+}
+
 func flushUserDebtResourceStatus(req *helper.AdminFlushDebtResourceStatusReq, clt client.Client, namespaces []string) error {
 	switch req.LastDebtStatus {
 	case types.NormalPeriod, types.LowBalancePeriod, types.CriticalBalancePeriod:
@@ -270,6 +303,8 @@ func readNotice(ctx context.Context, clt client.Client, namespaces []string, not
 }
 
 func AdminFlushSubscriptionQuota(c *gin.Context) {
+	audit := newAdminAudit(c, "FlushSubscriptionQuota")
+	defer audit.emit()
 	err := authenticateAdminRequest(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)})
@@ -280,6 +315,10 @@ func AdminFlushSubscriptionQuota(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)})
 		return
 	}
+	audit.target(req.UserUID.String(), map[string]interface{}{
+		"planName": req.PlanName,
+		"planID":   req.PlanID.String(),
+	})
 	owner, err := dao.DBClient.GetUserCrName(types.UserQueryOpts{UID: req.UserUID})
 	if err != nil && err != gorm.ErrRecordNotFound {
 		c.JSON(http.StatusInternalServerError, helper.ErrorMessage{Error: fmt.Sprintf("failed to get user cr name: %v", err)})
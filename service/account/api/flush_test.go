@@ -0,0 +1,53 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+
+	"github.com/labring/sealos/service/account/helper"
+)
+
+func TestNotifyDebtStatusTransition(t *testing.T) {
+	received := make(chan helper.DebtStatusEvent, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event helper.DebtStatusEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		received <- event
+	}))
+	defer server.Close()
+	webhook := &helper.DebtStatusWebhook{URL: server.URL, InitialBackoff: time.Millisecond}
+
+	req := &helper.AdminFlushDebtResourceStatusReq{
+		UserUID:           uuid.New(),
+		LastDebtStatus:    types.NormalPeriod,
+		CurrentDebtStatus: types.DebtPeriod,
+	}
+	if err := <-notifyDebtStatusTransition(webhook, req); err != nil {
+		t.Fatalf("notify error = %v", err)
+	}
+	select {
+	case event := <-received:
+		if event.UserUID != req.UserUID || event.OldStatus != types.NormalPeriod || event.NewStatus != types.DebtPeriod || event.Timestamp.IsZero() {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatal("webhook not called on transition")
+	}
+
+	// 状态未变化或未配置 webhook 时不通知
+	req.LastDebtStatus = types.DebtPeriod
+	<-notifyDebtStatusTransition(webhook, req)
+	<-notifyDebtStatusTransition(nil, &helper.AdminFlushDebtResourceStatusReq{LastDebtStatus: types.NormalPeriod, CurrentDebtStatus: types.DebtPeriod})
+	if len(received) != 0 {
+		t.Error("webhook called without transition")
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/controllers/pkg/utils"
+
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+)
+
+type transferTestDB struct {
+	dao.Interface
+	region      types.Region
+	transferred *helper.TransferAmountReq
+	debtStatus  map[string]types.DebtStatusType
+}
+
+func (d *transferTestDB) Transfer(req *helper.TransferAmountReq) error {
+	d.transferred = req
+	return nil
+}
+
+func (d *transferTestDB) GetUserDebtStatus(ops *types.UserQueryOpts) (types.DebtStatusType, error) {
+	if status, ok := d.debtStatus[ops.ID]; ok {
+		return status, nil
+	}
+	return types.NormalPeriod, nil
+}
+
+func (d *transferTestDB) GetLocalRegion() types.Region {
+	return d.region
+}
+
+func TestTransferAmount_Currency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	oldDB, oldJwt, oldCurrency := dao.DBClient, dao.JwtMgr, dao.PaymentCurrency
+	t.Cleanup(func() {
+		dao.DBClient, dao.JwtMgr, dao.PaymentCurrency = oldDB, oldJwt, oldCurrency
+	})
+	dao.JwtMgr = utils.NewJWTManager("transfer-test-secret", time.Minute)
+	dao.PaymentCurrency = "USD"
+
+	tests := []struct {
+		name            string
+		currency        string
+		wantStatus      int
+		wantTransferred bool
+	}{
+		{name: "same currency", currency: "usd", wantStatus: http.StatusOK, wantTransferred: true},
+		{name: "currency omitted", wantStatus: http.StatusOK, wantTransferred: true},
+		{name: "cross currency", currency: "CNY", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &transferTestDB{region: types.Region{UID: uuid.New()}}
+			dao.DBClient = db
+			token, err := dao.JwtMgr.GenerateToken(utils.JwtUser{
+				UserUID:    uuid.New(),
+				UserID:     "from-user",
+				UserCrName: "from-user",
+				RegionUID:  db.region.UID.String(),
+			})
+			if err != nil {
+				t.Fatalf("generate token: %v", err)
+			}
+
+			body, _ := json.Marshal(helper.TransferAmountReq{Amount: 1000000, Currency: tt.currency, ToUser: "to-user"})
+			req := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/transfer", TransferAmount)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if (db.transferred != nil) != tt.wantTransferred {
+				t.Fatalf("transferred = %+v, want %v", db.transferred, tt.wantTransferred)
+			}
+			if tt.wantTransferred {
+				var resp map[string]string
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp["currency"] != "USD" {
+					t.Errorf("response = %s, want currency USD", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestTransferAmount_RecipientDebtStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	oldDB, oldJwt, oldCurrency := dao.DBClient, dao.JwtMgr, dao.PaymentCurrency
+	t.Cleanup(func() {
+		dao.DBClient, dao.JwtMgr, dao.PaymentCurrency = oldDB, oldJwt, oldCurrency
+	})
+	dao.JwtMgr = utils.NewJWTManager("transfer-test-secret", time.Minute)
+	dao.PaymentCurrency = "USD"
+
+	tests := []struct {
+		name            string
+		status          types.DebtStatusType
+		wantStatus      int
+		wantTransferred bool
+	}{
+		{name: "active recipient", status: types.NormalPeriod, wantStatus: http.StatusOK, wantTransferred: true},
+		{name: "low balance recipient", status: types.LowBalancePeriod, wantStatus: http.StatusOK, wantTransferred: true},
+		{name: "suspended recipient", status: types.DebtPeriod, wantStatus: http.StatusBadRequest},
+		{name: "deleting recipient", status: types.FinalDeletionPeriod, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &transferTestDB{
+				region:     types.Region{UID: uuid.New()},
+				debtStatus: map[string]types.DebtStatusType{"to-user": tt.status},
+			}
+			dao.DBClient = db
+			token, err := dao.JwtMgr.GenerateToken(utils.JwtUser{
+				UserUID:    uuid.New(),
+				UserID:     "from-user",
+				UserCrName: "from-user",
+				RegionUID:  db.region.UID.String(),
+			})
+			if err != nil {
+				t.Fatalf("generate token: %v", err)
+			}
+
+			body, _ := json.Marshal(helper.TransferAmountReq{Amount: 1000000, ToUser: "to-user"})
+			req := httptest.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/transfer", TransferAmount)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if (db.transferred != nil) != tt.wantTransferred {
+				t.Fatalf("transferred = %+v, want %v", db.transferred, tt.wantTransferred)
+			}
+		})
+	}
+}
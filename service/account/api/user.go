@@ -26,12 +26,20 @@ import (
 // @Failure 500 {object} helper.ErrorMessage "failed to create user"
 // @Router /admin/v1alpha1/create-user [post]
 func AdminCreateUser(c *gin.Context) {
+	audit := newAdminAudit(c, "CreateUser")
+	defer audit.emit()
+
 	// Parse the create user request
 	req, err := helper.ParseCreateUserReq(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: fmt.Sprintf("failed to parse create user request: %v", err)})
 		return
 	}
+	audit.target("", map[string]interface{}{
+		"username":       req.Username,
+		"userID":         req.UserID,
+		"initialBalance": req.InitialBalance,
+	})
 
 	// Authenticate admin request
 	if err := authenticateAdminRequest(c); err != nil {
@@ -138,12 +146,19 @@ func initializeUserQuota(username string) error {
 // @Failure 500 {object} helper.ErrorMessage "failed to generate token"
 // @Router /admin/v1alpha1/get-user-token [post]
 func AdminGetUserToken(c *gin.Context) {
+	audit := newAdminAudit(c, "GetUserToken")
+	defer audit.emit()
+
 	// Parse the request
 	req, err := helper.ParseGetUserTokenReq(c)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)})
 		return
 	}
+	audit.target(req.UserUID, map[string]interface{}{
+		"username":    req.Username,
+		"workspaceId": req.WorkspaceID,
+	})
 
 	// Authenticate admin request
 	if err := authenticateAdminRequest(c); err != nil {
@@ -190,6 +205,8 @@ func AdminGetUserToken(c *gin.Context) {
 		}
 	}
 
+	audit.record.TargetUser = userUID.String()
+
 	// Get workspace information if workspace ID is provided
 	var workspaceUID uuid.UUID
 	if req.WorkspaceID != "" {
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/controllers/pkg/utils"
+
+	"github.com/labring/sealos/service/account/common"
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+)
+
+type userUsageTestDB struct {
+	dao.Interface
+	region types.Region
+	failed map[string]bool
+}
+
+func (d *userUsageTestDB) GetMonitorUniqueValues(_, _ time.Time, namespaces []string) ([]common.Monitor, error) {
+	var usage []common.Monitor
+	for _, ns := range namespaces {
+		if d.failed[ns] {
+			return nil, errors.New("query " + ns + " failed")
+		}
+		usage = append(usage, common.Monitor{Namespace: ns, Name: ns + "-app"})
+	}
+	return usage, nil
+}
+
+func (d *userUsageTestDB) GetLocalRegion() types.Region {
+	return d.region
+}
+
+func TestUserUsage_PartialResults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	oldDB, oldJwt := dao.DBClient, dao.JwtMgr
+	t.Cleanup(func() {
+		dao.DBClient, dao.JwtMgr = oldDB, oldJwt
+	})
+	dao.JwtMgr = utils.NewJWTManager("user-usage-test-secret", time.Minute)
+
+	tests := []struct {
+		name           string
+		namespaces     []string
+		failed         map[string]bool
+		wantStatus     int
+		wantNamespaces []string
+		wantErrors     []string
+	}{
+		{
+			name:           "all namespaces succeed",
+			namespaces:     []string{"ns-a", "ns-b"},
+			wantStatus:     http.StatusOK,
+			wantNamespaces: []string{"ns-a", "ns-b"},
+		},
+		{
+			name:           "mixed valid and failing namespaces",
+			namespaces:     []string{"ns-a", "ns-bad", "ns-b", ""},
+			failed:         map[string]bool{"ns-bad": true},
+			wantStatus:     http.StatusOK,
+			wantNamespaces: []string{"ns-a", "ns-b"},
+			wantErrors:     []string{"ns-bad", ""},
+		},
+		{
+			name:       "all namespaces fail",
+			namespaces: []string{"ns-bad", "ns-worse"},
+			failed:     map[string]bool{"ns-bad": true, "ns-worse": true},
+			wantStatus: http.StatusInternalServerError,
+			wantErrors: []string{"ns-bad", "ns-worse"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &userUsageTestDB{region: types.Region{UID: uuid.New()}, failed: tt.failed}
+			dao.DBClient = db
+			token, err := dao.JwtMgr.GenerateToken(utils.JwtUser{
+				UserUID:    uuid.New(),
+				UserID:     "usage-user",
+				UserCrName: "usage-user",
+				RegionUID:  db.region.UID.String(),
+			})
+			if err != nil {
+				t.Fatalf("generate token: %v", err)
+			}
+
+			body, _ := json.Marshal(helper.UserUsageReq{NamespaceList: tt.namespaces})
+			req := httptest.NewRequest(http.MethodPost, "/user-usage", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/user-usage", UserUsage)
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp struct {
+				Data   []common.Monitor  `json:"data"`
+				Errors map[string]string `json:"errors"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("unmarshal response: %v", err)
+			}
+			if len(resp.Data) != len(tt.wantNamespaces) {
+				t.Fatalf("data = %+v, want namespaces %v", resp.Data, tt.wantNamespaces)
+			}
+			for i, ns := range tt.wantNamespaces {
+				if resp.Data[i].Namespace != ns {
+					t.Errorf("data[%d].Namespace = %q, want %q", i, resp.Data[i].Namespace, ns)
+				}
+			}
+			if len(resp.Errors) != len(tt.wantErrors) {
+				t.Fatalf("errors = %v, want keys %v", resp.Errors, tt.wantErrors)
+			}
+			for _, ns := range tt.wantErrors {
+				if resp.Errors[ns] == "" {
+					t.Errorf("missing error for namespace %q in %v", ns, resp.Errors)
+				}
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ type AppCost struct {
 	Used       Used      `json:"used,omitempty" bson:"used,omitempty"`
 	UsedAmount Used      `json:"used_amount,omitempty" bson:"used_amount,omitempty"`
 	Amount     int64     `json:"amount,omitempty" bson:"amount,omitempty" example:"100000000"`
+	Anomalous  bool      `json:"anomalous,omitempty" bson:"-"`
 }
 
 type Used map[uint8]int64
@@ -66,6 +66,7 @@ var (
 	BillingTask          *helper.TaskQueue
 	FlushQuotaProcesser  *FlushQuotaTask
 	K8sManager           ctrl.Manager
+	DebtStatusWebhook    *helper.DebtStatusWebhook
 
 	SendDebtStatusEmailBody map[types.DebtStatusType]string
 	//Debug                bool
@@ -162,6 +163,9 @@ func Init(ctx context.Context) error {
 	if PaymentCurrency = os.Getenv(helper.EnvPaymentCurrency); PaymentCurrency == "" {
 		PaymentCurrency = "USD"
 	}
+	if DebtStatusWebhook, err = helper.NewDebtStatusWebhookFromEnv(); err != nil {
+		return fmt.Errorf("init debt status webhook error: %v", err)
+	}
 	if os.Getenv(helper.EnvSubscriptionEnabled) == "true" {
 		plans, err := DBClient.GetSubscriptionPlanList()
 		if err != nil {
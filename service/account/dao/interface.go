@@ -63,6 +63,7 @@ type Interface interface {
 	GetTransfer(ops *types.GetTransfersReq) (*types.GetTransfersResp, error)
 	GetUserID(ops types.UserQueryOpts) (string, error)
 	GetUserCrName(ops types.UserQueryOpts) (string, error)
+	GetUserDebtStatus(ops *types.UserQueryOpts) (types.DebtStatusType, error)
 	GetRegions() ([]types.Region, error)
 	GetLocalRegion() types.Region
 	UseGiftCode(req *helper.UseGiftCodeReq) (*types.GiftCode, error)
@@ -160,6 +161,22 @@ func (g *Cockroach) GetUserID(ops types.UserQueryOpts) (string, error) {
 	return user.ID, nil
 }
 
+// GetUserDebtStatus 获取用户当前的欠费状态，没有欠费记录时视为正常
+func (g *Cockroach) GetUserDebtStatus(ops *types.UserQueryOpts) (types.DebtStatusType, error) {
+	userUID, err := g.ck.GetUserUID(ops)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user uid: %v", err)
+	}
+	debt := &types.Debt{}
+	if err := g.ck.GetGlobalDB().Where(&types.Debt{UserUID: userUID}).First(debt).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return types.NormalPeriod, nil
+		}
+		return "", fmt.Errorf("failed to get debt: %v", err)
+	}
+	return debt.AccountDebtStatus, nil
+}
+
 func (g *Cockroach) GetUserCrName(ops types.UserQueryOpts) (string, error) {
 	user, err := g.ck.GetUserCr(&ops)
 	if err != nil {
@@ -982,6 +999,7 @@ func (m *MongoDB) GetCostOverview(req helper.GetCostAppListReq) (resp helper.Cos
 		}
 		resp.Overviews = append(resp.Overviews, helper.CostOverview{
 			Amount:    totalAmount,
+			Currency:  PaymentCurrency,
 			Namespace: app.Namespace,
 			AppType:   app.AppType,
 			AppName:   app.AppName,
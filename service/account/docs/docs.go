@@ -1612,6 +1612,11 @@ const docTemplate = `{
         "helper.AppCostsReq": {
             "type": "object",
             "properties": {
+                "anomalyMultiple": {
+                    "description": "@Summary Anomaly multiple\n@Description Multiple of the rolling average above which a record is anomalous, default from COST_ANOMALY_MULTIPLE",
+                    "type": "number",
+                    "example": 3
+                },
                 "appName": {
                     "description": "@Summary App Name\n@Description App Name",
                     "type": "string",
@@ -1622,6 +1627,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": "app"
                 },
+                "detectAnomaly": {
+                    "description": "@Summary Detect anomaly\n@Description Flag records whose cost exceeds a multiple of the rolling average of the same app",
+                    "type": "boolean",
+                    "example": false
+                },
                 "endTime": {
                     "type": "string",
                     "example": "2021-12-01T00:00:00Z"
@@ -1837,6 +1847,11 @@ const docTemplate = `{
                     "description": "@Summary App type\n@Description App type",
                     "type": "integer"
                 },
+                "currency": {
+                    "description": "@Summary Currency\n@Description Currency of the amount (ISO 4217)",
+                    "type": "string",
+                    "example": "USD"
+                },
                 "namespace": {
                     "description": "@Summary Namespace\n@Description Namespace",
                     "type": "string"
@@ -2268,6 +2283,11 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 100000000
                 },
+                "currency": {
+                    "description": "@Summary Currency\n@Description Currency of the amount (ISO 4217), must match the account currency of the region, empty means the region currency",
+                    "type": "string",
+                    "example": "USD"
+                },
                 "kubeConfig": {
                     "type": "string"
                 },
@@ -0,0 +1,70 @@
+package helper
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/labring/sealos/service/account/common"
+)
+
+const (
+	// EnvCostAnomalyMultiple is the default multiple of the rolling average above which a cost record is anomalous.
+	EnvCostAnomalyMultiple = "COST_ANOMALY_MULTIPLE"
+
+	DefaultCostAnomalyMultiple = 3.0
+	// CostAnomalyWindow is the number of preceding records of the same app that make up the rolling average.
+	CostAnomalyWindow = 24
+	// costAnomalyMinSamples avoids flagging the first records of an app, whose average is not meaningful yet.
+	costAnomalyMinSamples = 3
+)
+
+// GetCostAnomalyMultiple returns the multiple from COST_ANOMALY_MULTIPLE, or DefaultCostAnomalyMultiple when unset.
+func GetCostAnomalyMultiple() (float64, error) {
+	value := os.Getenv(EnvCostAnomalyMultiple)
+	if value == "" {
+		return DefaultCostAnomalyMultiple, nil
+	}
+	multiple, err := strconv.ParseFloat(value, 64)
+	if err != nil || multiple <= 1 {
+		return 0, fmt.Errorf("invalid %s %q: must be a number greater than 1", EnvCostAnomalyMultiple, value)
+	}
+	return multiple, nil
+}
+
+// FlagAnomalousCosts sets Anomalous on records whose amount exceeds multiple times the rolling average
+// of the preceding CostAnomalyWindow records of the same namespace and app in the returned period.
+// The order of costs is preserved.
+func FlagAnomalousCosts(costs []common.AppCost, multiple float64) {
+	type appKey struct {
+		namespace string
+		appType   int32
+		appName   string
+	}
+	series := make(map[appKey][]int)
+	for i := range costs {
+		key := appKey{namespace: costs[i].Namespace, appType: costs[i].AppType, appName: costs[i].AppName}
+		series[key] = append(series[key], i)
+	}
+
+	for _, indexes := range series {
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return costs[indexes[a]].Time.Before(costs[indexes[b]].Time)
+		})
+		var windowSum int64
+		for n, idx := range indexes {
+			samples := n
+			if samples > CostAnomalyWindow {
+				samples = CostAnomalyWindow
+			}
+			costs[idx].Anomalous = samples >= costAnomalyMinSamples &&
+				float64(costs[idx].Amount) > multiple*float64(windowSum)/float64(samples)
+
+			windowSum += costs[idx].Amount
+			if n >= CostAnomalyWindow {
+				windowSum -= costs[indexes[n-CostAnomalyWindow]].Amount
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labring/sealos/service/account/common"
+)
+
+func TestFlagAnomalousCosts(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var costs []common.AppCost
+	// 稳定序列，第 10 个小时出现突增；结果按时间倒序返回，与 GetAppCosts 一致
+	for i := 23; i >= 0; i-- {
+		amount := int64(100 + i%3)
+		if i == 10 {
+			amount = 1000
+		}
+		costs = append(costs, common.AppCost{
+			Namespace: "ns-test",
+			AppName:   "app",
+			Time:      start.Add(time.Duration(i) * time.Hour),
+			Amount:    amount,
+		})
+	}
+	// 其他应用的高消费不影响本应用的平均值
+	costs = append(costs, common.AppCost{Namespace: "ns-test", AppName: "other", Time: start, Amount: 100000})
+
+	FlagAnomalousCosts(costs, DefaultCostAnomalyMultiple)
+
+	for _, cost := range costs {
+		wantAnomalous := cost.AppName == "app" && cost.Time.Equal(start.Add(10*time.Hour))
+		if cost.Anomalous != wantAnomalous {
+			t.Errorf("%s at %s amount %d: anomalous = %v, want %v", cost.AppName, cost.Time.Format(time.RFC3339), cost.Amount, cost.Anomalous, wantAnomalous)
+		}
+	}
+	if !costs[0].Time.Equal(start.Add(23 * time.Hour)) {
+		t.Error("FlagAnomalousCosts changed the order of costs")
+	}
+}
+
+func TestFlagAnomalousCosts_NotEnoughSamples(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	costs := []common.AppCost{
+		{AppName: "app", Time: start, Amount: 100},
+		{AppName: "app", Time: start.Add(time.Hour), Amount: 1000},
+	}
+	FlagAnomalousCosts(costs, DefaultCostAnomalyMultiple)
+	for _, cost := range costs {
+		if cost.Anomalous {
+			t.Errorf("record at %s flagged without enough history", cost.Time.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestGetCostAnomalyMultiple(t *testing.T) {
+	if multiple, err := GetCostAnomalyMultiple(); err != nil || multiple != DefaultCostAnomalyMultiple {
+		t.Errorf("default = %v, %v", multiple, err)
+	}
+	t.Setenv(EnvCostAnomalyMultiple, "5")
+	if multiple, err := GetCostAnomalyMultiple(); err != nil || multiple != 5 {
+		t.Errorf("configured = %v, %v", multiple, err)
+	}
+	t.Setenv(EnvCostAnomalyMultiple, "0.5")
+	if _, err := GetCostAnomalyMultiple(); err == nil {
+		t.Error("expected error for multiple <= 1")
+	}
+}
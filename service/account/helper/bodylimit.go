@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	EnvMaxRequestBodySize = "MAX_REQUEST_BODY_SIZE"
+
+	DefaultMaxRequestBodySize int64 = 1 << 20 // 1MiB
+)
+
+// GetMaxRequestBodySize reads MAX_REQUEST_BODY_SIZE (e.g. "1MiB", "512KB" or bytes),
+// falling back to DefaultMaxRequestBodySize when unset or invalid.
+func GetMaxRequestBodySize() int64 {
+	raw := os.Getenv(EnvMaxRequestBodySize)
+	if raw == "" {
+		return DefaultMaxRequestBodySize
+	}
+	size, err := humanize.ParseBytes(raw)
+	if err != nil || size == 0 || size > uint64(1<<62) {
+		return DefaultMaxRequestBodySize
+	}
+	return int64(size)
+}
+
+// BodySizeLimitMiddleware rejects requests whose body exceeds maxBytes.
+// Requests declaring a larger Content-Length are refused up front with 413, other bodies are
+// capped with http.MaxBytesReader so reading past the limit fails while binding JSON.
+func BodySizeLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorMessage{
+				Error: fmt.Sprintf("request body too large: %d bytes exceeds limit of %d bytes", c.Request.ContentLength, maxBytes),
+			})
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,84 @@
+package helper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newBodyLimitTestRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodySizeLimitMiddleware(maxBytes))
+	router.POST(GROUP+GetAccount, func(c *gin.Context) {
+		req := map[string]interface{}{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorMessage{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return router
+}
+
+// unknownLengthReader hides the body length so the request is sent without Content-Length
+type unknownLengthReader struct {
+	io.Reader
+}
+
+func TestBodySizeLimitMiddleware(t *testing.T) {
+	const limit = 1024
+	normalBody := `{"type":0}`
+	oversizedBody := `{"type":0,"padding":"` + strings.Repeat("a", 2*limit) + `"}`
+
+	tests := []struct {
+		name          string
+		body          io.Reader
+		expectStatus  int
+		expectMessage string
+	}{
+		{name: "normal body accepted", body: strings.NewReader(normalBody), expectStatus: http.StatusOK},
+		{name: "oversized body rejected", body: strings.NewReader(oversizedBody), expectStatus: http.StatusRequestEntityTooLarge, expectMessage: "request body too large"},
+		{name: "oversized body without content length rejected", body: unknownLengthReader{strings.NewReader(oversizedBody)}, expectStatus: http.StatusBadRequest, expectMessage: "request body too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newBodyLimitTestRouter(limit)
+			req := httptest.NewRequest(http.MethodPost, GROUP+GetAccount, tt.body)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.expectStatus, w.Body.String())
+			}
+			if tt.expectMessage != "" && !strings.Contains(w.Body.String(), tt.expectMessage) {
+				t.Errorf("body = %s, want message containing %q", w.Body.String(), tt.expectMessage)
+			}
+		})
+	}
+}
+
+func TestGetMaxRequestBodySize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+	}{
+		{value: "", expected: DefaultMaxRequestBodySize},
+		{value: "512KiB", expected: 512 << 10},
+		{value: "2048", expected: 2048},
+		{value: "invalid", expected: DefaultMaxRequestBodySize},
+	}
+
+	for _, tt := range tests {
+		t.Setenv(EnvMaxRequestBodySize, tt.value)
+		if got := GetMaxRequestBodySize(); got != tt.expected {
+			t.Errorf("GetMaxRequestBodySize() with %q = %d, want %d", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package helper
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	EnvCorsAllowedOrigins   = "CORS_ALLOWED_ORIGINS"
+	EnvCorsAllowedMethods   = "CORS_ALLOWED_METHODS"
+	EnvCorsAllowCredentials = "CORS_ALLOW_CREDENTIALS"
+)
+
+var (
+	defaultCorsMethods = []string{http.MethodGet, http.MethodPost, http.MethodOptions}
+	defaultCorsHeaders = []string{"Authorization", "Content-Type"}
+)
+
+// CorsConfig configures cross-origin access to the account service.
+// An empty AllowedOrigins list keeps the service same-origin only.
+type CorsConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+}
+
+// NewCorsConfigFromEnv builds the CORS config from CORS_ALLOWED_ORIGINS (comma separated, "*" allows any origin),
+// CORS_ALLOWED_METHODS and CORS_ALLOW_CREDENTIALS.
+func NewCorsConfigFromEnv() *CorsConfig {
+	config := &CorsConfig{
+		AllowedOrigins: splitAndTrim(os.Getenv(EnvCorsAllowedOrigins)),
+		AllowedMethods: splitAndTrim(os.Getenv(EnvCorsAllowedMethods)),
+	}
+	if allow, err := strconv.ParseBool(os.Getenv(EnvCorsAllowCredentials)); err == nil {
+		config.AllowCredentials = allow
+	}
+	return config
+}
+
+// CorsMiddleware sets CORS response headers for allowed origins and answers preflight requests.
+// Requests from other origins get no CORS headers, so browsers keep blocking them.
+func CorsMiddleware(config *CorsConfig) gin.HandlerFunc {
+	methods := config.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCorsMethods
+	}
+	headers := config.AllowedHeaders
+	if len(headers) == 0 {
+		headers = defaultCorsHeaders
+	}
+	allowedMethods := strings.Join(methods, ", ")
+	allowedHeaders := strings.Join(headers, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if !config.isOriginAllowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Origin")
+		c.Header("Access-Control-Allow-Origin", origin)
+		if config.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", allowedMethods)
+			c.Header("Access-Control-Allow-Headers", allowedHeaders)
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+func (c *CorsConfig) isOriginAllowed(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitAndTrim(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
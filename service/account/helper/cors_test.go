@@ -0,0 +1,130 @@
+package helper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCorsTestRouter(config *CorsConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CorsMiddleware(config))
+	router.POST(GROUP+GetAccount, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	return router
+}
+
+func TestCorsMiddleware(t *testing.T) {
+	config := &CorsConfig{
+		AllowedOrigins:   []string{"https://dashboard.example.com"},
+		AllowCredentials: true,
+	}
+
+	tests := []struct {
+		name              string
+		config            *CorsConfig
+		method            string
+		origin            string
+		preflight         bool
+		expectStatus      int
+		expectAllowOrigin string
+		expectCredentials string
+	}{
+		{
+			name:              "allowed origin",
+			config:            config,
+			method:            http.MethodPost,
+			origin:            "https://dashboard.example.com",
+			expectStatus:      http.StatusOK,
+			expectAllowOrigin: "https://dashboard.example.com",
+			expectCredentials: "true",
+		},
+		{
+			name:         "disallowed origin",
+			config:       config,
+			method:       http.MethodPost,
+			origin:       "https://evil.example.com",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:              "allowed preflight",
+			config:            config,
+			method:            http.MethodOptions,
+			origin:            "https://dashboard.example.com",
+			preflight:         true,
+			expectStatus:      http.StatusNoContent,
+			expectAllowOrigin: "https://dashboard.example.com",
+			expectCredentials: "true",
+		},
+		{
+			name:         "disallowed preflight",
+			config:       config,
+			method:       http.MethodOptions,
+			origin:       "https://evil.example.com",
+			preflight:    true,
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:         "default config is same-origin only",
+			config:       &CorsConfig{},
+			method:       http.MethodPost,
+			origin:       "https://dashboard.example.com",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "same-origin request without Origin header",
+			config:       config,
+			method:       http.MethodPost,
+			expectStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newCorsTestRouter(tt.config)
+			req := httptest.NewRequest(tt.method, GROUP+GetAccount, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.expectStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.expectAllowOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.expectAllowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.expectCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.expectCredentials)
+			}
+			if tt.preflight && tt.expectAllowOrigin != "" && w.Header().Get("Access-Control-Allow-Methods") == "" {
+				t.Error("preflight response missing Access-Control-Allow-Methods")
+			}
+		})
+	}
+}
+
+func TestNewCorsConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvCorsAllowedOrigins, "https://a.example.com, https://b.example.com,")
+	t.Setenv(EnvCorsAllowedMethods, "POST")
+	t.Setenv(EnvCorsAllowCredentials, "true")
+
+	config := NewCorsConfigFromEnv()
+	if len(config.AllowedOrigins) != 2 || config.AllowedOrigins[1] != "https://b.example.com" {
+		t.Errorf("AllowedOrigins = %v", config.AllowedOrigins)
+	}
+	if len(config.AllowedMethods) != 1 || config.AllowedMethods[0] != http.MethodPost {
+		t.Errorf("AllowedMethods = %v", config.AllowedMethods)
+	}
+	if !config.AllowCredentials {
+		t.Error("AllowCredentials = false, want true")
+	}
+}
@@ -0,0 +1,23 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeCurrency returns the upper-cased ISO 4217 code, e.g. " usd" -> "USD".
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// CheckCurrency verifies that an amount expressed in currency can be applied to an account held in accountCurrency.
+// An empty currency means the amount is already in the account currency.
+func CheckCurrency(currency, accountCurrency string) error {
+	if currency == "" {
+		return nil
+	}
+	if NormalizeCurrency(currency) != NormalizeCurrency(accountCurrency) {
+		return fmt.Errorf("%w: %s amount cannot be applied to %s account", ErrCurrencyMismatch, NormalizeCurrency(currency), NormalizeCurrency(accountCurrency))
+	}
+	return nil
+}
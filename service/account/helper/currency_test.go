@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckCurrency(t *testing.T) {
+	tests := []struct {
+		name            string
+		currency        string
+		accountCurrency string
+		wantErr         bool
+	}{
+		{name: "same currency", currency: "USD", accountCurrency: "USD"},
+		{name: "case insensitive", currency: " usd", accountCurrency: "USD"},
+		{name: "empty defaults to account currency", currency: "", accountCurrency: "CNY"},
+		{name: "cross currency", currency: "CNY", accountCurrency: "USD", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCurrency(tt.currency, tt.accountCurrency)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckCurrency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrCurrencyMismatch) {
+				t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCostOverview_MarshalCurrency(t *testing.T) {
+	data, err := json.Marshal(CostOverview{Amount: 1000000, Currency: "USD", Namespace: "ns-test"})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"currency":"USD"`) {
+		t.Errorf("currency missing from %s", data)
+	}
+
+	var overview CostOverview
+	if err := json.Unmarshal(data, &overview); err != nil || overview.Currency != "USD" || overview.Amount != 1000000 {
+		t.Errorf("unmarshal = %+v, %v", overview, err)
+	}
+}
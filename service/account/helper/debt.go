@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"fmt"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+// debtStatusTransitions is the debt state machine driven by the account debt controller:
+// a positive balance moves any state back to one of the non-debt states, otherwise a non-debt state
+// falls into DebtPeriod, which advances to DebtDeletionPeriod and then FinalDeletionPeriod over time.
+// Debt states never move backwards to an earlier debt state, nor skip one.
+var debtStatusTransitions = func() map[types.DebtStatusType][]types.DebtStatusType {
+	transitions := map[types.DebtStatusType][]types.DebtStatusType{
+		types.NormalPeriod:          {types.DebtPeriod},
+		types.LowBalancePeriod:      {types.DebtPeriod},
+		types.CriticalBalancePeriod: {types.DebtPeriod},
+		types.DebtPeriod:            {types.DebtDeletionPeriod},
+		types.DebtDeletionPeriod:    {types.FinalDeletionPeriod},
+		types.FinalDeletionPeriod:   {},
+	}
+	for status := range transitions {
+		transitions[status] = append(transitions[status], types.NonDebtStates...)
+	}
+	return transitions
+}()
+
+// ValidateDebtStatusTransition checks that the lastStatus -> currentStatus move is allowed by the debt state machine.
+func ValidateDebtStatusTransition(lastStatus, currentStatus types.DebtStatusType) error {
+	if _, ok := types.StatusMap[currentStatus]; !ok {
+		return fmt.Errorf("unknown currentDebtStatus: %s", currentStatus)
+	}
+	next, ok := debtStatusTransitions[lastStatus]
+	if !ok {
+		return fmt.Errorf("unknown lastDebtStatus: %s", lastStatus)
+	}
+	if lastStatus == currentStatus || types.ContainDebtStatus(next, currentStatus) {
+		return nil
+	}
+	return fmt.Errorf("illegal debt status transition: %s -> %s", lastStatus, currentStatus)
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+func TestValidateDebtStatusTransition(t *testing.T) {
+	tests := []struct {
+		last    types.DebtStatusType
+		current types.DebtStatusType
+		wantErr bool
+	}{
+		{last: types.NormalPeriod, current: types.LowBalancePeriod},
+		{last: types.CriticalBalancePeriod, current: types.NormalPeriod},
+		{last: types.LowBalancePeriod, current: types.DebtPeriod},
+		{last: types.DebtPeriod, current: types.DebtDeletionPeriod},
+		{last: types.DebtPeriod, current: types.NormalPeriod},
+		{last: types.DebtDeletionPeriod, current: types.FinalDeletionPeriod},
+		{last: types.DebtDeletionPeriod, current: types.CriticalBalancePeriod},
+		{last: types.FinalDeletionPeriod, current: types.NormalPeriod},
+		{last: types.FinalDeletionPeriod, current: types.FinalDeletionPeriod},
+
+		{last: types.NormalPeriod, current: types.DebtDeletionPeriod, wantErr: true},
+		{last: types.CriticalBalancePeriod, current: types.FinalDeletionPeriod, wantErr: true},
+		{last: types.DebtPeriod, current: types.FinalDeletionPeriod, wantErr: true},
+		{last: types.DebtDeletionPeriod, current: types.DebtPeriod, wantErr: true},
+		{last: types.FinalDeletionPeriod, current: types.DebtPeriod, wantErr: true},
+		{last: types.FinalDeletionPeriod, current: types.DebtDeletionPeriod, wantErr: true},
+		{last: "", current: types.NormalPeriod, wantErr: true},
+		{last: types.NormalPeriod, current: "UnknownPeriod", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.last)+"->"+string(tt.current), func(t *testing.T) {
+			err := ValidateDebtStatusTransition(tt.last, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDebtStatusTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseAdminFlushDebtResourceStatusReq_RejectsIllegalTransition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(req AdminFlushDebtResourceStatusReq) error {
+		body, _ := json.Marshal(req)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		_, err := ParseAdminFlushDebtResourceStatusReq(c)
+		return err
+	}
+
+	if err := parse(AdminFlushDebtResourceStatusReq{UserUID: uuid.New(), LastDebtStatus: types.NormalPeriod, CurrentDebtStatus: types.DebtPeriod}); err != nil {
+		t.Errorf("legal transition rejected: %v", err)
+	}
+	if err := parse(AdminFlushDebtResourceStatusReq{UserUID: uuid.New(), LastDebtStatus: types.DebtDeletionPeriod, CurrentDebtStatus: types.DebtPeriod}); err == nil {
+		t.Error("illegal transition accepted")
+	}
+}
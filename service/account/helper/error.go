@@ -1 +1,6 @@
 package helper
+
+import "errors"
+
+// ErrCurrencyMismatch amounts in different currencies cannot be mixed, e.g. transferring across currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
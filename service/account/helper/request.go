@@ -55,6 +55,10 @@ type TransferAmountReq struct {
 	// @JSONSchema required
 	Amount int64 `json:"amount" bson:"amount" example:"100000000"`
 
+	// @Summary Currency
+	// @Description Currency of the amount (ISO 4217), must match the account currency of the region, empty means the region currency
+	Currency string `json:"currency,omitempty" bson:"currency" example:"USD"`
+
 	// @Summary To user
 	// @Description To user
 	// @JSONSchema required
@@ -147,6 +151,14 @@ type AppCostsReq struct {
 	// @Summary Page Size
 	// @Description Page Size
 	PageSize int `json:"pageSize,omitempty" bson:"pageSize" example:"10"`
+
+	// @Summary Detect anomaly
+	// @Description Flag records whose cost exceeds a multiple of the rolling average of the same app
+	DetectAnomaly bool `json:"detectAnomaly,omitempty" bson:"detectAnomaly" example:"false"`
+
+	// @Summary Anomaly multiple
+	// @Description Multiple of the rolling average above which a record is anomalous, default from COST_ANOMALY_MULTIPLE
+	AnomalyMultiple float64 `json:"anomalyMultiple,omitempty" bson:"anomalyMultiple" example:"3"`
 }
 
 type GetPaymentReq struct {
@@ -375,6 +387,7 @@ func ParseTransferAmountReq(c *gin.Context) (*TransferAmountReq, error) {
 	if transferAmount.Amount == 0 && !transferAmount.TransferAll {
 		return nil, fmt.Errorf("transfer amount cannot be empty")
 	}
+	transferAmount.Currency = NormalizeCurrency(transferAmount.Currency)
 	return transferAmount, nil
 }
 
@@ -416,6 +429,9 @@ func ParseAppCostsReq(c *gin.Context) (*AppCostsReq, error) {
 		return nil, fmt.Errorf("bind json error: %v", err)
 	}
 	setDefaultTimeRange(&userCosts.TimeRange)
+	if userCosts.AnomalyMultiple != 0 && userCosts.AnomalyMultiple <= 1 {
+		return nil, fmt.Errorf("anomaly multiple must be greater than 1")
+	}
 	return userCosts, nil
 }
 
@@ -506,6 +522,10 @@ type CostOverview struct {
 	// @Description Amount
 	Amount int64 `json:"amount" bson:"amount"`
 
+	// @Summary Currency
+	// @Description Currency of the amount (ISO 4217)
+	Currency string `json:"currency" bson:"currency" example:"USD"`
+
 	// @Summary Namespace
 	// @Description Namespace
 	Namespace string `json:"namespace" bson:"namespace"`
@@ -778,5 +798,8 @@ func ParseAdminFlushDebtResourceStatusReq(c *gin.Context) (*AdminFlushDebtResour
 	if flushDebtResourceStatus.CurrentDebtStatus == "" {
 		return nil, fmt.Errorf("currentDebtStatus cannot be empty")
 	}
+	if err := ValidateDebtStatusTransition(flushDebtResourceStatus.LastDebtStatus, flushDebtResourceStatus.CurrentDebtStatus); err != nil {
+		return nil, err
+	}
 	return flushDebtResourceStatus, nil
 }
@@ -0,0 +1,177 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/controllers/pkg/utils/env"
+)
+
+const (
+	// EnvDebtStatusWebhookURL enables the debt status webhook when set.
+	EnvDebtStatusWebhookURL            = "DEBT_STATUS_WEBHOOK_URL"
+	EnvDebtStatusWebhookTimeout        = "DEBT_STATUS_WEBHOOK_TIMEOUT"
+	EnvDebtStatusWebhookMaxRetries     = "DEBT_STATUS_WEBHOOK_MAX_RETRIES"
+	EnvDebtStatusWebhookInitialBackoff = "DEBT_STATUS_WEBHOOK_INITIAL_BACKOFF"
+	EnvDebtStatusWebhookMaxBackoff     = "DEBT_STATUS_WEBHOOK_MAX_BACKOFF"
+	// EnvDebtStatusWebhookDeadLetterFile is the file that undeliverable events are appended to, as JSON lines.
+	// When empty, undeliverable events are only logged.
+	EnvDebtStatusWebhookDeadLetterFile = "DEBT_STATUS_WEBHOOK_DEAD_LETTER_FILE"
+)
+
+// DebtStatusEvent is the payload posted when a user's debt status transitions.
+type DebtStatusEvent struct {
+	UserUID   uuid.UUID            `json:"userUID"`
+	OldStatus types.DebtStatusType `json:"oldStatus"`
+	NewStatus types.DebtStatusType `json:"newStatus"`
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+// DeadLetterSink keeps events that could not be delivered after all retries.
+type DeadLetterSink interface {
+	Write(event *DebtStatusEvent, cause error) error
+}
+
+type deadLetterRecord struct {
+	Event *DebtStatusEvent `json:"event"`
+	Error string           `json:"error"`
+	Time  time.Time        `json:"time"`
+}
+
+type writerDeadLetterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterDeadLetterSink(w io.Writer) DeadLetterSink {
+	return &writerDeadLetterSink{w: w}
+}
+
+func (s *writerDeadLetterSink) Write(event *DebtStatusEvent, cause error) error {
+	data, err := json.Marshal(&deadLetterRecord{Event: event, Error: cause.Error(), Time: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+type logDeadLetterSink struct{}
+
+func (logDeadLetterSink) Write(event *DebtStatusEvent, cause error) error {
+	logrus.Errorf("debt status webhook dead letter: user %s %s -> %s at %s: %v",
+		event.UserUID, event.OldStatus, event.NewStatus, event.Timestamp.Format(time.RFC3339), cause)
+	return nil
+}
+
+// DebtStatusWebhook posts debt status transitions to an external endpoint with retry and exponential backoff.
+type DebtStatusWebhook struct {
+	URL            string
+	Client         *http.Client
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	DeadLetter     DeadLetterSink
+}
+
+// NewDebtStatusWebhookFromEnv returns nil when DEBT_STATUS_WEBHOOK_URL is not set.
+func NewDebtStatusWebhookFromEnv() (*DebtStatusWebhook, error) {
+	url := os.Getenv(EnvDebtStatusWebhookURL)
+	if url == "" {
+		return nil, nil
+	}
+	webhook := &DebtStatusWebhook{
+		URL:            url,
+		Client:         &http.Client{Timeout: env.GetDurationEnvWithDefault(EnvDebtStatusWebhookTimeout, 10*time.Second)},
+		MaxRetries:     env.GetIntEnvWithDefault(EnvDebtStatusWebhookMaxRetries, 5),
+		InitialBackoff: env.GetDurationEnvWithDefault(EnvDebtStatusWebhookInitialBackoff, time.Second),
+		MaxBackoff:     env.GetDurationEnvWithDefault(EnvDebtStatusWebhookMaxBackoff, time.Minute),
+		DeadLetter:     logDeadLetterSink{},
+	}
+	if path := os.Getenv(EnvDebtStatusWebhookDeadLetterFile); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("open debt status webhook dead letter file: %w", err)
+		}
+		webhook.DeadLetter = NewWriterDeadLetterSink(f)
+	}
+	return webhook, nil
+}
+
+// Send delivers the event, retrying network errors, 429 and 5xx responses.
+// Events that still cannot be delivered are handed to the dead letter sink and the last error is returned.
+func (w *DebtStatusWebhook) Send(ctx context.Context, event *DebtStatusEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal debt status event: %w", err)
+	}
+
+	backoff := w.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, sendErr := w.post(ctx, body)
+		if sendErr == nil {
+			return nil
+		}
+		err = sendErr
+		if !retryable || attempt >= w.MaxRetries {
+			break
+		}
+		logrus.Warnf("debt status webhook attempt %d for user %s failed, retry in %s: %v", attempt+1, event.UserUID, backoff, sendErr)
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("%v: %w", sendErr, ctx.Err())
+		case <-time.After(backoff):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if backoff *= 2; w.MaxBackoff > 0 && backoff > w.MaxBackoff {
+			backoff = w.MaxBackoff
+		}
+	}
+
+	if w.DeadLetter != nil {
+		if dlErr := w.DeadLetter.Write(event, err); dlErr != nil {
+			logrus.Errorf("failed to write debt status webhook dead letter for user %s: %v", event.UserUID, dlErr)
+		}
+	}
+	return fmt.Errorf("debt status webhook failed for user %s: %w", event.UserUID, err)
+}
+
+func (w *DebtStatusWebhook) post(ctx context.Context, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError, err
+}
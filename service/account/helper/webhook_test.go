@@ -0,0 +1,139 @@
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+type captureDeadLetterSink struct {
+	mu     sync.Mutex
+	events []*DebtStatusEvent
+}
+
+func (s *captureDeadLetterSink) Write(event *DebtStatusEvent, _ error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func newTestDebtStatusEvent() *DebtStatusEvent {
+	return &DebtStatusEvent{
+		UserUID:   uuid.New(),
+		OldStatus: types.NormalPeriod,
+		NewStatus: types.DebtPeriod,
+		Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestDebtStatusWebhook_SendPayload(t *testing.T) {
+	var received DebtStatusEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	event := newTestDebtStatusEvent()
+	webhook := &DebtStatusWebhook{URL: server.URL, MaxRetries: 3, InitialBackoff: time.Millisecond}
+	if err := webhook.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if received.UserUID != event.UserUID || received.OldStatus != event.OldStatus ||
+		received.NewStatus != event.NewStatus || !received.Timestamp.Equal(event.Timestamp) {
+		t.Errorf("received %+v, want %+v", received, event)
+	}
+}
+
+func TestDebtStatusWebhook_RetryTransientFailure(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	deadLetter := &captureDeadLetterSink{}
+	webhook := &DebtStatusWebhook{URL: server.URL, MaxRetries: 5, InitialBackoff: time.Millisecond, DeadLetter: deadLetter}
+	if err := webhook.Send(context.Background(), newTestDebtStatusEvent()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+	if len(deadLetter.events) != 0 {
+		t.Errorf("unexpected dead letters: %d", len(deadLetter.events))
+	}
+}
+
+func TestDebtStatusWebhook_DeadLetter(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantCalls int32
+	}{
+		{name: "persistent server error", status: http.StatusInternalServerError, wantCalls: 3},
+		{name: "permanent client error", status: http.StatusBadRequest, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			deadLetter := &captureDeadLetterSink{}
+			event := newTestDebtStatusEvent()
+			webhook := &DebtStatusWebhook{URL: server.URL, MaxRetries: 2, InitialBackoff: time.Millisecond, DeadLetter: deadLetter}
+			if err := webhook.Send(context.Background(), event); err == nil {
+				t.Fatal("Send() expected error")
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+			if len(deadLetter.events) != 1 || deadLetter.events[0] != event {
+				t.Errorf("dead letters = %v, want the event", deadLetter.events)
+			}
+		})
+	}
+}
+
+func TestDebtStatusWebhook_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	deadLetter := &captureDeadLetterSink{}
+	webhook := &DebtStatusWebhook{URL: server.URL, MaxRetries: 100, InitialBackoff: time.Hour, DeadLetter: deadLetter}
+	err := webhook.Send(ctx, newTestDebtStatusEvent())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send() error = %v, want deadline exceeded", err)
+	}
+	if len(deadLetter.events) != 1 {
+		t.Errorf("dead letters = %d, want 1", len(deadLetter.events))
+	}
+}
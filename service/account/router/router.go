@@ -33,6 +33,13 @@ import (
 
 func RegisterPayRouter() {
 	router := gin.Default()
+	router.Use(helper.CorsMiddleware(helper.NewCorsConfigFromEnv()),
+		helper.BodySizeLimitMiddleware(helper.GetMaxRequestBodySize()))
+	auditSink, err := helper.NewAuditSinkFromEnv()
+	if err != nil {
+		log.Fatalf("Error initializing audit sink: %v", err)
+	}
+	helper.SetAuditSink(auditSink)
 	ctx := context.Background()
 	if err := dao.Init(ctx); err != nil {
 		log.Fatalf("Error initializing database: %v", err)